@@ -1,18 +1,36 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"flag"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"syscall"
 	"time"
 
+	"gsqlhealth/internal/audit"
 	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/consul"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/dnsserver"
+	"gsqlhealth/internal/grafana"
+	"gsqlhealth/internal/grpcserver"
 	"gsqlhealth/internal/health"
+	"gsqlhealth/internal/logfile"
+	"gsqlhealth/internal/mqtt"
+	"gsqlhealth/internal/notify"
+	"gsqlhealth/internal/passive"
+	"gsqlhealth/internal/requestid"
 	"gsqlhealth/internal/server"
+	"gsqlhealth/internal/zabbix"
 )
 
 const (
@@ -23,7 +41,8 @@ const (
 func main() {
 	// Parse command line flags
 	var (
-		configPath = flag.String("config", defaultConfigPath, "Path to configuration file")
+		configPath = flag.String("config", defaultConfigPath, "Path or URL (https://, s3://, consul://) of the configuration")
+		configPoll = flag.Duration("config-poll", 0, "Interval for polling the configuration source for changes (0 = disabled)")
 		version    = flag.Bool("version", false, "Show version information")
 		validate   = flag.Bool("validate", false, "Validate configuration and exit")
 	)
@@ -37,7 +56,7 @@ func main() {
 	}
 
 	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	cfg, configData, err := config.LoadConfigData(*configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 		os.Exit(1)
@@ -49,16 +68,86 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Run the requested command instead of the service
+	switch flag.Arg(0) {
+	case "":
+	case "check":
+		os.Exit(checkOnce(cfg))
+	case "grafana-dashboard":
+		os.Exit(grafanaDashboard(cfg, flag.Args()[1:]))
+	case "zabbix-template":
+		os.Exit(zabbixTemplate(cfg, flag.Args()[1:]))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", flag.Arg(0))
+		os.Exit(2)
+	}
+
 	// Setup logger
-	logger := setupLogger(cfg.Logging)
+	output, closeOutput, err := logOutput(cfg.Logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open log output: %v\n", err)
+		os.Exit(1)
+	}
+	logger := setupLogger(cfg.Logging, output)
 	logger.Info("Starting gsqlhealth",
 		"version", "1.0.0",
 		"config_path", *configPath)
 
+	// Setup graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Watch the configuration source for changes if requested
+	reloadChan := make(chan *config.Config, 1)
+	if *configPoll > 0 {
+		watchCtx, watchCancel := context.WithCancel(context.Background())
+		defer watchCancel()
+		go watchConfig(watchCtx, *configPath, configData, *configPoll, reloadChan, logger)
+	}
+
+	// Run until shutdown, restarting with the new configuration on every change
+	for cfg != nil {
+		cfg = run(cfg, logger, sigChan, reloadChan)
+		if cfg != nil {
+			nextOutput, closeNextOutput, err := logOutput(cfg.Logging)
+			if err != nil {
+				logger.Error("Failed to open log output, keeping the current one", "error", err)
+				nextOutput, closeNextOutput = output, closeOutput
+			} else {
+				closeOutput()
+			}
+			output, closeOutput = nextOutput, closeNextOutput
+			logger = setupLogger(cfg.Logging, output)
+			logger.Info("Restarting with reloaded configuration",
+				"config_path", *configPath)
+		}
+	}
+
+	logger.Info("Shutdown complete")
+	closeOutput()
+}
+
+// run starts the health service and servers for a configuration and blocks
+// until shutdown. It returns the configuration to restart with when a reload
+// was requested, or nil when the process should exit.
+func run(cfg *config.Config, logger *slog.Logger, sigChan <-chan os.Signal, reloadChan <-chan *config.Config) *config.Config {
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Apply the configured soft memory limit; GOMEMLIMIT is used otherwise
+	if limit := cfg.Memory.GetSoftLimit(); limit > 0 {
+		debug.SetMemoryLimit(limit)
+	}
+
+	// Open the audit log if enabled
+	auditLog, err := audit.Open(cfg.Audit)
+	if err != nil {
+		logger.Error("Failed to open audit log", "error", err)
+		os.Exit(1)
+	}
+	defer auditLog.Close()
+
 	// Create health service
 	healthService := health.NewService(cfg, logger)
 
@@ -69,32 +158,120 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Send status changes to the configured notifiers
+	notifier := notify.NewDispatcher(cfg, healthService, logger)
+	if notifier.Enabled() {
+		notifier.Start()
+	}
+
+	// Publish check results and status changes to the MQTT broker if enabled
+	var mqttPublisher *mqtt.Publisher
+	if cfg.MQTT.Enabled {
+		publisher, err := mqtt.NewPublisher(cfg, healthService, logger)
+		if err != nil {
+			logger.Error("Failed to create MQTT publisher", "error", err)
+			os.Exit(1)
+		}
+		publisher.Start()
+		mqttPublisher = publisher
+	}
+
+	// Submit check results to Nagios or Icinga as passive checks if enabled
+	var passiveSender *passive.Sender
+	if cfg.PassiveChecks.Enabled {
+		sender, err := passive.NewSender(cfg, healthService, logger)
+		if err != nil {
+			logger.Error("Failed to create passive check sender", "error", err)
+			os.Exit(1)
+		}
+		sender.Start()
+		passiveSender = sender
+	}
+
+	// Push check results to Zabbix if enabled
+	var zabbixSender *zabbix.Sender
+	if cfg.Zabbix.Enabled {
+		zabbixSender = zabbix.NewSender(cfg, healthService, logger)
+		zabbixSender.Start()
+	}
+
 	// Create HTTP server
 	httpServer := server.NewServer(cfg, healthService, logger)
+	httpServer.SetAuditLog(auditLog)
 
-	// Setup graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Create gRPC health server if enabled
+	var grpcServer *grpcserver.Server
+	if cfg.GRPC.Enabled {
+		grpcServer = grpcserver.NewServer(cfg, healthService, logger)
+	}
+
+	// Create DNS health responder if enabled
+	var dnsServer *dnsserver.Server
+	if cfg.DNS.Enabled {
+		dnsServer = dnsserver.NewServer(cfg, healthService, logger)
+	}
 
 	// Start HTTP server in a goroutine
-	serverErrChan := make(chan error, 1)
+	serverErrChan := make(chan error, 3)
 	go func() {
 		logger.Info("HTTP server starting",
 			"address", cfg.Server.GetAddress())
 
-		if err := httpServer.Start(); err != nil {
+		if err := httpServer.Start(); err != nil && err != http.ErrServerClosed {
 			serverErrChan <- err
 		}
 	}()
 
-	// Wait for shutdown signal or server error
+	// Start gRPC server in a goroutine
+	if grpcServer != nil {
+		go func() {
+			logger.Info("gRPC server starting",
+				"address", cfg.GRPC.GetAddress())
+
+			if err := grpcServer.Start(); err != nil {
+				serverErrChan <- err
+			}
+		}()
+	}
+
+	// Start DNS responder in a goroutine
+	if dnsServer != nil {
+		go func() {
+			logger.Info("DNS responder starting",
+				"address", cfg.DNS.GetAddress())
+
+			if err := dnsServer.Start(); err != nil {
+				serverErrChan <- err
+			}
+		}()
+	}
+
+	// Register gsqlhealth and its databases in Consul if enabled
+	var consulRegistrar *consul.Registrar
+	if cfg.Consul.Enabled {
+		registrar, err := consul.NewRegistrar(cfg, healthService, logger)
+		if err != nil {
+			logger.Error("Failed to create Consul registrar", "error", err)
+			os.Exit(1)
+		}
+		registrar.Start()
+		consulRegistrar = registrar
+	}
+
+	// Wait for shutdown signal, configuration change or server error
+	var nextConfig *config.Config
 	select {
 	case err := <-serverErrChan:
 		if err != nil {
-			logger.Error("HTTP server error", "error", err)
+			logger.Error("Server error", "error", err)
 		}
 	case sig := <-sigChan:
 		logger.Info("Received shutdown signal", "signal", sig.String())
+	case nextConfig = <-reloadChan:
+		logger.Info("Configuration changed, reloading")
+		if err := auditLog.Log(audit.Record{Action: "config.reload", Principal: "system", Outcome: audit.OutcomeSuccess}); err != nil {
+			logger.Error("Failed to write audit record", "action", "config.reload", "error", err)
+		}
 	}
 
 	// Graceful shutdown
@@ -104,21 +281,212 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
 
+	// Deregister from Consul before the checks start failing
+	if consulRegistrar != nil {
+		consulRegistrar.Stop()
+	}
+
 	// Shutdown HTTP server
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Error shutting down HTTP server", "error", err)
 	}
 
+	// Shutdown gRPC server
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Error shutting down gRPC server", "error", err)
+		}
+	}
+
+	// Shutdown DNS responder
+	if dnsServer != nil {
+		if err := dnsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Error shutting down DNS responder", "error", err)
+		}
+	}
+
+	// Stop sending notifications
+	notifier.Stop()
+
+	// Stop publishing to the MQTT broker
+	if mqttPublisher != nil {
+		mqttPublisher.Stop()
+	}
+
+	// Stop submitting passive check results
+	if passiveSender != nil {
+		passiveSender.Stop()
+	}
+
+	// Stop pushing to Zabbix
+	if zabbixSender != nil {
+		zabbixSender.Stop()
+	}
+
 	// Close database connections
 	if err := healthService.Close(); err != nil {
 		logger.Error("Error closing database connections", "error", err)
 	}
 
-	logger.Info("Shutdown complete")
+	return nextConfig
+}
+
+// checkOnce runs every check once, prints the results as JSON and pushes
+// their metrics to the Pushgateway if configured, for batch jobs and cron. It
+// returns the exit code: 1 when a check is unhealthy or worse, or the push
+// failed.
+func checkOnce(cfg *config.Config) int {
+	// Logs go to stderr, keeping stdout for the results
+	logger := setupLogger(cfg.Logging, os.Stderr)
+
+	// The run neither restores nor overwrites the cache snapshot of the service
+	cfg.CacheSnapshot = ""
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	healthService := health.NewService(cfg, logger)
+	defer healthService.Close()
+	results := healthService.RunOnce(ctx)
+
+	output, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		logger.Error("Failed to encode results", "error", err)
+		return 1
+	}
+	fmt.Println(string(output))
+
+	code := 0
+	for _, dbResults := range results {
+		for _, result := range dbResults {
+			if result.Status.Code() >= database.StatusUnhealthy.Code() {
+				code = 1
+			}
+		}
+	}
+
+	if cfg.Pushgateway.IsEnabled() {
+		if err := server.PushMetrics(ctx, cfg.Pushgateway, cfg.Databases, results); err != nil {
+			logger.Error("Failed to push metrics to the Pushgateway", "error", err)
+			return 1
+		}
+		logger.Info("Pushed metrics to the Pushgateway",
+			"job", cfg.Pushgateway.GetJob(),
+			"instance", cfg.Pushgateway.GetInstance())
+	}
+	return code
+}
+
+// grafanaDashboard prints the Grafana dashboard of the configured checks as
+// JSON, returning the exit code
+func grafanaDashboard(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("grafana-dashboard", flag.ContinueOnError)
+	title := flags.String("title", "gsqlhealth", "Title of the dashboard")
+	uid := flags.String("uid", "gsqlhealth", "UID of the dashboard, part of its URL in Grafana")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	dashboard := grafana.Generate(cfg, grafana.Options{Title: *title, UID: *uid})
+	output, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode dashboard: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(output))
+	return 0
+}
+
+// zabbixTemplate prints the Zabbix template of the items the Zabbix sender
+// pushes to as XML, returning the exit code
+func zabbixTemplate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("zabbix-template", flag.ContinueOnError)
+	name := flags.String("name", "Template gsqlhealth", "Name of the template")
+	group := flags.String("group", "Templates/Databases", "Group of the template")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	template := zabbix.GenerateTemplate(cfg, zabbix.TemplateOptions{Name: *name, Group: *group})
+	output, err := xml.MarshalIndent(template, "", "    ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to encode template: %v\n", err)
+		return 1
+	}
+	fmt.Println(xml.Header + string(output))
+	return 0
+}
+
+// watchConfig polls the configuration source and sends valid configurations
+// to reloadChan whenever the content differs from last, the data the running
+// configuration was loaded from. Invalid changes are logged and ignored so a
+// bad push never takes the running service down.
+func watchConfig(ctx context.Context, location string, last []byte, interval time.Duration, reloadChan chan<- *config.Config, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.Info("Watching configuration source for changes",
+		"source", location,
+		"interval", interval)
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		data, err := config.ReadSource(ctx, location)
+		if err != nil {
+			logger.Warn("Failed to read configuration source", "source", location, "error", err)
+			continue
+		}
+
+		if bytes.Equal(data, last) {
+			continue
+		}
+
+		cfg, err := config.ParseConfig(data)
+		if err != nil {
+			logger.Error("Ignoring invalid configuration change", "source", location, "error", err)
+			last = data
+			continue
+		}
+		last = data
+
+		select {
+		case reloadChan <- cfg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// logOutput returns the writer of the configured log output, rotating log
+// files by size, and a function closing it
+func logOutput(logConfig config.Logging) (io.Writer, func(), error) {
+	switch logConfig.Output {
+	case config.LogOutputStderr:
+		return os.Stderr, func() {}, nil
+	case config.LogOutputFile:
+		writer, err := logfile.Open(logConfig.Path, logfile.Options{
+			MaxSize:    logConfig.GetMaxSize(),
+			MaxBackups: logConfig.MaxBackups,
+			MaxAge:     time.Duration(logConfig.MaxAge) * 24 * time.Hour,
+			Compress:   logConfig.Compress,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return writer, func() { writer.Close() }, nil
+	default:
+		return os.Stdout, func() {}, nil
+	}
 }
 
-// setupLogger creates and configures the logger based on configuration
-func setupLogger(logConfig config.Logging) *slog.Logger {
+// setupLogger creates and configures the logger based on configuration,
+// writing to output
+func setupLogger(logConfig config.Logging, output io.Writer) *slog.Logger {
 	var level slog.Level
 
 	// Parse log level
@@ -146,13 +514,14 @@ func setupLogger(logConfig config.Logging) *slog.Logger {
 	// Choose handler based on format
 	switch logConfig.Format {
 	case "json":
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(output, opts)
 	case "text":
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(output, opts)
 	default:
 		// Default to JSON for better structured logging
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(output, opts)
 	}
 
-	return slog.New(handler)
+	// Records logged with a request context carry its X-Request-ID
+	return slog.New(requestid.NewHandler(handler))
 }
\ No newline at end of file
@@ -0,0 +1,162 @@
+// Package audit writes the audit log: attributable records of API requests
+// and admin actions, as JSON lines separate from the application log.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+// Outcomes of audited actions
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied" // rejected by authentication, authorization or rate limiting
+	OutcomeFailure = "failure"
+)
+
+// redacted replaces the values of sensitive parameters
+const redacted = "[REDACTED]"
+
+// Record is an audit record. Principal is the authenticated client, such as
+// "user:alice", "api_key:ci" or "oidc:<subject>", or "system" for actions of
+// gsqlhealth itself.
+type Record struct {
+	Time       time.Time           `json:"time"`
+	Action     string              `json:"action"`
+	Principal  string              `json:"principal,omitempty"`
+	Outcome    string              `json:"outcome"`
+	RequestID  string              `json:"request_id,omitempty"`
+	Method     string              `json:"method,omitempty"`
+	Path       string              `json:"path,omitempty"`
+	Params     map[string]string   `json:"params,omitempty"` // path parameters
+	Query      map[string][]string `json:"query,omitempty"`
+	Body       interface{}         `json:"body,omitempty"` // parsed body of admin actions, redacted
+	Status     int                 `json:"status,omitempty"`
+	Duration   time.Duration       `json:"duration,omitempty"`
+	RemoteAddr string              `json:"remote_addr,omitempty"`
+	UserAgent  string              `json:"user_agent,omitempty"`
+	Details    map[string]string   `json:"details,omitempty"`
+}
+
+// Logger writes audit records. A nil Logger discards them, so callers need
+// not check whether auditing is enabled.
+type Logger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	closer io.Closer
+}
+
+// Open opens the configured audit log, appending to its file. It returns nil
+// when auditing is disabled.
+func Open(cfg config.Audit) (*Logger, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	switch cfg.Output {
+	case config.AuditOutputStdout:
+		return New(os.Stdout), nil
+	case config.AuditOutputStderr:
+		return New(os.Stderr), nil
+	}
+
+	file, err := os.OpenFile(cfg.Output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Logger{out: file, closer: file}, nil
+}
+
+// New returns a logger writing to w
+func New(w io.Writer) *Logger {
+	return &Logger{out: w}
+}
+
+// Log writes a record, stamping it with the current time unless it has one
+func (l *Logger) Log(record Record) error {
+	if l == nil {
+		return nil
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.out.Write(line)
+	return err
+}
+
+// Close closes the audit log file
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// IsSensitive reports whether a parameter name holds credentials, whose
+// values are redacted from records
+func IsSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"password", "passwd", "secret", "token", "credential", "dsn"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return name == "key" || strings.HasSuffix(name, "_key") || strings.HasSuffix(name, "-key")
+}
+
+// Redact returns a copy of a decoded JSON or YAML value with the values of
+// sensitive keys replaced, at any depth
+func Redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if IsSensitive(key) {
+				out[key] = redacted
+				continue
+			}
+			out[key] = Redact(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = Redact(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// RedactQuery returns a copy of query parameters with the values of sensitive
+// parameters replaced
+func RedactQuery(query map[string][]string) map[string][]string {
+	if len(query) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(query))
+	for name, values := range query {
+		if IsSensitive(name) {
+			values = []string{redacted}
+		}
+		out[name] = values
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestOpenAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for _, action := range []string{"silence.create", "config.reload"} {
+		logger, err := Open(config.Audit{Enabled: true, Output: path})
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		if err := logger.Log(Record{Action: action, Principal: "user:ops", Outcome: OutcomeSuccess}); err != nil {
+			t.Fatalf("Log() error = %v", err)
+		}
+		logger.Close()
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat audit log: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v; expected 0600", info.Mode().Perm())
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log has %d lines; expected 2", len(lines))
+	}
+	var record Record
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	if record.Action != "config.reload" || record.Principal != "user:ops" || record.Time.IsZero() {
+		t.Errorf("record = %+v; expected the reload with its time", record)
+	}
+
+	disabled, err := Open(config.Audit{})
+	if err != nil || disabled != nil {
+		t.Errorf("Open() = %v, %v; expected no logger when disabled", disabled, err)
+	}
+	if err := disabled.Log(Record{Action: "ignored"}); err != nil {
+		t.Errorf("Log() on a nil logger error = %v", err)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	body := map[string]interface{}{
+		"name":     "orders",
+		"password": "hunter2",
+		"tables": []interface{}{
+			map[string]interface{}{"name": "users", "api_key": "abc"},
+		},
+		"ssl": map[string]interface{}{"client_secret": "xyz", "mode": "require"},
+	}
+	expected := map[string]interface{}{
+		"name":     "orders",
+		"password": redacted,
+		"tables": []interface{}{
+			map[string]interface{}{"name": "users", "api_key": redacted},
+		},
+		"ssl": map[string]interface{}{"client_secret": redacted, "mode": "require"},
+	}
+	if got := Redact(body); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Redact() = %v; expected %v", got, expected)
+	}
+	if body["password"] != "hunter2" {
+		t.Error("Redact() modified its argument")
+	}
+
+	query := RedactQuery(map[string][]string{"token": {"abc"}, "page": {"2"}})
+	if query["token"][0] != redacted || query["page"][0] != "2" {
+		t.Errorf("RedactQuery() = %v; expected the token redacted", query)
+	}
+}
@@ -0,0 +1,329 @@
+package awsauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// imdsURL is the EC2 instance metadata service
+	imdsURL = "http://169.254.169.254"
+	// ecsCredentialsURL is the ECS container credentials endpoint, which
+	// AWS_CONTAINER_CREDENTIALS_RELATIVE_URI is relative to
+	ecsCredentialsURL = "http://169.254.170.2"
+	// imdsTimeout bounds requests to the instance metadata service, which
+	// does not answer off EC2
+	imdsTimeout = 2 * time.Second
+	// refreshMargin is how long before they expire temporary credentials are
+	// refreshed
+	refreshMargin = 5 * time.Minute
+	// roleSessionName names the sessions of assumed roles in CloudTrail
+	roleSessionName = "gsqlhealth"
+)
+
+// Provider retrieves AWS credentials for signing requests, caching temporary
+// credentials until shortly before they expire. It looks for credentials, in
+// order, in the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment
+// variables, a web identity token (IAM roles for EKS service accounts), the
+// ECS container credentials endpoint, and the EC2 instance profile. With a
+// role ARN, it assumes that role with them.
+type Provider struct {
+	roleARN string
+	region  string
+	client  *http.Client
+	imdsURL string // replaced in tests
+	ecsURL  string // replaced in tests
+
+	mu     sync.Mutex
+	cached Credentials
+}
+
+// stsCredentials are the credentials of STS AssumeRole responses
+type stsCredentials struct {
+	AccessKeyID     string    `xml:"AccessKeyId"`
+	SecretAccessKey string    `xml:"SecretAccessKey"`
+	SessionToken    string    `xml:"SessionToken"`
+	Expiration      time.Time `xml:"Expiration"`
+}
+
+// stsResponse is the response of the STS AssumeRole actions, whose result
+// element is named after the action
+type stsResponse struct {
+	Result struct {
+		Credentials stsCredentials `xml:"Credentials"`
+	} `xml:",any"`
+}
+
+// stsErrorResponse is the error response of STS
+type stsErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+// containerCredentials are the credentials served by the ECS container
+// credentials endpoint and the EC2 instance metadata service
+type containerCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+// NewProvider creates a credentials provider. region is the region of the STS
+// endpoint roles are assumed through; roleARN is the role to assume, if any.
+func NewProvider(roleARN, region string, client *http.Client) *Provider {
+	return &Provider{
+		roleARN: roleARN,
+		region:  region,
+		client:  client,
+		imdsURL: imdsURL,
+		ecsURL:  ecsCredentialsURL,
+	}
+}
+
+// Retrieve returns credentials, from the cache while they are valid
+func (p *Provider) Retrieve(ctx context.Context) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cached.AccessKeyID != "" && (p.cached.Expiration.IsZero() || time.Until(p.cached.Expiration) > refreshMargin) {
+		return p.cached, nil
+	}
+
+	creds, err := p.retrieveBase(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if p.roleARN != "" {
+		if creds, err = p.assumeRole(ctx, creds); err != nil {
+			return Credentials{}, fmt.Errorf("failed to assume role %s: %w", p.roleARN, err)
+		}
+	}
+
+	p.cached = creds
+	return creds, nil
+}
+
+// retrieveBase returns the credentials of the environment, before assuming
+// the configured role
+func (p *Provider) retrieveBase(ctx context.Context) (Credentials, error) {
+	if os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		return CredentialsFromEnv()
+	}
+
+	if tokenFile, roleARN := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN"); tokenFile != "" && roleARN != "" {
+		creds, err := p.assumeRoleWithWebIdentity(ctx, tokenFile, roleARN)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("web identity credentials: %w", err)
+		}
+		return creds, nil
+	}
+
+	if relative, full := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"), os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); relative != "" || full != "" {
+		endpoint := full
+		if relative != "" {
+			endpoint = p.ecsURL + relative
+		}
+		creds, err := p.containerCredentials(ctx, endpoint)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("container credentials: %w", err)
+		}
+		return creds, nil
+	}
+
+	creds, err := p.instanceCredentials(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("no AWS credentials found in the environment, and no instance profile: %w", err)
+	}
+	return creds, nil
+}
+
+// assumeRole assumes the configured role with base credentials
+func (p *Provider) assumeRole(ctx context.Context, base Credentials) (Credentials, error) {
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {"2011-06-15"},
+		"RoleArn":         {p.roleARN},
+		"RoleSessionName": {roleSessionName},
+	}
+	return p.callSTS(ctx, form, &base)
+}
+
+// assumeRoleWithWebIdentity exchanges a web identity token for the
+// credentials of a role
+func (p *Provider) assumeRoleWithWebIdentity(ctx context.Context, tokenFile, roleARN string) (Credentials, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = roleSessionName
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	return p.callSTS(ctx, form, nil)
+}
+
+// callSTS calls an STS action returning credentials, signed with creds
+// unless it is nil
+func (p *Provider) callSTS(ctx context.Context, form url.Values, creds *Credentials) (Credentials, error) {
+	body := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.stsURL(), bytes.NewReader(body))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if creds != nil {
+		SignRequest(req, body, "sts", p.region, *creds, time.Now())
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Credentials{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var stsError stsErrorResponse
+		if xml.Unmarshal(respBody, &stsError) == nil && stsError.Code != "" {
+			return Credentials{}, fmt.Errorf("sts returned %s: %s: %s", resp.Status, stsError.Code, stsError.Message)
+		}
+		return Credentials{}, fmt.Errorf("sts returned %s", resp.Status)
+	}
+
+	var response stsResponse
+	if err := xml.Unmarshal(respBody, &response); err != nil {
+		return Credentials{}, fmt.Errorf("invalid sts response: %w", err)
+	}
+	result := response.Result.Credentials
+	if result.AccessKeyID == "" {
+		return Credentials{}, fmt.Errorf("sts response carries no credentials")
+	}
+	return Credentials{
+		AccessKeyID:     result.AccessKeyID,
+		SecretAccessKey: result.SecretAccessKey,
+		SessionToken:    result.SessionToken,
+		Expiration:      result.Expiration,
+	}, nil
+}
+
+// stsURL returns the regional STS endpoint, or AWS_ENDPOINT_URL_STS
+func (p *Provider) stsURL() string {
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_STS"); endpoint != "" {
+		return endpoint
+	}
+	return fmt.Sprintf("https://sts.%s.amazonaws.com/", p.region)
+}
+
+// containerCredentials fetches the credentials of an ECS task role
+func (p *Provider) containerCredentials(ctx context.Context, endpoint string) (Credentials, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	return p.fetchCredentials(req)
+}
+
+// instanceCredentials fetches the credentials of the EC2 instance profile
+// through the instance metadata service, version 2
+func (p *Provider) instanceCredentials(ctx context.Context) (Credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.imdsURL+"/latest/api/token", nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := p.fetchText(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	rolesURL := p.imdsURL + "/latest/meta-data/iam/security-credentials/"
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, rolesURL, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	roles, err := p.fetchText(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	role, _, _ := strings.Cut(roles, "\n")
+	if role == "" {
+		return Credentials{}, fmt.Errorf("the instance has no instance profile")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, rolesURL+url.PathEscape(role), nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	return p.fetchCredentials(req)
+}
+
+// fetchCredentials fetches credentials in the JSON format of the ECS and
+// EC2 metadata endpoints
+func (p *Provider) fetchCredentials(req *http.Request) (Credentials, error) {
+	body, err := p.fetchText(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	var creds containerCredentials
+	if err := json.Unmarshal([]byte(body), &creds); err != nil {
+		return Credentials{}, fmt.Errorf("invalid credentials response: %w", err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("credentials response carries no credentials")
+	}
+	return Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.Token,
+		Expiration:      creds.Expiration,
+	}, nil
+}
+
+// fetchText returns the body of a metadata request
+func (p *Provider) fetchText(req *http.Request) (string, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned %s", req.URL.Redacted(), resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
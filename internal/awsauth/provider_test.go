@@ -0,0 +1,123 @@
+package awsauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearCredentialsEnv unsets the environment variables credentials are
+// looked up in
+func clearCredentialsEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_AUTHORIZATION_TOKEN",
+		"AWS_ENDPOINT_URL_STS",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestProviderEnvironment(t *testing.T) {
+	clearCredentialsEnv(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	creds, err := NewProvider("", "us-east-1", http.DefaultClient).Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if creds.AccessKeyID != "AKIDENV" || !creds.Expiration.IsZero() {
+		t.Errorf("Retrieve() = %+v; expected the environment's credentials", creds)
+	}
+}
+
+func TestProviderContainerAssumeRole(t *testing.T) {
+	clearCredentialsEnv(t)
+	expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	var stsRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/task-credentials":
+			if r.Header.Get("Authorization") != "task-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"AccessKeyId":"AKIDTASK","SecretAccessKey":"task-secret","Token":"task-session","Expiration":%q}`, expiration)
+		case "/sts":
+			stsRequests++
+			if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "AssumeRole" || r.Form.Get("RoleArn") != "arn:aws:iam::123456789012:role/events" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if !strings.Contains(r.Header.Get("Authorization"), "Credential=AKIDTASK/") || r.Header.Get("X-Amz-Security-Token") != "task-session" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `<ErrorResponse><Error><Code>AccessDenied</Code><Message>unsigned</Message></Error></ErrorResponse>`)
+				return
+			}
+			fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials>
+				<AccessKeyId>AKIDROLE</AccessKeyId><SecretAccessKey>role-secret</SecretAccessKey>
+				<SessionToken>role-session</SessionToken><Expiration>%s</Expiration>
+				</Credentials></AssumeRoleResult>
+				<ResponseMetadata><RequestId>c6104cbe</RequestId></ResponseMetadata></AssumeRoleResponse>`, expiration)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", server.URL+"/task-credentials")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "task-token")
+	t.Setenv("AWS_ENDPOINT_URL_STS", server.URL+"/sts")
+
+	provider := NewProvider("arn:aws:iam::123456789012:role/events", "eu-west-1", server.Client())
+	for i := 0; i < 2; i++ {
+		creds, err := provider.Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("Retrieve() error = %v", err)
+		}
+		if creds.AccessKeyID != "AKIDROLE" || creds.SessionToken != "role-session" || creds.Expiration.IsZero() {
+			t.Errorf("Retrieve() = %+v; expected the assumed role's credentials", creds)
+		}
+	}
+	if stsRequests != 1 {
+		t.Errorf("STS requests = %d; expected the credentials to be cached", stsRequests)
+	}
+}
+
+func TestProviderInstanceProfile(t *testing.T) {
+	clearCredentialsEnv(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			fmt.Fprint(w, "imds-token")
+		case r.Header.Get("X-aws-ec2-metadata-token") != "imds-token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprint(w, "gsqlhealth-instance\n")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/gsqlhealth-instance":
+			fmt.Fprint(w, `{"Code":"Success","AccessKeyId":"AKIDEC2","SecretAccessKey":"ec2-secret","Token":"ec2-session","Expiration":"2030-01-01T00:00:00Z"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider("", "us-east-1", server.Client())
+	provider.imdsURL = server.URL
+	creds, err := provider.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if creds.AccessKeyID != "AKIDEC2" || creds.SessionToken != "ec2-session" {
+		t.Errorf("Retrieve() = %+v; expected the instance profile's credentials", creds)
+	}
+}
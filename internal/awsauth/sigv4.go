@@ -0,0 +1,189 @@
+package awsauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// signingAlgorithm is the AWS Signature Version 4 algorithm identifier
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	// amzDateFormat is the timestamp format used in x-amz-date
+	amzDateFormat = "20060102T150405Z"
+	// shortDateFormat is the date format used in the credential scope
+	shortDateFormat = "20060102"
+	// defaultRegion is used when no region is configured in the environment
+	defaultRegion = "us-east-1"
+)
+
+// Credentials holds AWS credentials
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time // zero for static credentials
+}
+
+// CredentialsFromEnv loads credentials from the standard AWS environment variables
+func CredentialsFromEnv() (Credentials, error) {
+	creds := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+
+	return creds, nil
+}
+
+// RegionFromEnv returns the region from AWS_REGION or AWS_DEFAULT_REGION
+func RegionFromEnv() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	if region := os.Getenv("AWS_DEFAULT_REGION"); region != "" {
+		return region
+	}
+	return defaultRegion
+}
+
+// SignRequest signs an HTTP request in place using AWS Signature Version 4.
+// The payload must be the exact request body (nil for requests without one).
+func SignRequest(req *http.Request, payload []byte, service, region string, creds Credentials, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	shortDate := now.Format(shortDateFormat)
+	payloadHash := hashHex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers, signedHeaders := canonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signature := hex.EncodeToString(hmacSHA256(SigningKey(creds.SecretAccessKey, shortDate, region, service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// SigningKey derives the SigV4 signing key for a date, region and service
+func SigningKey(secret, shortDate, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), shortDate)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// canonicalURI returns the URI-encoded request path
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// canonicalQuery returns the sorted, RFC 3986 encoded query string
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// canonicalHeaders returns the canonical header block and signed header list
+func canonicalHeaders(req *http.Request) (string, string) {
+	values := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		values["host"] = req.Host
+	}
+	for name, headerValues := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "authorization" || lower == "user-agent" {
+			continue
+		}
+		trimmed := make([]string, len(headerValues))
+		for i, value := range headerValues {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		values[lower] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name)
+		builder.WriteString(":")
+		builder.WriteString(values[name])
+		builder.WriteString("\n")
+	}
+
+	return builder.String(), strings.Join(names, ";")
+}
+
+// uriEncode encodes a string per RFC 3986 as required by SigV4
+func uriEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// hashHex returns the hex encoded SHA-256 of data
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 computes HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Audit outputs other than files
+const (
+	AuditOutputStdout = "stdout"
+	AuditOutputStderr = "stderr"
+)
+
+// Audit represents the optional audit log, recording who made every API
+// request and admin action, with what parameters and outcome, as JSON lines
+// separate from the application log
+type Audit struct {
+	Enabled     bool     `yaml:"enabled"`
+	Output      string   `yaml:"output"`       // file path, stdout or stderr
+	AdminOnly   bool     `yaml:"admin_only"`   // record admin actions and check refreshes only
+	ExemptPaths []string `yaml:"exempt_paths"` // paths not recorded, e.g. /metrics
+}
+
+// Validate validates audit configuration
+func (a *Audit) Validate() error {
+	if !a.Enabled {
+		return nil
+	}
+
+	if strings.TrimSpace(a.Output) == "" {
+		return fmt.Errorf("output is required (a file path, stdout or stderr)")
+	}
+	for _, path := range a.ExemptPaths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("exempt path %q must start with /", path)
+		}
+	}
+	return nil
+}
@@ -1,71 +1,472 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"gsqlhealth/internal/cron"
+
+	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/yaml.v3"
 )
 
+// minAPIKeyLength is the minimum length of configured API keys
+const minAPIKeyLength = 16
+
+// maxMaintenanceWindow bounds the length of a single maintenance window
+const maxMaintenanceWindow = 7 * 24 * time.Hour
+
 // Config represents the main configuration structure
 type Config struct {
 	Databases []Database `yaml:"databases"`
 	Server    Server     `yaml:"server"`
 	Logging   Logging    `yaml:"logging"`
+	Audit     Audit      `yaml:"audit"`
 	Retry     Retry      `yaml:"retry"`
+	GRPC      GRPC       `yaml:"grpc"`
+	DNS       DNS        `yaml:"dns"`
+	MQTT      MQTT       `yaml:"mqtt"`
+	Consul    Consul     `yaml:"consul"`
+	Zabbix    Zabbix     `yaml:"zabbix"`
+	Watchdog  Watchdog   `yaml:"watchdog"`
+	Memory    Memory     `yaml:"memory"`
+	History   History    `yaml:"history"`
+	Flapping  Flapping   `yaml:"flapping"`
+	Scoring   Scoring    `yaml:"scoring"`
+	Scheduler Scheduler  `yaml:"scheduler"`
+
+	Notifications Notifications `yaml:"notifications"`
+	PassiveChecks PassiveChecks `yaml:"passive_checks"`
+
+	Pushgateway Pushgateway `yaml:"pushgateway"`
+
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance_windows"` // apply to all databases
+
+	Groups []Group `yaml:"groups"` // named sets of checks for /gate/{group}
+
+	QueryTimeoutDefault   Duration `yaml:"query_timeout_default"`   // used by tables without timeout
+	CheckIntervalDefault  Duration `yaml:"check_interval_default"`  // used by tables without check_interval
+	ScheduledCheckTimeout Duration `yaml:"scheduled_check_timeout"` // deadline of a scheduled check (default 30s)
+	Jitter                Percent  `yaml:"jitter"`                  // random spread of check intervals, used by tables without jitter
+	MaxResultAge          Duration `yaml:"max_result_age"`          // age past which cached results are unknown, used by tables without max_result_age
+
+	DynamicDatabases string `yaml:"dynamic_databases"` // file keeping the databases added through /admin/databases
+	CacheSnapshot    string `yaml:"cache_snapshot"`    // file keeping the cached results across restarts
 }
 
 // Database represents a database connection configuration
 type Database struct {
-	Name     string  `yaml:"name"`
-	Type     string  `yaml:"type"`
-	Host     string  `yaml:"host"`
-	Port     int     `yaml:"port"`
-	Username string  `yaml:"username"`
-	Password string  `yaml:"password"`
-	Database string  `yaml:"database"`
-	SSLMode  string  `yaml:"ssl_mode,omitempty"`
-	Tables   []Table `yaml:"tables"`
+	Name     string            `yaml:"name"`
+	Type     string            `yaml:"type"`
+	Host     string            `yaml:"host"`
+	Port     int               `yaml:"port"`
+	Username string            `yaml:"username"`
+	Password string            `yaml:"password"`
+	Database string            `yaml:"database"`
+	SSLMode  string            `yaml:"ssl_mode,omitempty"`
+	Enabled  *bool             `yaml:"enabled,omitempty"` // defaults to true
+	Labels   map[string]string `yaml:"labels,omitempty"`
+	Vars     map[string]string `yaml:"vars,omitempty"` // template variables of the table queries
+	Tables   []Table           `yaml:"tables"`
+
+	// ConnectionCheck adds a check of connectivity alone; databases without
+	// tables get one by default
+	ConnectionCheck *Table `yaml:"connection_check,omitempty"`
+
+	MaintenanceWindows []MaintenanceWindow `yaml:"maintenance_windows,omitempty"`
+
+	QueryTimeoutDefault  Duration `yaml:"query_timeout_default,omitempty"`  // overrides the global default
+	CheckIntervalDefault Duration `yaml:"check_interval_default,omitempty"` // overrides the global default
+	MaxConcurrentChecks  int      `yaml:"max_concurrent_checks,omitempty"`  // scheduled checks querying the database at once (0 = unlimited)
+	ActiveHours          string   `yaml:"active_hours,omitempty"`           // daily window in which the checks run, e.g. "08:00-20:00 Europe/Berlin"
+
+	// Dynamic marks databases added through the admin API, which are kept in
+	// the dynamic_databases file rather than the configuration
+	Dynamic bool `yaml:"-"`
 }
 
 // Table represents a table health check configuration
 type Table struct {
-	Name          string `yaml:"name"`
-	Query         string `yaml:"query"`
-	Timeout       int    `yaml:"timeout"`        // timeout in seconds
-	CheckInterval int    `yaml:"check_interval"` // check interval in seconds
+	Name             string            `yaml:"name"`
+	Query            string            `yaml:"query"`
+	Steps            []Step            `yaml:"steps,omitempty"`             // queries run in order instead of query
+	Transaction      bool              `yaml:"transaction,omitempty"`       // run the steps in a transaction that is rolled back
+	Check            string            `yaml:"check,omitempty"`             // built-in check run instead of query, see the Check constants
+	From             string            `yaml:"from,omitempty"`              // table queried by a built-in check (default name)
+	MinCount         *int64            `yaml:"min_count,omitempty"`         // fewest rows row_count accepts
+	MaxCount         *int64            `yaml:"max_count,omitempty"`         // most rows row_count accepts
+	Column           string            `yaml:"column,omitempty"`            // timestamp column of freshness
+	MaxAge           Duration          `yaml:"max_age,omitempty"`           // oldest newest row freshness accepts
+	MaxLag           Duration          `yaml:"max_lag,omitempty"`           // largest lag replication_lag accepts
+	MaxUsage         float64           `yaml:"max_usage,omitempty"`         // percentage of capacity in use above which storage fails
+	CapacityMB       int64             `yaml:"capacity_mb,omitempty"`       // capacity storage measures against (default the volume size, mssql only)
+	WarnUsage        float64           `yaml:"warn_usage,omitempty"`        // percentage of max_connections above which connections is degraded
+	CriticalUsage    float64           `yaml:"critical_usage,omitempty"`    // percentage of max_connections above which connections is unhealthy
+	MaxWait          Duration          `yaml:"max_wait,omitempty"`          // longest lock wait blocking accepts
+	MaxDuration      Duration          `yaml:"max_duration,omitempty"`      // longest running query long_queries accepts
+	ShowQueries      bool              `yaml:"show_queries,omitempty"`      // include the truncated text of long queries in data
+	MaxDeadlocks     int64             `yaml:"max_deadlocks,omitempty"`     // most new deadlocks between two deadlocks checks
+	Timeout          Duration          `yaml:"timeout"`                     // seconds or duration string
+	CheckInterval    Duration          `yaml:"check_interval"`              // seconds or duration string
+	Jitter           *Percent          `yaml:"jitter,omitempty"`            // random spread of the check interval, e.g. 10%
+	RecheckInterval  Duration          `yaml:"recheck_interval,omitempty"`  // interval while the check is failing (default check_interval)
+	MaxResultAge     Duration          `yaml:"max_result_age,omitempty"`    // age past which the cached result is reported unknown (default none)
+	ActiveHours      string            `yaml:"active_hours,omitempty"`      // daily window in which the check runs (default the database's)
+	Paused           bool              `yaml:"paused,omitempty"`            // start with the scheduled check paused, until resumed through the admin API
+	Enabled          *bool             `yaml:"enabled,omitempty"`           // defaults to true
+	Critical         *bool             `yaml:"critical,omitempty"`          // deprecated: overrides whether the check is skipped under memory pressure, which follows severity
+	Severity         string            `yaml:"severity,omitempty"`          // critical, warning or info: how a failure affects the overall status (default critical)
+	Weight           *float64          `yaml:"weight,omitempty"`            // contribution to the health score (default 1, 0 leaves the check out)
+	Labels           map[string]string `yaml:"labels,omitempty"`            // merged over the database labels
+	Vars             map[string]string `yaml:"vars,omitempty"`              // template variables merged over the database vars
+	Expect           Expectations      `yaml:"expect,omitempty"`            // assertions on the returned rows
+	MinRows          *int              `yaml:"min_rows,omitempty"`          // fewest rows the query may return
+	MaxRows          *int              `yaml:"max_rows,omitempty"`          // most rows the query may return
+	WarnLatency      Duration          `yaml:"warn_latency,omitempty"`      // query time above which the check is degraded
+	CriticalLatency  Duration          `yaml:"critical_latency,omitempty"`  // query time above which the check is unhealthy
+	CapturePlan      bool              `yaml:"capture_plan,omitempty"`      // attach the execution plan of checks above their latency thresholds
+	AnomalyStddevs   float64           `yaml:"anomaly_stddevs,omitempty"`   // standard deviations above the query time baseline at which the check is degraded
+	FailureThreshold int               `yaml:"failure_threshold,omitempty"` // consecutive failures before the published status fails (default 1)
+	SuccessThreshold int               `yaml:"success_threshold,omitempty"` // consecutive successes before it recovers (default 1)
+	AlertOnChange    bool              `yaml:"alert_on_change,omitempty"`   // fail scheduled checks whose data differs from the previous check
+	AllowWrites      bool              `yaml:"allow_writes,omitempty"`      // run queries that are not read-only
+	Metrics          map[string]string `yaml:"metrics,omitempty"`           // result columns published as Prometheus gauges: column to metric name
 }
 
 // Server represents HTTP server configuration
 type Server struct {
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	ReadTimeout  int    `yaml:"read_timeout"`
-	WriteTimeout int    `yaml:"write_timeout"`
-	IdleTimeout  int    `yaml:"idle_timeout"`
+	Host               string    `yaml:"host"`
+	Port               int       `yaml:"port"`        // 0 disables the TCP listener when a socket is set
+	Socket             string    `yaml:"socket"`      // unix socket path, served in addition to host:port
+	SocketMode         string    `yaml:"socket_mode"` // octal permissions of the socket (default "0660")
+	ReadTimeout        Duration  `yaml:"read_timeout"`
+	WriteTimeout       Duration  `yaml:"write_timeout"`
+	IdleTimeout        Duration  `yaml:"idle_timeout"`
+	RequestTimeout     Duration  `yaml:"request_timeout"`      // deadline of realtime checks (default 30s)
+	PingTimeout        Duration  `yaml:"ping_timeout"`         // deadline of /ping/{database} (default 10s)
+	BatchConcurrency   int       `yaml:"batch_concurrency"`    // checks run at once by POST /health/check (default 8)
+	DegradedStatusCode int       `yaml:"degraded_status_code"` // HTTP status of degraded responses (default 200)
+	UnknownStatusCode  int       `yaml:"unknown_status_code"`  // HTTP status of responses whose worst status is unknown (default 200)
+	CORS               CORS      `yaml:"cors"`
+	Auth               Auth      `yaml:"auth"`
+	TLS                TLS       `yaml:"tls"`
+	RateLimit          RateLimit `yaml:"rate_limit"`
+	Debug              Debug     `yaml:"debug"`
+}
+
+// Debug represents the net/http/pprof profiling endpoints. On a separate port
+// they are reachable without the API's authentication, so the listener binds
+// to loopback by default.
+type Debug struct {
+	Enabled bool   `yaml:"enabled"` // serve /debug/pprof
+	Host    string `yaml:"host"`    // debug listener host (default 127.0.0.1)
+	Port    int    `yaml:"port"`    // debug listener port; 0 serves the profiles on the API listener
+}
+
+// RateLimit represents per-client request rate limits. Requests are limited
+// by IP address before authentication, and by API key, user or token subject
+// once authenticated. Realtime requests, which query the databases directly,
+// count against both limits.
+type RateLimit struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"` // sustained rate of all requests; 0 disables
+	Burst             int     `yaml:"burst"`               // default: requests_per_second rounded up
+	RealtimePerSecond float64 `yaml:"realtime_per_second"` // sustained rate of realtime requests; 0 disables
+	RealtimeBurst     int     `yaml:"realtime_burst"`      // default: realtime_per_second rounded up
+}
+
+// TLS represents the certificate of the HTTP server. Certificates are
+// reloaded when the files change, so they can be rotated without a restart.
+type TLS struct {
+	CertFile     string `yaml:"cert_file"`      // PEM certificate chain; enables TLS when set
+	KeyFile      string `yaml:"key_file"`       // PEM private key
+	ClientCAFile string `yaml:"client_ca_file"` // PEM CA bundle; requires client certificates signed by it
+}
+
+// CORS represents the cross-origin resource sharing policy of the HTTP server.
+// Unset lists select permissive defaults.
+type CORS struct {
+	Enabled          *bool    `yaml:"enabled,omitempty"` // defaults to true
+	AllowedOrigins   []string `yaml:"allowed_origins"`   // "*" or scheme://host[:port] (default "*")
+	AllowedMethods   []string `yaml:"allowed_methods"`   // default GET, POST, OPTIONS
+	AllowedHeaders   []string `yaml:"allowed_headers"`   // request headers allowed in preflights
+	AllowCredentials bool     `yaml:"allow_credentials"` // requires explicit origins
+}
+
+// Auth represents HTTP API authentication. Requests must present one of the
+// API keys, the credentials of one of the users or an OIDC bearer token once
+// any of them is configured.
+type Auth struct {
+	Users       []AuthUser `yaml:"users"`
+	APIKeys     []APIKey   `yaml:"api_keys"`
+	OIDC        OIDC       `yaml:"oidc"`
+	ExemptPaths []string   `yaml:"exempt_paths"` // served without authentication, under /api/v1 too
+}
+
+// AuthUser is a user allowed to authenticate with HTTP basic auth
+type AuthUser struct {
+	Username     string `yaml:"username"`
+	PasswordHash string `yaml:"password_hash"` // bcrypt hash
+}
+
+// APIKey is a key accepted in the X-API-Key header
+type APIKey struct {
+	Name string `yaml:"name"` // identifies the key in logs
+	Key  string `yaml:"key"`
+}
+
+// OIDC represents bearer token authentication with JWTs issued by an OpenID
+// Connect provider
+type OIDC struct {
+	Issuer     string   `yaml:"issuer"`      // expected iss claim; enables OIDC when set
+	Audience   string   `yaml:"audience"`    // expected aud claim
+	JWKSURL    string   `yaml:"jwks_url"`    // signing keys; discovered from the issuer when empty
+	RolesClaim string   `yaml:"roles_claim"` // claim holding the token's roles, dots select nested claims (default "roles")
+	AdminRoles []string `yaml:"admin_roles"` // roles allowed to use /admin endpoints; any valid token when empty
+}
+
+// GRPC represents the optional gRPC health checking listener configuration
+type GRPC struct {
+	Enabled bool   `yaml:"enabled"`
+	Host    string `yaml:"host"`
+	Port    int    `yaml:"port"`
+}
+
+// DNS represents the optional DNS health responder configuration
+type DNS struct {
+	Enabled bool        `yaml:"enabled"`
+	Host    string      `yaml:"host"`
+	Port    int         `yaml:"port"`
+	TTL     int         `yaml:"ttl"` // TTL of answers in seconds
+	Records []DNSRecord `yaml:"records"`
+}
+
+// DNSRecord maps a DNS name to the addresses served while a database, or
+// every check of a group, is healthy
+type DNSRecord struct {
+	Name      string   `yaml:"name"`
+	Database  string   `yaml:"database"`
+	Group     string   `yaml:"group"`     // group served instead of a single database
+	Addresses []string `yaml:"addresses"` // IPv4 addresses returned for A queries
+	TXT       []string `yaml:"txt"`       // strings returned for TXT queries
+}
+
+// Watchdog represents the optional resource leak watchdog configuration.
+// Zero thresholds select defaults derived from the rest of the configuration.
+type Watchdog struct {
+	Enabled            bool     `yaml:"enabled"`
+	Interval           Duration `yaml:"interval"`             // sampling interval (default 30s)
+	MaxGoroutines      int      `yaml:"max_goroutines"`       // default 1000
+	MaxOpenConnections int      `yaml:"max_open_connections"` // default: pool size per enabled database
+	MaxCacheSize       int      `yaml:"max_cache_size"`       // default: number of configured checks
+}
+
+// Memory represents soft memory limit and load shedding configuration.
+// Load shedding is active whenever a limit is in effect, either from
+// soft_limit_mb or from the GOMEMLIMIT environment variable.
+type Memory struct {
+	SoftLimitMB       int `yaml:"soft_limit_mb"`      // sets the Go soft memory limit (0 = keep GOMEMLIMIT)
+	PressureThreshold int `yaml:"pressure_threshold"` // percent of the limit at which shedding starts (default 90)
+}
+
+// Scheduler represents the limits of the check scheduler
+type Scheduler struct {
+	MaxConcurrent int   `yaml:"max_concurrent"` // scheduled checks running at once (0 = unlimited)
+	Workers       int   `yaml:"workers"`        // goroutines running scheduled checks (default max_concurrent, or 64)
+	Stagger       *bool `yaml:"stagger"`        // defaults to true; spreads first runs across the check interval
+	StuckFactor   int   `yaml:"stuck_factor"`   // multiple of scheduled_check_timeout after which a running check is abandoned (default 2)
+}
+
+// GetStuckFactor returns the multiple of the scheduled check timeout after
+// which a check still running is abandoned as stuck, defaulting to 2
+func (s *Scheduler) GetStuckFactor() int {
+	if s.StuckFactor == 0 {
+		return 2
+	}
+	return s.StuckFactor
+}
+
+// IsStaggered reports whether the first runs of checks are spread across
+// their interval rather than all made at once
+func (s *Scheduler) IsStaggered() bool {
+	return s.Stagger == nil || *s.Stagger
+}
+
+// defaultSchedulerWorkers is the worker pool size without max_concurrent
+const defaultSchedulerWorkers = 64
+
+// GetWorkers returns the number of goroutines running scheduled checks. A
+// global limit needs no more workers than it lets run.
+func (s *Scheduler) GetWorkers() int {
+	if s.Workers > 0 {
+		return s.Workers
+	}
+	if s.MaxConcurrent > 0 {
+		return s.MaxConcurrent
+	}
+	return defaultSchedulerWorkers
+}
+
+// History represents the per-check result history configuration. History is
+// kept in memory by default; the other backends keep every result in a
+// database for the retention period instead. The postgres and mysql backends
+// write to an external database, which several instances can share.
+type History struct {
+	Size      int      `yaml:"size"`      // results kept per check in memory (default 1000)
+	Recent    int      `yaml:"recent"`    // latest results listed in the cached result of each check (default 0, none)
+	Events    int      `yaml:"events"`    // status transitions kept in memory for GET /events?since= (default 10000)
+	Backend   string   `yaml:"backend"`   // memory, sqlite, postgres or mysql (default memory)
+	Path      string   `yaml:"path"`      // database file of the sqlite backend
+	DSN       string   `yaml:"dsn"`       // connection string of the postgres and mysql backends
+	Table     string   `yaml:"table"`     // table results are written to (default gsqlhealth_history)
+	Instance  string   `yaml:"instance"`  // name recorded with each result (default the host name)
+	Retention Duration `yaml:"retention"` // how long results are kept in a database (default 30 days)
+
+	SLAMetricsWindow Duration `yaml:"sla_metrics_window"` // window of the SLA summaries on /metrics (default none)
+}
+
+// History backends
+const (
+	HistoryBackendMemory   = "memory"
+	HistoryBackendSQLite   = "sqlite"
+	HistoryBackendPostgres = "postgres"
+	HistoryBackendMySQL    = "mysql"
+)
+
+// historyTablePattern matches the table names accepted for history, which are
+// written into the statements unquoted
+var historyTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Flapping represents flap detection. A check whose status changes too often
+// within a rolling window is held in the "flapping" status, without status
+// change events, until it stabilizes.
+type Flapping struct {
+	Enabled       bool     `yaml:"enabled"`
+	Window        Duration `yaml:"window"`         // rolling window (default 1h)
+	HighThreshold int      `yaml:"high_threshold"` // status changes within the window that start flapping (default 10)
+	LowThreshold  int      `yaml:"low_threshold"`  // status changes within the window at or below which flapping stops (default half the high threshold)
+}
+
+// Scoring represents the weighted health score. When enabled, /health
+// reports a 0-100 score of the checks weighted by their weight, and derives
+// the overall status from it.
+type Scoring struct {
+	Enabled        bool    `yaml:"enabled"`
+	DegradedBelow  float64 `yaml:"degraded_below"`  // score below which the status is degraded (default 100)
+	UnhealthyBelow float64 `yaml:"unhealthy_below"` // score below which the status is unhealthy (default 50)
+}
+
+// Group represents a named set of checks that deploy pipelines gate on
+type Group struct {
+	Name      string            `yaml:"name"`
+	Databases []string          `yaml:"databases,omitempty"` // empty means all databases
+	Labels    map[string]string `yaml:"labels,omitempty"`    // checks must carry all of these labels
+}
+
+// MaintenanceWindow represents a recurring period during which failing checks
+// are reported as "maintenance" instead of affecting the overall status
+type MaintenanceWindow struct {
+	Schedule string   `yaml:"schedule"` // cron expression for the window start
+	Duration Duration `yaml:"duration"` // window length (seconds or duration string)
+	Timezone string   `yaml:"timezone"` // IANA time zone of the schedule (default UTC)
 }
 
+// Log outputs
+const (
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+	LogOutputFile   = "file"
+)
+
+// defaultLogMaxSize is the size in megabytes log files are rotated at
+const defaultLogMaxSize = 100
+
 // Logging represents logging configuration
 type Logging struct {
-	Level  string `yaml:"level"`
-	Format string `yaml:"format"`
+	Level      string `yaml:"level"`
+	Format     string `yaml:"format"`
+	Output     string `yaml:"output"`      // stdout, stderr or file (default stdout)
+	Path       string `yaml:"path"`        // log file of the file output
+	MaxSize    int    `yaml:"max_size"`    // megabytes written before the file is rotated (default 100)
+	MaxBackups int    `yaml:"max_backups"` // rotated files kept (default all)
+	MaxAge     int    `yaml:"max_age"`     // days rotated files are kept (default forever)
+	Compress   bool   `yaml:"compress"`    // gzip rotated files
+}
+
+// Validate validates logging configuration
+func (l *Logging) Validate() error {
+	switch l.Output {
+	case "", LogOutputStdout, LogOutputStderr:
+		if l.Path != "" {
+			return fmt.Errorf("path requires output file")
+		}
+	case LogOutputFile:
+		if l.Path == "" {
+			return fmt.Errorf("output file requires path")
+		}
+	default:
+		return fmt.Errorf("invalid output: %q (expected stdout, stderr or file)", l.Output)
+	}
+
+	if l.MaxSize < 0 || l.MaxBackups < 0 || l.MaxAge < 0 {
+		return fmt.Errorf("max_size, max_backups and max_age must not be negative")
+	}
+	return nil
+}
+
+// GetMaxSize returns the size in megabytes log files are rotated at
+func (l *Logging) GetMaxSize() int {
+	if l.MaxSize == 0 {
+		return defaultLogMaxSize
+	}
+	return l.MaxSize
 }
 
 // Retry represents connection retry configuration
 type Retry struct {
-	MaxAttempts     int `yaml:"max_attempts"`      // Maximum number of retry attempts (0 = infinite)
-	InitialDelay    int `yaml:"initial_delay"`     // Initial retry delay in seconds
-	MaxDelay        int `yaml:"max_delay"`         // Maximum retry delay in seconds
-	BackoffFactor   int `yaml:"backoff_factor"`    // Exponential backoff multiplier
-	ConnectionRetry int `yaml:"connection_retry"`  // Retry interval for connection recovery in seconds
+	MaxAttempts     int      `yaml:"max_attempts"`     // Maximum number of retry attempts (0 = infinite)
+	InitialDelay    Duration `yaml:"initial_delay"`    // Initial retry delay (seconds or duration string)
+	MaxDelay        Duration `yaml:"max_delay"`        // Maximum retry delay (seconds or duration string)
+	BackoffFactor   int      `yaml:"backoff_factor"`   // Exponential backoff multiplier
+	ConnectionRetry Duration `yaml:"connection_retry"` // Retry interval for connection recovery (seconds or duration string)
+	ConnectTimeout  Duration `yaml:"connect_timeout"`  // Deadline of each connection attempt (default 30s)
+	PingTimeout     Duration `yaml:"ping_timeout"`     // Deadline of the liveness ping of connected databases (default 5s)
+}
+
+// LoadConfig loads configuration from a YAML file or a remote source (see ReadSource)
+func LoadConfig(location string) (*Config, error) {
+	cfg, _, err := LoadConfigData(location)
+	return cfg, err
+}
+
+// LoadConfigData loads configuration like LoadConfig and also returns the raw
+// data it was parsed from, for watchers comparing the source with it
+func LoadConfigData(location string) (*Config, []byte, error) {
+	data, err := ReadSource(context.Background(), location)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	cfg, err := ParseConfig(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, data, nil
 }
 
-// LoadConfig loads configuration from a YAML file
-func LoadConfig(filename string) (*Config, error) {
-	data, err := os.ReadFile(filename)
+// ParseConfig decrypts, parses and validates raw configuration data
+func ParseConfig(data []byte) (*Config, error) {
+	// Decrypt SOPS or age encrypted configuration files
+	data, err := decryptConfig(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("failed to decrypt config file: %w", err)
 	}
 
 	var config Config
@@ -76,6 +477,19 @@ func LoadConfig(filename string) (*Config, error) {
 	// Set defaults for retry configuration
 	config.Retry.SetDefaults()
 
+	// Databases added through the admin API survive restarts and reloads
+	if err := config.loadDynamicDatabases(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Connection checks are scheduled like any other table
+	if err := config.applyConnectionChecks(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Fill in table timeouts and intervals from database and global defaults
+	config.applyTableDefaults()
+
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
@@ -83,9 +497,83 @@ func LoadConfig(filename string) (*Config, error) {
 	return &config, nil
 }
 
+// applyConnectionChecks adds the connection check of each database that
+// configures one or has no tables to its tables
+func (c *Config) applyConnectionChecks() error {
+	for i := range c.Databases {
+		db := &c.Databases[i]
+		if db.ConnectionCheck == nil && len(db.Tables) > 0 {
+			continue
+		}
+
+		var check Table
+		if db.ConnectionCheck != nil {
+			check = *db.ConnectionCheck
+		}
+		if check.Query != "" || check.Check != "" || len(check.Steps) > 0 {
+			return fmt.Errorf("database %s: connection_check runs no query of its own", db.Name)
+		}
+		if check.Name == "" {
+			check.Name = DefaultConnectionCheckName
+		}
+		check.Check = CheckConnection
+
+		for _, table := range db.Tables {
+			if table.Name == check.Name {
+				return fmt.Errorf("database %s: connection_check name %q is already used by a table", db.Name, check.Name)
+			}
+		}
+		db.Tables = append(db.Tables, check)
+	}
+	return nil
+}
+
+// applyTableDefaults sets unset table timeouts and check intervals from the
+// database defaults, falling back to the global defaults, and unset jitter
+// and max_result_age from the global ones
+func (c *Config) applyTableDefaults() {
+	for i := range c.Databases {
+		db := &c.Databases[i]
+
+		timeout := db.QueryTimeoutDefault
+		if timeout == 0 {
+			timeout = c.QueryTimeoutDefault
+		}
+		interval := db.CheckIntervalDefault
+		if interval == 0 {
+			interval = c.CheckIntervalDefault
+		}
+
+		for j := range db.Tables {
+			if db.Tables[j].Timeout == 0 {
+				db.Tables[j].Timeout = timeout
+			}
+			if db.Tables[j].CheckInterval == 0 {
+				db.Tables[j].CheckInterval = interval
+			}
+			if db.Tables[j].Jitter == nil {
+				jitter := c.Jitter
+				db.Tables[j].Jitter = &jitter
+			}
+			if db.Tables[j].MaxResultAge == 0 {
+				db.Tables[j].MaxResultAge = c.MaxResultAge
+			}
+		}
+	}
+}
+
+// GetScheduledCheckTimeout returns the deadline of a scheduled check, defaulting to 30 seconds
+func (c *Config) GetScheduledCheckTimeout() time.Duration {
+	if c.ScheduledCheckTimeout == 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.ScheduledCheckTimeout)
+}
+
 // Validate performs validation on the configuration
 func (c *Config) Validate() error {
-	if len(c.Databases) == 0 {
+	// With dynamic databases, all of them may be added at runtime
+	if len(c.Databases) == 0 && c.DynamicDatabases == "" {
 		return fmt.Errorf("at least one database must be configured")
 	}
 
@@ -99,10 +587,118 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("server configuration: %w", err)
 	}
 
+	// POST /admin/databases makes the server connect anywhere with the
+	// credentials it is given, so it is never open to anonymous clients
+	if c.DynamicDatabases != "" {
+		if !c.Server.Auth.IsEnabled() {
+			return fmt.Errorf("dynamic_databases requires server.auth, since /admin/databases accepts any connection settings")
+		}
+		for _, path := range c.Server.Auth.ExemptPaths {
+			if strings.HasPrefix(path, "/admin/databases") {
+				return fmt.Errorf("dynamic_databases requires authentication of %s; remove it from server.auth.exempt_paths", path)
+			}
+		}
+	}
+
+	if err := c.Logging.Validate(); err != nil {
+		return fmt.Errorf("logging configuration: %w", err)
+	}
+
+	if err := c.Audit.Validate(); err != nil {
+		return fmt.Errorf("audit configuration: %w", err)
+	}
+
 	if err := c.Retry.Validate(); err != nil {
 		return fmt.Errorf("retry configuration: %w", err)
 	}
 
+	if err := c.GRPC.Validate(); err != nil {
+		return fmt.Errorf("grpc configuration: %w", err)
+	}
+
+	if err := c.DNS.Validate(c.Databases, c.Groups); err != nil {
+		return fmt.Errorf("dns configuration: %w", err)
+	}
+
+	if err := c.MQTT.Validate(); err != nil {
+		return fmt.Errorf("mqtt configuration: %w", err)
+	}
+
+	if err := c.Consul.Validate(c.Server); err != nil {
+		return fmt.Errorf("consul configuration: %w", err)
+	}
+
+	if err := c.Zabbix.Validate(); err != nil {
+		return fmt.Errorf("zabbix configuration: %w", err)
+	}
+
+	if err := c.PassiveChecks.Validate(); err != nil {
+		return fmt.Errorf("passive checks configuration: %w", err)
+	}
+
+	if err := c.Pushgateway.Validate(); err != nil {
+		return fmt.Errorf("pushgateway configuration: %w", err)
+	}
+
+	for i, window := range c.MaintenanceWindows {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("maintenance window %d: %w", i, err)
+		}
+	}
+
+	if err := c.Watchdog.Validate(); err != nil {
+		return fmt.Errorf("watchdog configuration: %w", err)
+	}
+
+	if c.ScheduledCheckTimeout < 0 {
+		return fmt.Errorf("scheduled_check_timeout must not be negative")
+	}
+
+	if c.MaxResultAge < 0 {
+		return fmt.Errorf("max_result_age must not be negative")
+	}
+
+	if err := c.Memory.Validate(); err != nil {
+		return fmt.Errorf("memory configuration: %w", err)
+	}
+
+	if err := c.History.Validate(); err != nil {
+		return fmt.Errorf("history configuration: %w", err)
+	}
+
+	if err := c.Flapping.Validate(); err != nil {
+		return fmt.Errorf("flapping configuration: %w", err)
+	}
+
+	if err := c.Scoring.Validate(); err != nil {
+		return fmt.Errorf("scoring configuration: %w", err)
+	}
+
+	if err := c.Notifications.Validate(); err != nil {
+		return fmt.Errorf("notifications configuration: %w", err)
+	}
+
+	if c.Scheduler.MaxConcurrent < 0 {
+		return fmt.Errorf("scheduler configuration: max_concurrent must not be negative")
+	}
+	if c.Scheduler.Workers < 0 {
+		return fmt.Errorf("scheduler configuration: workers must not be negative")
+	}
+	if c.Scheduler.StuckFactor < 0 {
+		return fmt.Errorf("scheduler configuration: stuck_factor must not be negative")
+	}
+
+	names := make(map[string]bool, len(c.Groups))
+	for i, group := range c.Groups {
+		if err := group.Validate(c.Databases); err != nil {
+			return fmt.Errorf("group %d (%s): %w", i, group.Name, err)
+		}
+		if names[group.Name] {
+			return fmt.Errorf("group %d: duplicate name: %s", i, group.Name)
+		}
+		names[group.Name] = true
+	}
+
 	return nil
 }
 
@@ -133,26 +729,188 @@ func (d *Database) Validate() error {
 	}
 
 	if len(d.Tables) == 0 {
-		return fmt.Errorf("at least one table must be configured")
+		return fmt.Errorf("at least one table or connection_check must be configured")
+	}
+
+	if d.MaxConcurrentChecks < 0 {
+		return fmt.Errorf("max_concurrent_checks must not be negative")
+	}
+
+	if d.ActiveHours != "" {
+		if _, err := ParseActiveHours(d.ActiveHours); err != nil {
+			return err
+		}
+	}
+
+	if err := validateLabels(d.Labels); err != nil {
+		return err
+	}
+
+	for i, window := range d.MaintenanceWindows {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("maintenance window %d: %w", i, err)
+		}
 	}
 
 	for i, table := range d.Tables {
 		if err := table.Validate(); err != nil {
 			return fmt.Errorf("table %d (%s): %w", i, table.Name, err)
 		}
+		// Expanding the query once catches template errors and unknown vars
+		queries, err := d.RenderQueries(&table, time.Now())
+		if err != nil {
+			return fmt.Errorf("table %d (%s): invalid query template: %w", i, table.Name, err)
+		}
+		// Health checks run often and unattended, so writes must be intended
+		if !table.AllowWrites {
+			for _, query := range queries {
+				if keyword := writeStatement(d.Type, query); keyword != "" {
+					return fmt.Errorf("table %d (%s): query is not read-only (%s); set allow_writes to run it", i, table.Name, keyword)
+				}
+			}
+		}
+		if table.Check == CheckReplicationLag && d.Type != "mysql" && d.Type != "postgres" {
+			return fmt.Errorf("table %d (%s): check %s is not supported for %s", i, table.Name, table.Check, d.Type)
+		}
+		// Only SQL Server reports the size of the volumes holding its files
+		if table.Check == CheckStorage && d.Type != "mssql" && table.CapacityMB == 0 {
+			return fmt.Errorf("table %d (%s): check %s requires capacity_mb for %s", i, table.Name, table.Check, d.Type)
+		}
+	}
+
+	return nil
+}
+
+// IsEnabled reports whether checks for the database are enabled
+func (d *Database) IsEnabled() bool {
+	return d.Enabled == nil || *d.Enabled
+}
+
+// IsTableEnabled reports whether a table is checked, taking the database flag into account
+func (d *Database) IsTableEnabled(table *Table) bool {
+	return d.IsEnabled() && table.IsEnabled()
+}
+
+// TableLabels returns the effective labels of a table: the database labels
+// overridden by the table's own labels
+func (d *Database) TableLabels(table *Table) map[string]string {
+	if len(d.Labels) == 0 && len(table.Labels) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(d.Labels)+len(table.Labels))
+	for key, value := range d.Labels {
+		labels[key] = value
+	}
+	for key, value := range table.Labels {
+		labels[key] = value
 	}
+	return labels
+}
+
+// TableActiveHours returns the active hours of a table, defaulting to those
+// of the database; empty when its checks run around the clock
+func (d *Database) TableActiveHours(table *Table) string {
+	if table.ActiveHours != "" {
+		return table.ActiveHours
+	}
+	return d.ActiveHours
+}
 
+// validateLabels checks that label keys are usable in label selectors
+func validateLabels(labels map[string]string) error {
+	for key := range labels {
+		if key == "" || strings.ContainsAny(key, ":,") {
+			return fmt.Errorf("invalid label key: %q", key)
+		}
+	}
 	return nil
 }
 
+// isMetricName reports whether a name is a valid Prometheus metric name
+func isMetricName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // Validate validates table configuration
 func (t *Table) Validate() error {
 	if t.Name == "" {
 		return fmt.Errorf("table name is required")
 	}
 
-	if t.Query == "" {
-		return fmt.Errorf("table query is required")
+	switch t.Check {
+	case "":
+		if len(t.Steps) > 0 {
+			if err := t.validateSteps(); err != nil {
+				return err
+			}
+		} else if t.Query == "" {
+			return fmt.Errorf("table query is required")
+		}
+	case CheckRowCount:
+		if err := t.validateRowCount(); err != nil {
+			return err
+		}
+	case CheckFreshness:
+		if err := t.validateFreshness(); err != nil {
+			return err
+		}
+	case CheckReplicationLag:
+		if t.Query != "" {
+			return fmt.Errorf("table query must be empty for check %s", t.Check)
+		}
+		if t.MaxLag <= 0 {
+			return fmt.Errorf("max_lag must be positive")
+		}
+	case CheckStorage:
+		if err := t.validateStorage(); err != nil {
+			return err
+		}
+	case CheckConnections:
+		if err := t.validateConnections(); err != nil {
+			return err
+		}
+	case CheckBlocking:
+		if t.Query != "" {
+			return fmt.Errorf("table query must be empty for check %s", t.Check)
+		}
+		if t.MaxWait <= 0 {
+			return fmt.Errorf("max_wait must be positive")
+		}
+	case CheckLongQueries:
+		if t.Query != "" {
+			return fmt.Errorf("table query must be empty for check %s", t.Check)
+		}
+		if t.MaxDuration <= 0 {
+			return fmt.Errorf("max_duration must be positive")
+		}
+	case CheckDeadlocks:
+		if t.Query != "" {
+			return fmt.Errorf("table query must be empty for check %s", t.Check)
+		}
+		if t.MaxDeadlocks < 0 {
+			return fmt.Errorf("max_deadlocks must not be negative")
+		}
+	case CheckConnection:
+		if t.Query != "" {
+			return fmt.Errorf("table query must be empty for check %s", t.Check)
+		}
+	default:
+		return fmt.Errorf("unknown check type: %s", t.Check)
 	}
 
 	if t.Timeout <= 0 {
@@ -163,62 +921,967 @@ func (t *Table) Validate() error {
 		return fmt.Errorf("check_interval must be positive")
 	}
 
-	return nil
-}
+	if err := validateLabels(t.Labels); err != nil {
+		return err
+	}
 
-// Validate validates server configuration
-func (s *Server) Validate() error {
-	if s.Host == "" {
-		return fmt.Errorf("server host is required")
+	for i := range t.Expect {
+		if err := t.Expect[i].Validate(); err != nil {
+			return err
+		}
 	}
 
-	if s.Port <= 0 || s.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", s.Port)
+	if t.MinRows != nil && *t.MinRows < 0 {
+		return fmt.Errorf("min_rows must not be negative")
 	}
 
-	if s.ReadTimeout <= 0 {
-		return fmt.Errorf("read timeout must be positive")
+	if t.MaxRows != nil && *t.MaxRows < 0 {
+		return fmt.Errorf("max_rows must not be negative")
 	}
 
-	if s.WriteTimeout <= 0 {
-		return fmt.Errorf("write timeout must be positive")
+	if t.MinRows != nil && t.MaxRows != nil && *t.MaxRows < *t.MinRows {
+		return fmt.Errorf("max_rows must be at least min_rows")
 	}
 
-	if s.IdleTimeout <= 0 {
-		return fmt.Errorf("idle timeout must be positive")
+	if t.WarnLatency < 0 || t.CriticalLatency < 0 {
+		return fmt.Errorf("warn_latency and critical_latency must not be negative")
 	}
 
-	return nil
-}
+	if t.WarnLatency > 0 && t.CriticalLatency > 0 && t.CriticalLatency < t.WarnLatency {
+		return fmt.Errorf("critical_latency must be at least warn_latency")
+	}
 
-// GetAddress returns the server address in host:port format
-func (s *Server) GetAddress() string {
-	return fmt.Sprintf("%s:%d", s.Host, s.Port)
-}
+	for column, metric := range t.Metrics {
+		if column == "" || !isMetricName(metric) {
+			return fmt.Errorf("invalid metric for column %q: %q", column, metric)
+		}
+	}
 
-// GetReadTimeout returns read timeout as time.Duration
-func (s *Server) GetReadTimeout() time.Duration {
-	return time.Duration(s.ReadTimeout) * time.Second
-}
+	if t.RecheckInterval < 0 {
+		return fmt.Errorf("recheck_interval must not be negative")
+	}
 
-// GetWriteTimeout returns write timeout as time.Duration
-func (s *Server) GetWriteTimeout() time.Duration {
-	return time.Duration(s.WriteTimeout) * time.Second
-}
+	if t.MaxResultAge < 0 {
+		return fmt.Errorf("max_result_age must not be negative")
+	}
+	if t.MaxResultAge > 0 && t.MaxResultAge < t.CheckInterval {
+		return fmt.Errorf("max_result_age must be at least check_interval")
+	}
 
-// GetIdleTimeout returns idle timeout as time.Duration
-func (s *Server) GetIdleTimeout() time.Duration {
-	return time.Duration(s.IdleTimeout) * time.Second
-}
+	if t.ActiveHours != "" {
+		if _, err := ParseActiveHours(t.ActiveHours); err != nil {
+			return err
+		}
+	}
 
-// GetQueryTimeout returns query timeout as time.Duration
-func (t *Table) GetQueryTimeout() time.Duration {
-	return time.Duration(t.Timeout) * time.Second
+	if jitter := t.GetJitter(); jitter < 0 || jitter > 0.5 {
+		return fmt.Errorf("jitter must be between 0%% and 50%%")
+	}
+
+	if t.AnomalyStddevs < 0 {
+		return fmt.Errorf("anomaly_stddevs must not be negative")
+	}
+
+	if t.CapturePlan {
+		if t.Check != "" || len(t.Steps) > 0 {
+			return fmt.Errorf("capture_plan requires a query")
+		}
+		if t.WarnLatency == 0 && t.CriticalLatency == 0 {
+			return fmt.Errorf("capture_plan requires warn_latency or critical_latency")
+		}
+	}
+
+	if t.FailureThreshold < 0 || t.SuccessThreshold < 0 {
+		return fmt.Errorf("failure_threshold and success_threshold must not be negative")
+	}
+
+	if t.Weight != nil && *t.Weight < 0 {
+		return fmt.Errorf("weight must not be negative")
+	}
+
+	switch t.GetSeverity() {
+	case SeverityCritical, SeverityWarning, SeverityInfo:
+	default:
+		return fmt.Errorf("invalid severity: %s (must be critical, warning or info)", t.Severity)
+	}
+
+	return nil
+}
+
+// Validate validates server configuration
+func (s *Server) Validate() error {
+	if s.Port == 0 && s.Socket == "" {
+		return fmt.Errorf("server port or socket is required")
+	}
+
+	if s.Port != 0 && s.Host == "" {
+		return fmt.Errorf("server host is required")
+	}
+
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid server port: %d", s.Port)
+	}
+
+	if s.SocketMode != "" {
+		if _, err := strconv.ParseUint(s.SocketMode, 8, 32); err != nil {
+			return fmt.Errorf("invalid socket mode %q, expected octal permissions such as \"0660\"", s.SocketMode)
+		}
+	}
+
+	if s.ReadTimeout <= 0 {
+		return fmt.Errorf("read timeout must be positive")
+	}
+
+	if s.WriteTimeout <= 0 {
+		return fmt.Errorf("write timeout must be positive")
+	}
+
+	if s.IdleTimeout <= 0 {
+		return fmt.Errorf("idle timeout must be positive")
+	}
+
+	if s.RequestTimeout < 0 {
+		return fmt.Errorf("request timeout must not be negative")
+	}
+
+	if s.PingTimeout < 0 {
+		return fmt.Errorf("ping timeout must not be negative")
+	}
+
+	if s.BatchConcurrency < 0 {
+		return fmt.Errorf("batch concurrency must not be negative")
+	}
+
+	if s.DegradedStatusCode != 0 && (s.DegradedStatusCode < 200 || s.DegradedStatusCode > 599) {
+		return fmt.Errorf("invalid degraded status code: %d", s.DegradedStatusCode)
+	}
+
+	if s.UnknownStatusCode != 0 && (s.UnknownStatusCode < 200 || s.UnknownStatusCode > 599) {
+		return fmt.Errorf("invalid unknown status code: %d", s.UnknownStatusCode)
+	}
+
+	if err := s.CORS.Validate(); err != nil {
+		return fmt.Errorf("cors: %w", err)
+	}
+
+	if err := s.Auth.Validate(); err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+
+	if err := s.TLS.Validate(); err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+
+	if err := s.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("rate_limit: %w", err)
+	}
+
+	if s.Debug.Port < 0 || s.Debug.Port > 65535 {
+		return fmt.Errorf("debug: invalid port: %d", s.Debug.Port)
+	}
+
+	if s.Debug.Enabled && s.Debug.Port != 0 && s.Debug.Port == s.Port {
+		return fmt.Errorf("debug: port must differ from the server port")
+	}
+
+	return nil
+}
+
+// Validate validates CORS configuration
+func (c *CORS) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return fmt.Errorf("allow_credentials requires explicit allowed_origins, not \"*\"")
+			}
+			continue
+		}
+
+		scheme, host, found := strings.Cut(origin, "://")
+		if !found || (scheme != "http" && scheme != "https") || host == "" || strings.ContainsAny(host, "/?#") {
+			return fmt.Errorf("invalid allowed origin %q, expected scheme://host[:port]", origin)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates authentication configuration
+func (a *Auth) Validate() error {
+	usernames := make(map[string]bool, len(a.Users))
+	for i, user := range a.Users {
+		if user.Username == "" {
+			return fmt.Errorf("user %d: username is required", i)
+		}
+		if usernames[user.Username] {
+			return fmt.Errorf("user %d: duplicate username: %s", i, user.Username)
+		}
+		usernames[user.Username] = true
+
+		if _, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil {
+			return fmt.Errorf("user %s: password_hash must be a bcrypt hash: %w", user.Username, err)
+		}
+	}
+
+	for i, key := range a.APIKeys {
+		if key.Name == "" {
+			return fmt.Errorf("api key %d: name is required", i)
+		}
+		if len(key.Key) < minAPIKeyLength {
+			return fmt.Errorf("api key %s: key must be at least %d characters", key.Name, minAPIKeyLength)
+		}
+	}
+
+	if err := a.OIDC.Validate(); err != nil {
+		return fmt.Errorf("oidc: %w", err)
+	}
+
+	for _, path := range a.ExemptPaths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("exempt path %q must start with /", path)
+		}
+	}
+
+	return nil
+}
+
+// IsEnabled reports whether requests must authenticate
+func (a *Auth) IsEnabled() bool {
+	return len(a.Users) > 0 || len(a.APIKeys) > 0 || a.OIDC.IsEnabled()
+}
+
+// Validate validates TLS configuration
+func (t *TLS) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("cert_file and key_file must be set together")
+	}
+	if t.ClientCAFile != "" && !t.IsEnabled() {
+		return fmt.Errorf("client_ca_file requires cert_file and key_file")
+	}
+	return nil
+}
+
+// IsEnabled reports whether the HTTP server serves TLS
+func (t *TLS) IsEnabled() bool {
+	return t.CertFile != ""
+}
+
+// Validate validates rate limit configuration
+func (r *RateLimit) Validate() error {
+	if r.RequestsPerSecond < 0 || r.RealtimePerSecond < 0 {
+		return fmt.Errorf("rates must not be negative")
+	}
+	if r.Burst < 0 || r.RealtimeBurst < 0 {
+		return fmt.Errorf("bursts must not be negative")
+	}
+	return nil
+}
+
+// IsEnabled reports whether any rate limit is configured
+func (r *RateLimit) IsEnabled() bool {
+	return r.RequestsPerSecond > 0 || r.RealtimePerSecond > 0
+}
+
+// GetBurst returns the burst of all requests, defaulting to the rate rounded up
+func (r *RateLimit) GetBurst() int {
+	return defaultBurst(r.Burst, r.RequestsPerSecond)
+}
+
+// GetRealtimeBurst returns the burst of realtime requests, defaulting to the
+// rate rounded up
+func (r *RateLimit) GetRealtimeBurst() int {
+	return defaultBurst(r.RealtimeBurst, r.RealtimePerSecond)
+}
+
+// defaultBurst returns burst if set, otherwise the rate rounded up to at least one
+func defaultBurst(burst int, rate float64) int {
+	if burst > 0 {
+		return burst
+	}
+	if rounded := int(math.Ceil(rate)); rounded > 1 {
+		return rounded
+	}
+	return 1
+}
+
+// Validate validates OIDC configuration
+func (o *OIDC) Validate() error {
+	if !o.IsEnabled() {
+		if o.Audience != "" || o.JWKSURL != "" || len(o.AdminRoles) > 0 {
+			return fmt.Errorf("issuer is required")
+		}
+		return nil
+	}
+
+	if !isHTTPURL(o.Issuer) {
+		return fmt.Errorf("invalid issuer %q, expected an http(s) URL", o.Issuer)
+	}
+	if o.Audience == "" {
+		return fmt.Errorf("audience is required")
+	}
+	if o.JWKSURL != "" && !isHTTPURL(o.JWKSURL) {
+		return fmt.Errorf("invalid jwks_url %q, expected an http(s) URL", o.JWKSURL)
+	}
+
+	return nil
+}
+
+// IsEnabled reports whether OIDC bearer tokens are accepted
+func (o *OIDC) IsEnabled() bool {
+	return o.Issuer != ""
+}
+
+// GetRolesClaim returns the claim holding the token's roles, defaulting to "roles"
+func (o *OIDC) GetRolesClaim() string {
+	if o.RolesClaim == "" {
+		return "roles"
+	}
+	return o.RolesClaim
+}
+
+// isHTTPURL reports whether value is an absolute http or https URL
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// IsEnabled reports whether CORS headers are sent
+func (c *CORS) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// GetAllowedOrigins returns the allowed origins, defaulting to any origin
+func (c *CORS) GetAllowedOrigins() []string {
+	if len(c.AllowedOrigins) == 0 {
+		return []string{"*"}
+	}
+	return c.AllowedOrigins
+}
+
+// GetAllowedMethods returns the allowed methods, defaulting to GET, POST and OPTIONS
+func (c *CORS) GetAllowedMethods() []string {
+	if len(c.AllowedMethods) == 0 {
+		return []string{"GET", "POST", "OPTIONS"}
+	}
+	return c.AllowedMethods
+}
+
+// GetAllowedHeaders returns the allowed request headers, defaulting to those the API uses
+func (c *CORS) GetAllowedHeaders() []string {
+	if len(c.AllowedHeaders) == 0 {
+		return []string{"Content-Type", "Authorization", "If-None-Match", "If-Modified-Since", "X-API-Key", "X-Request-ID"}
+	}
+	return c.AllowedHeaders
+}
+
+// GetAddress returns the server address in host:port format
+func (s *Server) GetAddress() string {
+	return fmt.Sprintf("%s:%d", s.Host, s.Port)
+}
+
+// GetHost returns the debug listener host, defaulting to loopback
+func (d *Debug) GetHost() string {
+	if d.Host == "" {
+		return "127.0.0.1"
+	}
+	return d.Host
+}
+
+// GetAddress returns the debug listener address in host:port format
+func (d *Debug) GetAddress() string {
+	return fmt.Sprintf("%s:%d", d.GetHost(), d.Port)
+}
+
+// GetSocketMode returns the permissions of the unix socket, defaulting to 0660
+func (s *Server) GetSocketMode() os.FileMode {
+	mode, err := strconv.ParseUint(s.SocketMode, 8, 32)
+	if err != nil {
+		return 0o660
+	}
+	return os.FileMode(mode)
+}
+
+// GetReadTimeout returns read timeout as time.Duration
+func (s *Server) GetReadTimeout() time.Duration {
+	return time.Duration(s.ReadTimeout)
+}
+
+// GetWriteTimeout returns write timeout as time.Duration
+func (s *Server) GetWriteTimeout() time.Duration {
+	return time.Duration(s.WriteTimeout)
+}
+
+// GetIdleTimeout returns idle timeout as time.Duration
+func (s *Server) GetIdleTimeout() time.Duration {
+	return time.Duration(s.IdleTimeout)
+}
+
+// GetRequestTimeout returns the realtime check deadline, defaulting to 30 seconds
+func (s *Server) GetRequestTimeout() time.Duration {
+	if s.RequestTimeout == 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(s.RequestTimeout)
+}
+
+// GetPingTimeout returns the /ping/{database} deadline, defaulting to 10 seconds
+func (s *Server) GetPingTimeout() time.Duration {
+	if s.PingTimeout == 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(s.PingTimeout)
+}
+
+// GetBatchConcurrency returns how many checks of a batch run at once, defaulting to 8
+func (s *Server) GetBatchConcurrency() int {
+	if s.BatchConcurrency == 0 {
+		return 8
+	}
+	return s.BatchConcurrency
+}
+
+// GetDegradedStatusCode returns the HTTP status code of responses whose worst
+// status is degraded, defaulting to 200
+func (s *Server) GetDegradedStatusCode() int {
+	if s.DegradedStatusCode == 0 {
+		return 200
+	}
+	return s.DegradedStatusCode
+}
+
+// GetUnknownStatusCode returns the HTTP status code of responses whose worst
+// status is unknown, defaulting to 200
+func (s *Server) GetUnknownStatusCode() int {
+	if s.UnknownStatusCode == 0 {
+		return 200
+	}
+	return s.UnknownStatusCode
+}
+
+// Validate validates gRPC configuration
+func (g *GRPC) Validate() error {
+	if !g.Enabled {
+		return nil
+	}
+
+	if g.Host == "" {
+		return fmt.Errorf("grpc host is required")
+	}
+
+	if g.Port <= 0 || g.Port > 65535 {
+		return fmt.Errorf("invalid grpc port: %d", g.Port)
+	}
+
+	return nil
+}
+
+// GetAddress returns the gRPC listener address in host:port format
+func (g *GRPC) GetAddress() string {
+	return fmt.Sprintf("%s:%d", g.Host, g.Port)
+}
+
+// Validate validates DNS responder configuration against the configured databases and groups
+func (d *DNS) Validate(databases []Database, groups []Group) error {
+	if !d.Enabled {
+		return nil
+	}
+
+	if d.Host == "" {
+		return fmt.Errorf("dns host is required")
+	}
+
+	if d.Port <= 0 || d.Port > 65535 {
+		return fmt.Errorf("invalid dns port: %d", d.Port)
+	}
+
+	if d.TTL < 0 {
+		return fmt.Errorf("dns ttl must not be negative")
+	}
+
+	if len(d.Records) == 0 {
+		return fmt.Errorf("at least one dns record must be configured")
+	}
+
+	known := make(map[string]bool, len(databases))
+	for _, db := range databases {
+		known[db.Name] = true
+	}
+
+	knownGroups := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		knownGroups[group.Name] = true
+	}
+
+	for i, record := range d.Records {
+		if record.Name == "" {
+			return fmt.Errorf("record %d: name is required", i)
+		}
+
+		switch {
+		case record.Database != "" && record.Group != "":
+			return fmt.Errorf("record %d (%s): database and group are mutually exclusive", i, record.Name)
+		case record.Group != "":
+			if !knownGroups[record.Group] {
+				return fmt.Errorf("record %d (%s): unknown group: %s", i, record.Name, record.Group)
+			}
+		case !known[record.Database]:
+			return fmt.Errorf("record %d (%s): unknown database: %s", i, record.Name, record.Database)
+		}
+
+		if len(record.Addresses) == 0 && len(record.TXT) == 0 {
+			return fmt.Errorf("record %d (%s): addresses or txt is required", i, record.Name)
+		}
+
+		for _, address := range record.Addresses {
+			ip := net.ParseIP(address)
+			if ip == nil || ip.To4() == nil {
+				return fmt.Errorf("record %d (%s): invalid IPv4 address: %s", i, record.Name, address)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetAddress returns the DNS listener address in host:port format
+func (d *DNS) GetAddress() string {
+	return fmt.Sprintf("%s:%d", d.Host, d.Port)
+}
+
+// GetTTL returns the answer TTL, defaulting to 30 seconds when unset
+func (d *DNS) GetTTL() uint32 {
+	if d.TTL == 0 {
+		return 30
+	}
+	return uint32(d.TTL)
+}
+
+// IsEnabled reports whether checks for the table are enabled
+func (t *Table) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+// Validate validates watchdog configuration
+func (w *Watchdog) Validate() error {
+	if !w.Enabled {
+		return nil
+	}
+
+	if w.Interval < 0 {
+		return fmt.Errorf("interval must not be negative")
+	}
+
+	if w.MaxGoroutines < 0 || w.MaxOpenConnections < 0 || w.MaxCacheSize < 0 {
+		return fmt.Errorf("thresholds must not be negative")
+	}
+
+	return nil
+}
+
+// GetInterval returns the sampling interval, defaulting to 30 seconds
+func (w *Watchdog) GetInterval() time.Duration {
+	if w.Interval == 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(w.Interval)
+}
+
+// GetMaxGoroutines returns the goroutine threshold, defaulting to 1000
+func (w *Watchdog) GetMaxGoroutines() int {
+	if w.MaxGoroutines == 0 {
+		return 1000
+	}
+	return w.MaxGoroutines
+}
+
+// Validate validates memory configuration
+func (m *Memory) Validate() error {
+	if m.SoftLimitMB < 0 {
+		return fmt.Errorf("soft_limit_mb must not be negative")
+	}
+
+	if m.PressureThreshold < 0 || m.PressureThreshold > 100 {
+		return fmt.Errorf("pressure_threshold must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// GetSoftLimit returns the configured soft limit in bytes, or 0 when unset
+func (m *Memory) GetSoftLimit() int64 {
+	return int64(m.SoftLimitMB) << 20
+}
+
+// GetPressureThreshold returns the shedding threshold as a fraction of the limit
+func (m *Memory) GetPressureThreshold() float64 {
+	if m.PressureThreshold == 0 {
+		return 0.9
+	}
+	return float64(m.PressureThreshold) / 100
+}
+
+// Validate validates history configuration
+func (h *History) Validate() error {
+	if h.Size < 0 {
+		return fmt.Errorf("size must not be negative")
+	}
+	if h.Recent < 0 {
+		return fmt.Errorf("recent must not be negative")
+	}
+	if h.Events < 0 {
+		return fmt.Errorf("events must not be negative")
+	}
+
+	switch h.GetBackend() {
+	case HistoryBackendMemory:
+	case HistoryBackendSQLite:
+		if h.Path == "" {
+			return fmt.Errorf("path is required for the sqlite backend")
+		}
+	case HistoryBackendPostgres, HistoryBackendMySQL:
+		if h.DSN == "" {
+			return fmt.Errorf("dsn is required for the %s backend", h.Backend)
+		}
+	default:
+		return fmt.Errorf("unsupported backend %q, expected memory, sqlite, postgres or mysql", h.Backend)
+	}
+
+	if !historyTablePattern.MatchString(h.GetTable()) {
+		return fmt.Errorf("invalid table %q: use letters, digits and underscores", h.Table)
+	}
+
+	if h.Retention < 0 {
+		return fmt.Errorf("retention must not be negative")
+	}
+	if h.SLAMetricsWindow < 0 {
+		return fmt.Errorf("sla_metrics_window must not be negative")
+	}
+	return nil
+}
+
+// GetSize returns the number of results kept per check, defaulting to 1000
+func (h *History) GetSize() int {
+	if h.Size == 0 {
+		return 1000
+	}
+	return h.Size
+}
+
+// GetEvents returns the number of status transitions kept, defaulting to
+// 10000
+func (h *History) GetEvents() int {
+	if h.Events == 0 {
+		return 10000
+	}
+	return h.Events
+}
+
+// GetBackend returns the history backend, defaulting to memory
+func (h *History) GetBackend() string {
+	if h.Backend == "" {
+		return HistoryBackendMemory
+	}
+	return h.Backend
+}
+
+// GetTable returns the table results are written to, defaulting to
+// gsqlhealth_history
+func (h *History) GetTable() string {
+	if h.Table == "" {
+		return "gsqlhealth_history"
+	}
+	return h.Table
+}
+
+// GetInstance returns the name recorded with each result, defaulting to the
+// host name
+func (h *History) GetInstance() string {
+	if h.Instance != "" {
+		return h.Instance
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// GetRetention returns how long results are kept in a database, defaulting
+// to 30 days
+func (h *History) GetRetention() time.Duration {
+	if h.Retention == 0 {
+		return 30 * 24 * time.Hour
+	}
+	return time.Duration(h.Retention)
+}
+
+// Validate validates flap detection configuration
+func (f *Flapping) Validate() error {
+	if !f.Enabled {
+		return nil
+	}
+
+	if f.Window < 0 {
+		return fmt.Errorf("window must not be negative")
+	}
+
+	if f.HighThreshold < 0 || f.LowThreshold < 0 {
+		return fmt.Errorf("thresholds must not be negative")
+	}
+
+	if f.GetLowThreshold() >= f.GetHighThreshold() {
+		return fmt.Errorf("low_threshold must be below high_threshold")
+	}
+
+	return nil
+}
+
+// GetWindow returns the rolling window of flap detection, defaulting to 1 hour
+func (f *Flapping) GetWindow() time.Duration {
+	if f.Window == 0 {
+		return time.Hour
+	}
+	return time.Duration(f.Window)
+}
+
+// GetHighThreshold returns the status changes within the window that start
+// flapping, defaulting to 10
+func (f *Flapping) GetHighThreshold() int {
+	if f.HighThreshold == 0 {
+		return 10
+	}
+	return f.HighThreshold
+}
+
+// GetLowThreshold returns the status changes within the window at or below
+// which flapping stops, defaulting to half the high threshold
+func (f *Flapping) GetLowThreshold() int {
+	if f.LowThreshold == 0 {
+		return f.GetHighThreshold() / 2
+	}
+	return f.LowThreshold
+}
+
+// Validate validates scoring configuration
+func (s *Scoring) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+
+	if s.DegradedBelow < 0 || s.DegradedBelow > 100 || s.UnhealthyBelow < 0 || s.UnhealthyBelow > 100 {
+		return fmt.Errorf("thresholds must be scores between 0 and 100")
+	}
+
+	if s.GetUnhealthyBelow() > s.GetDegradedBelow() {
+		return fmt.Errorf("unhealthy_below must not be above degraded_below")
+	}
+
+	return nil
+}
+
+// GetDegradedBelow returns the score below which the status is degraded,
+// defaulting to 100 so that any failing check degrades the status
+func (s *Scoring) GetDegradedBelow() float64 {
+	if s.DegradedBelow == 0 {
+		return 100
+	}
+	return s.DegradedBelow
+}
+
+// GetUnhealthyBelow returns the score below which the status is unhealthy,
+// defaulting to 50
+func (s *Scoring) GetUnhealthyBelow() float64 {
+	if s.UnhealthyBelow == 0 {
+		return 50
+	}
+	return s.UnhealthyBelow
+}
+
+// Validate validates group configuration
+func (g *Group) Validate(databases []Database) error {
+	if g.Name == "" {
+		return fmt.Errorf("group name is required")
+	}
+
+	known := make(map[string]bool, len(databases))
+	for _, db := range databases {
+		known[db.Name] = true
+	}
+
+	for _, name := range g.Databases {
+		if !known[name] {
+			return fmt.Errorf("unknown database: %s", name)
+		}
+	}
+
+	return validateLabels(g.Labels)
+}
+
+// Contains reports whether a check of a database with the given labels belongs to the group
+func (g *Group) Contains(databaseName string, labels map[string]string) bool {
+	if len(g.Databases) > 0 {
+		found := false
+		for _, name := range g.Databases {
+			if name == databaseName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for key, value := range g.Labels {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// GetGroup returns the group with the given name, or nil if none is configured
+func (c *Config) GetGroup(name string) *Group {
+	for i := range c.Groups {
+		if c.Groups[i].Name == name {
+			return &c.Groups[i]
+		}
+	}
+	return nil
+}
+
+// Validate validates maintenance window configuration
+func (m *MaintenanceWindow) Validate() error {
+	if _, err := cron.Parse(m.Schedule); err != nil {
+		return err
+	}
+
+	if m.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+
+	if m.GetDuration() > maxMaintenanceWindow {
+		return fmt.Errorf("duration must not exceed %s", maxMaintenanceWindow)
+	}
+
+	if _, err := m.GetLocation(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetDuration returns the window length as time.Duration
+func (m *MaintenanceWindow) GetDuration() time.Duration {
+	return time.Duration(m.Duration)
+}
+
+// GetLocation returns the time zone the schedule is evaluated in
+func (m *MaintenanceWindow) GetLocation() (*time.Location, error) {
+	if m.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(m.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", m.Timezone, err)
+	}
+	return location, nil
+}
+
+// IsCritical reports whether failures of the check have severity critical
+func (t *Table) IsCritical() bool {
+	return t.GetSeverity() == SeverityCritical
+}
+
+// IsSheddable reports whether the check is skipped under memory pressure:
+// checks of severity warning or info, unless the deprecated critical option
+// says otherwise
+func (t *Table) IsSheddable() bool {
+	if t.Critical != nil {
+		return !*t.Critical
+	}
+	return !t.IsCritical()
+}
+
+// Severities of a check's failures
+const (
+	// SeverityCritical failures make the database and overall status unhealthy
+	SeverityCritical = "critical"
+	// SeverityWarning failures make them degraded at worst
+	SeverityWarning = "warning"
+	// SeverityInfo failures are reported without affecting them
+	SeverityInfo = "info"
+)
+
+// GetSeverity returns the severity of the check's failures, defaulting to critical
+func (t *Table) GetSeverity() string {
+	if t.Severity == "" {
+		return SeverityCritical
+	}
+	return t.Severity
+}
+
+// GetJitter returns the random spread of the check interval as a fraction of
+// it, 0 if unset
+func (t *Table) GetJitter() float64 {
+	if t.Jitter == nil {
+		return 0
+	}
+	return t.Jitter.Fraction()
+}
+
+// GetWeight returns the contribution of the check to the health score,
+// defaulting to 1
+func (t *Table) GetWeight() float64 {
+	if t.Weight == nil {
+		return 1
+	}
+	return *t.Weight
+}
+
+// GetQueryTimeout returns query timeout as time.Duration
+func (t *Table) GetQueryTimeout() time.Duration {
+	return time.Duration(t.Timeout)
+}
+
+// GetRecheckInterval returns the interval of the check while it is failing,
+// defaulting to the check interval
+func (t *Table) GetRecheckInterval() time.Duration {
+	if t.RecheckInterval == 0 {
+		return t.GetCheckInterval()
+	}
+	return time.Duration(t.RecheckInterval)
 }
 
 // GetCheckInterval returns check interval as time.Duration
 func (t *Table) GetCheckInterval() time.Duration {
-	return time.Duration(t.CheckInterval) * time.Second
+	return time.Duration(t.CheckInterval)
+}
+
+// GetWarnLatency returns the degraded query time threshold, 0 if unset
+func (t *Table) GetWarnLatency() time.Duration {
+	return time.Duration(t.WarnLatency)
+}
+
+// GetCriticalLatency returns the unhealthy query time threshold, 0 if unset
+func (t *Table) GetCriticalLatency() time.Duration {
+	return time.Duration(t.CriticalLatency)
+}
+
+// GetFailureThreshold returns how many consecutive failures change the
+// published status of a healthy check, defaulting to 1
+func (t *Table) GetFailureThreshold() int {
+	if t.FailureThreshold == 0 {
+		return 1
+	}
+	return t.FailureThreshold
+}
+
+// GetSuccessThreshold returns how many consecutive successes change the
+// published status of a failing check, defaulting to 1
+func (t *Table) GetSuccessThreshold() int {
+	if t.SuccessThreshold == 0 {
+		return 1
+	}
+	return t.SuccessThreshold
 }
 
 // Validate validates retry configuration
@@ -247,31 +1910,57 @@ func (r *Retry) Validate() error {
 		return fmt.Errorf("connection_retry must be positive")
 	}
 
+	if r.ConnectTimeout < 0 {
+		return fmt.Errorf("connect_timeout cannot be negative")
+	}
+
+	if r.PingTimeout < 0 {
+		return fmt.Errorf("ping_timeout cannot be negative")
+	}
+
 	return nil
 }
 
 // GetInitialDelay returns initial delay as time.Duration
 func (r *Retry) GetInitialDelay() time.Duration {
-	return time.Duration(r.InitialDelay) * time.Second
+	return time.Duration(r.InitialDelay)
 }
 
 // GetMaxDelay returns max delay as time.Duration
 func (r *Retry) GetMaxDelay() time.Duration {
-	return time.Duration(r.MaxDelay) * time.Second
+	return time.Duration(r.MaxDelay)
 }
 
 // GetConnectionRetry returns connection retry interval as time.Duration
 func (r *Retry) GetConnectionRetry() time.Duration {
-	return time.Duration(r.ConnectionRetry) * time.Second
+	return time.Duration(r.ConnectionRetry)
+}
+
+// GetConnectTimeout returns the deadline of a connection attempt, defaulting
+// to 30 seconds
+func (r *Retry) GetConnectTimeout() time.Duration {
+	if r.ConnectTimeout == 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(r.ConnectTimeout)
+}
+
+// GetPingTimeout returns the deadline of the liveness ping of a connected
+// database, defaulting to 5 seconds
+func (r *Retry) GetPingTimeout() time.Duration {
+	if r.PingTimeout == 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(r.PingTimeout)
 }
 
 // SetDefaults sets default retry values if not specified
 func (r *Retry) SetDefaults() {
 	if r.MaxAttempts == 0 && r.InitialDelay == 0 {
 		r.MaxAttempts = 0 // infinite retries
-		r.InitialDelay = 5
-		r.MaxDelay = 60
+		r.InitialDelay = Duration(5 * time.Second)
+		r.MaxDelay = Duration(60 * time.Second)
 		r.BackoffFactor = 2
-		r.ConnectionRetry = 30
+		r.ConnectionRetry = Duration(30 * time.Second)
 	}
-}
\ No newline at end of file
+}
@@ -1,9 +1,22 @@
 package config
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -97,23 +110,23 @@ func TestConfigValidation(t *testing.T) {
 						Password: "pass",
 						Database: "db",
 						Tables: []Table{
-							{Name: "table1", Query: "SELECT 1", Timeout: 5, CheckInterval: 30},
+							{Name: "table1", Query: "SELECT 1", Timeout: Duration(5 * time.Second), CheckInterval: Duration(30 * time.Second)},
 						},
 					},
 				},
 				Server: Server{
 					Host:         "localhost",
 					Port:         8080,
-					ReadTimeout:  30,
-					WriteTimeout: 30,
-					IdleTimeout:  120,
+					ReadTimeout:  Duration(30 * time.Second),
+					WriteTimeout: Duration(30 * time.Second),
+					IdleTimeout:  Duration(120 * time.Second),
 				},
 				Retry: Retry{
 					MaxAttempts:     3,
-					InitialDelay:    1,
-					MaxDelay:        10,
+					InitialDelay:    Duration(1 * time.Second),
+					MaxDelay:        Duration(10 * time.Second),
 					BackoffFactor:   2,
-					ConnectionRetry: 5,
+					ConnectionRetry: Duration(5 * time.Second),
 				},
 			},
 			expectError: false,
@@ -125,16 +138,16 @@ func TestConfigValidation(t *testing.T) {
 				Server: Server{
 					Host:         "localhost",
 					Port:         8080,
-					ReadTimeout:  30,
-					WriteTimeout: 30,
-					IdleTimeout:  120,
+					ReadTimeout:  Duration(30 * time.Second),
+					WriteTimeout: Duration(30 * time.Second),
+					IdleTimeout:  Duration(120 * time.Second),
 				},
 				Retry: Retry{
 					MaxAttempts:     3,
-					InitialDelay:    1,
-					MaxDelay:        10,
+					InitialDelay:    Duration(1 * time.Second),
+					MaxDelay:        Duration(10 * time.Second),
 					BackoffFactor:   2,
-					ConnectionRetry: 5,
+					ConnectionRetry: Duration(5 * time.Second),
 				},
 			},
 			expectError: true,
@@ -152,23 +165,23 @@ func TestConfigValidation(t *testing.T) {
 						Password: "pass",
 						Database: "db",
 						Tables: []Table{
-							{Name: "table1", Query: "SELECT 1", Timeout: 5, CheckInterval: 30},
+							{Name: "table1", Query: "SELECT 1", Timeout: Duration(5 * time.Second), CheckInterval: Duration(30 * time.Second)},
 						},
 					},
 				},
 				Server: Server{
 					Host:         "localhost",
 					Port:         8080,
-					ReadTimeout:  30,
-					WriteTimeout: 30,
-					IdleTimeout:  120,
+					ReadTimeout:  Duration(30 * time.Second),
+					WriteTimeout: Duration(30 * time.Second),
+					IdleTimeout:  Duration(120 * time.Second),
 				},
 				Retry: Retry{
 					MaxAttempts:     3,
-					InitialDelay:    1,
-					MaxDelay:        10,
+					InitialDelay:    Duration(1 * time.Second),
+					MaxDelay:        Duration(10 * time.Second),
 					BackoffFactor:   2,
-					ConnectionRetry: 5,
+					ConnectionRetry: Duration(5 * time.Second),
 				},
 			},
 			expectError: true,
@@ -188,6 +201,117 @@ func TestConfigValidation(t *testing.T) {
 	}
 }
 
+func TestDNSValidation(t *testing.T) {
+	databases := []Database{{Name: "primary"}}
+	groups := []Group{{Name: "checkout", Databases: []string{"primary"}}}
+
+	tests := []struct {
+		name        string
+		dns         DNS
+		expectError bool
+	}{
+		{
+			name:        "disabled",
+			dns:         DNS{},
+			expectError: false,
+		},
+		{
+			name: "valid records",
+			dns: DNS{
+				Enabled: true,
+				Host:    "0.0.0.0",
+				Port:    5353,
+				Records: []DNSRecord{
+					{Name: "db.example.internal", Database: "primary", Addresses: []string{"10.0.0.5"}},
+					{Name: "db.example.internal", Database: "primary", TXT: []string{"primary"}},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown database",
+			dns: DNS{
+				Enabled: true,
+				Host:    "0.0.0.0",
+				Port:    5353,
+				Records: []DNSRecord{
+					{Name: "db.example.internal", Database: "replica", Addresses: []string{"10.0.0.5"}},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "group record",
+			dns: DNS{
+				Enabled: true,
+				Host:    "0.0.0.0",
+				Port:    5353,
+				Records: []DNSRecord{
+					{Name: "checkout.example.internal", Group: "checkout", Addresses: []string{"10.0.0.7"}},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown group",
+			dns: DNS{
+				Enabled: true,
+				Host:    "0.0.0.0",
+				Port:    5353,
+				Records: []DNSRecord{
+					{Name: "checkout.example.internal", Group: "billing", Addresses: []string{"10.0.0.7"}},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "database and group",
+			dns: DNS{
+				Enabled: true,
+				Host:    "0.0.0.0",
+				Port:    5353,
+				Records: []DNSRecord{
+					{Name: "checkout.example.internal", Database: "primary", Group: "checkout", Addresses: []string{"10.0.0.7"}},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "invalid address",
+			dns: DNS{
+				Enabled: true,
+				Host:    "0.0.0.0",
+				Port:    5353,
+				Records: []DNSRecord{
+					{Name: "db.example.internal", Database: "primary", Addresses: []string{"::1"}},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "no records",
+			dns: DNS{
+				Enabled: true,
+				Host:    "0.0.0.0",
+				Port:    5353,
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dns.Validate(databases, groups)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
 func TestLoadConfigFileNotFound(t *testing.T) {
 	_, err := LoadConfig("nonexistent.yaml")
 	if err == nil {
@@ -208,4 +332,1751 @@ func TestServerGetAddress(t *testing.T) {
 	if addr := server.GetAddress(); addr != expected {
 		t.Errorf("Expected address '%s', got '%s'", expected, addr)
 	}
-}
\ No newline at end of file
+}
+
+func TestServerListenerValidation(t *testing.T) {
+	base := Server{
+		ReadTimeout:  Duration(30 * time.Second),
+		WriteTimeout: Duration(30 * time.Second),
+		IdleTimeout:  Duration(120 * time.Second),
+	}
+
+	tests := []struct {
+		name         string
+		host         string
+		port         int
+		socket       string
+		socketMode   string
+		expectError  bool
+		expectedMode os.FileMode
+	}{
+		{"tcp", "localhost", 8080, "", "", false, 0o660},
+		{"tcp and socket", "localhost", 8080, "/run/gsqlhealth.sock", "0600", false, 0o600},
+		{"socket only", "", 0, "/run/gsqlhealth.sock", "", false, 0o660},
+		{"no listener", "localhost", 0, "", "", true, 0},
+		{"tcp without host", "", 8080, "", "", true, 0},
+		{"invalid socket mode", "", 0, "/run/gsqlhealth.sock", "rw-rw----", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := base
+			server.Host, server.Port, server.Socket, server.SocketMode = tt.host, tt.port, tt.socket, tt.socketMode
+
+			err := server.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if mode := server.GetSocketMode(); mode != tt.expectedMode {
+				t.Errorf("GetSocketMode() = %o; expected %o", mode, tt.expectedMode)
+			}
+		})
+	}
+}
+
+// sopsFixture encrypts SOPS documents with a random data key wrapped for an
+// age identity, which is set in SOPS_AGE_KEY
+type sopsFixture struct {
+	identity *age.X25519Identity
+	dataKey  []byte
+	wrapped  string
+}
+
+func newSOPSFixture(t *testing.T) *sopsFixture {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate age identity: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		t.Fatalf("Failed to generate data key: %v", err)
+	}
+
+	var wrapped bytes.Buffer
+	armorWriter := armor.NewWriter(&wrapped)
+	ageWriter, err := age.Encrypt(armorWriter, identity.Recipient())
+	if err != nil {
+		t.Fatalf("Failed to encrypt data key: %v", err)
+	}
+	ageWriter.Write(dataKey)
+	ageWriter.Close()
+	armorWriter.Close()
+
+	return &sopsFixture{identity: identity, dataKey: dataKey, wrapped: wrapped.String()}
+}
+
+// encrypt encrypts a value as SOPS does, authenticated with additional data
+func (f *sopsFixture) encrypt(value, additionalData, valueType string) string {
+	iv := make([]byte, 32)
+	rand.Read(iv)
+	block, _ := aes.NewCipher(f.dataKey)
+	gcm, _ := cipher.NewGCMWithNonceSize(block, len(iv))
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(additionalData))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag),
+		valueType)
+}
+
+// metadata returns the sops block of a document whose plaintext values, in
+// document order, are values
+func (f *sopsFixture) metadata(values ...string) string {
+	const lastModified = "2024-01-15T10:30:00Z"
+	mac := sha512.Sum512([]byte(strings.Join(values, "")))
+	return fmt.Sprintf(`sops:
+  age:
+    - recipient: %s
+      enc: |
+%s
+  lastmodified: "%s"
+  mac: %s
+  version: 3.8.1
+`,
+		f.identity.Recipient().String(),
+		indent(f.wrapped, "        "),
+		lastModified,
+		f.encrypt(fmt.Sprintf("%X", mac[:]), lastModified, "str"))
+}
+
+func TestLoadConfigSOPSEncrypted(t *testing.T) {
+	sops := newSOPSFixture(t)
+
+	configContent := fmt.Sprintf(`
+databases:
+  - name: "test-mysql"
+    type: "mysql"
+    host: "localhost"
+    port: %s
+    username: "testuser"
+    password: %s
+    database: "testdb"
+    tables:
+      - name: "users"
+        query: "SELECT 1"
+        timeout: 5
+        check_interval: 30
+server:
+  host: "localhost"
+  port: 8080
+  read_timeout: 30
+  write_timeout: 30
+  idle_timeout: 120
+%s`,
+		sops.encrypt("3306", "databases:port:", "int"),
+		sops.encrypt("s3cret", "databases:password:", "str"),
+		sops.metadata("test-mysql", "mysql", "localhost", "3306", "testuser", "s3cret", "testdb",
+			"users", "SELECT 1", "5", "30", "localhost", "8080", "30", "30", "120"))
+
+	tmpFile, err := os.CreateTemp("", "config_sops_*.yaml")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(configContent); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if config.Databases[0].Password != "s3cret" {
+		t.Errorf("Expected decrypted password 's3cret', got '%s'", config.Databases[0].Password)
+	}
+
+	if config.Databases[0].Port != 3306 {
+		t.Errorf("Expected decrypted port 3306, got %d", config.Databases[0].Port)
+	}
+
+	// A different key must not be able to decrypt the config
+	other, _ := age.GenerateX25519Identity()
+	t.Setenv("SOPS_AGE_KEY", other.String())
+	if _, err := LoadConfig(tmpFile.Name()); err == nil {
+		t.Error("Expected error when decrypting with the wrong key")
+	}
+}
+
+func TestDecryptSOPSTampered(t *testing.T) {
+	sops := newSOPSFixture(t)
+	metadata := sops.metadata("primary", "s3cret", "True")
+
+	tests := []struct {
+		name     string
+		document string
+		wantErr  bool
+	}{
+		{
+			name: "unmodified",
+			document: fmt.Sprintf("host: primary\npassword: %s\nverify: true\n%s",
+				sops.encrypt("s3cret", "password:", "str"), metadata),
+		},
+		{
+			name: "value replaced",
+			document: fmt.Sprintf("host: primary\npassword: %s\nverify: true\n%s",
+				sops.encrypt("hunter2", "password:", "str"), metadata),
+			wantErr: true,
+		},
+		{
+			name: "unencrypted value changed",
+			document: fmt.Sprintf("host: attacker\npassword: %s\nverify: true\n%s",
+				sops.encrypt("s3cret", "password:", "str"), metadata),
+			wantErr: true,
+		},
+		{
+			name:     "value removed",
+			document: fmt.Sprintf("host: primary\nverify: true\n%s", metadata),
+			wantErr:  true,
+		},
+		{
+			name: "value added",
+			document: fmt.Sprintf("host: primary\npassword: %s\nverify: true\nextra: 1\n%s",
+				sops.encrypt("s3cret", "password:", "str"), metadata),
+			wantErr: true,
+		},
+		{
+			name: "mac removed",
+			document: fmt.Sprintf("host: primary\npassword: %s\nverify: true\n%s",
+				sops.encrypt("s3cret", "password:", "str"), strings.Replace(metadata, "  mac:", "  unused:", 1)),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := decryptConfig([]byte(tt.document))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error but got plaintext %q", plaintext)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if !strings.Contains(string(plaintext), "password: s3cret") {
+				t.Errorf("plaintext = %q; expected the decrypted password", plaintext)
+			}
+		})
+	}
+}
+
+func TestLoadConfigAgeEncrypted(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate age identity: %v", err)
+	}
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+
+	configContent := `
+databases:
+  - name: "test-postgres"
+    type: "postgres"
+    host: "localhost"
+    port: 5432
+    username: "testuser"
+    password: "testpass"
+    database: "testdb"
+    tables:
+      - name: "events"
+        query: "SELECT 1"
+        timeout: 5
+        check_interval: 30
+server:
+  host: "localhost"
+  port: 8080
+  read_timeout: 30
+  write_timeout: 30
+  idle_timeout: 120
+`
+
+	var encrypted bytes.Buffer
+	armorWriter := armor.NewWriter(&encrypted)
+	ageWriter, err := age.Encrypt(armorWriter, identity.Recipient())
+	if err != nil {
+		t.Fatalf("Failed to encrypt config: %v", err)
+	}
+	ageWriter.Write([]byte(configContent))
+	ageWriter.Close()
+	armorWriter.Close()
+
+	tmpFile, err := os.CreateTemp("", "config_age_*.yaml.age")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(encrypted.Bytes()); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	config, err := LoadConfig(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if config.Databases[0].Name != "test-postgres" {
+		t.Errorf("Expected database name 'test-postgres', got '%s'", config.Databases[0].Name)
+	}
+}
+
+// indent prefixes every line of s with the given prefix
+func indent(s, prefix string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestTableLabels(t *testing.T) {
+	db := Database{
+		Labels: map[string]string{"team": "payments", "env": "prod"},
+		Tables: []Table{
+			{Name: "orders", Labels: map[string]string{"tier": "1", "env": "staging"}},
+			{Name: "users"},
+		},
+	}
+
+	labels := db.TableLabels(&db.Tables[0])
+	expected := map[string]string{"team": "payments", "env": "staging", "tier": "1"}
+	if len(labels) != len(expected) {
+		t.Fatalf("TableLabels() = %v; expected %v", labels, expected)
+	}
+	for key, value := range expected {
+		if labels[key] != value {
+			t.Errorf("TableLabels()[%q] = %q; expected %q", key, labels[key], value)
+		}
+	}
+
+	if labels := db.TableLabels(&db.Tables[1]); labels["team"] != "payments" || len(labels) != 2 {
+		t.Errorf("Expected database labels to be inherited, got %v", labels)
+	}
+
+	if err := validateLabels(map[string]string{"team:name": "x"}); err == nil {
+		t.Error("Expected error for label key containing ':'")
+	}
+}
+
+func TestMaintenanceWindowValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		window      MaintenanceWindow
+		expectError bool
+	}{
+		{"valid", MaintenanceWindow{Schedule: "0 3 * * 0", Duration: Duration(3600 * time.Second)}, false},
+		{"valid with timezone", MaintenanceWindow{Schedule: "30 23 * * *", Duration: Duration(3600 * time.Second), Timezone: "UTC"}, false},
+		{"invalid schedule", MaintenanceWindow{Schedule: "0 25 * * *", Duration: Duration(3600 * time.Second)}, true},
+		{"zero duration", MaintenanceWindow{Schedule: "0 3 * * *"}, true},
+		{"duration too long", MaintenanceWindow{Schedule: "0 3 * * *", Duration: Duration(8 * 24 * 3600 * time.Second)}, true},
+		{"invalid timezone", MaintenanceWindow{Schedule: "0 3 * * *", Duration: Duration(3600 * time.Second), Timezone: "Mars/Base"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.window.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestGroupValidation(t *testing.T) {
+	databases := []Database{{Name: "primary"}}
+
+	tests := []struct {
+		name        string
+		group       Group
+		expectError bool
+	}{
+		{"all databases", Group{Name: "all"}, false},
+		{"known database with labels", Group{Name: "payments", Databases: []string{"primary"}, Labels: map[string]string{"team": "payments"}}, false},
+		{"missing name", Group{}, true},
+		{"unknown database", Group{Name: "payments", Databases: []string{"replica"}}, true},
+		{"invalid label", Group{Name: "payments", Labels: map[string]string{"team:x": "payments"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.group.Validate(databases)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryValidation(t *testing.T) {
+	tests := []struct {
+		name              string
+		memory            Memory
+		expectError       bool
+		expectedLimit     int64
+		expectedThreshold float64
+	}{
+		{"defaults", Memory{}, false, 0, 0.9},
+		{"configured", Memory{SoftLimitMB: 256, PressureThreshold: 75}, false, 256 << 20, 0.75},
+		{"negative limit", Memory{SoftLimitMB: -1}, true, 0, 0},
+		{"threshold too high", Memory{PressureThreshold: 150}, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.memory.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if got := tt.memory.GetSoftLimit(); got != tt.expectedLimit {
+				t.Errorf("GetSoftLimit() = %d; expected %d", got, tt.expectedLimit)
+			}
+			if got := tt.memory.GetPressureThreshold(); got != tt.expectedThreshold {
+				t.Errorf("GetPressureThreshold() = %v; expected %v", got, tt.expectedThreshold)
+			}
+		})
+	}
+}
+
+func TestHistoryValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		history      History
+		expectError  bool
+		expectedSize int
+	}{
+		{"defaults", History{}, false, 1000},
+		{"configured", History{Size: 50}, false, 50},
+		{"negative size", History{Size: -1}, true, 0},
+		{"sqlite", History{Backend: "sqlite", Path: "history.db", Retention: Duration(24 * time.Hour)}, false, 1000},
+		{"sqlite without path", History{Backend: "sqlite"}, true, 0},
+		{"unknown backend", History{Backend: "redis"}, true, 0},
+		{"negative retention", History{Retention: -1}, true, 0},
+		{"recent", History{Recent: 20}, false, 1000},
+		{"negative recent", History{Recent: -1}, true, 0},
+		{"postgres", History{Backend: "postgres", DSN: "postgres://gsqlhealth@archive/history", Table: "check_results"}, false, 1000},
+		{"mysql without dsn", History{Backend: "mysql"}, true, 0},
+		{"invalid table", History{Backend: "postgres", DSN: "postgres://archive/history", Table: "history; DROP TABLE users"}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.history.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if got := tt.history.GetSize(); got != tt.expectedSize {
+				t.Errorf("GetSize() = %d; expected %d", got, tt.expectedSize)
+			}
+		})
+	}
+}
+
+func TestCORSValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		cors        CORS
+		expectError bool
+	}{
+		{"defaults", CORS{}, false},
+		{"explicit origins with credentials", CORS{AllowedOrigins: []string{"https://dashboard.example.com", "http://localhost:3000"}, AllowCredentials: true}, false},
+		{"any origin with credentials", CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true}, true},
+		{"missing scheme", CORS{AllowedOrigins: []string{"dashboard.example.com"}}, true},
+		{"origin with path", CORS{AllowedOrigins: []string{"https://dashboard.example.com/app"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cors.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthValidation(t *testing.T) {
+	// bcrypt hash of "password" at cost 4
+	const hash = "$2a$04$K3bvqZJTLQkqgvdNTSVqS.CI8oT8VqngBBapkz/gdOe1VVffN7/p6"
+
+	tests := []struct {
+		name        string
+		auth        Auth
+		expectError bool
+		expectOn    bool
+	}{
+		{"disabled", Auth{}, false, false},
+		{"users and keys", Auth{
+			Users:   []AuthUser{{Username: "ops", PasswordHash: hash}},
+			APIKeys: []APIKey{{Name: "ci", Key: "0123456789abcdef"}},
+		}, false, true},
+		{"plain text password", Auth{Users: []AuthUser{{Username: "ops", PasswordHash: "password"}}}, true, false},
+		{"duplicate user", Auth{Users: []AuthUser{{Username: "ops", PasswordHash: hash}, {Username: "ops", PasswordHash: hash}}}, true, false},
+		{"short key", Auth{APIKeys: []APIKey{{Name: "ci", Key: "short"}}}, true, false},
+		{"unnamed key", Auth{APIKeys: []APIKey{{Key: "0123456789abcdef"}}}, true, false},
+		{"relative exempt path", Auth{ExemptPaths: []string{"livez"}}, true, false},
+		{"oidc", Auth{OIDC: OIDC{Issuer: "https://login.example.com", Audience: "gsqlhealth"}}, false, true},
+		{"oidc with jwks url", Auth{OIDC: OIDC{Issuer: "https://login.example.com", Audience: "gsqlhealth", JWKSURL: "https://login.example.com/keys"}}, false, true},
+		{"oidc without audience", Auth{OIDC: OIDC{Issuer: "https://login.example.com"}}, true, false},
+		{"oidc without issuer", Auth{OIDC: OIDC{Audience: "gsqlhealth"}}, true, false},
+		{"oidc relative issuer", Auth{OIDC: OIDC{Issuer: "login.example.com", Audience: "gsqlhealth"}}, true, false},
+		{"oidc invalid jwks url", Auth{OIDC: OIDC{Issuer: "https://login.example.com", Audience: "gsqlhealth", JWKSURL: "keys"}}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.auth.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if enabled := tt.auth.IsEnabled(); enabled != tt.expectOn {
+				t.Errorf("IsEnabled() = %v; expected %v", enabled, tt.expectOn)
+			}
+		})
+	}
+}
+
+func TestTLSValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		tls         TLS
+		expectError bool
+		expectOn    bool
+	}{
+		{"disabled", TLS{}, false, false},
+		{"certificate", TLS{CertFile: "server.crt", KeyFile: "server.key"}, false, true},
+		{"client certificates", TLS{CertFile: "server.crt", KeyFile: "server.key", ClientCAFile: "ca.crt"}, false, true},
+		{"missing key", TLS{CertFile: "server.crt"}, true, false},
+		{"missing certificate", TLS{KeyFile: "server.key"}, true, false},
+		{"client CA without certificate", TLS{ClientCAFile: "ca.crt"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tls.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if enabled := tt.tls.IsEnabled(); enabled != tt.expectOn {
+				t.Errorf("IsEnabled() = %v; expected %v", enabled, tt.expectOn)
+			}
+		})
+	}
+}
+
+func TestRateLimitDefaults(t *testing.T) {
+	tests := []struct {
+		name                  string
+		rateLimit             RateLimit
+		expectError           bool
+		expectedBurst         int
+		expectedRealtimeBurst int
+	}{
+		{"disabled", RateLimit{}, false, 1, 1},
+		{"default bursts", RateLimit{RequestsPerSecond: 10.5, RealtimePerSecond: 0.2}, false, 11, 1},
+		{"explicit bursts", RateLimit{RequestsPerSecond: 10, Burst: 50, RealtimePerSecond: 1, RealtimeBurst: 5}, false, 50, 5},
+		{"negative rate", RateLimit{RequestsPerSecond: -1}, true, 0, 0},
+		{"negative burst", RateLimit{RequestsPerSecond: 1, Burst: -1}, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rateLimit.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if burst := tt.rateLimit.GetBurst(); burst != tt.expectedBurst {
+				t.Errorf("GetBurst() = %d; expected %d", burst, tt.expectedBurst)
+			}
+			if burst := tt.rateLimit.GetRealtimeBurst(); burst != tt.expectedRealtimeBurst {
+				t.Errorf("GetRealtimeBurst() = %d; expected %d", burst, tt.expectedRealtimeBurst)
+			}
+		})
+	}
+}
+
+func TestDebugValidation(t *testing.T) {
+	base := Server{
+		Host:         "0.0.0.0",
+		Port:         8080,
+		ReadTimeout:  Duration(30 * time.Second),
+		WriteTimeout: Duration(30 * time.Second),
+		IdleTimeout:  Duration(120 * time.Second),
+	}
+
+	tests := []struct {
+		name            string
+		debug           Debug
+		expectError     bool
+		expectedAddress string
+	}{
+		{"api listener", Debug{Enabled: true}, false, "127.0.0.1:0"},
+		{"separate port", Debug{Enabled: true, Port: 6060}, false, "127.0.0.1:6060"},
+		{"separate host", Debug{Enabled: true, Host: "10.0.0.5", Port: 6060}, false, "10.0.0.5:6060"},
+		{"server port", Debug{Enabled: true, Port: 8080}, true, ""},
+		{"invalid port", Debug{Enabled: true, Port: 70000}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := base
+			server.Debug = tt.debug
+
+			err := server.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if address := server.Debug.GetAddress(); address != tt.expectedAddress {
+				t.Errorf("GetAddress() = %q; expected %q", address, tt.expectedAddress)
+			}
+		})
+	}
+}
+
+func TestParseConfigTableDefaults(t *testing.T) {
+	configContent := `
+query_timeout_default: "5s"
+check_interval_default: "1m"
+jitter: 10%
+databases:
+  - name: "inherits-global"
+    type: "postgres"
+    host: "localhost"
+    port: 5432
+    username: "user"
+    database: "app"
+    tables:
+      - name: "users"
+        query: "SELECT 1"
+      - name: "orders"
+        query: "SELECT 1"
+        timeout: 2
+        jitter: 0
+        recheck_interval: 10s
+  - name: "database-defaults"
+    type: "postgres"
+    host: "localhost"
+    port: 5432
+    username: "user"
+    database: "app"
+    query_timeout_default: "10s"
+    check_interval_default: "30s"
+    tables:
+      - name: "events"
+        query: "SELECT 1"
+server:
+  host: "localhost"
+  port: 8080
+  read_timeout: 30
+  write_timeout: 30
+  idle_timeout: 120
+`
+
+	config, err := ParseConfig([]byte(configContent))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	tests := []struct {
+		table    Table
+		timeout  time.Duration
+		interval time.Duration
+	}{
+		{config.Databases[0].Tables[0], 5 * time.Second, time.Minute},
+		{config.Databases[0].Tables[1], 2 * time.Second, time.Minute},
+		{config.Databases[1].Tables[0], 10 * time.Second, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := tt.table.GetQueryTimeout(); got != tt.timeout {
+			t.Errorf("%s: timeout = %v; expected %v", tt.table.Name, got, tt.timeout)
+		}
+		if got := tt.table.GetCheckInterval(); got != tt.interval {
+			t.Errorf("%s: check_interval = %v; expected %v", tt.table.Name, got, tt.interval)
+		}
+	}
+
+	if jitter := config.Databases[0].Tables[0].GetJitter(); jitter != 0.1 {
+		t.Errorf("users: jitter = %g; expected the global 0.1", jitter)
+	}
+	if jitter := config.Databases[0].Tables[1].GetJitter(); jitter != 0 {
+		t.Errorf("orders: jitter = %g; expected its own 0", jitter)
+	}
+
+	if got := config.Databases[0].Tables[0].GetRecheckInterval(); got != time.Minute {
+		t.Errorf("users: recheck_interval = %v; expected the check interval 1m", got)
+	}
+	if got := config.Databases[0].Tables[1].GetRecheckInterval(); got != 10*time.Second {
+		t.Errorf("orders: recheck_interval = %v; expected 10s", got)
+	}
+
+	if got := config.GetScheduledCheckTimeout(); got != 30*time.Second {
+		t.Errorf("Expected default scheduled check timeout of 30s, got %v", got)
+	}
+	if got := config.Server.GetRequestTimeout(); got != 30*time.Second {
+		t.Errorf("Expected default request timeout of 30s, got %v", got)
+	}
+	if got := config.Server.GetPingTimeout(); got != 10*time.Second {
+		t.Errorf("Expected default /ping timeout of 10s, got %v", got)
+	}
+	if got := config.Retry.GetConnectTimeout(); got != 30*time.Second {
+		t.Errorf("Expected default connect timeout of 30s, got %v", got)
+	}
+	if got := config.Retry.GetPingTimeout(); got != 5*time.Second {
+		t.Errorf("Expected default ping timeout of 5s, got %v", got)
+	}
+}
+
+func TestParseConfigConnectionCheck(t *testing.T) {
+	configContent := `
+query_timeout_default: "5s"
+check_interval_default: "1m"
+databases:
+  - name: "no-tables"
+    type: "postgres"
+    host: "localhost"
+    port: 5432
+    username: "user"
+    database: "app"
+  - name: "explicit"
+    type: "mysql"
+    host: "localhost"
+    port: 3306
+    username: "user"
+    database: "app"
+    connection_check:
+      name: "ping"
+      check_interval: "10s"
+      severity: "warning"
+    tables:
+      - name: "users"
+        query: "SELECT 1"
+server:
+  host: "localhost"
+  port: 8080
+  read_timeout: 30
+  write_timeout: 30
+  idle_timeout: 120
+`
+
+	config, err := ParseConfig([]byte(configContent))
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+
+	tables := config.Databases[0].Tables
+	if len(tables) != 1 || tables[0].Name != DefaultConnectionCheckName || tables[0].Check != CheckConnection {
+		t.Fatalf("Expected a default connection check, got %+v", tables)
+	}
+	if tables[0].GetCheckInterval() != time.Minute || tables[0].GetQueryTimeout() != 5*time.Second {
+		t.Errorf("Expected the connection check to get the default interval and timeout")
+	}
+
+	tables = config.Databases[1].Tables
+	if len(tables) != 2 || tables[1].Name != "ping" || tables[1].GetCheckInterval() != 10*time.Second || tables[1].GetSeverity() != SeverityWarning {
+		t.Errorf("Expected the configured connection check after the tables, got %+v", tables)
+	}
+
+	for _, invalid := range []string{
+		`connection_check: {query: "SELECT 2"}`,
+		`connection_check: {name: "users"}`,
+	} {
+		content := strings.Replace(configContent, `connection_check:
+      name: "ping"
+      check_interval: "10s"
+      severity: "warning"`, invalid, 1)
+		if _, err := ParseConfig([]byte(content)); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestTableExpectations(t *testing.T) {
+	var single, list Table
+	if err := yaml.Unmarshal([]byte(`expect: {column: count, operator: ">=", value: 1}`), &single); err != nil {
+		t.Fatalf("Failed to parse a single expectation: %v", err)
+	}
+	if len(single.Expect) != 1 || single.Expect[0].Column != "count" || single.Expect[0].Value != 1 {
+		t.Errorf("Unexpected expectations: %+v", single.Expect)
+	}
+
+	listContent := `
+expect:
+  - column: count
+    operator: ">"
+    value: 0
+  - column: stale
+    value: false
+`
+	if err := yaml.Unmarshal([]byte(listContent), &list); err != nil {
+		t.Fatalf("Failed to parse a list of expectations: %v", err)
+	}
+	if len(list.Expect) != 2 || list.Expect[1].GetOperator() != "==" {
+		t.Errorf("Unexpected expectations: %+v", list.Expect)
+	}
+
+	intPtr := func(n int) *int { return &n }
+	tests := []struct {
+		name        string
+		expect      Expectations
+		minRows     *int
+		maxRows     *int
+		expectError bool
+	}{
+		{"no expectations", nil, nil, nil, false},
+		{"numeric", Expectations{{Column: "count", Operator: ">=", Value: 1}}, nil, nil, false},
+		{"string equality", Expectations{{Column: "state", Value: "ok"}}, nil, nil, false},
+		{"missing column", Expectations{{Operator: ">=", Value: 1}}, nil, nil, true},
+		{"missing value", Expectations{{Column: "count"}}, nil, nil, true},
+		{"invalid operator", Expectations{{Column: "count", Operator: "=>", Value: 1}}, nil, nil, true},
+		{"ordered string", Expectations{{Column: "state", Operator: ">", Value: "ok"}}, nil, nil, true},
+		{"row bounds", nil, intPtr(1), intPtr(10), false},
+		{"no rows", nil, nil, intPtr(0), false},
+		{"negative min_rows", nil, intPtr(-1), nil, true},
+		{"max_rows below min_rows", nil, intPtr(5), intPtr(1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := Table{
+				Name:          "users",
+				Query:         "SELECT COUNT(*) AS count FROM users",
+				Timeout:       Duration(time.Second),
+				CheckInterval: Duration(time.Minute),
+				Expect:        tt.expect,
+				MinRows:       tt.minRows,
+				MaxRows:       tt.maxRows,
+			}
+			err := table.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestTableLatencyValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		warn        time.Duration
+		critical    time.Duration
+		expectError bool
+	}{
+		{"unset", 0, 0, false},
+		{"warn only", time.Second, 0, false},
+		{"critical only", 0, time.Second, false},
+		{"both", time.Second, 10 * time.Second, false},
+		{"critical below warn", 10 * time.Second, time.Second, true},
+		{"negative", -time.Second, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := Table{
+				Name:            "users",
+				Query:           "SELECT 1",
+				Timeout:         Duration(time.Second),
+				CheckInterval:   Duration(time.Minute),
+				WarnLatency:     Duration(tt.warn),
+				CriticalLatency: Duration(tt.critical),
+			}
+			err := table.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+
+			// Plans are captured for checks above a latency threshold
+			table.CapturePlan = true
+			if err := table.Validate(); !tt.expectError && (err == nil) != (tt.warn > 0 || tt.critical > 0) {
+				t.Errorf("Validate() with capture_plan error = %v", err)
+			}
+		})
+	}
+
+	table := Table{Name: "users", Check: CheckRowCount, Timeout: Duration(time.Second), CheckInterval: Duration(time.Minute), WarnLatency: Duration(time.Second), CapturePlan: true}
+	if err := table.Validate(); err == nil {
+		t.Errorf("Expected an error for capture_plan on a built-in check")
+	}
+
+	table = Table{Name: "users", Query: "SELECT 1", Timeout: Duration(time.Second), CheckInterval: Duration(time.Minute), AnomalyStddevs: -1}
+	if err := table.Validate(); err == nil {
+		t.Errorf("Expected an error for a negative anomaly_stddevs")
+	}
+}
+
+func TestTableMetricsValidation(t *testing.T) {
+	tests := []struct {
+		metrics     map[string]string
+		expectError bool
+	}{
+		{map[string]string{"count": "gsqlhealth_orders_count"}, false},
+		{map[string]string{"lag": "replica:lag_seconds"}, false},
+		{map[string]string{"count": "orders-count"}, true},
+		{map[string]string{"count": "1count"}, true},
+		{map[string]string{"count": ""}, true},
+	}
+
+	for _, tt := range tests {
+		table := Table{Name: "orders", Query: "SELECT 1", Timeout: Duration(time.Second), CheckInterval: Duration(time.Minute), Metrics: tt.metrics}
+		if err := table.Validate(); (err != nil) != tt.expectError {
+			t.Errorf("Validate() with metrics %v error = %v; expected error %v", tt.metrics, err, tt.expectError)
+		}
+	}
+}
+
+func TestDegradedStatusCodeValidation(t *testing.T) {
+	tests := []struct {
+		code         int
+		expectError  bool
+		expectedCode int
+	}{
+		{0, false, 200},
+		{207, false, 207},
+		{429, false, 429},
+		{99, true, 0},
+		{600, true, 0},
+	}
+
+	for _, tt := range tests {
+		server := Server{
+			Host:               "localhost",
+			Port:               8080,
+			ReadTimeout:        Duration(30 * time.Second),
+			WriteTimeout:       Duration(30 * time.Second),
+			IdleTimeout:        Duration(120 * time.Second),
+			DegradedStatusCode: tt.code,
+		}
+		err := server.Validate()
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("degraded_status_code %d: expected error but got none", tt.code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("degraded_status_code %d: expected no error but got: %v", tt.code, err)
+		}
+		if code := server.GetDegradedStatusCode(); code != tt.expectedCode {
+			t.Errorf("GetDegradedStatusCode() = %d; expected %d", code, tt.expectedCode)
+		}
+	}
+}
+
+func TestUnknownStatusCodeValidation(t *testing.T) {
+	tests := []struct {
+		code         int
+		expectError  bool
+		expectedCode int
+	}{
+		{0, false, 200},
+		{503, false, 503},
+		{99, true, 0},
+		{600, true, 0},
+	}
+
+	for _, tt := range tests {
+		server := Server{
+			Host:              "localhost",
+			Port:              8080,
+			ReadTimeout:       Duration(30 * time.Second),
+			WriteTimeout:      Duration(30 * time.Second),
+			IdleTimeout:       Duration(120 * time.Second),
+			UnknownStatusCode: tt.code,
+		}
+		err := server.Validate()
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("unknown_status_code %d: expected error but got none", tt.code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unknown_status_code %d: expected no error but got: %v", tt.code, err)
+		}
+		if code := server.GetUnknownStatusCode(); code != tt.expectedCode {
+			t.Errorf("GetUnknownStatusCode() = %d; expected %d", code, tt.expectedCode)
+		}
+	}
+}
+
+func TestMaxResultAge(t *testing.T) {
+	tests := []struct {
+		maxResultAge time.Duration
+		expectError  bool
+	}{
+		{0, false},
+		{time.Minute, false},
+		{time.Hour, false},
+		{time.Second, true},
+		{-time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		table := Table{Name: "orders", Query: "SELECT 1", Timeout: Duration(time.Second), CheckInterval: Duration(time.Minute), MaxResultAge: Duration(tt.maxResultAge)}
+		if err := table.Validate(); (err != nil) != tt.expectError {
+			t.Errorf("Validate() with max_result_age %v error = %v; expected error %v", tt.maxResultAge, err, tt.expectError)
+		}
+	}
+
+	config := &Config{
+		MaxResultAge: Duration(time.Hour),
+		Databases: []Database{{Name: "main", Tables: []Table{
+			{Name: "users"},
+			{Name: "orders", MaxResultAge: Duration(2 * time.Hour)},
+		}}},
+	}
+	config.applyTableDefaults()
+	if got := config.Databases[0].Tables[0].MaxResultAge; got != Duration(time.Hour) {
+		t.Errorf("users: max_result_age = %v; expected the top-level 1h", got)
+	}
+	if got := config.Databases[0].Tables[1].MaxResultAge; got != Duration(2*time.Hour) {
+		t.Errorf("orders: max_result_age = %v; expected 2h", got)
+	}
+}
+
+func TestTableThresholds(t *testing.T) {
+	table := Table{Name: "users", Query: "SELECT 1", Timeout: Duration(time.Second), CheckInterval: Duration(time.Minute)}
+	if table.GetFailureThreshold() != 1 || table.GetSuccessThreshold() != 1 {
+		t.Errorf("Expected thresholds to default to 1, got %d and %d", table.GetFailureThreshold(), table.GetSuccessThreshold())
+	}
+
+	table.FailureThreshold, table.SuccessThreshold = 3, 2
+	if err := table.Validate(); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+	if table.GetFailureThreshold() != 3 || table.GetSuccessThreshold() != 2 {
+		t.Errorf("Thresholds = %d and %d; expected 3 and 2", table.GetFailureThreshold(), table.GetSuccessThreshold())
+	}
+
+	table.FailureThreshold = -1
+	if err := table.Validate(); err == nil {
+		t.Errorf("Expected an error for a negative failure_threshold")
+	}
+}
+
+func TestTableIsSheddable(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name     string
+		table    Table
+		expected bool
+	}{
+		{"default severity", Table{}, false},
+		{"warning", Table{Severity: SeverityWarning}, true},
+		{"info", Table{Severity: SeverityInfo}, true},
+		{"deprecated critical false", Table{Severity: SeverityCritical, Critical: &disabled}, true},
+		{"deprecated critical true", Table{Severity: SeverityInfo, Critical: &enabled}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sheddable := tt.table.IsSheddable(); sheddable != tt.expected {
+				t.Errorf("IsSheddable() = %v; expected %v", sheddable, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFlappingValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		flapping     Flapping
+		expectError  bool
+		expectedHigh int
+		expectedLow  int
+	}{
+		{"disabled", Flapping{}, false, 10, 5},
+		{"defaults", Flapping{Enabled: true}, false, 10, 5},
+		{"custom", Flapping{Enabled: true, HighThreshold: 6, LowThreshold: 2}, false, 6, 2},
+		{"low not below high", Flapping{Enabled: true, HighThreshold: 4, LowThreshold: 4}, true, 0, 0},
+		{"negative window", Flapping{Enabled: true, Window: Duration(-time.Minute)}, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.flapping.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if high, low := tt.flapping.GetHighThreshold(), tt.flapping.GetLowThreshold(); high != tt.expectedHigh || low != tt.expectedLow {
+				t.Errorf("Thresholds = %d and %d; expected %d and %d", high, low, tt.expectedHigh, tt.expectedLow)
+			}
+			if window := tt.flapping.GetWindow(); window != time.Hour {
+				t.Errorf("GetWindow() = %v; expected 1h", window)
+			}
+		})
+	}
+}
+
+func TestScoringValidation(t *testing.T) {
+	tests := []struct {
+		name              string
+		scoring           Scoring
+		expectError       bool
+		expectedDegraded  float64
+		expectedUnhealthy float64
+	}{
+		{"disabled", Scoring{DegradedBelow: 200}, false, 200, 50},
+		{"defaults", Scoring{Enabled: true}, false, 100, 50},
+		{"custom", Scoring{Enabled: true, DegradedBelow: 90, UnhealthyBelow: 70}, false, 90, 70},
+		{"unhealthy above degraded", Scoring{Enabled: true, DegradedBelow: 60, UnhealthyBelow: 70}, true, 0, 0},
+		{"above 100", Scoring{Enabled: true, DegradedBelow: 120}, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scoring.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if degraded, unhealthy := tt.scoring.GetDegradedBelow(), tt.scoring.GetUnhealthyBelow(); degraded != tt.expectedDegraded || unhealthy != tt.expectedUnhealthy {
+				t.Errorf("Thresholds = %g and %g; expected %g and %g", degraded, unhealthy, tt.expectedDegraded, tt.expectedUnhealthy)
+			}
+		})
+	}
+
+	weight := -1.0
+	table := Table{Name: "users", Query: "SELECT 1", Weight: &weight}
+	if err := table.Validate(); err == nil {
+		t.Errorf("Expected an error for a negative weight")
+	}
+	if weight := (&Table{Name: "users"}).GetWeight(); weight != 1 {
+		t.Errorf("Default GetWeight() = %g; expected 1", weight)
+	}
+}
+
+func TestRowCountValidation(t *testing.T) {
+	int64Ptr := func(n int64) *int64 { return &n }
+	tests := []struct {
+		name        string
+		table       Table
+		expectError bool
+	}{
+		{"count by name", Table{Name: "users", Check: "row_count"}, false},
+		{"schema qualified", Table{Name: "orders", Check: "row_count", From: "sales.orders"}, false},
+		{"bounds", Table{Name: "users", Check: "row_count", MinCount: int64Ptr(1), MaxCount: int64Ptr(1000)}, false},
+		{"unknown check", Table{Name: "users", Check: "ping"}, true},
+		{"query and check", Table{Name: "users", Check: "row_count", Query: "SELECT 1"}, true},
+		{"invalid table", Table{Name: "users", Check: "row_count", From: "users; DROP TABLE users"}, true},
+		{"empty schema", Table{Name: "users", Check: "row_count", From: ".users"}, true},
+		{"max below min", Table{Name: "users", Check: "row_count", MinCount: int64Ptr(10), MaxCount: int64Ptr(1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.table.Timeout = Duration(time.Second)
+			tt.table.CheckInterval = Duration(time.Minute)
+			err := tt.table.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+
+	table := Table{Name: "users", Check: "row_count", MinCount: int64Ptr(1), Expect: Expectations{{Column: "count", Operator: "!=", Value: 42}}}
+	if expectations := table.GetExpectations(); len(expectations) != 2 || expectations[1].Operator != ">=" {
+		t.Errorf("GetExpectations() = %+v; expected the configured expectation and the min_count bound", expectations)
+	}
+}
+
+func TestFreshnessValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		table       Table
+		expectError bool
+	}{
+		{"valid", Table{Name: "events", Check: "freshness", Column: "updated_at", MaxAge: Duration(15 * time.Minute)}, false},
+		{"schema qualified", Table{Name: "etl", Check: "freshness", From: "warehouse.events", Column: "loaded_at", MaxAge: Duration(time.Hour)}, false},
+		{"missing column", Table{Name: "events", Check: "freshness", MaxAge: Duration(time.Hour)}, true},
+		{"invalid column", Table{Name: "events", Check: "freshness", Column: "updated_at)", MaxAge: Duration(time.Hour)}, true},
+		{"missing max_age", Table{Name: "events", Check: "freshness", Column: "updated_at"}, true},
+		{"query and check", Table{Name: "events", Check: "freshness", Query: "SELECT 1", Column: "updated_at", MaxAge: Duration(time.Hour)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.table.Timeout = Duration(time.Second)
+			tt.table.CheckInterval = Duration(time.Minute)
+			err := tt.table.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestReplicationLagValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		dbType      string
+		table       Table
+		expectError bool
+	}{
+		{"postgres", "postgres", Table{Name: "replica", Check: "replication_lag", MaxLag: Duration(30 * time.Second)}, false},
+		{"mysql", "mysql", Table{Name: "replica", Check: "replication_lag", MaxLag: Duration(30 * time.Second)}, false},
+		{"mssql", "mssql", Table{Name: "replica", Check: "replication_lag", MaxLag: Duration(30 * time.Second)}, true},
+		{"missing max_lag", "postgres", Table{Name: "replica", Check: "replication_lag"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.table.Timeout = Duration(time.Second)
+			tt.table.CheckInterval = Duration(time.Minute)
+			db := Database{Name: "db", Type: tt.dbType, Host: "localhost", Port: 5432, Username: "user", Database: "app", Tables: []Table{tt.table}}
+			err := db.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		dbType      string
+		table       Table
+		expectError bool
+	}{
+		{"mssql volumes", "mssql", Table{Name: "storage", Check: "storage", MaxUsage: 90}, false},
+		{"postgres capacity", "postgres", Table{Name: "storage", Check: "storage", MaxUsage: 90, CapacityMB: 10240}, false},
+		{"mysql without capacity", "mysql", Table{Name: "storage", Check: "storage", MaxUsage: 90}, true},
+		{"missing max_usage", "mssql", Table{Name: "storage", Check: "storage"}, true},
+		{"max_usage above 100", "mssql", Table{Name: "storage", Check: "storage", MaxUsage: 150}, true},
+		{"negative capacity", "mssql", Table{Name: "storage", Check: "storage", MaxUsage: 90, CapacityMB: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.table.Timeout = Duration(time.Second)
+			tt.table.CheckInterval = Duration(time.Minute)
+			db := Database{Name: "db", Type: tt.dbType, Host: "localhost", Port: 5432, Username: "user", Database: "app", Tables: []Table{tt.table}}
+			err := db.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestMonitoringCheckValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		table       Table
+		expectError bool
+	}{
+		{"warn and critical", Table{Name: "connections", Check: "connections", WarnUsage: 80, CriticalUsage: 95}, false},
+		{"critical only", Table{Name: "connections", Check: "connections", CriticalUsage: 90}, false},
+		{"no thresholds", Table{Name: "connections", Check: "connections"}, true},
+		{"warn above critical", Table{Name: "connections", Check: "connections", WarnUsage: 95, CriticalUsage: 80}, true},
+		{"above 100", Table{Name: "connections", Check: "connections", WarnUsage: 120}, true},
+		{"with query", Table{Name: "connections", Check: "connections", Query: "SELECT 1", WarnUsage: 80}, true},
+		{"blocking", Table{Name: "blocking", Check: "blocking", MaxWait: Duration(time.Minute)}, false},
+		{"blocking without max_wait", Table{Name: "blocking", Check: "blocking"}, true},
+		{"long_queries", Table{Name: "long_queries", Check: "long_queries", MaxDuration: Duration(time.Minute), ShowQueries: true}, false},
+		{"long_queries without max_duration", Table{Name: "long_queries", Check: "long_queries"}, true},
+		{"deadlocks", Table{Name: "deadlocks", Check: "deadlocks"}, false},
+		{"negative max_deadlocks", Table{Name: "deadlocks", Check: "deadlocks", MaxDeadlocks: -1}, true},
+		{"steps", Table{Name: "probe", Steps: []Step{{Query: "SET ROLE app"}, {Query: "SELECT 1", Expect: Expectations{{Column: "1", Value: 1}}}}, Transaction: true}, false},
+		{"steps with query", Table{Name: "probe", Query: "SELECT 1", Steps: []Step{{Query: "SELECT 1"}}}, true},
+		{"steps with table expect", Table{Name: "probe", Steps: []Step{{Query: "SELECT 1"}}, Expect: Expectations{{Column: "1", Value: 1}}}, true},
+		{"step without query", Table{Name: "probe", Steps: []Step{{Name: "empty"}}}, true},
+		{"warning severity", Table{Name: "reports", Query: "SELECT 1", Severity: "warning"}, false},
+		{"unknown severity", Table{Name: "reports", Query: "SELECT 1", Severity: "fatal"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.table.Timeout = Duration(time.Second)
+			tt.table.CheckInterval = Duration(time.Minute)
+			err := tt.table.Validate()
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestNotificationsValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		slack       Slack
+		expectError bool
+	}{
+		{"webhook", Slack{WebhookURL: "https://hooks.slack.com/services/T0/B0/x"}, false},
+		{"token", Slack{Token: "xoxb-token", Channel: "#db-alerts"}, false},
+		{"filtered", Slack{WebhookURL: "https://hooks.slack.com/services/T0/B0/x", NotificationFilter: NotificationFilter{Severities: []string{SeverityCritical}}}, false},
+		{"neither", Slack{Channel: "#db-alerts"}, true},
+		{"both", Slack{WebhookURL: "https://hooks.slack.com/services/T0/B0/x", Token: "xoxb-token", Channel: "#db-alerts"}, true},
+		{"token without channel", Slack{Token: "xoxb-token"}, true},
+		{"invalid webhook", Slack{WebhookURL: "hooks.slack.com"}, true},
+		{"invalid severity", Slack{WebhookURL: "https://hooks.slack.com/services/T0/B0/x", NotificationFilter: NotificationFilter{Severities: []string{"fatal"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications := Notifications{Slack: []Slack{tt.slack}}
+			if err := notifications.Validate(); (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	notifications := Notifications{RepeatInterval: Duration(-time.Minute)}
+	if err := notifications.Validate(); err == nil {
+		t.Error("Validate() expected an error for a negative repeat_interval")
+	}
+}
+
+func TestPagerDutyValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		pagerDuty   PagerDuty
+		expectError bool
+	}{
+		{"routing key", PagerDuty{RoutingKey: "R0UT1NG"}, false},
+		{"url", PagerDuty{RoutingKey: "R0UT1NG", URL: "https://events.eu.pagerduty.com/v2/enqueue"}, false},
+		{"no routing key", PagerDuty{}, true},
+		{"invalid url", PagerDuty{RoutingKey: "R0UT1NG", URL: "events.pagerduty.com"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications := Notifications{PagerDuty: []PagerDuty{tt.pagerDuty}}
+			if err := notifications.Validate(); (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	pagerDuty := PagerDuty{RoutingKey: "R0UT1NG"}
+	if url := pagerDuty.GetURL(); url != "https://events.pagerduty.com/v2/enqueue" {
+		t.Errorf("GetURL() = %q; expected the PagerDuty endpoint", url)
+	}
+	if filter := pagerDuty.GetFilter(); !reflect.DeepEqual(filter.Severities, []string{SeverityCritical}) {
+		t.Errorf("GetFilter() severities = %v; expected critical checks only", filter.Severities)
+	}
+	pagerDuty.Severities = []string{SeverityWarning}
+	if filter := pagerDuty.GetFilter(); !reflect.DeepEqual(filter.Severities, []string{SeverityWarning}) {
+		t.Errorf("GetFilter() severities = %v; expected the configured severities", filter.Severities)
+	}
+}
+
+func TestAlertmanagerValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		alertmanager Alertmanager
+		expectError  bool
+	}{
+		{"url", Alertmanager{URL: "http://alertmanager:9093"}, false},
+		{"basic auth", Alertmanager{URL: "https://alertmanager.example.com", Username: "gsqlhealth", Password: "secret"}, false},
+		{"no url", Alertmanager{}, true},
+		{"invalid url", Alertmanager{URL: "alertmanager:9093"}, true},
+		{"negative resend interval", Alertmanager{URL: "http://alertmanager:9093", ResendInterval: Duration(-time.Minute)}, true},
+		{"both authentications", Alertmanager{URL: "http://alertmanager:9093", BearerToken: "token", Username: "gsqlhealth"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifications := Notifications{Alertmanager: []Alertmanager{tt.alertmanager}}
+			if err := notifications.Validate(); (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	alertmanager := Alertmanager{URL: "http://alertmanager:9093/"}
+	if url := alertmanager.GetAlertsURL(); url != "http://alertmanager:9093/api/v2/alerts" {
+		t.Errorf("GetAlertsURL() = %q; expected the v2 alerts endpoint", url)
+	}
+	if interval := alertmanager.GetResendInterval(); interval != time.Minute {
+		t.Errorf("GetResendInterval() = %v; expected 1m", interval)
+	}
+}
+
+func TestAWSNotifiersValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		notifications  Notifications
+		expectError    bool
+		expectedRegion string
+	}{
+		{"sns", Notifications{SNS: []SNS{{TopicARN: "arn:aws:sns:eu-west-1:123456789012:db-health"}}}, false, "eu-west-1"},
+		{"sns fifo", Notifications{SNS: []SNS{{TopicARN: "arn:aws:sns:us-east-2:123456789012:db-health.fifo", RoleARN: "arn:aws:iam::123456789012:role/publisher"}}}, false, "us-east-2"},
+		{"sns invalid arn", Notifications{SNS: []SNS{{TopicARN: "db-health"}}}, true, ""},
+		{"sns invalid endpoint", Notifications{SNS: []SNS{{TopicARN: "arn:aws:sns:eu-west-1:123456789012:db-health", Endpoint: "localstack:4566"}}}, true, ""},
+		{"sqs", Notifications{SQS: []SQS{{QueueURL: "https://sqs.ap-southeast-2.amazonaws.com/123456789012/db-health"}}}, false, "ap-southeast-2"},
+		{"sqs legacy url", Notifications{SQS: []SQS{{QueueURL: "https://eu-central-1.queue.amazonaws.com/123456789012/db-health"}}}, false, "eu-central-1"},
+		{"sqs other host", Notifications{SQS: []SQS{{QueueURL: "http://localstack:4566/000000000000/db-health", Region: "us-east-1"}}}, false, "us-east-1"},
+		{"sqs other host without region", Notifications{SQS: []SQS{{QueueURL: "http://localstack:4566/000000000000/db-health"}}}, true, ""},
+		{"sqs invalid url", Notifications{SQS: []SQS{{QueueURL: "https://sqs.eu-west-1.amazonaws.com/db-health"}}}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.notifications.Validate()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+
+			region := ""
+			if len(tt.notifications.SNS) > 0 {
+				region = tt.notifications.SNS[0].GetRegion()
+			} else {
+				region = tt.notifications.SQS[0].GetRegion()
+			}
+			if region != tt.expectedRegion {
+				t.Errorf("GetRegion() = %q; expected %q", region, tt.expectedRegion)
+			}
+		})
+	}
+
+	sns := SNS{TopicARN: "arn:aws:sns:eu-west-1:123456789012:db-health"}
+	if endpoint := sns.GetEndpoint(); endpoint != "https://sns.eu-west-1.amazonaws.com/" {
+		t.Errorf("GetEndpoint() = %q; expected the region's endpoint", endpoint)
+	}
+}
+
+func TestNotificationFilterMatches(t *testing.T) {
+	filter := NotificationFilter{Severities: []string{SeverityCritical, SeverityWarning}, Labels: map[string]string{"team": "payments"}}
+
+	tests := []struct {
+		severity string
+		labels   map[string]string
+		expected bool
+	}{
+		{SeverityCritical, map[string]string{"team": "payments", "env": "prod"}, true},
+		{SeverityWarning, map[string]string{"team": "payments"}, true},
+		{SeverityInfo, map[string]string{"team": "payments"}, false},
+		{SeverityCritical, map[string]string{"team": "search"}, false},
+		{SeverityCritical, nil, false},
+	}
+
+	for _, tt := range tests {
+		if matches := filter.Matches(tt.severity, tt.labels); matches != tt.expected {
+			t.Errorf("Matches(%s, %v) = %v; expected %v", tt.severity, tt.labels, matches, tt.expected)
+		}
+	}
+
+	if empty := (NotificationFilter{}); !empty.Matches(SeverityInfo, nil) {
+		t.Errorf("Expected an empty filter to match every check")
+	}
+}
+
+func TestMQTTValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		mqtt        MQTT
+		expectError bool
+	}{
+		{"disabled", MQTT{Broker: "not a url"}, false},
+		{"tcp", MQTT{Enabled: true, Broker: "tcp://mosquitto:1883", QoS: 1, Retain: true}, false},
+		{"tls with ca", MQTT{Enabled: true, Broker: "ssl://broker.example.com:8883", CAFile: "/etc/ssl/broker-ca.pem", Username: "monitor", Password: "secret"}, false},
+		{"custom topics", MQTT{Enabled: true, Broker: "mqtt://mosquitto", StatusTopic: "site/db/{database}/{table}", ConnectionTopic: "site/db/{database}"}, false},
+		{"missing broker", MQTT{Enabled: true}, true},
+		{"invalid scheme", MQTT{Enabled: true, Broker: "http://mosquitto:1883"}, true},
+		{"ca without tls", MQTT{Enabled: true, Broker: "tcp://mosquitto:1883", CAFile: "/etc/ssl/broker-ca.pem"}, true},
+		{"password without username", MQTT{Enabled: true, Broker: "tcp://mosquitto:1883", Password: "secret"}, true},
+		{"invalid qos", MQTT{Enabled: true, Broker: "tcp://mosquitto:1883", QoS: 3}, true},
+		{"invalid keep alive", MQTT{Enabled: true, Broker: "tcp://mosquitto:1883", KeepAlive: Duration(24 * time.Hour)}, true},
+		{"wildcard topic", MQTT{Enabled: true, Broker: "tcp://mosquitto:1883", EventTopic: "gsqlhealth/+/events"}, true},
+		{"connection topic with table", MQTT{Enabled: true, Broker: "tcp://mosquitto:1883", ConnectionTopic: "gsqlhealth/{database}/{table}"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.mqtt.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	mqtt := MQTT{Broker: "tcp://mosquitto:1883"}
+	if mqtt.GetStatusTopic() != "gsqlhealth/{database}/{table}/status" || mqtt.GetKeepAlive() != 30*time.Second || mqtt.UsesTLS() {
+		t.Errorf("expected the defaults of a plain TCP broker")
+	}
+}
+
+func TestPushgatewayValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		pushgateway Pushgateway
+		expectError bool
+	}{
+		{"disabled", Pushgateway{}, false},
+		{"url", Pushgateway{URL: "http://pushgateway:9091"}, false},
+		{"labels", Pushgateway{URL: "https://pushgateway.example.com", Job: "nightly", Labels: map[string]string{"env": "prod"}, Username: "pusher", Password: "secret"}, false},
+		{"invalid url", Pushgateway{URL: "pushgateway:9091"}, true},
+		{"password without username", Pushgateway{URL: "http://pushgateway:9091", Password: "secret"}, true},
+		{"negative timeout", Pushgateway{URL: "http://pushgateway:9091", Timeout: Duration(-time.Second)}, true},
+		{"invalid label", Pushgateway{URL: "http://pushgateway:9091", Labels: map[string]string{"env-name": "prod"}}, true},
+		{"job label", Pushgateway{URL: "http://pushgateway:9091", Labels: map[string]string{"job": "nightly"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.pushgateway.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	pushgateway := Pushgateway{URL: "http://pushgateway:9091"}
+	if pushgateway.GetJob() != "gsqlhealth" || pushgateway.GetTimeout() != 10*time.Second {
+		t.Errorf("expected the default job and timeout")
+	}
+}
+
+func TestConsulValidation(t *testing.T) {
+	server := Server{Host: "0.0.0.0", Port: 8080}
+	tests := []struct {
+		name        string
+		consul      Consul
+		server      Server
+		expectError bool
+	}{
+		{"disabled", Consul{Check: "grpc"}, server, false},
+		{"defaults", Consul{Enabled: true}, server, false},
+		{"ttl", Consul{Enabled: true, Check: ConsulCheckTTL, TTL: Duration(15 * time.Second), Databases: true}, Server{}, false},
+		{"check url", Consul{Enabled: true, CheckURL: "https://gsqlhealth.service.consul:8443"}, Server{}, false},
+		{"http without port", Consul{Enabled: true}, Server{}, true},
+		{"invalid check", Consul{Enabled: true, Check: "grpc"}, server, true},
+		{"invalid check url", Consul{Enabled: true, CheckURL: "gsqlhealth:8080"}, server, true},
+		{"short ttl", Consul{Enabled: true, Check: ConsulCheckTTL, TTL: Duration(time.Second)}, server, true},
+		{"negative interval", Consul{Enabled: true, Interval: Duration(-time.Second)}, server, true},
+		{"service name without database", Consul{Enabled: true, DatabaseServiceName: "db"}, server, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.consul.Validate(tt.server)
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	consul := Consul{Address: "consul:8500", DatabaseServiceName: "db-{database}"}
+	if consul.GetAddress() != "http://consul:8500" || consul.GetCheckType() != ConsulCheckHTTP || consul.GetTTL() != 30*time.Second {
+		t.Errorf("expected the address with a scheme and the default check")
+	}
+	if name := consul.GetDatabaseServiceName("Orders_DB"); name != "db-orders-db" {
+		t.Errorf("GetDatabaseServiceName() = %q; expected db-orders-db", name)
+	}
+	if checkURL := consul.GetCheckURL(server); checkURL != "http://127.0.0.1:8080" {
+		t.Errorf("GetCheckURL() = %q; expected the loopback address", checkURL)
+	}
+}
+
+func TestPassiveChecksValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		passive     PassiveChecks
+		expectError bool
+	}{
+		{"disabled", PassiveChecks{Protocol: "snmp"}, false},
+		{"nsca", PassiveChecks{Enabled: true, Protocol: PassiveProtocolNSCA, Address: "nagios.example.com"}, false},
+		{"nsca xor", PassiveChecks{Enabled: true, Protocol: PassiveProtocolNSCA, Address: "nagios:5667", Encryption: NSCAEncryptionXOR, Password: "secret"}, false},
+		{"icinga2", PassiveChecks{Enabled: true, Protocol: PassiveProtocolIcinga2, Address: "https://icinga:5665", Username: "gsqlhealth", Password: "secret", HostName: "db-{database}"}, false},
+		{"invalid protocol", PassiveChecks{Enabled: true, Protocol: "snmp", Address: "nagios"}, true},
+		{"nsca without address", PassiveChecks{Enabled: true, Protocol: PassiveProtocolNSCA}, true},
+		{"nsca url", PassiveChecks{Enabled: true, Protocol: PassiveProtocolNSCA, Address: "tcp://nagios:5667"}, true},
+		{"nsca password without encryption", PassiveChecks{Enabled: true, Protocol: PassiveProtocolNSCA, Address: "nagios", Password: "secret"}, true},
+		{"nsca unsupported encryption", PassiveChecks{Enabled: true, Protocol: PassiveProtocolNSCA, Address: "nagios", Encryption: "3des", Password: "secret"}, true},
+		{"icinga2 without username", PassiveChecks{Enabled: true, Protocol: PassiveProtocolIcinga2, Address: "https://icinga:5665"}, true},
+		{"icinga2 invalid address", PassiveChecks{Enabled: true, Protocol: PassiveProtocolIcinga2, Address: "icinga:5665", Username: "gsqlhealth"}, true},
+		{"service without table", PassiveChecks{Enabled: true, Protocol: PassiveProtocolNSCA, Address: "nagios", ServiceName: "{database}"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.passive.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	passive := PassiveChecks{Protocol: PassiveProtocolNSCA, Address: "nagios"}
+	if passive.GetAddress() != "nagios:5667" || passive.GetHostName() != "{database}" || passive.GetTimeout() != 10*time.Second {
+		t.Errorf("expected the default port, host name and timeout")
+	}
+}
+
+func TestZabbixValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		zabbix      Zabbix
+		expectError bool
+	}{
+		{"disabled", Zabbix{}, false},
+		{"server", Zabbix{Enabled: true, Server: "zabbix.example.com", Host: "db-monitor"}, false},
+		{"server with port", Zabbix{Enabled: true, Server: "zabbix-proxy:10051", Timeout: Duration(5 * time.Second)}, false},
+		{"missing server", Zabbix{Enabled: true}, true},
+		{"url", Zabbix{Enabled: true, Server: "tcp://zabbix:10051"}, true},
+		{"negative timeout", Zabbix{Enabled: true, Server: "zabbix", Timeout: Duration(-time.Second)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.zabbix.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	zabbix := Zabbix{Server: "zabbix"}
+	if zabbix.GetServer() != "zabbix:10051" || zabbix.GetTimeout() != 10*time.Second {
+		t.Errorf("expected the default port and timeout")
+	}
+}
+
+func TestAuditValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		audit       Audit
+		expectError bool
+	}{
+		{"disabled", Audit{}, false},
+		{"file", Audit{Enabled: true, Output: "/var/log/gsqlhealth/audit.log", ExemptPaths: []string{"/metrics"}}, false},
+		{"stderr", Audit{Enabled: true, Output: AuditOutputStderr, AdminOnly: true}, false},
+		{"missing output", Audit{Enabled: true}, true},
+		{"relative exempt path", Audit{Enabled: true, Output: AuditOutputStdout, ExemptPaths: []string{"metrics"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.audit.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestLoggingValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		logging     Logging
+		expectError bool
+	}{
+		{"default", Logging{}, false},
+		{"stderr", Logging{Output: LogOutputStderr}, false},
+		{"file", Logging{Output: LogOutputFile, Path: "/var/log/gsqlhealth/gsqlhealth.log", MaxSize: 50, MaxBackups: 5, MaxAge: 30, Compress: true}, false},
+		{"file without path", Logging{Output: LogOutputFile}, true},
+		{"path without file", Logging{Path: "gsqlhealth.log"}, true},
+		{"invalid output", Logging{Output: "syslog"}, true},
+		{"negative max size", Logging{Output: LogOutputFile, Path: "gsqlhealth.log", MaxSize: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.logging.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v; expected error %v", err, tt.expectError)
+			}
+		})
+	}
+
+	logging := Logging{Output: LogOutputFile, Path: "gsqlhealth.log"}
+	if logging.GetMaxSize() != 100 {
+		t.Errorf("GetMaxSize() = %d; expected 100", logging.GetMaxSize())
+	}
+}
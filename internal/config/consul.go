@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Consul check types
+const (
+	ConsulCheckHTTP = "http" // Consul polls /health/{database}
+	ConsulCheckTTL  = "ttl"  // gsqlhealth reports the status to Consul
+)
+
+// Consul represents the optional registration of gsqlhealth, and of a
+// synthetic service per database, in the local Consul agent. The check of
+// each database service follows the health of the database, so Consul DNS
+// and service mesh routing only resolve databases that are healthy.
+type Consul struct {
+	Enabled             bool              `yaml:"enabled"`
+	Address             string            `yaml:"address"`               // agent URL (default CONSUL_HTTP_ADDR, or http://127.0.0.1:8500)
+	Token               string            `yaml:"token"`                 // ACL token (default CONSUL_HTTP_TOKEN)
+	ServiceName         string            `yaml:"service_name"`          // default gsqlhealth
+	ServiceID           string            `yaml:"service_id"`            // default <service_name>-<hostname>
+	ServiceAddress      string            `yaml:"service_address"`       // advertised address of gsqlhealth (default the agent's)
+	Tags                []string          `yaml:"tags"`                  // tags of all registered services
+	Check               string            `yaml:"check"`                 // http or ttl (default http)
+	CheckURL            string            `yaml:"check_url"`             // URL Consul reaches gsqlhealth at for HTTP checks (default the server's address)
+	CheckHeaders        map[string]string `yaml:"check_headers"`         // headers of HTTP checks, e.g. an API key
+	TLSSkipVerify       bool              `yaml:"tls_skip_verify"`       // skip certificate verification of HTTPS checks
+	Interval            Duration          `yaml:"interval"`              // interval of HTTP checks (default 10s)
+	TTL                 Duration          `yaml:"ttl"`                   // TTL of ttl checks, reported at a third of it (default 30s)
+	DeregisterAfter     Duration          `yaml:"deregister_after"`      // deregister services critical for this long (default never)
+	Databases           bool              `yaml:"databases"`             // register a service per enabled database
+	DatabaseServiceName string            `yaml:"database_service_name"` // name template of database services (default {database})
+}
+
+// Consul defaults
+const (
+	defaultConsulServiceName = "gsqlhealth"
+	defaultConsulInterval    = 10 * time.Second
+	defaultConsulTTL         = 30 * time.Second
+)
+
+// Validate validates Consul configuration against the HTTP server the checks
+// reach
+func (c *Consul) Validate(server Server) error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if _, err := url.Parse(c.GetAddress()); err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+
+	switch c.Check {
+	case "", ConsulCheckHTTP:
+		if c.CheckURL == "" && server.Port == 0 {
+			return fmt.Errorf("http checks require check_url when the server has no port")
+		}
+		if c.CheckURL != "" {
+			parsed, err := url.Parse(c.CheckURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("invalid check_url: %q (expected http:// or https://)", c.CheckURL)
+			}
+		}
+	case ConsulCheckTTL:
+	default:
+		return fmt.Errorf("invalid check: %q (expected http or ttl)", c.Check)
+	}
+
+	if c.Interval < 0 || c.TTL < 0 || c.DeregisterAfter < 0 {
+		return fmt.Errorf("interval, ttl and deregister_after must not be negative")
+	}
+	if c.TTL != 0 && time.Duration(c.TTL) < 3*time.Second {
+		return fmt.Errorf("ttl must be at least 3s")
+	}
+
+	if c.DatabaseServiceName != "" && !strings.Contains(c.DatabaseServiceName, "{database}") {
+		return fmt.Errorf("database_service_name must contain {database}")
+	}
+	return nil
+}
+
+// GetAddress returns the agent URL, defaulting to CONSUL_HTTP_ADDR and then
+// the local agent
+func (c *Consul) GetAddress() string {
+	return consulAddress(c.Address)
+}
+
+// GetToken returns the ACL token, defaulting to CONSUL_HTTP_TOKEN
+func (c *Consul) GetToken() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	return os.Getenv("CONSUL_HTTP_TOKEN")
+}
+
+// GetServiceName returns the service name of gsqlhealth
+func (c *Consul) GetServiceName() string {
+	if c.ServiceName == "" {
+		return defaultConsulServiceName
+	}
+	return c.ServiceName
+}
+
+// GetServiceID returns the service ID of gsqlhealth, unique on the agent
+func (c *Consul) GetServiceID() string {
+	if c.ServiceID != "" {
+		return c.ServiceID
+	}
+	hostname, _ := os.Hostname()
+	return c.GetServiceName() + "-" + hostname
+}
+
+// GetCheckType returns the check type, defaulting to http
+func (c *Consul) GetCheckType() string {
+	if c.Check == "" {
+		return ConsulCheckHTTP
+	}
+	return c.Check
+}
+
+// GetCheckURL returns the URL Consul reaches gsqlhealth at, defaulting to the
+// server's host and port, or the loopback address for wildcard hosts
+func (c *Consul) GetCheckURL(server Server) string {
+	if c.CheckURL != "" {
+		return strings.TrimSuffix(c.CheckURL, "/")
+	}
+
+	scheme := "http"
+	if server.TLS.IsEnabled() {
+		scheme = "https"
+	}
+	host := server.Host
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(server.Port))
+}
+
+// GetInterval returns the interval of HTTP checks
+func (c *Consul) GetInterval() time.Duration {
+	if c.Interval == 0 {
+		return defaultConsulInterval
+	}
+	return time.Duration(c.Interval)
+}
+
+// GetTTL returns the TTL of ttl checks
+func (c *Consul) GetTTL() time.Duration {
+	if c.TTL == 0 {
+		return defaultConsulTTL
+	}
+	return time.Duration(c.TTL)
+}
+
+// GetDatabaseServiceName returns the service name of a database: the
+// template with {database} replaced by the name, in the lowercase letters,
+// digits and hyphens of DNS labels
+func (c *Consul) GetDatabaseServiceName(databaseName string) string {
+	template := c.DatabaseServiceName
+	if template == "" {
+		template = "{database}"
+	}
+	name := strings.ToLower(strings.ReplaceAll(template, "{database}", databaseName))
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '-'
+	}, name), "-")
+}
+
+// consulAddress returns a Consul agent URL: the configured one, else
+// CONSUL_HTTP_ADDR, else the local agent. Addresses without a scheme are
+// taken as http.
+func consulAddress(configured string) string {
+	address := configured
+	if address == "" {
+		address = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if address == "" {
+		address = defaultConsulAddress
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	return address
+}
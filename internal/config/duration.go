@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a configuration duration. In YAML it accepts either an integer
+// number of seconds (the original format) or a Go duration string such as
+// "500ms", "90s" or "1h30m".
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: duration must be a number of seconds or a duration string", node.Line)
+	}
+
+	if seconds, err := strconv.ParseFloat(node.Value, 64); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q: %w", node.Line, node.Value, err)
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// String returns the duration in Go duration notation
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDurationUnmarshal(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{input: "30", expected: 30 * time.Second},
+		{input: "0.5", expected: 500 * time.Millisecond},
+		{input: `"90s"`, expected: 90 * time.Second},
+		{input: `"500ms"`, expected: 500 * time.Millisecond},
+		{input: "1h30m", expected: 90 * time.Minute},
+		{input: `"soon"`, expectError: true},
+		{input: "[1, 2]", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var value struct {
+				Timeout Duration `yaml:"timeout"`
+			}
+			err := yaml.Unmarshal([]byte("timeout: "+tt.input), &value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if time.Duration(value.Timeout) != tt.expected {
+				t.Errorf("Duration = %v; expected %v", time.Duration(value.Timeout), tt.expected)
+			}
+		})
+	}
+}
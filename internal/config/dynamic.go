@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dynamicDatabases is the layout of the dynamic_databases file
+type dynamicDatabases struct {
+	Databases []Database `yaml:"databases"`
+}
+
+// ReadDynamicDatabases reads the databases added through the admin API from
+// a dynamic_databases file, as they were submitted. A missing file holds no
+// databases.
+func ReadDynamicDatabases(path string) ([]Database, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file dynamicDatabases
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file.Databases, nil
+}
+
+// WriteDynamicDatabases replaces the databases of a dynamic_databases file.
+// The file holds connection passwords, so it is only readable by its owner,
+// and it is replaced atomically so a crash never leaves it half written.
+func WriteDynamicDatabases(path string, databases []Database) error {
+	data, err := yaml.Marshal(dynamicDatabases{Databases: databases})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadDynamicDatabases adds the databases of the dynamic_databases file to
+// the configured databases
+func (c *Config) loadDynamicDatabases() error {
+	if c.DynamicDatabases == "" {
+		return nil
+	}
+
+	databases, err := ReadDynamicDatabases(c.DynamicDatabases)
+	if err != nil {
+		return fmt.Errorf("failed to read dynamic databases: %w", err)
+	}
+
+	for _, db := range databases {
+		for _, configured := range c.Databases {
+			if configured.Name == db.Name {
+				return fmt.Errorf("dynamic database %s is also configured; remove it from %s", db.Name, c.DynamicDatabases)
+			}
+		}
+		db.Dynamic = true
+		c.Databases = append(c.Databases, db)
+	}
+	return nil
+}
+
+// PrepareDatabase returns a database definition submitted through the admin
+// API as it would be configured: with its connection check and the global
+// table defaults applied, validated
+func (c *Config) PrepareDatabase(db Database) (Database, error) {
+	prepared := Config{
+		Databases:            []Database{db},
+		QueryTimeoutDefault:  c.QueryTimeoutDefault,
+		CheckIntervalDefault: c.CheckIntervalDefault,
+		Jitter:               c.Jitter,
+	}
+	// Tables are filled in place, so the submitted definition gets its own
+	prepared.Databases[0].Tables = append([]Table(nil), db.Tables...)
+
+	if err := prepared.applyConnectionChecks(); err != nil {
+		return Database{}, err
+	}
+	prepared.applyTableDefaults()
+
+	db = prepared.Databases[0]
+	if err := db.Validate(); err != nil {
+		return Database{}, err
+	}
+	db.Dynamic = true
+	return db, nil
+}
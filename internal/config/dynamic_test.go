@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfigDynamicDatabases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dynamic.yaml")
+	configContent := `
+check_interval_default: "1m"
+query_timeout_default: "5s"
+dynamic_databases: ` + path + `
+server:
+  host: "localhost"
+  port: 8080
+  read_timeout: 30
+  write_timeout: 30
+  idle_timeout: 120
+  auth:
+    api_keys:
+      - name: "provisioner"
+        key: "0123456789abcdef0123"
+`
+
+	// Without the file, no databases are configured yet
+	config, err := ParseConfig([]byte(configContent))
+	if err != nil {
+		t.Fatalf("ParseConfig without dynamic databases failed: %v", err)
+	}
+	if len(config.Databases) != 0 {
+		t.Fatalf("Databases = %d; expected none", len(config.Databases))
+	}
+
+	// Anyone could otherwise add databases
+	unauthenticated := strings.Replace(configContent, "  auth:", "  unused:", 1)
+	if _, err := ParseConfig([]byte(unauthenticated)); err == nil || !strings.Contains(err.Error(), "requires server.auth") {
+		t.Errorf("ParseConfig without auth error = %v; expected dynamic_databases to require auth", err)
+	}
+
+	submitted := Database{Name: "reports", Type: "postgres", Host: "localhost", Port: 5432, Username: "user", Database: "reports"}
+	prepared, err := config.PrepareDatabase(submitted)
+	if err != nil {
+		t.Fatalf("PrepareDatabase() error = %v", err)
+	}
+	if !prepared.Dynamic || len(prepared.Tables) != 1 || prepared.Tables[0].Check != CheckConnection {
+		t.Fatalf("PrepareDatabase() = %+v; expected a dynamic database with a connection check", prepared)
+	}
+	if got := prepared.Tables[0].GetCheckInterval(); got != time.Minute {
+		t.Errorf("check_interval = %v; expected the global default 1m", got)
+	}
+
+	if err := WriteDynamicDatabases(path, []Database{submitted}); err != nil {
+		t.Fatalf("WriteDynamicDatabases() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("file mode = %v; expected 0600", mode)
+	}
+
+	config, err = ParseConfig([]byte(configContent))
+	if err != nil {
+		t.Fatalf("ParseConfig with dynamic databases failed: %v", err)
+	}
+	if len(config.Databases) != 1 || !config.Databases[0].Dynamic || len(config.Databases[0].Tables) != 1 {
+		t.Fatalf("Databases = %+v; expected reports with its connection check", config.Databases)
+	}
+
+	// A dynamic database may not shadow a configured one
+	clashing := configContent + `
+databases:
+  - name: "reports"
+    type: "postgres"
+    host: "localhost"
+    port: 5432
+    username: "user"
+    database: "reports"
+`
+	if _, err := ParseConfig([]byte(clashing)); err == nil || !strings.Contains(err.Error(), "also configured") {
+		t.Errorf("ParseConfig() error = %v; expected a clash with the configured database", err)
+	}
+
+	if _, err := config.PrepareDatabase(Database{Name: "broken", Type: "oracle"}); err == nil {
+		t.Error("Expected PrepareDatabase to reject an invalid database")
+	}
+}
@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ageArmorHeader marks an ASCII-armored age file
+	ageArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+	// ageBinaryHeader marks a binary age file
+	ageBinaryHeader = "age-encryption.org/v1"
+	// sopsMetadataKey is the top-level key SOPS uses for its metadata block
+	sopsMetadataKey = "sops"
+)
+
+// sopsValuePattern matches a single SOPS-encrypted value
+var sopsValuePattern = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// sopsMetadata holds the parts of the SOPS metadata block needed for decryption
+type sopsMetadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+	LastModified     string `yaml:"lastmodified"`
+	MAC              string `yaml:"mac"`
+	MACOnlyEncrypted bool   `yaml:"mac_only_encrypted"`
+}
+
+// decryptConfig returns the plaintext configuration, transparently decrypting
+// whole-file age encryption or SOPS-encrypted values when they are detected.
+// Unencrypted data is returned unchanged.
+func decryptConfig(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+
+	if bytes.HasPrefix(trimmed, []byte(ageArmorHeader)) || bytes.HasPrefix(trimmed, []byte(ageBinaryHeader)) {
+		return decryptAgeFile(data)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		// Leave parse errors to the caller so they are reported consistently
+		return data, nil
+	}
+
+	if metadata := findSOPSMetadata(&root); metadata != nil {
+		return decryptSOPS(&root, metadata)
+	}
+
+	return data, nil
+}
+
+// decryptAgeFile decrypts a configuration file encrypted as a whole with age
+func decryptAgeFile(data []byte) ([]byte, error) {
+	identities, err := loadAgeIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(ageArmorHeader)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age encrypted config: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age encrypted config: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// findSOPSMetadata returns the SOPS metadata node of a document, if present
+func findSOPSMetadata(root *yaml.Node) *yaml.Node {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil
+	}
+
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == sopsMetadataKey {
+			return doc.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// decryptSOPS decrypts every SOPS-encrypted value in the document, verifies
+// the MAC of the whole document and returns the resulting plaintext YAML
+// without the metadata block. Only age key groups are supported; the data key
+// is unwrapped with the identities from the environment.
+func decryptSOPS(root *yaml.Node, metadataNode *yaml.Node) ([]byte, error) {
+	var metadata sopsMetadata
+	if err := metadataNode.Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse sops metadata: %w", err)
+	}
+
+	if len(metadata.Age) == 0 {
+		return nil, fmt.Errorf("sops encrypted config has no age recipients (only age keys are supported)")
+	}
+
+	identities, err := loadAgeIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	var dataKey []byte
+	var lastErr error
+	for _, entry := range metadata.Age {
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(entry.Enc))), identities...)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if dataKey, err = io.ReadAll(r); err != nil {
+			lastErr = err
+			continue
+		}
+		break
+	}
+
+	if dataKey == nil {
+		return nil, fmt.Errorf("failed to decrypt sops data key: %w", lastErr)
+	}
+
+	decryption := &sopsDecryption{
+		dataKey:          dataKey,
+		mac:              sha512.New(),
+		macOnlyEncrypted: metadata.MACOnlyEncrypted,
+	}
+
+	doc := root.Content[0]
+	decrypted := &yaml.Node{Kind: yaml.MappingNode, Tag: doc.Tag}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		if key.Value == sopsMetadataKey {
+			continue
+		}
+		if err := decryption.decryptNode(value, []string{key.Value}); err != nil {
+			return nil, err
+		}
+		decrypted.Content = append(decrypted.Content, key, value)
+	}
+
+	if err := verifySOPSMAC(metadata, dataKey, fmt.Sprintf("%X", decryption.mac.Sum(nil))); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := yaml.Marshal(decrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode decrypted config: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// verifySOPSMAC compares the message authentication code of the decrypted
+// values with the one recorded by SOPS, which is encrypted with the
+// modification time as additional data. A mismatch means values were
+// swapped, removed or added after the file was encrypted.
+func verifySOPSMAC(metadata sopsMetadata, dataKey []byte, computed string) error {
+	if metadata.MAC == "" {
+		return fmt.Errorf("sops encrypted config has no mac")
+	}
+	lastModified, err := time.Parse(time.RFC3339, metadata.LastModified)
+	if err != nil {
+		return fmt.Errorf("invalid sops lastmodified: %q", metadata.LastModified)
+	}
+
+	recorded, _, err := decryptSOPSValue(metadata.MAC, lastModified.Format(time.RFC3339), dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt sops mac: %w", err)
+	}
+	if recorded != computed {
+		return fmt.Errorf("sops mac mismatch: the encrypted config was modified")
+	}
+
+	return nil
+}
+
+// sopsDecryption decrypts the values of a SOPS document, hashing the
+// plaintext of its values in document order for the MAC
+type sopsDecryption struct {
+	dataKey          []byte
+	mac              hash.Hash
+	macOnlyEncrypted bool // only encrypted values are part of the MAC
+}
+
+// decryptNode walks a YAML node and decrypts encrypted scalars in place.
+// SOPS authenticates each value with the colon-joined path of mapping keys
+// leading to it; sequence items share the path of their parent key.
+func (d *sopsDecryption) decryptNode(node *yaml.Node, path []string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyPath := append(append([]string{}, path...), node.Content[i].Value)
+			if err := d.decryptNode(node.Content[i+1], keyPath); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if err := d.decryptNode(item, path); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.HasPrefix(node.Value, "ENC[") {
+			if d.macOnlyEncrypted {
+				return nil
+			}
+			value, ok := sopsMACValue(node)
+			if ok {
+				d.mac.Write([]byte(value))
+			}
+			return nil
+		}
+		value, tag, err := decryptSOPSValue(node.Value, strings.Join(path, ":")+":", d.dataKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt value at %s: %w", strings.Join(path, "."), err)
+		}
+		d.mac.Write([]byte(value))
+		node.Value = value
+		node.Tag = tag
+		node.Style = 0
+	}
+
+	return nil
+}
+
+// sopsMACValue returns the text SOPS hashes for an unencrypted scalar, which
+// is formatted from its decoded value. Nulls are not part of the MAC.
+func sopsMACValue(node *yaml.Node) (string, bool) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return node.Value, true
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "", false
+	case bool:
+		if v {
+			return "True", true
+		}
+		return "False", true
+	case int:
+		return strconv.Itoa(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case string:
+		return v, true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// decryptSOPSValue decrypts a single ENC[AES256_GCM,...] value and returns the
+// plaintext along with the YAML tag matching its recorded type
+func decryptSOPSValue(encrypted, additionalData string, dataKey []byte) (string, string, error) {
+	matches := sopsValuePattern.FindStringSubmatch(encrypted)
+	if matches == nil {
+		return "", "", fmt.Errorf("malformed sops value")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(matches[1])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid data encoding: %w", err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(matches[2])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid iv encoding: %w", err)
+	}
+	tag, err := base64.StdEncoding.DecodeString(matches[3])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid tag encoding: %w", err)
+	}
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid data key: %w", err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to initialize cipher: %w", err)
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", "", errors.New("authentication failed (wrong key or tampered value)")
+	}
+
+	switch matches[4] {
+	case "int":
+		return string(plaintext), "!!int", nil
+	case "float":
+		return string(plaintext), "!!float", nil
+	case "bool":
+		return string(plaintext), "!!bool", nil
+	default:
+		return string(plaintext), "!!str", nil
+	}
+}
+
+// loadAgeIdentities loads age identities using the same environment variables
+// as the sops CLI: SOPS_AGE_KEY (inline keys), SOPS_AGE_KEY_FILE, or the
+// default sops keys file in the user configuration directory
+func loadAgeIdentities() ([]age.Identity, error) {
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		identities, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SOPS_AGE_KEY: %w", err)
+		}
+		return identities, nil
+	}
+
+	keyFile := os.Getenv("SOPS_AGE_KEY_FILE")
+	if keyFile == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("no age key configured: set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE")
+		}
+		keyFile = filepath.Join(configDir, "sops", "age", "keys.txt")
+	}
+
+	f, err := os.Open(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("no age key available (set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE): %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age key file %s: %w", keyFile, err)
+	}
+
+	return identities, nil
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in check types
+const (
+	// CheckRowCount counts the rows of a table
+	CheckRowCount = "row_count"
+	// CheckFreshness checks the age of the newest row of a table
+	CheckFreshness = "freshness"
+	// CheckReplicationLag checks how far a replica is behind its source
+	CheckReplicationLag = "replication_lag"
+	// CheckStorage checks how much of its storage a database uses
+	CheckStorage = "storage"
+	// CheckConnections checks how many of its allowed connections a database uses
+	CheckConnections = "connections"
+	// CheckBlocking checks for sessions waiting on locks
+	CheckBlocking = "blocking"
+	// CheckLongQueries checks for queries running too long
+	CheckLongQueries = "long_queries"
+	// CheckDeadlocks checks the deadlocks since the previous check
+	CheckDeadlocks = "deadlocks"
+	// CheckConnection checks that the database accepts connections and queries
+	CheckConnection = "connection"
+)
+
+// DefaultConnectionCheckName is the table name of a database's connection check
+const DefaultConnectionCheckName = "connection"
+
+// Operators are the comparison operators of expectations
+var Operators = map[string]bool{
+	"==": true,
+	"!=": true,
+	"<":  true,
+	"<=": true,
+	">":  true,
+	">=": true,
+}
+
+// Expectation asserts on a column of the rows a check query returns. A query
+// that succeeds but fails an expectation is reported unhealthy.
+type Expectation struct {
+	Column   string      `yaml:"column"`
+	Operator string      `yaml:"operator"` // ==, !=, <, <=, > or >= (default ==)
+	Value    interface{} `yaml:"value"`
+}
+
+// Expectations is a list of expectations. In YAML it accepts a single
+// expectation as well as a list.
+type Expectations []Expectation
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (e *Expectations) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var expectation Expectation
+		if err := node.Decode(&expectation); err != nil {
+			return err
+		}
+		*e = Expectations{expectation}
+		return nil
+	}
+
+	var expectations []Expectation
+	if err := node.Decode(&expectations); err != nil {
+		return err
+	}
+	*e = expectations
+	return nil
+}
+
+// GetOperator returns the comparison operator, defaulting to ==
+func (e *Expectation) GetOperator() string {
+	if e.Operator == "" {
+		return "=="
+	}
+	return e.Operator
+}
+
+// Validate validates the expectation
+func (e *Expectation) Validate() error {
+	if e.Column == "" {
+		return fmt.Errorf("expect column is required")
+	}
+
+	if !Operators[e.GetOperator()] {
+		return fmt.Errorf("invalid expect operator %q for column %s", e.Operator, e.Column)
+	}
+
+	switch e.Value.(type) {
+	case int, int64, uint64, float64, string, bool:
+	case nil:
+		return fmt.Errorf("expect value is required for column %s", e.Column)
+	default:
+		return fmt.Errorf("expect value for column %s must be a number, string or boolean", e.Column)
+	}
+
+	// Only numbers are ordered
+	switch e.Value.(type) {
+	case string, bool:
+		if e.GetOperator() != "==" && e.GetOperator() != "!=" {
+			return fmt.Errorf("expect operator %s for column %s requires a numeric value", e.Operator, e.Column)
+		}
+	}
+
+	return nil
+}
+
+// validateRowCount validates a row_count check
+func (t *Table) validateRowCount() error {
+	if t.Query != "" {
+		return fmt.Errorf("table query must be empty for check %s", t.Check)
+	}
+
+	if err := t.validateFrom(); err != nil {
+		return err
+	}
+
+	if t.MinCount != nil && *t.MinCount < 0 {
+		return fmt.Errorf("min_count must not be negative")
+	}
+
+	if t.MinCount != nil && t.MaxCount != nil && *t.MaxCount < *t.MinCount {
+		return fmt.Errorf("max_count must be at least min_count")
+	}
+
+	return nil
+}
+
+// validateFreshness validates a freshness check
+func (t *Table) validateFreshness() error {
+	if t.Query != "" {
+		return fmt.Errorf("table query must be empty for check %s", t.Check)
+	}
+
+	if err := t.validateFrom(); err != nil {
+		return err
+	}
+
+	if !isIdentifier(t.Column) {
+		return fmt.Errorf("invalid freshness column: %q", t.Column)
+	}
+
+	if t.MaxAge <= 0 {
+		return fmt.Errorf("max_age must be positive")
+	}
+
+	return nil
+}
+
+// validateStorage validates a storage check
+func (t *Table) validateStorage() error {
+	if t.Query != "" {
+		return fmt.Errorf("table query must be empty for check %s", t.Check)
+	}
+
+	if t.MaxUsage <= 0 || t.MaxUsage > 100 {
+		return fmt.Errorf("max_usage must be a percentage between 0 and 100")
+	}
+
+	if t.CapacityMB < 0 {
+		return fmt.Errorf("capacity_mb must not be negative")
+	}
+
+	return nil
+}
+
+// validateConnections validates a connections check
+func (t *Table) validateConnections() error {
+	if t.Query != "" {
+		return fmt.Errorf("table query must be empty for check %s", t.Check)
+	}
+
+	if t.WarnUsage == 0 && t.CriticalUsage == 0 {
+		return fmt.Errorf("warn_usage or critical_usage is required for check %s", t.Check)
+	}
+
+	if t.WarnUsage < 0 || t.WarnUsage > 100 {
+		return fmt.Errorf("warn_usage must be a percentage between 0 and 100")
+	}
+
+	if t.CriticalUsage < 0 || t.CriticalUsage > 100 {
+		return fmt.Errorf("critical_usage must be a percentage between 0 and 100")
+	}
+
+	if t.WarnUsage > 0 && t.CriticalUsage > 0 && t.WarnUsage >= t.CriticalUsage {
+		return fmt.Errorf("warn_usage must be less than critical_usage")
+	}
+
+	return nil
+}
+
+// validateFrom validates the table of a built-in check, which is quoted into
+// the generated query
+func (t *Table) validateFrom() error {
+	for _, part := range strings.Split(t.GetCheckTable(), ".") {
+		if !isIdentifier(part) {
+			return fmt.Errorf("invalid table for check %s: %q", t.Check, t.GetCheckTable())
+		}
+	}
+	return nil
+}
+
+// isIdentifier reports whether a name is a plain SQL identifier: letters,
+// digits, "_" and "$", not starting with a digit
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case (c >= '0' && c <= '9') || c == '$':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// GetCheckTable returns the table queried by a built-in check, defaulting to
+// the check name
+func (t *Table) GetCheckTable() string {
+	if t.From == "" {
+		return t.Name
+	}
+	return t.From
+}
+
+// GetMaxAge returns the maximum age of the newest row of a freshness check
+func (t *Table) GetMaxAge() time.Duration {
+	return time.Duration(t.MaxAge)
+}
+
+// GetMaxLag returns the largest replication lag a replication_lag check accepts
+func (t *Table) GetMaxLag() time.Duration {
+	return time.Duration(t.MaxLag)
+}
+
+// GetMaxWait returns the longest lock wait a blocking check accepts
+func (t *Table) GetMaxWait() time.Duration {
+	return time.Duration(t.MaxWait)
+}
+
+// GetMaxDuration returns the longest running query a long_queries check accepts
+func (t *Table) GetMaxDuration() time.Duration {
+	return time.Duration(t.MaxDuration)
+}
+
+// GetCapacity returns the capacity in bytes a storage check measures usage
+// against, or 0 to use the size of the database's volumes
+func (t *Table) GetCapacity() int64 {
+	return t.CapacityMB << 20
+}
+
+// GetExpectations returns the expectations of a table, including the bounds
+// of a row_count check on its count column
+func (t *Table) GetExpectations() Expectations {
+	if t.Check != CheckRowCount || (t.MinCount == nil && t.MaxCount == nil) {
+		return t.Expect
+	}
+
+	expectations := append(Expectations{}, t.Expect...)
+	if t.MinCount != nil {
+		expectations = append(expectations, Expectation{Column: "count", Operator: ">=", Value: *t.MinCount})
+	}
+	if t.MaxCount != nil {
+		expectations = append(expectations, Expectation{Column: "count", Operator: "<=", Value: *t.MaxCount})
+	}
+	return expectations
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActiveHours is a daily window during which checks run, written as
+// "08:00-20:00" with an optional IANA time zone, such as
+// "08:00-20:00 Europe/Berlin" (default UTC). A window that ends before it
+// starts spans midnight.
+type ActiveHours struct {
+	Start    time.Duration // since midnight
+	End      time.Duration // since midnight
+	Location *time.Location
+}
+
+// ParseActiveHours parses an active_hours value
+func ParseActiveHours(value string) (*ActiveHours, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid active_hours %q: expected HH:MM-HH:MM and an optional time zone", value)
+	}
+
+	start, end, found := strings.Cut(fields[0], "-")
+	if !found {
+		return nil, fmt.Errorf("invalid active_hours %q: expected HH:MM-HH:MM and an optional time zone", value)
+	}
+
+	hours := &ActiveHours{Location: time.UTC}
+	var err error
+	if hours.Start, err = parseClock(start); err != nil {
+		return nil, fmt.Errorf("invalid active_hours %q: %w", value, err)
+	}
+	if hours.End, err = parseClock(end); err != nil {
+		return nil, fmt.Errorf("invalid active_hours %q: %w", value, err)
+	}
+	if hours.Start == hours.End {
+		return nil, fmt.Errorf("invalid active_hours %q: window is empty", value)
+	}
+
+	if len(fields) == 2 {
+		if hours.Location, err = time.LoadLocation(fields[1]); err != nil {
+			return nil, fmt.Errorf("invalid active_hours %q: %w", value, err)
+		}
+	}
+
+	return hours, nil
+}
+
+// parseClock parses a time of day such as "08:00" or "24:00" into the time
+// since midnight
+func parseClock(value string) (time.Duration, error) {
+	var hour, minute int
+	if n, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || n != 2 || len(value) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// Contains reports whether t falls within the window
+func (h *ActiveHours) Contains(t time.Time) bool {
+	t = t.In(h.Location)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if h.Start < h.End {
+		return offset >= h.Start && offset < h.End
+	}
+	return offset >= h.Start || offset < h.End
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseActiveHours(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		value    string
+		at       time.Time
+		expected bool
+	}{
+		{"08:00-20:00 Europe/Berlin", time.Date(2024, 3, 4, 8, 0, 0, 0, berlin), true},
+		{"08:00-20:00 Europe/Berlin", time.Date(2024, 3, 4, 19, 59, 59, 0, berlin), true},
+		{"08:00-20:00 Europe/Berlin", time.Date(2024, 3, 4, 20, 0, 0, 0, berlin), false},
+		{"08:00-20:00 Europe/Berlin", time.Date(2024, 3, 4, 7, 30, 0, 0, time.UTC), true}, // 08:30 in Berlin
+		{"08:00-20:00", time.Date(2024, 3, 4, 7, 30, 0, 0, time.UTC), false},
+		{"22:00-06:00", time.Date(2024, 3, 4, 23, 0, 0, 0, time.UTC), true},
+		{"22:00-06:00", time.Date(2024, 3, 4, 5, 59, 0, 0, time.UTC), true},
+		{"22:00-06:00", time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC), false},
+		{"00:00-24:00", time.Date(2024, 3, 4, 23, 59, 59, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		hours, err := ParseActiveHours(tt.value)
+		if err != nil {
+			t.Fatalf("ParseActiveHours(%q) error = %v", tt.value, err)
+		}
+		if got := hours.Contains(tt.at); got != tt.expected {
+			t.Errorf("ParseActiveHours(%q).Contains(%v) = %v; expected %v", tt.value, tt.at, got, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"", "08:00", "8:00-20:00", "08:00-08:00", "08:60-20:00", "08:00-25:00", "08:00-20:00 Mars/Olympus", "08:00-20:00 UTC extra"} {
+		if _, err := ParseActiveHours(value); err == nil {
+			t.Errorf("ParseActiveHours(%q) expected an error", value)
+		}
+	}
+}
+
+func TestTableActiveHours(t *testing.T) {
+	db := Database{ActiveHours: "08:00-20:00"}
+	if got := db.TableActiveHours(&Table{}); got != "08:00-20:00" {
+		t.Errorf("TableActiveHours() = %q; expected the database's", got)
+	}
+	if got := db.TableActiveHours(&Table{ActiveHours: "06:00-22:00"}); got != "06:00-22:00" {
+		t.Errorf("TableActiveHours() = %q; expected the table's", got)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// MQTT represents the optional MQTT publisher, sending check results, status
+// changes and connection states to a broker. Topics are templates where
+// {database} and {table} are replaced by the names of the check.
+type MQTT struct {
+	Enabled         bool     `yaml:"enabled"`
+	Broker          string   `yaml:"broker"`    // tcp://host:1883, or ssl://host:8883 for TLS
+	ClientID        string   `yaml:"client_id"` // default gsqlhealth-<hostname>
+	Username        string   `yaml:"username"`
+	Password        string   `yaml:"password"`
+	CAFile          string   `yaml:"ca_file"`          // PEM CA bundle verifying a TLS broker (default the system's)
+	QoS             int      `yaml:"qos"`              // 0, 1 or 2 (default 0)
+	Retain          bool     `yaml:"retain"`           // retain status and connection messages, so subscribers get the latest at once
+	KeepAlive       Duration `yaml:"keep_alive"`       // default 30s
+	StatusTopic     string   `yaml:"status_topic"`     // result of every check run (default gsqlhealth/{database}/{table}/status)
+	EventTopic      string   `yaml:"event_topic"`      // status changes (default gsqlhealth/{database}/{table}/events)
+	ConnectionTopic string   `yaml:"connection_topic"` // database connections (default gsqlhealth/{database}/connection)
+}
+
+// MQTT defaults
+const (
+	defaultMQTTKeepAlive       = 30 * time.Second
+	defaultMQTTStatusTopic     = "gsqlhealth/{database}/{table}/status"
+	defaultMQTTEventTopic      = "gsqlhealth/{database}/{table}/events"
+	defaultMQTTConnectionTopic = "gsqlhealth/{database}/connection"
+)
+
+// Validate validates MQTT configuration
+func (m *MQTT) Validate() error {
+	if !m.Enabled {
+		return nil
+	}
+
+	parsed, err := url.Parse(m.Broker)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid broker: %q (expected tcp://host:port or ssl://host:port)", m.Broker)
+	}
+	switch parsed.Scheme {
+	case "tcp", "mqtt", "ssl", "tls", "mqtts":
+	default:
+		return fmt.Errorf("invalid broker scheme: %q (expected tcp, mqtt, ssl, tls or mqtts)", parsed.Scheme)
+	}
+
+	if m.CAFile != "" && !m.UsesTLS() {
+		return fmt.Errorf("ca_file requires a TLS broker (ssl://, tls:// or mqtts://)")
+	}
+	if m.Password != "" && m.Username == "" {
+		return fmt.Errorf("password requires username")
+	}
+
+	if m.QoS < 0 || m.QoS > 2 {
+		return fmt.Errorf("qos must be 0, 1 or 2")
+	}
+	if m.KeepAlive < 0 || time.Duration(m.KeepAlive) > 65535*time.Second {
+		return fmt.Errorf("keep_alive must be between 0 and 65535s")
+	}
+
+	for name, topic := range map[string]string{
+		"status_topic":     m.StatusTopic,
+		"event_topic":      m.EventTopic,
+		"connection_topic": m.ConnectionTopic,
+	} {
+		if strings.ContainsAny(topic, "+#") {
+			return fmt.Errorf("%s must not contain wildcards: %s", name, topic)
+		}
+	}
+	if strings.Contains(m.ConnectionTopic, "{table}") {
+		return fmt.Errorf("connection_topic must not contain {table}, connections are per database")
+	}
+	return nil
+}
+
+// UsesTLS reports whether the broker is connected to over TLS
+func (m *MQTT) UsesTLS() bool {
+	parsed, err := url.Parse(m.Broker)
+	if err != nil {
+		return false
+	}
+	switch parsed.Scheme {
+	case "ssl", "tls", "mqtts":
+		return true
+	default:
+		return false
+	}
+}
+
+// GetClientID returns the MQTT client ID, defaulting to gsqlhealth-<hostname>
+func (m *MQTT) GetClientID() string {
+	if m.ClientID != "" {
+		return m.ClientID
+	}
+	hostname, _ := os.Hostname()
+	return "gsqlhealth-" + hostname
+}
+
+// GetKeepAlive returns the keep alive interval, defaulting to 30 seconds
+func (m *MQTT) GetKeepAlive() time.Duration {
+	if m.KeepAlive == 0 {
+		return defaultMQTTKeepAlive
+	}
+	return time.Duration(m.KeepAlive)
+}
+
+// GetStatusTopic returns the topic template of check results
+func (m *MQTT) GetStatusTopic() string {
+	if m.StatusTopic == "" {
+		return defaultMQTTStatusTopic
+	}
+	return m.StatusTopic
+}
+
+// GetEventTopic returns the topic template of status changes
+func (m *MQTT) GetEventTopic() string {
+	if m.EventTopic == "" {
+		return defaultMQTTEventTopic
+	}
+	return m.EventTopic
+}
+
+// GetConnectionTopic returns the topic template of database connections
+func (m *MQTT) GetConnectionTopic() string {
+	if m.ConnectionTopic == "" {
+		return defaultMQTTConnectionTopic
+	}
+	return m.ConnectionTopic
+}
@@ -0,0 +1,321 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Notifications represents the notifiers that are sent status changes of
+// checks, such as a check failing or recovering
+type Notifications struct {
+	// DashboardURL is linked from notifications, with {database} and {table}
+	// replaced by the check's names
+	DashboardURL string `yaml:"dashboard_url"`
+	// RepeatInterval is the interval notifications of an ongoing failure are
+	// sent again at (default 0, never)
+	RepeatInterval Duration `yaml:"repeat_interval"`
+
+	Slack        []Slack        `yaml:"slack"`
+	PagerDuty    []PagerDuty    `yaml:"pagerduty"`
+	Alertmanager []Alertmanager `yaml:"alertmanager"`
+	SNS          []SNS          `yaml:"sns"`
+	SQS          []SQS          `yaml:"sqs"`
+}
+
+// NotificationFilter selects the checks a notifier is sent status changes of.
+// An empty filter selects every check.
+type NotificationFilter struct {
+	Severities []string          `yaml:"severities,omitempty"` // severities of the checks (default all)
+	Labels     map[string]string `yaml:"labels,omitempty"`     // labels the checks must all have
+}
+
+// Slack represents a Slack notifier, posting through an incoming webhook or
+// with a bot token to a channel
+type Slack struct {
+	WebhookURL         string `yaml:"webhook_url"`
+	Token              string `yaml:"token"`   // bot token, used instead of webhook_url
+	Channel            string `yaml:"channel"` // required with token; overrides the webhook's channel otherwise
+	NotificationFilter `yaml:",inline"`
+}
+
+// PagerDuty represents a PagerDuty notifier, triggering and resolving
+// incidents through the Events API v2. Without severities, it is sent the
+// status changes of critical checks only.
+type PagerDuty struct {
+	RoutingKey         string `yaml:"routing_key"` // integration key of an Events API v2 integration
+	URL                string `yaml:"url"`         // Events API endpoint (default https://events.pagerduty.com/v2/enqueue)
+	NotificationFilter `yaml:",inline"`
+}
+
+// defaultPagerDutyURL is the endpoint of the PagerDuty Events API v2
+const defaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Alertmanager represents a notifier posting alerts to the v2 API of a
+// Prometheus Alertmanager. Firing alerts are posted again every
+// resend_interval, as Alertmanager resolves alerts that are not.
+type Alertmanager struct {
+	URL                string   `yaml:"url"`             // base URL, e.g. http://alertmanager:9093
+	ResendInterval     Duration `yaml:"resend_interval"` // interval firing alerts are posted again at (default 1m)
+	BearerToken        string   `yaml:"bearer_token"`
+	Username           string   `yaml:"username"` // basic authentication, instead of bearer_token
+	Password           string   `yaml:"password"`
+	NotificationFilter `yaml:",inline"`
+}
+
+// defaultAlertmanagerResendInterval is the default resend_interval
+const defaultAlertmanagerResendInterval = time.Minute
+
+// SNS represents a notifier publishing status changes as JSON messages to an
+// Amazon SNS topic, signed with the credentials of the environment or of an
+// assumed role
+type SNS struct {
+	TopicARN           string `yaml:"topic_arn"`
+	Region             string `yaml:"region"`   // region of the endpoint (default the topic's)
+	RoleARN            string `yaml:"role_arn"` // role assumed to publish (default none, the environment's credentials)
+	Endpoint           string `yaml:"endpoint"` // SNS endpoint (default the region's)
+	NotificationFilter `yaml:",inline"`
+}
+
+// SQS represents a notifier sending status changes as JSON messages to an
+// Amazon SQS queue, signed like SNS
+type SQS struct {
+	QueueURL           string `yaml:"queue_url"`
+	Region             string `yaml:"region"`   // region of the queue (default from queue_url)
+	RoleARN            string `yaml:"role_arn"` // role assumed to send (default none, the environment's credentials)
+	NotificationFilter `yaml:",inline"`
+}
+
+// snsTopicARNPattern matches SNS topic ARNs, capturing their region
+var snsTopicARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:sns:([a-z0-9-]+):[0-9]{12}:[A-Za-z0-9_-]+(\.fifo)?$`)
+
+// sqsHostPattern matches the hosts of SQS queue URLs, capturing their region
+var sqsHostPattern = regexp.MustCompile(`^(?:sqs\.([a-z0-9-]+)|([a-z0-9-]+)\.queue)\.amazonaws\.com(?:\.cn)?$`)
+
+// Validate validates notification configuration
+func (n *Notifications) Validate() error {
+	if n.DashboardURL != "" {
+		if _, err := url.Parse(n.DashboardURL); err != nil {
+			return fmt.Errorf("invalid dashboard_url: %w", err)
+		}
+	}
+
+	if n.RepeatInterval < 0 {
+		return fmt.Errorf("repeat_interval must not be negative")
+	}
+
+	for i, slack := range n.Slack {
+		if err := slack.Validate(); err != nil {
+			return fmt.Errorf("slack %d: %w", i, err)
+		}
+	}
+
+	for i, pagerDuty := range n.PagerDuty {
+		if err := pagerDuty.Validate(); err != nil {
+			return fmt.Errorf("pagerduty %d: %w", i, err)
+		}
+	}
+
+	for i, alertmanager := range n.Alertmanager {
+		if err := alertmanager.Validate(); err != nil {
+			return fmt.Errorf("alertmanager %d: %w", i, err)
+		}
+	}
+
+	for i, sns := range n.SNS {
+		if err := sns.Validate(); err != nil {
+			return fmt.Errorf("sns %d: %w", i, err)
+		}
+	}
+
+	for i, sqs := range n.SQS {
+		if err := sqs.Validate(); err != nil {
+			return fmt.Errorf("sqs %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates a Slack notifier
+func (s *Slack) Validate() error {
+	switch {
+	case s.WebhookURL == "" && s.Token == "":
+		return fmt.Errorf("webhook_url or token is required")
+	case s.WebhookURL != "" && s.Token != "":
+		return fmt.Errorf("webhook_url and token are mutually exclusive")
+	case s.Token != "" && s.Channel == "":
+		return fmt.Errorf("channel is required with token")
+	}
+
+	if s.WebhookURL != "" {
+		if parsed, err := url.Parse(s.WebhookURL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+			return fmt.Errorf("invalid webhook_url: %s", s.WebhookURL)
+		}
+	}
+
+	return s.NotificationFilter.Validate()
+}
+
+// Validate validates a PagerDuty notifier
+func (p *PagerDuty) Validate() error {
+	if p.RoutingKey == "" {
+		return fmt.Errorf("routing_key is required")
+	}
+
+	if p.URL != "" {
+		if parsed, err := url.Parse(p.URL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+			return fmt.Errorf("invalid url: %s", p.URL)
+		}
+	}
+
+	return p.NotificationFilter.Validate()
+}
+
+// GetURL returns the Events API endpoint, defaulting to PagerDuty's
+func (p *PagerDuty) GetURL() string {
+	if p.URL == "" {
+		return defaultPagerDutyURL
+	}
+	return p.URL
+}
+
+// GetFilter returns the filter of the checks the notifier is sent, selecting
+// critical checks unless severities are configured
+func (p *PagerDuty) GetFilter() NotificationFilter {
+	filter := p.NotificationFilter
+	if len(filter.Severities) == 0 {
+		filter.Severities = []string{SeverityCritical}
+	}
+	return filter
+}
+
+// Validate validates an Alertmanager notifier
+func (a *Alertmanager) Validate() error {
+	if parsed, err := url.Parse(a.URL); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("invalid url: %q", a.URL)
+	}
+
+	if a.ResendInterval < 0 {
+		return fmt.Errorf("resend_interval must not be negative")
+	}
+
+	if a.BearerToken != "" && a.Username != "" {
+		return fmt.Errorf("bearer_token and username are mutually exclusive")
+	}
+
+	return a.NotificationFilter.Validate()
+}
+
+// GetAlertsURL returns the endpoint alerts are posted to
+func (a *Alertmanager) GetAlertsURL() string {
+	return strings.TrimSuffix(a.URL, "/") + "/api/v2/alerts"
+}
+
+// GetResendInterval returns the interval firing alerts are posted again at,
+// defaulting to a minute
+func (a *Alertmanager) GetResendInterval() time.Duration {
+	if a.ResendInterval == 0 {
+		return defaultAlertmanagerResendInterval
+	}
+	return time.Duration(a.ResendInterval)
+}
+
+// Validate validates an SNS notifier
+func (s *SNS) Validate() error {
+	if !snsTopicARNPattern.MatchString(s.TopicARN) {
+		return fmt.Errorf("invalid topic_arn: %q", s.TopicARN)
+	}
+
+	if s.Endpoint != "" {
+		if parsed, err := url.Parse(s.Endpoint); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return fmt.Errorf("invalid endpoint: %q", s.Endpoint)
+		}
+	}
+
+	return s.NotificationFilter.Validate()
+}
+
+// GetRegion returns the region of the SNS endpoint, defaulting to the topic's
+func (s *SNS) GetRegion() string {
+	if s.Region != "" {
+		return s.Region
+	}
+	if match := snsTopicARNPattern.FindStringSubmatch(s.TopicARN); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// GetEndpoint returns the SNS endpoint, defaulting to the region's
+func (s *SNS) GetEndpoint() string {
+	if s.Endpoint != "" {
+		return s.Endpoint
+	}
+	return fmt.Sprintf("https://sns.%s.amazonaws.com/", s.GetRegion())
+}
+
+// Validate validates an SQS notifier
+func (s *SQS) Validate() error {
+	parsed, err := url.Parse(s.QueueURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" || strings.Count(strings.Trim(parsed.Path, "/"), "/") != 1 {
+		return fmt.Errorf("invalid queue_url: %q (expected https://sqs.<region>.amazonaws.com/<account>/<queue>)", s.QueueURL)
+	}
+
+	if s.GetRegion() == "" {
+		return fmt.Errorf("region is required for queue URLs of other hosts than sqs.<region>.amazonaws.com")
+	}
+
+	return s.NotificationFilter.Validate()
+}
+
+// GetRegion returns the region of the queue, defaulting to the one of its URL
+func (s *SQS) GetRegion() string {
+	if s.Region != "" {
+		return s.Region
+	}
+	parsed, err := url.Parse(s.QueueURL)
+	if err != nil {
+		return ""
+	}
+	if match := sqsHostPattern.FindStringSubmatch(parsed.Hostname()); match != nil {
+		return match[1] + match[2]
+	}
+	return ""
+}
+
+// Validate validates a notification filter
+func (f *NotificationFilter) Validate() error {
+	for _, severity := range f.Severities {
+		switch severity {
+		case SeverityCritical, SeverityWarning, SeverityInfo:
+		default:
+			return fmt.Errorf("invalid severity: %s (must be critical, warning or info)", severity)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the filter selects a check of a severity and labels
+func (f *NotificationFilter) Matches(severity string, labels map[string]string) bool {
+	if len(f.Severities) > 0 {
+		selected := false
+		for _, s := range f.Severities {
+			if s == severity {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			return false
+		}
+	}
+
+	for name, value := range f.Labels {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Passive check protocols
+const (
+	PassiveProtocolNSCA    = "nsca"    // Nagios Service Check Acceptor
+	PassiveProtocolIcinga2 = "icinga2" // Icinga 2 REST API
+)
+
+// NSCA encryption methods
+const (
+	NSCAEncryptionNone = "none"
+	NSCAEncryptionXOR  = "xor"
+)
+
+// PassiveChecks represents the optional submission of check results to
+// Nagios or Icinga as passive service checks, after every scheduled run. Host
+// and service names are templates where {database} and {table} are replaced
+// by the names of the check.
+type PassiveChecks struct {
+	Enabled       bool     `yaml:"enabled"`
+	Protocol      string   `yaml:"protocol"`        // nsca or icinga2
+	Address       string   `yaml:"address"`         // nsca: host:port (default port 5667); icinga2: https://host:5665
+	Encryption    string   `yaml:"encryption"`      // nsca: none or xor (default none)
+	Username      string   `yaml:"username"`        // icinga2: API user
+	Password      string   `yaml:"password"`        // nsca: encryption password; icinga2: API user password
+	CAFile        string   `yaml:"ca_file"`         // icinga2: PEM CA bundle verifying the API (default the system's)
+	TLSSkipVerify bool     `yaml:"tls_skip_verify"` // icinga2: skip certificate verification
+	HostName      string   `yaml:"host_name"`       // default {database}
+	ServiceName   string   `yaml:"service_name"`    // default {table}
+	Timeout       Duration `yaml:"timeout"`         // default 10s
+}
+
+// Passive check defaults
+const (
+	defaultNSCAPort           = "5667"
+	defaultPassiveHostName    = "{database}"
+	defaultPassiveServiceName = "{table}"
+	defaultPassiveTimeout     = 10 * time.Second
+)
+
+// Validate validates passive check configuration
+func (p *PassiveChecks) Validate() error {
+	if !p.Enabled {
+		return nil
+	}
+
+	switch p.Protocol {
+	case PassiveProtocolNSCA:
+		if p.Address == "" {
+			return fmt.Errorf("nsca requires address")
+		}
+		if strings.Contains(p.Address, "://") {
+			return fmt.Errorf("invalid address: %q (expected host:port)", p.Address)
+		}
+		switch p.Encryption {
+		case "", NSCAEncryptionNone:
+			if p.Password != "" {
+				return fmt.Errorf("password requires encryption xor")
+			}
+		case NSCAEncryptionXOR:
+		default:
+			return fmt.Errorf("invalid encryption: %q (expected none or xor)", p.Encryption)
+		}
+		if p.Username != "" || p.CAFile != "" || p.TLSSkipVerify {
+			return fmt.Errorf("username, ca_file and tls_skip_verify require protocol icinga2")
+		}
+	case PassiveProtocolIcinga2:
+		parsed, err := url.Parse(p.Address)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid address: %q (expected https://host:5665)", p.Address)
+		}
+		if p.Username == "" {
+			return fmt.Errorf("icinga2 requires username")
+		}
+		if p.Encryption != "" {
+			return fmt.Errorf("encryption requires protocol nsca")
+		}
+	default:
+		return fmt.Errorf("invalid protocol: %q (expected nsca or icinga2)", p.Protocol)
+	}
+
+	if !strings.Contains(p.GetServiceName(), "{table}") {
+		return fmt.Errorf("service_name must contain {table}")
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
+
+// GetAddress returns the address of the NSCA daemon with its port, or the
+// URL of the Icinga 2 API
+func (p *PassiveChecks) GetAddress() string {
+	if p.Protocol != PassiveProtocolNSCA {
+		return strings.TrimSuffix(p.Address, "/")
+	}
+	if _, _, err := net.SplitHostPort(p.Address); err != nil {
+		return net.JoinHostPort(p.Address, defaultNSCAPort)
+	}
+	return p.Address
+}
+
+// GetHostName returns the host name template of check results
+func (p *PassiveChecks) GetHostName() string {
+	if p.HostName == "" {
+		return defaultPassiveHostName
+	}
+	return p.HostName
+}
+
+// GetServiceName returns the service name template of check results
+func (p *PassiveChecks) GetServiceName() string {
+	if p.ServiceName == "" {
+		return defaultPassiveServiceName
+	}
+	return p.ServiceName
+}
+
+// GetTimeout returns the timeout of submissions, defaulting to 10 seconds
+func (p *PassiveChecks) GetTimeout() time.Duration {
+	if p.Timeout == 0 {
+		return defaultPassiveTimeout
+	}
+	return time.Duration(p.Timeout)
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Percent is a configuration percentage. In YAML it accepts a number of
+// percent with or without a "%" sign, such as 10 or "10%".
+type Percent float64
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (p *Percent) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: percentage must be a number such as 10%%", node.Line)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(node.Value), "%"), 64)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid percentage %q", node.Line, node.Value)
+	}
+
+	*p = Percent(value)
+	return nil
+}
+
+// Fraction returns the percentage as a fraction of one
+func (p Percent) Fraction() float64 {
+	return float64(p) / 100
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPercentUnmarshal(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    float64
+		expectError bool
+	}{
+		{input: "10%", expected: 0.1},
+		{input: `"2.5%"`, expected: 0.025},
+		{input: "20", expected: 0.2},
+		{input: "often", expectError: true},
+		{input: "[10]", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var value struct {
+				Jitter Percent `yaml:"jitter"`
+			}
+			err := yaml.Unmarshal([]byte("jitter: "+tt.input), &value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %s", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if fraction := value.Jitter.Fraction(); fraction != tt.expected {
+				t.Errorf("Fraction() = %g; expected %g", fraction, tt.expected)
+			}
+		})
+	}
+}
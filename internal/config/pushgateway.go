@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Pushgateway represents the Prometheus Pushgateway the one-shot check
+// command pushes its metrics to, for batch jobs that no scraper can reach
+type Pushgateway struct {
+	URL      string            `yaml:"url"`      // e.g. http://pushgateway:9091
+	Job      string            `yaml:"job"`      // job label of the metrics (default gsqlhealth)
+	Instance string            `yaml:"instance"` // instance label of the metrics (default the hostname)
+	Labels   map[string]string `yaml:"labels"`   // further grouping labels
+	Username string            `yaml:"username"` // basic auth (default none)
+	Password string            `yaml:"password"`
+	Timeout  Duration          `yaml:"timeout"` // default 10s
+}
+
+// defaultPushgatewayTimeout bounds pushes unless configured
+const defaultPushgatewayTimeout = 10 * time.Second
+
+// Validate validates Pushgateway configuration
+func (p *Pushgateway) Validate() error {
+	if p.URL == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(p.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid url: %q (expected http:// or https://)", p.URL)
+	}
+	if p.Password != "" && p.Username == "" {
+		return fmt.Errorf("password requires username")
+	}
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+
+	for name := range p.Labels {
+		if !isLabelName(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid label name: %q", name)
+		}
+		if name == "job" || name == "instance" {
+			return fmt.Errorf("label %q is set by %s, not labels", name, name)
+		}
+	}
+	return nil
+}
+
+// IsEnabled reports whether metrics are pushed
+func (p *Pushgateway) IsEnabled() bool {
+	return p.URL != ""
+}
+
+// GetJob returns the job label, defaulting to gsqlhealth
+func (p *Pushgateway) GetJob() string {
+	if p.Job == "" {
+		return "gsqlhealth"
+	}
+	return p.Job
+}
+
+// GetInstance returns the instance label, defaulting to the hostname
+func (p *Pushgateway) GetInstance() string {
+	if p.Instance != "" {
+		return p.Instance
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// GetTimeout returns the timeout of pushes, defaulting to 10 seconds
+func (p *Pushgateway) GetTimeout() time.Duration {
+	if p.Timeout == 0 {
+		return defaultPushgatewayTimeout
+	}
+	return time.Duration(p.Timeout)
+}
+
+// isLabelName reports whether a name is a valid Prometheus label name
+func isLabelName(name string) bool {
+	return name != "" && !strings.Contains(name, ":") && isMetricName(name)
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"strings"
+)
+
+// Statements starting with these keywords only read. Statements that start
+// with any other keyword, such as INSERT, DELETE, CREATE or EXEC, are writes.
+var readKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXPLAIN":  true,
+	"SET":      true,
+}
+
+// writeKeywords make a statement that starts like a read write after all, as
+// in "WITH ... DELETE", "SELECT ... INTO" or "EXPLAIN ANALYZE DELETE"
+var writeKeywords = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"MERGE":    true,
+	"UPSERT":   true,
+	"TRUNCATE": true,
+	"CREATE":   true,
+	"ALTER":    true,
+	"DROP":     true,
+	"GRANT":    true,
+	"REVOKE":   true,
+	"INTO":     true,
+}
+
+// writeStatement returns the keyword that makes a query write, or "" if every
+// statement of the query only reads. The check is lexical: strings, quoted
+// identifiers and comments are skipped, and functions that write when
+// selected cannot be told apart from other functions.
+func writeStatement(dbType, query string) string {
+	for _, statement := range sqlStatements(dbType, query) {
+		if len(statement) == 0 {
+			continue
+		}
+		if !readKeywords[statement[0]] {
+			return statement[0]
+		}
+		// SHOW CREATE TABLE and DESCRIBE only read, whatever follows
+		if statement[0] == "SHOW" || statement[0] == "DESCRIBE" || statement[0] == "DESC" {
+			continue
+		}
+		for i, word := range statement[1:] {
+			// SELECT ... FOR UPDATE and FOR NO KEY UPDATE lock rows without writing
+			if word == "UPDATE" && (statement[i] == "FOR" || statement[i] == "KEY") {
+				continue
+			}
+			if writeKeywords[word] {
+				return word
+			}
+		}
+	}
+	return ""
+}
+
+// sqlStatements splits a query into statements of upper-cased words, leaving
+// out strings, quoted identifiers, comments and punctuation in the dialect of
+// the database type
+func sqlStatements(dbType, query string) [][]string {
+	mysql, mssql, postgres := dbType == "mysql", dbType == "mssql", dbType == "postgres"
+
+	statements := [][]string{nil}
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ';':
+			statements = append(statements, nil)
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"), c == '#' && mysql:
+			i = skipPast(query, i+1, "\n")
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			i = skipPast(query, i+2, "*/")
+		case c == '\'', c == '"':
+			i = skipQuoted(query, i+1, c, mysql)
+		case c == '`' && mysql:
+			i = skipPast(query, i+1, "`")
+		case c == '[' && mssql:
+			i = skipPast(query, i+1, "]")
+		case c == '$' && postgres && dollarTag(query[i:]) != "":
+			tag := dollarTag(query[i:])
+			i = skipPast(query, i+len(tag), tag)
+		case isWordChar(c):
+			start := i
+			for i < len(query) && isWordChar(query[i]) {
+				i++
+			}
+			last := len(statements) - 1
+			statements[last] = append(statements[last], strings.ToUpper(query[start:i]))
+		default:
+			i++
+		}
+	}
+	return statements
+}
+
+// skipPast returns the index after the first end at or after i, or the end
+// of the query
+func skipPast(query string, i int, end string) int {
+	if j := strings.Index(query[i:], end); j >= 0 {
+		return i + j + len(end)
+	}
+	return len(query)
+}
+
+// skipQuoted returns the index after the closing quote of a string or quoted
+// identifier starting at i. A doubled quote reads as two adjacent strings,
+// which skip the same text; MySQL also escapes quotes with a backslash.
+func skipQuoted(query string, i int, quote byte, backslash bool) int {
+	for ; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// dollarTag returns the opening tag of a PostgreSQL dollar-quoted string at
+// the start of s, such as "$$" or "$body$", or ""
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return s[:i+1]
+		case !isWordChar(s[i]) || (s[i] >= '0' && s[i] <= '9'):
+			return ""
+		}
+	}
+	return ""
+}
+
+// isWordChar reports whether c may be part of an SQL keyword or identifier
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
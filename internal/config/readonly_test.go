@@ -0,0 +1,65 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbType   string
+		query    string
+		expected string
+	}{
+		{"select", "postgres", "SELECT COUNT(*) FROM users", ""},
+		{"cte", "postgres", "WITH recent AS (SELECT id FROM orders) SELECT COUNT(*) FROM recent", ""},
+		{"show create", "mysql", "SHOW CREATE TABLE users", ""},
+		{"set role", "postgres", "SET ROLE app_reader", ""},
+		{"for update", "postgres", "SELECT id FROM jobs FOR NO KEY UPDATE SKIP LOCKED", ""},
+		{"keyword in string", "postgres", "SELECT 1 FROM audit WHERE action = 'DELETE'", ""},
+		{"keyword in comment", "mssql", "SELECT 1 -- was: DELETE FROM users\n/* DROP TABLE users */", ""},
+		{"quoted identifier", "mysql", "SELECT `update` FROM settings", ""},
+		{"dollar quoted", "postgres", "SELECT $body$ DROP $body$", ""},
+		{"delete", "postgres", "DELETE FROM sessions WHERE expires < now()", "DELETE"},
+		{"lower case", "mysql", "insert into probes values (1)", "INSERT"},
+		{"second statement", "mssql", "SELECT 1; TRUNCATE TABLE users", "TRUNCATE"},
+		{"writing cte", "postgres", "WITH gone AS (DELETE FROM sessions RETURNING id) SELECT COUNT(*) FROM gone", "DELETE"},
+		{"select into", "mssql", "SELECT * INTO users_copy FROM users", "INTO"},
+		{"explain analyze", "postgres", "EXPLAIN ANALYZE UPDATE users SET active = false", "UPDATE"},
+		{"procedure", "mssql", "EXEC dbo.cleanup", "EXEC"},
+		{"escaped quote", "mysql", `SELECT 'it\'s'; DROP TABLE users`, "DROP"},
+		{"hash is not a comment", "postgres", "SELECT 1 # 2; DROP TABLE users", "DROP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if keyword := writeStatement(tt.dbType, tt.query); keyword != tt.expected {
+				t.Errorf("writeStatement(%q) = %q; expected %q", tt.query, keyword, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAllowWrites(t *testing.T) {
+	db := Database{
+		Name: "app", Type: "postgres", Host: "localhost", Port: 5432, Username: "user", Database: "app",
+		Tables: []Table{{
+			Name:          "probe",
+			Steps:         []Step{{Query: "INSERT INTO probes VALUES (1)"}, {Query: "SELECT COUNT(*) AS count FROM probes"}},
+			Transaction:   true,
+			Timeout:       Duration(time.Second),
+			CheckInterval: Duration(time.Minute),
+		}},
+	}
+
+	if err := db.Validate(); err == nil || !strings.Contains(err.Error(), "not read-only (INSERT)") {
+		t.Errorf("Validate() error = %v; expected the INSERT step to be rejected", err)
+	}
+
+	db.Tables[0].AllowWrites = true
+	if err := db.Validate(); err != nil {
+		t.Errorf("Validate() error = %v; expected writes to be allowed", err)
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/awsauth"
+)
+
+const (
+	// sourceFetchTimeout bounds how long fetching a remote configuration may take
+	sourceFetchTimeout = 30 * time.Second
+	// maxSourceSize limits the size of a remote configuration document
+	maxSourceSize = 10 << 20
+	// defaultConsulAddress is used when CONSUL_HTTP_ADDR is not set
+	defaultConsulAddress = "http://127.0.0.1:8500"
+)
+
+// IsRemoteSource reports whether a configuration location is a URL rather than a file path
+func IsRemoteSource(location string) bool {
+	u, err := url.Parse(location)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case "http", "https", "s3", "consul":
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadSource reads raw configuration data from a file path or a remote source.
+// Supported remote sources are http(s)://, s3://bucket/key (credentials from
+// the standard AWS environment variables) and consul://key/path (agent
+// address from CONSUL_HTTP_ADDR, token from CONSUL_HTTP_TOKEN).
+func ReadSource(ctx context.Context, location string) ([]byte, error) {
+	if !IsRemoteSource(location) {
+		return os.ReadFile(location)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config source %q: %w", location, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sourceFetchTimeout)
+	defer cancel()
+
+	switch u.Scheme {
+	case "s3":
+		return fetchS3(ctx, u)
+	case "consul":
+		return fetchConsul(ctx, u)
+	default:
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		return doFetch(req)
+	}
+}
+
+// fetchS3 downloads an object from S3 using a SigV4 signed request.
+// AWS_ENDPOINT_URL_S3 selects an S3-compatible endpoint (path-style addressing).
+func fetchS3(ctx context.Context, u *url.URL) ([]byte, error) {
+	bucket := u.Host
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("s3 config source must be of the form s3://bucket/key")
+	}
+
+	creds, err := awsauth.CredentialsFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("s3 config source: %w", err)
+	}
+	region := awsauth.RegionFromEnv()
+
+	objectURL := &url.URL{
+		Scheme: "https",
+		Host:   fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, region),
+		Path:   "/" + key,
+	}
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_S3"); endpoint != "" {
+		base, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AWS_ENDPOINT_URL_S3: %w", err)
+		}
+		objectURL = base.JoinPath(bucket, key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	awsauth.SignRequest(req, nil, "s3", region, creds, time.Now())
+
+	return doFetch(req)
+}
+
+// fetchConsul reads a raw value from the Consul KV store
+func fetchConsul(ctx context.Context, u *url.URL) ([]byte, error) {
+	key := strings.Trim(u.Host+u.Path, "/")
+	if key == "" {
+		return nil, fmt.Errorf("consul config source must be of the form consul://key/path")
+	}
+
+	base, err := url.Parse(consulAddress(""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CONSUL_HTTP_ADDR: %w", err)
+	}
+	kvURL := base.JoinPath("v1", "kv", key)
+	kvURL.RawQuery = "raw"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, kvURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token := os.Getenv("CONSUL_HTTP_TOKEN"); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	return doFetch(req)
+}
+
+// doFetch executes a request and returns the body of a successful response
+func doFetch(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: unexpected status %s", req.URL.Redacted(), resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", req.URL.Redacted(), err)
+	}
+
+	return data, nil
+}
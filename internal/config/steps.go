@@ -0,0 +1,69 @@
+package config
+
+import "fmt"
+
+// Step is one query of a multi-step check. Steps run in order on a single
+// connection and the check fails at the first step that fails.
+type Step struct {
+	Name    string       `yaml:"name,omitempty"` // shown in failures (default the step number)
+	Query   string       `yaml:"query"`
+	Expect  Expectations `yaml:"expect,omitempty"`   // assertions on the returned rows
+	MinRows *int         `yaml:"min_rows,omitempty"` // fewest rows the query may return
+	MaxRows *int         `yaml:"max_rows,omitempty"` // most rows the query may return
+}
+
+// Validate validates the step
+func (s *Step) Validate() error {
+	if s.Query == "" {
+		return fmt.Errorf("query is required")
+	}
+
+	for i := range s.Expect {
+		if err := s.Expect[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	if (s.MinRows != nil && *s.MinRows < 0) || (s.MaxRows != nil && *s.MaxRows < 0) {
+		return fmt.Errorf("min_rows and max_rows must not be negative")
+	}
+
+	if s.MinRows != nil && s.MaxRows != nil && *s.MaxRows < *s.MinRows {
+		return fmt.Errorf("max_rows must be at least min_rows")
+	}
+
+	return nil
+}
+
+// GetName returns the name of the step at index i, defaulting to its number
+func (s *Step) GetName(i int) string {
+	if s.Name == "" {
+		return fmt.Sprintf("step %d", i+1)
+	}
+	return fmt.Sprintf("step %d (%s)", i+1, s.Name)
+}
+
+// AsTable returns the assertions of the step as a table, so they can be
+// checked like the assertions of a single-query check
+func (s *Step) AsTable() *Table {
+	return &Table{Query: s.Query, Expect: s.Expect, MinRows: s.MinRows, MaxRows: s.MaxRows}
+}
+
+// validateSteps validates the steps of a multi-step check
+func (t *Table) validateSteps() error {
+	if t.Query != "" {
+		return fmt.Errorf("table query and steps are mutually exclusive")
+	}
+
+	if len(t.Expect) > 0 || t.MinRows != nil || t.MaxRows != nil {
+		return fmt.Errorf("expect, min_rows and max_rows belong to the steps of a multi-step check")
+	}
+
+	for i := range t.Steps {
+		if err := t.Steps[i].Validate(); err != nil {
+			return fmt.Errorf("%s: %w", t.Steps[i].GetName(i), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// QueryTemplateData is the data a table's query is expanded with as a Go
+// template when the check runs
+type QueryTemplateData struct {
+	Now      time.Time         // time the check runs, in UTC
+	Today    string            // date the check runs, in UTC, as 2006-01-02
+	Database string            // database name
+	Table    string            // check name
+	Vars     map[string]string // database vars overridden by the table's vars
+}
+
+// TableVars returns the effective template variables of a table: the
+// database vars overridden by the table's own vars
+func (d *Database) TableVars(table *Table) map[string]string {
+	vars := make(map[string]string, len(d.Vars)+len(table.Vars))
+	for key, value := range d.Vars {
+		vars[key] = value
+	}
+	for key, value := range table.Vars {
+		vars[key] = value
+	}
+	return vars
+}
+
+// RenderQuery returns a table's query with its template expanded for a check
+// running at now. Queries without "{{" are returned as is.
+func (d *Database) RenderQuery(table *Table, now time.Time) (string, error) {
+	return d.render(table, table.Query, now)
+}
+
+// RenderQueries returns the queries of a table's check with their templates
+// expanded for a check running at now: the query, or the query of each step
+func (d *Database) RenderQueries(table *Table, now time.Time) ([]string, error) {
+	if len(table.Steps) == 0 {
+		query, err := d.RenderQuery(table, now)
+		return []string{query}, err
+	}
+
+	queries := make([]string, len(table.Steps))
+	for i := range table.Steps {
+		query, err := d.render(table, table.Steps[i].Query, now)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", table.Steps[i].GetName(i), err)
+		}
+		queries[i] = query
+	}
+	return queries, nil
+}
+
+// render expands a query template of a table
+func (d *Database) render(table *Table, query string, now time.Time) (string, error) {
+	if !strings.Contains(query, "{{") {
+		return query, nil
+	}
+
+	tmpl, err := template.New(table.Name).Option("missingkey=error").Parse(query)
+	if err != nil {
+		return "", err
+	}
+
+	now = now.UTC()
+	var expanded strings.Builder
+	err = tmpl.Execute(&expanded, QueryTemplateData{
+		Now:      now,
+		Today:    now.Format("2006-01-02"),
+		Database: d.Name,
+		Table:    table.Name,
+		Vars:     d.TableVars(table),
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded.String(), nil
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderQuery(t *testing.T) {
+	db := &Database{Name: "warehouse", Vars: map[string]string{"schema": "sales", "region": "eu"}}
+	now := time.Date(2024, 3, 9, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))
+
+	tests := []struct {
+		name        string
+		table       Table
+		expected    string
+		expectError bool
+	}{
+		{"plain query", Table{Name: "users", Query: "SELECT 1"}, "SELECT 1", false},
+		{"today", Table{Name: "loads", Query: "SELECT COUNT(*) FROM loads WHERE day = '{{ .Today }}'"}, "SELECT COUNT(*) FROM loads WHERE day = '2024-03-10'", false},
+		{"now", Table{Name: "events", Query: `SELECT 1 WHERE '{{ .Now.Format "15:04" }}' > '04:00'`}, "SELECT 1 WHERE '04:30' > '04:00'", false},
+		{"names", Table{Name: "orders", Query: "SELECT '{{ .Database }}/{{ .Table }}'"}, "SELECT 'warehouse/orders'", false},
+		{"vars", Table{Name: "orders", Query: "SELECT 1 FROM {{ .Vars.schema }}.orders WHERE region = '{{ .Vars.region }}'", Vars: map[string]string{"region": "us"}}, "SELECT 1 FROM sales.orders WHERE region = 'us'", false},
+		{"unknown var", Table{Name: "orders", Query: "SELECT {{ .Vars.missing }}"}, "", true},
+		{"syntax error", Table{Name: "orders", Query: "SELECT {{ .Today "}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := db.RenderQuery(&tt.table, now)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("RenderQuery() = %q; expected an error", query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderQuery() error = %v", err)
+			}
+			if query != tt.expected {
+				t.Errorf("RenderQuery() = %q; expected %q", query, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderQueriesSteps(t *testing.T) {
+	db := &Database{Name: "app", Vars: map[string]string{"role": "app_reader"}}
+	table := &Table{Name: "probe", Steps: []Step{
+		{Query: "SET ROLE {{ .Vars.role }}"},
+		{Name: "read", Query: "SELECT 1"},
+	}}
+
+	queries, err := db.RenderQueries(table, time.Now())
+	if err != nil {
+		t.Fatalf("RenderQueries() error = %v", err)
+	}
+	if len(queries) != 2 || queries[0] != "SET ROLE app_reader" || queries[1] != "SELECT 1" {
+		t.Errorf("RenderQueries() = %q; expected the expanded step queries", queries)
+	}
+
+	table.Steps[1].Query = "SELECT {{ .Vars.missing }}"
+	if _, err := db.RenderQueries(table, time.Now()); err == nil || !strings.Contains(err.Error(), "step 2 (read)") {
+		t.Errorf("RenderQueries() error = %v; expected an error naming step 2", err)
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Zabbix represents the optional Zabbix sender, pushing the status and query
+// time of every check run to trapper items of a Zabbix server or proxy
+type Zabbix struct {
+	Enabled bool     `yaml:"enabled"`
+	Server  string   `yaml:"server"`  // host:port of the server or proxy (default port 10051)
+	Host    string   `yaml:"host"`    // Zabbix host the items belong to (default the hostname)
+	Timeout Duration `yaml:"timeout"` // default 10s
+}
+
+// Zabbix defaults
+const (
+	defaultZabbixPort    = "10051"
+	defaultZabbixTimeout = 10 * time.Second
+)
+
+// Validate validates Zabbix configuration
+func (z *Zabbix) Validate() error {
+	if !z.Enabled {
+		return nil
+	}
+
+	if z.Server == "" {
+		return fmt.Errorf("server is required")
+	}
+	if strings.Contains(z.Server, "://") {
+		return fmt.Errorf("invalid server: %q (expected host:port)", z.Server)
+	}
+	if len(z.GetHost()) > 128 {
+		return fmt.Errorf("host must be at most 128 characters")
+	}
+	if z.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
+
+// GetServer returns the address of the server or proxy with its port
+func (z *Zabbix) GetServer() string {
+	if _, _, err := net.SplitHostPort(z.Server); err != nil {
+		return net.JoinHostPort(z.Server, defaultZabbixPort)
+	}
+	return z.Server
+}
+
+// GetHost returns the Zabbix host of the items, defaulting to the hostname
+func (z *Zabbix) GetHost() string {
+	if z.Host != "" {
+		return z.Host
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// GetTimeout returns the timeout of sends, defaulting to 10 seconds
+func (z *Zabbix) GetTimeout() time.Duration {
+	if z.Timeout == 0 {
+		return defaultZabbixTimeout
+	}
+	return time.Duration(z.Timeout)
+}
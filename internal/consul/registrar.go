@@ -0,0 +1,403 @@
+// Package consul registers gsqlhealth, and a synthetic service per monitored
+// database, in the local Consul agent, with checks following the health of
+// the databases.
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+const (
+	// requestTimeout bounds requests to the agent
+	requestTimeout = 5 * time.Second
+	// retryInterval is the interval of registration attempts in HTTP check
+	// mode, until the agent accepted the services
+	retryInterval = 10 * time.Second
+	// httpCheckTimeout bounds the HTTP checks of the agent
+	httpCheckTimeout = "5s"
+)
+
+// Check statuses of the agent API
+const (
+	statusPassing  = "passing"
+	statusWarning  = "warning"
+	statusCritical = "critical"
+)
+
+// service is a service registration of the agent API
+type service struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Address string            `json:"Address,omitempty"`
+	Port    int               `json:"Port,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   check             `json:"Check"`
+
+	database string // monitored database, empty for gsqlhealth itself
+}
+
+// check is the check of a service registration
+type check struct {
+	CheckID                        string              `json:"CheckID"`
+	Name                           string              `json:"Name"`
+	HTTP                           string              `json:"HTTP,omitempty"`
+	Header                         map[string][]string `json:"Header,omitempty"`
+	TLSSkipVerify                  bool                `json:"TLSSkipVerify,omitempty"`
+	Interval                       string              `json:"Interval,omitempty"`
+	Timeout                        string              `json:"Timeout,omitempty"`
+	TTL                            string              `json:"TTL,omitempty"`
+	DeregisterCriticalServiceAfter string              `json:"DeregisterCriticalServiceAfter,omitempty"`
+}
+
+// checkUpdate is the body of TTL check updates
+type checkUpdate struct {
+	Status string `json:"Status"`
+	Output string `json:"Output"`
+}
+
+// Registrar registers the services of gsqlhealth in the Consul agent while it
+// runs, and deregisters them on Stop. In TTL mode it reports the status of
+// every service at a third of the TTL, re-registering services the agent lost.
+type Registrar struct {
+	cfg           config.Consul
+	server        config.Server
+	healthService *health.Service
+	logger        *slog.Logger
+	address       *url.URL
+	client        *http.Client
+	services      []service
+
+	// registered is whether the agent accepted the services, only accessed
+	// by the goroutine registering them
+	registered bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRegistrar creates a registrar of the configured services
+func NewRegistrar(cfg *config.Config, healthService *health.Service, logger *slog.Logger) (*Registrar, error) {
+	address, err := url.Parse(cfg.Consul.GetAddress())
+	if err != nil {
+		return nil, fmt.Errorf("invalid consul address: %w", err)
+	}
+
+	r := &Registrar{
+		cfg:           cfg.Consul,
+		server:        cfg.Server,
+		healthService: healthService,
+		logger:        logger,
+		address:       address,
+		client:        &http.Client{Timeout: requestTimeout},
+	}
+
+	serviceID := cfg.Consul.GetServiceID()
+	r.services = append(r.services, service{
+		ID:      serviceID,
+		Name:    cfg.Consul.GetServiceName(),
+		Tags:    cfg.Consul.Tags,
+		Address: cfg.Consul.ServiceAddress,
+		Port:    cfg.Server.Port,
+		Check:   r.newCheck(serviceID, "gsqlhealth", cfg.Consul.GetCheckURL(cfg.Server)+"/"),
+	})
+
+	if cfg.Consul.Databases {
+		for _, dbConfig := range healthService.GetDatabases() {
+			if dbConfig.IsEnabled() {
+				r.services = append(r.services, r.databaseService(dbConfig))
+			}
+		}
+	}
+	return r, nil
+}
+
+// databaseService returns the synthetic service of a monitored database
+func (r *Registrar) databaseService(dbConfig config.Database) service {
+	serviceID := r.cfg.GetServiceID()
+	id := serviceID + "-" + r.cfg.GetDatabaseServiceName(dbConfig.Name)
+	checkURL := r.cfg.GetCheckURL(r.server) + "/health/" + url.PathEscape(dbConfig.Name)
+	return service{
+		ID:      id,
+		Name:    r.cfg.GetDatabaseServiceName(dbConfig.Name),
+		Tags:    append(append([]string(nil), r.cfg.Tags...), dbConfig.Type),
+		Address: dbConfig.Host,
+		Port:    dbConfig.Port,
+		Meta:    map[string]string{"database": dbConfig.Name, "type": dbConfig.Type, "monitored_by": serviceID},
+		Check:   r.newCheck(id, "Database "+dbConfig.Name, checkURL),
+
+		database: dbConfig.Name,
+	}
+}
+
+// newCheck returns the check of a service: an HTTP check of checkURL, or a
+// TTL check updated by the registrar
+func (r *Registrar) newCheck(serviceID, name, checkURL string) check {
+	c := check{CheckID: "service:" + serviceID, Name: name}
+	if r.cfg.DeregisterAfter > 0 {
+		c.DeregisterCriticalServiceAfter = time.Duration(r.cfg.DeregisterAfter).String()
+	}
+
+	if r.cfg.GetCheckType() == config.ConsulCheckTTL {
+		c.TTL = r.cfg.GetTTL().String()
+		return c
+	}
+
+	c.HTTP = checkURL
+	c.Interval = r.cfg.GetInterval().String()
+	c.Timeout = httpCheckTimeout
+	c.TLSSkipVerify = r.cfg.TLSSkipVerify
+	if len(r.cfg.CheckHeaders) > 0 {
+		c.Header = make(map[string][]string, len(r.cfg.CheckHeaders))
+		for name, value := range r.cfg.CheckHeaders {
+			c.Header[name] = []string{value}
+		}
+	}
+	return c
+}
+
+// Start registers the services, retrying until the agent accepts them, and
+// reports their status in TTL mode until Stop is called
+func (r *Registrar) Start() {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	interval := retryInterval
+	ttl := r.cfg.GetCheckType() == config.ConsulCheckTTL
+	if ttl {
+		interval = r.cfg.GetTTL() / 3
+	}
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer close(r.done)
+		defer ticker.Stop()
+		for {
+			r.tick(ttl)
+			select {
+			case <-ticker.C:
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+
+	r.logger.Info("Consul registration enabled",
+		"agent", r.address.Redacted(),
+		"services", len(r.services),
+		"check", r.cfg.GetCheckType())
+}
+
+// Stop ends the status reports and deregisters the services
+func (r *Registrar) Stop() {
+	if r.done == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	for _, svc := range r.services {
+		if err := r.call(ctx, "/v1/agent/service/deregister/"+url.PathEscape(svc.ID), nil); err != nil {
+			r.logger.Warn("Failed to deregister Consul service", "service_id", svc.ID, "error", err)
+		}
+	}
+}
+
+// tick registers the services unless they are, and reports their status in
+// TTL mode
+func (r *Registrar) tick(ttl bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if !r.registered {
+		if err := r.register(ctx); err != nil {
+			r.logger.Warn("Failed to register Consul services", "agent", r.address.Redacted(), "error", err)
+			return
+		}
+		r.registered = true
+		r.logger.Info("Registered Consul services", "services", len(r.services))
+	} else if r.cfg.Databases {
+		r.syncDatabases(ctx)
+	}
+
+	if !ttl {
+		return
+	}
+	for _, svc := range r.services {
+		status, output := r.status(svc)
+		err := r.call(ctx, "/v1/agent/check/update/"+url.PathEscape(svc.Check.CheckID), checkUpdate{Status: status, Output: output})
+		if err != nil {
+			// The agent forgets services when it restarts without persistence;
+			// they are registered again on the next tick
+			r.logger.Warn("Failed to update Consul check", "check_id", svc.Check.CheckID, "error", err)
+			r.registered = false
+			return
+		}
+	}
+}
+
+// syncDatabases registers the services of databases added through the admin
+// API and deregisters those of databases removed since, retrying failed
+// requests on the next tick
+func (r *Registrar) syncDatabases(ctx context.Context) {
+	monitored := make(map[string]config.Database)
+	for _, dbConfig := range r.healthService.GetDatabases() {
+		if dbConfig.IsEnabled() {
+			monitored[dbConfig.Name] = dbConfig
+		}
+	}
+
+	services := r.services[:0]
+	for _, svc := range r.services {
+		if _, exists := monitored[svc.database]; svc.database == "" || exists {
+			delete(monitored, svc.database)
+			services = append(services, svc)
+			continue
+		}
+		if err := r.call(ctx, "/v1/agent/service/deregister/"+url.PathEscape(svc.ID), nil); err != nil {
+			r.logger.Warn("Failed to deregister Consul service", "service_id", svc.ID, "error", err)
+			services = append(services, svc)
+			continue
+		}
+		r.logger.Info("Deregistered Consul service of removed database", "service_id", svc.ID, "database", svc.database)
+	}
+	r.services = services
+
+	names := make([]string, 0, len(monitored))
+	for name := range monitored {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		svc := r.databaseService(monitored[name])
+		if err := r.call(ctx, "/v1/agent/service/register?replace-existing-checks=true", svc); err != nil {
+			r.logger.Warn("Failed to register Consul service", "service_id", svc.ID, "error", err)
+			continue
+		}
+		r.services = append(r.services, svc)
+		r.logger.Info("Registered Consul service of added database", "service_id", svc.ID, "database", name)
+	}
+}
+
+// register registers all services, replacing their existing checks
+func (r *Registrar) register(ctx context.Context) error {
+	for _, svc := range r.services {
+		if err := r.call(ctx, "/v1/agent/service/register?replace-existing-checks=true", svc); err != nil {
+			return fmt.Errorf("service %s: %w", svc.ID, err)
+		}
+	}
+	return nil
+}
+
+// status returns the TTL check status of a service and its output: passing
+// for gsqlhealth itself, and the aggregate of the cached results of a
+// database's checks, as /health/{database} reports it
+func (r *Registrar) status(svc service) (string, string) {
+	if svc.database == "" {
+		return statusPassing, "gsqlhealth is running"
+	}
+
+	results, err := r.healthService.GetCachedDatabaseHealth(svc.database)
+	if err != nil {
+		return statusCritical, err.Error()
+	}
+	return databaseStatus(results, r.server.GetUnknownStatusCode())
+}
+
+// databaseStatus aggregates check results into a check status the way
+// /health/{database} does: critical when a check fails, warning when the
+// database is degraded, and for results nobody can vouch for the status an
+// HTTP check of unknownStatusCode would have. The output names the checks
+// that are not passing.
+func databaseStatus(results []*database.HealthResult, unknownStatusCode int) (string, string) {
+	summary := database.NewSummary()
+	summary.Add(results...)
+
+	status := statusPassing
+	switch summary.Status {
+	case database.StatusUnhealthy:
+		status = statusCritical
+	case database.StatusUnknown:
+		status = httpCheckStatus(unknownStatusCode)
+	case database.StatusDegraded:
+		status = statusWarning
+	}
+
+	var problems []string
+	for _, result := range results {
+		switch result.AggregateStatus() {
+		case database.StatusHealthy, database.StatusDisabled, database.StatusPaused, database.StatusMaintenance:
+		default:
+			problems = append(problems, result.TableName+" "+string(result.Status))
+		}
+	}
+
+	if len(problems) == 0 {
+		return status, fmt.Sprintf("%d checks passing", len(results))
+	}
+	sort.Strings(problems)
+	return status, fmt.Sprintf("%d of %d checks not passing: %s", len(problems), len(results), strings.Join(problems, ", "))
+}
+
+// httpCheckStatus returns the status the agent gives an HTTP check answered
+// with statusCode: passing for 2xx, warning for 429 and critical otherwise
+func httpCheckStatus(statusCode int) string {
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return statusPassing
+	case statusCode == http.StatusTooManyRequests:
+		return statusWarning
+	default:
+		return statusCritical
+	}
+}
+
+// call sends a PUT request to the agent API, with body encoded as JSON unless
+// it is nil
+func (r *Registrar) call(ctx context.Context, path string, body interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	endpoint := strings.TrimSuffix(r.address.String(), "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if token := r.cfg.GetToken(); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("consul returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package consul
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+// request is a request received by the test agent
+type request struct {
+	path  string
+	token string
+	body  string
+}
+
+// testAgent is a fake Consul agent recording requests, which forgets its
+// services when told to
+type testAgent struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []request
+	services map[string]bool
+	down     bool
+}
+
+// newTestAgent starts a test agent
+func newTestAgent(t *testing.T) *testAgent {
+	t.Helper()
+	agent := &testAgent{services: make(map[string]bool)}
+	agent.server = httptest.NewServer(http.HandlerFunc(agent.handle))
+	t.Cleanup(agent.server.Close)
+	return agent
+}
+
+func (a *testAgent) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = append(a.requests, request{path: r.URL.Path, token: r.Header.Get("X-Consul-Token"), body: string(body)})
+
+	switch {
+	case r.Method != http.MethodPut:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	case a.down:
+		http.Error(w, "agent unavailable", http.StatusInternalServerError)
+	case r.URL.Path == "/v1/agent/service/register":
+		var svc service
+		json.Unmarshal(body, &svc)
+		a.services[svc.ID] = true
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		delete(a.services, strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/check/update/service:"):
+		if !a.services[strings.TrimPrefix(r.URL.Path, "/v1/agent/check/update/service:")] {
+			http.Error(w, "Unknown check ID", http.StatusNotFound)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// received returns the requests received, and resets them
+func (a *testAgent) received() []request {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	requests := a.requests
+	a.requests = nil
+	return requests
+}
+
+// forget drops the registered services, as an agent restarted without
+// persistence does
+func (a *testAgent) forget() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.services = make(map[string]bool)
+}
+
+// setDown makes the agent fail requests
+func (a *testAgent) setDown(down bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.down = down
+}
+
+// newTestRegistrar returns a registrar of a test agent, for a server on port
+// 8080 monitoring the databases app and Reports DB
+func newTestRegistrar(t *testing.T, agent *testAgent, consulConfig config.Consul) *Registrar {
+	t.Helper()
+	consulConfig.Enabled = true
+	consulConfig.Address = agent.server.URL
+	consulConfig.ServiceID = "gsqlhealth-1"
+	cfg := &config.Config{
+		Server: config.Server{Port: 8080},
+		Databases: []config.Database{
+			{Name: "app", Type: "postgres", Host: "db1", Port: 5432, Tables: []config.Table{{Name: "orders"}}},
+			{Name: "Reports DB", Type: "mysql", Host: "db2", Port: 3306, Tables: []config.Table{{Name: "jobs"}}},
+		},
+		Consul: consulConfig,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registrar, err := NewRegistrar(cfg, health.NewService(cfg, logger), logger)
+	if err != nil {
+		t.Fatalf("NewRegistrar() error = %v", err)
+	}
+	return registrar
+}
+
+func TestRegisterHTTPChecks(t *testing.T) {
+	agent := newTestAgent(t)
+	registrar := newTestRegistrar(t, agent, config.Consul{
+		Token:        "secret",
+		Tags:         []string{"monitoring"},
+		CheckHeaders: map[string]string{"X-API-Key": "key"},
+		Databases:    true,
+	})
+
+	registrar.tick(false)
+	requests := agent.received()
+	if len(requests) != 3 {
+		t.Fatalf("received %d requests; expected 3 registrations", len(requests))
+	}
+
+	var services []service
+	for _, req := range requests {
+		if req.path != "/v1/agent/service/register" || req.token != "secret" {
+			t.Errorf("request = %s with token %q; expected a registration with the token", req.path, req.token)
+		}
+		var svc service
+		if err := json.Unmarshal([]byte(req.body), &svc); err != nil {
+			t.Fatalf("Failed to decode registration: %v", err)
+		}
+		services = append(services, svc)
+	}
+
+	self := services[0]
+	if self.ID != "gsqlhealth-1" || self.Name != "gsqlhealth" || self.Port != 8080 {
+		t.Errorf("service = %+v; expected gsqlhealth on port 8080", self)
+	}
+	if self.Check.HTTP != "http://127.0.0.1:8080/" || self.Check.Interval != "10s" || self.Check.TTL != "" {
+		t.Errorf("check = %+v; expected an HTTP check of the server every 10s", self.Check)
+	}
+
+	reports := services[2]
+	if reports.ID != "gsqlhealth-1-reports-db" || reports.Name != "reports-db" || reports.Address != "db2" || reports.Port != 3306 {
+		t.Errorf("service = %+v; expected reports-db at db2:3306", reports)
+	}
+	if reports.Meta["database"] != "Reports DB" || strings.Join(reports.Tags, ",") != "monitoring,mysql" {
+		t.Errorf("meta = %v, tags = %v; expected the database and its type", reports.Meta, reports.Tags)
+	}
+	if reports.Check.CheckID != "service:gsqlhealth-1-reports-db" || reports.Check.HTTP != "http://127.0.0.1:8080/health/Reports%20DB" {
+		t.Errorf("check = %+v; expected an HTTP check of /health/Reports%%20DB", reports.Check)
+	}
+	if got := reports.Check.Header["X-API-Key"]; len(got) != 1 || got[0] != "key" {
+		t.Errorf("check headers = %v; expected the API key", reports.Check.Header)
+	}
+
+	// Registered services are not registered again
+	registrar.tick(false)
+	if requests := agent.received(); len(requests) != 0 {
+		t.Errorf("received %d requests; expected none", len(requests))
+	}
+}
+
+func TestTTLChecks(t *testing.T) {
+	agent := newTestAgent(t)
+	registrar := newTestRegistrar(t, agent, config.Consul{Check: config.ConsulCheckTTL, TTL: config.Duration(15 * time.Second), Databases: true})
+
+	registrar.tick(true)
+	requests := agent.received()
+	if len(requests) != 6 {
+		t.Fatalf("received %d requests; expected 3 registrations and 3 updates", len(requests))
+	}
+	if !strings.Contains(requests[0].body, `"TTL":"15s"`) || strings.Contains(requests[0].body, `"HTTP"`) {
+		t.Errorf("registration = %s; expected a TTL check", requests[0].body)
+	}
+
+	expected := map[string]string{
+		"/v1/agent/check/update/service:gsqlhealth-1":     statusPassing,
+		"/v1/agent/check/update/service:gsqlhealth-1-app": statusCritical, // no results yet
+	}
+	for _, req := range requests[3:5] {
+		var update checkUpdate
+		if err := json.Unmarshal([]byte(req.body), &update); err != nil {
+			t.Fatalf("Failed to decode update: %v", err)
+		}
+		if update.Status != expected[req.path] {
+			t.Errorf("update of %s = %+v; expected %s", req.path, update, expected[req.path])
+		}
+	}
+
+	// Services the agent forgot are registered again on the next tick
+	agent.forget()
+	registrar.tick(true)
+	if requests := agent.received(); len(requests) != 1 {
+		t.Errorf("received %d requests; expected the failed update", len(requests))
+	}
+	registrar.tick(true)
+	if requests := agent.received(); len(requests) != 6 || requests[0].path != "/v1/agent/service/register" {
+		t.Errorf("received %d requests; expected the registrations and updates", len(requests))
+	}
+}
+
+func TestRegistrarRetriesAndDeregisters(t *testing.T) {
+	agent := newTestAgent(t)
+	registrar := newTestRegistrar(t, agent, config.Consul{})
+
+	agent.setDown(true)
+	registrar.tick(false)
+	if registrar.registered {
+		t.Fatal("registered = true; expected the failed registration to be retried")
+	}
+
+	agent.setDown(false)
+	registrar.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		agent.mu.Lock()
+		registered := agent.services["gsqlhealth-1"]
+		agent.mu.Unlock()
+		if registered {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	registrar.Stop()
+	agent.mu.Lock()
+	defer agent.mu.Unlock()
+	if len(agent.services) != 0 {
+		t.Errorf("services = %v; expected them deregistered", agent.services)
+	}
+	if last := agent.requests[len(agent.requests)-1]; last.path != "/v1/agent/service/deregister/gsqlhealth-1" {
+		t.Errorf("last request = %s; expected the deregistration", last.path)
+	}
+}
+
+func TestRegistrarSyncsDynamicDatabases(t *testing.T) {
+	agent := newTestAgent(t)
+	cfg := &config.Config{
+		Server: config.Server{Port: 8080},
+		Databases: []config.Database{
+			{Name: "app", Type: "postgres", Host: "db1", Port: 5432, Tables: []config.Table{{Name: "orders"}}},
+		},
+		Consul: config.Consul{Enabled: true, Address: agent.server.URL, ServiceID: "gsqlhealth-1", Databases: true},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registrar, err := NewRegistrar(cfg, health.NewService(cfg, logger), logger)
+	if err != nil {
+		t.Fatalf("NewRegistrar() error = %v", err)
+	}
+
+	registrar.tick(false)
+	agent.received()
+
+	// Databases added through the admin API replace the list
+	cfg.Databases = append([]config.Database{{Name: "billing", Type: "mysql", Host: "db3", Port: 3306}}, cfg.Databases[0])
+	registrar.tick(false)
+	agent.mu.Lock()
+	registered := agent.services["gsqlhealth-1-billing"]
+	agent.mu.Unlock()
+	if !registered {
+		t.Errorf("services = %v; expected the added database to be registered", agent.services)
+	}
+
+	cfg.Databases = cfg.Databases[:1]
+	registrar.tick(false)
+	requests := agent.received()
+	if len(requests) != 2 || requests[1].path != "/v1/agent/service/deregister/gsqlhealth-1-app" {
+		t.Errorf("received %v; expected the removed database to be deregistered", requests)
+	}
+	if len(registrar.services) != 2 {
+		t.Errorf("services = %d; expected gsqlhealth and billing", len(registrar.services))
+	}
+}
+
+func TestDatabaseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []*database.HealthResult
+		expected string
+		output   string
+	}{
+		{
+			name: "healthy",
+			results: []*database.HealthResult{
+				{TableName: "orders", Status: database.StatusHealthy},
+				{TableName: "jobs", Status: database.StatusMaintenance},
+			},
+			expected: statusPassing,
+			output:   "2 checks passing",
+		},
+		{
+			name: "degraded",
+			results: []*database.HealthResult{
+				{TableName: "orders", Status: database.StatusHealthy},
+				{TableName: "jobs", Status: database.StatusDegraded},
+			},
+			expected: statusWarning,
+			output:   "1 of 2 checks not passing: jobs degraded",
+		},
+		{
+			name: "unhealthy",
+			results: []*database.HealthResult{
+				{TableName: "orders", Status: database.StatusUnhealthy},
+				{TableName: "jobs", Status: database.StatusDegraded},
+			},
+			expected: statusCritical,
+			output:   "2 of 2 checks not passing: jobs degraded, orders unhealthy",
+		},
+		{
+			name: "warning severity",
+			results: []*database.HealthResult{
+				{TableName: "orders", Status: database.StatusUnhealthy, Severity: config.SeverityWarning},
+			},
+			expected: statusWarning,
+			output:   "1 of 1 checks not passing: orders unhealthy",
+		},
+		{
+			name: "unknown",
+			results: []*database.HealthResult{
+				{TableName: "orders", Status: database.StatusUnknown},
+				{TableName: "jobs", Status: database.StatusHealthy},
+			},
+			expected: statusPassing,
+			output:   "1 of 2 checks not passing: orders unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, output := databaseStatus(tt.results, http.StatusOK)
+			if status != tt.expected || output != tt.output {
+				t.Errorf("databaseStatus() = %s, %q; expected %s, %q", status, output, tt.expected, tt.output)
+			}
+		})
+	}
+
+	// Unknown results follow unknown_status_code as the HTTP checks do
+	unknown := []*database.HealthResult{{TableName: "orders", Status: database.StatusUnknown}}
+	for code, expected := range map[int]string{http.StatusServiceUnavailable: statusCritical, http.StatusTooManyRequests: statusWarning} {
+		if status, _ := databaseStatus(unknown, code); status != expected {
+			t.Errorf("databaseStatus() with unknown_status_code %d = %s; expected %s", code, status, expected)
+		}
+	}
+}
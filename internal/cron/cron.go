@@ -0,0 +1,182 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field describes the allowed range of a cron field
+type field struct {
+	name string
+	min  int
+	max  int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+// Schedule is a parsed five-field cron expression
+// (minute hour day-of-month month day-of-week)
+type Schedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// domStar and dowStar record unrestricted day fields; when both day
+	// fields are restricted a time matches if either of them matches
+	domStar bool
+	dowStar bool
+}
+
+// Parse parses a five-field cron expression. Each field accepts `*`, single
+// values, ranges (`1-5`), lists (`1,15`) and steps (`*/15`, `0-30/10`).
+// Day of week 7 is accepted as Sunday.
+func Parse(expression string) (*Schedule, error) {
+	parts := strings.Fields(expression)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields", expression, len(fields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, part := range parts {
+		parsed, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expression, err)
+		}
+		bits[i] = parsed
+	}
+
+	// Fold day of week 7 onto Sunday
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &Schedule{
+		minute:     bits[0],
+		hour:       bits[1],
+		dayOfMonth: bits[2],
+		month:      bits[3],
+		dayOfWeek:  bits[4],
+		domStar:    parts[2] == "*",
+		dowStar:    parts[4] == "*",
+	}, nil
+}
+
+// Matches reports whether the schedule fires in the minute containing t
+func (s *Schedule) Matches(t time.Time) bool {
+	if s.minute&(1<<uint(t.Minute())) == 0 ||
+		s.hour&(1<<uint(t.Hour())) == 0 ||
+		s.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+
+	return s.matchesDay(t)
+}
+
+// Prev returns the latest time the schedule fires after after and no later
+// than t, in the location of t. It looks at each day between them once
+// instead of every minute, so long spans stay cheap.
+func (s *Schedule) Prev(t, after time.Time) (time.Time, bool) {
+	for day := 0; ; day++ {
+		date := time.Date(t.Year(), t.Month(), t.Day()-day, 0, 0, 0, 0, t.Location())
+		if !date.AddDate(0, 0, 1).After(after) {
+			return time.Time{}, false
+		}
+		if s.month&(1<<uint(date.Month())) == 0 || !s.matchesDay(date) {
+			continue
+		}
+
+		maxHour := 23
+		if day == 0 {
+			maxHour = t.Hour()
+		}
+		for hour := maxHour; hour >= 0; hour-- {
+			if s.hour&(1<<uint(hour)) == 0 {
+				continue
+			}
+
+			maxMinute := 59
+			if day == 0 && hour == t.Hour() {
+				maxMinute = t.Minute()
+			}
+			for minute := maxMinute; minute >= 0; minute-- {
+				if s.minute&(1<<uint(minute)) == 0 {
+					continue
+				}
+
+				fire := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, t.Location())
+				if fire.Hour() != hour || fire.Minute() != minute || fire.After(t) {
+					// Skipped by a daylight saving gap
+					continue
+				}
+				if !fire.After(after) {
+					return time.Time{}, false
+				}
+				return fire, true
+			}
+		}
+	}
+}
+
+// matchesDay reports whether the day fields of the schedule match the day of t
+func (s *Schedule) matchesDay(t time.Time) bool {
+	domMatch := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField parses a comma-separated cron field into a bit set
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, term := range strings.Split(value, ",") {
+		rangePart, step := term, 1
+		if before, after, found := strings.Cut(term, "/"); found {
+			parsedStep, err := strconv.Atoi(after)
+			if err != nil || parsedStep <= 0 {
+				return 0, fmt.Errorf("invalid %s step: %q", f.name, term)
+			}
+			rangePart, step = before, parsedStep
+		}
+
+		low, high := f.min, f.max
+		if rangePart != "*" {
+			before, after, isRange := strings.Cut(rangePart, "-")
+
+			var err error
+			low, err = strconv.Atoi(before)
+			if err != nil {
+				return 0, fmt.Errorf("invalid %s: %q", f.name, term)
+			}
+			high = low
+			if isRange {
+				high, err = strconv.Atoi(after)
+				if err != nil {
+					return 0, fmt.Errorf("invalid %s: %q", f.name, term)
+				}
+			}
+		}
+
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%s %q out of range %d-%d", f.name, term, f.min, f.max)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
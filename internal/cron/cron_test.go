@@ -0,0 +1,96 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	}
+
+	for _, expression := range invalid {
+		if _, err := Parse(expression); err == nil {
+			t.Errorf("Parse(%q) expected error", expression)
+		}
+	}
+}
+
+func TestScheduleMatches(t *testing.T) {
+	// Wednesday 2024-01-10 02:30
+	wednesday := time.Date(2024, 1, 10, 2, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		time       time.Time
+		expected   bool
+	}{
+		{"* * * * *", wednesday, true},
+		{"30 2 * * *", wednesday, true},
+		{"0 2 * * *", wednesday, false},
+		{"*/15 * * * *", wednesday, true},
+		{"0-20/10 * * * *", wednesday, false},
+		{"30 2 * * 1-5", wednesday, true},
+		{"30 2 * * 0,6", wednesday, false},
+		{"30 2 * * 7", time.Date(2024, 1, 14, 2, 30, 0, 0, time.UTC), true},
+		{"30 2 1 * *", wednesday, false},
+		// Both day fields restricted: either may match
+		{"30 2 1 * 3", wednesday, true},
+		{"30 2 10 1 *", wednesday, true},
+		{"30 2 10 2 *", wednesday, false},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expression)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.expression, err)
+		}
+		if matched := schedule.Matches(tt.time); matched != tt.expected {
+			t.Errorf("Parse(%q).Matches(%v) = %v; expected %v", tt.expression, tt.time, matched, tt.expected)
+		}
+	}
+}
+
+func TestSchedulePrev(t *testing.T) {
+	// Wednesday 2024-01-10 02:30
+	wednesday := time.Date(2024, 1, 10, 2, 30, 0, 0, time.UTC)
+	week := wednesday.Add(-7 * 24 * time.Hour)
+
+	tests := []struct {
+		expression string
+		after      time.Time
+		expected   time.Time
+		found      bool
+	}{
+		{"* * * * *", week, wednesday, true},
+		{"0 * * * *", week, time.Date(2024, 1, 10, 2, 0, 0, 0, time.UTC), true},
+		{"45 23 * * *", week, time.Date(2024, 1, 9, 23, 45, 0, 0, time.UTC), true},
+		{"0 3 * * 0", week, time.Date(2024, 1, 7, 3, 0, 0, 0, time.UTC), true},
+		{"0 3 1 * *", week, time.Time{}, false},
+		{"0 3 1 * *", wednesday.AddDate(0, -1, 0), time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC), true},
+		// The bound itself is excluded
+		{"0 2 * * *", time.Date(2024, 1, 10, 2, 0, 0, 0, time.UTC), time.Time{}, false},
+		{"0 2 * * *", time.Date(2024, 1, 10, 1, 59, 0, 0, time.UTC), time.Date(2024, 1, 10, 2, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expression)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.expression, err)
+		}
+		prev, found := schedule.Prev(wednesday, tt.after)
+		if found != tt.found || !prev.Equal(tt.expected) {
+			t.Errorf("Parse(%q).Prev(%v, %v) = %v, %v; expected %v, %v", tt.expression, wednesday, tt.after, prev, found, tt.expected, tt.found)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The queries of the built-in checks are generated in the dialect of the
+// database type. Tables may be schema qualified ("sales.orders"); each part
+// is quoted as an identifier, so callers must validate the names.
+
+// CountQuery returns the query of the row_count check for a table
+func CountQuery(dbType, table string) string {
+	return "SELECT COUNT(*) AS count FROM " + quoteTable(dbType, table)
+}
+
+// FreshnessQuery returns the query of the freshness check, selecting the
+// newest value of a timestamp column as "newest"
+func FreshnessQuery(dbType, table, column string) string {
+	return "SELECT MAX(" + quoteIdentifier(dbType, column) + ") AS newest FROM " + quoteTable(dbType, table)
+}
+
+// ReplicationLagQuery returns the query of the replication_lag check, or ""
+// for database types without one. PostgreSQL reports the replay lag as
+// "lag_seconds", zero when all received WAL is replayed so an idle source
+// does not look like lag, and NULL on a primary. MySQL reports its replica
+// status, with the lag in Seconds_Behind_Source.
+func ReplicationLagQuery(dbType string) string {
+	switch dbType {
+	case "postgres":
+		return `SELECT pg_is_in_recovery() AS in_recovery,
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END AS lag_seconds,
+	(SELECT status FROM pg_stat_wal_receiver) AS receiver_status`
+	case "mysql":
+		return "SHOW REPLICA STATUS"
+	default:
+		return ""
+	}
+}
+
+// StorageQuery returns the query of the storage check: the bytes the
+// database uses as "used_bytes", and on SQL Server one row per volume holding
+// its files, with the volume's "total_bytes" and "available_bytes"
+func StorageQuery(dbType string) string {
+	switch dbType {
+	case "postgres":
+		return "SELECT pg_database_size(current_database()) AS used_bytes"
+	case "mysql":
+		return `SELECT COALESCE(SUM(data_length + index_length + data_free), 0) AS used_bytes
+	FROM information_schema.TABLES WHERE table_schema = DATABASE()`
+	case "mssql":
+		return `SELECT vs.volume_mount_point AS volume,
+	SUM(CAST(f.size AS bigint)) * 8192 AS used_bytes,
+	MAX(vs.total_bytes) AS total_bytes,
+	MAX(vs.available_bytes) AS available_bytes
+	FROM sys.master_files f
+	CROSS APPLY sys.dm_os_volume_stats(f.database_id, f.file_id) vs
+	WHERE f.database_id = DB_ID()
+	GROUP BY vs.volume_mount_point`
+	default:
+		return ""
+	}
+}
+
+// ConnectionsQuery returns the query of the connections check: the open
+// client connections as "connections", the server's "max_connections", and
+// the share in use as "usage_percent"
+func ConnectionsQuery(dbType string) string {
+	var counts string
+	switch dbType {
+	case "postgres":
+		counts = `SELECT count(*) AS connections, current_setting('max_connections')::int AS max_connections
+		FROM pg_stat_activity WHERE backend_type = 'client backend'`
+	case "mysql":
+		counts = `SELECT CAST(VARIABLE_VALUE AS UNSIGNED) AS connections, @@max_connections AS max_connections
+		FROM performance_schema.global_status WHERE VARIABLE_NAME = 'Threads_connected'`
+	case "mssql":
+		counts = `SELECT COUNT(*) AS connections, @@MAX_CONNECTIONS AS max_connections
+		FROM sys.dm_exec_sessions WHERE is_user_process = 1`
+	default:
+		return ""
+	}
+	return `SELECT c.connections, c.max_connections,
+	ROUND(100.0 * c.connections / c.max_connections, 1) AS usage_percent
+	FROM (` + counts + `) c`
+}
+
+// BlockingQuery returns the query of the blocking check: the number of
+// sessions waiting on a lock longer than maxWait as "blocked_sessions", and
+// the longest wait of any blocked session as "longest_wait_seconds".
+// PostgreSQL measures the wait from the start of the blocked statement.
+func BlockingQuery(dbType string, maxWait time.Duration) string {
+	seconds := strconv.FormatFloat(maxWait.Seconds(), 'f', -1, 64)
+	switch dbType {
+	case "postgres":
+		return `SELECT COUNT(*) FILTER (WHERE wait_seconds > ` + seconds + `) AS blocked_sessions,
+	COALESCE(MAX(wait_seconds), 0) AS longest_wait_seconds
+	FROM (SELECT EXTRACT(EPOCH FROM now() - query_start) AS wait_seconds
+		FROM pg_stat_activity WHERE cardinality(pg_blocking_pids(pid)) > 0) w`
+	case "mysql":
+		return `SELECT COUNT(CASE WHEN wait_seconds > ` + seconds + ` THEN 1 END) AS blocked_sessions,
+	COALESCE(MAX(wait_seconds), 0) AS longest_wait_seconds
+	FROM (SELECT TIMESTAMPDIFF(MICROSECOND, trx_wait_started, NOW(6)) / 1000000 AS wait_seconds
+		FROM information_schema.innodb_trx WHERE trx_state = 'LOCK WAIT') w`
+	case "mssql":
+		return `SELECT COUNT(CASE WHEN wait_time > ` + strconv.FormatInt(maxWait.Milliseconds(), 10) + ` THEN 1 END) AS blocked_sessions,
+	COALESCE(MAX(wait_time), 0) / 1000.0 AS longest_wait_seconds
+	FROM sys.dm_exec_requests WHERE blocking_session_id <> 0`
+	default:
+		return ""
+	}
+}
+
+// queryTextLength is how much of a query's text LongQueriesQuery returns
+const queryTextLength = "200"
+
+// LongQueriesQuery returns the query of the long_queries check: one row per
+// query in the current database running longer than maxDuration, longest
+// first, with its "session_id", "duration_seconds" and, when includeText is
+// set, the start of its text as "query"
+func LongQueriesQuery(dbType string, maxDuration time.Duration, includeText bool) string {
+	seconds := strconv.FormatFloat(maxDuration.Seconds(), 'f', -1, 64)
+	switch dbType {
+	case "postgres":
+		text := ""
+		if includeText {
+			text = ", LEFT(query, " + queryTextLength + ") AS query"
+		}
+		return `SELECT pid AS session_id, EXTRACT(EPOCH FROM now() - query_start) AS duration_seconds` + text + `
+	FROM pg_stat_activity
+	WHERE state = 'active' AND datname = current_database() AND pid <> pg_backend_pid()
+		AND now() - query_start > interval '` + seconds + ` seconds'
+	ORDER BY query_start`
+	case "mysql":
+		text := ""
+		if includeText {
+			text = ", LEFT(INFO, " + queryTextLength + ") AS query"
+		}
+		return `SELECT ID AS session_id, TIME AS duration_seconds` + text + `
+	FROM information_schema.PROCESSLIST
+	WHERE COMMAND = 'Query' AND DB = DATABASE() AND ID <> CONNECTION_ID() AND TIME > ` + seconds + `
+	ORDER BY TIME DESC`
+	case "mssql":
+		text, apply := "", ""
+		if includeText {
+			text = ", LEFT(t.text, " + queryTextLength + ") AS query"
+			apply = " OUTER APPLY sys.dm_exec_sql_text(r.sql_handle) t"
+		}
+		return `SELECT r.session_id, r.total_elapsed_time / 1000.0 AS duration_seconds` + text + `
+	FROM sys.dm_exec_requests r
+	JOIN sys.dm_exec_sessions s ON s.session_id = r.session_id` + apply + `
+	WHERE s.is_user_process = 1 AND r.database_id = DB_ID() AND r.session_id <> @@SPID
+		AND r.total_elapsed_time > ` + strconv.FormatInt(maxDuration.Milliseconds(), 10) + `
+	ORDER BY r.total_elapsed_time DESC`
+	default:
+		return ""
+	}
+}
+
+// DeadlocksQuery returns the query of the deadlocks check, selecting the
+// engine's cumulative deadlock counter as "deadlocks": for the current
+// database on PostgreSQL, and for the server on MySQL and SQL Server
+func DeadlocksQuery(dbType string) string {
+	switch dbType {
+	case "postgres":
+		return "SELECT deadlocks FROM pg_stat_database WHERE datname = current_database()"
+	case "mysql":
+		return "SELECT `COUNT` AS deadlocks FROM information_schema.INNODB_METRICS WHERE NAME = 'lock_deadlocks'"
+	case "mssql":
+		return `SELECT cntr_value AS deadlocks FROM sys.dm_os_performance_counters
+	WHERE counter_name = 'Number of Deadlocks/sec' AND instance_name = '_Total'`
+	default:
+		return ""
+	}
+}
+
+// ConnectionQuery returns the query of the connection check, which only
+// proves the database answers queries
+func ConnectionQuery(dbType string) string {
+	return "SELECT 1 AS connected"
+}
+
+// ExplainQuery returns the query showing the execution plan of a query, or ""
+// for database types without one. PostgreSQL and MySQL explain the query
+// without running it; SQL Server returns the cached plan of its latest run as
+// showplan XML, which needs the VIEW SERVER STATE permission.
+func ExplainQuery(dbType, query string) string {
+	switch dbType {
+	case "postgres":
+		return "EXPLAIN " + query
+	case "mysql":
+		return "EXPLAIN FORMAT=TREE " + query
+	case "mssql":
+		return `SELECT TOP 1 CAST(p.query_plan AS nvarchar(max)) AS query_plan
+	FROM sys.dm_exec_query_stats s
+	CROSS APPLY sys.dm_exec_sql_text(s.sql_handle) t
+	CROSS APPLY sys.dm_exec_query_plan(s.plan_handle) p
+	WHERE t.text = N'` + strings.ReplaceAll(query, "'", "''") + `'
+	ORDER BY s.last_execution_time DESC`
+	default:
+		return ""
+	}
+}
+
+// quoteTable quotes a table name, which may be schema qualified
+func quoteTable(dbType, table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(dbType, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteIdentifier quotes an identifier for a database type
+func quoteIdentifier(dbType, name string) string {
+	switch dbType {
+	case "mysql":
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case "mssql":
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	default:
+		return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	}
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -10,11 +12,114 @@ import (
 type HealthResult struct {
 	DatabaseName string                 `json:"database_name"`
 	TableName    string                 `json:"table_name"`
-	Status       string                 `json:"status"`
+	Status       Status                 `json:"status"`
+	Labels       map[string]string      `json:"labels,omitempty"`
 	Data         map[string]interface{} `json:"data,omitempty"`
 	Error        string                 `json:"error,omitempty"`
 	QueryTime    time.Duration          `json:"query_time"`
 	Timestamp    time.Time              `json:"timestamp"`
+
+	// UnhealthySince and Downtime describe the ongoing outage of a failing
+	// check; Downtime is measured up to Timestamp
+	UnhealthySince *time.Time    `json:"unhealthy_since,omitempty"`
+	Downtime       time.Duration `json:"downtime,omitempty"`
+
+	// DisconnectedAt is when the connection to the database of a
+	// disconnected check was lost
+	DisconnectedAt *time.Time `json:"disconnected_at,omitempty"`
+
+	// WarnLatency and CriticalLatency are the query time thresholds above
+	// which the check is degraded or unhealthy, when configured
+	WarnLatency     time.Duration `json:"warn_latency,omitempty"`
+	CriticalLatency time.Duration `json:"critical_latency,omitempty"`
+
+	// Severity is "warning" or "info" for checks whose failures do not make
+	// their database and the overall status unhealthy; empty for critical checks
+	Severity string `json:"severity,omitempty"`
+
+	// Plan is the execution plan of a check slower than its latency
+	// thresholds, for checks that capture plans
+	Plan string `json:"plan,omitempty"`
+
+	// LastRunAt, LastDuration and NextRunAt describe the runs of the
+	// scheduled check behind a cached result, and the counters its outcomes
+	// since the service started
+	LastRunAt           *time.Time    `json:"last_run_at,omitempty"`
+	LastDuration        time.Duration `json:"last_duration,omitempty"`
+	NextRunAt           *time.Time    `json:"next_run_at,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures,omitempty"`
+	RunCount            int64         `json:"run_count,omitempty"`
+	FailureCount        int64         `json:"failure_count,omitempty"`
+
+	// Stale marks a result restored from before the service restarted,
+	// until the check runs again, and a cached result older than its
+	// check's interval, along with its Age
+	Stale bool          `json:"stale,omitempty"`
+	Age   time.Duration `json:"age,omitempty"`
+
+	// UnknownSince is when a cached result passed its check's
+	// max_result_age and became unknown
+	UnknownSince *time.Time `json:"unknown_since,omitempty"`
+
+	// Recent lists the latest results of the scheduled check behind a cached
+	// result, newest first, when history.recent is set
+	Recent []RecentResult `json:"recent,omitempty"`
+}
+
+// RecentResult is a past result of a scheduled check, as listed in Recent
+type RecentResult struct {
+	Status    Status        `json:"status"`
+	QueryTime time.Duration `json:"query_time"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// IsCritical reports whether a failure of the check makes its database and
+// the overall status unhealthy
+func (r *HealthResult) IsCritical() bool {
+	return r.Severity == "" || r.Severity == "critical"
+}
+
+// AggregateStatus returns the status the result contributes to its database
+// and the overall status: failures of warning checks count as degraded and
+// failures of info checks as healthy
+func (r *HealthResult) AggregateStatus() Status {
+	if r.IsCritical() || r.Status.Code() <= StatusDegraded.Code() {
+		return r.Status
+	}
+	if r.Severity == "warning" {
+		return StatusDegraded
+	}
+	return StatusHealthy
+}
+
+// IsServing reports whether a database with the given results is up: it has
+// enabled results and all of them are healthy, degraded, which is slow but
+// up, or in maintenance. Failures of warning and info checks count as up.
+// The gRPC health service and DNS records follow this rule.
+func IsServing(results []*HealthResult) bool {
+	enabled := 0
+	for _, result := range results {
+		if result.Status == StatusDisabled || result.Status == StatusPaused {
+			continue
+		}
+		switch result.AggregateStatus() {
+		case StatusHealthy, StatusDegraded, StatusMaintenance:
+		default:
+			return false
+		}
+		enabled++
+	}
+
+	return enabled > 0
+}
+
+// MarshalJSON adds the numeric status_code of the status to the encoded result
+func (r HealthResult) MarshalJSON() ([]byte, error) {
+	type result HealthResult
+	return json.Marshal(struct {
+		result
+		StatusCode int `json:"status_code"`
+	}{result(r), r.Status.Code()})
 }
 
 // ConnectionInfo holds database connection information
@@ -46,6 +151,14 @@ type Driver interface {
 	GetDriverName() string
 }
 
+// MaxOpenConns is the connection pool ceiling configured on every driver
+const MaxOpenConns = 25
+
+// StatsProvider is implemented by drivers that expose connection pool statistics
+type StatsProvider interface {
+	Stats() sql.DBStats
+}
+
 // Manager manages database connections and health checks
 type Manager struct {
 	drivers map[string]Driver
@@ -31,7 +31,7 @@ func (d *MySQLDriver) Connect(ctx context.Context, info ConnectionInfo) error {
 	}
 
 	// Configure connection pool settings
-	d.db.SetMaxOpenConns(25)
+	d.db.SetMaxOpenConns(MaxOpenConns)
 	d.db.SetMaxIdleConns(5)
 	d.db.SetConnMaxLifetime(5 * time.Minute)
 	d.db.SetConnMaxIdleTime(1 * time.Minute)
@@ -71,6 +71,11 @@ func (d *MySQLDriver) ExecuteHealthCheck(ctx context.Context, query string) (map
 	return d.processRows(rows)
 }
 
+// ExecuteSequence executes the queries of a multi-step check on one connection
+func (d *MySQLDriver) ExecuteSequence(ctx context.Context, queries []string, transaction bool, next func(map[string]interface{}) bool) error {
+	return executeSequence(ctx, d.db, queries, transaction, d.processRows, next)
+}
+
 // Ping tests the database connection
 func (d *MySQLDriver) Ping(ctx context.Context) error {
 	if d.db == nil {
@@ -79,6 +84,14 @@ func (d *MySQLDriver) Ping(ctx context.Context) error {
 	return d.db.PingContext(ctx)
 }
 
+// Stats returns connection pool statistics
+func (d *MySQLDriver) Stats() sql.DBStats {
+	if d.db == nil {
+		return sql.DBStats{}
+	}
+	return d.db.Stats()
+}
+
 // GetDriverName returns the name of the database driver
 func (d *MySQLDriver) GetDriverName() string {
 	return "mysql"
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// SequenceExecutor is implemented by drivers that run multi-step checks
+type SequenceExecutor interface {
+	// ExecuteSequence runs queries in order on a single connection, inside a
+	// transaction that is rolled back when transaction is set. The data of
+	// each query is passed to next, and the sequence stops when it returns
+	// false.
+	ExecuteSequence(ctx context.Context, queries []string, transaction bool, next func(data map[string]interface{}) bool) error
+}
+
+// querier is the query method shared by connections and transactions
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// executeSequence implements ExecuteSequence for the drivers built on
+// database/sql, converting rows with processRows
+func executeSequence(ctx context.Context, db *sql.DB, queries []string, transaction bool,
+	processRows func(*sql.Rows) (map[string]interface{}, error), next func(map[string]interface{}) bool) error {
+	if db == nil {
+		return fmt.Errorf("database connection is not established")
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	// Steps may change session state such as the role, so the connection is
+	// discarded instead of being returned to the pool
+	defer func() {
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		conn.Close()
+	}()
+
+	var q querier = conn
+	if transaction {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+		q = tx
+	}
+
+	for i, query := range queries {
+		rows, err := q.QueryContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("step %d: failed to execute query: %w", i+1, err)
+		}
+		data, err := processRows(rows)
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if !next(data) {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package database
+
+// Status is the outcome of a health check. Its string values are part of the
+// API and are never renamed; new statuses may be added.
+type Status string
+
+const (
+	// StatusHealthy indicates the check query succeeded
+	StatusHealthy Status = "healthy"
+	// StatusDisabled indicates the check is disabled in the configuration
+	StatusDisabled Status = "disabled"
+	// StatusMaintenance indicates the check failed during a maintenance window
+	StatusMaintenance Status = "maintenance"
+	// StatusDegraded indicates the service itself is close to a resource limit,
+	// or a check query was slower than its warning threshold
+	StatusDegraded Status = "degraded"
+	// StatusUnhealthy indicates the check query failed, returned unexpected
+	// data or was slower than its critical threshold
+	StatusUnhealthy Status = "unhealthy"
+	// StatusFlapping indicates the check changes status too often to report
+	// any one of them
+	StatusFlapping Status = "flapping"
+	// StatusError indicates the check could not be run, e.g. no connection
+	StatusError Status = "error"
+	// StatusInternalError indicates the check panicked
+	StatusInternalError Status = "error(internal)"
+	// StatusDisconnected indicates the connection to the check's database was
+	// lost; its last result is withheld until the database reconnects
+	StatusDisconnected Status = "disconnected"
+	// StatusStuck indicates the check ran far past its deadline and was
+	// abandoned, e.g. because the driver ignored the cancellation
+	StatusStuck Status = "stuck"
+	// StatusPaused indicates the check is outside its active hours and not run
+	StatusPaused Status = "paused"
+	// StatusUnknown indicates the cached result of the check is older than
+	// its max_result_age, so its status cannot be told
+	StatusUnknown Status = "unknown"
+)
+
+// statusCodes maps statuses to numeric codes ordered by severity
+var statusCodes = map[Status]int{
+	StatusHealthy:       0,
+	StatusDisabled:      1,
+	StatusMaintenance:   2,
+	StatusDegraded:      3,
+	StatusUnhealthy:     4,
+	StatusFlapping:      4, // as severe as unhealthy; codes of existing statuses never change
+	StatusError:         5,
+	StatusInternalError: 6,
+	StatusDisconnected:  5, // as severe as error
+	StatusPaused:        1, // as severe as disabled
+	StatusStuck:         5, // as severe as error
+	StatusUnknown:       4, // as severe as unhealthy
+}
+
+// Code returns the numeric status code, higher meaning more severe, or -1
+// for an unrecognized status
+func (s Status) Code() int {
+	code, known := statusCodes[s]
+	if !known {
+		return -1
+	}
+	return code
+}
+
+// Summary aggregates the results of checks into the status of their database
+// or of the service
+type Summary struct {
+	Status              Status
+	TotalChecks         int // checks that are neither disabled nor paused
+	HealthyChecks       int
+	DegradedChecks      int
+	MaintenanceChecks   int
+	NonCriticalFailures int             // failures of warning and info checks
+	Failing             []*HealthResult // failures of critical checks
+}
+
+// NewSummary returns the healthy summary of no results
+func NewSummary() *Summary {
+	return &Summary{Status: StatusHealthy}
+}
+
+// Add aggregates results into the summary. A failing critical check makes the
+// status unhealthy; otherwise a critical check whose result is too old makes
+// it unknown, and a slow check or a failing warning check makes it degraded.
+// Disabled and paused checks, failures during maintenance windows and
+// failures of info checks leave it unchanged.
+func (s *Summary) Add(results ...*HealthResult) {
+	for _, result := range results {
+		if result.Status == StatusDisabled || result.Status == StatusPaused {
+			continue
+		}
+
+		s.TotalChecks++
+		switch {
+		case result.Status == StatusHealthy:
+			s.HealthyChecks++
+		case result.Status == StatusMaintenance:
+			s.MaintenanceChecks++
+		case result.Status == StatusDegraded:
+			s.DegradedChecks++
+			s.raise(StatusDegraded)
+		case !result.IsCritical():
+			s.NonCriticalFailures++
+			if result.AggregateStatus() == StatusDegraded {
+				s.raise(StatusDegraded)
+			}
+		case result.Status == StatusUnknown:
+			s.raise(StatusUnknown)
+		default:
+			s.Failing = append(s.Failing, result)
+			s.raise(StatusUnhealthy)
+		}
+	}
+}
+
+// raise sets the status of the summary, unless it is already worse
+func (s *Summary) raise(status Status) {
+	switch {
+	case s.Status == StatusUnhealthy:
+	case s.Status == StatusUnknown && status != StatusUnhealthy:
+	default:
+		s.Status = status
+	}
+}
@@ -0,0 +1,247 @@
+package dnsserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// maxUDPMessageSize is the largest DNS message accepted or sent over UDP
+const maxUDPMessageSize = 512
+
+// Server answers A and TXT queries for configured names while the mapped
+// database or group is healthy. Names whose records are all unhealthy get an
+// empty answer, so resolvers only ever see addresses of healthy backends.
+type Server struct {
+	config        *config.Config
+	healthService *health.Service
+	logger        *slog.Logger
+	records       map[string][]config.DNSRecord
+
+	mu   sync.Mutex
+	conn net.PacketConn
+}
+
+// NewServer creates a new DNS responder instance
+func NewServer(cfg *config.Config, healthService *health.Service, logger *slog.Logger) *Server {
+	records := make(map[string][]config.DNSRecord)
+	for _, record := range cfg.DNS.Records {
+		name := canonicalName(record.Name)
+		records[name] = append(records[name], record)
+	}
+
+	return &Server{
+		config:        cfg,
+		healthService: healthService,
+		logger:        logger,
+		records:       records,
+	}
+}
+
+// Start starts the DNS responder on UDP and blocks until it stops
+func (s *Server) Start() error {
+	conn, err := net.ListenPacket("udp", s.config.DNS.GetAddress())
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.DNS.GetAddress(), err)
+	}
+
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+
+	s.logger.Info("Starting DNS responder",
+		"address", s.config.DNS.GetAddress(),
+		"records", len(s.config.DNS.Records))
+
+	buf := make([]byte, maxUDPMessageSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("failed to read dns query: %w", err)
+		}
+
+		response, err := s.handleQuery(buf[:n], s.healthService.GetAllCachedHealth())
+		if err != nil {
+			s.logger.Debug("Dropping malformed DNS query",
+				"remote", addr.String(),
+				"error", err)
+			continue
+		}
+
+		if _, err := conn.WriteTo(response, addr); err != nil {
+			s.logger.Warn("Failed to write DNS response",
+				"remote", addr.String(),
+				"error", err)
+		}
+	}
+}
+
+// Shutdown stops the DNS responder
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Info("Shutting down DNS responder")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// handleQuery parses a query and builds the packed response from the cached results
+func (s *Server) handleQuery(query []byte, results map[string][]*database.HealthResult) ([]byte, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	if header.Response {
+		return nil, fmt.Errorf("unexpected response message")
+	}
+
+	question, err := parser.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	responseHeader := dnsmessage.Header{
+		ID:                 header.ID,
+		Response:           true,
+		Authoritative:      true,
+		RecursionDesired:   header.RecursionDesired,
+		RecursionAvailable: false,
+	}
+
+	records, known := s.records[canonicalName(question.Name.String())]
+	switch {
+	case header.OpCode != 0:
+		responseHeader.RCode = dnsmessage.RCodeNotImplemented
+	case question.Class != dnsmessage.ClassINET:
+		responseHeader.RCode = dnsmessage.RCodeRefused
+	case !known:
+		responseHeader.RCode = dnsmessage.RCodeNameError
+	}
+
+	response, err := s.buildResponse(responseHeader, question, records, results)
+	if err != nil {
+		return nil, err
+	}
+
+	// A response that does not fit in a UDP message is sent without answers
+	// and with the TC bit set, so resolvers know the answer was cut short
+	if len(response) > maxUDPMessageSize {
+		responseHeader.Truncated = true
+		return s.buildResponse(responseHeader, question, nil, nil)
+	}
+	return response, nil
+}
+
+// buildResponse packs a response to the question with the answers of records
+func (s *Server) buildResponse(header dnsmessage.Header, question dnsmessage.Question, records []config.DNSRecord, results map[string][]*database.HealthResult) ([]byte, error) {
+	builder := dnsmessage.NewBuilder(make([]byte, 0, maxUDPMessageSize), header)
+	builder.EnableCompression()
+	if err := builder.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := builder.Question(question); err != nil {
+		return nil, err
+	}
+	if err := builder.StartAnswers(); err != nil {
+		return nil, err
+	}
+
+	if header.RCode == dnsmessage.RCodeSuccess {
+		if err := s.buildAnswers(&builder, question, records, results); err != nil {
+			return nil, err
+		}
+	}
+
+	return builder.Finish()
+}
+
+// buildAnswers adds the A or TXT answers of every record whose database or group is healthy
+func (s *Server) buildAnswers(builder *dnsmessage.Builder, question dnsmessage.Question, records []config.DNSRecord, results map[string][]*database.HealthResult) error {
+	resourceHeader := dnsmessage.ResourceHeader{
+		Name:  question.Name,
+		Class: dnsmessage.ClassINET,
+		TTL:   s.config.DNS.GetTTL(),
+	}
+
+	for _, record := range records {
+		if !isHealthy(s.recordResults(record, results)) {
+			continue
+		}
+
+		switch question.Type {
+		case dnsmessage.TypeA:
+			for _, address := range record.Addresses {
+				var a dnsmessage.AResource
+				copy(a.A[:], net.ParseIP(address).To4())
+				if err := builder.AResource(resourceHeader, a); err != nil {
+					return err
+				}
+			}
+		case dnsmessage.TypeTXT:
+			if len(record.TXT) == 0 {
+				continue
+			}
+			if err := builder.TXTResource(resourceHeader, dnsmessage.TXTResource{TXT: record.TXT}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordResults returns the cached results that decide whether a record is
+// served: those of its database, or those of every check in its group
+func (s *Server) recordResults(record config.DNSRecord, results map[string][]*database.HealthResult) []*database.HealthResult {
+	if record.Group == "" {
+		return results[record.Database]
+	}
+
+	group := s.config.GetGroup(record.Group)
+	if group == nil {
+		return nil
+	}
+
+	var groupResults []*database.HealthResult
+	for databaseName, dbResults := range results {
+		for _, result := range dbResults {
+			if group.Contains(databaseName, result.Labels) {
+				groupResults = append(groupResults, result)
+			}
+		}
+	}
+	return groupResults
+}
+
+// isHealthy reports whether the records of a database are served, by the
+// rule of the gRPC health service
+func isHealthy(results []*database.HealthResult) bool {
+	return database.IsServing(results)
+}
+
+// canonicalName lower-cases a DNS name and makes it fully qualified
+func canonicalName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
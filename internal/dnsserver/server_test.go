@@ -0,0 +1,282 @@
+package dnsserver
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestIsHealthy(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []*database.HealthResult
+		expected bool
+	}{
+		{
+			name:     "no results yet",
+			results:  nil,
+			expected: false,
+		},
+		{
+			name: "all healthy",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "healthy"},
+			},
+			expected: true,
+		},
+		{
+			name: "one unhealthy",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "unhealthy"},
+			},
+			expected: false,
+		},
+		{
+			name: "slow but up",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "degraded"},
+			},
+			expected: true,
+		},
+		{
+			name: "failure during maintenance",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "maintenance"},
+			},
+			expected: true,
+		},
+		{
+			name: "failing info check",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "reports", Status: "error", Severity: "info"},
+			},
+			expected: true,
+		},
+		{
+			name: "disabled tables ignored",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "disabled"},
+			},
+			expected: true,
+		},
+		{
+			name: "all disabled",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "disabled"},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if healthy := isHealthy(tt.results); healthy != tt.expected {
+				t.Errorf("isHealthy() = %v; expected %v", healthy, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandleQuery(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "primary"}},
+		DNS: config.DNS{
+			Enabled: true,
+			Records: []config.DNSRecord{
+				{Name: "db.example.internal", Database: "primary", Addresses: []string{"10.0.0.5"}},
+			},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	tests := []struct {
+		name     string
+		query    string
+		expected dnsmessage.RCode
+	}{
+		{
+			name:     "configured name without healthy results",
+			query:    "DB.example.internal.",
+			expected: dnsmessage.RCodeSuccess,
+		},
+		{
+			name:     "unknown name",
+			query:    "other.example.internal.",
+			expected: dnsmessage.RCodeNameError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := dnsmessage.Message{
+				Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+				Questions: []dnsmessage.Question{{
+					Name:  dnsmessage.MustNewName(tt.query),
+					Type:  dnsmessage.TypeA,
+					Class: dnsmessage.ClassINET,
+				}},
+			}
+			packed, err := query.Pack()
+			if err != nil {
+				t.Fatalf("Failed to pack query: %v", err)
+			}
+
+			response, err := server.handleQuery(packed, nil)
+			if err != nil {
+				t.Fatalf("handleQuery failed: %v", err)
+			}
+
+			var message dnsmessage.Message
+			if err := message.Unpack(response); err != nil {
+				t.Fatalf("Failed to unpack response: %v", err)
+			}
+
+			if message.Header.ID != 42 || !message.Header.Response {
+				t.Errorf("Unexpected response header: %+v", message.Header)
+			}
+			if message.Header.RCode != tt.expected {
+				t.Errorf("RCode = %v; expected %v", message.Header.RCode, tt.expected)
+			}
+			if len(message.Answers) != 0 {
+				t.Errorf("Expected no answers, got %d", len(message.Answers))
+			}
+		})
+	}
+}
+
+func TestHandleQueryGroup(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "primary"}, {Name: "replica"}},
+		Groups:    []config.Group{{Name: "checkout", Labels: map[string]string{"service": "checkout"}}},
+		DNS: config.DNS{
+			Enabled: true,
+			Records: []config.DNSRecord{
+				{Name: "checkout.example.internal", Group: "checkout", Addresses: []string{"10.0.0.7"}},
+			},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	checkout := map[string]string{"service": "checkout"}
+
+	tests := []struct {
+		name     string
+		results  map[string][]*database.HealthResult
+		expected int
+	}{
+		{
+			name: "every group check healthy",
+			results: map[string][]*database.HealthResult{
+				"primary": {{DatabaseName: "primary", TableName: "orders", Status: "healthy", Labels: checkout}},
+				"replica": {{DatabaseName: "replica", TableName: "orders", Status: "healthy", Labels: checkout}},
+			},
+			expected: 1,
+		},
+		{
+			name: "check outside the group unhealthy",
+			results: map[string][]*database.HealthResult{
+				"primary": {
+					{DatabaseName: "primary", TableName: "orders", Status: "healthy", Labels: checkout},
+					{DatabaseName: "primary", TableName: "reports", Status: "unhealthy"},
+				},
+			},
+			expected: 1,
+		},
+		{
+			name: "group check unhealthy",
+			results: map[string][]*database.HealthResult{
+				"primary": {{DatabaseName: "primary", TableName: "orders", Status: "healthy", Labels: checkout}},
+				"replica": {{DatabaseName: "replica", TableName: "orders", Status: "unhealthy", Labels: checkout}},
+			},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := query(t, server, "checkout.example.internal.", tt.results)
+			if len(message.Answers) != tt.expected {
+				t.Errorf("len(Answers) = %d; expected %d", len(message.Answers), tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandleQueryTruncated(t *testing.T) {
+	var addresses []string
+	for i := 1; i <= 40; i++ {
+		addresses = append(addresses, fmt.Sprintf("10.0.0.%d", i))
+	}
+
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "primary"}},
+		DNS: config.DNS{
+			Enabled: true,
+			Records: []config.DNSRecord{
+				{Name: "db.example.internal", Database: "primary", Addresses: addresses[:10]},
+				{Name: "pool.example.internal", Database: "primary", Addresses: addresses},
+			},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	results := map[string][]*database.HealthResult{
+		"primary": {{DatabaseName: "primary", TableName: "users", Status: "healthy"}},
+	}
+
+	message := query(t, server, "db.example.internal.", results)
+	if message.Header.Truncated || len(message.Answers) != 10 {
+		t.Errorf("Truncated = %v with %d answers; expected false with 10", message.Header.Truncated, len(message.Answers))
+	}
+
+	message = query(t, server, "pool.example.internal.", results)
+	if !message.Header.Truncated || len(message.Answers) != 0 {
+		t.Errorf("Truncated = %v with %d answers; expected true with 0", message.Header.Truncated, len(message.Answers))
+	}
+}
+
+// query sends an A query for name to the server and unpacks the response
+func query(t *testing.T, server *Server, name string, results map[string][]*database.HealthResult) dnsmessage.Message {
+	t.Helper()
+
+	request := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	packed, err := request.Pack()
+	if err != nil {
+		t.Fatalf("Failed to pack query: %v", err)
+	}
+
+	response, err := server.handleQuery(packed, results)
+	if err != nil {
+		t.Fatalf("handleQuery failed: %v", err)
+	}
+	if len(response) > maxUDPMessageSize {
+		t.Errorf("Response of %d bytes exceeds %d", len(response), maxUDPMessageSize)
+	}
+
+	var message dnsmessage.Message
+	if err := message.Unpack(response); err != nil {
+		t.Fatalf("Failed to unpack response: %v", err)
+	}
+	return message
+}
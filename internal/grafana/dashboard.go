@@ -0,0 +1,425 @@
+// Package grafana generates Grafana dashboards of the checks of a
+// configuration, from the metrics gsqlhealth publishes on /metrics.
+package grafana
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+const (
+	// schemaVersion is the Grafana dashboard schema the panels are written in
+	schemaVersion = 39
+	// gridWidth is the width of the Grafana dashboard grid
+	gridWidth = 24
+	// datasourceVariable is the template variable selecting the Prometheus
+	// data source, chosen on import
+	datasourceVariable = "datasource"
+)
+
+// statusColors are the colors of the status codes of gsqlhealth_check_status;
+// statuses sharing a code are shown by the first of them
+var statusColors = []struct {
+	status database.Status
+	color  string
+}{
+	{database.StatusHealthy, "green"},
+	{database.StatusDisabled, "text"},
+	{database.StatusMaintenance, "blue"},
+	{database.StatusDegraded, "yellow"},
+	{database.StatusUnhealthy, "red"},
+	{database.StatusError, "dark-red"},
+	{database.StatusInternalError, "purple"},
+}
+
+// Options are the options of a generated dashboard
+type Options struct {
+	Title string
+	UID   string
+}
+
+// Dashboard is a Grafana dashboard, in the JSON model of the dashboard API
+// and of dashboard imports
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	Editable      bool       `json:"editable"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Refresh       string     `json:"refresh"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+// TimeRange is the default time range of a dashboard
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Templating holds the template variables of a dashboard
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a template variable
+type Variable struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+// Panel is a dashboard panel, or a row grouping the panels below it
+type Panel struct {
+	ID          int                    `json:"id"`
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	GridPos     GridPos                `json:"gridPos"`
+	Collapsed   *bool                  `json:"collapsed,omitempty"`
+	Datasource  *DatasourceRef         `json:"datasource,omitempty"`
+	Targets     []Target               `json:"targets,omitempty"`
+	FieldConfig *FieldConfig           `json:"fieldConfig,omitempty"`
+	Options     map[string]interface{} `json:"options,omitempty"`
+}
+
+// GridPos is the position and size of a panel on the dashboard grid
+type GridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// DatasourceRef references the data source of a panel
+type DatasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Target is a query of a panel
+type Target struct {
+	RefID        string         `json:"refId"`
+	Expr         string         `json:"expr"`
+	LegendFormat string         `json:"legendFormat,omitempty"`
+	Datasource   *DatasourceRef `json:"datasource"`
+}
+
+// FieldConfig configures the display of the values of a panel
+type FieldConfig struct {
+	Defaults  FieldDefaults `json:"defaults"`
+	Overrides []interface{} `json:"overrides"`
+}
+
+// FieldDefaults are the display settings of all fields of a panel
+type FieldDefaults struct {
+	Unit       string         `json:"unit,omitempty"`
+	Decimals   *int           `json:"decimals,omitempty"`
+	Color      *FieldColor    `json:"color,omitempty"`
+	Mappings   []ValueMapping `json:"mappings,omitempty"`
+	Thresholds *Thresholds    `json:"thresholds,omitempty"`
+}
+
+// FieldColor selects how values are colored
+type FieldColor struct {
+	Mode string `json:"mode"`
+}
+
+// ValueMapping maps values to texts and colors
+type ValueMapping struct {
+	Type    string                        `json:"type"`
+	Options map[string]ValueMappingResult `json:"options"`
+}
+
+// ValueMappingResult is the text and color a value is mapped to
+type ValueMappingResult struct {
+	Text  string `json:"text"`
+	Color string `json:"color"`
+	Index int    `json:"index"`
+}
+
+// Thresholds colors values by the steps they reach
+type Thresholds struct {
+	Mode  string          `json:"mode"`
+	Steps []ThresholdStep `json:"steps"`
+}
+
+// ThresholdStep is the color of values from Value up; the first step has no
+// value and colors everything below the second
+type ThresholdStep struct {
+	Color string   `json:"color"`
+	Value *float64 `json:"value"`
+}
+
+// layout places panels on the dashboard grid left to right, wrapping at the
+// grid width
+type layout struct {
+	panels    []Panel
+	x, y      int
+	rowHeight int
+}
+
+// Generate returns the dashboard of the enabled checks of a configuration: a
+// row per database with the status of its checks, their availability,
+// incidents, MTTR and query time when history.sla_metrics_window publishes
+// them, and the result columns of checks with metrics
+func Generate(cfg *config.Config, opts Options) *Dashboard {
+	l := &layout{}
+	sla := cfg.History.SLAMetricsWindow > 0
+	window := windowName(time.Duration(cfg.History.SLAMetricsWindow))
+
+	for i := range cfg.Databases {
+		dbConfig := &cfg.Databases[i]
+		if !dbConfig.IsEnabled() {
+			continue
+		}
+		var tables []config.Table
+		for _, table := range dbConfig.Tables {
+			if table.IsEnabled() {
+				tables = append(tables, table)
+			}
+		}
+		if len(tables) == 0 {
+			continue
+		}
+
+		l.row(dbConfig.Name)
+		selector := fmt.Sprintf("{database=%q}", dbConfig.Name)
+		l.add(statusPanel("stat", "Status", "gsqlhealth_check_status"+selector), 12, 6)
+		l.add(statusPanel("state-timeline", "Status history", "gsqlhealth_check_status"+selector), 12, 6)
+
+		if sla {
+			availability := statPanel("Availability ("+window+")", "gsqlhealth_check_availability_percent"+selector, "percent", thresholds("red", 99, "yellow", 99.9, "green"))
+			availability.FieldConfig.Defaults.Decimals = intPtr(2)
+			l.add(availability, 8, 6)
+			l.add(statPanel("Incidents ("+window+")", "gsqlhealth_check_incidents"+selector, "none", thresholds("green", 1, "red")), 8, 6)
+			l.add(statPanel("MTTR ("+window+")", "gsqlhealth_check_mttr_seconds"+selector, "s", thresholds("green")), 8, 6)
+			for _, quantile := range []string{"0.5", "0.95", "0.99"} {
+				expr := fmt.Sprintf("gsqlhealth_check_query_time_seconds{database=%q,quantile=%q}", dbConfig.Name, quantile)
+				l.add(timeSeriesPanel("Query time (p"+quantileName(quantile)+")", "s", Target{Expr: expr, LegendFormat: "{{table}}"}), 8, 8)
+			}
+		}
+
+		for _, table := range tables {
+			for _, metric := range metricNames(table.Metrics) {
+				expr := fmt.Sprintf("%s{database=%q,table=%q}", metric, dbConfig.Name, table.Name)
+				l.add(timeSeriesPanel(table.Name+": "+metric, "short", Target{Expr: expr}), 12, 8)
+			}
+		}
+	}
+
+	return &Dashboard{
+		UID:           opts.UID,
+		Title:         opts.Title,
+		Tags:          []string{"gsqlhealth"},
+		Timezone:      "browser",
+		Editable:      true,
+		SchemaVersion: schemaVersion,
+		Refresh:       "1m",
+		Time:          TimeRange{From: "now-6h", To: "now"},
+		Templating: Templating{List: []Variable{{
+			Name:  datasourceVariable,
+			Label: "Data source",
+			Type:  "datasource",
+			Query: "prometheus",
+		}}},
+		Panels: l.panels,
+	}
+}
+
+// row starts a row of panels below the current ones
+func (l *layout) row(title string) {
+	l.newline()
+	l.panels = append(l.panels, Panel{
+		ID:        len(l.panels) + 1,
+		Type:      "row",
+		Title:     title,
+		GridPos:   GridPos{X: 0, Y: l.y, W: gridWidth, H: 1},
+		Collapsed: boolPtr(false),
+	})
+	l.y++
+}
+
+// add places a panel of a width and height after the current ones
+func (l *layout) add(panel Panel, w, h int) {
+	if l.x+w > gridWidth {
+		l.newline()
+	}
+	panel.ID = len(l.panels) + 1
+	panel.GridPos = GridPos{X: l.x, Y: l.y, W: w, H: h}
+	l.panels = append(l.panels, panel)
+	l.x += w
+	l.rowHeight = max(l.rowHeight, h)
+}
+
+// newline moves below the current line of panels
+func (l *layout) newline() {
+	if l.x > 0 {
+		l.y += l.rowHeight
+	}
+	l.x, l.rowHeight = 0, 0
+}
+
+// statusPanel returns a panel showing the status of checks, by name and color
+func statusPanel(panelType, title, expr string) Panel {
+	mapping := ValueMapping{Type: "value", Options: make(map[string]ValueMappingResult)}
+	for i, status := range statusColors {
+		code := strconv.Itoa(status.status.Code())
+		if _, ok := mapping.Options[code]; !ok {
+			mapping.Options[code] = ValueMappingResult{Text: string(status.status), Color: status.color, Index: i}
+		}
+	}
+
+	panel := Panel{
+		Type:       panelType,
+		Title:      title,
+		Datasource: datasource(),
+		Targets:    []Target{{RefID: "A", Expr: expr, LegendFormat: "{{table}}", Datasource: datasource()}},
+		FieldConfig: &FieldConfig{
+			Defaults: FieldDefaults{
+				Color:      &FieldColor{Mode: "thresholds"},
+				Mappings:   []ValueMapping{mapping},
+				Thresholds: thresholds("green", 3, "yellow", 4, "red"),
+			},
+			Overrides: []interface{}{},
+		},
+	}
+	if panelType == "stat" {
+		panel.Options = map[string]interface{}{
+			"colorMode":     "background",
+			"graphMode":     "none",
+			"textMode":      "value_and_name",
+			"reduceOptions": map[string]interface{}{"calcs": []string{"lastNotNull"}, "fields": "", "values": false},
+		}
+	} else {
+		panel.Options = map[string]interface{}{
+			"showValue":   "never",
+			"mergeValues": true,
+		}
+	}
+	return panel
+}
+
+// statPanel returns a panel showing the latest value of each check
+func statPanel(title, expr, unit string, steps *Thresholds) Panel {
+	return Panel{
+		Type:       "stat",
+		Title:      title,
+		Datasource: datasource(),
+		Targets:    []Target{{RefID: "A", Expr: expr, LegendFormat: "{{table}}", Datasource: datasource()}},
+		FieldConfig: &FieldConfig{
+			Defaults: FieldDefaults{
+				Unit:       unit,
+				Color:      &FieldColor{Mode: "thresholds"},
+				Thresholds: steps,
+			},
+			Overrides: []interface{}{},
+		},
+		Options: map[string]interface{}{
+			"colorMode":     "value",
+			"graphMode":     "area",
+			"textMode":      "value_and_name",
+			"reduceOptions": map[string]interface{}{"calcs": []string{"lastNotNull"}, "fields": "", "values": false},
+		},
+	}
+}
+
+// timeSeriesPanel returns a graph of a query
+func timeSeriesPanel(title, unit string, target Target) Panel {
+	target.RefID = "A"
+	target.Datasource = datasource()
+	return Panel{
+		Type:       "timeseries",
+		Title:      title,
+		Datasource: datasource(),
+		Targets:    []Target{target},
+		FieldConfig: &FieldConfig{
+			Defaults: FieldDefaults{
+				Unit:  unit,
+				Color: &FieldColor{Mode: "palette-classic"},
+			},
+			Overrides: []interface{}{},
+		},
+		Options: map[string]interface{}{
+			"legend":  map[string]interface{}{"displayMode": "list", "placement": "bottom", "showLegend": true},
+			"tooltip": map[string]interface{}{"mode": "multi", "sort": "desc"},
+		},
+	}
+}
+
+// thresholds returns threshold steps from alternating colors and values,
+// starting with the color of the lowest values
+func thresholds(colorsAndValues ...interface{}) *Thresholds {
+	t := &Thresholds{Mode: "absolute"}
+	var value *float64
+	for _, item := range colorsAndValues {
+		switch v := item.(type) {
+		case string:
+			t.Steps = append(t.Steps, ThresholdStep{Color: v, Value: value})
+		case int:
+			value = floatPtr(float64(v))
+		case float64:
+			value = floatPtr(v)
+		}
+	}
+	return t
+}
+
+// metricNames returns the metric names of a check's result columns, sorted
+// and without duplicates
+func metricNames(metrics map[string]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range metrics {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// quantileName returns the percentile of a quantile label, e.g. 95 for 0.95
+func quantileName(quantile string) string {
+	q, _ := strconv.ParseFloat(quantile, 64)
+	return strconv.FormatFloat(q*100, 'g', -1, 64)
+}
+
+// windowName returns a duration without zero minutes and seconds, e.g. 24h
+// rather than 24h0m0s
+func windowName(d time.Duration) string {
+	name := d.String()
+	if strings.HasSuffix(name, "m0s") {
+		name = strings.TrimSuffix(name, "0s")
+	}
+	if strings.HasSuffix(name, "h0m") {
+		name = strings.TrimSuffix(name, "0m")
+	}
+	return name
+}
+
+// datasource references the data source chosen by the template variable
+func datasource() *DatasourceRef {
+	return &DatasourceRef{Type: "prometheus", UID: "${" + datasourceVariable + "}"}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
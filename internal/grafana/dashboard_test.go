@@ -0,0 +1,76 @@
+package grafana
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestGenerate(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Databases: []config.Database{
+			{Name: "app", Tables: []config.Table{
+				{Name: "orders", Metrics: map[string]string{"count": "gsqlhealth_orders_count", "total": "gsqlhealth_orders_total"}},
+				{Name: "archive", Enabled: &disabled},
+			}},
+			{Name: "legacy", Enabled: &disabled, Tables: []config.Table{{Name: "users"}}},
+		},
+		History: config.History{SLAMetricsWindow: config.Duration(24 * time.Hour)},
+	}
+
+	dashboard := Generate(cfg, Options{Title: "Databases", UID: "db-health"})
+	if dashboard.Title != "Databases" || dashboard.UID != "db-health" || dashboard.Templating.List[0].Query != "prometheus" {
+		t.Errorf("dashboard = %+v; expected the options and a Prometheus data source variable", dashboard)
+	}
+
+	expected := []struct {
+		title   string
+		expr    string
+		gridPos GridPos
+	}{
+		{"app", "", GridPos{X: 0, Y: 0, W: 24, H: 1}},
+		{"Status", `gsqlhealth_check_status{database="app"}`, GridPos{X: 0, Y: 1, W: 12, H: 6}},
+		{"Status history", `gsqlhealth_check_status{database="app"}`, GridPos{X: 12, Y: 1, W: 12, H: 6}},
+		{"Availability (24h)", `gsqlhealth_check_availability_percent{database="app"}`, GridPos{X: 0, Y: 7, W: 8, H: 6}},
+		{"Incidents (24h)", `gsqlhealth_check_incidents{database="app"}`, GridPos{X: 8, Y: 7, W: 8, H: 6}},
+		{"MTTR (24h)", `gsqlhealth_check_mttr_seconds{database="app"}`, GridPos{X: 16, Y: 7, W: 8, H: 6}},
+		{"Query time (p50)", `gsqlhealth_check_query_time_seconds{database="app",quantile="0.5"}`, GridPos{X: 0, Y: 13, W: 8, H: 8}},
+		{"Query time (p95)", `gsqlhealth_check_query_time_seconds{database="app",quantile="0.95"}`, GridPos{X: 8, Y: 13, W: 8, H: 8}},
+		{"Query time (p99)", `gsqlhealth_check_query_time_seconds{database="app",quantile="0.99"}`, GridPos{X: 16, Y: 13, W: 8, H: 8}},
+		{"orders: gsqlhealth_orders_count", `gsqlhealth_orders_count{database="app",table="orders"}`, GridPos{X: 0, Y: 21, W: 12, H: 8}},
+		{"orders: gsqlhealth_orders_total", `gsqlhealth_orders_total{database="app",table="orders"}`, GridPos{X: 12, Y: 21, W: 12, H: 8}},
+	}
+	if len(dashboard.Panels) != len(expected) {
+		t.Fatalf("got %d panels; expected %d", len(dashboard.Panels), len(expected))
+	}
+	for i, panel := range dashboard.Panels {
+		expr := ""
+		if len(panel.Targets) > 0 {
+			expr = panel.Targets[0].Expr
+		}
+		if panel.ID != i+1 || panel.Title != expected[i].title || expr != expected[i].expr || panel.GridPos != expected[i].gridPos {
+			t.Errorf("panel %d = %d %q %q %+v; expected %q %q %+v", i, panel.ID, panel.Title, expr, panel.GridPos, expected[i].title, expected[i].expr, expected[i].gridPos)
+		}
+	}
+
+	mapping := dashboard.Panels[1].FieldConfig.Defaults.Mappings[0].Options
+	if mapping["0"].Text != "healthy" || mapping["4"].Text != "unhealthy" || mapping["5"].Text != "error" || len(mapping) != 7 {
+		t.Errorf("status mappings = %+v; expected a text per status code", mapping)
+	}
+
+	if _, err := json.Marshal(dashboard); err != nil {
+		t.Errorf("Failed to encode dashboard: %v", err)
+	}
+}
+
+func TestGenerateWithoutSLAMetrics(t *testing.T) {
+	cfg := &config.Config{Databases: []config.Database{{Name: "app", Tables: []config.Table{{Name: "orders"}}}}}
+
+	dashboard := Generate(cfg, Options{Title: "gsqlhealth", UID: "gsqlhealth"})
+	if len(dashboard.Panels) != 3 {
+		t.Errorf("got %d panels; expected the row and status panels only", len(dashboard.Panels))
+	}
+}
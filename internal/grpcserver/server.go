@@ -0,0 +1,137 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/grpcserver/gsqlhealthpb"
+	"gsqlhealth/internal/health"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// statusUpdateInterval is how often serving statuses are refreshed from the cache
+const statusUpdateInterval = 5 * time.Second
+
+// Server exposes database health over the standard grpc.health.v1 protocol
+// and the gsqlhealth.v1 service. For grpc.health.v1 the empty service name
+// reports the overall status and every configured database is registered as
+// its own service name.
+type Server struct {
+	config        *config.Config
+	healthService *health.Service
+	logger        *slog.Logger
+	grpcServer    *grpc.Server
+	healthServer  *grpchealth.Server
+	done          chan struct{} // closed on shutdown to end Watch streams and status updates
+}
+
+// NewServer creates a new gRPC server instance
+func NewServer(cfg *config.Config, healthService *health.Service, logger *slog.Logger) *Server {
+	return &Server{
+		config:        cfg,
+		healthService: healthService,
+		logger:        logger,
+		grpcServer:    grpc.NewServer(),
+		healthServer:  grpchealth.NewServer(),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts the gRPC server and blocks until it stops
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.config.GRPC.GetAddress())
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.GRPC.GetAddress(), err)
+	}
+
+	healthpb.RegisterHealthServer(s.grpcServer, s.healthServer)
+	gsqlhealthpb.RegisterGSQLHealthServer(s.grpcServer, &gsqlHealthService{server: s})
+
+	// Publish initial statuses before accepting requests. The updates end
+	// with done, which exists from NewServer on, so a Shutdown racing with
+	// Start still stops them.
+	s.updateStatuses()
+	go s.runStatusUpdates()
+
+	s.logger.Info("Starting gRPC server",
+		"address", s.config.GRPC.GetAddress())
+
+	return s.grpcServer.Serve(listener)
+}
+
+// Shutdown gracefully stops the gRPC server, forcing it closed if the context expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Info("Shutting down gRPC server")
+
+	// Let watchers know the service is going away
+	s.healthServer.Shutdown()
+	close(s.done)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// runStatusUpdates periodically refreshes serving statuses from cached
+// results until the server shuts down
+func (s *Server) runStatusUpdates() {
+	ticker := time.NewTicker(statusUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.updateStatuses()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// updateStatuses maps cached health results onto gRPC serving statuses
+func (s *Server) updateStatuses() {
+	results := s.healthService.GetAllCachedHealth()
+	overall := healthpb.HealthCheckResponse_SERVING
+
+	for _, databaseName := range s.healthService.GetDatabaseNames() {
+		status := servingStatus(results[databaseName])
+		s.healthServer.SetServingStatus(databaseName, status)
+
+		// Disabled databases never take the overall status down
+		if !s.healthService.IsDatabaseEnabled(databaseName) {
+			continue
+		}
+
+		if status != healthpb.HealthCheckResponse_SERVING {
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	s.healthServer.SetServingStatus("", overall)
+}
+
+// servingStatus returns SERVING only when a database has enabled results and all of them are healthy, degraded or in maintenance
+func servingStatus(results []*database.HealthResult) healthpb.HealthCheckResponse_ServingStatus {
+	if !database.IsServing(results) {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,231 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/grpcserver/gsqlhealthpb"
+	"gsqlhealth/internal/health"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestServingStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []*database.HealthResult
+		expected healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name:     "no results yet",
+			results:  nil,
+			expected: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name: "all healthy",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "healthy"},
+			},
+			expected: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "one unhealthy",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "unhealthy"},
+			},
+			expected: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name: "disabled tables ignored",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "disabled"},
+			},
+			expected: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "slow but up",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "degraded"},
+			},
+			expected: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "failing warning check",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "reports", Status: "unhealthy", Severity: "warning"},
+			},
+			expected: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "failure during maintenance",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "healthy"},
+				{TableName: "orders", Status: "maintenance"},
+			},
+			expected: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name: "all disabled",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "disabled"},
+			},
+			expected: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name: "error result",
+			results: []*database.HealthResult{
+				{TableName: "users", Status: "error"},
+			},
+			expected: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := servingStatus(tt.results); status != tt.expected {
+				t.Errorf("servingStatus() = %v; expected %v", status, tt.expected)
+			}
+		})
+	}
+}
+
+func newTestService() *gsqlHealthService {
+	cfg := &config.Config{
+		Databases: []config.Database{{
+			Name:   "db",
+			Labels: map[string]string{"team": "payments"},
+			Tables: []config.Table{{Name: "users"}, {Name: "orders"}},
+		}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return &gsqlHealthService{server: NewServer(cfg, health.NewService(cfg, logger), logger)}
+}
+
+func TestCheckHealthTargets(t *testing.T) {
+	service := newTestService()
+
+	tests := []struct {
+		name         string
+		request      *gsqlhealthpb.CheckHealthRequest
+		expectedCode codes.Code
+		expectedLen  int
+	}{
+		{"all databases", &gsqlhealthpb.CheckHealthRequest{}, codes.OK, 0},
+		{"one table", &gsqlhealthpb.CheckHealthRequest{Database: "db", Table: "users"}, codes.OK, 1},
+		{"table without database", &gsqlhealthpb.CheckHealthRequest{Table: "users"}, codes.InvalidArgument, 0},
+		{"unknown database", &gsqlhealthpb.CheckHealthRequest{Database: "missing"}, codes.NotFound, 0},
+		{"unknown table", &gsqlhealthpb.CheckHealthRequest{Database: "db", Table: "missing"}, codes.NotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := service.CheckHealth(context.Background(), tt.request)
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Fatalf("CheckHealth() code = %v; expected %v (error: %v)", code, tt.expectedCode, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(response.GetResults()) != tt.expectedLen {
+				t.Errorf("len(Results) = %d; expected %d", len(response.GetResults()), tt.expectedLen)
+			}
+		})
+	}
+}
+
+func TestOverallStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []*database.HealthResult
+		expected database.Status
+	}{
+		{"healthy", []*database.HealthResult{{Status: "healthy"}, {Status: "maintenance"}}, database.StatusHealthy},
+		{"degraded", []*database.HealthResult{{Status: "healthy"}, {Status: "unhealthy", Severity: "warning"}}, database.StatusDegraded},
+		{"unknown", []*database.HealthResult{{Status: "degraded"}, {Status: "unknown"}}, database.StatusUnknown},
+		{"unknown info check", []*database.HealthResult{{Status: "healthy"}, {Status: "unknown", Severity: "info"}}, database.StatusHealthy},
+		{"unhealthy", []*database.HealthResult{{Status: "error"}, {Status: "unknown"}}, database.StatusUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := overallStatus(tt.results); status != tt.expected {
+				t.Errorf("overallStatus() = %s; expected %s", status, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListDatabases(t *testing.T) {
+	response, err := newTestService().ListDatabases(context.Background(), &gsqlhealthpb.ListDatabasesRequest{})
+	if err != nil {
+		t.Fatalf("ListDatabases() failed: %v", err)
+	}
+
+	if len(response.GetDatabases()) != 1 {
+		t.Fatalf("len(Databases) = %d; expected 1", len(response.GetDatabases()))
+	}
+	db := response.GetDatabases()[0]
+	if db.GetName() != "db" || !db.GetEnabled() || len(db.GetTables()) != 2 || db.GetLabels()["team"] != "payments" {
+		t.Errorf("unexpected database: %v", db)
+	}
+}
+
+func TestToProtoResult(t *testing.T) {
+	timestamp := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	since := timestamp.Add(-time.Minute)
+
+	converted := toProtoResult(&database.HealthResult{
+		DatabaseName:   "db",
+		TableName:      "users",
+		Status:         database.StatusUnhealthy,
+		Data:           map[string]interface{}{"count": int64(3), "checked_at": timestamp},
+		Timestamp:      timestamp,
+		UnhealthySince: &since,
+		Downtime:       time.Minute,
+	})
+
+	if converted.GetStatusCode() != int32(database.StatusUnhealthy.Code()) {
+		t.Errorf("StatusCode = %d; expected %d", converted.GetStatusCode(), database.StatusUnhealthy.Code())
+	}
+	if count := converted.GetData().GetFields()["count"].GetNumberValue(); count != 3 {
+		t.Errorf("Data[count] = %v; expected 3", count)
+	}
+	if checkedAt := converted.GetData().GetFields()["checked_at"].GetStringValue(); checkedAt == "" {
+		t.Errorf("Expected unsupported data values to be converted to strings")
+	}
+	if converted.GetDowntime().AsDuration() != time.Minute {
+		t.Errorf("Downtime = %v; expected %v", converted.GetDowntime().AsDuration(), time.Minute)
+	}
+}
+
+func TestShutdownStopsStatusUpdates(t *testing.T) {
+	server := newTestService().server
+
+	// Shutdown may run before Start has started the status updates
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() failed: %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.runStatusUpdates()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("runStatusUpdates() kept running after Shutdown")
+	}
+}
@@ -0,0 +1,280 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/grpcserver/gsqlhealthpb"
+	"gsqlhealth/internal/health"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchPollInterval is how often Watch streams look for new cached results
+const watchPollInterval = time.Second
+
+// gsqlHealthService implements the gsqlhealth.v1.GSQLHealth service
+type gsqlHealthService struct {
+	gsqlhealthpb.UnimplementedGSQLHealthServer
+	server *Server
+}
+
+// CheckHealth returns cached or realtime results of a table, a database or all databases
+func (g *gsqlHealthService) CheckHealth(ctx context.Context, req *gsqlhealthpb.CheckHealthRequest) (*gsqlhealthpb.CheckHealthResponse, error) {
+	if err := g.validateTarget(req.GetDatabase(), req.GetTable()); err != nil {
+		return nil, err
+	}
+
+	var results []*database.HealthResult
+	var err error
+	if req.GetRealtime() {
+		results, err = g.realtimeResults(ctx, req.GetDatabase(), req.GetTable())
+	} else {
+		results, err = g.cachedResults(req.GetDatabase(), req.GetTable())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	overall := overallStatus(results)
+	response := &gsqlhealthpb.CheckHealthResponse{
+		Status:     string(overall),
+		StatusCode: int32(overall.Code()),
+	}
+	for _, result := range results {
+		response.Results = append(response.Results, toProtoResult(result))
+	}
+
+	return response, nil
+}
+
+// ListDatabases returns the configured databases and their tables
+func (g *gsqlHealthService) ListDatabases(ctx context.Context, req *gsqlhealthpb.ListDatabasesRequest) (*gsqlhealthpb.ListDatabasesResponse, error) {
+	healthService := g.server.healthService
+	response := &gsqlhealthpb.ListDatabasesResponse{}
+
+	for _, databaseName := range healthService.GetDatabaseNames() {
+		tables, err := healthService.GetTableNames(databaseName)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		response.Databases = append(response.Databases, &gsqlhealthpb.Database{
+			Name:    databaseName,
+			Enabled: healthService.IsDatabaseEnabled(databaseName),
+			Labels:  healthService.GetDatabaseLabels(databaseName),
+			Tables:  tables,
+		})
+	}
+
+	return response, nil
+}
+
+// Watch sends the current cached results of the target and then every new
+// result until the client goes away or the server shuts down
+func (g *gsqlHealthService) Watch(req *gsqlhealthpb.WatchRequest, stream grpc.ServerStreamingServer[gsqlhealthpb.WatchResponse]) error {
+	if err := g.validateTarget(req.GetDatabase(), req.GetTable()); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	sent := make(map[string]time.Time) // last sent result timestamp per "database/table"
+	for {
+		results, err := g.cachedResults(req.GetDatabase(), req.GetTable())
+		if err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			key := result.DatabaseName + "/" + result.TableName
+			if last, ok := sent[key]; ok && !result.Timestamp.After(last) {
+				continue
+			}
+			if err := stream.Send(&gsqlhealthpb.WatchResponse{Result: toProtoResult(result)}); err != nil {
+				return err
+			}
+			sent[key] = result.Timestamp
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return nil
+		case <-g.server.done:
+			return nil
+		}
+	}
+}
+
+// validateTarget checks that a requested database and table are configured
+func (g *gsqlHealthService) validateTarget(databaseName, tableName string) error {
+	if databaseName == "" {
+		if tableName != "" {
+			return status.Error(codes.InvalidArgument, "table requires database")
+		}
+		return nil
+	}
+
+	tables, err := g.server.healthService.GetTableNames(databaseName)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "database %q not found", databaseName)
+	}
+	if tableName == "" {
+		return nil
+	}
+
+	for _, name := range tables {
+		if name == tableName {
+			return nil
+		}
+	}
+	return status.Errorf(codes.NotFound, "table %q not found in database %q", tableName, databaseName)
+}
+
+// cachedResults returns the cached results of a target, sorted by database and table
+func (g *gsqlHealthService) cachedResults(databaseName, tableName string) ([]*database.HealthResult, error) {
+	healthService := g.server.healthService
+
+	var results []*database.HealthResult
+	switch {
+	case tableName != "":
+		result, err, updatedAt := healthService.GetCachedHealth(databaseName, tableName)
+		if result == nil {
+			if err == nil {
+				return nil, nil
+			}
+			result = errorResult(databaseName, tableName, err, updatedAt)
+		}
+		results = []*database.HealthResult{result}
+	case databaseName != "":
+		var err error
+		results, err = healthService.GetCachedDatabaseHealth(databaseName)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+	default:
+		for _, dbResults := range healthService.GetAllCachedHealth() {
+			results = append(results, dbResults...)
+		}
+	}
+
+	sortResults(results)
+	return results, nil
+}
+
+// realtimeResults runs the checks of a target now, under the realtime request deadline
+func (g *gsqlHealthService) realtimeResults(ctx context.Context, databaseName, tableName string) ([]*database.HealthResult, error) {
+	healthService := g.server.healthService
+	if healthService.UnderMemoryPressure() {
+		return nil, status.Error(codes.ResourceExhausted, "realtime checks are unavailable under memory pressure, use cached results")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.server.config.Server.GetRequestTimeout())
+	defer cancel()
+
+	var results []*database.HealthResult
+	switch {
+	case tableName != "":
+		result, err := healthService.CheckHealth(ctx, databaseName, tableName)
+		if err != nil {
+			var healthErr *health.HealthError
+			if errors.As(err, &healthErr) && healthErr.IsNotFoundError() {
+				return nil, status.Error(codes.NotFound, err.Error())
+			}
+			if result == nil {
+				result = errorResult(databaseName, tableName, err, time.Now())
+			}
+		}
+		results = []*database.HealthResult{result}
+	case databaseName != "":
+		var err error
+		results, err = healthService.CheckDatabaseHealth(ctx, databaseName)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+	default:
+		all, err := healthService.CheckAllHealth(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		for _, dbResults := range all {
+			results = append(results, dbResults...)
+		}
+	}
+
+	sortResults(results)
+	return results, nil
+}
+
+// errorResult creates the result reported for a check that failed without one
+func errorResult(databaseName, tableName string, err error, timestamp time.Time) *database.HealthResult {
+	return &database.HealthResult{
+		DatabaseName: databaseName,
+		TableName:    tableName,
+		Status:       database.StatusError,
+		Error:        err.Error(),
+		Timestamp:    timestamp,
+	}
+}
+
+// overallStatus summarizes results the same way as the HTTP /health endpoint
+func overallStatus(results []*database.HealthResult) database.Status {
+	summary := database.NewSummary()
+	summary.Add(results...)
+	return summary.Status
+}
+
+// sortResults orders results by database and table name
+func sortResults(results []*database.HealthResult) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].DatabaseName != results[j].DatabaseName {
+			return results[i].DatabaseName < results[j].DatabaseName
+		}
+		return results[i].TableName < results[j].TableName
+	})
+}
+
+// toProtoResult converts a health result to its protobuf representation
+func toProtoResult(result *database.HealthResult) *gsqlhealthpb.HealthResult {
+	converted := &gsqlhealthpb.HealthResult{
+		Database:   result.DatabaseName,
+		Table:      result.TableName,
+		Status:     string(result.Status),
+		StatusCode: int32(result.Status.Code()),
+		Labels:     result.Labels,
+		Error:      result.Error,
+		QueryTime:  durationpb.New(result.QueryTime),
+		Timestamp:  timestamppb.New(result.Timestamp),
+	}
+
+	if result.Data != nil {
+		fields := make(map[string]*structpb.Value, len(result.Data))
+		for key, value := range result.Data {
+			protoValue, err := structpb.NewValue(value)
+			if err != nil {
+				// Values without a JSON-like representation, e.g. time.Time
+				protoValue = structpb.NewStringValue(fmt.Sprint(value))
+			}
+			fields[key] = protoValue
+		}
+		converted.Data = &structpb.Struct{Fields: fields}
+	}
+
+	if result.UnhealthySince != nil {
+		converted.UnhealthySince = timestamppb.New(*result.UnhealthySince)
+		converted.Downtime = durationpb.New(result.Downtime)
+	}
+
+	return converted
+}
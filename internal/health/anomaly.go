@@ -0,0 +1,105 @@
+package health
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+const (
+	// baselineWeight is the weight of each new query time in the moving
+	// average and variance of a check's baseline
+	baselineWeight = 0.1
+
+	// baselineWarmup is the number of query times a baseline needs before
+	// anomalies are reported
+	baselineWarmup = 20
+
+	// minAnomalyDelta is the smallest excess over the baseline reported, so
+	// the jitter of very fast queries is not an anomaly
+	minAnomalyDelta = 10 * time.Millisecond
+)
+
+// latencyBaseline is the exponentially weighted moving average and variance
+// of a check's query times, in seconds
+type latencyBaseline struct {
+	mean     float64
+	variance float64
+	samples  int
+}
+
+// detectAnomaly adds the query time of a scheduled check's successful run to
+// the check's baseline, degrading an otherwise healthy result whose query
+// time is anomalous
+func (s *Scheduler) detectAnomaly(check *ScheduledCheck, result *database.HealthResult, err error) *database.HealthResult {
+	if err != nil || result == nil || result.Data == nil {
+		return result
+	}
+
+	anomaly := s.service.observeLatency(result.DatabaseName, result.TableName, check.AnomalyStddevs, result.QueryTime)
+	if anomaly == "" || result.Status != database.StatusHealthy {
+		return result
+	}
+
+	// The query succeeded, but much more slowly than it usually does
+	anomalies.Add(1)
+	anomalous := *result
+	anomalous.Error = anomaly
+	anomalous.Status = database.StatusDegraded
+	if s.service.InMaintenance(result.DatabaseName, result.Timestamp) {
+		anomalous.Status = database.StatusMaintenance
+	}
+	s.logger.Warn("Health check query time is anomalous",
+		"database", result.DatabaseName,
+		"table", result.TableName,
+		"query_time", result.QueryTime,
+		"error", anomaly)
+	return &anomalous
+}
+
+// observeLatency compares the query time of a successful check with the
+// check's baseline and adds it to the baseline, returning why the query time
+// is more than stddevs standard deviations above the baseline or "" if it is
+// not. Anomalous query times are added too, so a lasting change becomes the
+// new baseline.
+func (s *Service) observeLatency(databaseName, tableName string, stddevs float64, queryTime time.Duration) string {
+	if stddevs <= 0 {
+		return ""
+	}
+	key := databaseName + "/" + tableName
+	seconds := queryTime.Seconds()
+
+	s.baselineMu.Lock()
+	defer s.baselineMu.Unlock()
+
+	baseline, exists := s.baselines[key]
+	if !exists {
+		s.baselines[key] = &latencyBaseline{mean: seconds, samples: 1}
+		return ""
+	}
+
+	stddev := math.Sqrt(baseline.variance)
+	excess := seconds - baseline.mean
+	anomalous := baseline.samples >= baselineWarmup &&
+		excess > stddevs*stddev &&
+		excess > minAnomalyDelta.Seconds()
+
+	message := ""
+	if anomalous {
+		message = fmt.Sprintf("query time %v is %.1f standard deviations above the baseline of %v",
+			queryTime, excess/stddev, secondsDuration(baseline.mean))
+	}
+
+	increment := baselineWeight * excess
+	baseline.mean += increment
+	baseline.variance = (1 - baselineWeight) * (baseline.variance + excess*increment)
+	baseline.samples++
+	return message
+}
+
+// secondsDuration converts seconds to a duration rounded for messages
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Microsecond)
+}
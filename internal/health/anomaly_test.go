@@ -0,0 +1,85 @@
+package health
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestObserveLatency(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Query times alternate between 95ms and 105ms while the baseline warms up
+	for i := 0; i < baselineWarmup; i++ {
+		queryTime := 95 * time.Millisecond
+		if i%2 == 1 {
+			queryTime = 105 * time.Millisecond
+		}
+		if anomaly := service.observeLatency("db", "orders", 3, queryTime); anomaly != "" {
+			t.Fatalf("check %d: observeLatency() = %q; expected no anomaly while warming up", i, anomaly)
+		}
+	}
+
+	if anomaly := service.observeLatency("db", "orders", 3, 110*time.Millisecond); anomaly != "" {
+		t.Errorf("observeLatency(110ms) = %q; expected usual jitter to pass", anomaly)
+	}
+	anomaly := service.observeLatency("db", "orders", 3, 300*time.Millisecond)
+	if !strings.HasPrefix(anomaly, "query time 300ms is ") || !strings.Contains(anomaly, "standard deviations above the baseline of") {
+		t.Errorf("observeLatency(300ms) = %q; expected an anomaly", anomaly)
+	}
+
+	// Checks without anomaly_stddevs keep no baseline
+	if anomaly := service.observeLatency("db", "users", 0, time.Hour); anomaly != "" {
+		t.Errorf("observeLatency() = %q; expected no anomaly detection", anomaly)
+	}
+	if _, exists := service.baselines["db/users"]; exists {
+		t.Error("Expected no baseline for a check without anomaly_stddevs")
+	}
+}
+
+func TestObserveLatencyMinimumDelta(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for i := 0; i < baselineWarmup; i++ {
+		service.observeLatency("db", "ping", 3, time.Millisecond)
+	}
+
+	// Far above a baseline without variance, but too little to matter
+	if anomaly := service.observeLatency("db", "ping", 3, 5*time.Millisecond); anomaly != "" {
+		t.Errorf("observeLatency(5ms) = %q; expected excesses below %v to pass", anomaly, minAnomalyDelta)
+	}
+	if anomaly := service.observeLatency("db", "ping", 3, 50*time.Millisecond); anomaly == "" {
+		t.Error("observeLatency(50ms) = \"\"; expected an anomaly")
+	}
+}
+
+func TestDetectAnomaly(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	check := &ScheduledCheck{DatabaseName: "db", TableName: "orders", AnomalyStddevs: 3}
+	run := func(status database.Status, queryTime time.Duration) *database.HealthResult {
+		result := &database.HealthResult{
+			DatabaseName: "db",
+			TableName:    "orders",
+			Status:       status,
+			QueryTime:    queryTime,
+			Data:         map[string]interface{}{"row_count": 1},
+		}
+		return service.scheduler.detectAnomaly(check, result, nil)
+	}
+
+	for i := 0; i < baselineWarmup; i++ {
+		run(database.StatusHealthy, 100*time.Millisecond)
+	}
+
+	if result := run(database.StatusHealthy, time.Second); result.Status != database.StatusDegraded || result.Error == "" {
+		t.Errorf("anomalous run = %s (%q); expected degraded", result.Status, result.Error)
+	}
+	if result := run(database.StatusUnhealthy, time.Second); result.Status != database.StatusUnhealthy {
+		t.Errorf("failing anomalous run = %s; expected its status to be kept", result.Status)
+	}
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// CheckTarget identifies one check of a batch request
+type CheckTarget struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+}
+
+// CheckBatch runs the given checks with at most concurrency of them in flight
+// and returns their results in request order. Checks that fail without a
+// result are reported as error results, as in CheckDatabaseHealth. Unknown
+// targets are rejected with a not found error before any check runs.
+func (s *Service) CheckBatch(ctx context.Context, targets []CheckTarget, concurrency int) ([]*database.HealthResult, error) {
+	for _, target := range targets {
+		if !s.hasTable(target.Database, target.Table) {
+			return nil, NewNotFoundError(target.Database, target.Table, "table not found in database configuration")
+		}
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]*database.HealthResult, len(targets))
+	slots := make(chan struct{}, concurrency)
+	done := make(chan struct{})
+
+	for i, target := range targets {
+		go func(i int, target CheckTarget) {
+			defer func() { done <- struct{}{} }()
+
+			var result *database.HealthResult
+			var err error
+
+			select {
+			case slots <- struct{}{}:
+				result, err = s.safeCheckHealth(ctx, target.Database, target.Table)
+				<-slots
+			case <-ctx.Done():
+				err = NewTimeoutError(target.Database, target.Table, "batch deadline exceeded before the check started", ctx.Err())
+			}
+
+			if err != nil && (result == nil || result.Status != database.StatusInternalError) {
+				result = &database.HealthResult{
+					DatabaseName: target.Database,
+					TableName:    target.Table,
+					Labels:       s.GetTableLabels(target.Database, target.Table),
+					Severity:     s.GetTableSeverity(target.Database, target.Table),
+					Status:       database.StatusError,
+					Error:        err.Error(),
+					Timestamp:    time.Now(),
+				}
+				if since, down := s.unhealthySinceFor(target.Database, target.Table); down {
+					annotateDowntime(result, since)
+				}
+			}
+			results[i] = result
+		}(i, target)
+	}
+
+	for range targets {
+		<-done
+	}
+
+	return results, nil
+}
+
+// hasTable reports whether a table is configured for a database
+func (s *Service) hasTable(databaseName, tableName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, db := range s.config.Databases {
+		if db.Name != databaseName {
+			continue
+		}
+		for _, table := range db.Tables {
+			if table.Name == tableName {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// detectChange compares the data of a check that alerts on change with the
+// previous successful check. When it differs, a passing result is replaced
+// by an unhealthy copy naming the changed columns and a data_changed event is
+// published. The first check, and checks without data, only set the
+// baseline.
+func (s *Scheduler) detectChange(cachedResult *CachedResult, result *database.HealthResult, err error) *database.HealthResult {
+	if err != nil || result == nil || result.Data == nil {
+		return result
+	}
+
+	cachedResult.mu.Lock()
+	previous := cachedResult.previousData
+	cachedResult.previousData = result.Data
+	cachedResult.mu.Unlock()
+
+	if previous == nil {
+		return result
+	}
+	changed := changedColumns(previous, result.Data)
+	if len(changed) == 0 {
+		return result
+	}
+
+	s.logger.Warn("Health check data changed",
+		"database", result.DatabaseName,
+		"table", result.TableName,
+		"changed", changed)
+
+	changedResult := *result
+	if !isFailing(result, nil) {
+		changedResult.Status = database.StatusUnhealthy
+		if s.service.InMaintenance(result.DatabaseName, result.Timestamp) {
+			changedResult.Status = database.StatusMaintenance
+		}
+		changedResult.Error = fmt.Sprintf("data changed since the previous check: %s", strings.Join(changed, ", "))
+	}
+
+	s.service.publish(Event{
+		Type:      EventDataChanged,
+		Database:  result.DatabaseName,
+		Table:     result.TableName,
+		Status:    changedResult.Status,
+		Labels:    result.Labels,
+		Changed:   changed,
+		Timestamp: time.Now(),
+	})
+
+	return &changedResult
+}
+
+// changedColumns returns the sorted columns whose values differ between two
+// check results, including columns present in only one of them
+func changedColumns(previous, current map[string]interface{}) []string {
+	var changed []string
+	for column, value := range current {
+		if old, exists := previous[column]; !exists || !reflect.DeepEqual(old, value) {
+			changed = append(changed, column)
+		}
+	}
+	for column := range previous {
+		if _, exists := current[column]; !exists {
+			changed = append(changed, column)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestDetectChange(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	events, unsubscribe := service.Subscribe(4)
+	defer unsubscribe()
+
+	cached := &CachedResult{}
+	check := func(status database.Status, data map[string]interface{}) *database.HealthResult {
+		result := &database.HealthResult{DatabaseName: "db", TableName: "settings", Status: status, Data: data}
+		return service.scheduler.detectChange(cached, result, nil)
+	}
+
+	if result := check(database.StatusHealthy, map[string]interface{}{"mode": "strict", "version": int64(3)}); result.Status != database.StatusHealthy {
+		t.Errorf("first check status = %s; expected healthy", result.Status)
+	}
+	if result := check(database.StatusHealthy, map[string]interface{}{"mode": "strict", "version": int64(3)}); result.Status != database.StatusHealthy {
+		t.Errorf("unchanged check status = %s; expected healthy", result.Status)
+	}
+
+	result := check(database.StatusHealthy, map[string]interface{}{"mode": "lenient", "flag": true})
+	if result.Status != database.StatusUnhealthy || result.Error != "data changed since the previous check: flag, mode, version" {
+		t.Errorf("changed check = %s (%q); expected unhealthy naming flag, mode and version", result.Status, result.Error)
+	}
+	select {
+	case event := <-events:
+		if event.Type != EventDataChanged || !reflect.DeepEqual(event.Changed, []string{"flag", "mode", "version"}) {
+			t.Errorf("event = %+v; expected data_changed for flag, mode and version", event)
+		}
+	default:
+		t.Error("expected a data_changed event")
+	}
+
+	// The changed data becomes the new baseline
+	if result := check(database.StatusHealthy, map[string]interface{}{"mode": "lenient", "flag": true}); result.Status != database.StatusHealthy {
+		t.Errorf("check after change status = %s; expected healthy", result.Status)
+	}
+}
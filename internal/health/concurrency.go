@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"sync"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// checkSlots limits the scheduled checks that run at once, overall and per
+// database. Each limit is a semaphore of its capacity.
+type checkSlots struct {
+	global    chan struct{}            // nil without a global limit
+	databases map[string]chan struct{} // key: database, only databases with a limit
+	mu        sync.RWMutex             // guards databases
+}
+
+// newCheckSlots creates the slots of the configured limits
+func newCheckSlots(cfg *config.Config) *checkSlots {
+	slots := &checkSlots{databases: make(map[string]chan struct{})}
+	if cfg.Scheduler.MaxConcurrent > 0 {
+		slots.global = make(chan struct{}, cfg.Scheduler.MaxConcurrent)
+	}
+	for _, db := range cfg.Databases {
+		slots.addDatabase(db)
+	}
+	return slots
+}
+
+// addDatabase creates the slots of a database with a limit
+func (s *checkSlots) addDatabase(db config.Database) {
+	if db.MaxConcurrentChecks <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.databases[db.Name] = make(chan struct{}, db.MaxConcurrentChecks)
+}
+
+// removeDatabase drops the slots of a database. Checks holding one release
+// it to the dropped semaphore.
+func (s *checkSlots) removeDatabase(databaseName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.databases, databaseName)
+}
+
+// acquire waits for a slot of the database and then an overall slot, and
+// returns the function releasing both. The database slot is taken first, so
+// checks queued behind a busy database do not hold overall slots that checks
+// of other databases could use.
+func (s *checkSlots) acquire(ctx context.Context, databaseName string) (func(), error) {
+	s.mu.RLock()
+	database := s.databases[databaseName]
+	s.mu.RUnlock()
+
+	if err := take(ctx, database); err != nil {
+		return nil, err
+	}
+	if err := take(ctx, s.global); err != nil {
+		give(database)
+		return nil, err
+	}
+	return func() {
+		give(s.global)
+		give(database)
+	}, nil
+}
+
+// take takes a slot of a semaphore, which is free without a limit
+func take(ctx context.Context, semaphore chan struct{}) error {
+	if semaphore == nil {
+		return nil
+	}
+	select {
+	case semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// give returns a slot taken from a semaphore
+func give(semaphore chan struct{}) {
+	if semaphore != nil {
+		<-semaphore
+	}
+}
+
+// runLimited runs a scheduled check once the concurrency limits allow it. The
+// scheduled check deadline starts when the check runs, not while it waits. It
+// reports false if the scheduler stopped before the check could run.
+func (s *Scheduler) runLimited(databaseName, tableName string) (*database.HealthResult, error, bool) {
+	release, err := s.slots.acquire(s.ctx, databaseName)
+	if err != nil {
+		return nil, nil, false
+	}
+	defer release()
+
+	timeout := s.service.config.GetScheduledCheckTimeout()
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+
+	result, err := s.runWatched(ctx, databaseName, tableName, timeout)
+	return result, err, true
+}
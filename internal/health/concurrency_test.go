@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestCheckSlots(t *testing.T) {
+	slots := newCheckSlots(&config.Config{
+		Scheduler: config.Scheduler{MaxConcurrent: 2},
+		Databases: []config.Database{{Name: "busy", MaxConcurrentChecks: 1}, {Name: "other"}},
+	})
+
+	release, err := slots.acquire(context.Background(), "busy")
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	// The database limit holds back a second check of the same database...
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := slots.acquire(ctx, "busy"); err == nil {
+		t.Fatal("acquire() succeeded beyond the database limit")
+	}
+
+	// ...without holding an overall slot that another database can use
+	releaseOther, err := slots.acquire(context.Background(), "other")
+	if err != nil {
+		t.Fatalf("acquire() for another database error = %v", err)
+	}
+
+	// Both overall slots are taken now
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := slots.acquire(ctx, "other"); err == nil {
+		t.Fatal("acquire() succeeded beyond the overall limit")
+	}
+
+	release()
+	releaseOther()
+	release, err = slots.acquire(context.Background(), "busy")
+	if err != nil {
+		t.Fatalf("acquire() after release error = %v", err)
+	}
+	release()
+}
+
+func TestCheckSlotsUnlimited(t *testing.T) {
+	slots := newCheckSlots(&config.Config{Databases: []config.Database{{Name: "db"}}})
+	for i := 0; i < 100; i++ {
+		if _, err := slots.acquire(context.Background(), "db"); err != nil {
+			t.Fatalf("acquire() without limits error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// deadlockSample is a reading of a deadlocks check's counter
+type deadlockSample struct {
+	count int64
+	at    time.Time
+}
+
+// sampleDeadlocks records the deadlock counter a deadlocks check returned and
+// adds the deadlocks since the previous sample to its data as
+// "new_deadlocks", with the time between the samples as "interval_seconds".
+// The first sample, and the first after the counter was reset, only set the
+// baseline.
+func (s *Service) sampleDeadlocks(databaseName, tableName string, data map[string]interface{}, now time.Time) {
+	value, ok := toFloat(data["deadlocks"])
+	if !ok {
+		return
+	}
+	count := int64(value)
+	key := databaseName + "/" + tableName
+
+	s.deadlockMu.Lock()
+	previous, exists := s.deadlocks[key]
+	s.deadlocks[key] = deadlockSample{count: count, at: now}
+	s.deadlockMu.Unlock()
+
+	if exists && count >= previous.count {
+		data["new_deadlocks"] = count - previous.count
+		data["interval_seconds"] = now.Sub(previous.at).Seconds()
+	}
+}
+
+// detectDeadlocks samples the deadlock counter of a scheduled deadlocks
+// check's result, failing it when more deadlocks than the check allows
+// occurred since the previous sample
+func (s *Scheduler) detectDeadlocks(check *ScheduledCheck, result *database.HealthResult, err error) *database.HealthResult {
+	if err != nil || result == nil || result.Data == nil {
+		return result
+	}
+
+	sampled := *result
+	sampled.Data = make(map[string]interface{}, len(result.Data)+2)
+	for column, value := range result.Data {
+		sampled.Data[column] = value
+	}
+	s.service.sampleDeadlocks(result.DatabaseName, result.TableName, sampled.Data, result.Timestamp)
+
+	failed := exceededDeadlocks(check.MaxDeadlocks, sampled.Data)
+	if failed == "" || isFailing(result, nil) {
+		return &sampled
+	}
+
+	checkFailures.Add(1)
+	sampled.Status = database.StatusUnhealthy
+	if s.service.InMaintenance(result.DatabaseName, result.Timestamp) {
+		sampled.Status = database.StatusMaintenance
+	}
+	sampled.Error = failed
+	s.logger.Warn("Health check exceeded deadlock threshold",
+		"database", result.DatabaseName,
+		"table", result.TableName,
+		"error", failed)
+	return &sampled
+}
+
+// checkDeadlocks checks that a deadlocks check returned a deadlock counter,
+// returning why it did not or "" if it did. The deadlocks since the previous
+// sample are checked on scheduled runs only.
+func checkDeadlocks(data map[string]interface{}) string {
+	if _, ok := toFloat(data["deadlocks"]); !ok {
+		return fmt.Sprintf("expected a deadlock count, got %v", data["deadlocks"])
+	}
+	return ""
+}
+
+// exceededDeadlocks checks the deadlocks since the previous sample of a
+// deadlocks check against maxDeadlocks, returning how many occurred or "" if
+// they are within it
+func exceededDeadlocks(maxDeadlocks int64, data map[string]interface{}) string {
+	deadlocks, sampled := data["new_deadlocks"].(int64)
+	if !sampled || deadlocks <= maxDeadlocks {
+		return ""
+	}
+
+	seconds, _ := data["interval_seconds"].(float64)
+	interval := time.Duration(seconds * float64(time.Second)).Round(time.Second)
+	return fmt.Sprintf("%d deadlocks in the last %v, expected at most %d", deadlocks, interval, maxDeadlocks)
+}
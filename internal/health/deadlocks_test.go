@@ -0,0 +1,51 @@
+package health
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestDeadlocksCheck(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	check := &ScheduledCheck{DatabaseName: "db", TableName: "deadlocks", Deadlocks: true, MaxDeadlocks: 2}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		count          interface{}
+		expected       string
+		expectedStatus database.Status
+	}{
+		{"baseline", int64(100), "", database.StatusHealthy},
+		{"within", "102", "", database.StatusHealthy},
+		{"exceeded", int64(110), "8 deadlocks in the last 1m0s, expected at most 2", database.StatusUnhealthy},
+		{"counter reset", int64(1), "", database.StatusHealthy},
+		{"after reset", int64(2), "", database.StatusHealthy},
+	}
+
+	for i, tt := range tests {
+		result := &database.HealthResult{
+			DatabaseName: "db",
+			TableName:    "deadlocks",
+			Status:       database.StatusHealthy,
+			Data:         map[string]interface{}{"deadlocks": tt.count},
+			Timestamp:    start.Add(time.Duration(i) * time.Minute),
+		}
+		sampled := service.scheduler.detectDeadlocks(check, result, nil)
+		if sampled.Status != tt.expectedStatus || sampled.Error != tt.expected {
+			t.Errorf("%s: detectDeadlocks() = %s (%q); expected %s (%q)", tt.name, sampled.Status, sampled.Error, tt.expectedStatus, tt.expected)
+		}
+		if _, exists := result.Data["new_deadlocks"]; exists {
+			t.Errorf("%s: detectDeadlocks() modified the data of the check's result", tt.name)
+		}
+	}
+
+	if failed := checkDeadlocks(map[string]interface{}{"row_count": 0}); failed == "" {
+		t.Error("checkDeadlocks() passed without a deadlock counter")
+	}
+}
@@ -0,0 +1,69 @@
+package health
+
+import (
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// skippedConnectionDown is the error of table checks skipped while the
+// connection check of their database fails
+const skippedConnectionDown = "skipped (connection down)"
+
+// connectionDown reports whether the latest connection check of a database
+// failed. Databases without a connection check are never considered down.
+func (s *Scheduler) connectionDown(databaseName string) bool {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	return s.connDown[databaseName]
+}
+
+// recordConnectionState records the outcome of a database's connection check.
+// Table checks of the database are skipped while it fails, and refreshed as
+// soon as it passes again rather than at their next interval.
+func (s *Scheduler) recordConnectionState(databaseName string, down bool) {
+	s.connMu.Lock()
+	changed := s.connDown[databaseName] != down
+	s.connDown[databaseName] = down
+	s.connMu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if down {
+		s.logger.Warn("Connection check failed, skipping table checks",
+			"database", databaseName)
+		return
+	}
+
+	s.logger.Info("Connection check recovered, resuming table checks",
+		"database", databaseName)
+
+	s.mu.RLock()
+	var dependents []*ScheduledCheck
+	for _, check := range s.checks {
+		if check.DatabaseName == databaseName && !check.ConnectionCheck {
+			dependents = append(dependents, check)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, check := range dependents {
+		go s.Refresh(s.ctx, check.DatabaseName, check.TableName)
+	}
+}
+
+// skippedResult returns the result of a table check skipped because the
+// connection check of its database fails
+func (s *Scheduler) skippedResult(databaseName, tableName string) *database.HealthResult {
+	return &database.HealthResult{
+		DatabaseName: databaseName,
+		TableName:    tableName,
+		Labels:       s.service.GetTableLabels(databaseName, tableName),
+		Severity:     s.service.GetTableSeverity(databaseName, tableName),
+		Status:       database.StatusUnhealthy,
+		Error:        skippedConnectionDown,
+		Timestamp:    time.Now(),
+	}
+}
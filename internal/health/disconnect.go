@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// disconnectedError is the error of checks whose database is disconnected
+const disconnectedError = "database connection lost"
+
+// markDisconnected records that the connection to a database was lost. Until
+// it reconnects, its cached results are reported as disconnected.
+func (s *Service) markDisconnected(databaseName string, at time.Time) {
+	s.disconnectMu.Lock()
+	defer s.disconnectMu.Unlock()
+
+	if _, down := s.disconnected[databaseName]; !down {
+		s.disconnected[databaseName] = at
+	}
+}
+
+// markConnected reruns the checks of a reconnected database and then clears
+// its disconnect, so the results from before the outage are not served again
+func (s *Service) markConnected(databaseName string) {
+	if _, down := s.disconnectedSince(databaseName); !down {
+		return
+	}
+
+	go func() {
+		if err := s.scheduler.RefreshDatabase(context.Background(), databaseName); err != nil {
+			s.logger.Debug("Failed to refresh checks of reconnected database",
+				"database", databaseName,
+				"error", err)
+		}
+
+		s.disconnectMu.Lock()
+		delete(s.disconnected, databaseName)
+		s.disconnectMu.Unlock()
+	}()
+}
+
+// disconnectedSince returns when the connection to a database was lost, if it
+// has not reconnected since
+func (s *Service) disconnectedSince(databaseName string) (time.Time, bool) {
+	s.disconnectMu.RLock()
+	defer s.disconnectMu.RUnlock()
+
+	at, down := s.disconnected[databaseName]
+	return at, down
+}
+
+// overlayDisconnected returns the result to report for a cached check result:
+// a disconnected result while its database is disconnected, unless the check
+// is disabled or paused or the database in maintenance, the cached result otherwise
+func (s *Scheduler) overlayDisconnected(databaseName, tableName string, result *database.HealthResult, err error) (*database.HealthResult, error) {
+	at, down := s.service.disconnectedSince(databaseName)
+	if !down || (result == nil && err == nil) {
+		return result, err
+	}
+	if result != nil && (result.Status == database.StatusDisabled || result.Status == database.StatusPaused) {
+		return result, err
+	}
+	if s.service.InMaintenance(databaseName, time.Now()) {
+		return result, err
+	}
+
+	return &database.HealthResult{
+		DatabaseName:   databaseName,
+		TableName:      tableName,
+		Labels:         s.service.GetTableLabels(databaseName, tableName),
+		Severity:       s.service.GetTableSeverity(databaseName, tableName),
+		Status:         database.StatusDisconnected,
+		Error:          disconnectedError,
+		Timestamp:      at,
+		DisconnectedAt: &at,
+	}, nil
+}
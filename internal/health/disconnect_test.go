@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestDisconnectedResults(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Type: "postgres", Tables: []config.Table{
+			{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			{Name: "archive", Enabled: &disabled},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.drivers["test"] = &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := service.RefreshHealth(ctx, "test", "users"); err != nil {
+		t.Fatalf("RefreshHealth() error = %v", err)
+	}
+
+	lost := time.Now().Truncate(time.Second)
+	service.markDisconnected("test", lost)
+
+	result, err, _ := service.GetCachedHealth("test", "users")
+	if err != nil || result.Status != database.StatusDisconnected {
+		t.Fatalf("GetCachedHealth() = %+v, %v; expected disconnected", result, err)
+	}
+	if result.DisconnectedAt == nil || !result.DisconnectedAt.Equal(lost) {
+		t.Errorf("DisconnectedAt = %v; expected %v", result.DisconnectedAt, lost)
+	}
+
+	statuses := make(map[string]database.Status)
+	for _, result := range service.GetAllCachedHealth()["test"] {
+		statuses[result.TableName] = result.Status
+	}
+	if statuses["users"] != database.StatusDisconnected || statuses["archive"] != database.StatusDisabled {
+		t.Errorf("GetAllCachedHealth() statuses = %v; expected users disconnected and archive disabled", statuses)
+	}
+
+	// A later disconnect keeps the time of the first
+	service.markDisconnected("test", lost.Add(time.Minute))
+	if at, _ := service.disconnectedSince("test"); !at.Equal(lost) {
+		t.Errorf("disconnectedSince() = %v; expected %v", at, lost)
+	}
+
+	reconnected := time.Now()
+	service.markConnected("test")
+	for {
+		if _, down := service.disconnectedSince("test"); !down {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("Expected the disconnect to clear once the database reconnected")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	result, err, updatedAt := service.GetCachedHealth("test", "users")
+	if err != nil || result.Status != database.StatusHealthy {
+		t.Errorf("GetCachedHealth() after reconnecting = %+v, %v; expected healthy", result, err)
+	}
+	if updatedAt.Before(reconnected) {
+		t.Errorf("Expected users to be checked again before the disconnect cleared")
+	}
+}
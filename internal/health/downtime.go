@@ -0,0 +1,91 @@
+package health
+
+import (
+	"errors"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// recordDowntime tracks when a check became unhealthy and annotates the
+// result with the start and length of the ongoing outage. A check counts as
+// down from its first failing result until its next healthy or disabled one.
+// It also tracks since when a check has been continuously healthy.
+func (s *Service) recordDowntime(databaseName, tableName string, result *database.HealthResult, err error) {
+	// Unknown checks are not tracked so arbitrary requests cannot grow the map
+	var healthErr *HealthError
+	if errors.As(err, &healthErr) && healthErr.IsNotFoundError() {
+		return
+	}
+
+	key := databaseName + "/" + tableName
+	at := time.Now()
+	if result != nil {
+		at = result.Timestamp
+	}
+
+	s.downtimeMu.Lock()
+	defer s.downtimeMu.Unlock()
+
+	if err == nil && result != nil && (result.Status == database.StatusHealthy || result.Status == database.StatusDisabled || result.Status == database.StatusPaused) {
+		delete(s.unhealthySince, key)
+		if result.Status != database.StatusHealthy {
+			delete(s.healthySince, key)
+		} else if _, exists := s.healthySince[key]; !exists {
+			s.healthySince[key] = at
+		}
+		return
+	}
+
+	delete(s.healthySince, key)
+
+	since, exists := s.unhealthySince[key]
+	if !exists {
+		since = at
+		s.unhealthySince[key] = since
+	}
+
+	if result != nil {
+		annotateDowntime(result, since)
+	}
+}
+
+// forgetDowntime drops the outage tracking of a check, for outcomes of
+// removed checks recorded after their database was forgotten
+func (s *Service) forgetDowntime(databaseName, tableName string) {
+	key := databaseName + "/" + tableName
+
+	s.downtimeMu.Lock()
+	defer s.downtimeMu.Unlock()
+
+	delete(s.unhealthySince, key)
+	delete(s.healthySince, key)
+}
+
+// unhealthySinceFor returns when a check became unhealthy, if it currently is
+func (s *Service) unhealthySinceFor(databaseName, tableName string) (time.Time, bool) {
+	s.downtimeMu.Lock()
+	defer s.downtimeMu.Unlock()
+
+	since, exists := s.unhealthySince[databaseName+"/"+tableName]
+	return since, exists
+}
+
+// HealthySince returns since when a check has been continuously healthy, if it currently is
+func (s *Service) HealthySince(databaseName, tableName string) (time.Time, bool) {
+	s.downtimeMu.Lock()
+	defer s.downtimeMu.Unlock()
+
+	since, exists := s.healthySince[databaseName+"/"+tableName]
+	return since, exists
+}
+
+// annotateDowntime sets the outage fields of a result
+func annotateDowntime(result *database.HealthResult, since time.Time) {
+	unhealthySince := since
+	result.UnhealthySince = &unhealthySince
+	result.Downtime = result.Timestamp.Sub(since)
+	if result.Downtime < 0 {
+		result.Downtime = 0
+	}
+}
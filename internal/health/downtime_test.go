@@ -0,0 +1,77 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestRecordDowntime(t *testing.T) {
+	service := NewService(&config.Config{}, nil)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first := &database.HealthResult{Status: "unhealthy", Timestamp: start}
+	service.recordDowntime("db", "table", first, errors.New("connection refused"))
+	if first.UnhealthySince == nil || !first.UnhealthySince.Equal(start) {
+		t.Fatalf("UnhealthySince = %v; expected %v", first.UnhealthySince, start)
+	}
+	if first.Downtime != 0 {
+		t.Errorf("Downtime = %v; expected 0", first.Downtime)
+	}
+
+	// Errors without a result keep the outage going
+	service.recordDowntime("db", "table", nil, NewConnectionError("db", "table", "database connection failed", nil))
+
+	second := &database.HealthResult{Status: "maintenance", Timestamp: start.Add(5 * time.Minute)}
+	service.recordDowntime("db", "table", second, nil)
+	if second.UnhealthySince == nil || !second.UnhealthySince.Equal(start) {
+		t.Errorf("UnhealthySince = %v; expected %v", second.UnhealthySince, start)
+	}
+	if second.Downtime != 5*time.Minute {
+		t.Errorf("Downtime = %v; expected %v", second.Downtime, 5*time.Minute)
+	}
+
+	healthy := &database.HealthResult{Status: "healthy", Timestamp: start.Add(6 * time.Minute)}
+	service.recordDowntime("db", "table", healthy, nil)
+	if healthy.UnhealthySince != nil || healthy.Downtime != 0 {
+		t.Errorf("Expected no downtime on a healthy result, got since %v, downtime %v", healthy.UnhealthySince, healthy.Downtime)
+	}
+	if _, down := service.unhealthySinceFor("db", "table"); down {
+		t.Errorf("Expected outage to be cleared after a healthy result")
+	}
+}
+
+func TestRecordDowntimeIgnoresUnknownChecks(t *testing.T) {
+	service := NewService(&config.Config{}, nil)
+
+	service.recordDowntime("missing", "table", nil, NewNotFoundError("missing", "table", "table not found in database configuration"))
+
+	if _, down := service.unhealthySinceFor("missing", "table"); down {
+		t.Errorf("Expected unknown checks not to be tracked")
+	}
+}
+
+func TestHealthySince(t *testing.T) {
+	service := NewService(&config.Config{}, nil)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	service.recordDowntime("db", "table", &database.HealthResult{Status: "healthy", Timestamp: start}, nil)
+	service.recordDowntime("db", "table", &database.HealthResult{Status: "healthy", Timestamp: start.Add(time.Minute)}, nil)
+	if since, ok := service.HealthySince("db", "table"); !ok || !since.Equal(start) {
+		t.Errorf("HealthySince = %v, %v; expected %v, true", since, ok, start)
+	}
+
+	service.recordDowntime("db", "table", &database.HealthResult{Status: "unhealthy", Timestamp: start.Add(2 * time.Minute)}, errors.New("query failed"))
+	if _, ok := service.HealthySince("db", "table"); ok {
+		t.Errorf("Expected the healthy streak to end after a failure")
+	}
+
+	restart := start.Add(3 * time.Minute)
+	service.recordDowntime("db", "table", &database.HealthResult{Status: "healthy", Timestamp: restart}, nil)
+	if since, ok := service.HealthySince("db", "table"); !ok || !since.Equal(restart) {
+		t.Errorf("HealthySince = %v, %v; expected %v, true", since, ok, restart)
+	}
+}
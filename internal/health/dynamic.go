@@ -0,0 +1,266 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gsqlhealth/internal/config"
+)
+
+var (
+	// ErrDynamicDisabled is returned when databases are added or removed
+	// without a dynamic_databases file to keep them in
+	ErrDynamicDisabled = errors.New("dynamic databases are not enabled")
+	// ErrInvalidDatabase is returned for database definitions that fail validation
+	ErrInvalidDatabase = errors.New("invalid database")
+	// ErrDatabaseExists is returned when an added database's name is taken
+	ErrDatabaseExists = errors.New("database already exists")
+	// ErrStaticDatabase is returned when removing a database of the configuration file
+	ErrStaticDatabase = errors.New("database is configured in the configuration file")
+)
+
+// AddDatabase validates a database definition, keeps it in the
+// dynamic_databases file, and schedules its checks. The connection is made
+// in the background, retried like those of configured databases.
+func (s *Service) AddDatabase(db config.Database) (config.Database, error) {
+	if s.config.DynamicDatabases == "" {
+		return config.Database{}, ErrDynamicDisabled
+	}
+
+	prepared, err := s.config.PrepareDatabase(db)
+	if err != nil {
+		return config.Database{}, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.findDatabase(db.Name); exists {
+		return config.Database{}, fmt.Errorf("%w: %s", ErrDatabaseExists, db.Name)
+	}
+
+	// The definition is kept as submitted, so defaults apply afresh on load
+	stored, err := config.ReadDynamicDatabases(s.config.DynamicDatabases)
+	if err != nil {
+		return config.Database{}, err
+	}
+	if err := config.WriteDynamicDatabases(s.config.DynamicDatabases, append(stored, db)); err != nil {
+		return config.Database{}, fmt.Errorf("failed to save dynamic databases: %w", err)
+	}
+
+	// Databases are replaced rather than appended to, so readers holding the
+	// previous list are not affected
+	databases := make([]config.Database, 0, len(s.config.Databases)+1)
+	s.config.Databases = append(append(databases, s.config.Databases...), prepared)
+	s.indexDatabase(prepared)
+	s.scheduler.addDatabase(prepared)
+
+	if prepared.IsEnabled() {
+		ctx, cancel := context.WithCancel(s.background)
+		s.connecting[prepared.Name] = cancel
+		go s.connectDatabase(ctx, NewRetryableConnector(&s.config.Retry, s.logger), prepared)
+	}
+
+	s.logger.Info("Added dynamic database",
+		"database", prepared.Name,
+		"type", prepared.Type,
+		"tables", len(prepared.Tables))
+	return prepared, nil
+}
+
+// RemoveDatabase stops the checks of a database added through AddDatabase,
+// closes its connection and removes it from the dynamic_databases file
+func (s *Service) RemoveDatabase(databaseName string) error {
+	if s.config.DynamicDatabases == "" {
+		return ErrDynamicDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, exists := s.findDatabase(databaseName)
+	if !exists {
+		return NewNotFoundError(databaseName, "", "database not found in configuration")
+	}
+	if !db.Dynamic {
+		return fmt.Errorf("%w: %s", ErrStaticDatabase, databaseName)
+	}
+
+	stored, err := config.ReadDynamicDatabases(s.config.DynamicDatabases)
+	if err != nil {
+		return err
+	}
+	kept := stored[:0]
+	for _, storedDB := range stored {
+		if storedDB.Name != databaseName {
+			kept = append(kept, storedDB)
+		}
+	}
+	if err := config.WriteDynamicDatabases(s.config.DynamicDatabases, kept); err != nil {
+		return fmt.Errorf("failed to save dynamic databases: %w", err)
+	}
+
+	databases := make([]config.Database, 0, len(s.config.Databases))
+	for _, configured := range s.config.Databases {
+		if configured.Name != databaseName {
+			databases = append(databases, configured)
+		}
+	}
+	s.config.Databases = databases
+
+	s.scheduler.removeDatabase(databaseName)
+
+	if cancel, connecting := s.connecting[databaseName]; connecting {
+		cancel()
+		delete(s.connecting, databaseName)
+	}
+	if driver, connected := s.drivers[databaseName]; connected {
+		driver.Close()
+		delete(s.drivers, databaseName)
+	}
+
+	s.unindexDatabase(databaseName)
+	s.forgetChecks(databaseName)
+
+	s.logger.Info("Removed dynamic database", "database", databaseName)
+	return nil
+}
+
+// findDatabase returns the configuration of a database; callers must hold s.mu
+func (s *Service) findDatabase(databaseName string) (config.Database, bool) {
+	for _, db := range s.config.Databases {
+		if db.Name == databaseName {
+			return db, true
+		}
+	}
+	return config.Database{}, false
+}
+
+// indexDatabase resolves the effective labels, severities, maintenance
+// windows and active hours of a database's checks
+func (s *Service) indexDatabase(db config.Database) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	s.labels[db.Name] = db.Labels
+	s.maintenance[db.Name] = append(newMaintenanceWindows(db.MaintenanceWindows), newMaintenanceWindows(s.config.MaintenanceWindows)...)
+	for _, table := range db.Tables {
+		s.labels[db.Name+"/"+table.Name] = db.TableLabels(&table)
+		if severity := table.GetSeverity(); severity != config.SeverityCritical {
+			s.severities[db.Name+"/"+table.Name] = severity
+		}
+		// Errors for checks without active hours; other values are validated
+		// with the configuration
+		if hours, err := config.ParseActiveHours(db.TableActiveHours(&table)); err == nil {
+			s.activeHours[db.Name+"/"+table.Name] = hours
+		}
+	}
+}
+
+// unindexDatabase drops what indexDatabase resolved for a database
+func (s *Service) unindexDatabase(databaseName string) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+
+	delete(s.labels, databaseName)
+	delete(s.maintenance, databaseName)
+	deletePrefix(s.labels, databaseName+"/")
+	deletePrefix(s.severities, databaseName+"/")
+	deletePrefix(s.activeHours, databaseName+"/")
+}
+
+// forgetChecks drops the per-check state of a removed database's checks, so
+// a database added again under its name starts afresh
+func (s *Service) forgetChecks(databaseName string) {
+	prefix := databaseName + "/"
+
+	s.panicMu.Lock()
+	deletePrefix(s.panics, prefix)
+	s.panicMu.Unlock()
+
+	s.downtimeMu.Lock()
+	deletePrefix(s.unhealthySince, prefix)
+	deletePrefix(s.healthySince, prefix)
+	s.downtimeMu.Unlock()
+
+	s.historyMu.Lock()
+	deletePrefix(s.history, prefix)
+	s.historyMu.Unlock()
+
+	if s.historyStore != nil {
+		if err := s.historyStore.Forget(databaseName); err != nil {
+			s.logger.Error("Failed to delete check history", "database", databaseName, "error", err)
+		}
+	}
+
+	s.flapMu.Lock()
+	deletePrefix(s.flaps, prefix)
+	s.flapMu.Unlock()
+
+	s.deadlockMu.Lock()
+	deletePrefix(s.deadlocks, prefix)
+	s.deadlockMu.Unlock()
+
+	s.baselineMu.Lock()
+	deletePrefix(s.baselines, prefix)
+	s.baselineMu.Unlock()
+
+	s.disconnectMu.Lock()
+	delete(s.disconnected, databaseName)
+	s.disconnectMu.Unlock()
+}
+
+// deletePrefix deletes the keys of a map that start with prefix
+func deletePrefix[V any](m map[string]V, prefix string) {
+	for key := range m {
+		if strings.HasPrefix(key, prefix) {
+			delete(m, key)
+		}
+	}
+}
+
+// addDatabase schedules the checks of an added database
+func (s *Scheduler) addDatabase(db config.Database) {
+	s.slots.addDatabase(db)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, table := range db.Tables {
+		s.scheduleTable(db, table)
+	}
+	cacheSize.Set(int64(len(s.results)))
+}
+
+// removeDatabase stops the checks of a removed database and drops their
+// cached results
+func (s *Scheduler) removeDatabase(databaseName string) {
+	s.mu.Lock()
+	for key, check := range s.checks {
+		if !s.checkKeyMatches(key, databaseName, "") {
+			continue
+		}
+		s.unschedule(check)
+		delete(s.checks, key)
+	}
+	for key := range s.results {
+		if s.checkKeyMatches(key, databaseName, "") {
+			delete(s.results, key)
+		}
+	}
+	cacheSize.Set(int64(len(s.results)))
+	s.mu.Unlock()
+
+	s.slots.removeDatabase(databaseName)
+
+	s.pauseMu.Lock()
+	delete(s.pausedDatabases, databaseName)
+	s.pauseMu.Unlock()
+	s.forgetPausedChecks(databaseName)
+
+	s.connMu.Lock()
+	delete(s.connDown, databaseName)
+	s.connMu.Unlock()
+}
@@ -14,6 +14,8 @@ const (
 	ErrorTypeQuery
 	// ErrorTypeTimeout indicates a timeout occurred
 	ErrorTypeTimeout
+	// ErrorTypeInternal indicates a panic recovered while running a check
+	ErrorTypeInternal
 )
 
 // HealthError represents an error that occurred during health check operations
@@ -58,6 +60,11 @@ func (e *HealthError) IsTimeoutError() bool {
 	return e.Type == ErrorTypeTimeout
 }
 
+// IsInternalError returns true if the error is an internal error
+func (e *HealthError) IsInternalError() bool {
+	return e.Type == ErrorTypeInternal
+}
+
 // NewNotFoundError creates a new not found error
 func NewNotFoundError(database, table, message string) *HealthError {
 	return &HealthError{
@@ -99,4 +106,14 @@ func NewTimeoutError(database, table, message string, cause error) *HealthError
 		Message:  message,
 		Cause:    cause,
 	}
+}
+
+// NewInternalError creates a new internal error
+func NewInternalError(database, table, message string) *HealthError {
+	return &HealthError{
+		Type:     ErrorTypeInternal,
+		Database: database,
+		Table:    table,
+		Message:  message,
+	}
 }
\ No newline at end of file
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"sync"
+	"time"
+)
+
+// eventLog keeps the latest status transitions in a ring buffer, for the
+// timeline of GET /events?since=
+type eventLog struct {
+	mu     sync.RWMutex
+	events []Event
+	next   int // index the next event is written to
+	full   bool
+}
+
+// newEventLog creates an event log keeping up to size events
+func newEventLog(size int) *eventLog {
+	return &eventLog{events: make([]Event, size)}
+}
+
+// add appends an event, overwriting the oldest once the log is full
+func (l *eventLog) add(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events[l.next] = event
+	l.next = (l.next + 1) % len(l.events)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// since returns the events at or after since, oldest first
+func (l *eventLog) since(since time.Time) []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	count, oldest := l.next, 0
+	if l.full {
+		count, oldest = len(l.events), l.next
+	}
+
+	events := []Event{}
+	for i := 0; i < count; i++ {
+		event := l.events[(oldest+i)%len(l.events)]
+		if !event.Timestamp.Before(since) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// isTransition reports whether events of a type are status transitions, kept
+// in the event log: status changes of checks and database connections
+func (t EventType) isTransition() bool {
+	switch t {
+	case EventStatusChanged, EventConnected, EventDisconnected:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetEvents returns the status transitions of checks and database connections
+// at or after since, oldest first. Only the latest history.events transitions
+// are kept, in memory.
+func (s *Service) GetEvents(since time.Time) []Event {
+	return s.eventLog.since(since)
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestEventLog(t *testing.T) {
+	log := newEventLog(3)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if events := log.since(time.Time{}); len(events) != 0 {
+		t.Errorf("since() = %+v; expected no events", events)
+	}
+
+	for i := 0; i < 5; i++ {
+		log.add(Event{Type: EventStatusChanged, Table: string(rune('a' + i)), Timestamp: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	tests := []struct {
+		name     string
+		since    time.Time
+		expected string
+	}{
+		{"all kept", time.Time{}, "cde"},
+		{"since", start.Add(3 * time.Minute), "de"},
+		{"future", start.Add(time.Hour), ""},
+	}
+	for _, tt := range tests {
+		tables := ""
+		for _, event := range log.since(tt.since) {
+			tables += event.Table
+		}
+		if tables != tt.expected {
+			t.Errorf("%s: since() tables = %q; expected %q", tt.name, tables, tt.expected)
+		}
+	}
+}
+
+func TestGetEvents(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	before := time.Now()
+
+	service.publishStatusChange("app", "orders", database.StatusHealthy, &database.HealthResult{Status: database.StatusError, Error: "connection refused"}, nil)
+	service.publishCheckCompleted("app", "orders", &database.HealthResult{Status: database.StatusError}, nil)
+	service.publishConnectionEvent(EventDisconnected, "app")
+
+	events := service.GetEvents(before)
+	if len(events) != 2 {
+		t.Fatalf("GetEvents() = %+v; expected the status change and disconnection only", events)
+	}
+	if change := events[0]; change.Type != EventStatusChanged || change.PreviousStatus != database.StatusHealthy || change.Status != database.StatusError || change.Error != "connection refused" {
+		t.Errorf("events[0] = %+v; expected the status change", change)
+	}
+	if events[1].Type != EventDisconnected {
+		t.Errorf("events[1] = %+v; expected the disconnection", events[1])
+	}
+	if events := service.GetEvents(time.Now().Add(time.Minute)); len(events) != 0 {
+		t.Errorf("GetEvents() = %+v; expected none in the future", events)
+	}
+}
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"sync"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// EventType identifies the kind of a health event
+type EventType string
+
+const (
+	// EventStatusChanged is published when a check's status differs from its previous result
+	EventStatusChanged EventType = "status_changed"
+	// EventConnected is published when a database connection is established or recovered
+	EventConnected EventType = "connected"
+	// EventDisconnected is published when a database connection is found dead
+	EventDisconnected EventType = "disconnected"
+	// EventCheckCompleted is published after every scheduled check
+	EventCheckCompleted EventType = "check_completed"
+	// EventDataChanged is published when the data of a check that alerts on
+	// change differs from the previous check
+	EventDataChanged EventType = "data_changed"
+)
+
+// Event describes a change in health state
+type Event struct {
+	Type           EventType         `json:"type"`
+	Database       string            `json:"database"`
+	Table          string            `json:"table,omitempty"`
+	Status         database.Status   `json:"status,omitempty"`
+	PreviousStatus database.Status   `json:"previous_status,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	QueryTime      time.Duration     `json:"query_time,omitempty"`
+	Changed        []string          `json:"changed,omitempty"` // columns whose values changed
+	Timestamp      time.Time         `json:"timestamp"`
+}
+
+// eventBroker fans events out to subscribers
+type eventBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// newEventBroker creates an event broker without subscribers
+func newEventBroker() *eventBroker {
+	return &eventBroker{subscribers: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving health events and a function that
+// ends the subscription. Events are dropped for subscribers whose buffer is
+// full, so a slow consumer never delays health checks.
+func (s *Service) Subscribe(buffer int) (<-chan Event, func()) {
+	events := make(chan Event, buffer)
+
+	s.events.mu.Lock()
+	s.events.subscribers[events] = struct{}{}
+	s.events.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.events.mu.Lock()
+			delete(s.events.subscribers, events)
+			s.events.mu.Unlock()
+		})
+	}
+
+	return events, unsubscribe
+}
+
+// publish delivers an event to every subscriber that has room for it, and
+// records status transitions in the event log
+func (s *Service) publish(event Event) {
+	if event.Type.isTransition() {
+		s.eventLog.add(event)
+	}
+
+	s.events.mu.Lock()
+	defer s.events.mu.Unlock()
+
+	for subscriber := range s.events.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			s.logger.Debug("Dropping health event for slow subscriber",
+				"type", event.Type,
+				"database", event.Database,
+				"table", event.Table)
+		}
+	}
+}
+
+// publishStatusChange publishes a status change event when a check's status
+// differs from the previous one; previousStatus is empty for the first result
+func (s *Service) publishStatusChange(databaseName, tableName string, previousStatus database.Status, current *database.HealthResult, err error) {
+	event := Event{
+		Type:           EventStatusChanged,
+		Database:       databaseName,
+		Table:          tableName,
+		Status:         database.StatusError,
+		PreviousStatus: previousStatus,
+		Labels:         s.GetTableLabels(databaseName, tableName),
+		Timestamp:      time.Now(),
+	}
+	if current != nil {
+		event.Status = current.Status
+		event.Error = current.Error
+		event.QueryTime = current.QueryTime
+	} else if err != nil {
+		event.Error = err.Error()
+	}
+
+	if event.Status == previousStatus {
+		return
+	}
+
+	s.publish(event)
+}
+
+// publishCheckCompleted publishes the outcome of a scheduled check
+func (s *Service) publishCheckCompleted(databaseName, tableName string, result *database.HealthResult, err error) {
+	event := Event{
+		Type:      EventCheckCompleted,
+		Database:  databaseName,
+		Table:     tableName,
+		Status:    database.StatusError,
+		Labels:    s.GetTableLabels(databaseName, tableName),
+		Timestamp: time.Now(),
+	}
+	if result != nil {
+		event.Status = result.Status
+		event.Error = result.Error
+		event.QueryTime = result.QueryTime
+	} else if err != nil {
+		event.Error = err.Error()
+	}
+
+	s.publish(event)
+}
+
+// publishConnectionEvent publishes a database connect or disconnect event
+func (s *Service) publishConnectionEvent(eventType EventType, databaseName string) {
+	s.publish(Event{
+		Type:      eventType,
+		Database:  databaseName,
+		Labels:    s.GetDatabaseLabels(databaseName),
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestPublishStatusChange(t *testing.T) {
+	tests := []struct {
+		name           string
+		previousStatus database.Status
+		result         *database.HealthResult
+		err            error
+		expectEvent    bool
+		expectedStatus database.Status
+	}{
+		{"first result", "", &database.HealthResult{Status: database.StatusHealthy}, nil, true, database.StatusHealthy},
+		{"unchanged", database.StatusHealthy, &database.HealthResult{Status: database.StatusHealthy}, nil, false, ""},
+		{"became unhealthy", database.StatusHealthy, &database.HealthResult{Status: database.StatusUnhealthy, Error: "query failed"}, errors.New("query failed"), true, database.StatusUnhealthy},
+		{"error without result", database.StatusHealthy, nil, errors.New("database connection failed"), true, database.StatusError},
+		{"still erroring", database.StatusError, nil, errors.New("database connection failed"), false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			events, unsubscribe := service.Subscribe(1)
+			defer unsubscribe()
+
+			service.publishStatusChange("db", "table", tt.previousStatus, tt.result, tt.err)
+
+			select {
+			case event := <-events:
+				if !tt.expectEvent {
+					t.Fatalf("Unexpected event: %+v", event)
+				}
+				if event.Type != EventStatusChanged || event.Status != tt.expectedStatus || event.PreviousStatus != tt.previousStatus {
+					t.Errorf("Event = %+v; expected %s from %q to %q", event, EventStatusChanged, tt.previousStatus, tt.expectedStatus)
+				}
+			default:
+				if tt.expectEvent {
+					t.Fatalf("Expected an event but got none")
+				}
+			}
+		})
+	}
+}
+
+func TestSubscribeDropsForSlowSubscribers(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	events, unsubscribe := service.Subscribe(1)
+
+	// The second event must not block on the full buffer
+	service.publishConnectionEvent(EventConnected, "db")
+	service.publishConnectionEvent(EventDisconnected, "db")
+
+	if event := <-events; event.Type != EventConnected {
+		t.Errorf("Type = %s; expected %s", event.Type, EventConnected)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	service.publishConnectionEvent(EventConnected, "db")
+	select {
+	case event := <-events:
+		t.Errorf("Unexpected event after unsubscribe: %+v", event)
+	default:
+	}
+}
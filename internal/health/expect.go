@@ -0,0 +1,389 @@
+package health
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// rowsOf returns the rows of a check's data as shaped by the drivers: a
+// single row is returned directly, several under "results", and no rows as
+// just a zero "row_count"
+func rowsOf(data map[string]interface{}) []map[string]interface{} {
+	if rows, ok := data["results"].([]map[string]interface{}); ok {
+		return rows
+	}
+	if count, ok := data["row_count"]; ok && len(data) == 1 {
+		if n, numeric := toFloat(count); numeric && n == 0 {
+			return nil
+		}
+	}
+	return []map[string]interface{}{data}
+}
+
+// checkQuery returns the query a table's check runs: the configured query, or
+// the generated query of a built-in check in the database's dialect
+func checkQuery(dbConfig *config.Database, table *config.Table) string {
+	switch table.Check {
+	case config.CheckRowCount:
+		return database.CountQuery(dbConfig.Type, table.GetCheckTable())
+	case config.CheckFreshness:
+		return database.FreshnessQuery(dbConfig.Type, table.GetCheckTable(), table.Column)
+	case config.CheckReplicationLag:
+		return database.ReplicationLagQuery(dbConfig.Type)
+	case config.CheckStorage:
+		return database.StorageQuery(dbConfig.Type)
+	case config.CheckConnections:
+		return database.ConnectionsQuery(dbConfig.Type)
+	case config.CheckBlocking:
+		return database.BlockingQuery(dbConfig.Type, table.GetMaxWait())
+	case config.CheckLongQueries:
+		return database.LongQueriesQuery(dbConfig.Type, table.GetMaxDuration(), table.ShowQueries)
+	case config.CheckDeadlocks:
+		return database.DeadlocksQuery(dbConfig.Type)
+	case config.CheckConnection:
+		return database.ConnectionQuery(dbConfig.Type)
+	}
+	return table.Query
+}
+
+// renderQueries returns the queries a table's check runs at now, with the
+// templates of configured queries expanded
+func renderQueries(dbConfig *config.Database, table *config.Table, now time.Time) ([]string, error) {
+	if table.Check != "" {
+		return []string{checkQuery(dbConfig, table)}, nil
+	}
+	return dbConfig.RenderQueries(table, now)
+}
+
+// checkExpectations checks the data of a successful query against the table's
+// row bounds and expectations, returning why it fails them or "" if it passes
+func checkExpectations(table *config.Table, data map[string]interface{}) string {
+	if len(table.Steps) > 0 {
+		return checkSteps(table, data)
+	}
+
+	switch table.Check {
+	case config.CheckFreshness:
+		if failed := checkFreshness(table, data, time.Now()); failed != "" {
+			return failed
+		}
+	case config.CheckReplicationLag:
+		if failed := checkReplicationLag(table, data); failed != "" {
+			return failed
+		}
+	case config.CheckStorage:
+		if failed := checkStorage(table, data); failed != "" {
+			return failed
+		}
+	case config.CheckBlocking:
+		if failed := checkBlocking(table, data); failed != "" {
+			return failed
+		}
+	case config.CheckLongQueries:
+		if failed := checkLongQueries(table, data); failed != "" {
+			return failed
+		}
+	case config.CheckDeadlocks:
+		if failed := checkDeadlocks(data); failed != "" {
+			return failed
+		}
+	}
+
+	expectations := table.GetExpectations()
+	if len(expectations) == 0 && table.MinRows == nil && table.MaxRows == nil {
+		return ""
+	}
+
+	rows := rowsOf(data)
+	if table.MinRows != nil && len(rows) < *table.MinRows {
+		return fmt.Sprintf("expected at least %d rows, got %d", *table.MinRows, len(rows))
+	}
+	if table.MaxRows != nil && len(rows) > *table.MaxRows {
+		return fmt.Sprintf("expected at most %d rows, got %d", *table.MaxRows, len(rows))
+	}
+
+	// Every row must meet every expectation
+	for _, expectation := range expectations {
+		if len(rows) == 0 {
+			return fmt.Sprintf("expected %s %s %v, got no rows", expectation.Column, expectation.GetOperator(), expectation.Value)
+		}
+		for _, row := range rows {
+			actual, exists := row[expectation.Column]
+			if !exists {
+				return fmt.Sprintf("expected column %s in the query result", expectation.Column)
+			}
+			if !compare(actual, expectation.GetOperator(), expectation.Value) {
+				return fmt.Sprintf("expected %s %s %v, got %v", expectation.Column, expectation.GetOperator(), expectation.Value, actual)
+			}
+		}
+	}
+
+	return ""
+}
+
+// checkFreshness checks the newest value of a freshness check's column
+// against its max_age, returning why it is too old or "" if it is fresh
+func checkFreshness(table *config.Table, data map[string]interface{}, now time.Time) string {
+	value := data["newest"]
+	if value == nil {
+		return fmt.Sprintf("expected rows in %s, got none", table.GetCheckTable())
+	}
+
+	newest, ok := toTime(value)
+	if !ok {
+		return fmt.Sprintf("expected a timestamp in column %s, got %v", table.Column, value)
+	}
+
+	if age := now.Sub(newest); age > table.GetMaxAge() {
+		return fmt.Sprintf("expected %s within %v, newest is %v old", table.Column, table.GetMaxAge(), age.Round(time.Second))
+	}
+	return ""
+}
+
+// checkReplicationLag checks the replica status of a replication_lag check
+// against its max_lag, returning why the replica is failing or "" if it is
+// within the lag. Multi-source MySQL replicas must be within it on every
+// channel.
+func checkReplicationLag(table *config.Table, data map[string]interface{}) string {
+	rows := rowsOf(data)
+	if len(rows) == 0 {
+		return "expected a replica, replication is not configured"
+	}
+
+	for _, row := range rows {
+		var lag interface{}
+		if _, postgres := row["lag_seconds"]; postgres {
+			if recovering, _ := row["in_recovery"].(bool); !recovering {
+				return "expected a replica, the database is not in recovery"
+			}
+			if row["receiver_status"] == nil {
+				return "expected a replica streaming from its source, the WAL receiver is not running"
+			}
+			lag = row["lag_seconds"]
+		} else {
+			ioRunning, sqlRunning := fmt.Sprint(row["Replica_IO_Running"]), fmt.Sprint(row["Replica_SQL_Running"])
+			if ioRunning != "Yes" || sqlRunning != "Yes" {
+				failed := fmt.Sprintf("expected replication to be running, IO thread %s, SQL thread %s", ioRunning, sqlRunning)
+				for _, column := range []string{"Last_IO_Error", "Last_SQL_Error"} {
+					if message, _ := row[column].(string); message != "" {
+						failed += ": " + message
+					}
+				}
+				return failed
+			}
+			lag = row["Seconds_Behind_Source"]
+		}
+
+		seconds, ok := toFloat(lag)
+		if !ok {
+			return fmt.Sprintf("expected a replication lag, got %v", lag)
+		}
+		if lagged := time.Duration(seconds * float64(time.Second)); lagged > table.GetMaxLag() {
+			return fmt.Sprintf("expected replication lag within %v, replica is %v behind", table.GetMaxLag(), lagged.Round(time.Second))
+		}
+	}
+	return ""
+}
+
+// checkStorage checks the usage of a storage check against its max_usage,
+// returning why storage is too full or "" if it is within. With a capacity
+// the database's size is measured against it; otherwise every volume holding
+// the database's files must be within, including space used by other files.
+func checkStorage(table *config.Table, data map[string]interface{}) string {
+	rows := rowsOf(data)
+	if len(rows) == 0 {
+		return "expected storage usage, got no rows"
+	}
+
+	for _, row := range rows {
+		var used, total float64
+		if capacity := table.GetCapacity(); capacity > 0 {
+			var ok bool
+			if used, ok = toFloat(row["used_bytes"]); !ok {
+				return fmt.Sprintf("expected the database size, got %v", row["used_bytes"])
+			}
+			total = float64(capacity)
+		} else {
+			var totalOK, availableOK bool
+			var available float64
+			total, totalOK = toFloat(row["total_bytes"])
+			available, availableOK = toFloat(row["available_bytes"])
+			if !totalOK || !availableOK || total <= 0 {
+				return fmt.Sprintf("expected the size of volume %v, got %v bytes with %v available", row["volume"], row["total_bytes"], row["available_bytes"])
+			}
+			used = total - available
+		}
+
+		if usage := used / total * 100; usage > table.MaxUsage {
+			failed := fmt.Sprintf("expected storage usage within %g%%, %.1f%% of %s in use", table.MaxUsage, usage, formatBytes(total))
+			if volume, _ := row["volume"].(string); volume != "" {
+				failed += " on " + volume
+			}
+			return failed
+		}
+	}
+	return ""
+}
+
+// checkBlocking checks the lock waits of a blocking check, returning how many
+// sessions are blocked longer than max_wait or "" if none are
+func checkBlocking(table *config.Table, data map[string]interface{}) string {
+	blocked, ok := toFloat(data["blocked_sessions"])
+	if !ok {
+		return fmt.Sprintf("expected a blocked session count, got %v", data["blocked_sessions"])
+	}
+	if blocked == 0 {
+		return ""
+	}
+
+	failed := fmt.Sprintf("%g blocked sessions waiting longer than %v", blocked, table.GetMaxWait())
+	if longest, ok := toFloat(data["longest_wait_seconds"]); ok {
+		failed += fmt.Sprintf(", longest waiting %v", time.Duration(longest*float64(time.Second)).Round(time.Second))
+	}
+	return failed
+}
+
+// checkLongQueries checks the rows of a long_queries check, one per query
+// running longer than max_duration, returning how many there are or "" if
+// there are none
+func checkLongQueries(table *config.Table, data map[string]interface{}) string {
+	rows := rowsOf(data)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	longest := 0.0
+	for _, row := range rows {
+		if seconds, ok := toFloat(row["duration_seconds"]); ok && seconds > longest {
+			longest = seconds
+		}
+	}
+	return fmt.Sprintf("%d queries running longer than %v, longest for %v", len(rows), table.GetMaxDuration(), time.Duration(longest*float64(time.Second)).Round(time.Second))
+}
+
+// formatBytes formats a byte count in binary units
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	unit := 0
+	for bytes >= 1024 && unit < len(units)-1 {
+		bytes /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[unit])
+}
+
+// toTime converts a timestamp value to a time. The drivers return
+// timestamps as RFC 3339 strings; numbers are taken as Unix seconds.
+func toTime(value interface{}) (time.Time, bool) {
+	if s, ok := value.(string); ok {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+	}
+	if seconds, ok := toFloat(value); ok {
+		if _, boolean := value.(bool); !boolean {
+			return time.Unix(int64(seconds), 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compare applies an operator to a column value and an expected value.
+// Numbers, including numeric strings as some drivers return them, compare
+// numerically; other values only compare for equality, as text.
+func compare(actual interface{}, operator string, expected interface{}) bool {
+	a, aNumeric := toFloat(actual)
+	e, eNumeric := toFloat(expected)
+	if aNumeric && eNumeric {
+		switch operator {
+		case "==":
+			return a == e
+		case "!=":
+			return a != e
+		case "<":
+			return a < e
+		case "<=":
+			return a <= e
+		case ">":
+			return a > e
+		case ">=":
+			return a >= e
+		}
+		return false
+	}
+
+	switch operator {
+	case "==":
+		return actual != nil && fmt.Sprint(actual) == fmt.Sprint(expected)
+	case "!=":
+		return actual == nil || fmt.Sprint(actual) != fmt.Sprint(expected)
+	}
+	return false
+}
+
+// toFloat converts a numeric value to float64; booleans count as 1 and 0 so
+// that they match databases storing flags as integers
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// checkConnections returns the status of a connections check given the share
+// of max_connections in use, with the threshold it exceeded
+func checkConnections(table *config.Table, data map[string]interface{}) (database.Status, string) {
+	if table.Check != config.CheckConnections {
+		return database.StatusHealthy, ""
+	}
+
+	usage, ok := toFloat(data["usage_percent"])
+	if !ok {
+		return database.StatusUnhealthy, fmt.Sprintf("expected connection usage, got %v", data["usage_percent"])
+	}
+
+	if table.CriticalUsage > 0 && usage > table.CriticalUsage {
+		return database.StatusUnhealthy, fmt.Sprintf("%v of %v connections in use (%g%%) exceeded critical_usage %g%%", data["connections"], data["max_connections"], usage, table.CriticalUsage)
+	}
+	if table.WarnUsage > 0 && usage > table.WarnUsage {
+		return database.StatusDegraded, fmt.Sprintf("%v of %v connections in use (%g%%) exceeded warn_usage %g%%", data["connections"], data["max_connections"], usage, table.WarnUsage)
+	}
+	return database.StatusHealthy, ""
+}
+
+// checkLatency returns the status of a successful query given its query time
+// and the table's latency thresholds, with the threshold it exceeded
+func checkLatency(table *config.Table, queryTime time.Duration) (database.Status, string) {
+	if critical := table.GetCriticalLatency(); critical > 0 && queryTime > critical {
+		return database.StatusUnhealthy, fmt.Sprintf("query time %v exceeded critical_latency %v", queryTime, critical)
+	}
+	if warn := table.GetWarnLatency(); warn > 0 && queryTime > warn {
+		return database.StatusDegraded, fmt.Sprintf("query time %v exceeded warn_latency %v", queryTime, warn)
+	}
+	return database.StatusHealthy, ""
+}
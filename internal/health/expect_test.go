@@ -0,0 +1,333 @@
+package health
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestCheckExpectations(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	noRows := map[string]interface{}{"row_count": 0}
+	oneRow := map[string]interface{}{"count": int64(42), "state": "ok", "stale": false}
+	twoRows := map[string]interface{}{
+		"results": []map[string]interface{}{
+			{"lag": "1.5"},
+			{"lag": "12"},
+		},
+		"row_count": 2,
+	}
+
+	tests := []struct {
+		name     string
+		table    config.Table
+		data     map[string]interface{}
+		expected string // substring of the failure, "" to pass
+	}{
+		{"no expectations", config.Table{}, noRows, ""},
+		{"min_rows met", config.Table{MinRows: intPtr(1)}, oneRow, ""},
+		{"min_rows unmet", config.Table{MinRows: intPtr(1)}, noRows, "at least 1 rows, got 0"},
+		{"max_rows unmet", config.Table{MaxRows: intPtr(1)}, twoRows, "at most 1 rows, got 2"},
+		{"max_rows zero", config.Table{MaxRows: intPtr(0)}, noRows, ""},
+		{"numeric", config.Table{Expect: config.Expectations{{Column: "count", Operator: ">=", Value: 1}}}, oneRow, ""},
+		{"numeric unmet", config.Table{Expect: config.Expectations{{Column: "count", Operator: "<", Value: 10}}}, oneRow, "count < 10, got 42"},
+		{"string", config.Table{Expect: config.Expectations{{Column: "state", Value: "ok"}}}, oneRow, ""},
+		{"string unmet", config.Table{Expect: config.Expectations{{Column: "state", Operator: "!=", Value: "ok"}}}, oneRow, "state != ok"},
+		{"boolean", config.Table{Expect: config.Expectations{{Column: "stale", Value: false}}}, oneRow, ""},
+		{"missing column", config.Table{Expect: config.Expectations{{Column: "total", Value: 1}}}, oneRow, "column total"},
+		{"no rows", config.Table{Expect: config.Expectations{{Column: "count", Operator: ">", Value: 0}}}, noRows, "got no rows"},
+		{"every row", config.Table{Expect: config.Expectations{{Column: "lag", Operator: "<", Value: 10}}}, twoRows, "lag < 10, got 12"},
+		{"numeric strings", config.Table{Expect: config.Expectations{{Column: "lag", Operator: "<", Value: 60}}}, twoRows, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed := checkExpectations(&tt.table, tt.data)
+			if tt.expected == "" && failed != "" {
+				t.Errorf("Expected the data to pass, got %q", failed)
+			}
+			if tt.expected != "" && !strings.Contains(failed, tt.expected) {
+				t.Errorf("checkExpectations() = %q; expected it to contain %q", failed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckLatency(t *testing.T) {
+	table := &config.Table{
+		WarnLatency:     config.Duration(time.Second),
+		CriticalLatency: config.Duration(10 * time.Second),
+	}
+
+	tests := []struct {
+		queryTime time.Duration
+		table     *config.Table
+		expected  database.Status
+	}{
+		{500 * time.Millisecond, table, database.StatusHealthy},
+		{time.Second, table, database.StatusHealthy},
+		{2 * time.Second, table, database.StatusDegraded},
+		{25 * time.Second, table, database.StatusUnhealthy},
+		{25 * time.Second, &config.Table{}, database.StatusHealthy},
+		{25 * time.Second, &config.Table{WarnLatency: config.Duration(time.Second)}, database.StatusDegraded},
+	}
+
+	for _, tt := range tests {
+		status, exceeded := checkLatency(tt.table, tt.queryTime)
+		if status != tt.expected {
+			t.Errorf("checkLatency(%v) = %s; expected %s", tt.queryTime, status, tt.expected)
+		}
+		if (status == database.StatusHealthy) != (exceeded == "") {
+			t.Errorf("checkLatency(%v) returned status %s with message %q", tt.queryTime, status, exceeded)
+		}
+	}
+}
+
+func TestRowCountCheck(t *testing.T) {
+	minCount, maxCount := int64(1), int64(100)
+	table := &config.Table{Name: "users", Check: config.CheckRowCount, From: "app.users", MinCount: &minCount, MaxCount: &maxCount}
+
+	tests := []struct {
+		dbType   string
+		expected string
+	}{
+		{"mysql", "SELECT COUNT(*) AS count FROM `app`.`users`"},
+		{"postgres", `SELECT COUNT(*) AS count FROM "app"."users"`},
+		{"mssql", "SELECT COUNT(*) AS count FROM [app].[users]"},
+	}
+	for _, tt := range tests {
+		if query := checkQuery(&config.Database{Type: tt.dbType}, table); query != tt.expected {
+			t.Errorf("%s: checkQuery() = %q; expected %q", tt.dbType, query, tt.expected)
+		}
+	}
+
+	if query := checkQuery(&config.Database{Type: "mysql"}, &config.Table{Query: "SELECT 1"}); query != "SELECT 1" {
+		t.Errorf("checkQuery() = %q; expected the configured query", query)
+	}
+
+	for count, expected := range map[int64]string{0: "count >= 1", 50: "", 101: "count <= 100"} {
+		failed := checkExpectations(table, map[string]interface{}{"count": count})
+		if (expected == "") != (failed == "") || !strings.Contains(failed, expected) {
+			t.Errorf("checkExpectations(count %d) = %q; expected %q", count, failed, expected)
+		}
+	}
+}
+
+func TestFreshnessCheck(t *testing.T) {
+	table := &config.Table{Name: "events", Check: config.CheckFreshness, Column: "updated_at", MaxAge: config.Duration(15 * time.Minute)}
+
+	tests := []struct {
+		dbType   string
+		expected string
+	}{
+		{"mysql", "SELECT MAX(`updated_at`) AS newest FROM `events`"},
+		{"postgres", `SELECT MAX("updated_at") AS newest FROM "events"`},
+		{"mssql", "SELECT MAX([updated_at]) AS newest FROM [events]"},
+	}
+	for _, tt := range tests {
+		if query := checkQuery(&config.Database{Type: tt.dbType}, table); query != tt.expected {
+			t.Errorf("%s: checkQuery() = %q; expected %q", tt.dbType, query, tt.expected)
+		}
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	freshness := []struct {
+		name     string
+		newest   interface{}
+		expected string // substring of the failure, "" to pass
+	}{
+		{"fresh", "2024-01-01T11:50:00Z", ""},
+		{"other time zone", "2024-01-01T13:55:00+02:00", ""},
+		{"stale", "2024-01-01T10:00:00Z", "newest is 2h0m0s old"},
+		{"unix seconds", now.Add(-time.Minute).Unix(), ""},
+		{"no rows", nil, "got none"},
+		{"not a timestamp", "yesterday", "expected a timestamp"},
+	}
+	for _, tt := range freshness {
+		t.Run(tt.name, func(t *testing.T) {
+			failed := checkFreshness(table, map[string]interface{}{"newest": tt.newest}, now)
+			if (tt.expected == "") != (failed == "") || !strings.Contains(failed, tt.expected) {
+				t.Errorf("checkFreshness() = %q; expected %q", failed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReplicationLagCheck(t *testing.T) {
+	table := &config.Table{Name: "replica", Check: config.CheckReplicationLag, MaxLag: config.Duration(30 * time.Second)}
+
+	if query := checkQuery(&config.Database{Type: "mysql"}, table); query != "SHOW REPLICA STATUS" {
+		t.Errorf("checkQuery() = %q; expected SHOW REPLICA STATUS", query)
+	}
+	if query := checkQuery(&config.Database{Type: "postgres"}, table); !strings.Contains(query, "pg_last_xact_replay_timestamp()") {
+		t.Errorf("checkQuery() = %q; expected a replay lag query", query)
+	}
+
+	mysqlStatus := func(io, sql string, lag interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"Replica_IO_Running":    io,
+			"Replica_SQL_Running":   sql,
+			"Seconds_Behind_Source": lag,
+			"Last_IO_Error":         "",
+			"Last_SQL_Error":        "",
+		}
+	}
+	stopped := mysqlStatus("Yes", "No", nil)
+	stopped["Last_SQL_Error"] = "Error 'Duplicate entry'"
+
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		expected string // substring of the failure, "" to pass
+	}{
+		{"mysql within lag", mysqlStatus("Yes", "Yes", int64(3)), ""},
+		{"mysql lagging", mysqlStatus("Yes", "Yes", "120"), "replica is 2m0s behind"},
+		{"mysql stopped", stopped, "SQL thread No: Error 'Duplicate entry'"},
+		{"mysql not a replica", map[string]interface{}{"row_count": 0}, "not configured"},
+		{"mysql multi-source", map[string]interface{}{
+			"results":   []map[string]interface{}{mysqlStatus("Yes", "Yes", int64(1)), mysqlStatus("Yes", "Yes", int64(45))},
+			"row_count": 2,
+		}, "45s behind"},
+		{"postgres within lag", map[string]interface{}{"in_recovery": true, "lag_seconds": "1.25", "receiver_status": "streaming"}, ""},
+		{"postgres lagging", map[string]interface{}{"in_recovery": true, "lag_seconds": "90.5", "receiver_status": "streaming"}, "behind"},
+		{"postgres primary", map[string]interface{}{"in_recovery": false, "lag_seconds": nil, "receiver_status": nil}, "not in recovery"},
+		{"postgres not streaming", map[string]interface{}{"in_recovery": true, "lag_seconds": "0", "receiver_status": nil}, "WAL receiver"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed := checkReplicationLag(table, tt.data)
+			if (tt.expected == "") != (failed == "") || !strings.Contains(failed, tt.expected) {
+				t.Errorf("checkReplicationLag() = %q; expected %q", failed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStorageCheck(t *testing.T) {
+	volume := func(name string, total, available int64) map[string]interface{} {
+		return map[string]interface{}{"volume": name, "used_bytes": int64(1 << 30), "total_bytes": total, "available_bytes": available}
+	}
+
+	tests := []struct {
+		name       string
+		capacityMB int64
+		data       map[string]interface{}
+		expected   string // substring of the failure, "" to pass
+	}{
+		{"capacity within", 1024, map[string]interface{}{"used_bytes": "536870912"}, ""},
+		{"capacity exceeded", 1024, map[string]interface{}{"used_bytes": int64(1000 << 20)}, "97.7% of 1.0 GiB in use"},
+		{"volume within", 0, volume("/var/opt/mssql/", 100<<30, 50<<30), ""},
+		{"volume exceeded", 0, map[string]interface{}{
+			"results":   []map[string]interface{}{volume("C:\\", 100<<30, 50<<30), volume("D:\\", 100<<30, 5<<30)},
+			"row_count": 2,
+		}, "95.0% of 100.0 GiB in use on D:\\"},
+		{"volume unknown", 0, map[string]interface{}{"used_bytes": int64(1 << 30)}, "expected the size of volume"},
+		{"no rows", 0, map[string]interface{}{"row_count": 0}, "got no rows"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &config.Table{Name: "storage", Check: config.CheckStorage, MaxUsage: 90, CapacityMB: tt.capacityMB}
+			failed := checkStorage(table, tt.data)
+			if (tt.expected == "") != (failed == "") || !strings.Contains(failed, tt.expected) {
+				t.Errorf("checkStorage() = %q; expected %q", failed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConnectionsCheck(t *testing.T) {
+	table := &config.Table{Name: "connections", Check: config.CheckConnections, WarnUsage: 80, CriticalUsage: 95}
+
+	if query := checkQuery(&config.Database{Type: "mssql"}, table); !strings.Contains(query, "sys.dm_exec_sessions") {
+		t.Errorf("checkQuery() = %q; expected a sys.dm_exec_sessions query", query)
+	}
+
+	tests := []struct {
+		name     string
+		usage    interface{}
+		expected database.Status
+	}{
+		{"below warn", "42.0", database.StatusHealthy},
+		{"above warn", 85.5, database.StatusDegraded},
+		{"above critical", "97.0", database.StatusUnhealthy},
+		{"missing", nil, database.StatusUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]interface{}{"connections": int64(90), "max_connections": int64(100), "usage_percent": tt.usage}
+			status, message := checkConnections(table, data)
+			if status != tt.expected {
+				t.Errorf("checkConnections() = %s (%q); expected %s", status, message, tt.expected)
+			}
+		})
+	}
+
+	if status, _ := checkConnections(&config.Table{Name: "users", Query: "SELECT 1"}, map[string]interface{}{}); status != database.StatusHealthy {
+		t.Errorf("checkConnections() = %s for a query check; expected healthy", status)
+	}
+}
+
+func TestBlockingCheck(t *testing.T) {
+	table := &config.Table{Name: "blocking", Check: config.CheckBlocking, MaxWait: config.Duration(30 * time.Second)}
+
+	if query := checkQuery(&config.Database{Type: "mssql"}, table); !strings.Contains(query, "wait_time > 30000") {
+		t.Errorf("checkQuery() = %q; expected a 30000ms threshold", query)
+	}
+	if query := checkQuery(&config.Database{Type: "postgres"}, table); !strings.Contains(query, "wait_seconds > 30)") {
+		t.Errorf("checkQuery() = %q; expected a 30s threshold", query)
+	}
+
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		expected string // substring of the failure, "" to pass
+	}{
+		{"none blocked", map[string]interface{}{"blocked_sessions": int64(0), "longest_wait_seconds": "0"}, ""},
+		{"short waits", map[string]interface{}{"blocked_sessions": int64(0), "longest_wait_seconds": "12.5"}, ""},
+		{"blocked", map[string]interface{}{"blocked_sessions": int64(3), "longest_wait_seconds": "95.2"}, "3 blocked sessions waiting longer than 30s, longest waiting 1m35s"},
+		{"missing", map[string]interface{}{}, "expected a blocked session count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed := checkBlocking(table, tt.data)
+			if (tt.expected == "") != (failed == "") || !strings.Contains(failed, tt.expected) {
+				t.Errorf("checkBlocking() = %q; expected %q", failed, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLongQueriesCheck(t *testing.T) {
+	table := &config.Table{Name: "long_queries", Check: config.CheckLongQueries, MaxDuration: config.Duration(5 * time.Minute)}
+
+	if query := checkQuery(&config.Database{Type: "mysql"}, table); !strings.Contains(query, "TIME > 300") || strings.Contains(query, "INFO") {
+		t.Errorf("checkQuery() = %q; expected a 300s threshold without query text", query)
+	}
+	table.ShowQueries = true
+	if query := checkQuery(&config.Database{Type: "mssql"}, table); !strings.Contains(query, "total_elapsed_time > 300000") || !strings.Contains(query, "sys.dm_exec_sql_text") {
+		t.Errorf("checkQuery() = %q; expected a 300000ms threshold with query text", query)
+	}
+
+	if failed := checkLongQueries(table, map[string]interface{}{"row_count": 0}); failed != "" {
+		t.Errorf("checkLongQueries() = %q for no queries; expected a pass", failed)
+	}
+
+	data := map[string]interface{}{
+		"results": []map[string]interface{}{
+			{"session_id": int64(12), "duration_seconds": "720.5", "query": "UPDATE orders SET"},
+			{"session_id": int64(40), "duration_seconds": "310.0", "query": "SELECT * FROM events"},
+		},
+		"row_count": 2,
+	}
+	expected := "2 queries running longer than 5m0s, longest for 12m1s"
+	if failed := checkLongQueries(table, data); failed != expected {
+		t.Errorf("checkLongQueries() = %q; expected %q", failed, expected)
+	}
+}
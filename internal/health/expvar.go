@@ -0,0 +1,76 @@
+package health
+
+import (
+	"expvar"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// Internal counters published under "gsqlhealth" on /debug/vars. They are
+// package level because expvar names can only be registered once per process.
+var (
+	expvarStats = expvar.NewMap("gsqlhealth")
+
+	checksRun       = new(expvar.Int) // health check queries executed
+	checkFailures   = new(expvar.Int) // health check queries that failed
+	cacheSize       = new(expvar.Int) // cached results held by the scheduler
+	checkPanics     = new(expvar.Int) // panics recovered while running checks
+	slowChecks      = new(expvar.Int) // checks slower than their warn_latency
+	latencyFailures = new(expvar.Int) // checks slower than their critical_latency
+	anomalies       = new(expvar.Int) // checks far slower than their query time baseline
+	warningFailures = new(expvar.Int) // failed checks of severity warning
+	infoFailures    = new(expvar.Int) // failed checks of severity info
+	stuckChecks     = new(expvar.Int) // checks abandoned after running far past their deadline
+	abandonedChecks = new(expvar.Int) // abandoned checks that have not returned yet
+	revalidations   = new(expvar.Int) // overdue checks run because their cached result was read
+	notifierQueue   = new(expvar.Int) // alerts waiting to be sent to notifiers
+
+	connectionUsage = new(expvar.Map) // last usage_percent of connections checks, by database/table
+)
+
+func init() {
+	expvarStats.Set("checks_run", checksRun)
+	expvarStats.Set("check_failures", checkFailures)
+	expvarStats.Set("cache_size", cacheSize)
+	expvarStats.Set("check_panics", checkPanics)
+	expvarStats.Set("slow_checks", slowChecks)
+	expvarStats.Set("latency_failures", latencyFailures)
+	expvarStats.Set("latency_anomalies", anomalies)
+	expvarStats.Set("warning_failures", warningFailures)
+	expvarStats.Set("info_failures", infoFailures)
+	expvarStats.Set("stuck_checks", stuckChecks)
+	expvarStats.Set("abandoned_checks", abandonedChecks)
+	expvarStats.Set("revalidations", revalidations)
+	expvarStats.Set("notifier_queue_depth", notifierQueue)
+	expvarStats.Set("connection_usage", connectionUsage)
+}
+
+// SetNotifierQueueDepth publishes the number of alerts waiting to be sent to
+// notifiers
+func SetNotifierQueueDepth(depth int) {
+	notifierQueue.Set(int64(depth))
+}
+
+// recordSeverityFailure counts a failed check of severity warning or info
+func recordSeverityFailure(result *database.HealthResult, err error) {
+	if !isFailing(result, err) || result == nil {
+		return
+	}
+	switch result.Severity {
+	case config.SeverityWarning:
+		warningFailures.Add(1)
+	case config.SeverityInfo:
+		infoFailures.Add(1)
+	}
+}
+
+// recordConnectionUsage publishes the connection usage a connections check
+// returned
+func recordConnectionUsage(databaseName, tableName string, data map[string]interface{}) {
+	if usage, ok := toFloat(data["usage_percent"]); ok {
+		value := new(expvar.Float)
+		value.Set(usage)
+		connectionUsage.Set(databaseName+"/"+tableName, value)
+	}
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// flapState tracks the published status changes of a check
+type flapState struct {
+	last     database.Status // status of the last published result, as checked
+	changes  []time.Time     // status changes within the window, oldest first
+	flapping bool
+}
+
+// detectFlapping records the status of a result about to be published and
+// returns what to publish instead: while the check is flapping, a copy in the
+// flapping status. A check starts flapping once its status changed at least
+// high_threshold times within the window and stops at low_threshold or fewer,
+// so status change events stop while it bounces.
+func (s *Service) detectFlapping(databaseName, tableName string, result *database.HealthResult, err error, now time.Time) (*database.HealthResult, error) {
+	if !s.config.Flapping.Enabled {
+		return result, err
+	}
+
+	status := database.StatusError
+	message := ""
+	if result != nil {
+		status = result.Status
+		message = result.Error
+	} else if err != nil {
+		message = err.Error()
+	}
+
+	key := databaseName + "/" + tableName
+	window := s.config.Flapping.GetWindow()
+
+	s.flapMu.Lock()
+	defer s.flapMu.Unlock()
+
+	state, exists := s.flaps[key]
+	if !exists {
+		state = &flapState{last: status}
+		s.flaps[key] = state
+	} else if status != state.last {
+		state.changes = append(state.changes, now)
+		state.last = status
+	}
+
+	cutoff := now.Add(-window)
+	expired := 0
+	for expired < len(state.changes) && state.changes[expired].Before(cutoff) {
+		expired++
+	}
+	state.changes = state.changes[expired:]
+
+	switch {
+	case !state.flapping && len(state.changes) >= s.config.Flapping.GetHighThreshold():
+		state.flapping = true
+		s.logger.Warn("Health check started flapping",
+			"database", databaseName,
+			"table", tableName,
+			"changes", len(state.changes),
+			"window", window)
+	case state.flapping && len(state.changes) <= s.config.Flapping.GetLowThreshold():
+		state.flapping = false
+		s.logger.Info("Health check stopped flapping",
+			"database", databaseName,
+			"table", tableName,
+			"status", status)
+	}
+
+	if !state.flapping {
+		return result, err
+	}
+
+	var flapping database.HealthResult
+	if result != nil {
+		flapping = *result
+	} else {
+		flapping = database.HealthResult{
+			DatabaseName: databaseName,
+			TableName:    tableName,
+			Labels:       s.GetTableLabels(databaseName, tableName),
+			Severity:     s.GetTableSeverity(databaseName, tableName),
+			Timestamp:    now,
+		}
+	}
+	flapping.Status = database.StatusFlapping
+	flapping.Error = fmt.Sprintf("%d status changes within %v, currently %s", len(state.changes), window, status)
+	if message != "" {
+		flapping.Error += ": " + message
+	}
+	return &flapping, nil
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestDetectFlapping(t *testing.T) {
+	cfg := &config.Config{Flapping: config.Flapping{
+		Enabled:       true,
+		Window:        config.Duration(time.Hour),
+		HighThreshold: 4,
+		LowThreshold:  1,
+	}}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	check := func(minutes int, status database.Status) database.Status {
+		t.Helper()
+		result := &database.HealthResult{DatabaseName: "db", TableName: "replica", Status: status, Timestamp: start}
+		published, err := service.detectFlapping("db", "replica", result, nil, start.Add(time.Duration(minutes)*time.Minute))
+		if err != nil {
+			t.Fatalf("detectFlapping() error = %v", err)
+		}
+		return published.Status
+	}
+
+	// Three changes stay below the high threshold
+	for i, status := range []database.Status{"healthy", "unhealthy", "healthy", "unhealthy"} {
+		if published := check(i, status); published != status {
+			t.Fatalf("Change %d: published %s; expected %s", i, published, status)
+		}
+	}
+
+	// The fourth change starts flapping, which holds while the check bounces
+	if published := check(4, "healthy"); published != database.StatusFlapping {
+		t.Fatalf("Published %s; expected flapping", published)
+	}
+	if published := check(5, "unhealthy"); published != database.StatusFlapping {
+		t.Fatalf("Published %s; expected flapping", published)
+	}
+
+	// Once changes age out of the window the check stabilizes
+	if published := check(64, "unhealthy"); published != database.StatusFlapping {
+		t.Fatalf("Published %s; expected flapping with 2 changes in the window", published)
+	}
+	if published := check(66, "unhealthy"); published != database.StatusUnhealthy {
+		t.Fatalf("Published %s; expected unhealthy once stable", published)
+	}
+
+	// Errors without a result are published as flapping results too
+	connectionErr := errors.New("connection refused")
+	service.detectFlapping("db", "primary", nil, connectionErr, start)
+	service.detectFlapping("db", "primary", &database.HealthResult{Status: database.StatusHealthy}, nil, start)
+	service.detectFlapping("db", "primary", nil, connectionErr, start)
+	service.detectFlapping("db", "primary", &database.HealthResult{Status: database.StatusHealthy}, nil, start)
+	published, err := service.detectFlapping("db", "primary", nil, connectionErr, start)
+	if err != nil || published == nil || published.Status != database.StatusFlapping {
+		t.Fatalf("detectFlapping() = %v, %v; expected a flapping result", published, err)
+	}
+	if published.DatabaseName != "db" || published.TableName != "primary" {
+		t.Errorf("Flapping result is for %s/%s; expected db/primary", published.DatabaseName, published.TableName)
+	}
+}
+
+func TestDetectFlappingDisabled(t *testing.T) {
+	service := NewService(&config.Config{}, nil)
+	result := &database.HealthResult{Status: database.StatusHealthy}
+	for i := 0; i < 20; i++ {
+		result.Status = []database.Status{"healthy", "unhealthy"}[i%2]
+		if published, _ := service.detectFlapping("db", "replica", result, nil, time.Now()); published != result {
+			t.Fatalf("Expected results to be published unchanged without flap detection")
+		}
+	}
+}
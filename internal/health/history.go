@@ -0,0 +1,113 @@
+package health
+
+import (
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// HistoryEntry is a past result of a scheduled check
+type HistoryEntry struct {
+	Status     database.Status `json:"status"`
+	StatusCode int             `json:"status_code"`
+	Error      string          `json:"error,omitempty"`
+	QueryTime  time.Duration   `json:"query_time"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Plan       string          `json:"plan,omitempty"`
+	Instance   string          `json:"instance,omitempty"` // instance that recorded the result, with a database backend
+}
+
+// checkHistory is a fixed-size ring of the most recent entries of a check
+type checkHistory struct {
+	entries []HistoryEntry
+	next    int // index the next entry is written to
+	full    bool
+}
+
+// add appends an entry, overwriting the oldest once the ring is full
+func (h *checkHistory) add(entry HistoryEntry) {
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// newest returns up to limit entries at or after since, newest first; a
+// negative limit returns all of them
+func (h *checkHistory) newest(since time.Time, limit int) []HistoryEntry {
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+
+	entries := []HistoryEntry{}
+	for i := 1; i <= count && (limit < 0 || len(entries) < limit); i++ {
+		entry := h.entries[(h.next-i+len(h.entries))%len(h.entries)]
+		if entry.Timestamp.Before(since) {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// recordHistory appends the outcome of a scheduled check to its history
+func (s *Service) recordHistory(databaseName, tableName string, result *database.HealthResult, err error) {
+	entry := HistoryEntry{Timestamp: time.Now()}
+	switch {
+	case result != nil:
+		entry.Status = result.Status
+		entry.Error = result.Error
+		entry.QueryTime = result.QueryTime
+		entry.Timestamp = result.Timestamp
+		entry.Plan = result.Plan
+	case err != nil:
+		entry.Status = database.StatusError
+		entry.Error = err.Error()
+	}
+	entry.StatusCode = entry.Status.Code()
+
+	if s.historyStore != nil {
+		if err := s.historyStore.Add(databaseName, tableName, entry); err != nil {
+			s.logger.Error("Failed to record check history",
+				"database", databaseName,
+				"table", tableName,
+				"error", err)
+		}
+		return
+	}
+
+	key := databaseName + "/" + tableName
+
+	s.historyMu.Lock()
+	defer s.historyMu.Unlock()
+
+	history, exists := s.history[key]
+	if !exists {
+		history = &checkHistory{entries: make([]HistoryEntry, s.config.History.GetSize())}
+		s.history[key] = history
+	}
+	history.add(entry)
+}
+
+// GetHistory returns up to limit past results of a check recorded at or after
+// since, newest first; a negative limit returns all of them
+func (s *Service) GetHistory(databaseName, tableName string, since time.Time, limit int) ([]HistoryEntry, error) {
+	if !s.hasTable(databaseName, tableName) {
+		return nil, NewNotFoundError(databaseName, tableName, "table not found in database configuration")
+	}
+
+	if s.historyStore != nil {
+		return s.historyStore.Newest(databaseName, tableName, since, limit)
+	}
+
+	s.historyMu.RLock()
+	defer s.historyMu.RUnlock()
+
+	history, exists := s.history[databaseName+"/"+tableName]
+	if !exists {
+		return []HistoryEntry{}, nil
+	}
+	return history.newest(since, limit), nil
+}
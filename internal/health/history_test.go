@@ -0,0 +1,64 @@
+package health
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestHistory(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{{Name: "table1"}}}},
+		History:   config.History{Size: 3},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 4; i++ {
+		result := &database.HealthResult{Status: database.StatusHealthy, QueryTime: time.Duration(i), Timestamp: start.Add(time.Duration(i) * time.Minute)}
+		service.recordHistory("test", "table1", result, nil)
+	}
+	service.recordHistory("test", "table1", nil, errors.New("database connection failed"))
+
+	tests := []struct {
+		name          string
+		since         time.Time
+		limit         int
+		expectedLen   int
+		expectedFirst database.Status
+	}{
+		{"bounded by size", time.Time{}, 100, 3, database.StatusError},
+		{"limited", time.Time{}, 2, 2, database.StatusError},
+		{"unlimited", time.Time{}, -1, 3, database.StatusError},
+		{"since", start.Add(150 * time.Second), 100, 2, database.StatusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := service.GetHistory("test", "table1", tt.since, tt.limit)
+			if err != nil {
+				t.Fatalf("GetHistory() error = %v", err)
+			}
+			if len(entries) != tt.expectedLen {
+				t.Fatalf("len(entries) = %d; expected %d", len(entries), tt.expectedLen)
+			}
+			if entries[0].Status != tt.expectedFirst {
+				t.Errorf("newest status = %q; expected %q", entries[0].Status, tt.expectedFirst)
+			}
+			for i := 1; i < len(entries); i++ {
+				if entries[i].Timestamp.After(entries[i-1].Timestamp) {
+					t.Errorf("entries not newest first: %v after %v", entries[i].Timestamp, entries[i-1].Timestamp)
+				}
+			}
+		})
+	}
+
+	if entries, err := service.GetHistory("test", "missing", time.Time{}, 100); err == nil {
+		t.Errorf("Expected an error for an unknown table, got %v", entries)
+	}
+}
@@ -0,0 +1,286 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// historyPruneInterval is how often results past the retention are deleted
+const historyPruneInterval = time.Hour
+
+// HistoryStore keeps the results of scheduled checks outside of the service,
+// in place of the in-memory history. Stores are bounded by age rather than by
+// a number of results per check, and may be shared by several instances.
+type HistoryStore interface {
+	// Add records a result of a check
+	Add(databaseName, tableName string, entry HistoryEntry) error
+	// Newest returns up to limit results of a check at or after since,
+	// newest first; a negative limit returns all of them
+	Newest(databaseName, tableName string, since time.Time, limit int) ([]HistoryEntry, error)
+	// Prune deletes the results recorded before cutoff, returning how many
+	Prune(cutoff time.Time) (int64, error)
+	// Forget deletes the results of a database's checks
+	Forget(databaseName string) error
+	// Close releases the store
+	Close() error
+}
+
+// historyDialect describes how a SQL history store talks to its database
+type historyDialect struct {
+	driver string
+	schema []string // statements creating the table, with %s for its name
+	// nativeTime is set when timestamps are stored in a time column rather
+	// than as Unix nanoseconds
+	nativeTime bool
+	// numbered is set when placeholders are numbered ($1) rather than ?
+	numbered bool
+}
+
+var historyDialects = map[string]historyDialect{
+	config.HistoryBackendSQLite: {
+		driver: "sqlite3",
+		schema: []string{
+			`CREATE TABLE IF NOT EXISTS %[1]s (
+				instance      TEXT NOT NULL,
+				database_name TEXT NOT NULL,
+				table_name    TEXT NOT NULL,
+				status        TEXT NOT NULL,
+				status_code   INTEGER NOT NULL,
+				error         TEXT NOT NULL,
+				query_time_ns INTEGER NOT NULL,
+				checked_at    INTEGER NOT NULL,
+				plan          TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS %[1]s_check ON %[1]s (database_name, table_name, checked_at)`,
+			`CREATE INDEX IF NOT EXISTS %[1]s_checked_at ON %[1]s (checked_at)`,
+		},
+	},
+	config.HistoryBackendPostgres: {
+		driver: "postgres",
+		schema: []string{
+			`CREATE TABLE IF NOT EXISTS %[1]s (
+				instance      TEXT NOT NULL,
+				database_name TEXT NOT NULL,
+				table_name    TEXT NOT NULL,
+				status        TEXT NOT NULL,
+				status_code   INTEGER NOT NULL,
+				error         TEXT NOT NULL,
+				query_time_ns BIGINT NOT NULL,
+				checked_at    TIMESTAMPTZ NOT NULL,
+				plan          TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS %[1]s_check ON %[1]s (database_name, table_name, checked_at)`,
+			`CREATE INDEX IF NOT EXISTS %[1]s_checked_at ON %[1]s (checked_at)`,
+		},
+		nativeTime: true,
+		numbered:   true,
+	},
+	config.HistoryBackendMySQL: {
+		driver: "mysql",
+		schema: []string{
+			`CREATE TABLE IF NOT EXISTS %[1]s (
+				instance      VARCHAR(255) NOT NULL,
+				database_name VARCHAR(255) NOT NULL,
+				table_name    VARCHAR(255) NOT NULL,
+				status        VARCHAR(32) NOT NULL,
+				status_code   INT NOT NULL,
+				error         TEXT NOT NULL,
+				query_time_ns BIGINT NOT NULL,
+				checked_at    DATETIME(6) NOT NULL,
+				plan          TEXT NOT NULL,
+				INDEX %[1]s_check (database_name, table_name, checked_at),
+				INDEX %[1]s_checked_at (checked_at)
+			)`,
+		},
+		nativeTime: true,
+	},
+}
+
+// sqlHistoryStore is a HistoryStore in a SQL database: an embedded SQLite
+// file, or an external PostgreSQL or MySQL database
+type sqlHistoryStore struct {
+	db       *sql.DB
+	dialect  historyDialect
+	table    string
+	instance string
+}
+
+// openHistoryStore opens the store of the configured history backend,
+// creating its table if needed
+func openHistoryStore(cfg *config.History) (HistoryStore, error) {
+	dialect, supported := historyDialects[cfg.GetBackend()]
+	if !supported {
+		return nil, fmt.Errorf("unsupported history backend %q", cfg.GetBackend())
+	}
+
+	dsn := cfg.DSN
+	switch cfg.GetBackend() {
+	case config.HistoryBackendSQLite:
+		dsn = "file:" + cfg.Path + "?_journal_mode=WAL&_busy_timeout=5000"
+	case config.HistoryBackendMySQL:
+		// DATETIME columns are only scanned into time.Time with parseTime
+		mysqlConfig, err := mysql.ParseDSN(cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dsn: %w", err)
+		}
+		mysqlConfig.ParseTime = true
+		dsn = mysqlConfig.FormatDSN()
+	}
+
+	db, err := sql.Open(dialect.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.GetBackend() == config.HistoryBackendSQLite {
+		// SQLite allows a single writer; one connection avoids busy errors
+		db.SetMaxOpenConns(1)
+	}
+
+	for _, statement := range dialect.schema {
+		if _, err := db.Exec(fmt.Sprintf(statement, cfg.GetTable())); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create history table: %w", err)
+		}
+	}
+
+	return &sqlHistoryStore{
+		db:       db,
+		dialect:  dialect,
+		table:    cfg.GetTable(),
+		instance: cfg.GetInstance(),
+	}, nil
+}
+
+// bind numbers the ? placeholders of a statement for dialects that need it
+func (h *sqlHistoryStore) bind(statement string) string {
+	if !h.dialect.numbered {
+		return statement
+	}
+
+	var bound strings.Builder
+	n := 0
+	for _, r := range statement {
+		if r == '?' {
+			n++
+			bound.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		bound.WriteRune(r)
+	}
+	return bound.String()
+}
+
+// timeValue returns the value t is stored as
+func (h *sqlHistoryStore) timeValue(t time.Time) any {
+	if h.dialect.nativeTime {
+		return t.UTC()
+	}
+	return t.UnixNano()
+}
+
+// Add implements HistoryStore
+func (h *sqlHistoryStore) Add(databaseName, tableName string, entry HistoryEntry) error {
+	_, err := h.db.Exec(h.bind(`INSERT INTO `+h.table+` (instance, database_name, table_name, status, status_code, error, query_time_ns, checked_at, plan) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		h.instance, databaseName, tableName, string(entry.Status), entry.StatusCode, entry.Error, int64(entry.QueryTime), h.timeValue(entry.Timestamp), entry.Plan)
+	return err
+}
+
+// Newest implements HistoryStore
+func (h *sqlHistoryStore) Newest(databaseName, tableName string, since time.Time, limit int) ([]HistoryEntry, error) {
+	query := `SELECT instance, status, error, query_time_ns, checked_at, plan FROM ` + h.table + ` WHERE database_name = ? AND table_name = ?`
+	args := []any{databaseName, tableName}
+	// The zero time is out of the range of some of the databases
+	if !since.IsZero() {
+		query += ` AND checked_at >= ?`
+		args = append(args, h.timeValue(since))
+	}
+	query += ` ORDER BY checked_at DESC`
+	if limit >= 0 {
+		query += ` LIMIT ` + strconv.Itoa(limit)
+	}
+
+	rows, err := h.db.Query(h.bind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []HistoryEntry{}
+	for rows.Next() {
+		var entry HistoryEntry
+		var status string
+		var queryTime int64
+		var checkedAt any
+		if err := rows.Scan(&entry.Instance, &status, &entry.Error, &queryTime, &checkedAt, &entry.Plan); err != nil {
+			return nil, err
+		}
+
+		entry.Status = database.Status(status)
+		entry.StatusCode = entry.Status.Code()
+		entry.QueryTime = time.Duration(queryTime)
+		switch checkedAt := checkedAt.(type) {
+		case time.Time:
+			entry.Timestamp = checkedAt.Local()
+		case int64:
+			entry.Timestamp = time.Unix(0, checkedAt)
+		default:
+			return nil, fmt.Errorf("unexpected checked_at value %T", checkedAt)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Prune implements HistoryStore
+func (h *sqlHistoryStore) Prune(cutoff time.Time) (int64, error) {
+	result, err := h.db.Exec(h.bind(`DELETE FROM `+h.table+` WHERE checked_at < ?`), h.timeValue(cutoff))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Forget implements HistoryStore
+func (h *sqlHistoryStore) Forget(databaseName string) error {
+	_, err := h.db.Exec(h.bind(`DELETE FROM `+h.table+` WHERE database_name = ?`), databaseName)
+	return err
+}
+
+// Close implements HistoryStore
+func (h *sqlHistoryStore) Close() error {
+	return h.db.Close()
+}
+
+// pruneHistory deletes results past the retention, on start and then every
+// historyPruneInterval, until ctx is done
+func (s *Service) pruneHistory(ctx context.Context) {
+	ticker := time.NewTicker(historyPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		retention := s.config.History.GetRetention()
+		pruned, err := s.historyStore.Prune(time.Now().Add(-retention))
+		if err != nil {
+			s.logger.Error("Failed to prune check history", "error", err)
+		} else if pruned > 0 {
+			s.logger.Debug("Pruned check history", "results", pruned, "retention", retention)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestHistoryStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{{Name: "table1"}}}},
+		History:   config.History{Size: 1, Backend: config.HistoryBackendSQLite, Path: path, Instance: "instance-1"},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	historyStore, err := openHistoryStore(&cfg.History)
+	if err != nil {
+		t.Fatalf("openHistoryStore() error = %v", err)
+	}
+	service.historyStore = historyStore
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 4; i++ {
+		result := &database.HealthResult{Status: database.StatusHealthy, QueryTime: time.Millisecond, Timestamp: start.Add(time.Duration(i) * time.Minute)}
+		service.recordHistory("test", "table1", result, nil)
+	}
+	service.recordHistory("test", "table1", nil, errors.New("database connection failed"))
+
+	// The size of in-memory history does not bound the database
+	entries, err := service.GetHistory("test", "table1", time.Time{}, 100)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("len(entries) = %d; expected 5", len(entries))
+	}
+	if entries[0].Status != database.StatusError || entries[0].StatusCode != database.StatusError.Code() || entries[0].Error != "database connection failed" {
+		t.Errorf("newest entry = %+v; expected the error", entries[0])
+	}
+	if entries[0].Instance != "instance-1" {
+		t.Errorf("instance = %q; expected instance-1", entries[0].Instance)
+	}
+	if entries[1].QueryTime != time.Millisecond || !entries[1].Timestamp.Equal(start.Add(3*time.Minute)) {
+		t.Errorf("entries[1] = %+v; expected the last healthy result", entries[1])
+	}
+
+	if entries, _ := service.GetHistory("test", "table1", start.Add(150*time.Second), 100); len(entries) != 2 {
+		t.Errorf("len(entries since) = %d; expected 2", len(entries))
+	}
+
+	report, err := service.GetTableSLA("test", "table1", start.Add(-time.Hour), time.Now())
+	if err != nil {
+		t.Fatalf("GetTableSLA() error = %v", err)
+	}
+	if report.Samples != 5 || report.Incidents != 1 {
+		t.Errorf("report = %+v; expected 5 samples and 1 incident", report)
+	}
+
+	// History outlives the service
+	historyStore.Close()
+	if service.historyStore, err = openHistoryStore(&cfg.History); err != nil {
+		t.Fatalf("openHistoryStore() error = %v", err)
+	}
+	defer service.historyStore.Close()
+
+	pruned, err := service.historyStore.Prune(start.Add(90 * time.Second))
+	if err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	if pruned != 2 {
+		t.Errorf("pruned = %d; expected 2", pruned)
+	}
+	if entries, _ := service.GetHistory("test", "table1", time.Time{}, 100); len(entries) != 3 {
+		t.Errorf("len(entries) after pruning = %d; expected 3", len(entries))
+	}
+
+	service.forgetChecks("test")
+	if entries, _ := service.GetHistory("test", "table1", time.Time{}, 100); len(entries) != 0 {
+		t.Errorf("len(entries) after forgetting = %d; expected 0", len(entries))
+	}
+}
+
+func TestHistoryStoreBind(t *testing.T) {
+	statement := `DELETE FROM history WHERE database_name = ? AND checked_at < ?`
+
+	postgres := &sqlHistoryStore{dialect: historyDialects[config.HistoryBackendPostgres]}
+	if got := postgres.bind(statement); got != `DELETE FROM history WHERE database_name = $1 AND checked_at < $2` {
+		t.Errorf("postgres bind() = %q", got)
+	}
+
+	mysql := &sqlHistoryStore{dialect: historyDialects[config.HistoryBackendMySQL]}
+	if got := mysql.bind(statement); got != statement {
+		t.Errorf("mysql bind() = %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package health
+
+import (
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/cron"
+)
+
+// maintenanceWindow is a parsed config.MaintenanceWindow
+type maintenanceWindow struct {
+	schedule *cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+// newMaintenanceWindows parses validated maintenance window configurations,
+// skipping any that fail to parse
+func newMaintenanceWindows(windows []config.MaintenanceWindow) []maintenanceWindow {
+	var parsed []maintenanceWindow
+	for _, window := range windows {
+		schedule, err := cron.Parse(window.Schedule)
+		if err != nil {
+			continue
+		}
+		location, err := window.GetLocation()
+		if err != nil {
+			continue
+		}
+
+		parsed = append(parsed, maintenanceWindow{
+			schedule: schedule,
+			duration: window.GetDuration(),
+			location: location,
+		})
+	}
+	return parsed
+}
+
+// active reports whether t falls within a window started by the schedule
+func (w maintenanceWindow) active(t time.Time) bool {
+	t = t.In(w.location)
+	_, started := w.schedule.Prev(t, t.Add(-w.duration))
+	return started
+}
+
+// InMaintenance reports whether a database is inside a global or database
+// maintenance window at time t
+func (s *Service) InMaintenance(databaseName string, t time.Time) bool {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+
+	for _, window := range s.maintenance[databaseName] {
+		if window.active(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// isActive reports whether t falls within the active hours of a check;
+// checks without active hours are always active
+func (s *Service) isActive(databaseName, tableName string, t time.Time) bool {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+
+	hours, restricted := s.activeHours[databaseName+"/"+tableName]
+	return !restricted || hours.Contains(t)
+}
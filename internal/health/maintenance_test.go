@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestInMaintenance(t *testing.T) {
+	cfg := &config.Config{
+		MaintenanceWindows: []config.MaintenanceWindow{
+			// Every Sunday 03:00-04:00 UTC
+			{Schedule: "0 3 * * 0", Duration: config.Duration(3600 * time.Second)},
+		},
+		Databases: []config.Database{
+			{
+				Name: "nightly",
+				MaintenanceWindows: []config.MaintenanceWindow{
+					// Every night 23:30-00:30 in New York
+					{Schedule: "30 23 * * *", Duration: config.Duration(3600 * time.Second), Timezone: "America/New_York"},
+				},
+			},
+			{Name: "other"},
+		},
+	}
+
+	service := NewService(cfg, nil)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		database string
+		time     time.Time
+		expected bool
+	}{
+		{"global window start", "other", time.Date(2024, 1, 14, 3, 0, 0, 0, time.UTC), true},
+		{"global window end", "other", time.Date(2024, 1, 14, 3, 59, 59, 0, time.UTC), true},
+		{"after global window", "other", time.Date(2024, 1, 14, 4, 0, 0, 0, time.UTC), false},
+		{"global window wrong day", "other", time.Date(2024, 1, 15, 3, 30, 0, 0, time.UTC), false},
+		{"global window applies to all databases", "nightly", time.Date(2024, 1, 14, 3, 30, 0, 0, time.UTC), true},
+		{"database window across midnight", "nightly", time.Date(2024, 1, 16, 0, 15, 0, 0, newYork), true},
+		{"database window only for its database", "other", time.Date(2024, 1, 16, 0, 15, 0, 0, newYork), false},
+		{"before database window", "nightly", time.Date(2024, 1, 15, 23, 29, 0, 0, newYork), false},
+		{"unknown database", "missing", time.Date(2024, 1, 14, 3, 30, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if active := service.InMaintenance(tt.database, tt.time); active != tt.expected {
+				t.Errorf("InMaintenance(%s, %v) = %v; expected %v", tt.database, tt.time, active, tt.expected)
+			}
+		})
+	}
+}
+
+func TestActiveHours(t *testing.T) {
+	// A window starting an hour from now leaves the current time outside it
+	start := time.Now().UTC().Add(time.Hour)
+	offHours := start.Format("15") + ":00-" + start.Add(time.Hour).Format("15") + ":00"
+
+	cfg := &config.Config{
+		Databases: []config.Database{{
+			Name:        "licensed",
+			ActiveHours: offHours,
+			Tables: []config.Table{
+				{Name: "orders", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second)},
+				{Name: "always", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), ActiveHours: "00:00-24:00"},
+			},
+		}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.drivers["licensed"] = &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+
+	result, err := service.CheckHealth(context.Background(), "licensed", "orders")
+	if err != nil || result.Status != database.StatusPaused {
+		t.Errorf("CheckHealth(orders) = %+v, %v; expected paused outside %s", result, err, offHours)
+	}
+
+	result, err = service.CheckHealth(context.Background(), "licensed", "always")
+	if err != nil || result.Status != database.StatusHealthy {
+		t.Errorf("CheckHealth(always) = %+v, %v; expected the table's active hours to apply", result, err)
+	}
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// memorySampleInterval is how often memory usage is compared against the soft limit
+const memorySampleInterval = 5 * time.Second
+
+// memoryMetrics are the runtime metrics the Go soft memory limit is enforced against
+var memoryMetrics = []string{
+	"/memory/classes/total:bytes",
+	"/memory/classes/heap/released:bytes",
+}
+
+// memoryMonitor tracks whether the process is close to its soft memory limit
+type memoryMonitor struct {
+	service   *Service
+	threshold float64
+	pressure  atomic.Bool
+}
+
+// newMemoryMonitor creates a memory monitor for the configured threshold
+func newMemoryMonitor(service *Service) *memoryMonitor {
+	return &memoryMonitor{
+		service:   service,
+		threshold: service.config.Memory.GetPressureThreshold(),
+	}
+}
+
+// run samples memory usage until the context is cancelled. It returns
+// immediately when no soft memory limit is in effect.
+func (m *memoryMonitor) run(ctx context.Context) {
+	limit := debug.SetMemoryLimit(-1)
+	if limit == math.MaxInt64 {
+		return
+	}
+
+	m.service.logger.Info("Starting memory pressure monitor",
+		"soft_limit_bytes", limit,
+		"threshold", m.threshold)
+
+	ticker := time.NewTicker(memorySampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.sample(limit)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sample updates the pressure state from current memory usage
+func (m *memoryMonitor) sample(limit int64) {
+	samples := make([]metrics.Sample, len(memoryMetrics))
+	for i, name := range memoryMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	used := samples[0].Value.Uint64() - samples[1].Value.Uint64()
+	m.setPressure(float64(used) >= m.threshold*float64(limit), used, limit)
+}
+
+// setPressure records the pressure state, shrinking the cache on entering it
+func (m *memoryMonitor) setPressure(pressure bool, used uint64, limit int64) {
+	if m.pressure.Swap(pressure) == pressure {
+		return
+	}
+
+	if pressure {
+		m.service.logger.Warn("Memory pressure detected, shedding load",
+			"used_bytes", used,
+			"soft_limit_bytes", limit)
+		m.service.scheduler.dropCachedData()
+	} else {
+		m.service.logger.Info("Memory pressure relieved",
+			"used_bytes", used,
+			"soft_limit_bytes", limit)
+	}
+}
+
+// UnderMemoryPressure reports whether memory usage is near the soft limit.
+// While it is, realtime requests should be rejected, cached payloads are
+// dropped and non-critical checks are skipped.
+func (s *Service) UnderMemoryPressure() bool {
+	return s.memory != nil && s.memory.pressure.Load()
+}
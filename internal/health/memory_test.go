@@ -0,0 +1,78 @@
+package health
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func newPressureTestService() *Service {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{{Name: "table1"}}}},
+	}
+	return NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestMemoryPressureDropsCachedData(t *testing.T) {
+	service := newPressureTestService()
+	original := &database.HealthResult{
+		DatabaseName: "test",
+		TableName:    "table1",
+		Status:       "healthy",
+		Data:         map[string]interface{}{"count": 1},
+	}
+	service.scheduler.results["test/table1"] = &CachedResult{Result: original}
+
+	service.memory.setPressure(true, 0, 0)
+
+	if !service.UnderMemoryPressure() {
+		t.Fatalf("UnderMemoryPressure() = false; expected true")
+	}
+
+	cached := service.scheduler.results["test/table1"].Result
+	if cached.Data != nil {
+		t.Errorf("Data = %v; expected nil under memory pressure", cached.Data)
+	}
+	if cached.Status != "healthy" {
+		t.Errorf("Status = %q; expected %q", cached.Status, "healthy")
+	}
+	if original.Data == nil {
+		t.Errorf("Expected the original result to be left untouched")
+	}
+
+	service.memory.setPressure(false, 0, 0)
+	if service.UnderMemoryPressure() {
+		t.Errorf("UnderMemoryPressure() = true; expected false")
+	}
+}
+
+func TestMemoryPressureSkipsNonCriticalChecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		sheddable   bool
+		expectCheck bool
+	}{
+		{"critical check runs", false, true},
+		{"non-critical check skipped", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPressureTestService()
+			previous := &database.HealthResult{DatabaseName: "test", TableName: "table1", Status: "healthy"}
+			service.scheduler.results["test/table1"] = &CachedResult{Result: previous}
+			service.memory.setPressure(true, 0, 0)
+
+			check := &ScheduledCheck{DatabaseName: "test", TableName: "table1", Sheddable: tt.sheddable}
+			service.scheduler.runCheck(check, "test/table1")
+
+			ran := service.scheduler.results["test/table1"].Result != previous
+			if ran != tt.expectCheck {
+				t.Errorf("check ran = %v; expected %v", ran, tt.expectCheck)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"sync"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// RunOnce connects to the enabled databases with one attempt each, rather than
+// retrying in the background, and runs every check once. It is the one-shot
+// mode of batch jobs and cron: the scheduler is not started, and databases
+// that fail to connect report errors for their checks.
+func (s *Service) RunOnce(ctx context.Context) map[string][]*database.HealthResult {
+	var wg sync.WaitGroup
+	for _, dbConfig := range s.GetDatabases() {
+		if !dbConfig.IsEnabled() || s.IsConnected(dbConfig.Name) {
+			continue
+		}
+		wg.Add(1)
+		go func(dbConfig config.Database) {
+			defer wg.Done()
+			s.connectOnce(ctx, dbConfig)
+		}(dbConfig)
+	}
+	wg.Wait()
+
+	results, _ := s.CheckAllHealth(ctx)
+	return results
+}
+
+// connectOnce makes a single attempt to connect to a database, and adds its
+// driver on success
+func (s *Service) connectOnce(ctx context.Context, dbConfig config.Database) {
+	driver, err := s.factory.CreateDriver(dbConfig.Type)
+	if err != nil {
+		s.logger.Error("Failed to create driver",
+			"database", dbConfig.Name,
+			"type", dbConfig.Type,
+			"error", err)
+		return
+	}
+
+	connInfo := database.ConnectionInfo{
+		Host:     dbConfig.Host,
+		Port:     dbConfig.Port,
+		Username: dbConfig.Username,
+		Password: dbConfig.Password,
+		Database: dbConfig.Database,
+		SSLMode:  dbConfig.SSLMode,
+		Timeout:  s.config.Retry.GetConnectTimeout(),
+	}
+
+	connCtx, cancel := context.WithTimeout(ctx, s.config.Retry.GetConnectTimeout())
+	defer cancel()
+	if err := driver.Connect(connCtx, connInfo); err != nil {
+		s.logger.Error("Failed to connect to database",
+			"database", dbConfig.Name,
+			"error", err)
+		driver.Close()
+		return
+	}
+
+	s.mu.Lock()
+	s.drivers[dbConfig.Name] = driver
+	s.mu.Unlock()
+}
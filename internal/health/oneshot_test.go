@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestRunOnce(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{
+			{Name: "test", Type: "postgres", Tables: []config.Table{
+				{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			}},
+			{Name: "unsupported", Type: "oracle", Tables: []config.Table{
+				{Name: "orders", Query: "SELECT 1 FROM dual", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			}},
+		},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.drivers["test"] = &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	results := service.RunOnce(ctx)
+
+	if len(results["test"]) != 1 || results["test"][0].Status != database.StatusHealthy {
+		t.Errorf("results of test = %+v; expected users healthy", results["test"])
+	}
+	if len(results["unsupported"]) != 1 || results["unsupported"][0].Status != database.StatusError {
+		t.Errorf("results of unsupported = %+v; expected orders to fail to connect", results["unsupported"])
+	}
+}
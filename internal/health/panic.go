@@ -0,0 +1,92 @@
+package health
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// safeCheckHealth runs CheckHealth, converting a panic into an internal error
+// result for that check so the rest of the service keeps running
+func (s *Service) safeCheckHealth(ctx context.Context, databaseName, tableName string) (result *database.HealthResult, err error) {
+	key := databaseName + "/" + tableName
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			s.resetPanics(key)
+			return
+		}
+
+		stack := debug.Stack()
+		fingerprint := stackFingerprint(stack)
+		count := s.recordPanic(key)
+		checkPanics.Add(1)
+
+		s.logger.Error("Recovered panic in health check",
+			"database", databaseName,
+			"table", tableName,
+			"panic", fmt.Sprint(recovered),
+			"fingerprint", fingerprint,
+			"consecutive_panics", count,
+			"stack", string(stack))
+
+		message := fmt.Sprintf("internal error: %v (fingerprint %s)", recovered, fingerprint)
+		result = &database.HealthResult{
+			DatabaseName: databaseName,
+			TableName:    tableName,
+			Labels:       s.GetTableLabels(databaseName, tableName),
+			Severity:     s.GetTableSeverity(databaseName, tableName),
+			Status:       database.StatusInternalError,
+			Error:        message,
+			Timestamp:    time.Now(),
+		}
+		err = NewInternalError(databaseName, tableName, message)
+		s.recordDowntime(databaseName, tableName, result, err)
+	}()
+
+	result, err = s.CheckHealth(ctx, databaseName, tableName)
+	recordSeverityFailure(result, err)
+	return result, err
+}
+
+// recordPanic increments and returns the consecutive panic count of a check
+func (s *Service) recordPanic(key string) int {
+	s.panicMu.Lock()
+	defer s.panicMu.Unlock()
+
+	s.panics[key]++
+	return s.panics[key]
+}
+
+// resetPanics clears the consecutive panic count of a check
+func (s *Service) resetPanics(key string) {
+	s.panicMu.Lock()
+	defer s.panicMu.Unlock()
+
+	delete(s.panics, key)
+}
+
+// stackFingerprint returns a short hash of the function names in a stack
+// trace, ignoring goroutine IDs, argument values and offsets so the same
+// panic site always yields the same fingerprint
+func stackFingerprint(stack []byte) string {
+	hash := sha256.New()
+	for _, line := range strings.Split(string(stack), "\n") {
+		if line == "" || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "goroutine ") {
+			continue
+		}
+		if idx := strings.LastIndex(line, "("); idx > 0 {
+			line = line[:idx]
+		}
+		hash.Write([]byte(line))
+		hash.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// panicDriver is a driver whose health check query always panics
+type panicDriver struct{}
+
+func (panicDriver) Connect(ctx context.Context, info database.ConnectionInfo) error { return nil }
+func (panicDriver) Close() error                                                    { return nil }
+func (panicDriver) Ping(ctx context.Context) error                                  { return nil }
+func (panicDriver) GetDriverName() string                                           { return "panic" }
+func (panicDriver) ExecuteHealthCheck(ctx context.Context, query string) (map[string]interface{}, error) {
+	panic("driver exploded")
+}
+
+func TestSafeCheckHealthRecoversPanic(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{
+			{
+				Name: "test",
+				Tables: []config.Table{
+					{Name: "table1", Query: "SELECT 1", Timeout: config.Duration(5 * time.Second), CheckInterval: config.Duration(30 * time.Second)},
+				},
+			},
+		},
+	}
+
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.drivers["test"] = panicDriver{}
+
+	var fingerprint string
+	for i := 1; i <= 2; i++ {
+		result, err := service.safeCheckHealth(context.Background(), "test", "table1")
+
+		var healthErr *HealthError
+		if !errors.As(err, &healthErr) || !healthErr.IsInternalError() {
+			t.Fatalf("Expected internal error, got %v", err)
+		}
+		if result == nil || result.Status != database.StatusInternalError {
+			t.Fatalf("Expected %s result, got %+v", database.StatusInternalError, result)
+		}
+
+		if i == 1 {
+			fingerprint = result.Error
+		} else if result.Error != fingerprint {
+			t.Errorf("Expected stable fingerprint, got %q and %q", fingerprint, result.Error)
+		}
+
+		if count := service.panics["test/table1"]; count != i {
+			t.Errorf("Expected %d consecutive panics, got %d", i, count)
+		}
+	}
+}
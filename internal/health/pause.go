@@ -0,0 +1,208 @@
+package health
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// SchedulerState describes which scheduled checks are paused
+type SchedulerState struct {
+	Paused          bool     `json:"paused"`           // all scheduled checks are paused
+	PausedDatabases []string `json:"paused_databases"` // databases paused individually
+	PausedChecks    []string `json:"paused_checks"`    // checks paused individually, as "database/table"
+}
+
+// pause stops scheduled checks of a database, or of all databases when
+// databaseName is empty. Cached results are kept but no longer updated.
+func (s *Scheduler) pause(databaseName string) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	if databaseName == "" {
+		s.paused = true
+		return
+	}
+	s.pausedDatabases[databaseName] = true
+}
+
+// resume restarts scheduled checks of a database, or of all databases when
+// databaseName is empty. Resuming globally also resumes paused databases.
+func (s *Scheduler) resume(databaseName string) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	if databaseName == "" {
+		s.paused = false
+		s.pausedDatabases = make(map[string]bool)
+		return
+	}
+	delete(s.pausedDatabases, databaseName)
+}
+
+// isPaused reports whether scheduled checks of a database are paused
+func (s *Scheduler) isPaused(databaseName string) bool {
+	s.pauseMu.RLock()
+	defer s.pauseMu.RUnlock()
+
+	return s.paused || s.pausedDatabases[databaseName]
+}
+
+// state returns the current pause state
+func (s *Scheduler) state() SchedulerState {
+	s.pauseMu.RLock()
+	defer s.pauseMu.RUnlock()
+
+	state := SchedulerState{Paused: s.paused, PausedDatabases: []string{}, PausedChecks: []string{}}
+	for databaseName := range s.pausedDatabases {
+		state.PausedDatabases = append(state.PausedDatabases, databaseName)
+	}
+	for key := range s.pausedChecks {
+		state.PausedChecks = append(state.PausedChecks, key)
+	}
+	sort.Strings(state.PausedDatabases)
+	sort.Strings(state.PausedChecks)
+	return state
+}
+
+// pauseCheck mutes the scheduled check of a table: it is no longer run and
+// reports paused, so its status does not count, until it is resumed. Pausing
+// or resuming the whole scheduler does not change it.
+func (s *Scheduler) pauseCheck(databaseName, tableName string) {
+	key := s.getCheckKey(databaseName, tableName)
+
+	s.pauseMu.Lock()
+	s.pausedChecks[key] = true
+	s.pauseMu.Unlock()
+
+	s.publishPaused(key, databaseName, tableName)
+}
+
+// resumeCheck unmutes the scheduled check of a table and runs it right away
+func (s *Scheduler) resumeCheck(databaseName, tableName string) {
+	key := s.getCheckKey(databaseName, tableName)
+
+	s.pauseMu.Lock()
+	paused := s.pausedChecks[key]
+	delete(s.pausedChecks, key)
+	s.pauseMu.Unlock()
+
+	s.mu.RLock()
+	check, scheduled := s.checks[key]
+	s.mu.RUnlock()
+	if paused && scheduled {
+		s.enqueue(check, time.Now())
+	}
+}
+
+// isCheckPaused reports whether the check of a table is paused individually
+func (s *Scheduler) isCheckPaused(key string) bool {
+	s.pauseMu.RLock()
+	defer s.pauseMu.RUnlock()
+
+	return s.pausedChecks[key]
+}
+
+// forgetPausedChecks drops the paused checks of a removed database
+func (s *Scheduler) forgetPausedChecks(databaseName string) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	for key := range s.pausedChecks {
+		if strings.HasPrefix(key, databaseName+"/") {
+			delete(s.pausedChecks, key)
+		}
+	}
+}
+
+// publishPaused replaces the cached result of a scheduled check with a paused
+// result. Disabled checks keep theirs.
+func (s *Scheduler) publishPaused(key, databaseName, tableName string) {
+	s.mu.RLock()
+	cachedResult, exists := s.results[key]
+	_, scheduled := s.checks[key]
+	s.mu.RUnlock()
+	if !exists || !scheduled {
+		return
+	}
+
+	result := s.service.newPausedResult(databaseName, tableName)
+	cachedResult.mu.Lock()
+	previousStatus := cachedResult.status()
+	cachedResult.Result, cachedResult.Error = result, nil
+	cachedResult.UpdatedAt = result.Timestamp
+	cachedResult.streak = 0
+	cachedResult.mu.Unlock()
+
+	if previousStatus != database.StatusPaused {
+		s.service.publishStatusChange(databaseName, tableName, previousStatus, result, nil)
+	}
+}
+
+// PauseScheduler pauses scheduled checks of a database, or of all databases
+// when databaseName is empty. Realtime checks and refreshes still run.
+func (s *Service) PauseScheduler(databaseName string) error {
+	if databaseName != "" && !s.hasDatabase(databaseName) {
+		return NewNotFoundError(databaseName, "", "database not found in configuration")
+	}
+
+	s.scheduler.pause(databaseName)
+	s.logger.Info("Scheduled health checks paused", "database", databaseName)
+	return nil
+}
+
+// ResumeScheduler resumes scheduled checks of a database, or of all databases
+// when databaseName is empty
+func (s *Service) ResumeScheduler(databaseName string) error {
+	if databaseName != "" && !s.hasDatabase(databaseName) {
+		return NewNotFoundError(databaseName, "", "database not found in configuration")
+	}
+
+	s.scheduler.resume(databaseName)
+	s.logger.Info("Scheduled health checks resumed", "database", databaseName)
+	return nil
+}
+
+// PauseCheck pauses the scheduled check of a table, which then reports
+// paused until it is resumed
+func (s *Service) PauseCheck(databaseName, tableName string) error {
+	if !s.hasTable(databaseName, tableName) {
+		return NewNotFoundError(databaseName, tableName, "table not found in database configuration")
+	}
+
+	s.scheduler.pauseCheck(databaseName, tableName)
+	s.logger.Info("Scheduled health check paused", "database", databaseName, "table", tableName)
+	return nil
+}
+
+// ResumeCheck resumes the scheduled check of a table paused with PauseCheck
+// or in the configuration
+func (s *Service) ResumeCheck(databaseName, tableName string) error {
+	if !s.hasTable(databaseName, tableName) {
+		return NewNotFoundError(databaseName, tableName, "table not found in database configuration")
+	}
+
+	s.scheduler.resumeCheck(databaseName, tableName)
+	s.logger.Info("Scheduled health check resumed", "database", databaseName, "table", tableName)
+	return nil
+}
+
+// GetSchedulerState returns which scheduled checks are paused
+func (s *Service) GetSchedulerState() SchedulerState {
+	return s.scheduler.state()
+}
+
+// hasDatabase reports whether a database is configured
+func (s *Service) hasDatabase(databaseName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, db := range s.config.Databases {
+		if db.Name == databaseName {
+			return true
+		}
+	}
+	return false
+}
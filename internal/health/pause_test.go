@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestPauseSkipsScheduledChecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		pause       []string
+		resume      []string
+		expectCheck bool
+	}{
+		{"not paused", nil, nil, true},
+		{"paused globally", []string{""}, nil, false},
+		{"database paused", []string{"test"}, nil, false},
+		{"other database paused", []string{"other"}, nil, true},
+		{"database resumed", []string{"test"}, []string{"test"}, true},
+		{"database resumed while paused globally", []string{"", "test"}, []string{"test"}, false},
+		{"resumed globally", []string{"", "test"}, []string{""}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPressureTestService()
+			previous := &database.HealthResult{DatabaseName: "test", TableName: "table1", Status: "healthy"}
+			service.scheduler.results["test/table1"] = &CachedResult{Result: previous}
+
+			for _, databaseName := range tt.pause {
+				service.scheduler.pause(databaseName)
+			}
+			for _, databaseName := range tt.resume {
+				service.scheduler.resume(databaseName)
+			}
+
+			check := &ScheduledCheck{DatabaseName: "test", TableName: "table1"}
+			service.scheduler.runCheck(check, "test/table1")
+
+			ran := service.scheduler.results["test/table1"].Result != previous
+			if ran != tt.expectCheck {
+				t.Errorf("check ran = %v; expected %v", ran, tt.expectCheck)
+			}
+		})
+	}
+}
+
+func TestPauseScheduler(t *testing.T) {
+	service := newPressureTestService()
+
+	if err := service.PauseScheduler("missing"); err == nil {
+		t.Errorf("Expected an error pausing an unknown database")
+	}
+	if err := service.PauseScheduler("test"); err != nil {
+		t.Fatalf("PauseScheduler() error = %v", err)
+	}
+
+	expected := SchedulerState{Paused: false, PausedDatabases: []string{"test"}, PausedChecks: []string{}}
+	if state := service.GetSchedulerState(); !reflect.DeepEqual(state, expected) {
+		t.Errorf("GetSchedulerState() = %+v; expected %+v", state, expected)
+	}
+
+	if err := service.ResumeScheduler(""); err != nil {
+		t.Fatalf("ResumeScheduler() error = %v", err)
+	}
+	expected = SchedulerState{Paused: false, PausedDatabases: []string{}, PausedChecks: []string{}}
+	if state := service.GetSchedulerState(); !reflect.DeepEqual(state, expected) {
+		t.Errorf("GetSchedulerState() = %+v; expected %+v", state, expected)
+	}
+}
+
+func TestPauseCheck(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "noisy", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			{Name: "muted", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour), Paused: true},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.drivers["test"] = &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	// Checks paused in the configuration report paused before they ever run
+	if result, _, _ := service.GetCachedHealth("test", "muted"); result == nil || result.Status != database.StatusPaused {
+		t.Errorf("GetCachedHealth(muted) = %+v; expected paused", result)
+	}
+
+	if err := service.PauseCheck("test", "missing"); err == nil {
+		t.Errorf("Expected an error pausing an unknown table")
+	}
+	if err := service.PauseCheck("test", "noisy"); err != nil {
+		t.Fatalf("PauseCheck() error = %v", err)
+	}
+	expected := SchedulerState{PausedDatabases: []string{}, PausedChecks: []string{"test/muted", "test/noisy"}}
+	if state := service.GetSchedulerState(); !reflect.DeepEqual(state, expected) {
+		t.Errorf("GetSchedulerState() = %+v; expected %+v", state, expected)
+	}
+
+	// Neither scheduled runs nor refreshes unmute the check
+	service.scheduler.runCheck(service.scheduler.checks["test/noisy"], "test/noisy")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := service.RefreshHealth(ctx, "test", "noisy"); err != nil {
+		t.Fatalf("RefreshHealth() error = %v", err)
+	}
+	if result, _, _ := service.GetCachedHealth("test", "noisy"); result == nil || result.Status != database.StatusPaused {
+		t.Errorf("GetCachedHealth(noisy) = %+v; expected paused", result)
+	}
+
+	// Resuming globally leaves paused checks alone
+	service.scheduler.resume("")
+	if !service.scheduler.isCheckPaused("test/noisy") {
+		t.Errorf("Expected the check to stay paused when the scheduler resumes")
+	}
+
+	if err := service.ResumeCheck("test", "noisy"); err != nil {
+		t.Fatalf("ResumeCheck() error = %v", err)
+	}
+	if err := service.RefreshHealth(ctx, "test", "noisy"); err != nil {
+		t.Fatalf("RefreshHealth() error = %v", err)
+	}
+	if result, _, _ := service.GetCachedHealth("test", "noisy"); result == nil || result.Status != database.StatusHealthy {
+		t.Errorf("GetCachedHealth(noisy) after resuming = %+v; expected healthy", result)
+	}
+}
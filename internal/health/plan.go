@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// maxPlanLength bounds the plans kept with results and history
+const maxPlanLength = 16 << 10
+
+// capturePlan returns the execution plan of the query of a slow check, or ""
+// if it cannot be captured. Failing to capture a plan never fails the check.
+func (s *Service) capturePlan(ctx context.Context, driver database.Driver, dbConfig *config.Database, table *config.Table, query string) string {
+	explain := database.ExplainQuery(dbConfig.Type, query)
+	if explain == "" {
+		return ""
+	}
+
+	planCtx, cancel := context.WithTimeout(ctx, table.GetQueryTimeout())
+	defer cancel()
+
+	data, err := driver.ExecuteHealthCheck(planCtx, explain)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to capture execution plan",
+			"database", dbConfig.Name,
+			"table", table.Name,
+			"error", err)
+		return ""
+	}
+
+	plan := planText(data)
+	if len(plan) > maxPlanLength {
+		plan = plan[:maxPlanLength] + "\n(truncated)"
+	}
+	return plan
+}
+
+// planText renders the rows of an explain query as text, one line per row.
+// Plans are single-column rows; other rows list their columns by name.
+func planText(data map[string]interface{}) string {
+	var lines []string
+	for _, row := range rowsOf(data) {
+		if len(row) == 1 {
+			for _, value := range row {
+				lines = append(lines, fmt.Sprint(value))
+			}
+			continue
+		}
+
+		columns := make([]string, 0, len(row))
+		for column := range row {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		for i, column := range columns {
+			columns[i] = fmt.Sprintf("%s: %v", column, row[column])
+		}
+		lines = append(lines, strings.Join(columns, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestCapturePlan(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	dbConfig := &config.Database{Name: "app", Type: "postgres"}
+	table := &config.Table{Name: "orders", Query: "SELECT * FROM orders"}
+
+	driver := &sequenceDriver{data: map[string]map[string]interface{}{
+		"EXPLAIN SELECT * FROM orders": {"results": []map[string]interface{}{
+			{"QUERY PLAN": "Seq Scan on orders  (cost=0.00..35.50 rows=2550 width=4)"},
+			{"QUERY PLAN": "  Filter: (state = 'open'::text)"},
+		}, "row_count": 2},
+	}}
+
+	plan := service.capturePlan(context.Background(), driver, dbConfig, table, table.Query)
+	if plan != "Seq Scan on orders  (cost=0.00..35.50 rows=2550 width=4)\n  Filter: (state = 'open'::text)" {
+		t.Errorf("capturePlan() = %q; expected the plan lines", plan)
+	}
+
+	// Plans are best effort
+	if plan := service.capturePlan(context.Background(), driver, &config.Database{Type: "sqlite"}, table, table.Query); plan != "" {
+		t.Errorf("capturePlan() = %q; expected no plan without an explain query", plan)
+	}
+	driver.queryErr = errors.New("permission denied for table orders")
+	if plan := service.capturePlan(context.Background(), driver, dbConfig, table, table.Query); plan != "" {
+		t.Errorf("capturePlan() = %q; expected no plan when explain fails", plan)
+	}
+}
+
+func TestPlanText(t *testing.T) {
+	plan := planText(map[string]interface{}{"id": int64(1), "select_type": "SIMPLE", "table": "orders"})
+	if plan != "id: 1, select_type: SIMPLE, table: orders" {
+		t.Errorf("planText() = %q; expected the columns by name", plan)
+	}
+}
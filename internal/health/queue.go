@@ -0,0 +1,182 @@
+package health
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// runQueue is a min-heap of scheduled checks ordered by their next run. A
+// single dispatcher hands due checks to the worker pool, so the number of
+// goroutines does not grow with the number of checks.
+type runQueue []*ScheduledCheck
+
+func (q runQueue) Len() int           { return len(q) }
+func (q runQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q runQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *runQueue) Push(x any) {
+	check := x.(*ScheduledCheck)
+	check.index = len(*q)
+	*q = append(*q, check)
+}
+
+func (q *runQueue) Pop() any {
+	old := *q
+	check := old[len(old)-1]
+	old[len(old)-1] = nil
+	check.index = -1
+	*q = old[:len(old)-1]
+	return check
+}
+
+// idleWait is how long the dispatcher sleeps with nothing queued; adding a
+// check wakes it earlier
+const idleWait = time.Hour
+
+// enqueue schedules the next run of a check, moving it if it is queued
+// already. Removed checks are not queued again.
+func (s *Scheduler) enqueue(check *ScheduledCheck, next time.Time) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if check.removed {
+		return
+	}
+	check.next = next
+	if check.index >= 0 {
+		heap.Fix(&s.queue, check.index)
+	} else {
+		heap.Push(&s.queue, check)
+	}
+
+	// The dispatcher may be waiting for a later check
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// unschedule takes a check off the queue for good
+func (s *Scheduler) unschedule(check *ScheduledCheck) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	check.removed = true
+	if check.index >= 0 {
+		heap.Remove(&s.queue, check.index)
+	}
+}
+
+// isRemoved reports whether a check was taken off the queue for good
+func (s *Scheduler) isRemoved(check *ScheduledCheck) bool {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	return check.removed
+}
+
+// nextDue pops the first check if it is due at now, or returns how long
+// until it is
+func (s *Scheduler) nextDue(now time.Time) (*ScheduledCheck, time.Duration) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if len(s.queue) == 0 {
+		return nil, idleWait
+	}
+	if wait := s.queue[0].next.Sub(now); wait > 0 {
+		return nil, wait
+	}
+	return heap.Pop(&s.queue).(*ScheduledCheck), 0
+}
+
+// dispatch hands checks to the workers as they fall due, until the scheduler
+// stops. With every worker busy, due checks wait in order of their next run.
+func (s *Scheduler) dispatch() {
+	timer := time.NewTimer(idleWait)
+	defer timer.Stop()
+
+	for {
+		check, wait := s.nextDue(time.Now())
+		if check != nil {
+			select {
+			case s.due <- check:
+				continue
+			case <-s.ctx.Done():
+				return
+			}
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// work runs the checks handed out by the dispatcher until the scheduler stops
+func (s *Scheduler) work() {
+	for {
+		select {
+		case check := <-s.due:
+			s.runScheduled(check)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// runScheduled runs a due check and queues its next run
+func (s *Scheduler) runScheduled(check *ScheduledCheck) {
+	key := s.getCheckKey(check.DatabaseName, check.TableName)
+
+	if !check.lock(s.ctx) {
+		return
+	}
+	defer check.unlock()
+
+	s.runCheck(check, key)
+	s.enqueue(check, time.Now().Add(check.nextInterval(s.isCheckFailing(key))))
+}
+
+// lock waits until no other run of the check is in progress. It reports
+// false if ctx ends first.
+func (c *ScheduledCheck) lock(ctx context.Context) bool {
+	select {
+	case c.running <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// tryLock starts a run of the check unless one is in progress
+func (c *ScheduledCheck) tryLock() bool {
+	select {
+	case c.running <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// unlock ends a run of the check started with lock or tryLock
+func (c *ScheduledCheck) unlock() {
+	<-c.running
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestRunQueue(t *testing.T) {
+	scheduler := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil))).scheduler
+
+	now := time.Now()
+	checks := make(map[string]*ScheduledCheck)
+	for i, name := range []string{"c", "a", "b", "gone"} {
+		checks[name] = &ScheduledCheck{TableName: name, index: -1}
+		scheduler.enqueue(checks[name], now.Add(time.Duration(len(name)*10-i)*time.Second))
+	}
+	// Moving a queued check reorders it rather than queueing it twice
+	scheduler.enqueue(checks["a"], now.Add(-2*time.Second))
+	scheduler.enqueue(checks["b"], now.Add(-time.Second))
+	scheduler.enqueue(checks["c"], now)
+	scheduler.unschedule(checks["gone"])
+
+	var order []string
+	for {
+		check, wait := scheduler.nextDue(now)
+		if check == nil {
+			if wait != idleWait {
+				t.Errorf("nextDue() wait = %v with nothing queued; expected %v", wait, idleWait)
+			}
+			break
+		}
+		order = append(order, check.TableName)
+	}
+	if fmt.Sprint(order) != "[a b c]" {
+		t.Errorf("nextDue() order = %v; expected [a b c]", order)
+	}
+
+	// Unscheduled checks are not queued again
+	scheduler.enqueue(checks["gone"], now)
+	if check, _ := scheduler.nextDue(now); check != nil {
+		t.Errorf("nextDue() = %s; expected the unscheduled check to stay off the queue", check.TableName)
+	}
+
+	scheduler.enqueue(checks["a"], now.Add(time.Minute))
+	if check, wait := scheduler.nextDue(now); check != nil || wait != time.Minute {
+		t.Errorf("nextDue() = %v, %v; expected to wait a minute", check, wait)
+	}
+}
+
+func TestSchedulerWorkerPool(t *testing.T) {
+	var tables []config.Table
+	for i := 0; i < 50; i++ {
+		tables = append(tables, config.Table{
+			Name:          fmt.Sprintf("table%d", i),
+			Query:         "SELECT 1 AS one",
+			Timeout:       config.Duration(time.Second),
+			CheckInterval: config.Duration(50 * time.Millisecond),
+		})
+	}
+	cfg := &config.Config{
+		Scheduler: config.Scheduler{Workers: 2},
+		Databases: []config.Database{{Name: "test", Tables: tables}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.drivers["test"] = &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+
+	started := time.Now()
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	// Every check runs more than once on two workers
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, table := range tables {
+		for {
+			if _, _, updatedAt := service.GetCachedHealth("test", table.Name); updatedAt.After(started.Add(50 * time.Millisecond)) {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				t.Fatalf("Expected %s to be checked again", table.Name)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}
+}
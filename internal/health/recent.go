@@ -0,0 +1,60 @@
+package health
+
+import (
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// recentResults is a ring of the latest results of a scheduled check, listed
+// inline in its cached result. It is guarded by the mutex of the check's
+// cached result.
+type recentResults struct {
+	entries []database.RecentResult
+	next    int // index the next result is written to
+	full    bool
+}
+
+// add records a result, keeping the latest size results. A size of zero
+// keeps none; a changed size starts the ring afresh.
+func (r *recentResults) add(result database.RecentResult, size int) {
+	if size <= 0 {
+		r.entries, r.next, r.full = nil, 0, false
+		return
+	}
+	if len(r.entries) != size {
+		r.entries, r.next, r.full = make([]database.RecentResult, size), 0, false
+	}
+
+	r.entries[r.next] = result
+	r.next = (r.next + 1) % size
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// newest returns the recorded results, newest first, or nil without any
+func (r *recentResults) newest() []database.RecentResult {
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	results := make([]database.RecentResult, 0, count)
+	for i := 1; i <= count; i++ {
+		results = append(results, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return results
+}
+
+// newRecentResult describes the outcome of a check run started at started;
+// runs without a result failed with an error
+func newRecentResult(result *database.HealthResult, started time.Time) database.RecentResult {
+	if result == nil {
+		return database.RecentResult{Status: database.StatusError, Timestamp: started}
+	}
+	return database.RecentResult{Status: result.Status, QueryTime: result.QueryTime, Timestamp: result.Timestamp}
+}
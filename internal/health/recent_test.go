@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestRecentResults(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+		}}},
+		History: config.History{Recent: 2},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	driver := &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	service.drivers["test"] = driver
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	refresh := func() {
+		if err := service.RefreshHealth(ctx, "test", "users"); err != nil {
+			t.Fatalf("RefreshHealth() error = %v", err)
+		}
+	}
+	statuses := func() []database.Status {
+		result, _, _ := service.GetCachedHealth("test", "users")
+		var statuses []database.Status
+		for _, recent := range result.Recent {
+			statuses = append(statuses, recent.Status)
+		}
+		return statuses
+	}
+
+	refresh()
+	if got := statuses(); len(got) != 1 || got[0] != database.StatusHealthy {
+		t.Fatalf("recent statuses = %v; expected [healthy]", got)
+	}
+
+	driver.queryErr = errors.New("relation does not exist")
+	refresh()
+	driver.queryErr = nil
+	refresh()
+
+	got := statuses()
+	if len(got) != 2 || got[0] != database.StatusHealthy || got[1] == database.StatusHealthy {
+		t.Errorf("recent statuses = %v; expected the last healthy result and the failure before it", got)
+	}
+}
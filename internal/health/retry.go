@@ -115,10 +115,15 @@ func (s *Service) attemptConnectionRecovery(ctx context.Context) {
 	defer s.mu.Unlock()
 
 	for _, dbConfig := range s.config.Databases {
+		// Disabled databases are never connected
+		if !dbConfig.IsEnabled() {
+			continue
+		}
+
 		// Check if this database is already connected
 		if driver, exists := s.drivers[dbConfig.Name]; exists {
 			// Test if connection is still alive
-			pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			pingCtx, cancel := context.WithTimeout(ctx, s.config.Retry.GetPingTimeout())
 			if err := driver.Ping(pingCtx); err == nil {
 				cancel()
 				continue // Connection is healthy
@@ -130,6 +135,8 @@ func (s *Service) attemptConnectionRecovery(ctx context.Context) {
 				"database", dbConfig.Name)
 			driver.Close()
 			delete(s.drivers, dbConfig.Name)
+			s.markDisconnected(dbConfig.Name, time.Now())
+			s.publishConnectionEvent(EventDisconnected, dbConfig.Name)
 		}
 
 		// Attempt to reconnect
@@ -151,11 +158,11 @@ func (s *Service) attemptConnectionRecovery(ctx context.Context) {
 			Password: dbConfig.Password,
 			Database: dbConfig.Database,
 			SSLMode:  dbConfig.SSLMode,
-			Timeout:  30 * time.Second,
+			Timeout:  s.config.Retry.GetConnectTimeout(),
 		}
 
 		// Use single attempt for recovery (don't block the recovery loop)
-		connCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		connCtx, cancel := context.WithTimeout(ctx, s.config.Retry.GetConnectTimeout())
 		err = driver.Connect(connCtx, connInfo)
 		cancel()
 
@@ -163,6 +170,8 @@ func (s *Service) attemptConnectionRecovery(ctx context.Context) {
 			s.drivers[dbConfig.Name] = driver
 			s.logger.Info("Database connection recovered",
 				"database", dbConfig.Name)
+			s.markConnected(dbConfig.Name)
+			s.publishConnectionEvent(EventConnected, dbConfig.Name)
 		} else {
 			s.logger.Debug("Database recovery failed, will try again later",
 				"database", dbConfig.Name,
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// overdue returns the age of the cached result of a check, and whether it is
+// older than the check's interval, allowing for jitter. Results of paused
+// checks are never overdue, and checks without a result yet have no age.
+// Callers must hold cachedResult.mu.
+func (s *Scheduler) overdue(check *ScheduledCheck, key string, cachedResult *CachedResult) (time.Duration, bool) {
+	checkedAt := cachedResult.UpdatedAt
+	if runs := cachedResult.runs; runs.runs > 0 {
+		checkedAt = runs.lastRunAt.Add(runs.lastDuration)
+	}
+	if checkedAt.IsZero() {
+		return 0, false
+	}
+	age := time.Since(checkedAt)
+	if s.isCheckPaused(key) || s.isPaused(check.DatabaseName) {
+		return age, false
+	}
+
+	interval := check.Interval
+	if check.Recheck > 0 && (cachedResult.Result != nil || cachedResult.Error != nil) && isFailing(cachedResult.Result, cachedResult.Error) {
+		interval = check.Recheck
+	}
+	return age, age > time.Duration(float64(interval)*(1+check.Jitter))
+}
+
+// withUnknown turns a copy of a cached result older than the check's
+// max_result_age into an unknown result, so week-old results are not served
+// as current. UnknownSince records when the result became unknown, so the
+// response changes for conditional requests too. Disabled and paused results
+// are kept as they are.
+func withUnknown(result *database.HealthResult, check *ScheduledCheck, age time.Duration) {
+	if check.MaxResultAge == 0 || age <= check.MaxResultAge ||
+		result.Status == database.StatusDisabled || result.Status == database.StatusPaused {
+		return
+	}
+
+	unknownSince := time.Now().Add(check.MaxResultAge - age)
+	result.Error = fmt.Sprintf("last checked %s ago, past its max_result_age of %s; last status %s", age.Round(time.Second), check.MaxResultAge, result.Status)
+	result.Status = database.StatusUnknown
+	result.Data = nil
+	result.Stale = true
+	result.Age = age
+	result.UnknownSince = &unknownSince
+}
+
+// revalidate runs an overdue check in the background, so a reader served the
+// old result causes it to heal even if its scheduled run was lost. A check
+// that is running already is left to finish, so concurrent reads coalesce
+// into a single run.
+func (s *Scheduler) revalidate(check *ScheduledCheck, key string) {
+	if s.ctx.Err() != nil || !check.tryLock() {
+		return
+	}
+
+	revalidations.Add(1)
+	s.logger.Debug("Revalidating overdue cached result",
+		"database", check.DatabaseName,
+		"table", check.TableName)
+
+	go func() {
+		defer check.unlock()
+
+		s.runCheck(check, key)
+		s.enqueue(check, time.Now().Add(check.nextInterval(s.isCheckFailing(key))))
+	}()
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestRevalidate(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	driver := &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	service.drivers["test"] = driver
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := service.RefreshHealth(ctx, "test", "users"); err != nil {
+		t.Fatalf("RefreshHealth() error = %v", err)
+	}
+
+	result, _, _ := service.GetCachedHealth("test", "users")
+	if result.Stale || result.Age != 0 {
+		t.Fatalf("Stale, Age = %v, %v; expected a current result", result.Stale, result.Age)
+	}
+
+	// As if the scheduled runs since had been lost
+	cachedResult := service.scheduler.results["test/users"]
+	cachedResult.mu.Lock()
+	cachedResult.runs.lastRunAt = time.Now().Add(-2 * time.Hour)
+	cachedResult.mu.Unlock()
+
+	for i := 0; i < 5; i++ {
+		result, _, _ = service.GetCachedHealth("test", "users")
+		if i == 0 && (!result.Stale || result.Age < time.Hour) {
+			t.Errorf("Stale, Age = %v, %v; expected a stale result over an hour old", result.Stale, result.Age)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		result, _, _ = service.GetCachedHealth("test", "users")
+		if !result.Stale {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Result still stale after revalidation: %+v", result)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Reads of the stale result coalesce into a single run
+	if result.RunCount != 2 {
+		t.Errorf("RunCount = %d; expected 2", result.RunCount)
+	}
+}
+
+func TestMaxResultAge(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour), MaxResultAge: config.Duration(3 * time.Hour)},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	driver := &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	service.drivers["test"] = driver
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := service.RefreshHealth(ctx, "test", "users"); err != nil {
+		t.Fatalf("RefreshHealth() error = %v", err)
+	}
+
+	// Paused schedulers are not revalidated, so the result keeps aging
+	service.PauseScheduler("test")
+	age := func(age time.Duration) *database.HealthResult {
+		cachedResult := service.scheduler.results["test/users"]
+		cachedResult.mu.Lock()
+		cachedResult.runs.lastRunAt = time.Now().Add(-age)
+		cachedResult.mu.Unlock()
+
+		result, _, _ := service.GetCachedHealth("test", "users")
+		return result
+	}
+
+	if result := age(2 * time.Hour); result.Status != database.StatusHealthy || result.Data == nil {
+		t.Errorf("Status = %q; expected a healthy result with its data within max_result_age", result.Status)
+	}
+
+	result := age(4 * time.Hour)
+	if result.Status != database.StatusUnknown || result.Data != nil || !result.Stale || result.Age < 3*time.Hour || result.Error == "" {
+		t.Errorf("result = %+v; expected an unknown result without data past max_result_age", result)
+	}
+	if result.UnknownSince == nil || time.Since(*result.UnknownSince).Round(time.Minute) != time.Hour {
+		t.Errorf("UnknownSince = %v; expected an hour ago, when max_result_age passed", result.UnknownSince)
+	}
+
+	results, _ := service.GetCachedDatabaseHealth("test")
+	if len(results) != 1 || results[0].Status != database.StatusUnknown {
+		t.Errorf("GetCachedDatabaseHealth() = %+v; expected the unknown result", results)
+	}
+}
@@ -0,0 +1,139 @@
+package health
+
+import (
+	"sort"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// checkRuns counts the runs of a scheduled check since the service started.
+// It is guarded by the mutex of the check's cached result.
+type checkRuns struct {
+	lastRunAt           time.Time
+	lastDuration        time.Duration
+	consecutiveFailures int
+	runs                int64
+	failures            int64
+}
+
+// record counts a completed run of the check
+func (r *checkRuns) record(started time.Time, duration time.Duration, failing bool) {
+	r.lastRunAt = started
+	r.lastDuration = duration
+	r.runs++
+	if failing {
+		r.failures++
+		r.consecutiveFailures++
+	} else {
+		r.consecutiveFailures = 0
+	}
+}
+
+// CheckRunStats describes the runs of a scheduled check
+type CheckRunStats struct {
+	Database            string        `json:"database"`
+	Table               string        `json:"table"`
+	LastRunAt           *time.Time    `json:"last_run_at,omitempty"`
+	LastDuration        time.Duration `json:"last_duration"`
+	NextRunAt           *time.Time    `json:"next_run_at,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	RunCount            int64         `json:"run_count"`
+	FailureCount        int64         `json:"failure_count"`
+}
+
+// runStats returns the run statistics of a check; callers must hold
+// cachedResult.mu
+func (s *Scheduler) runStats(databaseName, tableName string, check *ScheduledCheck, cachedResult *CachedResult) CheckRunStats {
+	runs := cachedResult.runs
+	stats := CheckRunStats{
+		Database:            databaseName,
+		Table:               tableName,
+		LastDuration:        runs.lastDuration,
+		ConsecutiveFailures: runs.consecutiveFailures,
+		RunCount:            runs.runs,
+		FailureCount:        runs.failures,
+	}
+	if runs.runs > 0 {
+		stats.LastRunAt = &runs.lastRunAt
+	}
+	if next, queued := s.nextRunAt(check); queued {
+		stats.NextRunAt = &next
+	}
+	return stats
+}
+
+// withRuns returns a copy of a cached result carrying the run statistics and
+// recent results of its scheduled check. A result older than the check's
+// interval is marked stale with its age, and the check is revalidated in the
+// background; one older than its max_result_age is unknown. Results of disabled checks, which have none, are returned as
+// they are. Callers must hold cachedResult.mu.
+func (s *Scheduler) withRuns(result *database.HealthResult, check *ScheduledCheck, cachedResult *CachedResult) *database.HealthResult {
+	if check == nil {
+		return result
+	}
+
+	key := s.getCheckKey(check.DatabaseName, check.TableName)
+	age, overdue := s.overdue(check, key, cachedResult)
+	if overdue {
+		s.revalidate(check, key)
+	}
+	if result == nil {
+		return result
+	}
+
+	stats := s.runStats(result.DatabaseName, result.TableName, check, cachedResult)
+	annotated := *result
+	annotated.LastRunAt = stats.LastRunAt
+	annotated.LastDuration = stats.LastDuration
+	annotated.NextRunAt = stats.NextRunAt
+	annotated.ConsecutiveFailures = stats.ConsecutiveFailures
+	annotated.RunCount = stats.RunCount
+	annotated.FailureCount = stats.FailureCount
+	annotated.Recent = cachedResult.recent.newest()
+	if overdue {
+		annotated.Stale = true
+		annotated.Age = age
+	}
+	withUnknown(&annotated, check, age)
+	return &annotated
+}
+
+// nextRunAt returns when a check runs next. A check that is running or
+// unscheduled has no next run.
+func (s *Scheduler) nextRunAt(check *ScheduledCheck) (time.Time, bool) {
+	if check == nil {
+		return time.Time{}, false
+	}
+
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if check.index < 0 {
+		return time.Time{}, false
+	}
+	return check.next, true
+}
+
+// checkRunStats returns the run statistics of every scheduled check, ordered
+// by database and table; callers must hold s.mu
+func (s *Scheduler) checkRunStats() []CheckRunStats {
+	keys := make([]string, 0, len(s.checks))
+	for key := range s.checks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stats := make([]CheckRunStats, 0, len(keys))
+	for _, key := range keys {
+		cachedResult, exists := s.results[key]
+		if !exists {
+			continue
+		}
+		databaseName, tableName := s.parseCheckKey(key)
+		cachedResult.mu.RLock()
+		stats = append(stats, s.runStats(databaseName, tableName, s.checks[key], cachedResult))
+		cachedResult.mu.RUnlock()
+	}
+	return stats
+}
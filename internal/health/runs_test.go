@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestCheckRuns(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	driver := &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	service.drivers["test"] = driver
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	refresh := func() {
+		if err := service.RefreshHealth(ctx, "test", "users"); err != nil {
+			t.Fatalf("RefreshHealth() error = %v", err)
+		}
+	}
+
+	before := time.Now()
+	refresh()
+	driver.queryErr = errors.New("relation does not exist")
+	refresh()
+	refresh()
+
+	result, _, _ := service.GetCachedHealth("test", "users")
+	if result.RunCount != 3 || result.FailureCount != 2 || result.ConsecutiveFailures != 2 {
+		t.Errorf("RunCount, FailureCount, ConsecutiveFailures = %d, %d, %d; expected 3, 2, 2", result.RunCount, result.FailureCount, result.ConsecutiveFailures)
+	}
+	if result.LastRunAt == nil || result.LastRunAt.Before(before) {
+		t.Errorf("LastRunAt = %v; expected after %v", result.LastRunAt, before)
+	}
+	if result.NextRunAt == nil || result.NextRunAt.Before(result.LastRunAt.Add(59*time.Minute)) {
+		t.Errorf("NextRunAt = %v; expected an hour after the last run at %v", result.NextRunAt, result.LastRunAt)
+	}
+
+	driver.queryErr = nil
+	refresh()
+	stats := service.GetCacheStats().Checks
+	if len(stats) != 1 || stats[0].Table != "users" || stats[0].RunCount != 4 || stats[0].ConsecutiveFailures != 0 || stats[0].FailureCount != 2 {
+		t.Errorf("GetCacheStats().Checks = %+v; expected 4 runs of users with 2 failures, none consecutive", stats)
+	}
+}
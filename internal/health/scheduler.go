@@ -2,10 +2,13 @@ package health
 
 import (
 	"context"
+	"hash/fnv"
 	"log/slog"
+	"math/rand"
 	"sync"
 	"time"
 
+	"gsqlhealth/internal/config"
 	"gsqlhealth/internal/database"
 )
 
@@ -14,8 +17,36 @@ type ScheduledCheck struct {
 	DatabaseName string
 	TableName    string
 	Interval     time.Duration
-	ticker       *time.Ticker
-	stopCh       chan bool
+	Recheck      time.Duration // interval while the published result is failing
+	Jitter       float64       // random spread of the interval, as a fraction of it
+	MaxResultAge time.Duration // age past which the cached result is unknown, 0 for none
+	Sheddable    bool          // skipped under memory pressure
+
+	next    time.Time     // next scheduled run
+	index   int           // position in the run queue, -1 while not queued
+	removed bool          // the check was unscheduled and is not queued again
+	running chan struct{} // held while the check runs, so refreshes wait for scheduled runs
+
+	// FailureThreshold and SuccessThreshold are the consecutive checks needed
+	// to change the published status
+	FailureThreshold int
+	SuccessThreshold int
+
+	// AlertOnChange fails checks whose data differs from the previous check
+	AlertOnChange bool
+
+	// ConnectionCheck marks the connection check of a database, which the
+	// other checks of the database depend on
+	ConnectionCheck bool
+
+	// Deadlocks marks a deadlocks check, whose counter is sampled on each
+	// scheduled run and fails it with more than MaxDeadlocks new deadlocks
+	Deadlocks    bool
+	MaxDeadlocks int64
+
+	// AnomalyStddevs degrades checks whose query time is this many standard
+	// deviations above the baseline of their scheduled runs, 0 for none
+	AnomalyStddevs float64
 }
 
 // Scheduler manages periodic health checks
@@ -27,6 +58,24 @@ type Scheduler struct {
 	mu          sync.RWMutex
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	slots *checkSlots // concurrency limits of scheduled checks
+
+	queue   runQueue             // scheduled checks by next run
+	queueMu sync.Mutex           // guards queue and the queue fields of checks
+	wake    chan struct{}        // wakes the dispatcher when the queue changes
+	due     chan *ScheduledCheck // due checks handed from the dispatcher to the workers
+
+	pauseMu         sync.RWMutex
+	paused          bool
+	pausedDatabases map[string]bool
+	pausedChecks    map[string]bool // key: "database/table"
+
+	connMu   sync.Mutex
+	connDown map[string]bool // key: database, whether its connection check fails
+
+	stuck   map[string]time.Time // start of abandoned checks that have not returned, per "database/table"
+	stuckMu sync.Mutex
 }
 
 // CachedResult holds a cached health check result with timestamp
@@ -35,6 +84,33 @@ type CachedResult struct {
 	Error     error
 	UpdatedAt time.Time
 	mu        sync.RWMutex
+
+	// streak counts the consecutive checks that disagree with the published
+	// result on whether the check is failing
+	streak int
+
+	// previousData is the data of the last successful check, kept for checks
+	// that alert on change
+	previousData map[string]interface{}
+
+	// runs counts the runs of the scheduled check
+	runs checkRuns
+
+	// recent keeps the latest results of the scheduled check
+	recent recentResults
+}
+
+// CacheStats summarizes the cached health check results
+type CacheStats struct {
+	TotalChecks        int `json:"total_checks"`
+	FreshResults       int `json:"fresh_results"`
+	HealthyResults     int `json:"healthy_results"`
+	UnhealthyResults   int `json:"unhealthy_results"`
+	DisabledResults    int `json:"disabled_results"`
+	MaintenanceResults int `json:"maintenance_results"`
+	PausedResults      int `json:"paused_results"`
+
+	Checks []CheckRunStats `json:"checks"` // runs of each scheduled check
 }
 
 // NewScheduler creates a new health check scheduler
@@ -47,6 +123,14 @@ func NewScheduler(service *Service, logger *slog.Logger) *Scheduler {
 		results: make(map[string]*CachedResult),
 		ctx:     ctx,
 		cancel:  cancel,
+		slots:   newCheckSlots(service.config),
+		wake:    make(chan struct{}, 1),
+		due:     make(chan *ScheduledCheck),
+
+		pausedDatabases: make(map[string]bool),
+		pausedChecks:    make(map[string]bool),
+		connDown:        make(map[string]bool),
+		stuck:           make(map[string]time.Time),
 	}
 }
 
@@ -60,31 +144,89 @@ func (s *Scheduler) Start() error {
 	// Create scheduled checks for all configured tables
 	for _, dbConfig := range s.service.config.Databases {
 		for _, tableConfig := range dbConfig.Tables {
-			key := s.getCheckKey(dbConfig.Name, tableConfig.Name)
+			s.scheduleTable(dbConfig, tableConfig)
+		}
+	}
 
-			scheduledCheck := &ScheduledCheck{
-				DatabaseName: dbConfig.Name,
-				TableName:    tableConfig.Name,
-				Interval:     tableConfig.GetCheckInterval(),
-				stopCh:       make(chan bool, 1),
-			}
+	cacheSize.Set(int64(len(s.results)))
+
+	// Results from before a restart are served until the checks run again
+	if restored, err := s.loadSnapshot(); err != nil {
+		s.logger.Warn("Failed to restore cached results",
+			"file", s.service.config.CacheSnapshot,
+			"error", err)
+	} else if restored > 0 {
+		s.logger.Info("Restored cached results from before the restart",
+			"file", s.service.config.CacheSnapshot,
+			"results", restored)
+	}
 
-			s.checks[key] = scheduledCheck
-			s.results[key] = &CachedResult{
-				UpdatedAt: time.Now(),
-			}
+	go s.dispatch()
+	for i := 0; i < s.service.config.Scheduler.GetWorkers(); i++ {
+		go s.work()
+	}
 
-			// Start the periodic check
-			go s.runPeriodicCheck(scheduledCheck)
+	return nil
+}
 
-			s.logger.Info("Scheduled health check",
-				"database", dbConfig.Name,
-				"table", tableConfig.Name,
-				"interval", tableConfig.GetCheckInterval())
+// scheduleTable starts the scheduled check of a table, or caches the static
+// result of a disabled one; callers must hold s.mu
+func (s *Scheduler) scheduleTable(dbConfig config.Database, tableConfig config.Table) {
+	key := s.getCheckKey(dbConfig.Name, tableConfig.Name)
+
+	// Disabled checks keep a static result so they stay visible
+	if !dbConfig.IsTableEnabled(&tableConfig) {
+		disabledResult := newDisabledResult(dbConfig.Name, tableConfig.Name)
+		disabledResult.Labels = s.service.GetTableLabels(dbConfig.Name, tableConfig.Name)
+		s.results[key] = &CachedResult{
+			Result:    disabledResult,
+			UpdatedAt: time.Now(),
 		}
+
+		s.logger.Info("Health check disabled",
+			"database", dbConfig.Name,
+			"table", tableConfig.Name)
+		return
 	}
 
-	return nil
+	scheduledCheck := &ScheduledCheck{
+		DatabaseName:     dbConfig.Name,
+		TableName:        tableConfig.Name,
+		Interval:         tableConfig.GetCheckInterval(),
+		Recheck:          tableConfig.GetRecheckInterval(),
+		Jitter:           tableConfig.GetJitter(),
+		MaxResultAge:     time.Duration(tableConfig.MaxResultAge),
+		Sheddable:        tableConfig.IsSheddable(),
+		FailureThreshold: tableConfig.GetFailureThreshold(),
+		SuccessThreshold: tableConfig.GetSuccessThreshold(),
+		AlertOnChange:    tableConfig.AlertOnChange,
+		ConnectionCheck:  tableConfig.Check == config.CheckConnection,
+		Deadlocks:        tableConfig.Check == config.CheckDeadlocks,
+		MaxDeadlocks:     tableConfig.MaxDeadlocks,
+		AnomalyStddevs:   tableConfig.AnomalyStddevs,
+		index:            -1,
+		running:          make(chan struct{}, 1),
+	}
+
+	s.checks[key] = scheduledCheck
+	s.results[key] = &CachedResult{
+		UpdatedAt: time.Now(),
+	}
+
+	// Checks paused in the configuration report paused from the start
+	if tableConfig.Paused {
+		s.pauseMu.Lock()
+		s.pausedChecks[key] = true
+		s.pauseMu.Unlock()
+		s.results[key].Result = s.service.newPausedResult(dbConfig.Name, tableConfig.Name)
+	}
+
+	s.enqueue(scheduledCheck, time.Now().Add(s.firstRunDelay(scheduledCheck, key)))
+
+	s.logger.Info("Scheduled health check",
+		"database", dbConfig.Name,
+		"table", tableConfig.Name,
+		"interval", tableConfig.GetCheckInterval())
 }
 
 // Stop stops all scheduled health checks
@@ -96,70 +238,266 @@ func (s *Scheduler) Stop() {
 
 	// Stop all scheduled checks
 	for _, check := range s.checks {
-		if check.ticker != nil {
-			check.ticker.Stop()
-		}
-		select {
-		case check.stopCh <- true:
-		default:
-		}
+		s.unschedule(check)
 	}
 
 	// Cancel the context
 	s.cancel()
 
+	if err := s.saveSnapshot(); err != nil {
+		s.logger.Error("Failed to save cached results",
+			"file", s.service.config.CacheSnapshot,
+			"error", err)
+	}
+
 	s.logger.Info("Health check scheduler stopped")
 }
 
-// runPeriodicCheck runs a periodic health check for a specific database/table
-func (s *Scheduler) runPeriodicCheck(check *ScheduledCheck) {
-	key := s.getCheckKey(check.DatabaseName, check.TableName)
+// firstRunDelay returns the time until the first run of a check. Staggered
+// checks start at a point of their first interval derived from their key, so
+// startup load is spread evenly and the same on every start. Otherwise the
+// first check runs right away, unless jitter spreads it.
+func (s *Scheduler) firstRunDelay(check *ScheduledCheck, key string) time.Duration {
+	if s.service.config.Scheduler.IsStaggered() {
+		hash := fnv.New32a()
+		hash.Write([]byte(key))
+		return time.Duration(float64(check.Interval) * float64(hash.Sum32()) / (1 << 32))
+	}
+	if check.Jitter > 0 {
+		return time.Duration(rand.Float64() * check.Jitter * float64(check.Interval))
+	}
+	return 0
+}
+
+// nextInterval returns the time until the next run of a check: its interval,
+// or its recheck interval while failing, moved randomly by up to its jitter
+// either way
+func (c *ScheduledCheck) nextInterval(failing bool) time.Duration {
+	interval := c.Interval
+	if failing && c.Recheck > 0 {
+		interval = c.Recheck
+	}
+	if c.Jitter == 0 {
+		return interval
+	}
+	return time.Duration(float64(interval) * (1 + c.Jitter*(2*rand.Float64()-1)))
+}
+
+// isCheckFailing reports whether the published result of a check is failing.
+// A check without a result yet is not.
+func (s *Scheduler) isCheckFailing(key string) bool {
+	s.mu.RLock()
+	cachedResult, exists := s.results[key]
+	s.mu.RUnlock()
+	if !exists {
+		return false
+	}
+
+	cachedResult.mu.RLock()
+	defer cachedResult.mu.RUnlock()
+	if cachedResult.Result == nil && cachedResult.Error == nil {
+		return false
+	}
+	return isFailing(cachedResult.Result, cachedResult.Error)
+}
+
+// runCheck performs a scheduled check unless it is paused or shed under memory pressure
+func (s *Scheduler) runCheck(check *ScheduledCheck, key string) {
+	if s.isCheckPaused(key) {
+		s.publishPaused(key, check.DatabaseName, check.TableName)
+		return
+	}
+
+	if s.isPaused(check.DatabaseName) {
+		s.logger.Debug("Skipping paused health check",
+			"database", check.DatabaseName,
+			"table", check.TableName)
+		return
+	}
+
+	if check.Sheddable && s.service.UnderMemoryPressure() {
+		s.logger.Debug("Skipping non-critical health check under memory pressure",
+			"database", check.DatabaseName,
+			"table", check.TableName)
+		return
+	}
 
-	// Perform initial check
 	s.performHealthCheck(check.DatabaseName, check.TableName, key)
+}
 
-	// Set up ticker for periodic checks
-	check.ticker = time.NewTicker(check.Interval)
-	defer check.ticker.Stop()
-
-	for {
-		select {
-		case <-check.ticker.C:
-			s.performHealthCheck(check.DatabaseName, check.TableName, key)
-		case <-check.stopCh:
-			s.logger.Debug("Stopping scheduled check",
-				"database", check.DatabaseName,
-				"table", check.TableName)
-			return
-		case <-s.ctx.Done():
-			return
+// Refresh runs the scheduled check of a table immediately, updating the
+// cache, and waits for it to complete. Disabled tables have nothing to refresh.
+func (s *Scheduler) Refresh(ctx context.Context, databaseName, tableName string) error {
+	key := s.getCheckKey(databaseName, tableName)
+
+	s.mu.RLock()
+	check, checkExists := s.checks[key]
+	_, resultExists := s.results[key]
+	s.mu.RUnlock()
+
+	if !checkExists {
+		if resultExists {
+			return nil
 		}
+		return NewNotFoundError(databaseName, tableName, "table not found in database configuration")
+	}
+
+	if s.ctx.Err() != nil {
+		return NewInternalError(databaseName, tableName, "scheduler stopped")
+	}
+
+	// A paused check stays muted until it is resumed
+	if s.isCheckPaused(key) {
+		return nil
+	}
+
+	// Refreshes are queued behind a scheduled check that is already running
+	if !check.lock(ctx) {
+		return NewTimeoutError(databaseName, tableName, "timed out waiting to refresh", ctx.Err())
 	}
+	defer check.unlock()
+
+	// A refresh restarts the interval so the next check is not due right away
+	s.performHealthCheck(databaseName, tableName, key)
+	s.enqueue(check, time.Now().Add(check.nextInterval(s.isCheckFailing(key))))
+	return nil
+}
+
+// RefreshDatabase refreshes the scheduled checks of all tables in a database
+// concurrently and waits for them to complete
+func (s *Scheduler) RefreshDatabase(ctx context.Context, databaseName string) error {
+	s.mu.RLock()
+	var tableNames []string
+	for key := range s.results {
+		if s.checkKeyMatches(key, databaseName, "") {
+			_, tableName := s.parseCheckKey(key)
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	s.mu.RUnlock()
+
+	if len(tableNames) == 0 {
+		return NewNotFoundError(databaseName, "", "database not found in configuration")
+	}
+
+	errs := make([]error, len(tableNames))
+	var wg sync.WaitGroup
+	for i, tableName := range tableNames {
+		wg.Add(1)
+		go func(i int, tableName string) {
+			defer wg.Done()
+			errs[i] = s.Refresh(ctx, databaseName, tableName)
+		}(i, tableName)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // performHealthCheck executes a health check and updates the cached result
 func (s *Scheduler) performHealthCheck(databaseName, tableName, key string) {
-	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
-	defer cancel()
-
 	s.logger.Debug("Performing scheduled health check",
 		"database", databaseName,
 		"table", tableName)
 
-	result, err := s.service.CheckHealth(ctx, databaseName, tableName)
-
-	// Update cached result
 	s.mu.RLock()
 	cachedResult, exists := s.results[key]
+	check := s.checks[key]
 	s.mu.RUnlock()
 
+	// Table checks are skipped while their database's connection check fails,
+	// instead of each waiting for its timeout
+	var result *database.HealthResult
+	var err error
+	started := time.Now()
+	if check != nil && !check.ConnectionCheck && s.connectionDown(databaseName) {
+		result = s.skippedResult(databaseName, tableName)
+	} else {
+		var ran bool
+		if result, err, ran = s.runLimited(databaseName, tableName); !ran {
+			return
+		}
+	}
+
+	// The database of a check removed while it ran was forgotten already, so
+	// the outcome is dropped rather than recorded and published
+	if check != nil && s.isRemoved(check) {
+		s.service.forgetDowntime(databaseName, tableName)
+		s.logger.Debug("Dropping result of removed health check",
+			"database", databaseName,
+			"table", tableName)
+		return
+	}
+
+	if check != nil && check.ConnectionCheck {
+		s.recordConnectionState(databaseName, isFailing(result, err))
+	}
+
+	// Only scheduled runs sample the deadlock counter, so realtime requests
+	// do not shorten the interval the deadlocks are counted over
+	if check != nil && check.Deadlocks {
+		result = s.detectDeadlocks(check, result, err)
+	}
+
+	// Likewise only scheduled runs feed the query time baseline, so bursts of
+	// realtime requests do not skew it
+	if check != nil && check.AnomalyStddevs > 0 {
+		result = s.detectAnomaly(check, result, err)
+	}
+
+	// Compare the data with the previous check before it may be dropped
+	if exists && check != nil && check.AlertOnChange {
+		result = s.detectChange(cachedResult, result, err)
+	}
+
+	// Keep cached results small while memory is tight
+	if result != nil && result.Data != nil && s.service.UnderMemoryPressure() {
+		result = withoutData(result)
+	}
+
 	if exists {
+		failureThreshold, successThreshold := 1, 1
+		if check != nil {
+			failureThreshold, successThreshold = check.FailureThreshold, check.SuccessThreshold
+		}
+
+		// The run starts when the check does, not while it waits for a slot
+		if result != nil && result.Timestamp.After(started) {
+			started = result.Timestamp
+		}
+
 		cachedResult.mu.Lock()
-		cachedResult.Result = result
-		cachedResult.Error = err
-		cachedResult.UpdatedAt = time.Now()
+		previousStatus := cachedResult.status()
+		cachedResult.runs.record(started, time.Since(started), isFailing(result, err))
+		cachedResult.recent.add(newRecentResult(result, started), s.service.config.History.Recent)
+		published := cachedResult.shouldPublish(isFailing(result, err), failureThreshold, successThreshold)
+		if published {
+			cachedResult.Result, cachedResult.Error = s.service.detectFlapping(databaseName, tableName, result, err, time.Now())
+			cachedResult.UpdatedAt = time.Now()
+		}
+		publishedResult, publishedErr := cachedResult.Result, cachedResult.Error
+		streak := cachedResult.streak
 		cachedResult.mu.Unlock()
 
+		// History keeps every check; the published status only changes once
+		// a threshold is crossed
+		s.service.recordHistory(databaseName, tableName, result, err)
+		if published {
+			s.service.publishStatusChange(databaseName, tableName, previousStatus, publishedResult, publishedErr)
+		} else {
+			s.logger.Debug("Keeping published status until the threshold is reached",
+				"database", databaseName,
+				"table", tableName,
+				"status", previousStatus,
+				"consecutive", streak)
+		}
+		s.service.publishCheckCompleted(databaseName, tableName, result, err)
+
 		if err != nil {
 			s.logger.Debug("Scheduled health check failed (expected during startup/outages)",
 				"database", databaseName,
@@ -174,12 +512,93 @@ func (s *Scheduler) performHealthCheck(databaseName, tableName, key string) {
 	}
 }
 
+// shouldPublish reports whether a check outcome replaces the cached result.
+// Outcomes that agree with the published result on whether the check is
+// failing are published right away; a change is published once it has held
+// for threshold consecutive checks. Callers must hold c.mu.
+func (c *CachedResult) shouldPublish(failing bool, failureThreshold, successThreshold int) bool {
+	// The first result is published as is
+	if c.Result == nil && c.Error == nil {
+		return true
+	}
+
+	if failing == isFailing(c.Result, c.Error) {
+		c.streak = 0
+		return true
+	}
+
+	c.streak++
+	threshold := successThreshold
+	if failing {
+		threshold = failureThreshold
+	}
+	if c.streak < threshold {
+		return false
+	}
+
+	c.streak = 0
+	return true
+}
+
+// isFailing reports whether a check outcome counts as a failure for the
+// consecutive thresholds. Degraded, maintenance and disabled results do not.
+func isFailing(result *database.HealthResult, err error) bool {
+	if err != nil || result == nil {
+		return true
+	}
+	return result.Status.Code() > database.StatusDegraded.Code()
+}
+
+// status returns the status of a cached result, empty before the first check
+// completes; callers must hold c.mu
+func (c *CachedResult) status() database.Status {
+	switch {
+	case c.Result != nil:
+		return c.Result.Status
+	case c.Error != nil:
+		return database.StatusError
+	default:
+		return ""
+	}
+}
+
+// dropCachedData replaces cached results with copies that carry no query data
+func (s *Scheduler) dropCachedData() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, cachedResult := range s.results {
+		cachedResult.mu.Lock()
+		if cachedResult.Result != nil && cachedResult.Result.Data != nil {
+			cachedResult.Result = withoutData(cachedResult.Result)
+		}
+		cachedResult.mu.Unlock()
+	}
+}
+
+// withoutData returns a copy of a result without its query data. Results are
+// shared with readers, so they are copied rather than modified in place.
+func withoutData(result *database.HealthResult) *database.HealthResult {
+	stripped := *result
+	stripped.Data = nil
+	return &stripped
+}
+
+// cacheSize returns the number of cached results
+func (s *Scheduler) cacheSize() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.results)
+}
+
 // GetCachedResult returns the cached result for a specific database/table
 func (s *Scheduler) GetCachedResult(databaseName, tableName string) (*database.HealthResult, error, time.Time) {
 	key := s.getCheckKey(databaseName, tableName)
 
 	s.mu.RLock()
 	cachedResult, exists := s.results[key]
+	check := s.checks[key]
 	s.mu.RUnlock()
 
 	if !exists {
@@ -189,7 +608,8 @@ func (s *Scheduler) GetCachedResult(databaseName, tableName string) (*database.H
 	cachedResult.mu.RLock()
 	defer cachedResult.mu.RUnlock()
 
-	return cachedResult.Result, cachedResult.Error, cachedResult.UpdatedAt
+	result, err := s.overlayDisconnected(databaseName, tableName, cachedResult.Result, cachedResult.Error)
+	return s.withRuns(result, check, cachedResult), err, cachedResult.UpdatedAt
 }
 
 // GetCachedDatabaseResults returns cached results for all tables in a database
@@ -203,20 +623,26 @@ func (s *Scheduler) GetCachedDatabaseResults(databaseName string) ([]*database.H
 	for key, cachedResult := range s.results {
 		if s.checkKeyMatches(key, databaseName, "") {
 			found = true
+			_, tableName := s.parseCheckKey(key)
 			cachedResult.mu.RLock()
-			if cachedResult.Result != nil {
-				results = append(results, cachedResult.Result)
-			} else if cachedResult.Error != nil {
+			result, err := s.overlayDisconnected(databaseName, tableName, cachedResult.Result, cachedResult.Error)
+			if result != nil {
+				results = append(results, s.withRuns(result, s.checks[key], cachedResult))
+			} else if err != nil {
 				// Create error result
-				_, tableName := s.parseCheckKey(key)
 				errorResult := &database.HealthResult{
 					DatabaseName: databaseName,
 					TableName:    tableName,
-					Status:       "error",
-					Error:        cachedResult.Error.Error(),
+					Labels:       s.service.GetTableLabels(databaseName, tableName),
+					Severity:     s.service.GetTableSeverity(databaseName, tableName),
+					Status:       database.StatusError,
+					Error:        err.Error(),
 					Timestamp:    cachedResult.UpdatedAt,
 				}
-				results = append(results, errorResult)
+				if since, down := s.service.unhealthySinceFor(databaseName, tableName); down {
+					annotateDowntime(errorResult, since)
+				}
+				results = append(results, s.withRuns(errorResult, s.checks[key], cachedResult))
 			}
 			cachedResult.mu.RUnlock()
 		}
@@ -237,22 +663,27 @@ func (s *Scheduler) GetAllCachedResults() map[string][]*database.HealthResult {
 	results := make(map[string][]*database.HealthResult)
 
 	for key, cachedResult := range s.results {
-		databaseName, _ := s.parseCheckKey(key)
+		databaseName, tableName := s.parseCheckKey(key)
 
 		cachedResult.mu.RLock()
-		if cachedResult.Result != nil {
-			results[databaseName] = append(results[databaseName], cachedResult.Result)
-		} else if cachedResult.Error != nil {
+		result, err := s.overlayDisconnected(databaseName, tableName, cachedResult.Result, cachedResult.Error)
+		if result != nil {
+			results[databaseName] = append(results[databaseName], s.withRuns(result, s.checks[key], cachedResult))
+		} else if err != nil {
 			// Create error result
-			_, tableName := s.parseCheckKey(key)
 			errorResult := &database.HealthResult{
 				DatabaseName: databaseName,
 				TableName:    tableName,
-				Status:       "error",
-				Error:        cachedResult.Error.Error(),
+				Labels:       s.service.GetTableLabels(databaseName, tableName),
+				Severity:     s.service.GetTableSeverity(databaseName, tableName),
+				Status:       database.StatusError,
+				Error:        err.Error(),
 				Timestamp:    cachedResult.UpdatedAt,
 			}
-			results[databaseName] = append(results[databaseName], errorResult)
+			if since, down := s.service.unhealthySinceFor(databaseName, tableName); down {
+				annotateDowntime(errorResult, since)
+			}
+			results[databaseName] = append(results[databaseName], s.withRuns(errorResult, s.checks[key], cachedResult))
 		}
 		cachedResult.mu.RUnlock()
 	}
@@ -275,10 +706,12 @@ func (s *Scheduler) IsResultFresh(databaseName, tableName string) bool {
 
 	cachedResult.mu.RLock()
 	updatedAt := cachedResult.UpdatedAt
+	stale := cachedResult.Result != nil && cachedResult.Result.Stale
 	cachedResult.mu.RUnlock()
 
-	// Consider result fresh if it's within the check interval
-	return time.Since(updatedAt) < check.Interval
+	// Consider result fresh if it's within the check interval; results from
+	// before a restart never are
+	return !stale && time.Since(updatedAt) < check.Interval
 }
 
 // getCheckKey creates a unique key for a database/table combination
@@ -317,7 +750,7 @@ func (s *Scheduler) checkKeyMatches(key, databaseName, tableName string) bool {
 }
 
 // GetCacheStats returns statistics about the cached results
-func (s *Scheduler) GetCacheStats() map[string]interface{} {
+func (s *Scheduler) GetCacheStats() CacheStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -325,12 +758,21 @@ func (s *Scheduler) GetCacheStats() map[string]interface{} {
 	freshResults := 0
 	healthyResults := 0
 	unhealthyResults := 0
+	disabledResults := 0
+	maintenanceResults := 0
+	pausedResults := 0
 
 	for key, cachedResult := range s.results {
 		cachedResult.mu.RLock()
 		if cachedResult.Result != nil {
-			if cachedResult.Result.Status == "healthy" {
+			if cachedResult.Result.Status == database.StatusHealthy {
 				healthyResults++
+			} else if cachedResult.Result.Status == database.StatusDisabled {
+				disabledResults++
+			} else if cachedResult.Result.Status == database.StatusMaintenance {
+				maintenanceResults++
+			} else if cachedResult.Result.Status == database.StatusPaused {
+				pausedResults++
 			} else {
 				unhealthyResults++
 			}
@@ -343,10 +785,14 @@ func (s *Scheduler) GetCacheStats() map[string]interface{} {
 		}
 	}
 
-	return map[string]interface{}{
-		"total_checks":     totalChecks,
-		"fresh_results":    freshResults,
-		"healthy_results":  healthyResults,
-		"unhealthy_results": unhealthyResults,
+	return CacheStats{
+		TotalChecks:        totalChecks,
+		FreshResults:       freshResults,
+		HealthyResults:     healthyResults,
+		UnhealthyResults:   unhealthyResults,
+		DisabledResults:    disabledResults,
+		MaintenanceResults: maintenanceResults,
+		PausedResults:      pausedResults,
+		Checks:             s.checkRunStats(),
 	}
 }
\ No newline at end of file
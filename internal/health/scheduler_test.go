@@ -0,0 +1,296 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestRefresh(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "table1", CheckInterval: config.Duration(time.Hour)},
+			{Name: "archive", Enabled: &disabled},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	tests := []struct {
+		name          string
+		table         string
+		expectRefresh bool
+		expectErr     bool
+	}{
+		{"scheduled check", "table1", true, false},
+		{"disabled check", "archive", false, false},
+		{"unknown table", "missing", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := service.RefreshHealth(ctx, "test", tt.table)
+			if tt.expectErr {
+				var healthErr *HealthError
+				if !errors.As(err, &healthErr) || !healthErr.IsNotFoundError() {
+					t.Fatalf("RefreshHealth() error = %v; expected a not found error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RefreshHealth() error = %v", err)
+			}
+
+			_, _, updatedAt := service.GetCachedHealth("test", tt.table)
+			if refreshed := !updatedAt.Before(before); refreshed != tt.expectRefresh {
+				t.Errorf("refreshed = %v; expected %v", refreshed, tt.expectRefresh)
+			}
+		})
+	}
+}
+
+func TestRefreshDatabase(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "table1", CheckInterval: config.Duration(time.Hour)},
+			{Name: "table2", CheckInterval: config.Duration(time.Hour)},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	before := time.Now()
+	if err := service.RefreshDatabaseHealth(context.Background(), "test"); err != nil {
+		t.Fatalf("RefreshDatabaseHealth() error = %v", err)
+	}
+	for _, table := range []string{"table1", "table2"} {
+		if _, _, updatedAt := service.GetCachedHealth("test", table); updatedAt.Before(before) {
+			t.Errorf("%s was not refreshed", table)
+		}
+	}
+
+	if err := service.RefreshDatabaseHealth(context.Background(), "missing"); err == nil {
+		t.Errorf("Expected an error for an unknown database")
+	}
+}
+
+func TestConnectionDependency(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Type: "postgres", Tables: []config.Table{
+			{Name: "connection", Check: config.CheckConnection, Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			{Name: "users", Query: "SELECT 1", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	driver := &sequenceDriver{pingErr: errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")}
+	service.drivers["test"] = driver
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer service.scheduler.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, table := range []string{"connection", "users"} {
+		if err := service.RefreshHealth(ctx, "test", table); err != nil {
+			t.Fatalf("RefreshHealth(%s) error = %v", table, err)
+		}
+	}
+
+	result, _, _ := service.GetCachedHealth("test", "users")
+	if result == nil || result.Status != database.StatusUnhealthy || result.Error != skippedConnectionDown {
+		t.Fatalf("users = %+v; expected it skipped while the connection is down", result)
+	}
+
+	// A recovered connection refreshes the skipped checks right away
+	driver.pingErr = nil
+	if err := service.RefreshHealth(ctx, "test", "connection"); err != nil {
+		t.Fatalf("RefreshHealth(connection) error = %v", err)
+	}
+	for {
+		result, _, _ := service.GetCachedHealth("test", "users")
+		if result != nil && result.Status == database.StatusHealthy {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("users = %+v; expected it checked again once the connection recovered", result)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestNextInterval(t *testing.T) {
+	check := &ScheduledCheck{Interval: 30 * time.Second}
+	if interval := check.nextInterval(false); interval != 30*time.Second {
+		t.Errorf("nextInterval() without jitter = %v; expected 30s", interval)
+	}
+
+	check.Jitter = 0.1
+	spread := false
+	for i := 0; i < 100; i++ {
+		interval := check.nextInterval(false)
+		if interval < 27*time.Second || interval > 33*time.Second {
+			t.Fatalf("nextInterval() = %v; expected within 10%% of 30s", interval)
+		}
+		spread = spread || interval != 30*time.Second
+	}
+	if !spread {
+		t.Error("Expected jittered intervals to vary")
+	}
+}
+
+func TestNextIntervalRecheck(t *testing.T) {
+	check := &ScheduledCheck{Interval: 30 * time.Second}
+	if interval := check.nextInterval(true); interval != 30*time.Second {
+		t.Errorf("nextInterval(true) without recheck interval = %v; expected 30s", interval)
+	}
+
+	check.Recheck = 5 * time.Second
+	if interval := check.nextInterval(true); interval != 5*time.Second {
+		t.Errorf("nextInterval(true) = %v; expected the recheck interval 5s", interval)
+	}
+	if interval := check.nextInterval(false); interval != 30*time.Second {
+		t.Errorf("nextInterval(false) = %v; expected the check interval 30s", interval)
+	}
+
+	check.Recheck = 5 * time.Minute
+	check.Jitter = 0.1
+	for i := 0; i < 100; i++ {
+		if interval := check.nextInterval(true); interval < 270*time.Second || interval > 330*time.Second {
+			t.Fatalf("nextInterval(true) = %v; expected within 10%% of 5m", interval)
+		}
+	}
+}
+
+func TestFirstRunDelay(t *testing.T) {
+	cfg := &config.Config{}
+	scheduler := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil))).scheduler
+	check := &ScheduledCheck{Interval: time.Minute}
+
+	delays := make(map[time.Duration]bool)
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("db/table%d", i)
+		delay := scheduler.firstRunDelay(check, key)
+		if delay < 0 || delay >= time.Minute {
+			t.Fatalf("firstRunDelay(%s) = %v; expected within the first interval", key, delay)
+		}
+		if again := scheduler.firstRunDelay(check, key); again != delay {
+			t.Errorf("firstRunDelay(%s) = %v, then %v; expected the same delay on every start", key, delay, again)
+		}
+		delays[delay] = true
+	}
+	if len(delays) < 10 {
+		t.Errorf("firstRunDelay() gave %d distinct delays for 20 checks; expected them spread", len(delays))
+	}
+
+	stagger := false
+	cfg.Scheduler.Stagger = &stagger
+	if delay := scheduler.firstRunDelay(check, "db/table0"); delay != 0 {
+		t.Errorf("firstRunDelay() without stagger = %v; expected the check to run right away", delay)
+	}
+}
+
+func TestShouldPublish(t *testing.T) {
+	healthy := &database.HealthResult{Status: database.StatusHealthy}
+	degraded := &database.HealthResult{Status: database.StatusDegraded}
+	unhealthy := &database.HealthResult{Status: database.StatusUnhealthy}
+	connectionErr := NewConnectionError("db", "users", "database connection failed", nil)
+
+	type outcome struct {
+		result  *database.HealthResult
+		err     error
+		publish bool
+	}
+
+	tests := []struct {
+		name     string
+		outcomes []outcome
+	}{
+		{"first result", []outcome{{unhealthy, nil, true}}},
+		{"transient failure", []outcome{
+			{healthy, nil, true},
+			{unhealthy, nil, false},
+			{healthy, nil, true},
+			{nil, connectionErr, false},
+			{unhealthy, nil, false},
+			{unhealthy, nil, true},
+		}},
+		{"degraded is not a failure", []outcome{{healthy, nil, true}, {degraded, nil, true}}},
+		{"recovery", []outcome{
+			{unhealthy, nil, true},
+			{healthy, nil, false},
+			{unhealthy, nil, true},
+			{healthy, nil, false},
+			{healthy, nil, true},
+			{unhealthy, nil, false},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cached := &CachedResult{}
+			for i, o := range tt.outcomes {
+				published := cached.shouldPublish(isFailing(o.result, o.err), 3, 2)
+				if published != o.publish {
+					t.Fatalf("Outcome %d: shouldPublish() = %v; expected %v", i, published, o.publish)
+				}
+				if published {
+					cached.Result, cached.Error = o.result, o.err
+				}
+			}
+		})
+	}
+}
+
+func TestRemovedCheckOutcomeDropped(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "users", Query: "SELECT 1", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.drivers["test"] = &sequenceDriver{queryErr: errors.New("connection reset")}
+	events, unsubscribe := service.Subscribe(4)
+	defer unsubscribe()
+
+	scheduler := service.scheduler
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer scheduler.Stop()
+
+	// The check is removed with its database while it runs
+	scheduler.unschedule(scheduler.checks["test/users"])
+	scheduler.performHealthCheck("test", "users", "test/users")
+
+	if _, down := service.unhealthySinceFor("test", "users"); down {
+		t.Error("Expected no downtime to be tracked for the removed check")
+	}
+	if _, exists := service.history["test/users"]; exists {
+		t.Error("Expected no history to be recorded for the removed check")
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Expected no event for the removed check, got %+v", event)
+	default:
+	}
+}
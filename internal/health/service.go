@@ -14,34 +14,114 @@ import (
 
 // Service manages health checks for multiple databases
 type Service struct {
-	config    *config.Config
-	manager   *database.Manager
-	factory   *database.DriverFactory
-	drivers   map[string]database.Driver // key: "database_name"
-	scheduler *Scheduler
-	mu        sync.RWMutex
-	logger    *slog.Logger
+	config      *config.Config
+	manager     *database.Manager
+	factory     *database.DriverFactory
+	drivers     map[string]database.Driver     // key: "database_name"
+	labels      map[string]map[string]string   // key: "database_name" or "database/table"
+	severities  map[string]string              // key: "database/table", warning and info checks only
+	maintenance map[string][]maintenanceWindow // key: "database_name", global windows included
+	activeHours map[string]*config.ActiveHours // key: "database/table", restricted checks only
+	scheduler   *Scheduler
+	watchdog    *Watchdog // nil when disabled
+	memory      *memoryMonitor
+	events      *eventBroker
+	eventLog    *eventLog
+	mu          sync.RWMutex
+	logger      *slog.Logger
+
+	panics  map[string]int // consecutive panics per "database/table"
+	panicMu sync.Mutex
+
+	unhealthySince map[string]time.Time // outage start per "database/table"
+	healthySince   map[string]time.Time // start of the healthy streak per "database/table"
+	downtimeMu     sync.Mutex
+
+	history      map[string]*checkHistory // scheduled check results per "database/table"
+	historyMu    sync.RWMutex
+	historyStore HistoryStore // replaces history with a database backend
+
+	flaps  map[string]*flapState // published status changes per "database/table"
+	flapMu sync.Mutex
+
+	deadlocks  map[string]deadlockSample // last deadlock counter per "database/table"
+	deadlockMu sync.Mutex
+
+	baselines  map[string]*latencyBaseline // query time baseline per "database/table"
+	baselineMu sync.Mutex
+
+	disconnected map[string]time.Time // connection loss per "database_name"
+	disconnectMu sync.RWMutex
+
+	silences *silences // notification silences added through the admin API
+
+	// indexMu guards labels, severities, maintenance and activeHours, which change as
+	// databases are added and removed through the admin API
+	indexMu sync.RWMutex
+
+	background context.Context               // of Initialize; background connections end with it
+	connecting map[string]context.CancelFunc // ends the connection retries of dynamic databases
 }
 
 // NewService creates a new health check service
 func NewService(cfg *config.Config, logger *slog.Logger) *Service {
 	service := &Service{
-		config:  cfg,
-		manager: database.NewManager(),
-		factory: database.NewDriverFactory(),
-		drivers: make(map[string]database.Driver),
-		logger:  logger,
+		config:      cfg,
+		manager:     database.NewManager(),
+		factory:     database.NewDriverFactory(),
+		drivers:     make(map[string]database.Driver),
+		labels:      make(map[string]map[string]string),
+		severities:  make(map[string]string),
+		maintenance: make(map[string][]maintenanceWindow),
+		activeHours: make(map[string]*config.ActiveHours),
+		logger:      logger,
+		panics:      make(map[string]int),
+		events:      newEventBroker(),
+		eventLog:    newEventLog(cfg.History.GetEvents()),
+
+		unhealthySince: make(map[string]time.Time),
+		healthySince:   make(map[string]time.Time),
+		history:        make(map[string]*checkHistory),
+		flaps:          make(map[string]*flapState),
+		deadlocks:      make(map[string]deadlockSample),
+		baselines:      make(map[string]*latencyBaseline),
+		disconnected:   make(map[string]time.Time),
+		silences:       newSilences(),
+		background:     context.Background(),
+		connecting:     make(map[string]context.CancelFunc),
+	}
+
+	// Resolve effective labels once; they only change as databases are added
+	// and removed
+	for _, db := range cfg.Databases {
+		service.indexDatabase(db)
 	}
 
 	// Create scheduler
 	service.scheduler = NewScheduler(service, logger)
 
+	if cfg.Watchdog.Enabled {
+		service.watchdog = newWatchdog(service)
+	}
+
+	service.memory = newMemoryMonitor(service)
+
 	return service
 }
 
 // Initialize sets up the service and starts background database connections
 func (s *Service) Initialize(ctx context.Context) error {
 	s.logger.Info("Initializing health service")
+	s.background = ctx
+
+	if s.config.History.GetBackend() != config.HistoryBackendMemory {
+		historyStore, err := openHistoryStore(&s.config.History)
+		if err != nil {
+			return fmt.Errorf("failed to open history store: %w", err)
+		}
+		s.historyStore = historyStore
+		go s.pruneHistory(ctx)
+	}
 
 	// Start the scheduler for periodic health checks (even without database connections)
 	if err := s.scheduler.Start(); err != nil {
@@ -55,6 +135,14 @@ func (s *Service) Initialize(ctx context.Context) error {
 	// Start background connection recovery
 	go s.BackgroundConnectionRecovery(ctx)
 
+	// Start the resource watchdog
+	if s.watchdog != nil {
+		go s.watchdog.run(ctx)
+	}
+
+	// Start load shedding when a soft memory limit is in effect
+	go s.memory.run(ctx)
+
 	s.logger.Info("Health service initialized, database connections starting in background")
 	return nil
 }
@@ -64,62 +152,86 @@ func (s *Service) initializeDatabaseConnections(ctx context.Context) {
 	s.logger.Info("Starting database connection initialization in background")
 
 	connector := NewRetryableConnector(&s.config.Retry, s.logger)
-	connectedCount := 0
 
 	for _, dbConfig := range s.config.Databases {
+		if !dbConfig.IsEnabled() {
+			s.logger.Info("Skipping disabled database",
+				"database", dbConfig.Name)
+			continue
+		}
+
 		// Start each database connection attempt in its own goroutine
-		go func(dbConfig config.Database) {
-			driver, err := s.factory.CreateDriver(dbConfig.Type)
-			if err != nil {
-				s.logger.Error("Failed to create driver",
-					"database", dbConfig.Name,
-					"type", dbConfig.Type,
-					"error", err)
-				return
-			}
+		go s.connectDatabase(ctx, connector, dbConfig)
+	}
+}
 
-			connInfo := database.ConnectionInfo{
-				Host:     dbConfig.Host,
-				Port:     dbConfig.Port,
-				Username: dbConfig.Username,
-				Password: dbConfig.Password,
-				Database: dbConfig.Database,
-				SSLMode:  dbConfig.SSLMode,
-				Timeout:  30 * time.Second,
-			}
+// connectDatabase connects to a database, retrying until it succeeds or ctx
+// ends, and adds its driver
+func (s *Service) connectDatabase(ctx context.Context, connector *RetryableConnector, dbConfig config.Database) {
+	driver, err := s.factory.CreateDriver(dbConfig.Type)
+	if err != nil {
+		s.logger.Error("Failed to create driver",
+			"database", dbConfig.Name,
+			"type", dbConfig.Type,
+			"error", err)
+		return
+	}
 
-			// Attempt connection with infinite retry logic
-			if err := connector.ConnectWithRetry(ctx, driver, connInfo, dbConfig.Name); err != nil {
-				s.logger.Warn("Database connection initialization cancelled",
-					"database", dbConfig.Name,
-					"error", err)
-				driver.Close()
-				return
-			}
+	connInfo := database.ConnectionInfo{
+		Host:     dbConfig.Host,
+		Port:     dbConfig.Port,
+		Username: dbConfig.Username,
+		Password: dbConfig.Password,
+		Database: dbConfig.Database,
+		SSLMode:  dbConfig.SSLMode,
+		Timeout:  s.config.Retry.GetConnectTimeout(),
+	}
 
-			// Successfully connected, add to drivers map
-			s.mu.Lock()
-			s.drivers[dbConfig.Name] = driver
-			connectedCount++
-			s.mu.Unlock()
+	// Attempt connection with infinite retry logic
+	if err := connector.ConnectWithRetry(ctx, driver, connInfo, dbConfig.Name); err != nil {
+		s.logger.Warn("Database connection initialization cancelled",
+			"database", dbConfig.Name,
+			"error", err)
+		driver.Close()
+		return
+	}
 
-			s.logger.Info("Successfully connected to database",
-				"database", dbConfig.Name,
-				"type", dbConfig.Type,
-				"host", dbConfig.Host)
-		}(dbConfig)
+	// Successfully connected, add to drivers map unless the database was
+	// removed in the meantime
+	s.mu.Lock()
+	if _, configured := s.findDatabase(dbConfig.Name); !configured {
+		s.mu.Unlock()
+		driver.Close()
+		return
 	}
+	s.drivers[dbConfig.Name] = driver
+	s.mu.Unlock()
+
+	s.logger.Info("Successfully connected to database",
+		"database", dbConfig.Name,
+		"type", dbConfig.Type,
+		"host", dbConfig.Host)
+	s.markConnected(dbConfig.Name)
+	s.publishConnectionEvent(EventConnected, dbConfig.Name)
 }
 
 // CheckHealth performs a health check for a specific database and table
-func (s *Service) CheckHealth(ctx context.Context, databaseName, tableName string) (*database.HealthResult, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+func (s *Service) CheckHealth(ctx context.Context, databaseName, tableName string) (result *database.HealthResult, err error) {
+	defer func() {
+		// Panics are recorded by safeCheckHealth once converted to a result
+		if result != nil || err != nil {
+			s.recordDowntime(databaseName, tableName, result, err)
+		}
+	}()
 
 	// Find the database configuration
 	var dbConfig *config.Database
 	var tableConfig *config.Table
 
+	// The lock is not held while the check runs, so a query that hangs does
+	// not block databases from being added and removed
+	s.mu.RLock()
+	driver, exists := s.drivers[databaseName]
 	for _, db := range s.config.Databases {
 		if db.Name == databaseName {
 			dbConfig = &db
@@ -132,6 +244,7 @@ func (s *Service) CheckHealth(ctx context.Context, databaseName, tableName strin
 			break
 		}
 	}
+	s.mu.RUnlock()
 
 	if dbConfig == nil {
 		return nil, NewNotFoundError(databaseName, "", "database not found in configuration")
@@ -141,17 +254,51 @@ func (s *Service) CheckHealth(ctx context.Context, databaseName, tableName strin
 		return nil, NewNotFoundError(databaseName, tableName, "table not found in database configuration")
 	}
 
-	// Get the driver
-	driver, exists := s.drivers[databaseName]
+	// Disabled checks are reported without querying the database
+	if !dbConfig.IsTableEnabled(tableConfig) {
+		result := newDisabledResult(databaseName, tableName)
+		result.Labels = s.GetTableLabels(databaseName, tableName)
+		return result, nil
+	}
+
+	// Outside its active hours a check is paused rather than run
+	if !s.isActive(databaseName, tableName, time.Now()) {
+		return s.newPausedResult(databaseName, tableName), nil
+	}
+
 	if !exists {
+		if s.InMaintenance(databaseName, time.Now()) {
+			return &database.HealthResult{
+				DatabaseName: databaseName,
+				TableName:    tableName,
+				Labels:       s.GetTableLabels(databaseName, tableName),
+				Severity:     s.GetTableSeverity(databaseName, tableName),
+				Status:       database.StatusMaintenance,
+				Error:        "database connection failed",
+				Timestamp:    time.Now(),
+			}, nil
+		}
 		return nil, NewConnectionError(databaseName, tableName, "database connection failed", nil)
 	}
 
 	// Create result structure
-	result := &database.HealthResult{
+	result = &database.HealthResult{
 		DatabaseName: databaseName,
 		TableName:    tableName,
+		Labels:       s.GetTableLabels(databaseName, tableName),
+		Severity:     s.GetTableSeverity(databaseName, tableName),
 		Timestamp:    time.Now(),
+
+		WarnLatency:     tableConfig.GetWarnLatency(),
+		CriticalLatency: tableConfig.GetCriticalLatency(),
+	}
+
+	queries, err := renderQueries(dbConfig, tableConfig, result.Timestamp)
+	if err != nil {
+		checkFailures.Add(1)
+		result.Status = database.StatusUnhealthy
+		result.Error = err.Error()
+		return result, NewQueryError(databaseName, tableName, "query template failed", err)
 	}
 
 	// Set query timeout
@@ -162,13 +309,30 @@ func (s *Service) CheckHealth(ctx context.Context, databaseName, tableName strin
 	startTime := time.Now()
 
 	// Execute the health check query
-	data, err := driver.ExecuteHealthCheck(queryCtx, tableConfig.Query)
+	data, err := executeQueries(queryCtx, driver, tableConfig, queries)
 	result.QueryTime = time.Since(startTime)
+	checksRun.Add(1)
+	if err == nil && tableConfig.Check == config.CheckConnections {
+		recordConnectionUsage(databaseName, tableName, data)
+	}
 
 	if err != nil {
-		result.Status = "unhealthy"
+		checkFailures.Add(1)
+		result.Status = database.StatusUnhealthy
 		result.Error = err.Error()
-		s.logger.Error("Health check failed",
+
+		// Failures inside a maintenance window are expected and not escalated
+		if s.InMaintenance(databaseName, result.Timestamp) {
+			result.Status = database.StatusMaintenance
+			s.logger.InfoContext(ctx, "Health check failed during maintenance window",
+				"database", databaseName,
+				"table", tableName,
+				"query_time", result.QueryTime,
+				"error", err)
+			return result, nil
+		}
+
+		s.logger.ErrorContext(ctx, "Health check failed",
 			"database", databaseName,
 			"table", tableName,
 			"query_time", result.QueryTime,
@@ -182,10 +346,62 @@ func (s *Service) CheckHealth(ctx context.Context, databaseName, tableName strin
 		} else {
 			return result, NewQueryError(databaseName, tableName, "query execution failed", err)
 		}
+	} else if failed := checkExpectations(tableConfig, data); failed != "" {
+		// The query succeeded but returned data the table does not expect
+		checkFailures.Add(1)
+		result.Data = data
+		result.Error = failed
+		result.Status = database.StatusUnhealthy
+		if s.InMaintenance(databaseName, result.Timestamp) {
+			result.Status = database.StatusMaintenance
+		}
+		s.logger.WarnContext(ctx, "Health check failed expectations",
+			"database", databaseName,
+			"table", tableName,
+			"query_time", result.QueryTime,
+			"error", failed)
+	} else if status, saturated := checkConnections(tableConfig, data); status != database.StatusHealthy {
+		// The query succeeded, but the database is running out of connections
+		if status == database.StatusUnhealthy {
+			checkFailures.Add(1)
+		}
+		result.Data = data
+		result.Error = saturated
+		result.Status = status
+		if s.InMaintenance(databaseName, result.Timestamp) {
+			result.Status = database.StatusMaintenance
+		}
+		s.logger.WarnContext(ctx, "Health check exceeded connection usage threshold",
+			"database", databaseName,
+			"table", tableName,
+			"query_time", result.QueryTime,
+			"error", saturated)
+	} else if status, exceeded := checkLatency(tableConfig, result.QueryTime); status != database.StatusHealthy {
+		// The query succeeded, but too slowly
+		if status == database.StatusDegraded {
+			slowChecks.Add(1)
+		} else {
+			checkFailures.Add(1)
+			latencyFailures.Add(1)
+		}
+		result.Data = data
+		result.Error = exceeded
+		result.Status = status
+		if s.InMaintenance(databaseName, result.Timestamp) {
+			result.Status = database.StatusMaintenance
+		}
+		if tableConfig.CapturePlan {
+			result.Plan = s.capturePlan(ctx, driver, dbConfig, tableConfig, queries[0])
+		}
+		s.logger.WarnContext(ctx, "Health check exceeded latency threshold",
+			"database", databaseName,
+			"table", tableName,
+			"query_time", result.QueryTime,
+			"error", exceeded)
 	} else {
-		result.Status = "healthy"
+		result.Status = database.StatusHealthy
 		result.Data = data
-		s.logger.Debug("Health check successful",
+		s.logger.DebugContext(ctx, "Health check successful",
 			"database", databaseName,
 			"table", tableName,
 			"query_time", result.QueryTime)
@@ -196,44 +412,41 @@ func (s *Service) CheckHealth(ctx context.Context, databaseName, tableName strin
 
 // CheckDatabaseHealth performs health checks for all tables in a database
 func (s *Service) CheckDatabaseHealth(ctx context.Context, databaseName string) ([]*database.HealthResult, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// Find the database configuration
-	var dbConfig *config.Database
-	for _, db := range s.config.Databases {
-		if db.Name == databaseName {
-			dbConfig = &db
-			break
-		}
-	}
-
-	if dbConfig == nil {
-		return nil, fmt.Errorf("database %s not found", databaseName)
+	// The table names are copied so the lock is released before any check
+	// runs, as CheckHealth takes it again and a hanging query must not block
+	// AddDatabase and RemoveDatabase
+	tableNames, err := s.GetTableNames(databaseName)
+	if err != nil {
+		return nil, err
 	}
 
 	var results []*database.HealthResult
 	var wg sync.WaitGroup
-	resultsChan := make(chan *database.HealthResult, len(dbConfig.Tables))
+	resultsChan := make(chan *database.HealthResult, len(tableNames))
 
 	// Execute health checks concurrently for all tables
-	for _, table := range dbConfig.Tables {
+	for _, tableName := range tableNames {
 		wg.Add(1)
 		go func(tableName string) {
 			defer wg.Done()
-			result, err := s.CheckHealth(ctx, databaseName, tableName)
-			if err != nil {
+			result, err := s.safeCheckHealth(ctx, databaseName, tableName)
+			if err != nil && (result == nil || result.Status != database.StatusInternalError) {
 				// Create error result if health check fails
 				result = &database.HealthResult{
 					DatabaseName: databaseName,
 					TableName:    tableName,
-					Status:       "error",
+					Labels:       s.GetTableLabels(databaseName, tableName),
+					Severity:     s.GetTableSeverity(databaseName, tableName),
+					Status:       database.StatusError,
 					Error:        err.Error(),
 					Timestamp:    time.Now(),
 				}
+				if since, down := s.unhealthySinceFor(databaseName, tableName); down {
+					annotateDowntime(result, since)
+				}
 			}
 			resultsChan <- result
-		}(table.Name)
+		}(tableName)
 	}
 
 	// Wait for all checks to complete
@@ -252,15 +465,13 @@ func (s *Service) CheckDatabaseHealth(ctx context.Context, databaseName string)
 
 // CheckAllHealth performs health checks for all databases and tables
 func (s *Service) CheckAllHealth(ctx context.Context) (map[string][]*database.HealthResult, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	results := make(map[string][]*database.HealthResult)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	// Execute health checks concurrently for all databases
-	for _, dbConfig := range s.config.Databases {
+	// Execute health checks concurrently for all databases, iterating over a
+	// copy of the names so the lock is not held while they run
+	for _, databaseName := range s.GetDatabaseNames() {
 		wg.Add(1)
 		go func(databaseName string) {
 			defer wg.Done()
@@ -273,7 +484,8 @@ func (s *Service) CheckAllHealth(ctx context.Context) (map[string][]*database.He
 				dbResults = []*database.HealthResult{{
 					DatabaseName: databaseName,
 					TableName:    "all",
-					Status:       "error",
+					Labels:       s.GetDatabaseLabels(databaseName),
+					Status:       database.StatusError,
 					Error:        err.Error(),
 					Timestamp:    time.Now(),
 				}}
@@ -282,7 +494,7 @@ func (s *Service) CheckAllHealth(ctx context.Context) (map[string][]*database.He
 			mu.Lock()
 			results[databaseName] = dbResults
 			mu.Unlock()
-		}(dbConfig.Name)
+		}(databaseName)
 	}
 
 	wg.Wait()
@@ -296,6 +508,9 @@ func (s *Service) Ping(ctx context.Context, databaseName string) error {
 
 	driver, exists := s.drivers[databaseName]
 	if !exists {
+		if !s.isDatabaseEnabled(databaseName) {
+			return fmt.Errorf("database %s is disabled", databaseName)
+		}
 		return fmt.Errorf("driver for database %s not initialized", databaseName)
 	}
 
@@ -317,6 +532,12 @@ func (s *Service) Close() error {
 		}
 	}
 
+	if s.historyStore != nil {
+		if err := s.historyStore.Close(); err != nil {
+			errors = append(errors, fmt.Errorf("failed to close history store: %w", err))
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("errors closing connections: %v", errors)
 	}
@@ -325,6 +546,15 @@ func (s *Service) Close() error {
 	return nil
 }
 
+// GetDatabases returns the configured databases. Databases are replaced
+// rather than modified, so the returned configurations stay valid.
+func (s *Service) GetDatabases() []config.Database {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.config.Databases
+}
+
 // GetDatabaseNames returns a list of configured database names
 func (s *Service) GetDatabaseNames() []string {
 	s.mu.RLock()
@@ -355,6 +585,90 @@ func (s *Service) GetTableNames(databaseName string) ([]string, error) {
 	return nil, fmt.Errorf("database %s not found", databaseName)
 }
 
+// IsDatabaseEnabled reports whether checks for a configured database are enabled
+func (s *Service) IsDatabaseEnabled(databaseName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.isDatabaseEnabled(databaseName)
+}
+
+// isDatabaseEnabled reports whether a database is enabled; callers must hold s.mu
+func (s *Service) isDatabaseEnabled(databaseName string) bool {
+	for _, db := range s.config.Databases {
+		if db.Name == databaseName {
+			return db.IsEnabled()
+		}
+	}
+	return false
+}
+
+// IsTableEnabled reports whether checks for a configured table are enabled
+func (s *Service) IsTableEnabled(databaseName, tableName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, db := range s.config.Databases {
+		if db.Name != databaseName {
+			continue
+		}
+		for _, table := range db.Tables {
+			if table.Name == tableName {
+				return db.IsTableEnabled(&table)
+			}
+		}
+	}
+	return false
+}
+
+// GetDatabaseLabels returns the labels configured on a database
+func (s *Service) GetDatabaseLabels(databaseName string) map[string]string {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+
+	return s.labels[databaseName]
+}
+
+// GetTableLabels returns the effective labels of a table (database labels merged with table labels)
+func (s *Service) GetTableLabels(databaseName, tableName string) map[string]string {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+
+	return s.labels[databaseName+"/"+tableName]
+}
+
+// GetTableSeverity returns the severity of a table's check as reported in its
+// results: "warning" or "info", or empty for critical checks
+func (s *Service) GetTableSeverity(databaseName, tableName string) string {
+	s.indexMu.RLock()
+	defer s.indexMu.RUnlock()
+
+	return s.severities[databaseName+"/"+tableName]
+}
+
+// newDisabledResult creates the result reported for a disabled check
+func newDisabledResult(databaseName, tableName string) *database.HealthResult {
+	return &database.HealthResult{
+		DatabaseName: databaseName,
+		TableName:    tableName,
+		Status:       database.StatusDisabled,
+		Timestamp:    time.Now(),
+	}
+}
+
+// newPausedResult creates the result reported for a check that is paused,
+// outside its active hours or through the admin API
+func (s *Service) newPausedResult(databaseName, tableName string) *database.HealthResult {
+	return &database.HealthResult{
+		DatabaseName: databaseName,
+		TableName:    tableName,
+		Labels:       s.GetTableLabels(databaseName, tableName),
+		Severity:     s.GetTableSeverity(databaseName, tableName),
+		Status:       database.StatusPaused,
+		Timestamp:    time.Now(),
+	}
+}
+
 // isConnectionError determines if an error is related to database connectivity
 func (s *Service) isConnectionError(err error) bool {
 	if err == nil {
@@ -407,6 +721,18 @@ func (s *Service) GetCachedHealth(databaseName, tableName string) (*database.Hea
 	return s.scheduler.GetCachedResult(databaseName, tableName)
 }
 
+// RefreshHealth runs the scheduled check of a table immediately and waits for
+// the cached result to be updated
+func (s *Service) RefreshHealth(ctx context.Context, databaseName, tableName string) error {
+	return s.scheduler.Refresh(ctx, databaseName, tableName)
+}
+
+// RefreshDatabaseHealth runs the scheduled checks of all tables in a database
+// immediately and waits for the cached results to be updated
+func (s *Service) RefreshDatabaseHealth(ctx context.Context, databaseName string) error {
+	return s.scheduler.RefreshDatabase(ctx, databaseName)
+}
+
 // GetCachedDatabaseHealth returns cached health check results for all tables in a database
 func (s *Service) GetCachedDatabaseHealth(databaseName string) ([]*database.HealthResult, error) {
 	return s.scheduler.GetCachedDatabaseResults(databaseName)
@@ -423,6 +749,6 @@ func (s *Service) IsHealthResultFresh(databaseName, tableName string) bool {
 }
 
 // GetCacheStats returns statistics about cached health check results
-func (s *Service) GetCacheStats() map[string]interface{} {
+func (s *Service) GetCacheStats() CacheStats {
 	return s.scheduler.GetCacheStats()
 }
\ No newline at end of file
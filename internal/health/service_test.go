@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"gsqlhealth/internal/config"
 )
@@ -200,16 +201,16 @@ func TestCreateService(t *testing.T) {
 				Password: "pass",
 				Database: "db",
 				Tables: []config.Table{
-					{Name: "table1", Query: "SELECT 1", Timeout: 5, CheckInterval: 30},
+					{Name: "table1", Query: "SELECT 1", Timeout: config.Duration(5 * time.Second), CheckInterval: config.Duration(30 * time.Second)},
 				},
 			},
 		},
 		Server: config.Server{
 			Host:         "localhost",
 			Port:         8080,
-			ReadTimeout:  30,
-			WriteTimeout: 30,
-			IdleTimeout:  120,
+			ReadTimeout:  config.Duration(30 * time.Second),
+			WriteTimeout: config.Duration(30 * time.Second),
+			IdleTimeout:  config.Duration(120 * time.Second),
 		},
 	}
 
@@ -239,4 +240,50 @@ func TestCreateService(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for nonexistent database")
 	}
-}
\ No newline at end of file
+}
+
+func TestDisabledChecks(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Databases: []config.Database{
+			{
+				Name: "active",
+				Type: "mysql",
+				Tables: []config.Table{
+					{Name: "table1", Query: "SELECT 1", Timeout: config.Duration(5 * time.Second), CheckInterval: config.Duration(30 * time.Second)},
+					{Name: "table2", Query: "SELECT 1", Timeout: config.Duration(5 * time.Second), CheckInterval: config.Duration(30 * time.Second), Enabled: &disabled},
+				},
+			},
+			{
+				Name:    "paused",
+				Type:    "mysql",
+				Enabled: &disabled,
+				Tables: []config.Table{
+					{Name: "table1", Query: "SELECT 1", Timeout: config.Duration(5 * time.Second), CheckInterval: config.Duration(30 * time.Second)},
+				},
+			},
+		},
+	}
+
+	service := NewService(cfg, nil)
+
+	if !service.IsDatabaseEnabled("active") || service.IsDatabaseEnabled("paused") {
+		t.Error("Unexpected database enabled state")
+	}
+	if !service.IsTableEnabled("active", "table1") || service.IsTableEnabled("active", "table2") {
+		t.Error("Unexpected table enabled state")
+	}
+	if service.IsTableEnabled("paused", "table1") {
+		t.Error("Expected table of disabled database to be disabled")
+	}
+
+	for _, check := range [][2]string{{"active", "table2"}, {"paused", "table1"}} {
+		result, err := service.CheckHealth(context.Background(), check[0], check[1])
+		if err != nil {
+			t.Fatalf("CheckHealth(%s/%s) failed: %v", check[0], check[1], err)
+		}
+		if result.Status != "disabled" {
+			t.Errorf("CheckHealth(%s/%s) status = %q; expected disabled", check[0], check[1], result.Status)
+		}
+	}
+}
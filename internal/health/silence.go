@@ -0,0 +1,171 @@
+package health
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidSilence is returned for silences missing their matcher,
+	// duration, comment or creator
+	ErrInvalidSilence = errors.New("invalid silence")
+	// ErrSilenceNotFound is returned for unknown or expired silences
+	ErrSilenceNotFound = errors.New("silence not found")
+)
+
+// Silence suppresses the notifications of the checks its matcher selects
+// until it ends. Checks keep running and reporting their status.
+type Silence struct {
+	ID        string         `json:"id"`
+	Matcher   SilenceMatcher `json:"matcher"`
+	StartsAt  time.Time      `json:"starts_at"`
+	EndsAt    time.Time      `json:"ends_at"`
+	Comment   string         `json:"comment"`
+	CreatedBy string         `json:"created_by"`
+}
+
+// SilenceMatcher selects checks by database, table and labels. Empty fields
+// select every check, but a matcher must have at least one.
+type SilenceMatcher struct {
+	Database string            `json:"database,omitempty"`
+	Table    string            `json:"table,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"` // labels the checks must all have
+}
+
+// matches reports whether the matcher selects a check
+func (m SilenceMatcher) matches(databaseName, tableName string, labels map[string]string) bool {
+	if m.Database != "" && m.Database != databaseName {
+		return false
+	}
+	if m.Table != "" && m.Table != tableName {
+		return false
+	}
+	for name, value := range m.Labels {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// silences are the silences added through the admin API, kept until they end
+type silences struct {
+	mu   sync.RWMutex
+	byID map[string]Silence
+}
+
+// newSilences creates an empty set of silences
+func newSilences() *silences {
+	return &silences{byID: make(map[string]Silence)}
+}
+
+// newSilenceID generates a random silence ID
+func newSilenceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// active returns the silences active at a time, oldest first, dropping
+// the ones that ended
+func (s *silences) active(at time.Time) []Silence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active := []Silence{}
+	for id, silence := range s.byID {
+		if !at.Before(silence.EndsAt) {
+			delete(s.byID, id)
+			continue
+		}
+		active = append(active, silence)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].StartsAt.Equal(active[j].StartsAt) {
+			return active[i].ID < active[j].ID
+		}
+		return active[i].StartsAt.Before(active[j].StartsAt)
+	})
+	return active
+}
+
+// AddSilence silences the notifications of the checks a matcher selects for
+// a duration, starting now. The creator identifies who added it, and the
+// comment why, such as the maintenance it covers.
+func (s *Service) AddSilence(matcher SilenceMatcher, duration time.Duration, comment, createdBy string) (Silence, error) {
+	switch {
+	case matcher.Database == "" && matcher.Table == "" && len(matcher.Labels) == 0:
+		return Silence{}, fmt.Errorf("%w: matcher must select a database, table or labels", ErrInvalidSilence)
+	case duration <= 0:
+		return Silence{}, fmt.Errorf("%w: duration must be positive", ErrInvalidSilence)
+	case comment == "":
+		return Silence{}, fmt.Errorf("%w: comment is required", ErrInvalidSilence)
+	case createdBy == "":
+		return Silence{}, fmt.Errorf("%w: created_by is required", ErrInvalidSilence)
+	}
+
+	now := time.Now()
+	silence := Silence{
+		Matcher:   matcher,
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		Comment:   comment,
+		CreatedBy: createdBy,
+	}
+
+	s.silences.mu.Lock()
+	silence.ID = newSilenceID()
+	s.silences.byID[silence.ID] = silence
+	s.silences.mu.Unlock()
+
+	s.logger.Info("Notifications silenced",
+		"silence", silence.ID,
+		"database", matcher.Database,
+		"table", matcher.Table,
+		"labels", matcher.Labels,
+		"until", silence.EndsAt,
+		"created_by", createdBy)
+	return silence, nil
+}
+
+// GetSilences returns the active silences, oldest first
+func (s *Service) GetSilences() []Silence {
+	return s.silences.active(time.Now())
+}
+
+// ExpireSilence ends a silence before its end time
+func (s *Service) ExpireSilence(id string) error {
+	s.silences.mu.Lock()
+	silence, exists := s.silences.byID[id]
+	delete(s.silences.byID, id)
+	s.silences.mu.Unlock()
+
+	if !exists || !time.Now().Before(silence.EndsAt) {
+		return ErrSilenceNotFound
+	}
+
+	s.logger.Info("Silence expired", "silence", id)
+	return nil
+}
+
+// IsSilenced reports whether the notifications of a check are silenced at a
+// time. labels are the check's effective labels. Silences start when they are
+// added, so they also silence results of checks that ran just before.
+func (s *Service) IsSilenced(databaseName, tableName string, labels map[string]string, at time.Time) bool {
+	s.silences.mu.RLock()
+	defer s.silences.mu.RUnlock()
+
+	for _, silence := range s.silences.byID {
+		if at.Before(silence.EndsAt) && silence.Matcher.matches(databaseName, tableName, labels) {
+			return true
+		}
+	}
+	return false
+}
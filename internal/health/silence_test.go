@@ -0,0 +1,79 @@
+package health
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestSilences(t *testing.T) {
+	service := NewService(&config.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	invalid := []struct {
+		name      string
+		matcher   SilenceMatcher
+		duration  time.Duration
+		comment   string
+		createdBy string
+	}{
+		{"empty matcher", SilenceMatcher{}, time.Hour, "maintenance", "user:dba"},
+		{"no duration", SilenceMatcher{Database: "app"}, 0, "maintenance", "user:dba"},
+		{"no comment", SilenceMatcher{Database: "app"}, time.Hour, "", "user:dba"},
+		{"no creator", SilenceMatcher{Database: "app"}, time.Hour, "maintenance", ""},
+	}
+	for _, tt := range invalid {
+		if _, err := service.AddSilence(tt.matcher, tt.duration, tt.comment, tt.createdBy); !errors.Is(err, ErrInvalidSilence) {
+			t.Errorf("%s: AddSilence() error = %v; expected ErrInvalidSilence", tt.name, err)
+		}
+	}
+
+	database, err := service.AddSilence(SilenceMatcher{Database: "app"}, time.Hour, "upgrade", "user:dba")
+	if err != nil {
+		t.Fatalf("AddSilence() error = %v", err)
+	}
+	if _, err := service.AddSilence(SilenceMatcher{Labels: map[string]string{"team": "payments"}}, time.Hour, "migration", "api_key:deploy"); err != nil {
+		t.Fatalf("AddSilence() error = %v", err)
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		database string
+		table    string
+		labels   map[string]string
+		at       time.Time
+		silenced bool
+	}{
+		{"database", "app", "orders", nil, now, true},
+		{"other database", "billing", "invoices", nil, now, false},
+		{"labels", "billing", "invoices", map[string]string{"team": "payments", "env": "prod"}, now, true},
+		{"other labels", "billing", "invoices", map[string]string{"team": "search"}, now, false},
+		{"after the end", "app", "orders", nil, now.Add(2 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		if silenced := service.IsSilenced(tt.database, tt.table, tt.labels, tt.at); silenced != tt.silenced {
+			t.Errorf("%s: IsSilenced() = %v; expected %v", tt.name, silenced, tt.silenced)
+		}
+	}
+
+	if silences := service.GetSilences(); len(silences) != 2 {
+		t.Errorf("GetSilences() = %+v; expected both silences", silences)
+	}
+
+	if err := service.ExpireSilence(database.ID); err != nil {
+		t.Fatalf("ExpireSilence() error = %v", err)
+	}
+	if service.IsSilenced("app", "orders", nil, time.Now()) {
+		t.Error("IsSilenced() = true; expected the expired silence to end")
+	}
+	if err := service.ExpireSilence(database.ID); !errors.Is(err, ErrSilenceNotFound) {
+		t.Errorf("ExpireSilence() error = %v; expected ErrSilenceNotFound", err)
+	}
+	if silences := service.GetSilences(); len(silences) != 1 {
+		t.Errorf("GetSilences() = %+v; expected the remaining silence", silences)
+	}
+}
@@ -0,0 +1,251 @@
+package health
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// SLAReport summarizes the availability of one or more checks over a window,
+// computed from the recorded history. History is bounded, so the observed
+// period may be shorter than the requested window.
+type SLAReport struct {
+	Availability  *float64      `json:"availability,omitempty"` // percent of observed time healthy; omitted without observations
+	Incidents     int           `json:"incidents"`              // transitions into a failing status
+	StatusChanges int           `json:"status_changes"`         // transitions between any two statuses
+	MTTR          time.Duration `json:"mttr"`                   // mean time to recovery of resolved incidents
+	LatencyP50    time.Duration `json:"latency_p50"`
+	LatencyP95    time.Duration `json:"latency_p95"`
+	LatencyP99    time.Duration `json:"latency_p99"`
+	LatencySum    time.Duration `json:"latency_sum"`   // total query time of the samples with one
+	LatencyCount  int           `json:"latency_count"` // samples with a query time
+	Samples       int           `json:"samples"`
+	ObservedSince *time.Time    `json:"observed_since,omitempty"` // oldest result in the window
+	Observed      time.Duration `json:"observed"`                 // time covered by healthy and failing results
+}
+
+// slaAccumulator collects the history of one or more checks for an SLAReport
+type slaAccumulator struct {
+	up, down      time.Duration
+	incidents     int
+	statusChanges int
+	resolved      int
+	repairTime    time.Duration
+	latencies     []time.Duration
+	samples       int
+	observedSince time.Time
+}
+
+// add accounts for the entries of one check, oldest first. Each result holds
+// until the next one, the last until end. Maintenance and disabled results
+// count neither for nor against availability.
+func (a *slaAccumulator) add(entries []HistoryEntry, end time.Time) {
+	failing := false
+	var incidentStart time.Time
+
+	for i, entry := range entries {
+		next := end
+		if i+1 < len(entries) {
+			next = entries[i+1].Timestamp
+		}
+		held := next.Sub(entry.Timestamp)
+		if held < 0 {
+			held = 0
+		}
+
+		switch entry.Status {
+		case database.StatusHealthy:
+			a.up += held
+			if failing {
+				a.resolved++
+				a.repairTime += entry.Timestamp.Sub(incidentStart)
+				failing = false
+			}
+		case database.StatusMaintenance, database.StatusDisabled, database.StatusPaused:
+		default:
+			a.down += held
+			if !failing {
+				a.incidents++
+				incidentStart = entry.Timestamp
+				failing = true
+			}
+		}
+
+		if i > 0 && entry.Status != entries[i-1].Status {
+			a.statusChanges++
+		}
+		if entry.QueryTime > 0 {
+			a.latencies = append(a.latencies, entry.QueryTime)
+		}
+		a.samples++
+		if a.observedSince.IsZero() || entry.Timestamp.Before(a.observedSince) {
+			a.observedSince = entry.Timestamp
+		}
+	}
+}
+
+// merge adds the totals of another accumulator
+func (a *slaAccumulator) merge(other *slaAccumulator) {
+	a.up += other.up
+	a.down += other.down
+	a.incidents += other.incidents
+	a.statusChanges += other.statusChanges
+	a.resolved += other.resolved
+	a.repairTime += other.repairTime
+	a.latencies = append(a.latencies, other.latencies...)
+	a.samples += other.samples
+	if !other.observedSince.IsZero() && (a.observedSince.IsZero() || other.observedSince.Before(a.observedSince)) {
+		a.observedSince = other.observedSince
+	}
+}
+
+// report computes the SLA report of the accumulated history
+func (a *slaAccumulator) report() SLAReport {
+	report := SLAReport{
+		Incidents:     a.incidents,
+		StatusChanges: a.statusChanges,
+		LatencyCount:  len(a.latencies),
+		Samples:       a.samples,
+		Observed:      a.up + a.down,
+	}
+
+	if report.Observed > 0 {
+		availability := float64(a.up) / float64(report.Observed) * 100
+		report.Availability = &availability
+	}
+	if a.resolved > 0 {
+		report.MTTR = a.repairTime / time.Duration(a.resolved)
+	}
+	if !a.observedSince.IsZero() {
+		observedSince := a.observedSince
+		report.ObservedSince = &observedSince
+	}
+
+	sort.Slice(a.latencies, func(i, j int) bool { return a.latencies[i] < a.latencies[j] })
+	report.LatencyP50 = percentile(a.latencies, 50)
+	report.LatencyP95 = percentile(a.latencies, 95)
+	report.LatencyP99 = percentile(a.latencies, 99)
+	for _, latency := range a.latencies {
+		report.LatencySum += latency
+	}
+
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// accumulateSLA collects the history of a check from from until to
+func (s *Service) accumulateSLA(databaseName, tableName string, from, to time.Time) (*slaAccumulator, error) {
+	accumulator := &slaAccumulator{}
+
+	var entries []HistoryEntry
+	if s.historyStore != nil {
+		var err error
+		if entries, err = s.historyStore.Newest(databaseName, tableName, from, -1); err != nil {
+			return nil, err
+		}
+	} else {
+		s.historyMu.RLock()
+		if history, exists := s.history[databaseName+"/"+tableName]; exists {
+			entries = history.newest(from, len(history.entries))
+		}
+		s.historyMu.RUnlock()
+	}
+
+	// History is returned newest first, including results at or after to
+	for len(entries) > 0 && !entries[0].Timestamp.Before(to) {
+		entries = entries[1:]
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	accumulator.add(entries, to)
+
+	return accumulator, nil
+}
+
+// GetTableSLA computes the SLA report of a check between from and to
+func (s *Service) GetTableSLA(databaseName, tableName string, from, to time.Time) (SLAReport, error) {
+	if !s.hasTable(databaseName, tableName) {
+		return SLAReport{}, NewNotFoundError(databaseName, tableName, "table not found in database configuration")
+	}
+
+	accumulator, err := s.accumulateSLA(databaseName, tableName, from, to)
+	if err != nil {
+		return SLAReport{}, err
+	}
+	return accumulator.report(), nil
+}
+
+// GetDatabaseSLA computes the SLA report of all checks of a database between
+// from and to, along with the report of each table
+func (s *Service) GetDatabaseSLA(databaseName string, from, to time.Time) (SLAReport, map[string]SLAReport, error) {
+	tableNames, err := s.GetTableNames(databaseName)
+	if err != nil {
+		return SLAReport{}, nil, NewNotFoundError(databaseName, "", "database not found in configuration")
+	}
+
+	total := &slaAccumulator{}
+	tables := make(map[string]SLAReport, len(tableNames))
+	for _, tableName := range tableNames {
+		accumulator, err := s.accumulateSLA(databaseName, tableName, from, to)
+		if err != nil {
+			return SLAReport{}, nil, err
+		}
+		tables[tableName] = accumulator.report()
+		total.merge(accumulator)
+	}
+
+	return total.report(), tables, nil
+}
+
+// CheckSLA is the SLA report of a check
+type CheckSLA struct {
+	Database string
+	Table    string
+	Labels   map[string]string
+	Report   SLAReport
+}
+
+// GetCheckSLAs computes the SLA report of every configured check over the
+// window ending now, ordered by database and table
+func (s *Service) GetCheckSLAs(window time.Duration) ([]CheckSLA, error) {
+	to := time.Now()
+	from := to.Add(-window)
+
+	var checks []CheckSLA
+	for _, db := range s.GetDatabases() {
+		for _, table := range db.Tables {
+			accumulator, err := s.accumulateSLA(db.Name, table.Name, from, to)
+			if err != nil {
+				return nil, err
+			}
+			checks = append(checks, CheckSLA{
+				Database: db.Name,
+				Table:    table.Name,
+				Labels:   s.GetTableLabels(db.Name, table.Name),
+				Report:   accumulator.report(),
+			})
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].Database != checks[j].Database {
+			return checks[i].Database < checks[j].Database
+		}
+		return checks[i].Table < checks[j].Table
+	})
+	return checks, nil
+}
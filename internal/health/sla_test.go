@@ -0,0 +1,121 @@
+package health
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestSLAAccumulator(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	// healthy 0-30, unhealthy 30-40, healthy 40-50, maintenance 50-60, error 60-70, healthy 70-110
+	entries := []HistoryEntry{
+		{Status: database.StatusHealthy, QueryTime: 10 * time.Millisecond, Timestamp: at(0)},
+		{Status: database.StatusUnhealthy, QueryTime: 40 * time.Millisecond, Timestamp: at(30)},
+		{Status: database.StatusHealthy, QueryTime: 20 * time.Millisecond, Timestamp: at(40)},
+		{Status: database.StatusMaintenance, QueryTime: 30 * time.Millisecond, Timestamp: at(50)},
+		{Status: database.StatusError, Timestamp: at(60)},
+		{Status: database.StatusHealthy, QueryTime: 50 * time.Millisecond, Timestamp: at(70)},
+	}
+
+	accumulator := &slaAccumulator{}
+	accumulator.add(entries, at(110))
+	report := accumulator.report()
+
+	if report.Availability == nil || *report.Availability != 80 {
+		t.Errorf("Availability = %v; expected 80", formatAvailability(report.Availability))
+	}
+	if report.Incidents != 2 {
+		t.Errorf("Incidents = %d; expected 2", report.Incidents)
+	}
+	if report.StatusChanges != 5 {
+		t.Errorf("StatusChanges = %d; expected 5", report.StatusChanges)
+	}
+	if report.MTTR != 10*time.Minute {
+		t.Errorf("MTTR = %v; expected 10m", report.MTTR)
+	}
+	if report.Observed != 100*time.Minute {
+		t.Errorf("Observed = %v; expected 1h40m", report.Observed)
+	}
+	if report.LatencyP50 != 30*time.Millisecond || report.LatencyP99 != 50*time.Millisecond {
+		t.Errorf("Latency p50/p99 = %v/%v; expected 30ms/50ms", report.LatencyP50, report.LatencyP99)
+	}
+	if report.LatencySum != 150*time.Millisecond || report.LatencyCount != 5 {
+		t.Errorf("Latency sum/count = %v/%d; expected 150ms/5", report.LatencySum, report.LatencyCount)
+	}
+	if report.ObservedSince == nil || !report.ObservedSince.Equal(at(0)) {
+		t.Errorf("ObservedSince = %v; expected %v", report.ObservedSince, at(0))
+	}
+}
+
+func TestSLAWindow(t *testing.T) {
+	service := NewService(&config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{{Name: "table1"}}}},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	start := time.Now().Add(-time.Hour)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	statuses := []database.Status{database.StatusHealthy, database.StatusUnhealthy, database.StatusHealthy, database.StatusUnhealthy}
+	for i, status := range statuses {
+		service.recordHistory("test", "table1", &database.HealthResult{Status: status, QueryTime: time.Millisecond, Timestamp: at(i * 10)}, nil)
+	}
+
+	// Results at 10 and 20 minutes, the last holding until 30
+	report, err := service.GetTableSLA("test", "table1", at(5), at(30))
+	if err != nil {
+		t.Fatalf("GetTableSLA() error = %v", err)
+	}
+	if report.Samples != 2 || report.StatusChanges != 1 || report.Incidents != 1 {
+		t.Errorf("report = %+v; expected 2 samples, 1 status change and 1 incident", report)
+	}
+	if report.Availability == nil || *report.Availability != 50 {
+		t.Errorf("Availability = %v; expected 50", formatAvailability(report.Availability))
+	}
+
+	checks, err := service.GetCheckSLAs(2 * time.Hour)
+	if err != nil {
+		t.Fatalf("GetCheckSLAs() error = %v", err)
+	}
+	if len(checks) != 1 || checks[0].Table != "table1" || checks[0].Report.Samples != 4 || checks[0].Report.StatusChanges != 3 {
+		t.Errorf("GetCheckSLAs() = %+v; expected table1 with 4 samples and 3 status changes", checks)
+	}
+}
+
+func TestSLAWithoutHistory(t *testing.T) {
+	service := NewService(&config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{{Name: "table1"}, {Name: "table2"}}}},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	now := time.Now()
+	report, tables, err := service.GetDatabaseSLA("test", now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatalf("GetDatabaseSLA() error = %v", err)
+	}
+	if report.Availability != nil || report.Samples != 0 {
+		t.Errorf("Expected an empty report, got %+v", report)
+	}
+	if len(tables) != 2 {
+		t.Errorf("len(tables) = %d; expected 2", len(tables))
+	}
+
+	if _, err := service.GetTableSLA("test", "missing", now.Add(-time.Hour), now); err == nil {
+		t.Errorf("Expected an error for an unknown table")
+	}
+	if _, _, err := service.GetDatabaseSLA("missing", now.Add(-time.Hour), now); err == nil {
+		t.Errorf("Expected an error for an unknown database")
+	}
+}
+
+func formatAvailability(availability *float64) string {
+	if availability == nil {
+		return "<nil>"
+	}
+	return fmt.Sprint(*availability)
+}
@@ -0,0 +1,232 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// Snapshot is the state of the service: the cached results of scheduled
+// checks and, when taken through Service.Snapshot, the connection states and
+// scheduler metadata. The cache_snapshot file keeps the results alone.
+type Snapshot struct {
+	SavedAt     time.Time         `json:"saved_at"`
+	Results     []SnapshotResult  `json:"results"`
+	Connections []ConnectionState `json:"connections,omitempty"`
+	Scheduler   *SchedulerState   `json:"scheduler,omitempty"`
+	Runs        []CheckRunStats   `json:"runs,omitempty"`
+}
+
+// SnapshotResult is a cached result in a snapshot. Errors keep their type and
+// message, which is all the API reports of them.
+type SnapshotResult struct {
+	Database  string                 `json:"database"`
+	Table     string                 `json:"table"`
+	Result    *database.HealthResult `json:"result,omitempty"`
+	ErrorType ErrorType              `json:"error_type,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// ConnectionState describes the connection of the service to a database
+type ConnectionState struct {
+	Database       string     `json:"database"`
+	Connected      bool       `json:"connected"`                 // a connection was established
+	DisconnectedAt *time.Time `json:"disconnected_at,omitempty"` // the connection was lost and is not back yet
+	ConnectionDown bool       `json:"connection_down,omitempty"` // the database's connection check fails
+}
+
+// SnapshotRestore counts the results a restored snapshot provided
+type SnapshotRestore struct {
+	Restored int `json:"restored"` // results taken from the snapshot
+	Skipped  int `json:"skipped"`  // results of unscheduled checks, or older than the cached ones
+}
+
+// snapshotResults returns the cached results of scheduled checks; callers
+// must hold s.mu
+func (s *Scheduler) snapshotResults() []SnapshotResult {
+	results := []SnapshotResult{}
+	for key, check := range s.checks {
+		cachedResult, exists := s.results[key]
+		if !exists {
+			continue
+		}
+
+		cachedResult.mu.RLock()
+		entry := SnapshotResult{
+			Database:  check.DatabaseName,
+			Table:     check.TableName,
+			Result:    cachedResult.Result,
+			UpdatedAt: cachedResult.UpdatedAt,
+		}
+		if cachedResult.Error != nil {
+			entry.ErrorType, entry.Error = ErrorTypeInternal, cachedResult.Error.Error()
+			var healthErr *HealthError
+			if errors.As(cachedResult.Error, &healthErr) {
+				entry.ErrorType, entry.Error = healthErr.Type, healthErr.Message
+			}
+		}
+		cachedResult.mu.RUnlock()
+
+		if entry.Result != nil || entry.Error != "" {
+			results = append(results, entry)
+		}
+	}
+	return results
+}
+
+// restoreResults restores the results of a snapshot as stale results of the
+// scheduled checks whose cached results are older or missing, returning the
+// number of results restored; callers must hold s.mu
+func (s *Scheduler) restoreResults(entries []SnapshotResult) int {
+	restored := 0
+	for _, entry := range entries {
+		key := s.getCheckKey(entry.Database, entry.Table)
+		cachedResult, exists := s.results[key]
+		if _, scheduled := s.checks[key]; !exists || !scheduled || (entry.Result == nil && entry.Error == "") {
+			continue
+		}
+
+		cachedResult.mu.Lock()
+		if (cachedResult.Result == nil && cachedResult.Error == nil) || cachedResult.UpdatedAt.Before(entry.UpdatedAt) {
+			cachedResult.Result, cachedResult.Error = nil, nil
+			if entry.Result != nil {
+				result := *entry.Result
+				result.Stale = true
+				cachedResult.Result = &result
+			}
+			if entry.Error != "" {
+				cachedResult.Error = &HealthError{Type: entry.ErrorType, Database: entry.Database, Table: entry.Table, Message: entry.Error}
+			}
+			cachedResult.UpdatedAt = entry.UpdatedAt
+			restored++
+		}
+		cachedResult.mu.Unlock()
+	}
+	return restored
+}
+
+// saveSnapshot writes the cached results of scheduled checks to the
+// cache_snapshot file, replacing it atomically; callers must hold s.mu
+func (s *Scheduler) saveSnapshot() error {
+	path := s.service.config.CacheSnapshot
+	if path == "" {
+		return nil
+	}
+
+	snapshot := Snapshot{SavedAt: time.Now(), Results: s.snapshotResults()}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadSnapshot restores the cached results of the cache_snapshot file as
+// stale results of the checks that have none yet, so results are available
+// before the first checks complete. It returns the number of results
+// restored; callers must hold s.mu.
+func (s *Scheduler) loadSnapshot() (int, error) {
+	path := s.service.config.CacheSnapshot
+	if path == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, err
+	}
+	return s.restoreResults(snapshot.Results), nil
+}
+
+// Snapshot returns the current state of the service, for RestoreSnapshot on
+// another instance
+func (s *Service) Snapshot() Snapshot {
+	s.scheduler.mu.RLock()
+	results := s.scheduler.snapshotResults()
+	runs := s.scheduler.checkRunStats()
+	s.scheduler.mu.RUnlock()
+
+	state := s.scheduler.state()
+	snapshot := Snapshot{
+		SavedAt:     time.Now(),
+		Results:     results,
+		Connections: []ConnectionState{},
+		Scheduler:   &state,
+		Runs:        runs,
+	}
+
+	for _, databaseName := range s.GetDatabaseNames() {
+		connection := ConnectionState{Database: databaseName}
+		s.mu.RLock()
+		_, connection.Connected = s.drivers[databaseName]
+		s.mu.RUnlock()
+		if at, down := s.disconnectedSince(databaseName); down {
+			connection.DisconnectedAt = &at
+		}
+		connection.ConnectionDown = s.scheduler.connectionDown(databaseName)
+		snapshot.Connections = append(snapshot.Connections, connection)
+	}
+	return snapshot
+}
+
+// RestoreSnapshot loads a snapshot of another instance, so a replacement
+// instance serves results right away. Results are restored as stale results
+// of the configured checks whose cached results are older or missing, and the
+// pauses of the snapshot are added to the current ones. Connection states and
+// run statistics belong to the instance that took the snapshot and are not
+// restored.
+func (s *Service) RestoreSnapshot(snapshot Snapshot) SnapshotRestore {
+	s.scheduler.mu.Lock()
+	restored := s.scheduler.restoreResults(snapshot.Results)
+	s.scheduler.mu.Unlock()
+
+	// Pauses of databases and checks that are not configured here are dropped
+	if state := snapshot.Scheduler; state != nil {
+		if state.Paused {
+			s.scheduler.pause("")
+		}
+		for _, databaseName := range state.PausedDatabases {
+			if s.hasDatabase(databaseName) {
+				s.scheduler.pause(databaseName)
+			}
+		}
+		for _, key := range state.PausedChecks {
+			databaseName, tableName := s.scheduler.parseCheckKey(key)
+			if s.hasTable(databaseName, tableName) {
+				s.scheduler.pauseCheck(databaseName, tableName)
+			}
+		}
+	}
+
+	s.logger.Info("Restored state snapshot",
+		"saved_at", snapshot.SavedAt,
+		"results", restored)
+	return SnapshotRestore{Restored: restored, Skipped: len(snapshot.Results) - restored}
+}
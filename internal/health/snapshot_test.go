@@ -0,0 +1,127 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestCacheSnapshot(t *testing.T) {
+	cfg := &config.Config{
+		CacheSnapshot: filepath.Join(t.TempDir(), "cache.json"),
+		Databases: []config.Database{
+			{Name: "test", Tables: []config.Table{
+				{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			}},
+			{Name: "down", Tables: []config.Table{
+				{Name: "orders", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			}},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewService(cfg, logger)
+	service.drivers["test"] = &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	if err := service.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, check := range [][2]string{{"test", "users"}, {"down", "orders"}} {
+		if err := service.RefreshHealth(ctx, check[0], check[1]); err != nil {
+			t.Fatalf("RefreshHealth(%s/%s) error = %v", check[0], check[1], err)
+		}
+	}
+	_, _, checkedAt := service.GetCachedHealth("test", "users")
+	service.scheduler.Stop()
+
+	// After the restart, the results are served before any check runs
+	restarted := NewService(cfg, logger)
+	restarted.scheduler.pause("")
+	if err := restarted.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer restarted.scheduler.Stop()
+
+	result, err, updatedAt := restarted.GetCachedHealth("test", "users")
+	if err != nil || result.Status != database.StatusHealthy || !result.Stale {
+		t.Errorf("GetCachedHealth(users) = %+v, %v; expected a stale healthy result", result, err)
+	}
+	if !updatedAt.Equal(checkedAt) {
+		t.Errorf("updatedAt = %v; expected the time of the check before the restart %v", updatedAt, checkedAt)
+	}
+	if restarted.IsHealthResultFresh("test", "users") {
+		t.Errorf("Expected the restored result not to be fresh")
+	}
+
+	var healthErr *HealthError
+	if _, err, _ := restarted.GetCachedHealth("down", "orders"); !errors.As(err, &healthErr) || !healthErr.IsConnectionError() {
+		t.Errorf("GetCachedHealth(orders) error = %v; expected the connection error from before the restart", err)
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "test", Tables: []config.Table{
+			{Name: "users", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+			{Name: "orders", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second), CheckInterval: config.Duration(time.Hour)},
+		}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	old := NewService(cfg, logger)
+	old.drivers["test"] = &sequenceDriver{data: map[string]map[string]interface{}{"SELECT 1 AS one": {"one": int64(1)}}}
+	if err := old.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer old.scheduler.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := old.RefreshHealth(ctx, "test", "users"); err != nil {
+		t.Fatalf("RefreshHealth() error = %v", err)
+	}
+	if err := old.PauseCheck("test", "orders"); err != nil {
+		t.Fatalf("PauseCheck() error = %v", err)
+	}
+
+	snapshot := old.Snapshot()
+	if len(snapshot.Results) != 2 || len(snapshot.Runs) != 2 {
+		t.Errorf("Snapshot() = %+v; expected the results and runs of both checks", snapshot)
+	}
+	if len(snapshot.Connections) != 1 || !snapshot.Connections[0].Connected {
+		t.Errorf("Connections = %+v; expected the connected database", snapshot.Connections)
+	}
+	if snapshot.Scheduler == nil || len(snapshot.Scheduler.PausedChecks) != 1 {
+		t.Errorf("Scheduler = %+v; expected the paused check", snapshot.Scheduler)
+	}
+
+	replacement := NewService(cfg, logger)
+	replacement.scheduler.pause("test")
+	if err := replacement.scheduler.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer replacement.scheduler.Stop()
+
+	if restore := replacement.RestoreSnapshot(snapshot); restore.Restored != 2 || restore.Skipped != 0 {
+		t.Errorf("RestoreSnapshot() = %+v; expected both results restored", restore)
+	}
+	result, err, _ := replacement.GetCachedHealth("test", "users")
+	if err != nil || result.Status != database.StatusHealthy || !result.Stale {
+		t.Errorf("GetCachedHealth(users) = %+v, %v; expected a stale healthy result", result, err)
+	}
+	if state := replacement.GetSchedulerState(); len(state.PausedChecks) != 1 || state.PausedChecks[0] != "test/orders" {
+		t.Errorf("PausedChecks = %v; expected the check paused in the snapshot", state.PausedChecks)
+	}
+
+	// Results that are not older than the snapshot's are kept
+	if restore := replacement.RestoreSnapshot(snapshot); restore.Restored != 0 || restore.Skipped != 2 {
+		t.Errorf("RestoreSnapshot() again = %+v; expected both results skipped", restore)
+	}
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// executeQueries runs the queries of a table's check. A multi-step check runs
+// its steps on one connection until the first failing step, and returns the
+// data of the steps that ran under "steps".
+func executeQueries(ctx context.Context, driver database.Driver, table *config.Table, queries []string) (map[string]interface{}, error) {
+	// Connection checks ping the database before their query
+	if table.Check == config.CheckConnection {
+		if err := driver.Ping(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(table.Steps) == 0 {
+		return driver.ExecuteHealthCheck(ctx, queries[0])
+	}
+
+	executor, ok := driver.(database.SequenceExecutor)
+	if !ok {
+		return nil, fmt.Errorf("driver %s does not support multi-step checks", driver.GetDriverName())
+	}
+
+	var steps []interface{}
+	err := executor.ExecuteSequence(ctx, queries, table.Transaction, func(data map[string]interface{}) bool {
+		i := len(steps)
+		steps = append(steps, data)
+		return checkExpectations(table.Steps[i].AsTable(), data) == ""
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"steps": steps}, nil
+}
+
+// checkSteps checks the data of each step of a multi-step check against the
+// step's assertions, returning the first failure or "" if every step passes
+func checkSteps(table *config.Table, data map[string]interface{}) string {
+	steps, _ := data["steps"].([]interface{})
+	for i, step := range steps {
+		stepData, _ := step.(map[string]interface{})
+		if failed := checkExpectations(table.Steps[i].AsTable(), stepData); failed != "" {
+			return fmt.Sprintf("%s: %s", table.Steps[i].GetName(i), failed)
+		}
+	}
+
+	if len(steps) < len(table.Steps) {
+		return fmt.Sprintf("expected %d steps, %d ran", len(table.Steps), len(steps))
+	}
+	return ""
+}
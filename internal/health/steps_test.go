@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// sequenceDriver returns canned data for each query of a sequence
+type sequenceDriver struct {
+	data     map[string]map[string]interface{} // key: query
+	ran      []string
+	pingErr  error
+	queryErr error
+}
+
+func (d *sequenceDriver) Connect(ctx context.Context, info database.ConnectionInfo) error { return nil }
+func (d *sequenceDriver) Close() error                                                    { return nil }
+func (d *sequenceDriver) Ping(ctx context.Context) error                                  { return d.pingErr }
+func (d *sequenceDriver) GetDriverName() string                                           { return "sequence" }
+func (d *sequenceDriver) ExecuteHealthCheck(ctx context.Context, query string) (map[string]interface{}, error) {
+	return d.data[query], d.queryErr
+}
+
+func (d *sequenceDriver) ExecuteSequence(ctx context.Context, queries []string, transaction bool, next func(map[string]interface{}) bool) error {
+	for _, query := range queries {
+		d.ran = append(d.ran, query)
+		if !next(d.data[query]) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestExecuteSteps(t *testing.T) {
+	table := &config.Table{Name: "probe", Steps: []config.Step{
+		{Name: "set role", Query: "SET ROLE app_reader"},
+		{Name: "read", Query: "SELECT COUNT(*) AS count FROM orders", Expect: config.Expectations{{Column: "count", Operator: ">", Value: 0}}},
+		{Name: "verify", Query: "SELECT app_version() AS version", Expect: config.Expectations{{Column: "version", Value: "2.1"}}},
+	}}
+
+	driver := &sequenceDriver{data: map[string]map[string]interface{}{
+		"SET ROLE app_reader":                  {"row_count": 0},
+		"SELECT COUNT(*) AS count FROM orders": {"count": int64(12)},
+		"SELECT app_version() AS version":      {"version": "2.1"},
+	}}
+	queries := []string{table.Steps[0].Query, table.Steps[1].Query, table.Steps[2].Query}
+
+	data, err := executeQueries(context.Background(), driver, table, queries)
+	if err != nil {
+		t.Fatalf("executeQueries() error = %v", err)
+	}
+	if failed := checkExpectations(table, data); failed != "" {
+		t.Errorf("checkExpectations() = %q; expected every step to pass", failed)
+	}
+
+	// The sequence stops at the first failing step
+	driver.data["SELECT COUNT(*) AS count FROM orders"] = map[string]interface{}{"count": int64(0)}
+	driver.ran = nil
+	data, err = executeQueries(context.Background(), driver, table, queries)
+	if err != nil {
+		t.Fatalf("executeQueries() error = %v", err)
+	}
+	if !reflect.DeepEqual(driver.ran, queries[:2]) {
+		t.Errorf("ran %v; expected the first two steps", driver.ran)
+	}
+	if failed := checkExpectations(table, data); !strings.HasPrefix(failed, "step 2 (read): expected count > 0") {
+		t.Errorf("checkExpectations() = %q; expected step 2 to fail", failed)
+	}
+
+	if _, err := executeQueries(context.Background(), panicDriver{}, table, queries); err == nil {
+		t.Error("executeQueries() succeeded with a driver without sequences")
+	}
+}
+
+func TestExecuteConnectionCheck(t *testing.T) {
+	table := &config.Table{Name: "connection", Check: config.CheckConnection}
+	query := checkQuery(&config.Database{Type: "postgres"}, table)
+	driver := &sequenceDriver{data: map[string]map[string]interface{}{query: {"connected": int64(1)}}}
+
+	data, err := executeQueries(context.Background(), driver, table, []string{query})
+	if err != nil || data["connected"] != int64(1) {
+		t.Fatalf("executeQueries() = %v, %v; expected the connected row", data, err)
+	}
+
+	driver.pingErr = errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")
+	if _, err := executeQueries(context.Background(), driver, table, []string{query}); err != driver.pingErr {
+		t.Errorf("executeQueries() error = %v; expected the ping error", err)
+	}
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// checkOutcome is the result of a check run in its own goroutine
+type checkOutcome struct {
+	result *database.HealthResult
+	err    error
+}
+
+// runWatched runs a scheduled check, abandoning it once it has run for the
+// stuck factor times its deadline. Drivers that ignore the cancellation would
+// otherwise hold the check, and the worker running it, forever. An abandoned
+// check is reported stuck without being started again until it returns.
+func (s *Scheduler) runWatched(ctx context.Context, databaseName, tableName string, timeout time.Duration) (*database.HealthResult, error) {
+	key := s.getCheckKey(databaseName, tableName)
+	if since, stuck := s.stuckSince(key); stuck {
+		return s.service.stuckResult(databaseName, tableName, since, timeout)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// finished and abandoned are guarded by stuckMu, so a check returning as
+	// it is abandoned is either reported or cleared, never both or neither
+	var finished, abandoned bool
+	started := time.Now()
+	done := make(chan checkOutcome, 1)
+	go func() {
+		result, err := s.service.safeCheckHealth(ctx, databaseName, tableName)
+		done <- checkOutcome{result, err}
+
+		s.stuckMu.Lock()
+		defer s.stuckMu.Unlock()
+		finished = true
+		if abandoned {
+			delete(s.stuck, key)
+			abandonedChecks.Add(-1)
+		}
+	}()
+
+	timer := time.NewTimer(timeout * time.Duration(s.service.config.Scheduler.GetStuckFactor()))
+	defer timer.Stop()
+
+	select {
+	case outcome := <-done:
+		return outcome.result, outcome.err
+	case <-timer.C:
+	}
+
+	s.stuckMu.Lock()
+	if !finished {
+		abandoned = true
+		s.stuck[key] = started
+		abandonedChecks.Add(1)
+	}
+	s.stuckMu.Unlock()
+
+	if !abandoned {
+		outcome := <-done
+		return outcome.result, outcome.err
+	}
+
+	stuckChecks.Add(1)
+	s.logger.Error("Abandoned stuck health check",
+		"database", databaseName,
+		"table", tableName,
+		"running_for", time.Since(started),
+		"timeout", timeout)
+	return s.service.stuckResult(databaseName, tableName, started, timeout)
+}
+
+// stuckSince returns when an abandoned check that has not returned yet started
+func (s *Scheduler) stuckSince(key string) (time.Time, bool) {
+	s.stuckMu.Lock()
+	defer s.stuckMu.Unlock()
+
+	since, stuck := s.stuck[key]
+	return since, stuck
+}
+
+// stuckResult creates the result reported for a check that is stuck since started
+func (s *Service) stuckResult(databaseName, tableName string, started time.Time, timeout time.Duration) (*database.HealthResult, error) {
+	message := fmt.Sprintf("check still running after %s, past its %s deadline; abandoned", time.Since(started).Round(time.Second), timeout)
+	return &database.HealthResult{
+		DatabaseName: databaseName,
+		TableName:    tableName,
+		Labels:       s.GetTableLabels(databaseName, tableName),
+		Severity:     s.GetTableSeverity(databaseName, tableName),
+		Status:       database.StatusStuck,
+		Error:        message,
+		Timestamp:    time.Now(),
+	}, NewTimeoutError(databaseName, tableName, message, nil)
+}
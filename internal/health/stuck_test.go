@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// hangingDriver ignores cancellation and blocks its queries until released
+type hangingDriver struct {
+	sequenceDriver
+	release chan struct{}
+	queries atomic.Int32
+}
+
+func (d *hangingDriver) ExecuteHealthCheck(ctx context.Context, query string) (map[string]interface{}, error) {
+	d.queries.Add(1)
+	<-d.release
+	return map[string]interface{}{"one": int64(1)}, nil
+}
+
+func TestStuckCheck(t *testing.T) {
+	cfg := &config.Config{
+		ScheduledCheckTimeout: config.Duration(20 * time.Millisecond),
+		Databases: []config.Database{{Name: "mssql", Tables: []config.Table{
+			{Name: "orders", Query: "SELECT 1 AS one", Timeout: config.Duration(time.Second)},
+		}}},
+	}
+	service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	driver := &hangingDriver{release: make(chan struct{})}
+	service.drivers["mssql"] = driver
+
+	started := time.Now()
+	result, err, _ := service.scheduler.runLimited("mssql", "orders")
+	if result == nil || result.Status != database.StatusStuck || err == nil {
+		t.Fatalf("runLimited() = %+v, %v; expected a stuck result", result, err)
+	}
+	if elapsed := time.Since(started); elapsed < 40*time.Millisecond {
+		t.Errorf("Check abandoned after %v; expected twice the 20ms deadline", elapsed)
+	}
+
+	// While the abandoned query hangs, the check is not started again
+	if result, _, _ := service.scheduler.runLimited("mssql", "orders"); result.Status != database.StatusStuck {
+		t.Errorf("runLimited() while stuck = %s; expected stuck", result.Status)
+	}
+	if queries := driver.queries.Load(); queries != 1 {
+		t.Errorf("queries = %d; expected the stuck check not to run again", queries)
+	}
+
+	close(driver.release)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		if _, stuck := service.scheduler.stuckSince("mssql/orders"); !stuck {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("Expected the check to be cleared once the query returned")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	if result, err, _ := service.scheduler.runLimited("mssql", "orders"); err != nil || result.Status != database.StatusHealthy {
+		t.Errorf("runLimited() after the query returned = %+v, %v; expected healthy", result, err)
+	}
+}
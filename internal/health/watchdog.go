@@ -0,0 +1,174 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// selfDatabaseName identifies the watchdog's own check in health responses
+const selfDatabaseName = "_self"
+
+// Watchdog samples goroutine count, open database connections and cache size
+// and reports the service itself as degraded when a ceiling is exceeded
+type Watchdog struct {
+	service *Service
+	mu      sync.RWMutex
+	result  *database.HealthResult
+}
+
+// newWatchdog creates a watchdog that reports healthy until the first sample
+func newWatchdog(service *Service) *Watchdog {
+	return &Watchdog{
+		service: service,
+		result: &database.HealthResult{
+			DatabaseName: selfDatabaseName,
+			TableName:    "watchdog",
+			Status:       database.StatusHealthy,
+			Timestamp:    time.Now(),
+		},
+	}
+}
+
+// run samples resources at the configured interval until the context is cancelled
+func (w *Watchdog) run(ctx context.Context) {
+	interval := w.service.config.Watchdog.GetInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	w.service.logger.Info("Starting resource watchdog",
+		"interval", interval,
+		"max_goroutines", w.service.config.Watchdog.GetMaxGoroutines(),
+		"max_open_connections", w.maxOpenConnections(),
+		"max_cache_size", w.maxCacheSize())
+
+	for {
+		select {
+		case <-ticker.C:
+			w.sample()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sample collects current resource usage and updates the _self result
+func (w *Watchdog) sample() {
+	goroutines := runtime.NumGoroutine()
+	openConnections := w.service.openConnections()
+	cacheSize := w.service.scheduler.cacheSize()
+
+	var exceeded []string
+	if max := w.service.config.Watchdog.GetMaxGoroutines(); goroutines > max {
+		exceeded = append(exceeded, fmt.Sprintf("goroutines %d > %d", goroutines, max))
+	}
+	if max := w.maxOpenConnections(); openConnections > max {
+		exceeded = append(exceeded, fmt.Sprintf("open connections %d > %d", openConnections, max))
+	}
+	if max := w.maxCacheSize(); cacheSize > max {
+		exceeded = append(exceeded, fmt.Sprintf("cache size %d > %d", cacheSize, max))
+	}
+
+	result := &database.HealthResult{
+		DatabaseName: selfDatabaseName,
+		TableName:    "watchdog",
+		Status:       database.StatusHealthy,
+		Data: map[string]interface{}{
+			"goroutines":       goroutines,
+			"open_connections": openConnections,
+			"cache_size":       cacheSize,
+		},
+		Timestamp: time.Now(),
+	}
+	if len(exceeded) > 0 {
+		result.Status = database.StatusDegraded
+		result.Error = strings.Join(exceeded, "; ")
+	}
+
+	w.mu.Lock()
+	wasDegraded := w.result.Status == database.StatusDegraded
+	w.result = result
+	w.mu.Unlock()
+
+	// Log diagnostics once per transition to avoid flooding the logs
+	if len(exceeded) > 0 && !wasDegraded {
+		var profile bytes.Buffer
+		if goroutineProfile := pprof.Lookup("goroutine"); goroutineProfile != nil {
+			goroutineProfile.WriteTo(&profile, 1)
+		}
+
+		w.service.logger.Warn("Resource watchdog thresholds exceeded",
+			"exceeded", result.Error,
+			"goroutines", goroutines,
+			"open_connections", openConnections,
+			"cache_size", cacheSize,
+			"goroutine_profile", profile.String())
+	} else if len(exceeded) == 0 && wasDegraded {
+		w.service.logger.Info("Resource watchdog back within thresholds",
+			"goroutines", goroutines,
+			"open_connections", openConnections,
+			"cache_size", cacheSize)
+	}
+}
+
+// maxOpenConnections returns the configured connection ceiling or the pool
+// size of every enabled database
+func (w *Watchdog) maxOpenConnections() int {
+	if max := w.service.config.Watchdog.MaxOpenConnections; max > 0 {
+		return max
+	}
+
+	enabled := 0
+	for _, db := range w.service.GetDatabases() {
+		if db.IsEnabled() {
+			enabled++
+		}
+	}
+	return enabled * database.MaxOpenConns
+}
+
+// maxCacheSize returns the configured cache ceiling or the number of configured checks
+func (w *Watchdog) maxCacheSize() int {
+	if max := w.service.config.Watchdog.MaxCacheSize; max > 0 {
+		return max
+	}
+
+	checks := 0
+	for _, db := range w.service.GetDatabases() {
+		checks += len(db.Tables)
+	}
+	return checks
+}
+
+// GetSelfHealth returns the latest watchdog result, or nil when the watchdog is disabled
+func (s *Service) GetSelfHealth() *database.HealthResult {
+	if s.watchdog == nil {
+		return nil
+	}
+
+	s.watchdog.mu.RLock()
+	defer s.watchdog.mu.RUnlock()
+
+	return s.watchdog.result
+}
+
+// openConnections returns the number of open connections across all drivers
+func (s *Service) openConnections() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, driver := range s.drivers {
+		if provider, ok := driver.(database.StatsProvider); ok {
+			total += provider.Stats().OpenConnections
+		}
+	}
+	return total
+}
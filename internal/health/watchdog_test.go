@@ -0,0 +1,56 @@
+package health
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestWatchdogSample(t *testing.T) {
+	tests := []struct {
+		name     string
+		watchdog config.Watchdog
+		expected database.Status
+	}{
+		{
+			name:     "within thresholds",
+			watchdog: config.Watchdog{Enabled: true, MaxGoroutines: 100000},
+			expected: "healthy",
+		},
+		{
+			name:     "goroutine ceiling exceeded",
+			watchdog: config.Watchdog{Enabled: true, MaxGoroutines: 1},
+			expected: "degraded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Databases: []config.Database{{Name: "test", Tables: []config.Table{{Name: "table1"}}}},
+				Watchdog:  tt.watchdog,
+			}
+			service := NewService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			service.watchdog.sample()
+
+			result := service.GetSelfHealth()
+			if result.Status != tt.expected {
+				t.Errorf("Status = %q; expected %q (error: %s)", result.Status, tt.expected, result.Error)
+			}
+			if result.DatabaseName != selfDatabaseName {
+				t.Errorf("DatabaseName = %q; expected %q", result.DatabaseName, selfDatabaseName)
+			}
+		})
+	}
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	service := NewService(&config.Config{}, nil)
+	if result := service.GetSelfHealth(); result != nil {
+		t.Errorf("Expected no self health when watchdog is disabled, got %+v", result)
+	}
+}
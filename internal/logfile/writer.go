@@ -0,0 +1,264 @@
+// Package logfile writes logs to a file that is rotated by size, keeping a
+// bounded number of backups for a bounded time, optionally compressed.
+package logfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// backupTimeFormat is the timestamp of backup names, name-<time>.ext as
+	// written by lumberjack
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	// compressSuffix is the suffix of compressed backups
+	compressSuffix = ".gz"
+	// megabyte is the unit of MaxSize
+	megabyte = 1024 * 1024
+)
+
+// Options are the rotation options of a log file
+type Options struct {
+	MaxSize    int           // megabytes written before rotating; 0 never rotates
+	MaxBackups int           // backups kept; 0 keeps all
+	MaxAge     time.Duration // age of backups removed; 0 keeps them
+	Compress   bool          // gzip backups
+}
+
+// Writer appends to a log file, renaming it to a timestamped backup and
+// starting a new file when a write would exceed the maximum size. Backups are
+// compressed and pruned in the background after each rotation.
+type Writer struct {
+	path    string
+	options Options
+	now     func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	mill sync.Mutex     // serializes compressing and pruning backups
+	wg   sync.WaitGroup // background compressing and pruning
+}
+
+// Open opens a log file for appending, creating it and its directory
+func Open(path string, options Options) (*Writer, error) {
+	w := &Writer{path: path, options: options, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes a log record, rotating the file first if it would exceed the
+// maximum size. A record larger than the maximum size is written whole to a
+// new file.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if limit := int64(w.options.MaxSize) * megabyte; limit > 0 && w.size > 0 && w.size+int64(len(p)) > limit {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate renames the log file to a backup and starts a new one
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the log file, waiting for backups being compressed or pruned
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	return err
+}
+
+// open opens the log file for appending
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate renames the log file to a backup, opens a new one and starts
+// pruning backups. The file is closed before it is renamed, which Windows
+// requires.
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	if err := os.Rename(w.path, w.backupName(w.now())); err != nil && !os.IsNotExist(err) {
+		// Keep writing to the current file rather than losing records
+		if openErr := w.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("failed to rename log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.millBackups()
+	}()
+	return nil
+}
+
+// backupName returns the name of the backup of the log file rotated at t
+func (w *Writer) backupName(t time.Time) string {
+	dir, prefix, ext := w.nameParts()
+	return filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+}
+
+// nameParts returns the directory of the log file, and the prefix and
+// extension of the names of its backups
+func (w *Writer) nameParts() (string, string, string) {
+	dir, base := filepath.Split(w.path)
+	ext := filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// backup is a backup of the log file
+type backup struct {
+	path       string
+	rotatedAt  time.Time
+	compressed bool
+}
+
+// backups returns the backups of the log file, newest first
+func (w *Writer) backups() ([]backup, error) {
+	dir, prefix, ext := w.nameParts()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		compressed := strings.HasSuffix(name, ext+compressSuffix)
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix), ext)
+		if !compressed && !strings.HasSuffix(name, ext) {
+			continue
+		}
+		rotatedAt, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), rotatedAt: rotatedAt, compressed: compressed})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotatedAt.After(backups[j].rotatedAt) })
+	return backups, nil
+}
+
+// millBackups removes the backups beyond the maximum number or age, and
+// compresses the others if configured. Failures leave the backups for the
+// next rotation.
+func (w *Writer) millBackups() {
+	w.mill.Lock()
+	defer w.mill.Unlock()
+
+	backups, err := w.backups()
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Time{}
+	if w.options.MaxAge > 0 {
+		cutoff = w.now().Add(-w.options.MaxAge)
+	}
+	for i, b := range backups {
+		if (w.options.MaxBackups > 0 && i >= w.options.MaxBackups) || (!cutoff.IsZero() && b.rotatedAt.Before(cutoff)) {
+			os.Remove(b.path)
+			continue
+		}
+		if w.options.Compress && !b.compressed {
+			compressFile(b.path)
+		}
+	}
+}
+
+// compressFile gzips a file, removing the original once compressed
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + compressSuffix)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + compressSuffix)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + compressSuffix)
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,136 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestWriter opens a log file in a temporary directory with a clock
+// advancing a second per rotation
+func newTestWriter(t *testing.T, options Options) (*Writer, string) {
+	t.Helper()
+	dir := t.TempDir()
+	w, err := Open(filepath.Join(dir, "gsqlhealth.log"), options)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	w.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return w, dir
+}
+
+// files returns the names of the files in a directory
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestWriterRotatesBySize(t *testing.T) {
+	w, dir := newTestWriter(t, Options{MaxSize: 1})
+	record := []byte(strings.Repeat("x", 400*1024) + "\n")
+
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write(record); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	expected := []string{"gsqlhealth-2024-01-15T10-30-01.000.log", "gsqlhealth.log"}
+	if names := files(t, dir); strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("files = %v; expected %v", names, expected)
+	}
+	backup, _ := os.ReadFile(filepath.Join(dir, expected[0]))
+	current, _ := os.ReadFile(filepath.Join(dir, expected[1]))
+	if len(backup) != 2*len(record) || len(current) != len(record) {
+		t.Errorf("backup has %d bytes and current %d; expected whole records split at 1 MB", len(backup), len(current))
+	}
+}
+
+func TestWriterAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "gsqlhealth.log")
+	for _, line := range []string{"first\n", "second\n"} {
+		w, err := Open(path, Options{})
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		w.Write([]byte(line))
+		w.Close()
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("log file = %q; expected both lines", data)
+	}
+}
+
+func TestWriterPrunesBackups(t *testing.T) {
+	w, dir := newTestWriter(t, Options{MaxBackups: 2, Compress: true})
+	for i := 0; i < 4; i++ {
+		w.Write([]byte("record\n"))
+		if err := w.Rotate(); err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+	}
+	w.Close()
+
+	expected := []string{
+		"gsqlhealth-2024-01-15T10-30-03.000.log.gz",
+		"gsqlhealth-2024-01-15T10-30-04.000.log.gz",
+		"gsqlhealth.log",
+	}
+	if names := files(t, dir); strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("files = %v; expected %v", names, expected)
+	}
+
+	file, _ := os.Open(filepath.Join(dir, expected[0]))
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to open compressed backup: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	if string(data) != "record\n" {
+		t.Errorf("backup = %q; expected the record", data)
+	}
+}
+
+func TestWriterRemovesOldBackups(t *testing.T) {
+	w, dir := newTestWriter(t, Options{MaxAge: 24 * time.Hour})
+	old := filepath.Join(dir, "gsqlhealth-2024-01-10T00-00-00.000.log")
+	unrelated := filepath.Join(dir, "gsqlhealth-notes.log")
+	for _, path := range []string{old, unrelated} {
+		if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	w.Write([]byte("record\n"))
+	w.Rotate()
+	w.Close()
+
+	expected := []string{"gsqlhealth-2024-01-15T10-30-01.000.log", "gsqlhealth-notes.log", "gsqlhealth.log"}
+	if names := files(t, dir); strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("files = %v; expected %v", names, expected)
+	}
+}
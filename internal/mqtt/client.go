@@ -0,0 +1,291 @@
+// Package mqtt publishes check results and status changes to an MQTT broker.
+// It implements the publishing side of MQTT 3.1.1: connecting, publishing
+// with QoS 0, 1 or 2, and keep alive pings.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Control packet types
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetPubAck     = 4
+	packetPubRec     = 5
+	packetPubRel     = 6
+	packetPubComp    = 7
+	packetPingReq    = 12
+	packetPingResp   = 13
+	packetDisconnect = 14
+)
+
+// maxRemainingLength is the largest packet body MQTT can encode
+const maxRemainingLength = 268435455
+
+// connectReturnCodes describes the CONNACK return codes refusing a connection
+var connectReturnCodes = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Options are the connection options of a client
+type Options struct {
+	Address   string      // host:port of the broker
+	TLS       *tls.Config // nil for plain TCP
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration // zero disables keep alive
+	Timeout   time.Duration // deadline of connecting, and of each acknowledged publish
+}
+
+// Client is a connection to a broker. It is not safe for concurrent use.
+type Client struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	timeout  time.Duration
+	packetID uint16
+}
+
+// packet is a received control packet
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// Dial connects to a broker with a clean session
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+	var conn net.Conn
+	var err error
+	if opts.TLS != nil {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: opts.TLS}).DialContext(ctx, "tcp", opts.Address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", opts.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{conn: conn, reader: bufio.NewReader(conn), timeout: opts.Timeout}
+	if err := c.connect(opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect sends CONNECT and waits for the broker to accept it
+func (c *Client) connect(opts Options) error {
+	flags := byte(0x02) // clean session
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 is MQTT 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.KeepAlive/time.Second))
+	body = appendString(body, opts.ClientID)
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+	}
+	if opts.Password != "" {
+		body = appendString(body, opts.Password)
+	}
+
+	c.setDeadline()
+	if err := c.write(packetConnect<<4, body); err != nil {
+		return err
+	}
+	ack, err := c.expect(packetConnAck)
+	if err != nil {
+		return err
+	}
+	if len(ack.body) != 2 {
+		return fmt.Errorf("malformed CONNACK")
+	}
+	if code := ack.body[1]; code != 0 {
+		if reason, ok := connectReturnCodes[code]; ok {
+			return fmt.Errorf("connection refused: %s", reason)
+		}
+		return fmt.Errorf("connection refused: return code %d", code)
+	}
+	return nil
+}
+
+// Publish publishes a message, waiting for the broker to acknowledge it for
+// QoS 1 and 2
+func (c *Client) Publish(topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 2 {
+		return fmt.Errorf("invalid QoS %d", qos)
+	}
+
+	header := byte(packetPublish<<4) | qos<<1
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	var id uint16
+	if qos > 0 {
+		id = c.nextPacketID()
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, payload...)
+
+	c.setDeadline()
+	if err := c.write(header, body); err != nil {
+		return err
+	}
+
+	switch qos {
+	case 1:
+		return c.expectAck(packetPubAck, id)
+	case 2:
+		if err := c.expectAck(packetPubRec, id); err != nil {
+			return err
+		}
+		if err := c.write(packetPubRel<<4|0x02, binary.BigEndian.AppendUint16(nil, id)); err != nil {
+			return err
+		}
+		return c.expectAck(packetPubComp, id)
+	}
+	return nil
+}
+
+// Ping sends a keep alive ping and waits for the response
+func (c *Client) Ping() error {
+	c.setDeadline()
+	if err := c.write(packetPingReq<<4, nil); err != nil {
+		return err
+	}
+	_, err := c.expect(packetPingResp)
+	return err
+}
+
+// Close disconnects from the broker
+func (c *Client) Close() error {
+	c.setDeadline()
+	c.write(packetDisconnect<<4, nil)
+	return c.conn.Close()
+}
+
+// nextPacketID returns the identifier of the next acknowledged publish,
+// which must not be zero
+func (c *Client) nextPacketID() uint16 {
+	c.packetID++
+	if c.packetID == 0 {
+		c.packetID = 1
+	}
+	return c.packetID
+}
+
+// setDeadline bounds the next exchange with the broker
+func (c *Client) setDeadline() {
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+}
+
+// write sends a control packet
+func (c *Client) write(header byte, body []byte) error {
+	if len(body) > maxRemainingLength {
+		return fmt.Errorf("packet of %d bytes is too large", len(body))
+	}
+
+	buf := make([]byte, 0, len(body)+5)
+	buf = append(buf, header)
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		buf = append(buf, digit)
+		if length == 0 {
+			break
+		}
+	}
+	buf = append(buf, body...)
+
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+// read receives a control packet
+func (c *Client) read() (packet, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, errors.New("malformed remaining length")
+		}
+		digit, err := c.reader.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: header >> 4, flags: header & 0x0f, body: body}, nil
+}
+
+// expect receives a control packet of a type. Brokers only send the packets
+// answering ours, as the client subscribes to nothing.
+func (c *Client) expect(kind byte) (packet, error) {
+	received, err := c.read()
+	if err != nil {
+		return packet{}, err
+	}
+	if received.kind != kind {
+		return packet{}, fmt.Errorf("unexpected packet type %d, expected %d", received.kind, kind)
+	}
+	return received, nil
+}
+
+// expectAck receives the acknowledgement of a type of a publish
+func (c *Client) expectAck(kind byte, id uint16) error {
+	ack, err := c.expect(kind)
+	if err != nil {
+		return err
+	}
+	if len(ack.body) < 2 || binary.BigEndian.Uint16(ack.body) != id {
+		return fmt.Errorf("acknowledgement of another packet than %d", id)
+	}
+	return nil
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
@@ -0,0 +1,215 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// message is a PUBLISH received by the test broker
+type message struct {
+	topic   string
+	payload string
+	qos     byte
+	retain  bool
+}
+
+// testBroker is a broker accepting connections, acknowledging publishes and
+// answering pings, recording what it receives
+type testBroker struct {
+	listener   net.Listener
+	returnCode byte // of CONNACK
+
+	mu       sync.Mutex
+	connects []string // client ID, user name and password of each CONNECT
+	messages []message
+	pings    int
+	conns    []net.Conn
+}
+
+// newTestBroker starts a test broker, closed at the end of the test
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	b := &testBroker{listener: listener}
+	t.Cleanup(b.close)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conns = append(b.conns, conn)
+			b.mu.Unlock()
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// close stops the broker and drops its connections
+func (b *testBroker) close() {
+	b.listener.Close()
+	b.dropConnections()
+}
+
+// dropConnections closes the connections of clients, as a broker restart
+func (b *testBroker) dropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		conn.Close()
+	}
+	b.conns = nil
+}
+
+// serve answers the packets of a client
+func (b *testBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	c := &Client{conn: conn, reader: bufio.NewReader(conn)}
+	for {
+		p, err := c.read()
+		if err != nil {
+			return
+		}
+
+		switch p.kind {
+		case packetConnect:
+			b.mu.Lock()
+			b.connects = append(b.connects, parseConnect(p.body))
+			b.mu.Unlock()
+			c.write(packetConnAck<<4, []byte{0, b.returnCode})
+		case packetPublish:
+			topicLength := int(binary.BigEndian.Uint16(p.body))
+			m := message{topic: string(p.body[2 : 2+topicLength]), qos: p.flags >> 1 & 0x03, retain: p.flags&0x01 != 0}
+			rest := p.body[2+topicLength:]
+			var id []byte
+			if m.qos > 0 {
+				id, rest = rest[:2], rest[2:]
+			}
+			m.payload = string(rest)
+			b.mu.Lock()
+			b.messages = append(b.messages, m)
+			b.mu.Unlock()
+			switch m.qos {
+			case 1:
+				c.write(packetPubAck<<4, id)
+			case 2:
+				c.write(packetPubRec<<4, id)
+			}
+		case packetPubRel:
+			c.write(packetPubComp<<4, p.body)
+		case packetPingReq:
+			b.mu.Lock()
+			b.pings++
+			b.mu.Unlock()
+			c.write(packetPingResp<<4, nil)
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+// received returns the messages received so far
+func (b *testBroker) received() []message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]message(nil), b.messages...)
+}
+
+// connections returns the client ID, user name and password of each CONNECT
+// received so far
+func (b *testBroker) connections() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.connects...)
+}
+
+// parseConnect returns the client ID, user name and password of a CONNECT
+func parseConnect(body []byte) string {
+	flags := body[7]
+	fields := []string{}
+	rest := body[10:]
+	for len(rest) >= 2 {
+		length := int(binary.BigEndian.Uint16(rest))
+		fields = append(fields, string(rest[2:2+length]))
+		rest = rest[2+length:]
+	}
+	if flags&0x80 == 0 {
+		fields = append(fields, "")
+	}
+	return strings.Join(fields, ":")
+}
+
+func TestClientPublish(t *testing.T) {
+	broker := newTestBroker(t)
+
+	client, err := Dial(context.Background(), Options{
+		Address:   broker.listener.Addr().String(),
+		ClientID:  "gsqlhealth-test",
+		Username:  "monitor",
+		Password:  "secret",
+		KeepAlive: 30 * time.Second,
+		Timeout:   5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	for qos := byte(0); qos <= 2; qos++ {
+		if err := client.Publish("gsqlhealth/app/orders/status", []byte(`{"status":"healthy"}`), qos, qos == 1); err != nil {
+			t.Fatalf("Publish(qos %d) error = %v", qos, err)
+		}
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if err := client.Publish("topic", nil, 3, false); err == nil {
+		t.Error("expected an error for QoS 3")
+	}
+
+	if connects := broker.connections(); connects[0] != "gsqlhealth-test:monitor:secret" {
+		t.Errorf("CONNECT = %q; expected the client ID and credentials", connects[0])
+	}
+	messages := broker.received()
+	if len(messages) != 3 {
+		t.Fatalf("received %d messages; expected 3", len(messages))
+	}
+	for qos, m := range messages {
+		if m.topic != "gsqlhealth/app/orders/status" || m.payload != `{"status":"healthy"}` || m.qos != byte(qos) || m.retain != (qos == 1) {
+			t.Errorf("message = %+v; expected QoS %d", m, qos)
+		}
+	}
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if broker.pings != 1 {
+		t.Errorf("pings = %d; expected 1", broker.pings)
+	}
+}
+
+func TestClientConnectionRefused(t *testing.T) {
+	broker := newTestBroker(t)
+	broker.returnCode = 4
+
+	_, err := Dial(context.Background(), Options{
+		Address:  broker.listener.Addr().String(),
+		ClientID: "gsqlhealth-test",
+		Username: "monitor",
+		Password: "wrong",
+		Timeout:  5 * time.Second,
+	})
+	if err == nil || !strings.Contains(err.Error(), "bad user name or password") {
+		t.Errorf("Dial() error = %v; expected the refusal", err)
+	}
+}
@@ -0,0 +1,242 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/health"
+)
+
+const (
+	// eventBuffer is the number of health events buffered while a message is
+	// being published
+	eventBuffer = 256
+	// publishTimeout is the deadline of connecting to the broker, and of
+	// publishing a message
+	publishTimeout = 10 * time.Second
+	// minReconnectDelay and maxReconnectDelay bound the backoff between
+	// attempts to connect to the broker
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// topicReplacer replaces the characters of names that topics cannot contain,
+// or that would add topic levels
+var topicReplacer = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// Publisher publishes the events of the health service to an MQTT broker:
+// the result of every check run to the status topic, status changes to the
+// event topic, and database connections to the connection topic. Messages are
+// the JSON of the events. While the broker is unreachable, messages are
+// dropped and the connection is retried with backoff.
+type Publisher struct {
+	cfg           config.MQTT
+	healthService *health.Service
+	logger        *slog.Logger
+	options       Options
+
+	// client is the connection to the broker, only accessed by the goroutine
+	// publishing messages
+	client         *Client
+	reconnectDelay time.Duration
+	nextAttempt    time.Time
+
+	unsubscribe func()
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewPublisher creates a publisher to the configured broker
+func NewPublisher(cfg *config.Config, healthService *health.Service, logger *slog.Logger) (*Publisher, error) {
+	broker, err := url.Parse(cfg.MQTT.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker: %w", err)
+	}
+
+	options := Options{
+		Address:   broker.Host,
+		ClientID:  cfg.MQTT.GetClientID(),
+		Username:  cfg.MQTT.Username,
+		Password:  cfg.MQTT.Password,
+		KeepAlive: cfg.MQTT.GetKeepAlive(),
+		Timeout:   publishTimeout,
+	}
+	port := "1883"
+	if cfg.MQTT.UsesTLS() {
+		port = "8883"
+		options.TLS = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: broker.Hostname()}
+		if cfg.MQTT.CAFile != "" {
+			pem, err := os.ReadFile(cfg.MQTT.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA file %s", cfg.MQTT.CAFile)
+			}
+			options.TLS.RootCAs = pool
+		}
+	}
+	if broker.Port() == "" {
+		options.Address = net.JoinHostPort(broker.Hostname(), port)
+	}
+
+	return &Publisher{
+		cfg:            cfg.MQTT,
+		healthService:  healthService,
+		logger:         logger,
+		options:        options,
+		reconnectDelay: minReconnectDelay,
+	}, nil
+}
+
+// Start publishes the events of the health service until Stop is called
+func (p *Publisher) Start() {
+	events, unsubscribe := p.healthService.Subscribe(eventBuffer)
+	p.unsubscribe = unsubscribe
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+
+	// The ticker keeps the connection alive, and reconnects after failures
+	ticker := time.NewTicker(p.options.KeepAlive)
+
+	go func() {
+		defer close(p.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case event := <-events:
+				p.publishEvent(event)
+			case <-ticker.C:
+				p.keepAlive()
+			case <-p.stop:
+				if p.client != nil {
+					p.client.Close()
+				}
+				return
+			}
+		}
+	}()
+
+	p.logger.Info("MQTT publisher enabled",
+		"broker", p.options.Address,
+		"client_id", p.options.ClientID)
+}
+
+// Stop ends the publisher, waiting for the message being published and
+// disconnecting from the broker
+func (p *Publisher) Stop() {
+	if p.done == nil {
+		return
+	}
+	p.unsubscribe()
+	close(p.stop)
+	<-p.done
+}
+
+// publishEvent publishes an event to the topic of its type
+func (p *Publisher) publishEvent(event health.Event) {
+	topic, retain, ok := p.topicFor(event)
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		p.logger.Error("Failed to encode MQTT message", "error", err)
+		return
+	}
+	if !p.connect() {
+		p.logger.Debug("Dropped MQTT message while disconnected", "topic", topic)
+		return
+	}
+	if err := p.client.Publish(topic, payload, byte(p.cfg.QoS), retain); err != nil {
+		p.logger.Warn("Failed to publish MQTT message", "topic", topic, "error", err)
+		p.disconnect()
+	}
+}
+
+// topicFor returns the topic of an event, and whether it is retained. Check
+// results and connection states are the latest state of a check or database,
+// retained if configured; status changes are a stream, never retained.
+func (p *Publisher) topicFor(event health.Event) (string, bool, bool) {
+	switch event.Type {
+	case health.EventCheckCompleted:
+		return expandTopic(p.cfg.GetStatusTopic(), event.Database, event.Table), p.cfg.Retain, true
+	case health.EventStatusChanged:
+		if event.Table == "" {
+			return "", false, false
+		}
+		return expandTopic(p.cfg.GetEventTopic(), event.Database, event.Table), false, true
+	case health.EventConnected, health.EventDisconnected:
+		return expandTopic(p.cfg.GetConnectionTopic(), event.Database, ""), p.cfg.Retain, true
+	default:
+		return "", false, false
+	}
+}
+
+// keepAlive pings the broker, or reconnects to it
+func (p *Publisher) keepAlive() {
+	if p.client == nil {
+		p.connect()
+		return
+	}
+	if err := p.client.Ping(); err != nil {
+		p.logger.Warn("MQTT broker did not answer ping", "error", err)
+		p.disconnect()
+	}
+}
+
+// connect connects to the broker unless connected, or waiting to retry a
+// failed attempt, and reports whether the publisher is connected
+func (p *Publisher) connect() bool {
+	if p.client != nil {
+		return true
+	}
+	if time.Now().Before(p.nextAttempt) {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	client, err := Dial(ctx, p.options)
+	if err != nil {
+		p.logger.Warn("Failed to connect to MQTT broker",
+			"broker", p.options.Address,
+			"retry_in", p.reconnectDelay,
+			"error", err)
+		p.nextAttempt = time.Now().Add(p.reconnectDelay)
+		p.reconnectDelay = min(p.reconnectDelay*2, maxReconnectDelay)
+		return false
+	}
+
+	p.logger.Info("Connected to MQTT broker", "broker", p.options.Address)
+	p.client = client
+	p.reconnectDelay = minReconnectDelay
+	return true
+}
+
+// disconnect drops a failed connection, to be reconnected by the next message
+// or keep alive tick
+func (p *Publisher) disconnect() {
+	p.client.Close()
+	p.client = nil
+}
+
+// expandTopic replaces the {database} and {table} placeholders of a topic
+func expandTopic(template, databaseName, tableName string) string {
+	return strings.NewReplacer(
+		"{database}", topicReplacer.Replace(databaseName),
+		"{table}", topicReplacer.Replace(tableName),
+	).Replace(template)
+}
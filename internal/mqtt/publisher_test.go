@@ -0,0 +1,116 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+// newTestPublisher returns a publisher to a test broker
+func newTestPublisher(t *testing.T, broker *testBroker, mqttConfig config.MQTT) *Publisher {
+	t.Helper()
+	mqttConfig.Enabled = true
+	mqttConfig.Broker = "tcp://" + broker.listener.Addr().String()
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "app", Tables: []config.Table{{Name: "orders"}}}},
+		MQTT:      mqttConfig,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	publisher, err := NewPublisher(cfg, health.NewService(cfg, logger), logger)
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+	t.Cleanup(func() {
+		if publisher.client != nil {
+			publisher.client.Close()
+		}
+	})
+	return publisher
+}
+
+func TestPublishEvent(t *testing.T) {
+	broker := newTestBroker(t)
+	publisher := newTestPublisher(t, broker, config.MQTT{QoS: 1, Retain: true})
+	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	publisher.publishEvent(health.Event{Type: health.EventCheckCompleted, Database: "app", Table: "orders", Status: database.StatusHealthy, Timestamp: timestamp})
+	publisher.publishEvent(health.Event{Type: health.EventStatusChanged, Database: "app", Table: "orders", Status: database.StatusUnhealthy, PreviousStatus: database.StatusHealthy, Timestamp: timestamp})
+	publisher.publishEvent(health.Event{Type: health.EventDisconnected, Database: "app", Timestamp: timestamp})
+	publisher.publishEvent(health.Event{Type: health.EventDataChanged, Database: "app", Table: "orders", Timestamp: timestamp})
+
+	messages := broker.received()
+	expected := []message{
+		{topic: "gsqlhealth/app/orders/status", qos: 1, retain: true},
+		{topic: "gsqlhealth/app/orders/events", qos: 1, retain: false},
+		{topic: "gsqlhealth/app/connection", qos: 1, retain: true},
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("received %d messages; expected %d", len(messages), len(expected))
+	}
+	for i, m := range messages {
+		if m.topic != expected[i].topic || m.qos != expected[i].qos || m.retain != expected[i].retain {
+			t.Errorf("message %d = %+v; expected %+v", i, m, expected[i])
+		}
+	}
+
+	var event health.Event
+	if err := json.Unmarshal([]byte(messages[1].payload), &event); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if event.Status != database.StatusUnhealthy || event.PreviousStatus != database.StatusHealthy || !event.Timestamp.Equal(timestamp) {
+		t.Errorf("event = %+v; expected the status change", event)
+	}
+}
+
+func TestPublishReconnects(t *testing.T) {
+	broker := newTestBroker(t)
+	publisher := newTestPublisher(t, broker, config.MQTT{QoS: 1})
+	event := health.Event{Type: health.EventCheckCompleted, Database: "app", Table: "orders", Status: database.StatusHealthy}
+
+	publisher.publishEvent(event)
+	broker.dropConnections()
+
+	// The publish on the dropped connection fails, and the next one waits for
+	// the reconnect delay
+	publisher.publishEvent(event)
+	if publisher.client != nil {
+		t.Fatal("expected the failed connection to be dropped")
+	}
+	publisher.nextAttempt = time.Now().Add(time.Minute)
+	publisher.publishEvent(event)
+	if len(broker.received()) != 1 {
+		t.Errorf("received %d messages; expected messages to be dropped while disconnected", len(broker.received()))
+	}
+
+	publisher.nextAttempt = time.Time{}
+	publisher.keepAlive()
+	publisher.publishEvent(event)
+	if len(broker.received()) != 2 || len(broker.connections()) != 2 {
+		t.Errorf("received %d messages over %d connections; expected a reconnect", len(broker.received()), len(broker.connections()))
+	}
+}
+
+func TestExpandTopic(t *testing.T) {
+	tests := []struct {
+		template string
+		database string
+		table    string
+		expected string
+	}{
+		{"gsqlhealth/{database}/{table}/status", "app", "orders", "gsqlhealth/app/orders/status"},
+		{"gsqlhealth/{database}/connection", "app", "", "gsqlhealth/app/connection"},
+		{"health/{table}", "app", "public/orders#1", "health/public_orders_1"},
+	}
+
+	for _, tt := range tests {
+		if got := expandTopic(tt.template, tt.database, tt.table); got != tt.expected {
+			t.Errorf("expandTopic(%q, %q, %q) = %q; expected %q", tt.template, tt.database, tt.table, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+// alertmanagerAlertName is the alertname of the alerts of failing checks
+const alertmanagerAlertName = "GSQLHealthCheckFailing"
+
+// alertmanagerValidity is how many resend intervals a posted alert stays
+// firing without being posted again, so a missed resend does not resolve it
+const alertmanagerValidity = 4
+
+// Alertmanager posts an alert to the v2 API of a Prometheus Alertmanager
+// while a check fails, and resolves it when the check recovers. Alertmanager
+// resolves alerts past their endsAt, so firing alerts are posted again every
+// resend interval, as Prometheus does.
+type Alertmanager struct {
+	url            string
+	bearerToken    string
+	username       string
+	password       string
+	resendInterval time.Duration
+	client         *http.Client
+
+	mu     sync.Mutex
+	firing map[string]alertmanagerAlert // key: "database/table"
+}
+
+// alertmanagerAlert is an alert of the Alertmanager v2 API. Its labels
+// identify it; annotations may change between posts.
+type alertmanagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// NewAlertmanager creates an Alertmanager notifier
+func NewAlertmanager(cfg config.Alertmanager, client *http.Client) *Alertmanager {
+	return &Alertmanager{
+		url:            cfg.GetAlertsURL(),
+		bearerToken:    cfg.BearerToken,
+		username:       cfg.Username,
+		password:       cfg.Password,
+		resendInterval: cfg.GetResendInterval(),
+		client:         client,
+		firing:         make(map[string]alertmanagerAlert),
+	}
+}
+
+// Name implements Notifier
+func (a *Alertmanager) Name() string {
+	return "alertmanager"
+}
+
+// Notify implements Notifier. Failing checks fire an alert that starts when
+// the check started failing, and checks that are no longer failing resolve
+// it; a check becoming degraded is left alone, as it is slow but up.
+func (a *Alertmanager) Notify(ctx context.Context, alert Alert) error {
+	key := alert.Database + "/" + alert.Table
+
+	a.mu.Lock()
+	previous, firing := a.firing[key]
+	var posted alertmanagerAlert
+	switch {
+	case isFailing(alert.Status):
+		posted = newAlertmanagerAlert(alert)
+		if firing {
+			posted.StartsAt = previous.StartsAt
+		}
+		posted.EndsAt = time.Now().Add(alertmanagerValidity * a.resendInterval)
+		a.firing[key] = posted
+	case firing:
+		posted = previous
+		posted.EndsAt = alert.Timestamp
+		delete(a.firing, key)
+	default:
+		a.mu.Unlock()
+		return nil
+	}
+	a.mu.Unlock()
+
+	return a.post(ctx, []alertmanagerAlert{posted})
+}
+
+// run posts the firing alerts again every resend interval until stop is
+// closed
+func (a *Alertmanager) run(stop <-chan struct{}, logger *slog.Logger) {
+	ticker := time.NewTicker(a.resendInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		a.mu.Lock()
+		alerts := make([]alertmanagerAlert, 0, len(a.firing))
+		for key, alert := range a.firing {
+			alert.EndsAt = time.Now().Add(alertmanagerValidity * a.resendInterval)
+			a.firing[key] = alert
+			alerts = append(alerts, alert)
+		}
+		a.mu.Unlock()
+		if len(alerts) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		if err := a.post(ctx, alerts); err != nil {
+			logger.Error("Failed to resend firing alerts",
+				"notifier", a.Name(),
+				"alerts", len(alerts),
+				"error", err)
+		}
+		cancel()
+	}
+}
+
+// post posts alerts to the Alertmanager
+func (a *Alertmanager) post(ctx context.Context, alerts []alertmanagerAlert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case a.bearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+a.bearerToken)
+	case a.username != "":
+		req.SetBasicAuth(a.username, a.password)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("alertmanager returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// newAlertmanagerAlert returns the alert of a failing check, starting now.
+// It is labeled with the check's labels, and with its database, table and
+// severity, which take precedence.
+func newAlertmanagerAlert(alert Alert) alertmanagerAlert {
+	labels := make(map[string]string, len(alert.Labels)+4)
+	for name, value := range alert.Labels {
+		labels[name] = value
+	}
+	labels["alertname"] = alertmanagerAlertName
+	labels["database"] = alert.Database
+	labels["table"] = alert.Table
+	labels["severity"] = alert.Severity
+
+	annotations := map[string]string{
+		"summary": fmt.Sprintf("%s/%s is %s", alert.Database, alert.Table, alert.Status),
+		"status":  string(alert.Status),
+	}
+	if alert.Error != "" {
+		annotations["description"] = alert.Error
+	}
+	if alert.QueryTime > 0 {
+		annotations["query_time"] = alert.QueryTime.String()
+	}
+
+	return alertmanagerAlert{
+		Labels:       labels,
+		Annotations:  annotations,
+		StartsAt:     alert.Timestamp,
+		GeneratorURL: alert.DashboardURL,
+	}
+}
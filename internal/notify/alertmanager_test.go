@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// alertmanagerRecorder records the alerts posted to a test Alertmanager
+type alertmanagerRecorder struct {
+	mu    sync.Mutex
+	posts [][]alertmanagerAlert
+}
+
+func (r *alertmanagerRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var alerts []alertmanagerAlert
+	if req.URL.Path != "/api/v2/alerts" || json.NewDecoder(req.Body).Decode(&alerts) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.posts = append(r.posts, alerts)
+	r.mu.Unlock()
+}
+
+func (r *alertmanagerRecorder) last() ([]alertmanagerAlert, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.posts) == 0 {
+		return nil, 0
+	}
+	return r.posts[len(r.posts)-1], len(r.posts)
+}
+
+func TestAlertmanager(t *testing.T) {
+	recorder := &alertmanagerRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	alertmanager := NewAlertmanager(config.Alertmanager{URL: server.URL + "/"}, server.Client())
+	failedAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	alert := Alert{
+		Database:       "app",
+		Table:          "orders",
+		Status:         database.StatusUnhealthy,
+		PreviousStatus: database.StatusHealthy,
+		Severity:       config.SeverityCritical,
+		Labels:         map[string]string{"team": "payments", "table": "ignored"},
+		Error:          "connection refused",
+		Timestamp:      failedAt,
+		DashboardURL:   "https://grafana.example.com/d/db",
+	}
+
+	if err := alertmanager.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	alerts, posts := recorder.last()
+	if posts != 1 || len(alerts) != 1 {
+		t.Fatalf("Expected one alert posted, got %v", recorder.posts)
+	}
+	fired := alerts[0]
+	expectedLabels := map[string]string{"alertname": alertmanagerAlertName, "database": "app", "table": "orders", "severity": "critical", "team": "payments"}
+	for name, value := range expectedLabels {
+		if fired.Labels[name] != value {
+			t.Errorf("label %s = %q; expected %q", name, fired.Labels[name], value)
+		}
+	}
+	if !fired.StartsAt.Equal(failedAt) || !fired.EndsAt.After(time.Now()) || fired.GeneratorURL != alert.DashboardURL || fired.Annotations["description"] != "connection refused" {
+		t.Errorf("alert = %+v; expected a firing alert since the failure", fired)
+	}
+
+	// A different failure keeps the alert and its start
+	alert.PreviousStatus, alert.Status, alert.Timestamp = database.StatusUnhealthy, database.StatusError, failedAt.Add(time.Minute)
+	if err := alertmanager.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if alerts, _ := recorder.last(); !alerts[0].StartsAt.Equal(failedAt) || alerts[0].Annotations["status"] != "error" {
+		t.Errorf("alert = %+v; expected the original start and the new status", alerts[0])
+	}
+
+	recoveredAt := failedAt.Add(5 * time.Minute)
+	alert.PreviousStatus, alert.Status, alert.Timestamp = database.StatusError, database.StatusHealthy, recoveredAt
+	if err := alertmanager.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if alerts, _ := recorder.last(); !alerts[0].EndsAt.Equal(recoveredAt) || !alerts[0].StartsAt.Equal(failedAt) {
+		t.Errorf("alert = %+v; expected the alert resolved at the recovery", alerts[0])
+	}
+
+	// Degraded checks do not fire
+	alert.PreviousStatus, alert.Status = database.StatusHealthy, database.StatusDegraded
+	if err := alertmanager.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if _, posts := recorder.last(); posts != 3 {
+		t.Errorf("Expected no alert posted for a degraded check, got %d posts", posts)
+	}
+}
+
+func TestAlertmanagerResend(t *testing.T) {
+	recorder := &alertmanagerRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	alertmanager := NewAlertmanager(config.Alertmanager{URL: server.URL, ResendInterval: config.Duration(10 * time.Millisecond)}, server.Client())
+	alert := Alert{Database: "app", Table: "orders", Status: database.StatusUnhealthy, Severity: config.SeverityCritical, Timestamp: time.Now()}
+	if err := alertmanager.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		alertmanager.run(stop, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, posts := recorder.last(); posts >= 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the firing alert to be posted again")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(stop)
+	<-done
+
+	if alerts, _ := recorder.last(); len(alerts) != 1 || !alerts[0].StartsAt.Equal(alert.Timestamp) {
+		t.Errorf("resent alerts = %+v; expected the firing alert", alerts)
+	}
+}
@@ -0,0 +1,214 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/awsauth"
+	"gsqlhealth/internal/config"
+)
+
+// snsMaxSubject is the longest subject SNS accepts
+const snsMaxSubject = 100
+
+// SNS publishes alerts as JSON messages to an Amazon SNS topic, with the
+// database, table, status and severity as message attributes for
+// subscription filter policies
+type SNS struct {
+	topicARN string
+	api      awsQueryAPI
+}
+
+// SQS sends alerts as JSON messages to an Amazon SQS queue, with the same
+// message attributes as SNS
+type SQS struct {
+	queueURL string
+	api      awsQueryAPI
+}
+
+// awsQueryAPI calls actions of an AWS query API, such as SNS and SQS, with
+// SigV4 signed form posts
+type awsQueryAPI struct {
+	service     string // signing name of the service
+	endpoint    string
+	region      string
+	credentials *awsauth.Provider
+	client      *http.Client
+}
+
+// awsErrorResponse is the error response of AWS query APIs
+type awsErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+// NewSNS creates an SNS notifier
+func NewSNS(cfg config.SNS, client *http.Client) *SNS {
+	return &SNS{
+		topicARN: cfg.TopicARN,
+		api: awsQueryAPI{
+			service:     "sns",
+			endpoint:    cfg.GetEndpoint(),
+			region:      cfg.GetRegion(),
+			credentials: awsauth.NewProvider(cfg.RoleARN, cfg.GetRegion(), client),
+			client:      client,
+		},
+	}
+}
+
+// Name implements Notifier
+func (s *SNS) Name() string {
+	return "sns"
+}
+
+// Notify implements Notifier
+func (s *SNS) Notify(ctx context.Context, alert Alert) error {
+	message, err := json.Marshal(newAlertMessage(alert))
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"Action":   {"Publish"},
+		"Version":  {"2010-03-31"},
+		"TopicArn": {s.topicARN},
+		"Message":  {string(message)},
+		"Subject":  {snsSubject(alert)},
+	}
+	for i, attribute := range messageAttributes(alert) {
+		prefix := "MessageAttributes.entry." + strconv.Itoa(i+1)
+		form.Set(prefix+".Name", attribute[0])
+		form.Set(prefix+".Value.DataType", "String")
+		form.Set(prefix+".Value.StringValue", attribute[1])
+	}
+	if strings.HasSuffix(s.topicARN, ".fifo") {
+		setFIFOParameters(form, alert)
+	}
+
+	return s.api.call(ctx, form)
+}
+
+// NewSQS creates an SQS notifier
+func NewSQS(cfg config.SQS, client *http.Client) *SQS {
+	return &SQS{
+		queueURL: cfg.QueueURL,
+		api: awsQueryAPI{
+			service:     "sqs",
+			endpoint:    cfg.QueueURL,
+			region:      cfg.GetRegion(),
+			credentials: awsauth.NewProvider(cfg.RoleARN, cfg.GetRegion(), client),
+			client:      client,
+		},
+	}
+}
+
+// Name implements Notifier
+func (s *SQS) Name() string {
+	return "sqs"
+}
+
+// Notify implements Notifier
+func (s *SQS) Notify(ctx context.Context, alert Alert) error {
+	message, err := json.Marshal(newAlertMessage(alert))
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{
+		"Action":      {"SendMessage"},
+		"Version":     {"2012-11-05"},
+		"MessageBody": {string(message)},
+	}
+	for i, attribute := range messageAttributes(alert) {
+		prefix := "MessageAttribute." + strconv.Itoa(i+1)
+		form.Set(prefix+".Name", attribute[0])
+		form.Set(prefix+".Value.DataType", "String")
+		form.Set(prefix+".Value.StringValue", attribute[1])
+	}
+	if strings.HasSuffix(s.queueURL, ".fifo") {
+		setFIFOParameters(form, alert)
+	}
+
+	return s.api.call(ctx, form)
+}
+
+// call posts an action, signed with the provider's credentials
+func (a *awsQueryAPI) call(ctx context.Context, form url.Values) error {
+	creds, err := a.credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	body := []byte(form.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	awsauth.SignRequest(req, body, a.service, a.region, creds, time.Now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var awsError awsErrorResponse
+		if xml.Unmarshal(respBody, &awsError) == nil && awsError.Code != "" {
+			return fmt.Errorf("%s returned %s: %s: %s", a.service, resp.Status, awsError.Code, awsError.Message)
+		}
+		return fmt.Errorf("%s returned %s: %s", a.service, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// messageAttributes returns the name and value of the message attributes of
+// an alert; empty values are left out, as SNS and SQS reject them
+func messageAttributes(alert Alert) [][2]string {
+	var attributes [][2]string
+	for _, attribute := range [][2]string{
+		{"database", alert.Database},
+		{"table", alert.Table},
+		{"status", string(alert.Status)},
+		{"severity", alert.Severity},
+	} {
+		if attribute[1] != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes
+}
+
+// setFIFOParameters orders the messages of a check in FIFO topics and queues,
+// and deduplicates retries of the same alert
+func setFIFOParameters(form url.Values, alert Alert) {
+	group := alert.Database + "/" + alert.Table
+	sum := sha256.Sum256([]byte(group + "/" + string(alert.Status) + "/" + strconv.FormatInt(alert.Timestamp.UnixNano(), 10)))
+	form.Set("MessageGroupId", group)
+	form.Set("MessageDeduplicationId", hex.EncodeToString(sum[:]))
+}
+
+// snsSubject returns the subject of an alert, used by email subscriptions
+func snsSubject(alert Alert) string {
+	subject := fmt.Sprintf("%s/%s is %s", alert.Database, alert.Table, alert.Status)
+	if alert.Recovered() {
+		subject = fmt.Sprintf("%s/%s recovered (%s)", alert.Database, alert.Table, alert.Status)
+	}
+	if len(subject) > snsMaxSubject {
+		subject = subject[:snsMaxSubject-3] + "..."
+	}
+	return subject
+}
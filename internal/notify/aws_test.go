@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// newAWSTestServer returns a server recording the forms posted to it, and
+// answering with status
+func newAWSTestServer(t *testing.T, forms *[]url.Values, status *int) *httptest.Server {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+			t.Errorf("Authorization = %q; expected a SigV4 signature", r.Header.Get("Authorization"))
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		*forms = append(*forms, r.PostForm)
+		w.WriteHeader(*status)
+		if *status != http.StatusOK {
+			w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AuthorizationError</Code><Message>not authorized</Message></Error></ErrorResponse>`))
+		}
+	}))
+}
+
+func TestSNS(t *testing.T) {
+	var forms []url.Values
+	status := http.StatusOK
+	server := newAWSTestServer(t, &forms, &status)
+	defer server.Close()
+
+	cfg := config.SNS{TopicARN: "arn:aws:sns:eu-west-1:123456789012:db-health", Endpoint: server.URL}
+	sns := NewSNS(cfg, server.Client())
+	alert := Alert{
+		Database:       "app",
+		Table:          "orders",
+		Status:         database.StatusUnhealthy,
+		PreviousStatus: database.StatusHealthy,
+		Severity:       config.SeverityCritical,
+		Error:          "replication lag 45s",
+		Timestamp:      time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Since:          time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	if err := sns.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	form := forms[0]
+	if form.Get("Action") != "Publish" || form.Get("TopicArn") != cfg.TopicARN || form.Get("Subject") != "app/orders is unhealthy" {
+		t.Errorf("form = %v; expected a publish to the topic", form)
+	}
+	if form.Get("MessageAttributes.entry.1.Name") != "database" || form.Get("MessageAttributes.entry.1.Value.StringValue") != "app" ||
+		form.Get("MessageAttributes.entry.3.Name") != "status" || form.Get("MessageAttributes.entry.3.Value.StringValue") != "unhealthy" {
+		t.Errorf("form = %v; expected the check's message attributes", form)
+	}
+	if form.Has("MessageGroupId") {
+		t.Error("expected no message group for a standard topic")
+	}
+
+	var message alertMessage
+	if err := json.Unmarshal([]byte(form.Get("Message")), &message); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if message.Status != database.StatusUnhealthy || message.StatusCode != 4 || !message.Failing || message.Recovered || message.Since == nil || message.Error != alert.Error {
+		t.Errorf("message = %+v; expected the failing check", message)
+	}
+
+	status = http.StatusForbidden
+	if err := sns.Notify(context.Background(), alert); err == nil || !strings.Contains(err.Error(), "AuthorizationError") {
+		t.Errorf("Notify() error = %v; expected the SNS error code", err)
+	}
+}
+
+func TestSQS(t *testing.T) {
+	var forms []url.Values
+	status := http.StatusOK
+	server := newAWSTestServer(t, &forms, &status)
+	defer server.Close()
+
+	cfg := config.SQS{QueueURL: server.URL + "/123456789012/db-health.fifo", Region: "eu-west-1"}
+	sqs := NewSQS(cfg, server.Client())
+	alert := Alert{
+		Database:       "app",
+		Table:          "orders",
+		Status:         database.StatusHealthy,
+		PreviousStatus: database.StatusError,
+		Severity:       config.SeverityCritical,
+		Timestamp:      time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC),
+	}
+
+	if err := sqs.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	form := forms[0]
+	if form.Get("Action") != "SendMessage" || form.Get("MessageAttribute.2.Name") != "table" || form.Get("MessageAttribute.2.Value.StringValue") != "orders" {
+		t.Errorf("form = %v; expected a message with the check's attributes", form)
+	}
+	if form.Get("MessageGroupId") != "app/orders" || len(form.Get("MessageDeduplicationId")) != 64 {
+		t.Errorf("form = %v; expected the FIFO parameters of the check", form)
+	}
+
+	var message alertMessage
+	if err := json.Unmarshal([]byte(form.Get("MessageBody")), &message); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if !message.Recovered || message.Failing || message.PreviousStatus != database.StatusError {
+		t.Errorf("message = %+v; expected the recovery", message)
+	}
+}
@@ -0,0 +1,380 @@
+// Package notify sends status changes of checks, such as a check failing or
+// recovering, to notifiers like Slack, PagerDuty and Alertmanager, and to
+// queues of event-driven consumers like SNS and SQS
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+const (
+	// eventBuffer is the number of health events buffered while they are
+	// turned into alerts
+	eventBuffer = 256
+	// notifyTimeout is the deadline of sending an alert to a notifier
+	notifyTimeout = 10 * time.Second
+)
+
+// Alert is a status change of a check that notifiers are sent
+type Alert struct {
+	Database       string
+	Table          string
+	Status         database.Status
+	PreviousStatus database.Status // empty for the first result of the check
+	Severity       string
+	Labels         map[string]string
+	Error          string
+	QueryTime      time.Duration
+	Timestamp      time.Time
+	DashboardURL   string    // link to the check on the dashboard, if configured
+	Since          time.Time // when the check started failing
+	Repeat         bool      // the alert is a reminder of an ongoing failure
+}
+
+// Recovered reports whether the alert is the recovery of a failing check
+func (a Alert) Recovered() bool {
+	return !isAlerting(a.Status)
+}
+
+// Duration returns how long the check has been failing, or failed for before
+// it recovered
+func (a Alert) Duration() time.Duration {
+	if a.Since.IsZero() {
+		return 0
+	}
+	return a.Timestamp.Sub(a.Since)
+}
+
+// formatDuration formats the duration of an outage to the second
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// alertMessage is the JSON form of an alert, sent to queues and topics that
+// automation consumes
+type alertMessage struct {
+	Database       string            `json:"database"`
+	Table          string            `json:"table"`
+	Status         database.Status   `json:"status"`
+	StatusCode     int               `json:"status_code"`
+	PreviousStatus database.Status   `json:"previous_status,omitempty"`
+	Severity       string            `json:"severity"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	QueryTime      time.Duration     `json:"query_time,omitempty"`
+	Failing        bool              `json:"failing"`   // unhealthy or worse, rather than degraded or recovered
+	Recovered      bool              `json:"recovered"` // no longer degraded or worse
+	Repeat         bool              `json:"repeat,omitempty"`
+	Since          *time.Time        `json:"since,omitempty"` // start of the outage
+	Timestamp      time.Time         `json:"timestamp"`
+	DashboardURL   string            `json:"dashboard_url,omitempty"`
+}
+
+// newAlertMessage returns the JSON form of an alert
+func newAlertMessage(alert Alert) alertMessage {
+	message := alertMessage{
+		Database:       alert.Database,
+		Table:          alert.Table,
+		Status:         alert.Status,
+		StatusCode:     alert.Status.Code(),
+		PreviousStatus: alert.PreviousStatus,
+		Severity:       alert.Severity,
+		Labels:         alert.Labels,
+		Error:          alert.Error,
+		QueryTime:      alert.QueryTime,
+		Failing:        isFailing(alert.Status),
+		Recovered:      alert.Recovered(),
+		Repeat:         alert.Repeat,
+		Timestamp:      alert.Timestamp,
+		DashboardURL:   alert.DashboardURL,
+	}
+	if !alert.Since.IsZero() {
+		since := alert.Since
+		message.Since = &since
+	}
+	return message
+}
+
+// Notifier sends alerts to a destination
+type Notifier interface {
+	// Name identifies the notifier in logs
+	Name() string
+	// Notify sends an alert
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// backgroundNotifier is a Notifier with work of its own between alerts, such
+// as posting firing alerts again
+type backgroundNotifier interface {
+	Notifier
+	// run works until stop is closed
+	run(stop <-chan struct{}, logger *slog.Logger)
+}
+
+// route is a notifier with the filter selecting the checks it is sent
+type route struct {
+	notifier Notifier
+	filter   config.NotificationFilter
+}
+
+// Dispatcher turns the status changes of the health service into alerts and
+// sends them to the notifiers whose filters select the check
+type Dispatcher struct {
+	healthService  *health.Service
+	logger         *slog.Logger
+	dashboardURL   string
+	repeatInterval time.Duration
+	routes         []route
+
+	// outages are the ongoing failures of checks, only accessed by the
+	// goroutine sending alerts
+	outages map[string]*outage
+	queue   *alertQueue
+
+	unsubscribe func()
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewDispatcher creates a dispatcher for the configured notifiers
+func NewDispatcher(cfg *config.Config, healthService *health.Service, logger *slog.Logger) *Dispatcher {
+	client := &http.Client{Timeout: notifyTimeout}
+
+	d := &Dispatcher{
+		healthService:  healthService,
+		logger:         logger,
+		dashboardURL:   cfg.Notifications.DashboardURL,
+		repeatInterval: time.Duration(cfg.Notifications.RepeatInterval),
+		outages:        make(map[string]*outage),
+		queue:          newAlertQueue(),
+	}
+	for _, slack := range cfg.Notifications.Slack {
+		d.routes = append(d.routes, route{notifier: NewSlack(slack, client), filter: slack.NotificationFilter})
+	}
+	for _, pagerDuty := range cfg.Notifications.PagerDuty {
+		d.routes = append(d.routes, route{notifier: NewPagerDuty(pagerDuty, client), filter: pagerDuty.GetFilter()})
+	}
+	for _, alertmanager := range cfg.Notifications.Alertmanager {
+		d.routes = append(d.routes, route{notifier: NewAlertmanager(alertmanager, client), filter: alertmanager.NotificationFilter})
+	}
+	for _, sns := range cfg.Notifications.SNS {
+		d.routes = append(d.routes, route{notifier: NewSNS(sns, client), filter: sns.NotificationFilter})
+	}
+	for _, sqs := range cfg.Notifications.SQS {
+		d.routes = append(d.routes, route{notifier: NewSQS(sqs, client), filter: sqs.NotificationFilter})
+	}
+	return d
+}
+
+// Enabled reports whether any notifier is configured
+func (d *Dispatcher) Enabled() bool {
+	return len(d.routes) > 0
+}
+
+// Start sends alerts for the status changes of checks until Stop is called.
+// Health events are turned into alerts as they arrive and queued, so the
+// subscription never falls behind, and therefore never loses a recovery,
+// while a slow notifier is being sent earlier alerts.
+func (d *Dispatcher) Start() {
+	events, unsubscribe := d.healthService.Subscribe(eventBuffer)
+	d.unsubscribe = unsubscribe
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+
+	var workers sync.WaitGroup
+	for _, route := range d.routes {
+		if notifier, ok := route.notifier.(backgroundNotifier); ok {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				notifier.run(d.stop, d.logger)
+			}()
+		}
+	}
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		for {
+			select {
+			case event := <-events:
+				if alert, ok := d.alertFor(event); ok {
+					d.queue.push(alert)
+				}
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+
+	// The ticker sends repeats, and the alerts silenced until a silence ended
+	tick := repeatCheckInterval
+	if d.repeatInterval > 0 && d.repeatInterval < tick {
+		tick = d.repeatInterval
+	}
+	ticker := time.NewTicker(tick)
+
+	go func() {
+		defer close(d.done)
+		defer workers.Wait()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.queue.ready:
+				for alert, ok := d.queue.pop(); ok; alert, ok = d.queue.pop() {
+					d.handle(alert)
+					select {
+					case <-d.stop:
+						return
+					default:
+					}
+				}
+			case now := <-ticker.C:
+				for _, alert := range d.due(now) {
+					d.dispatch(alert)
+				}
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+
+	d.logger.Info("Notifications enabled", "notifiers", len(d.routes))
+}
+
+// Stop ends the dispatcher, waiting for the alert being sent
+func (d *Dispatcher) Stop() {
+	if d.done == nil {
+		return
+	}
+	d.unsubscribe()
+	close(d.stop)
+	<-d.done
+}
+
+// alertFor returns the alert of a health event. Status changes into or out of
+// degraded and worse statuses are alerts; the first healthy result of a
+// check and changes between disabled, paused and maintenance are not.
+func (d *Dispatcher) alertFor(event health.Event) (Alert, bool) {
+	if event.Type != health.EventStatusChanged || event.Table == "" {
+		return Alert{}, false
+	}
+	if !isAlerting(event.Status) && !isAlerting(event.PreviousStatus) {
+		return Alert{}, false
+	}
+
+	return Alert{
+		Database:       event.Database,
+		Table:          event.Table,
+		Status:         event.Status,
+		PreviousStatus: event.PreviousStatus,
+		Severity:       d.healthService.GetTableSeverity(event.Database, event.Table),
+		Labels:         event.Labels,
+		Error:          event.Error,
+		QueryTime:      event.QueryTime,
+		Timestamp:      event.Timestamp,
+		DashboardURL:   dashboardURL(d.dashboardURL, event.Database, event.Table),
+	}, true
+}
+
+// dispatch sends an alert to the notifiers whose filters select its check.
+// Failures are logged; alerts are not retried.
+func (d *Dispatcher) dispatch(alert Alert) {
+	for _, route := range d.routes {
+		if !route.filter.Matches(alert.Severity, alert.Labels) {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		err := route.notifier.Notify(ctx, alert)
+		cancel()
+		if err != nil {
+			d.logger.Error("Failed to send notification",
+				"notifier", route.notifier.Name(),
+				"database", alert.Database,
+				"table", alert.Table,
+				"status", alert.Status,
+				"error", err)
+			continue
+		}
+
+		d.logger.Debug("Sent notification",
+			"notifier", route.notifier.Name(),
+			"database", alert.Database,
+			"table", alert.Table,
+			"status", alert.Status)
+	}
+}
+
+// alertQueue holds the alerts waiting to be sent. It is unbounded: alerts are
+// only queued for status changes, and a slow notifier must delay them rather
+// than drop them.
+type alertQueue struct {
+	mu     sync.Mutex
+	alerts []Alert
+	ready  chan struct{} // signaled when alerts are queued
+}
+
+// newAlertQueue creates an empty alert queue
+func newAlertQueue() *alertQueue {
+	return &alertQueue{ready: make(chan struct{}, 1)}
+}
+
+// push queues an alert
+func (q *alertQueue) push(alert Alert) {
+	q.mu.Lock()
+	q.alerts = append(q.alerts, alert)
+	health.SetNotifierQueueDepth(len(q.alerts))
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes the oldest alert from the queue, returning false when it is
+// empty
+func (q *alertQueue) pop() (Alert, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.alerts) == 0 {
+		return Alert{}, false
+	}
+	alert := q.alerts[0]
+	q.alerts[0] = Alert{}
+	q.alerts = q.alerts[1:]
+	health.SetNotifierQueueDepth(len(q.alerts))
+	return alert, true
+}
+
+// isAlerting reports whether a status is worth notifying about: degraded or
+// worse, including errors and unknown results
+func isAlerting(status database.Status) bool {
+	return status.Code() >= database.StatusDegraded.Code()
+}
+
+// dashboardURL returns the dashboard link of a check, replacing {database}
+// and {table} in the configured URL
+func dashboardURL(template, databaseName, tableName string) string {
+	if template == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{database}", url.PathEscape(databaseName),
+		"{table}", url.PathEscape(tableName),
+	).Replace(template)
+}
@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+// recordingNotifier records the alerts it is sent
+type recordingNotifier struct {
+	alerts []Alert
+	err    error
+}
+
+func (n *recordingNotifier) Name() string { return "recording" }
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return n.err
+}
+
+func newTestDispatcher(t *testing.T) *Dispatcher {
+	t.Helper()
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "app", Tables: []config.Table{
+			{Name: "orders", Severity: config.SeverityCritical},
+			{Name: "reports", Severity: config.SeverityWarning},
+		}}},
+		Notifications: config.Notifications{DashboardURL: "https://grafana.example.com/d/db?var-database={database}&var-table={table}"},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewDispatcher(cfg, health.NewService(cfg, logger), logger)
+}
+
+func TestAlertFor(t *testing.T) {
+	dispatcher := newTestDispatcher(t)
+
+	tests := []struct {
+		name     string
+		event    health.Event
+		expected bool
+	}{
+		{"failure", health.Event{Type: health.EventStatusChanged, Database: "app", Table: "orders", Status: database.StatusUnhealthy, PreviousStatus: database.StatusHealthy}, true},
+		{"first failure", health.Event{Type: health.EventStatusChanged, Database: "app", Table: "orders", Status: database.StatusError}, true},
+		{"recovery", health.Event{Type: health.EventStatusChanged, Database: "app", Table: "orders", Status: database.StatusHealthy, PreviousStatus: database.StatusDegraded}, true},
+		{"first healthy result", health.Event{Type: health.EventStatusChanged, Database: "app", Table: "orders", Status: database.StatusHealthy}, false},
+		{"paused", health.Event{Type: health.EventStatusChanged, Database: "app", Table: "orders", Status: database.StatusPaused, PreviousStatus: database.StatusMaintenance}, false},
+		{"check completed", health.Event{Type: health.EventCheckCompleted, Database: "app", Table: "orders", Status: database.StatusUnhealthy}, false},
+		{"disconnected", health.Event{Type: health.EventDisconnected, Database: "app"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := dispatcher.alertFor(tt.event); ok != tt.expected {
+				t.Errorf("alertFor() = %v; expected %v", ok, tt.expected)
+			}
+		})
+	}
+
+	alert, _ := dispatcher.alertFor(health.Event{Type: health.EventStatusChanged, Database: "app", Table: "reports", Status: database.StatusUnhealthy})
+	if alert.Severity != config.SeverityWarning {
+		t.Errorf("Severity = %q; expected the check's severity", alert.Severity)
+	}
+	if expected := "https://grafana.example.com/d/db?var-database=app&var-table=reports"; alert.DashboardURL != expected {
+		t.Errorf("DashboardURL = %q; expected %q", alert.DashboardURL, expected)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	dispatcher := newTestDispatcher(t)
+	critical := &recordingNotifier{}
+	payments := &recordingNotifier{err: errors.New("unreachable")}
+	everything := &recordingNotifier{}
+	dispatcher.routes = []route{
+		{notifier: critical, filter: config.NotificationFilter{Severities: []string{config.SeverityCritical}}},
+		{notifier: payments, filter: config.NotificationFilter{Labels: map[string]string{"team": "payments"}}},
+		{notifier: everything},
+	}
+
+	dispatcher.dispatch(Alert{Database: "app", Table: "orders", Status: database.StatusUnhealthy, Severity: config.SeverityCritical, Timestamp: time.Now()})
+	dispatcher.dispatch(Alert{Database: "app", Table: "reports", Status: database.StatusUnhealthy, Severity: config.SeverityWarning, Labels: map[string]string{"team": "payments"}, Timestamp: time.Now()})
+
+	if len(critical.alerts) != 1 || critical.alerts[0].Table != "orders" {
+		t.Errorf("critical notifier alerts = %+v; expected the critical check", critical.alerts)
+	}
+	if len(payments.alerts) != 1 || payments.alerts[0].Table != "reports" {
+		t.Errorf("payments notifier alerts = %+v; expected the labeled check", payments.alerts)
+	}
+	if len(everything.alerts) != 2 {
+		t.Errorf("unfiltered notifier alerts = %+v; expected both", everything.alerts)
+	}
+}
+
+func TestAlertQueue(t *testing.T) {
+	queue := newAlertQueue()
+	depth := expvar.Get("gsqlhealth").(*expvar.Map).Get("notifier_queue_depth").(*expvar.Int)
+
+	// More alerts than the event buffer are kept while none is sent
+	for i := 0; i < 2*eventBuffer; i++ {
+		queue.push(Alert{Database: "app", Table: "orders", QueryTime: time.Duration(i)})
+	}
+	if depth.Value() != 2*eventBuffer {
+		t.Errorf("notifier_queue_depth = %d; expected %d", depth.Value(), 2*eventBuffer)
+	}
+	select {
+	case <-queue.ready:
+	default:
+		t.Fatal("Expected the queue to signal queued alerts")
+	}
+
+	for i := 0; i < 2*eventBuffer; i++ {
+		alert, ok := queue.pop()
+		if !ok || alert.QueryTime != time.Duration(i) {
+			t.Fatalf("pop() = %v, %v; expected alert %d in order", alert.QueryTime, ok, i)
+		}
+	}
+	if _, ok := queue.pop(); ok {
+		t.Error("Expected the queue to be empty")
+	}
+	if depth.Value() != 0 {
+		t.Errorf("notifier_queue_depth = %d; expected 0", depth.Value())
+	}
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// repeatCheckInterval is how often outages are checked for repeats, at most
+const repeatCheckInterval = time.Minute
+
+// Alert levels; notifications are sent when a check's level changes, not
+// for every status change
+const (
+	levelOK       = iota // healthy, or not counted, such as paused
+	levelDegraded        // slow but up
+	levelFailing         // unhealthy or worse
+)
+
+// outage is an ongoing failure of a check, from its first alert until it
+// recovers. The outage of a notified check is kept until its recovery is
+// sent, which a silence can delay.
+type outage struct {
+	since    time.Time // first alert of the outage
+	lastSent time.Time // latest notification, for repeats
+	level    int
+	alert    Alert // latest alert, repeated with its status and error
+	notified bool  // an alert of the outage was sent, so its recovery must be sent too
+	pending  bool  // the latest level change was silenced, and is sent when the silence ends
+}
+
+// alertLevel returns the level of a status
+func alertLevel(status database.Status) int {
+	switch {
+	case isFailing(status):
+		return levelFailing
+	case isAlerting(status):
+		return levelDegraded
+	default:
+		return levelOK
+	}
+}
+
+// handle sends the alert of a status change, unless it is a duplicate of an
+// ongoing outage or the check is silenced
+func (d *Dispatcher) handle(alert Alert) {
+	alert, send := d.track(alert)
+	if !send {
+		return
+	}
+
+	current := d.outages[alert.Database+"/"+alert.Table]
+	if d.silenced(alert) {
+		if current != nil {
+			current.pending = true
+		}
+		d.logger.Debug("Notification silenced", "database", alert.Database, "table", alert.Table, "status", alert.Status)
+		return
+	}
+	if current != nil {
+		current.notified, current.pending = true, false
+		current.lastSent = alert.Timestamp
+		if current.level == levelOK {
+			delete(d.outages, alert.Database+"/"+alert.Table)
+		}
+	}
+	d.dispatch(alert)
+}
+
+// silenced reports whether the notifications of an alert's check are silenced
+func (d *Dispatcher) silenced(alert Alert) bool {
+	return d.healthService.IsSilenced(alert.Database, alert.Table, alert.Labels, alert.Timestamp)
+}
+
+// track records an alert in the outage of its check, returning whether it is
+// sent. The first alert of an outage, changes between degraded and failing,
+// and the recovery of outages that were notified are sent; other status
+// changes of an ongoing outage, such as unhealthy to error, are duplicates and
+// only update what repeats report. Sent alerts carry the start of their outage.
+// The outage of a notified recovery is left to be removed once the recovery is
+// sent, and one failing again before then continues.
+func (d *Dispatcher) track(alert Alert) (Alert, bool) {
+	key := alert.Database + "/" + alert.Table
+	level := alertLevel(alert.Status)
+
+	current, ongoing := d.outages[key]
+	switch {
+	case !ongoing && level == levelOK:
+		return alert, false
+	case !ongoing:
+		alert.Since = alert.Timestamp
+		d.outages[key] = &outage{since: alert.Timestamp, level: level, alert: alert}
+		return alert, true
+	case level == levelOK && !current.notified:
+		delete(d.outages, key)
+		alert.Since = current.since
+		return alert, false
+	}
+
+	alert.Since = current.since
+	current.alert = alert
+	if level == current.level {
+		return alert, false
+	}
+	current.level = level
+	return alert, true
+}
+
+// due returns the alerts of outages to send at now: the alerts silenced
+// until a silence that has ended, and repeats of the outages last notified a
+// repeat interval or more before now, which are marked sent. Outages whose
+// recovery is sent and outages of checks that are no longer configured are
+// dropped.
+func (d *Dispatcher) due(now time.Time) []Alert {
+	var alerts []Alert
+	for key, current := range d.outages {
+		repeat := !current.pending && d.repeatInterval > 0 && now.Sub(current.lastSent) >= d.repeatInterval
+		if !current.pending && !repeat {
+			continue
+		}
+		if !d.configured(current.alert.Database, current.alert.Table) {
+			delete(d.outages, key)
+			continue
+		}
+
+		alert := current.alert
+		alert.Timestamp = now
+		if d.silenced(alert) {
+			continue
+		}
+		if repeat {
+			alert.PreviousStatus = alert.Status
+			alert.Repeat = true
+		}
+		current.notified, current.pending = true, false
+		current.lastSent = now
+		if current.level == levelOK {
+			delete(d.outages, key)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// configured reports whether a check is still configured
+func (d *Dispatcher) configured(databaseName, tableName string) bool {
+	tables, err := d.healthService.GetTableNames(databaseName)
+	if err != nil {
+		return false
+	}
+	for _, table := range tables {
+		if table == tableName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+func TestHandle(t *testing.T) {
+	dispatcher := newTestDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.routes = []route{{notifier: notifier}}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		status   database.Status
+		after    time.Duration
+		expected bool
+	}{
+		{database.StatusHealthy, 0, false},
+		{database.StatusDegraded, time.Minute, true},
+		{database.StatusUnhealthy, 2 * time.Minute, true},
+		{database.StatusError, 3 * time.Minute, false},
+		{database.StatusUnhealthy, 4 * time.Minute, false},
+		{database.StatusHealthy, 10 * time.Minute, true},
+		{database.StatusHealthy, 11 * time.Minute, false},
+	}
+
+	var previous database.Status
+	for i, step := range steps {
+		sent := len(notifier.alerts)
+		dispatcher.handle(Alert{Database: "app", Table: "orders", Status: step.status, PreviousStatus: previous, Timestamp: start.Add(step.after)})
+		if (len(notifier.alerts) > sent) != step.expected {
+			t.Errorf("step %d (%s): sent = %v; expected %v", i, step.status, len(notifier.alerts) > sent, step.expected)
+		}
+		if len(notifier.alerts) > sent && !notifier.alerts[sent].Since.Equal(start.Add(time.Minute)) {
+			t.Errorf("step %d (%s): Since = %v; expected the start of the outage", i, step.status, notifier.alerts[sent].Since)
+		}
+		previous = step.status
+	}
+
+	if recovery := notifier.alerts[len(notifier.alerts)-1]; !recovery.Recovered() || recovery.Duration() != 9*time.Minute {
+		t.Errorf("recovery = %+v; expected the outage to have lasted 9m", recovery)
+	}
+	if len(dispatcher.outages) != 0 {
+		t.Errorf("outages = %v; expected none after recovering", dispatcher.outages)
+	}
+}
+
+func TestRepeats(t *testing.T) {
+	dispatcher := newTestDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.routes = []route{{notifier: notifier}}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	dispatcher.handle(Alert{Database: "app", Table: "orders", Status: database.StatusUnhealthy, Timestamp: start})
+	dispatcher.handle(Alert{Database: "app", Table: "orders", Status: database.StatusError, Error: "connection refused", PreviousStatus: database.StatusUnhealthy, Timestamp: start.Add(time.Minute)})
+	dispatcher.handle(Alert{Database: "app", Table: "removed", Status: database.StatusUnhealthy, Timestamp: start})
+
+	if alerts := dispatcher.due(start.Add(time.Hour)); len(alerts) != 0 {
+		t.Errorf("due() = %+v; expected no repeats without a repeat interval", alerts)
+	}
+
+	dispatcher.repeatInterval = 30 * time.Minute
+	if alerts := dispatcher.due(start.Add(10 * time.Minute)); len(alerts) != 0 {
+		t.Errorf("due() = %+v; expected no repeats before the repeat interval", alerts)
+	}
+
+	alerts := dispatcher.due(start.Add(30 * time.Minute))
+	if len(alerts) != 1 {
+		t.Fatalf("due() = %+v; expected the configured check's outage", alerts)
+	}
+	repeat := alerts[0]
+	if !repeat.Repeat || repeat.Status != database.StatusError || repeat.PreviousStatus != database.StatusError || repeat.Error != "connection refused" {
+		t.Errorf("repeat = %+v; expected the latest status and error", repeat)
+	}
+	if repeat.Duration() != 30*time.Minute {
+		t.Errorf("Duration() = %v; expected 30m", repeat.Duration())
+	}
+	if _, ok := dispatcher.outages["app/removed"]; ok {
+		t.Error("expected the outage of a check no longer configured to be dropped")
+	}
+
+	if alerts := dispatcher.due(start.Add(45 * time.Minute)); len(alerts) != 0 {
+		t.Errorf("due() = %+v; expected no repeats until another repeat interval", alerts)
+	}
+	if alerts := dispatcher.due(start.Add(time.Hour)); len(alerts) != 1 {
+		t.Errorf("due() = %+v; expected the outage to be repeated again", alerts)
+	}
+}
+
+func TestHandleSilenced(t *testing.T) {
+	dispatcher := newTestDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.routes = []route{{notifier: notifier}}
+	now := time.Now()
+
+	silence, err := dispatcher.healthService.AddSilence(health.SilenceMatcher{Database: "app", Table: "orders"}, time.Hour, "index rebuild", "user:dba")
+	if err != nil {
+		t.Fatalf("AddSilence() error = %v", err)
+	}
+
+	dispatcher.handle(Alert{Database: "app", Table: "orders", Status: database.StatusUnhealthy, Timestamp: now})
+	dispatcher.handle(Alert{Database: "app", Table: "reports", Status: database.StatusUnhealthy, Timestamp: now})
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Table != "reports" {
+		t.Fatalf("alerts = %+v; expected the unsilenced check's only", notifier.alerts)
+	}
+	if alerts := dispatcher.due(now.Add(time.Minute)); len(alerts) != 0 {
+		t.Errorf("due() = %+v; expected nothing while silenced", alerts)
+	}
+
+	if err := dispatcher.healthService.ExpireSilence(silence.ID); err != nil {
+		t.Fatalf("ExpireSilence() error = %v", err)
+	}
+	alerts := dispatcher.due(now.Add(2 * time.Minute))
+	if len(alerts) != 1 || alerts[0].Table != "orders" || alerts[0].Repeat {
+		t.Fatalf("due() = %+v; expected the failure silenced until the silence expired", alerts)
+	}
+
+	// Recoveries are silenced too
+	if _, err := dispatcher.healthService.AddSilence(health.SilenceMatcher{Database: "app", Table: "reports"}, time.Hour, "index rebuild", "user:dba"); err != nil {
+		t.Fatalf("AddSilence() error = %v", err)
+	}
+	dispatcher.handle(Alert{Database: "app", Table: "reports", Status: database.StatusHealthy, PreviousStatus: database.StatusUnhealthy, Timestamp: now.Add(3 * time.Minute)})
+	if len(notifier.alerts) != 1 {
+		t.Errorf("alerts = %+v; expected the recovery to be silenced", notifier.alerts)
+	}
+}
+
+func TestRecoverySilenced(t *testing.T) {
+	dispatcher := newTestDispatcher(t)
+	notifier := &recordingNotifier{}
+	dispatcher.routes = []route{{notifier: notifier}}
+	now := time.Now()
+
+	dispatcher.handle(Alert{Database: "app", Table: "orders", Status: database.StatusUnhealthy, Timestamp: now})
+	silence, err := dispatcher.healthService.AddSilence(health.SilenceMatcher{Database: "app", Table: "orders"}, time.Hour, "maintenance", "user:dba")
+	if err != nil {
+		t.Fatalf("AddSilence() error = %v", err)
+	}
+	dispatcher.handle(Alert{Database: "app", Table: "orders", Status: database.StatusHealthy, PreviousStatus: database.StatusUnhealthy, Timestamp: now.Add(5 * time.Minute)})
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("alerts = %+v; expected the recovery to be silenced", notifier.alerts)
+	}
+	if alerts := dispatcher.due(now.Add(6 * time.Minute)); len(alerts) != 0 {
+		t.Errorf("due() = %+v; expected nothing while silenced", alerts)
+	}
+
+	if err := dispatcher.healthService.ExpireSilence(silence.ID); err != nil {
+		t.Fatalf("ExpireSilence() error = %v", err)
+	}
+	alerts := dispatcher.due(now.Add(7 * time.Minute))
+	if len(alerts) != 1 || !alerts[0].Recovered() || alerts[0].Repeat {
+		t.Fatalf("due() = %+v; expected the recovery silenced until the silence expired", alerts)
+	}
+	if !alerts[0].Since.Equal(now) {
+		t.Errorf("Since = %v; expected the start of the outage", alerts[0].Since)
+	}
+	if len(dispatcher.outages) != 0 {
+		t.Errorf("outages = %v; expected none once the recovery was sent", dispatcher.outages)
+	}
+	if alerts := dispatcher.due(now.Add(8 * time.Minute)); len(alerts) != 0 {
+		t.Errorf("due() = %+v; expected the recovery to be sent once", alerts)
+	}
+}
@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// PagerDuty triggers an incident when a check fails and resolves it when the
+// check recovers, through the Events API v2. Events of a check share a dedup
+// key, so a flapping check keeps a single incident open.
+type PagerDuty struct {
+	routingKey string
+	url        string
+	client     *http.Client
+}
+
+// pagerDutyEvent is the body of Events API v2 requests
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Client      string            `json:"client"`
+	ClientURL   string            `json:"client_url,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+// pagerDutyPayload describes the failure of a trigger event
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Component     string            `json:"component"`
+	Group         string            `json:"group"`
+	Class         string            `json:"class"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// pagerDutyLink is a link of an incident
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// NewPagerDuty creates a PagerDuty notifier
+func NewPagerDuty(cfg config.PagerDuty, client *http.Client) *PagerDuty {
+	return &PagerDuty{
+		routingKey: cfg.RoutingKey,
+		url:        cfg.GetURL(),
+		client:     client,
+	}
+}
+
+// Name implements Notifier
+func (p *PagerDuty) Name() string {
+	return "pagerduty"
+}
+
+// Notify implements Notifier. Failing checks trigger an incident, and checks
+// that are no longer failing resolve it; a check becoming degraded is left
+// alone, as it is slow but up.
+func (p *PagerDuty) Notify(ctx context.Context, alert Alert) error {
+	event := pagerDutyEvent{
+		RoutingKey: p.routingKey,
+		DedupKey:   pagerDutyDedupKey(alert.Database, alert.Table),
+		Client:     "gsqlhealth",
+		ClientURL:  alert.DashboardURL,
+	}
+	switch {
+	case isFailing(alert.Status):
+		event.EventAction = "trigger"
+		event.Payload = newPagerDutyPayload(alert)
+		if alert.DashboardURL != "" {
+			event.Links = []pagerDutyLink{{Href: alert.DashboardURL, Text: "Dashboard"}}
+		}
+	case isFailing(alert.PreviousStatus):
+		event.EventAction = "resolve"
+	default:
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("pagerduty returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// newPagerDutyPayload describes a failing check. The incident severity is
+// the check's, so warning checks routed to PagerDuty open low-urgency
+// incidents where the service is configured for them.
+func newPagerDutyPayload(alert Alert) *pagerDutyPayload {
+	summary := fmt.Sprintf("%s/%s is %s", alert.Database, alert.Table, alert.Status)
+	if alert.Error != "" {
+		summary += ": " + alert.Error
+	}
+	// Summaries are truncated by PagerDuty past 1024 characters
+	if len(summary) > 1024 {
+		summary = summary[:1021] + "..."
+	}
+
+	details := map[string]string{
+		"status":   string(alert.Status),
+		"severity": alert.Severity,
+	}
+	if alert.PreviousStatus != "" {
+		details["previous_status"] = string(alert.PreviousStatus)
+	}
+	if alert.Error != "" {
+		details["error"] = alert.Error
+	}
+	if alert.QueryTime > 0 {
+		details["query_time"] = alert.QueryTime.String()
+	}
+	for name, value := range alert.Labels {
+		details["label_"+name] = value
+	}
+
+	return &pagerDutyPayload{
+		Summary:       summary,
+		Source:        alert.Database,
+		Severity:      pagerDutySeverity(alert.Severity),
+		Timestamp:     alert.Timestamp.UTC().Format(time.RFC3339),
+		Component:     alert.Table,
+		Group:         alert.Database,
+		Class:         string(alert.Status),
+		CustomDetails: details,
+	}
+}
+
+// pagerDutyDedupKey returns the dedup key of a check's incidents
+func pagerDutyDedupKey(databaseName, tableName string) string {
+	return "gsqlhealth/" + databaseName + "/" + tableName
+}
+
+// pagerDutySeverity maps a check severity onto an event severity
+func pagerDutySeverity(severity string) string {
+	switch severity {
+	case config.SeverityWarning:
+		return "warning"
+	case config.SeverityInfo:
+		return "info"
+	default:
+		return "critical"
+	}
+}
+
+// isFailing reports whether a status is a failure rather than slow or
+// healthy: unhealthy or worse, including errors and unknown results
+func isFailing(status database.Status) bool {
+	return status.Code() >= database.StatusUnhealthy.Code()
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestPagerDuty(t *testing.T) {
+	var events []pagerDutyEvent
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Failed to decode event: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"status":"success","message":"Event processed"}`))
+	}))
+	defer server.Close()
+
+	pagerDuty := NewPagerDuty(config.PagerDuty{RoutingKey: "R0UT1NG", URL: server.URL}, server.Client())
+	alert := Alert{
+		Database:     "app",
+		Table:        "orders",
+		Severity:     config.SeverityCritical,
+		Labels:       map[string]string{"team": "payments"},
+		Error:        "connection refused",
+		Timestamp:    time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		DashboardURL: "https://grafana.example.com/d/db",
+	}
+
+	transitions := []struct {
+		previous, status database.Status
+		expectedAction   string
+	}{
+		{database.StatusHealthy, database.StatusUnhealthy, "trigger"},
+		{database.StatusUnhealthy, database.StatusError, "trigger"},
+		{database.StatusError, database.StatusDegraded, "resolve"},
+		{database.StatusDegraded, database.StatusHealthy, ""},
+		{database.StatusHealthy, database.StatusDegraded, ""},
+	}
+
+	for _, transition := range transitions {
+		events = nil
+		alert.PreviousStatus, alert.Status = transition.previous, transition.status
+		if err := pagerDuty.Notify(context.Background(), alert); err != nil {
+			t.Fatalf("Notify(%s → %s) error = %v", transition.previous, transition.status, err)
+		}
+
+		if transition.expectedAction == "" {
+			if len(events) != 0 {
+				t.Errorf("%s → %s: expected no event, got %+v", transition.previous, transition.status, events)
+			}
+			continue
+		}
+		if len(events) != 1 {
+			t.Fatalf("%s → %s: expected one event, got %d", transition.previous, transition.status, len(events))
+		}
+		event := events[0]
+		if event.EventAction != transition.expectedAction || event.RoutingKey != "R0UT1NG" || event.DedupKey != "gsqlhealth/app/orders" {
+			t.Errorf("%s → %s: event = %+v; expected %s with the check's dedup key", transition.previous, transition.status, event, transition.expectedAction)
+		}
+		if transition.expectedAction == "resolve" && event.Payload != nil {
+			t.Errorf("Expected resolve events without a payload, got %+v", event.Payload)
+		}
+	}
+
+	alert.PreviousStatus, alert.Status = database.StatusHealthy, database.StatusUnhealthy
+	events = nil
+	if err := pagerDuty.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	payload := events[0].Payload
+	if payload == nil || payload.Summary != "app/orders is unhealthy: connection refused" || payload.Severity != "critical" ||
+		payload.Source != "app" || payload.Component != "orders" || payload.Timestamp != "2024-01-15T10:30:00Z" ||
+		payload.CustomDetails["label_team"] != "payments" {
+		t.Errorf("payload = %+v; expected the failure of the check", payload)
+	}
+	if len(events[0].Links) != 1 || events[0].Links[0].Href != alert.DashboardURL {
+		t.Errorf("links = %+v; expected the dashboard", events[0].Links)
+	}
+
+	status = http.StatusBadRequest
+	if err := pagerDuty.Notify(context.Background(), alert); err == nil {
+		t.Errorf("Expected an error for rejected events")
+	}
+}
+
+func TestPagerDutySeverity(t *testing.T) {
+	tests := map[string]string{
+		config.SeverityCritical: "critical",
+		config.SeverityWarning:  "warning",
+		config.SeverityInfo:     "info",
+		"":                      "critical",
+	}
+	for severity, expected := range tests {
+		if got := pagerDutySeverity(severity); got != expected {
+			t.Errorf("pagerDutySeverity(%q) = %q; expected %q", severity, got, expected)
+		}
+	}
+}
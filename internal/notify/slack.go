@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// slackPostMessageURL is the Web API method bot tokens post messages with
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// Slack posts alerts as message attachments, to an incoming webhook or with
+// a bot token to a channel
+type Slack struct {
+	webhookURL string
+	token      string
+	channel    string
+	apiURL     string // chat.postMessage endpoint, replaced in tests
+	client     *http.Client
+}
+
+// slackMessage is the body of webhook and chat.postMessage requests
+type slackMessage struct {
+	Channel     string            `json:"channel,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// slackAttachment is a message attachment; the color bar shows the status
+type slackAttachment struct {
+	Fallback  string       `json:"fallback"`
+	Color     string       `json:"color"`
+	Title     string       `json:"title"`
+	TitleLink string       `json:"title_link,omitempty"`
+	Text      string       `json:"text,omitempty"`
+	Fields    []slackField `json:"fields"`
+	Footer    string       `json:"footer"`
+	Timestamp int64        `json:"ts"`
+}
+
+// slackField is a field of a message attachment
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// slackResponse is the response of Web API methods, which report errors with
+// a 200 status
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// NewSlack creates a Slack notifier
+func NewSlack(cfg config.Slack, client *http.Client) *Slack {
+	return &Slack{
+		webhookURL: cfg.WebhookURL,
+		token:      cfg.Token,
+		channel:    cfg.Channel,
+		apiURL:     slackPostMessageURL,
+		client:     client,
+	}
+}
+
+// Name implements Notifier
+func (s *Slack) Name() string {
+	return "slack"
+}
+
+// Notify implements Notifier
+func (s *Slack) Notify(ctx context.Context, alert Alert) error {
+	attachment := newSlackAttachment(alert)
+	body, err := json.Marshal(slackMessage{
+		Channel:     s.channel,
+		Text:        attachment.Fallback,
+		Attachments: []slackAttachment{attachment},
+	})
+	if err != nil {
+		return err
+	}
+
+	target := s.webhookURL
+	if s.token != "" {
+		target = s.apiURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("slack returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	if s.token != "" {
+		var response slackResponse
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			return fmt.Errorf("invalid slack response: %w", err)
+		}
+		if !response.OK {
+			return fmt.Errorf("slack returned error: %s", response.Error)
+		}
+	}
+	return nil
+}
+
+// newSlackAttachment formats an alert as a message attachment
+func newSlackAttachment(alert Alert) slackAttachment {
+	check := alert.Database + "/" + alert.Table
+	title := fmt.Sprintf("%s is %s", check, alert.Status)
+	switch {
+	case alert.Recovered() && alert.Duration() > 0:
+		title = fmt.Sprintf("%s recovered (%s) after %s", check, alert.Status, formatDuration(alert.Duration()))
+	case alert.Recovered():
+		title = fmt.Sprintf("%s recovered (%s)", check, alert.Status)
+	case alert.Repeat:
+		title = fmt.Sprintf("%s is still %s (for %s)", check, alert.Status, formatDuration(alert.Duration()))
+	}
+
+	transition := string(alert.Status)
+	if alert.PreviousStatus != "" {
+		transition = string(alert.PreviousStatus) + " → " + string(alert.Status)
+	}
+
+	attachment := slackAttachment{
+		Fallback:  title,
+		Color:     slackColor(alert.Status),
+		Title:     title,
+		TitleLink: alert.DashboardURL,
+		Fields: []slackField{
+			{Title: "Database", Value: alert.Database, Short: true},
+			{Title: "Table", Value: alert.Table, Short: true},
+			{Title: "Status", Value: transition, Short: true},
+			{Title: "Severity", Value: alert.Severity, Short: true},
+		},
+		Footer:    "gsqlhealth",
+		Timestamp: alert.Timestamp.Unix(),
+	}
+	if alert.QueryTime > 0 {
+		attachment.Fields = append(attachment.Fields, slackField{Title: "Latency", Value: alert.QueryTime.String(), Short: true})
+	}
+	if len(alert.Labels) > 0 {
+		labels := make([]string, 0, len(alert.Labels))
+		for name, value := range alert.Labels {
+			labels = append(labels, name+"="+value)
+		}
+		sort.Strings(labels)
+		attachment.Fields = append(attachment.Fields, slackField{Title: "Labels", Value: strings.Join(labels, ", "), Short: true})
+	}
+	if alert.Error != "" {
+		attachment.Text = "```" + alert.Error + "```"
+	}
+	return attachment
+}
+
+// slackColor returns the attachment color of a status
+func slackColor(status database.Status) string {
+	switch {
+	case !isAlerting(status):
+		return "good"
+	case status == database.StatusDegraded:
+		return "warning"
+	default:
+		return "danger"
+	}
+}
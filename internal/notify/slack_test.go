@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+func TestSlackWebhook(t *testing.T) {
+	var message slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("Expected no Authorization header for webhooks")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("Failed to decode message: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	slack := NewSlack(config.Slack{WebhookURL: server.URL}, server.Client())
+	alert := Alert{
+		Database:       "app",
+		Table:          "orders",
+		Status:         database.StatusUnhealthy,
+		PreviousStatus: database.StatusHealthy,
+		Severity:       config.SeverityCritical,
+		Labels:         map[string]string{"team": "payments", "env": "prod"},
+		Error:          "connection refused",
+		QueryTime:      1500 * time.Millisecond,
+		Timestamp:      time.Unix(1705314600, 0),
+		DashboardURL:   "https://grafana.example.com/d/db",
+	}
+	if err := slack.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if message.Channel != "" || len(message.Attachments) != 1 {
+		t.Fatalf("message = %+v; expected one attachment without a channel", message)
+	}
+	attachment := message.Attachments[0]
+	if attachment.Title != "app/orders is unhealthy" || attachment.Color != "danger" || attachment.TitleLink != alert.DashboardURL || attachment.Timestamp != 1705314600 {
+		t.Errorf("attachment = %+v; expected the failure linked to the dashboard", attachment)
+	}
+	if !strings.Contains(attachment.Text, "connection refused") {
+		t.Errorf("Text = %q; expected the error", attachment.Text)
+	}
+	fields := make(map[string]string)
+	for _, field := range attachment.Fields {
+		fields[field.Title] = field.Value
+	}
+	expected := map[string]string{
+		"Database": "app",
+		"Table":    "orders",
+		"Status":   "healthy → unhealthy",
+		"Severity": "critical",
+		"Latency":  "1.5s",
+		"Labels":   "env=prod, team=payments",
+	}
+	for title, value := range expected {
+		if fields[title] != value {
+			t.Errorf("field %s = %q; expected %q", title, fields[title], value)
+		}
+	}
+
+	alert.Status, alert.PreviousStatus, alert.Error = database.StatusHealthy, database.StatusUnhealthy, ""
+	if err := slack.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if attachment := message.Attachments[0]; attachment.Title != "app/orders recovered (healthy)" || attachment.Color != "good" {
+		t.Errorf("attachment = %+v; expected the recovery", attachment)
+	}
+
+	alert.Since = alert.Timestamp.Add(-90 * time.Minute)
+	if err := slack.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if attachment := message.Attachments[0]; attachment.Title != "app/orders recovered (healthy) after 1h30m0s" {
+		t.Errorf("Title = %q; expected the outage duration", attachment.Title)
+	}
+
+	alert.Status, alert.PreviousStatus, alert.Repeat = database.StatusError, database.StatusError, true
+	if err := slack.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if attachment := message.Attachments[0]; attachment.Title != "app/orders is still error (for 1h30m0s)" {
+		t.Errorf("Title = %q; expected a repeat", attachment.Title)
+	}
+}
+
+func TestSlackToken(t *testing.T) {
+	response := `{"ok": true}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer xoxb-token" {
+			t.Errorf("Authorization = %q; expected the bot token", auth)
+		}
+		var message slackMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil || message.Channel != "#db-alerts" {
+			t.Errorf("message = %+v, %v; expected the channel", message, err)
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	slack := NewSlack(config.Slack{Token: "xoxb-token", Channel: "#db-alerts"}, server.Client())
+	slack.apiURL = server.URL
+	alert := Alert{Database: "app", Table: "orders", Status: database.StatusDegraded, Timestamp: time.Now()}
+
+	if err := slack.Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	// The Web API reports errors with a 200 status
+	response = `{"ok": false, "error": "channel_not_found"}`
+	if err := slack.Notify(context.Background(), alert); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Notify() error = %v; expected channel_not_found", err)
+	}
+}
@@ -0,0 +1,69 @@
+package oidc
+
+import (
+	"strings"
+	"time"
+)
+
+// Claims are the claims of a verified token
+type Claims map[string]interface{}
+
+// Subject returns the sub claim
+func (c Claims) Subject() string {
+	subject, _ := c["sub"].(string)
+	return subject
+}
+
+// Strings returns the values of a string or string array claim. Dots in the
+// name select nested claims, as in "realm_access.roles", and string claims
+// are split on spaces, as in "scope".
+func (c Claims) Strings(name string) []string {
+	var value interface{} = map[string]interface{}(c)
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// hasAudience reports whether the aud claim, a string or an array, contains
+// the audience
+func (c Claims) hasAudience(audience string) bool {
+	switch aud := c["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, item := range aud {
+			if item == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// time returns a NumericDate claim
+func (c Claims) time(name string) (time.Time, bool) {
+	seconds, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
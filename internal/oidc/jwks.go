@@ -0,0 +1,112 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// jsonWebKeySet is a JWKS document (RFC 7517)
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey is a public key of a JWKS document
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"` // RSA modulus
+	E       string `json:"e"` // RSA exponent
+	Curve   string `json:"crv"`
+	X       string `json:"x"` // EC point
+	Y       string `json:"y"`
+}
+
+// publicKeys returns the signature keys of the set by key ID. Keys of other
+// types or uses are skipped.
+func (s *jsonWebKeySet) publicKeys() (map[string]crypto.PublicKey, error) {
+	keys := make(map[string]crypto.PublicKey, len(s.Keys))
+	for _, jwk := range s.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var err error
+		switch jwk.KeyType {
+		case "RSA":
+			key, err = jwk.rsaPublicKey()
+		case "EC":
+			key, err = jwk.ecPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.KeyID, err)
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys in key set")
+	}
+	return keys, nil
+}
+
+// rsaPublicKey decodes an RSA key
+func (k *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+
+	exponent := new(big.Int).SetBytes(e).Int64()
+	if exponent < 2 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent)}, nil
+}
+
+// ecPublicKey decodes an EC key, rejecting points that are not on the curve
+func (k *jsonWebKey) ecPublicKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	var validate ecdh.Curve
+	switch k.Curve {
+	case "P-256":
+		curve, validate = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, validate = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, validate = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+	}
+
+	size := (curve.Params().BitSize + 7) / 8
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+	if errX != nil || errY != nil || len(x) != size || len(y) != size {
+		return nil, fmt.Errorf("invalid point")
+	}
+
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := validate.NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid point: %w", err)
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: curve,
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
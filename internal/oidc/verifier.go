@@ -0,0 +1,275 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// keysTTL is how long fetched signing keys are used before they are refetched
+	keysTTL = time.Hour
+	// minRefreshInterval limits refetches for tokens signed with unknown key IDs
+	minRefreshInterval = time.Minute
+	// fetchTimeout bounds discovery and JWKS requests
+	fetchTimeout = 10 * time.Second
+	// maxDocumentSize limits the size of discovery and JWKS documents
+	maxDocumentSize = 1 << 20
+	// clockSkew is tolerated when checking exp and nbf
+	clockSkew = time.Minute
+)
+
+// algorithm describes a supported JWS signature algorithm
+type algorithm struct {
+	hash crypto.Hash
+	ec   bool // ECDSA rather than RSA PKCS#1 v1.5
+}
+
+// algorithms are the accepted JWS algorithms. "none" and HMAC algorithms are
+// never accepted.
+var algorithms = map[string]algorithm{
+	"RS256": {hash: crypto.SHA256},
+	"RS384": {hash: crypto.SHA384},
+	"RS512": {hash: crypto.SHA512},
+	"ES256": {hash: crypto.SHA256, ec: true},
+	"ES384": {hash: crypto.SHA384, ec: true},
+	"ES512": {hash: crypto.SHA512, ec: true},
+}
+
+// Verifier verifies JWTs issued by an OpenID Connect provider against the
+// provider's published signing keys
+type Verifier struct {
+	issuer   string
+	audience string
+	jwksURL  string // discovered from the issuer when empty
+	client   *http.Client
+	now      func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey // by key ID
+	fetchedAt   time.Time
+	attemptedAt time.Time // last fetch, successful or not
+}
+
+// NewVerifier creates a verifier for tokens of the issuer intended for the
+// audience. Signing keys are fetched from jwksURL, or from the jwks_uri of
+// the issuer's discovery document when jwksURL is empty.
+func NewVerifier(issuer, audience, jwksURL string) *Verifier {
+	return &Verifier{
+		issuer:   issuer,
+		audience: audience,
+		jwksURL:  jwksURL,
+		client:   &http.Client{Timeout: fetchTimeout},
+		now:      time.Now,
+	}
+}
+
+// Verify checks the signature, issuer, audience and validity period of a
+// compact serialized JWT and returns its claims
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	alg, supported := algorithms[header.Algorithm]
+	if !supported {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature encoding: %w", err)
+	}
+
+	key, err := v.key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// validateClaims checks the registered claims of a verified token
+func (v *Verifier) validateClaims(claims Claims) error {
+	if issuer, _ := claims["iss"].(string); issuer != v.issuer {
+		return fmt.Errorf("unexpected issuer %q", issuer)
+	}
+	if !claims.hasAudience(v.audience) {
+		return fmt.Errorf("token is not intended for audience %q", v.audience)
+	}
+
+	now := v.now()
+	expiry, ok := claims.time("exp")
+	if !ok {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(expiry.Add(clockSkew)) {
+		return fmt.Errorf("token expired at %s", expiry.Format(time.RFC3339))
+	}
+	if notBefore, ok := claims.time("nbf"); ok && now.Before(notBefore.Add(-clockSkew)) {
+		return fmt.Errorf("token is not valid before %s", notBefore.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// verifySignature checks a JWS signature over the signing input
+func verifySignature(alg algorithm, key crypto.PublicKey, signingInput string, signature []byte) error {
+	h := alg.hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	if alg.ec {
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("signing key is not an EC key")
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("signing key is not an RSA key")
+	}
+	if err := rsa.VerifyPKCS1v15(publicKey, alg.hash, digest, signature); err != nil {
+		return fmt.Errorf("invalid token signature")
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// key returns the signing key with the key ID, refetching the provider's keys
+// when they are stale or the key ID is unknown, at most once per
+// minRefreshInterval. Tokens without a key ID are accepted when the provider
+// publishes a single key.
+func (v *Verifier) key(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	key, found := v.lookup(keyID)
+	stale := !found || now.Sub(v.fetchedAt) >= keysTTL
+	if stale && now.Sub(v.attemptedAt) >= minRefreshInterval {
+		v.attemptedAt = now
+		keys, err := v.fetchKeys(ctx)
+		if err != nil {
+			if found {
+				// Keep using the cached key while the provider is unreachable
+				return key, nil
+			}
+			return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+		}
+		v.keys = keys
+		v.fetchedAt = now
+		key, found = v.lookup(keyID)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+	return key, nil
+}
+
+// lookup returns a cached key; callers must hold mu
+func (v *Verifier) lookup(keyID string) (crypto.PublicKey, bool) {
+	if keyID == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, found := v.keys[keyID]
+	return key, found
+}
+
+// fetchKeys downloads the provider's JWKS
+func (v *Verifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	jwksURL := v.jwksURL
+	if jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.fetchJSON(ctx, strings.TrimSuffix(v.issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, fmt.Errorf("discovery: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, fmt.Errorf("discovery document has no jwks_uri")
+		}
+		jwksURL = discovery.JWKSURI
+	}
+
+	var set jsonWebKeySet
+	if err := v.fetchJSON(ctx, jwksURL, &set); err != nil {
+		return nil, err
+	}
+	return set.publicKeys()
+}
+
+// fetchJSON decodes the JSON document at a URL
+func (v *Verifier) fetchJSON(ctx context.Context, url string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: unexpected status %s", req.URL.Redacted(), resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(target); err != nil {
+		return fmt.Errorf("invalid document at %s: %w", req.URL.Redacted(), err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testAudience is the audience of test tokens
+const testAudience = "gsqlhealth"
+
+// encodeSegment encodes a JSON token segment
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to encode segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signRS256 creates an RS256 token
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims map[string]interface{}) string {
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// signES256 creates an ES256 token
+func signES256(t *testing.T, key *ecdsa.PrivateKey, header, claims map[string]interface{}) string {
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+
+	jwks := map[string]interface{}{"keys": []map[string]string{
+		{
+			"kty": "RSA", "kid": "rsa", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": "ec", "crv": "P-256",
+			"x": base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+			"y": base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+		},
+	}}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{"jwks_uri": server.URL + "/keys"})
+		case "/keys":
+			json.NewEncoder(w).Encode(jwks)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	issuer := server.URL
+
+	now := time.Unix(1700000000, 0)
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": issuer,
+			"aud": testAudience,
+			"sub": "alice",
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		for name, value := range overrides {
+			if value == nil {
+				delete(c, name)
+			} else {
+				c[name] = value
+			}
+		}
+		return c
+	}
+	rsaHeader := map[string]interface{}{"alg": "RS256", "kid": "rsa"}
+
+	valid := signRS256(t, rsaKey, rsaHeader, claims(nil))
+	parts := strings.Split(valid, ".")
+	tampered := parts[0] + "." + encodeSegment(t, claims(map[string]interface{}{"sub": "mallory"})) + "." + parts[2]
+	unsigned := encodeSegment(t, map[string]interface{}{"alg": "none"}) + "." + parts[1] + "."
+
+	tests := []struct {
+		name      string
+		token     string
+		expectErr bool
+	}{
+		{"rs256", valid, false},
+		{"es256", signES256(t, ecKey, map[string]interface{}{"alg": "ES256", "kid": "ec"}, claims(nil)), false},
+		{"audience array", signRS256(t, rsaKey, rsaHeader, claims(map[string]interface{}{"aud": []string{"other", testAudience}})), false},
+		{"within clock skew", signRS256(t, rsaKey, rsaHeader, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()})), false},
+		{"wrong audience", signRS256(t, rsaKey, rsaHeader, claims(map[string]interface{}{"aud": "other"})), true},
+		{"wrong issuer", signRS256(t, rsaKey, rsaHeader, claims(map[string]interface{}{"iss": "https://evil.example.com"})), true},
+		{"expired", signRS256(t, rsaKey, rsaHeader, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})), true},
+		{"no expiry", signRS256(t, rsaKey, rsaHeader, claims(map[string]interface{}{"exp": nil})), true},
+		{"not yet valid", signRS256(t, rsaKey, rsaHeader, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})), true},
+		{"tampered", tampered, true},
+		{"alg none", unsigned, true},
+		{"unknown key", signRS256(t, rsaKey, map[string]interface{}{"alg": "RS256", "kid": "rotated"}, claims(nil)), true},
+		{"key type mismatch", signRS256(t, rsaKey, map[string]interface{}{"alg": "RS256", "kid": "ec"}, claims(nil)), true},
+		{"malformed", "not-a-token", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier(issuer, testAudience, "")
+			verifier.now = func() time.Time { return now }
+
+			claims, err := verifier.Verify(context.Background(), tt.token)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("Verify() error = %v; expected error %v", err, tt.expectErr)
+			}
+			if err == nil && claims.Subject() != "alice" {
+				t.Errorf("Subject() = %q; expected %q", claims.Subject(), "alice")
+			}
+		})
+	}
+}
+
+func TestClaimsStrings(t *testing.T) {
+	var claims Claims
+	if err := json.Unmarshal([]byte(`{
+		"roles": ["admin", "viewer"],
+		"scope": "openid health:read",
+		"realm_access": {"roles": ["ops"]},
+		"count": 3
+	}`), &claims); err != nil {
+		t.Fatalf("Failed to decode claims: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"roles", []string{"admin", "viewer"}},
+		{"scope", []string{"openid", "health:read"}},
+		{"realm_access.roles", []string{"ops"}},
+		{"count", nil},
+		{"missing", nil},
+		{"roles.nested", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := claims.Strings(tt.name); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Strings(%q) = %v; expected %v", tt.name, got, tt.expected)
+			}
+		})
+	}
+}
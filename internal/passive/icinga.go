@@ -0,0 +1,98 @@
+package passive
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"gsqlhealth/internal/config"
+)
+
+// icingaCheckResult is the body of the process-check-result action of the
+// Icinga 2 API
+type icingaCheckResult struct {
+	ExitStatus      int      `json:"exit_status"`
+	PluginOutput    string   `json:"plugin_output"`
+	PerformanceData []string `json:"performance_data,omitempty"`
+	CheckSource     string   `json:"check_source"`
+	ExecutionStart  int64    `json:"execution_start,omitempty"`
+	ExecutionEnd    int64    `json:"execution_end,omitempty"`
+}
+
+// icingaSubmitter submits check results to the Icinga 2 API
+type icingaSubmitter struct {
+	endpoint string
+	username string
+	password string
+	client   *http.Client
+}
+
+// newIcingaSubmitter creates a submitter to the configured Icinga 2 API
+func newIcingaSubmitter(cfg config.PassiveChecks) (*icingaSubmitter, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.TLSSkipVerify}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &icingaSubmitter{
+		endpoint: cfg.GetAddress() + "/v1/actions/process-check-result",
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+	}, nil
+}
+
+// submit posts a check result to the service host!service
+func (i *icingaSubmitter) submit(ctx context.Context, result checkResult) error {
+	body := icingaCheckResult{
+		ExitStatus:      result.state,
+		PluginOutput:    result.output,
+		PerformanceData: result.perfData,
+		CheckSource:     "gsqlhealth",
+	}
+	if !result.timestamp.IsZero() {
+		body.ExecutionStart = result.timestamp.Unix()
+		body.ExecutionEnd = result.timestamp.Unix()
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := i.endpoint + "?service=" + url.QueryEscape(result.host+"!"+result.service)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(i.username, i.password)
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("icinga returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package passive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestIcingaSubmit(t *testing.T) {
+	var (
+		service string
+		body    icingaCheckResult
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/actions/process-check-result" || username != "gsqlhealth" || password != "secret" {
+			http.Error(w, `{"error":401}`, http.StatusUnauthorized)
+			return
+		}
+		service = r.URL.Query().Get("service")
+		if service == "missing!orders" {
+			http.Error(w, `{"error":404,"status":"No objects found."}`, http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"results":[{"code":200,"status":"Successfully processed check result"}]}`))
+	}))
+	defer api.Close()
+
+	submitter, err := newIcingaSubmitter(config.PassiveChecks{Protocol: config.PassiveProtocolIcinga2, Address: api.URL + "/", Username: "gsqlhealth", Password: "secret"})
+	if err != nil {
+		t.Fatalf("newIcingaSubmitter() error = %v", err)
+	}
+	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	result := checkResult{
+		host:      "Reports DB",
+		service:   "orders",
+		state:     stateWarning,
+		output:    "WARNING - orders is degraded",
+		perfData:  []string{"query_time=0.012000s"},
+		timestamp: timestamp,
+	}
+
+	if err := submitter.submit(context.Background(), result); err != nil {
+		t.Fatalf("submit() error = %v", err)
+	}
+	if service != "Reports DB!orders" {
+		t.Errorf("service = %q; expected Reports DB!orders", service)
+	}
+	if body.ExitStatus != stateWarning || body.PluginOutput != result.output || len(body.PerformanceData) != 1 || body.ExecutionEnd != timestamp.Unix() {
+		t.Errorf("body = %+v; expected the check result", body)
+	}
+
+	result.host = "missing"
+	err = submitter.submit(context.Background(), result)
+	if err == nil || !strings.Contains(err.Error(), "No objects found") {
+		t.Errorf("submit() error = %v; expected the error of the API", err)
+	}
+}
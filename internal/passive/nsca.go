@@ -0,0 +1,117 @@
+package passive
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+// NSCA protocol (version 3, as sent by send_nsca): on connection the daemon
+// sends an initialization packet of a random IV and a timestamp; the client
+// answers with a fixed size data packet, encrypted with the IV and password.
+// Integers are big endian, and the CRC32 of the packet is computed with its
+// CRC field zeroed.
+const (
+	nscaPacketVersion = 3
+	nscaIVSize        = 128
+	nscaInitSize      = nscaIVSize + 4
+
+	nscaHostSize    = 64
+	nscaServiceSize = 128
+	nscaOutputSize  = 512
+
+	// Offsets of the fields of the data packet, laid out as the C struct
+	// with its padding
+	nscaVersionOffset   = 0
+	nscaCRCOffset       = 4
+	nscaTimestampOffset = 8
+	nscaStateOffset     = 12
+	nscaHostOffset      = 14
+	nscaServiceOffset   = nscaHostOffset + nscaHostSize
+	nscaOutputOffset    = nscaServiceOffset + nscaServiceSize
+	nscaPacketSize      = nscaOutputOffset + nscaOutputSize + 2
+)
+
+// nscaSubmitter submits check results to an NSCA daemon, a connection each
+type nscaSubmitter struct {
+	address  string
+	xor      bool
+	password []byte
+}
+
+// newNSCASubmitter creates a submitter to the configured NSCA daemon
+func newNSCASubmitter(cfg config.PassiveChecks) *nscaSubmitter {
+	return &nscaSubmitter{
+		address:  cfg.GetAddress(),
+		xor:      cfg.Encryption == config.NSCAEncryptionXOR,
+		password: []byte(cfg.Password),
+	}
+}
+
+// submit sends a check result to the daemon
+func (n *nscaSubmitter) submit(ctx context.Context, result checkResult) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	initPacket := make([]byte, nscaInitSize)
+	if _, err := io.ReadFull(conn, initPacket); err != nil {
+		return fmt.Errorf("failed to read initialization packet: %w", err)
+	}
+
+	packet := nscaPacket(result)
+	if n.xor {
+		n.encrypt(packet, initPacket[:nscaIVSize])
+	}
+	if _, err := conn.Write(packet); err != nil {
+		return fmt.Errorf("failed to send check result: %w", err)
+	}
+	return nil
+}
+
+// encrypt XORs a packet with the IV of the daemon and then the password
+func (n *nscaSubmitter) encrypt(packet, iv []byte) {
+	for i := range packet {
+		packet[i] ^= iv[i%len(iv)]
+		if len(n.password) > 0 {
+			packet[i] ^= n.password[i%len(n.password)]
+		}
+	}
+}
+
+// nscaPacket returns the data packet of a check result. Names and output are
+// truncated to their fields, keeping the terminating NUL.
+func nscaPacket(result checkResult) []byte {
+	packet := make([]byte, nscaPacketSize)
+	binary.BigEndian.PutUint16(packet[nscaVersionOffset:], nscaPacketVersion)
+	timestamp := result.timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	binary.BigEndian.PutUint32(packet[nscaTimestampOffset:], uint32(timestamp.Unix()))
+	binary.BigEndian.PutUint16(packet[nscaStateOffset:], uint16(result.state))
+
+	output := result.output
+	if len(result.perfData) > 0 {
+		output += " | " + strings.Join(result.perfData, " ")
+	}
+	copy(packet[nscaHostOffset:nscaHostOffset+nscaHostSize-1], result.host)
+	copy(packet[nscaServiceOffset:nscaServiceOffset+nscaServiceSize-1], result.service)
+	copy(packet[nscaOutputOffset:nscaOutputOffset+nscaOutputSize-1], output)
+
+	binary.BigEndian.PutUint32(packet[nscaCRCOffset:], crc32.ChecksumIEEE(packet))
+	return packet
+}
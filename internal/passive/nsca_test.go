@@ -0,0 +1,130 @@
+package passive
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+// testDaemon accepts one NSCA connection and returns the decrypted packet
+// received on it
+func testDaemon(t *testing.T, password string) (string, <-chan []byte) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	packets := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		initPacket := make([]byte, nscaInitSize)
+		for i := range initPacket[:nscaIVSize] {
+			initPacket[i] = byte(i * 7)
+		}
+		binary.BigEndian.PutUint32(initPacket[nscaIVSize:], uint32(time.Now().Unix()))
+		conn.Write(initPacket)
+
+		packet := make([]byte, nscaPacketSize)
+		if _, err := io.ReadFull(conn, packet); err != nil {
+			return
+		}
+		if password != "" {
+			n := &nscaSubmitter{password: []byte(password)}
+			n.encrypt(packet, initPacket[:nscaIVSize])
+		}
+		packets <- packet
+	}()
+	return listener.Addr().String(), packets
+}
+
+// cString returns the NUL terminated string of a field
+func cString(field []byte) string {
+	if i := bytes.IndexByte(field, 0); i >= 0 {
+		return string(field[:i])
+	}
+	return string(field)
+}
+
+func TestNSCASubmit(t *testing.T) {
+	tests := []struct {
+		name       string
+		encryption string
+		password   string
+	}{
+		{"plain", "", ""},
+		{"xor", config.NSCAEncryptionXOR, "secret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, packets := testDaemon(t, tt.password)
+			submitter := newNSCASubmitter(config.PassiveChecks{Protocol: config.PassiveProtocolNSCA, Address: address, Encryption: tt.encryption, Password: tt.password})
+			timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+			err := submitter.submit(context.Background(), checkResult{
+				host:      "app",
+				service:   "orders",
+				state:     stateCritical,
+				output:    "CRITICAL - orders is unhealthy",
+				perfData:  []string{"query_time=0.012000s"},
+				timestamp: timestamp,
+			})
+			if err != nil {
+				t.Fatalf("submit() error = %v", err)
+			}
+
+			packet := <-packets
+			crc := binary.BigEndian.Uint32(packet[nscaCRCOffset:])
+			binary.BigEndian.PutUint32(packet[nscaCRCOffset:], 0)
+			if crc != crc32.ChecksumIEEE(packet) {
+				t.Errorf("CRC = %08x; expected %08x", crc, crc32.ChecksumIEEE(packet))
+			}
+			if version := binary.BigEndian.Uint16(packet[nscaVersionOffset:]); version != nscaPacketVersion {
+				t.Errorf("version = %d; expected %d", version, nscaPacketVersion)
+			}
+			if ts := binary.BigEndian.Uint32(packet[nscaTimestampOffset:]); int64(ts) != timestamp.Unix() {
+				t.Errorf("timestamp = %d; expected %d", ts, timestamp.Unix())
+			}
+			if state := binary.BigEndian.Uint16(packet[nscaStateOffset:]); state != stateCritical {
+				t.Errorf("state = %d; expected %d", state, stateCritical)
+			}
+			if host := cString(packet[nscaHostOffset:nscaServiceOffset]); host != "app" {
+				t.Errorf("host = %q; expected app", host)
+			}
+			if service := cString(packet[nscaServiceOffset:nscaOutputOffset]); service != "orders" {
+				t.Errorf("service = %q; expected orders", service)
+			}
+			if output := cString(packet[nscaOutputOffset:]); output != "CRITICAL - orders is unhealthy | query_time=0.012000s" {
+				t.Errorf("output = %q; expected the output with performance data", output)
+			}
+		})
+	}
+}
+
+func TestNSCAPacketTruncates(t *testing.T) {
+	long := string(bytes.Repeat([]byte("x"), 1000))
+	packet := nscaPacket(checkResult{host: long, service: long, output: long})
+	if len(packet) != 720 {
+		t.Fatalf("packet size = %d; expected 720", len(packet))
+	}
+	if host := cString(packet[nscaHostOffset:nscaServiceOffset]); len(host) != nscaHostSize-1 {
+		t.Errorf("host length = %d; expected %d", len(host), nscaHostSize-1)
+	}
+	if output := cString(packet[nscaOutputOffset:]); len(output) != nscaOutputSize-1 {
+		t.Errorf("output length = %d; expected %d", len(output), nscaOutputSize-1)
+	}
+}
@@ -0,0 +1,187 @@
+// Package passive submits the results of checks to Nagios or Icinga as
+// passive service checks, through NSCA or the Icinga 2 API.
+package passive
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+// eventBuffer is the number of health events buffered while a result is
+// being submitted
+const eventBuffer = 256
+
+// Plugin return codes of Nagios and Icinga
+const (
+	stateOK       = 0
+	stateWarning  = 1
+	stateCritical = 2
+	stateUnknown  = 3
+)
+
+// stateNames are the names of plugin return codes in plugin output
+var stateNames = map[int]string{
+	stateOK:       "OK",
+	stateWarning:  "WARNING",
+	stateCritical: "CRITICAL",
+	stateUnknown:  "UNKNOWN",
+}
+
+// checkResult is a passive service check result
+type checkResult struct {
+	host      string
+	service   string
+	state     int
+	output    string
+	perfData  []string
+	timestamp time.Time
+}
+
+// submitter submits passive check results with one protocol
+type submitter interface {
+	submit(ctx context.Context, result checkResult) error
+}
+
+// Sender submits the result of every scheduled check run as a passive check
+// result. Results that fail to submit are logged and dropped; the next run of
+// the check submits its result again.
+type Sender struct {
+	cfg           config.PassiveChecks
+	healthService *health.Service
+	logger        *slog.Logger
+	submitter     submitter
+
+	unsubscribe func()
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewSender creates a sender to the configured NSCA daemon or Icinga 2 API
+func NewSender(cfg *config.Config, healthService *health.Service, logger *slog.Logger) (*Sender, error) {
+	s := &Sender{
+		cfg:           cfg.PassiveChecks,
+		healthService: healthService,
+		logger:        logger,
+	}
+
+	switch cfg.PassiveChecks.Protocol {
+	case config.PassiveProtocolNSCA:
+		s.submitter = newNSCASubmitter(cfg.PassiveChecks)
+	case config.PassiveProtocolIcinga2:
+		submitter, err := newIcingaSubmitter(cfg.PassiveChecks)
+		if err != nil {
+			return nil, err
+		}
+		s.submitter = submitter
+	default:
+		return nil, fmt.Errorf("unsupported protocol: %s", cfg.PassiveChecks.Protocol)
+	}
+	return s, nil
+}
+
+// Start submits the results of scheduled checks until Stop is called
+func (s *Sender) Start() {
+	events, unsubscribe := s.healthService.Subscribe(eventBuffer)
+	s.unsubscribe = unsubscribe
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		for {
+			select {
+			case event := <-events:
+				if event.Type == health.EventCheckCompleted {
+					s.submitEvent(event)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	s.logger.Info("Passive check submission enabled",
+		"protocol", s.cfg.Protocol,
+		"address", s.cfg.GetAddress())
+}
+
+// Stop ends the sender, waiting for the result being submitted
+func (s *Sender) Stop() {
+	if s.done == nil {
+		return
+	}
+	s.unsubscribe()
+	close(s.stop)
+	<-s.done
+}
+
+// submitEvent submits the result of a completed check
+func (s *Sender) submitEvent(event health.Event) {
+	result := s.newResult(event)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.GetTimeout())
+	defer cancel()
+	if err := s.submitter.submit(ctx, result); err != nil {
+		s.logger.Warn("Failed to submit passive check result",
+			"protocol", s.cfg.Protocol,
+			"host", result.host,
+			"service", result.service,
+			"error", err)
+	}
+}
+
+// newResult returns the passive check result of a completed check. Its state
+// follows the status the check contributes to its database, so failures of
+// warning checks are WARNING and failures of info checks OK.
+func (s *Sender) newResult(event health.Event) checkResult {
+	healthResult := &database.HealthResult{
+		Status:   event.Status,
+		Severity: s.healthService.GetTableSeverity(event.Database, event.Table),
+	}
+	state := stateFor(healthResult.AggregateStatus())
+
+	output := fmt.Sprintf("%s - %s is %s", stateNames[state], event.Table, event.Status)
+	if event.Error != "" {
+		output += ": " + event.Error
+	}
+
+	var perfData []string
+	if event.QueryTime > 0 {
+		perfData = append(perfData, fmt.Sprintf("query_time=%.6fs", event.QueryTime.Seconds()))
+	}
+
+	return checkResult{
+		host:      expand(s.cfg.GetHostName(), event.Database, event.Table),
+		service:   expand(s.cfg.GetServiceName(), event.Database, event.Table),
+		state:     state,
+		output:    output,
+		perfData:  perfData,
+		timestamp: event.Timestamp,
+	}
+}
+
+// stateFor returns the plugin return code of a status
+func stateFor(status database.Status) int {
+	switch status {
+	case database.StatusHealthy, database.StatusDisabled, database.StatusPaused, database.StatusMaintenance:
+		return stateOK
+	case database.StatusDegraded:
+		return stateWarning
+	case database.StatusUnknown:
+		return stateUnknown
+	default:
+		return stateCritical
+	}
+}
+
+// expand replaces {database} and {table} in a name template
+func expand(template, databaseName, tableName string) string {
+	return strings.NewReplacer("{database}", databaseName, "{table}", tableName).Replace(template)
+}
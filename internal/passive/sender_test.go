@@ -0,0 +1,62 @@
+package passive
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+func TestNewResult(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "app", Tables: []config.Table{
+			{Name: "orders"},
+			{Name: "reports", Severity: config.SeverityWarning},
+			{Name: "audit", Severity: config.SeverityInfo},
+		}}},
+		PassiveChecks: config.PassiveChecks{Enabled: true, Protocol: config.PassiveProtocolNSCA, Address: "nagios", HostName: "db-{database}", ServiceName: "gsqlhealth {table}"},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sender, err := NewSender(cfg, health.NewService(cfg, logger), logger)
+	if err != nil {
+		t.Fatalf("NewSender() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		event    health.Event
+		expected int
+		output   string
+	}{
+		{"healthy", health.Event{Table: "orders", Status: database.StatusHealthy}, stateOK, "OK - orders is healthy"},
+		{"maintenance", health.Event{Table: "orders", Status: database.StatusMaintenance}, stateOK, "OK - orders is maintenance"},
+		{"degraded", health.Event{Table: "orders", Status: database.StatusDegraded}, stateWarning, "WARNING - orders is degraded"},
+		{"error", health.Event{Table: "orders", Status: database.StatusError, Error: "connection refused"}, stateCritical, "CRITICAL - orders is error: connection refused"},
+		{"unknown", health.Event{Table: "orders", Status: database.StatusUnknown}, stateUnknown, "UNKNOWN - orders is unknown"},
+		{"warning severity", health.Event{Table: "reports", Status: database.StatusUnhealthy}, stateWarning, "WARNING - reports is unhealthy"},
+		{"info severity", health.Event{Table: "audit", Status: database.StatusUnhealthy}, stateOK, "OK - audit is unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.Type = health.EventCheckCompleted
+			tt.event.Database = "app"
+			result := sender.newResult(tt.event)
+			if result.state != tt.expected || result.output != tt.output {
+				t.Errorf("newResult() = %d, %q; expected %d, %q", result.state, result.output, tt.expected, tt.output)
+			}
+			if result.host != "db-app" || result.service != "gsqlhealth "+tt.event.Table {
+				t.Errorf("host, service = %q, %q; expected the expanded templates", result.host, result.service)
+			}
+		})
+	}
+
+	result := sender.newResult(health.Event{Database: "app", Table: "orders", Status: database.StatusHealthy, QueryTime: 12 * time.Millisecond})
+	if len(result.perfData) != 1 || result.perfData[0] != "query_time=0.012000s" {
+		t.Errorf("perfData = %v; expected the query time", result.perfData)
+	}
+}
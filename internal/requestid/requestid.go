@@ -0,0 +1,83 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// Header is the HTTP header carrying the request ID
+const Header = "X-Request-ID"
+
+// maxLength bounds the length of request IDs accepted from clients
+const maxLength = 128
+
+// contextKey is the context key of the request ID
+type contextKey struct{}
+
+// New generates a random request ID
+func New() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether a request ID received from a client may be propagated:
+// at most 128 letters, digits and "-", "_", ".", ":" or "/"
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a context carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID of a context, empty if there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// handler adds the request ID of the context to log records
+type handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps a log handler so that records logged with a context
+// carrying a request ID include it as "request_id"
+func NewHandler(h slog.Handler) slog.Handler {
+	return handler{h}
+}
+
+// Handle implements slog.Handler
+func (h handler) Handle(ctx context.Context, record slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return handler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h handler) WithGroup(name string) slog.Handler {
+	return handler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected bool
+	}{
+		{"4f1c2a9e0b7d4c3e", true},
+		{"dashboard:req-42/retry.1", true},
+		{"", false},
+		{strings.Repeat("a", maxLength), true},
+		{strings.Repeat("a", maxLength+1), false},
+		{"line\nbreak", false},
+		{"spaces are not allowed", false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.expected {
+			t.Errorf("Valid(%q) = %v; expected %v", tt.id, got, tt.expected)
+		}
+	}
+
+	if id := New(); !Valid(id) || len(id) != 32 {
+		t.Errorf("New() = %q; expected 32 hex characters", id)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(NewContext(context.Background(), "abc123"), "with id")
+	logger.InfoContext(context.Background(), "without id")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], "request_id=abc123") || !strings.Contains(lines[0], "component=test") {
+		t.Errorf("Expected the request ID and logger attributes in %q", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("Expected no request ID in %q", lines[1])
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/health"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+// handleSchedulerState handles requests to /admin/scheduler
+func (s *Server) handleSchedulerState(w http.ResponseWriter, r *http.Request) {
+	s.writeSchedulerState(w)
+}
+
+// handlePauseScheduler handles requests to /admin/scheduler/pause and
+// /admin/scheduler/pause/{database}
+func (s *Server) handlePauseScheduler(w http.ResponseWriter, r *http.Request) {
+	databaseName := mux.Vars(r)["database"]
+
+	if err := s.healthService.PauseScheduler(databaseName); err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, "")
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	s.writeSchedulerState(w)
+}
+
+// handleResumeScheduler handles requests to /admin/scheduler/resume and
+// /admin/scheduler/resume/{database}
+func (s *Server) handleResumeScheduler(w http.ResponseWriter, r *http.Request) {
+	databaseName := mux.Vars(r)["database"]
+
+	if err := s.healthService.ResumeScheduler(databaseName); err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, "")
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	s.writeSchedulerState(w)
+}
+
+// handlePauseCheck handles requests to /admin/checks/{database}/{table}/pause
+func (s *Server) handlePauseCheck(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseName, tableName := vars["database"], vars["table"]
+
+	if err := s.healthService.PauseCheck(databaseName, tableName); err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, tableName)
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	s.writeSchedulerState(w)
+}
+
+// handleResumeCheck handles requests to /admin/checks/{database}/{table}/resume
+func (s *Server) handleResumeCheck(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseName, tableName := vars["database"], vars["table"]
+
+	if err := s.healthService.ResumeCheck(databaseName, tableName); err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, tableName)
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	s.writeSchedulerState(w)
+}
+
+// writeSchedulerState writes the current scheduler pause state
+func (s *Server) writeSchedulerState(w http.ResponseWriter) {
+	state := s.healthService.GetSchedulerState()
+	s.writeJSONResponse(w, http.StatusOK, SchedulerResponse{
+		Paused:          state.Paused,
+		PausedDatabases: state.PausedDatabases,
+		PausedChecks:    state.PausedChecks,
+		Timestamp:       time.Now(),
+	})
+}
+
+// handleAddDatabase handles requests to POST /admin/databases. The body is a
+// database definition as in the configuration file, in YAML or JSON. Its
+// checks are scheduled right away and it is connected in the background.
+func (s *Server) handleAddDatabase(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDatabaseBodySize))
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body", err)
+		return
+	}
+
+	// YAML is a superset of JSON, and the configuration keys are YAML keys
+	var db config.Database
+	if err := yaml.Unmarshal(body, &db); err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body, expected a database definition", err)
+		return
+	}
+
+	added, err := s.healthService.AddDatabase(db)
+	if err != nil {
+		s.writeDatabaseAdminError(w, err, db.Name)
+		return
+	}
+
+	tables := make([]string, 0, len(added.Tables))
+	statuses := make(map[string]string, len(added.Tables))
+	for _, table := range added.Tables {
+		tables = append(tables, table.Name)
+		statuses[table.Name] = enabledStatus(added.IsTableEnabled(&table))
+	}
+
+	s.writeJSONResponse(w, http.StatusCreated, TableListResponse{
+		Database: added.Name,
+		Tables:   tables,
+		Statuses: statuses,
+		Count:    len(tables),
+	})
+}
+
+// handleRemoveDatabase handles requests to DELETE /admin/databases/{database}.
+// Only databases added through POST /admin/databases can be removed.
+func (s *Server) handleRemoveDatabase(w http.ResponseWriter, r *http.Request) {
+	databaseName := mux.Vars(r)["database"]
+
+	if err := s.healthService.RemoveDatabase(databaseName); err != nil {
+		s.writeDatabaseAdminError(w, err, databaseName)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSnapshot handles requests to GET /admin/snapshot, returning the state
+// of the service for POST /admin/restore on another instance
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	s.writeJSONResponse(w, http.StatusOK, s.healthService.Snapshot())
+}
+
+// handleRestore handles requests to POST /admin/restore. The body is a
+// snapshot from GET /admin/snapshot, typically of the instance being replaced.
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
+	var snapshot health.Snapshot
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSnapshotBodySize))
+	if err := decoder.Decode(&snapshot); err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body, expected a snapshot", err)
+		return
+	}
+	if snapshot.SavedAt.IsZero() {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body, expected a snapshot", fmt.Errorf("saved_at is required"))
+		return
+	}
+
+	restore := s.healthService.RestoreSnapshot(snapshot)
+	s.writeJSONResponse(w, http.StatusOK, RestoreResponse{
+		Restored:  restore.Restored,
+		Skipped:   restore.Skipped,
+		Timestamp: time.Now(),
+	})
+}
+
+// writeDatabaseAdminError writes the error of adding or removing a database
+func (s *Server) writeDatabaseAdminError(w http.ResponseWriter, err error, databaseName string) {
+	var healthError *health.HealthError
+	switch {
+	case errors.Is(err, health.ErrDynamicDisabled):
+		s.writeErrorResponse(w, http.StatusNotFound, "Dynamic databases are not enabled", err)
+	case errors.Is(err, health.ErrInvalidDatabase):
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid database definition", err)
+	case errors.Is(err, health.ErrDatabaseExists):
+		s.writeErrorResponse(w, http.StatusConflict, fmt.Sprintf("Database '%s' already exists", databaseName), err)
+	case errors.Is(err, health.ErrStaticDatabase):
+		s.writeErrorResponse(w, http.StatusConflict, fmt.Sprintf("Database '%s' is configured in the configuration file", databaseName), err)
+	case errors.As(err, &healthError):
+		statusCode, message := s.getErrorResponse(err, databaseName, "")
+		s.writeErrorResponse(w, statusCode, message, err)
+	default:
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to save dynamic databases", err)
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/audit"
+	"gsqlhealth/internal/requestid"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+// maxAuditBodySize bounds the request bodies recorded in the audit log;
+// larger bodies, such as snapshots, are not recorded
+const maxAuditBodySize = 16 << 10
+
+// auditActions name the actions of admin endpoints and check refreshes, by
+// method and route without the API prefix. Other requests are recorded as
+// "http.request".
+var auditActions = map[string]string{
+	"POST /admin/scheduler/pause":                  "scheduler.pause",
+	"POST /admin/scheduler/pause/{database}":       "scheduler.pause",
+	"POST /admin/scheduler/resume":                 "scheduler.resume",
+	"POST /admin/scheduler/resume/{database}":      "scheduler.resume",
+	"POST /admin/checks/{database}/{table}/pause":  "check.pause",
+	"POST /admin/checks/{database}/{table}/resume": "check.resume",
+	"POST /admin/databases":                        "database.add",
+	"DELETE /admin/databases/{database}":           "database.remove",
+	"GET /admin/snapshot":                          "snapshot.export",
+	"POST /admin/restore":                          "snapshot.restore",
+	"POST /admin/silences":                         "silence.create",
+	"DELETE /admin/silences/{id}":                  "silence.expire",
+	"POST /health/{database}/refresh":              "check.refresh",
+	"POST /health/{database}/{table}/refresh":      "check.refresh",
+}
+
+// auditKey is the context key of the audit entry of a request
+type auditKey struct{}
+
+// auditEntry collects what later middlewares learn about a request, such as
+// the authenticated client
+type auditEntry struct {
+	principal string
+}
+
+// SetAuditLog records the requests of the server in an audit log
+func (s *Server) SetAuditLog(auditLog *audit.Logger) {
+	s.auditLog = auditLog
+}
+
+// auditMiddleware records every request in the audit log, including those
+// rejected by authentication and rate limiting, with the authenticated
+// client, the parameters and the outcome
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	if s.auditLog == nil {
+		return next
+	}
+	cfg := s.config.Audit
+	exempt := make(map[string]bool)
+	for _, path := range cfg.ExemptPaths {
+		exempt[path] = true
+		exempt[apiPrefix+path] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		action := auditAction(r)
+		admin := action != "" || isAdminPath(r.URL.Path)
+		if exempt[r.URL.Path] || r.Method == http.MethodOptions || (cfg.AdminOnly && !admin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if action == "" {
+			action = "http.request"
+		}
+
+		var body interface{}
+		if admin && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			body = captureBody(r)
+		}
+
+		start := time.Now()
+		entry := &auditEntry{}
+		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), auditKey{}, entry)))
+
+		record := audit.Record{
+			Time:       start,
+			Action:     action,
+			Principal:  entry.principal,
+			Outcome:    auditOutcome(ww.statusCode),
+			RequestID:  requestid.FromContext(r.Context()),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Params:     mux.Vars(r),
+			Query:      audit.RedactQuery(r.URL.Query()),
+			Body:       body,
+			Status:     ww.statusCode,
+			Duration:   time.Since(start),
+			RemoteAddr: r.RemoteAddr,
+			UserAgent:  r.UserAgent(),
+		}
+		if err := s.auditLog.Log(record); err != nil {
+			s.logger.ErrorContext(r.Context(), "Failed to write audit record",
+				"action", action,
+				"path", r.URL.Path,
+				"error", err)
+		}
+	})
+}
+
+// setAuditPrincipal records the authenticated client in the audit entry of a
+// request, if it is audited
+func setAuditPrincipal(ctx context.Context, principal string) {
+	if entry, ok := ctx.Value(auditKey{}).(*auditEntry); ok {
+		entry.principal = principal
+	}
+}
+
+// auditAction returns the action name of a request, empty for requests that
+// are not admin actions or check refreshes
+func auditAction(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return auditActions[r.Method+" "+strings.TrimPrefix(template, apiPrefix)]
+}
+
+// auditOutcome returns the outcome of a response status code
+func auditOutcome(statusCode int) string {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden || statusCode == http.StatusTooManyRequests:
+		return audit.OutcomeDenied
+	case statusCode >= http.StatusBadRequest:
+		return audit.OutcomeFailure
+	default:
+		return audit.OutcomeSuccess
+	}
+}
+
+// captureBody returns the redacted JSON or YAML body of a request, leaving
+// the body readable by the handler. Bodies that are larger than
+// maxAuditBodySize or fail to parse are not captured.
+func captureBody(r *http.Request) interface{} {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodySize+1))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	if err != nil || len(data) == 0 || len(data) > maxAuditBodySize {
+		return nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		// POST /admin/databases also accepts YAML
+		if err := yaml.Unmarshal(data, &body); err != nil {
+			return nil
+		}
+	}
+	return audit.Redact(body)
+}
+
+// readCloser reads from a reader and closes a closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/oidc"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// principalKey is the context key of the authenticated client
+type principalKey struct{}
+
+// withPrincipal returns a context carrying the authenticated client, and
+// records the client in the audit entry of the request
+func withPrincipal(ctx context.Context, principal string) context.Context {
+	setAuditPrincipal(ctx, principal)
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// principalFromContext returns the authenticated client, empty if the request
+// was not authenticated
+func principalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalKey{}).(string)
+	return principal
+}
+
+// authenticator verifies API keys and basic auth credentials. bcrypt is slow
+// by design, so verified credentials are remembered as SHA-256 digests and
+// polling clients only pay for bcrypt once.
+type authenticator struct {
+	users   map[string][]byte // bcrypt hash per username
+	apiKeys map[string]string // key name per key
+
+	mu       sync.Mutex
+	verified map[string][sha256.Size]byte // digest of the verified password per username
+}
+
+// newAuthenticator creates an authenticator for the configured users and keys
+func newAuthenticator(auth config.Auth) *authenticator {
+	a := &authenticator{
+		users:    make(map[string][]byte, len(auth.Users)),
+		apiKeys:  make(map[string]string, len(auth.APIKeys)),
+		verified: make(map[string][sha256.Size]byte),
+	}
+	for _, user := range auth.Users {
+		a.users[user.Username] = []byte(user.PasswordHash)
+	}
+	for _, key := range auth.APIKeys {
+		a.apiKeys[key.Key] = key.Name
+	}
+	return a
+}
+
+// authenticate returns the API key ("api_key:name") or user ("user:name") a
+// request presents, and whether it is valid
+func (a *authenticator) authenticate(r *http.Request) (string, bool) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		for candidate, name := range a.apiKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+				return "api_key:" + name, true
+			}
+		}
+		return "", false
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+	return "user:" + username, a.verifyPassword(username, password)
+}
+
+// verifyPassword checks a password against the user's bcrypt hash
+func (a *authenticator) verifyPassword(username, password string) bool {
+	hash, exists := a.users[username]
+	if !exists {
+		return false
+	}
+
+	digest := sha256.Sum256([]byte(password))
+
+	a.mu.Lock()
+	verified, cached := a.verified[username]
+	a.mu.Unlock()
+	if cached && subtle.ConstantTimeCompare(digest[:], verified[:]) == 1 {
+		return true
+	}
+
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return false
+	}
+
+	a.mu.Lock()
+	a.verified[username] = digest
+	a.mu.Unlock()
+	return true
+}
+
+// authMiddleware returns a middleware that rejects requests without valid
+// credentials once users, API keys or OIDC are configured. Exempt paths and
+// CORS preflights pass through. The authenticator and verifier are shared by
+// all requests.
+func (s *Server) authMiddleware() mux.MiddlewareFunc {
+	if s.config == nil || !s.config.Server.Auth.IsEnabled() {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	auth := s.config.Server.Auth
+	authenticator := newAuthenticator(auth)
+	var verifier *oidc.Verifier
+	if auth.OIDC.IsEnabled() {
+		verifier = oidc.NewVerifier(auth.OIDC.Issuer, auth.OIDC.Audience, auth.OIDC.JWKSURL)
+	}
+	exempt := make(map[string]bool)
+	for _, path := range auth.ExemptPaths {
+		exempt[path] = true
+		exempt[apiPrefix+path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt[r.URL.Path] || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if token, found := bearerToken(r); found && verifier != nil {
+				claims, err := verifier.Verify(r.Context(), token)
+				if err != nil {
+					s.logger.WarnContext(r.Context(), "Rejected bearer token",
+						"path", r.URL.Path,
+						"remote_addr", r.RemoteAddr,
+						"error", err)
+					w.Header().Set("WWW-Authenticate", `Bearer realm="gsqlhealth", error="invalid_token"`)
+					s.writeErrorResponse(w, http.StatusUnauthorized, "Invalid bearer token", err)
+					return
+				}
+
+				if isAdminPath(r.URL.Path) && !hasAnyRole(claims.Strings(auth.OIDC.GetRolesClaim()), auth.OIDC.AdminRoles) {
+					s.logger.WarnContext(r.Context(), "Rejected request without admin role",
+						"path", r.URL.Path,
+						"subject", claims.Subject(),
+						"remote_addr", r.RemoteAddr)
+					s.writeErrorResponse(w, http.StatusForbidden, "Admin role required", nil)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), "oidc:"+claims.Subject())))
+				return
+			}
+
+			principal, ok := authenticator.authenticate(r)
+			if !ok {
+				s.logger.WarnContext(r.Context(), "Rejected unauthenticated request",
+					"path", r.URL.Path,
+					"principal", principal,
+					"remote_addr", r.RemoteAddr)
+				if len(auth.Users) > 0 || len(auth.APIKeys) > 0 {
+					w.Header().Add("WWW-Authenticate", `Basic realm="gsqlhealth", charset="UTF-8"`)
+				}
+				if verifier != nil {
+					w.Header().Add("WWW-Authenticate", `Bearer realm="gsqlhealth"`)
+				}
+				s.writeErrorResponse(w, http.StatusUnauthorized, "Authentication required", nil)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// bearerToken returns the token of an Authorization: Bearer header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// isAdminPath reports whether a path is an /admin endpoint or a profile
+func isAdminPath(path string) bool {
+	if strings.HasPrefix(path, "/debug/pprof/") {
+		return true
+	}
+	path = strings.TrimPrefix(path, apiPrefix)
+	return path == "/admin" || strings.HasPrefix(path, "/admin/")
+}
+
+// hasAnyRole reports whether roles contains one of the required roles. Any
+// roles satisfy an empty requirement.
+func hasAnyRole(roles, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	for _, role := range required {
+		if containsString(roles, role) {
+			return true
+		}
+	}
+	return false
+}
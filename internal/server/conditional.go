@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// responseETag returns a weak ETag for a response body. Callers pass the
+// response without its generation timestamp so the tag only changes with the
+// cached results. The Accept header and query select the representation and
+// are part of the tag.
+func responseETag(r *http.Request, data interface{}) string {
+	h := fnv.New64a()
+	if err := json.NewEncoder(h).Encode(data); err != nil {
+		return ""
+	}
+	io.WriteString(h, r.Header.Get("Accept"))
+	io.WriteString(h, r.URL.RawQuery)
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
+// latestResult returns the time of the most recent result, zero if there is
+// none. A result that became unknown changed when it did, not when it was
+// last checked.
+func latestResult(results ...*database.HealthResult) time.Time {
+	var latest time.Time
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if result.Timestamp.After(latest) {
+			latest = result.Timestamp
+		}
+		if result.UnknownSince != nil && result.UnknownSince.After(latest) {
+			latest = *result.UnknownSince
+		}
+	}
+	return latest
+}
+
+// notModified sets the ETag and Last-Modified headers of a cached response and
+// reports whether the client's copy is still current, in which case it has
+// written 304 Not Modified. If-None-Match takes precedence over
+// If-Modified-Since. Only successful GET and HEAD responses are conditional.
+func notModified(w http.ResponseWriter, r *http.Request, statusCode int, etag string, lastModified time.Time) bool {
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || statusCode != http.StatusOK {
+		return false
+	}
+
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	w.Header().Add("Vary", "Accept")
+
+	current := false
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		current = etag != "" && etagMatches(match, etag)
+	} else if since := r.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(since); err == nil {
+			current = !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+
+	if current {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return current
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag, using
+// weak comparison
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
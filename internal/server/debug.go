@@ -0,0 +1,59 @@
+package server
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// debugReadHeaderTimeout bounds reading request headers on the debug listener.
+// There is no write timeout, since CPU profiles and traces stream for as long
+// as requested.
+const debugReadHeaderTimeout = 10 * time.Second
+
+// registerPprofRoutes registers the net/http/pprof handlers under /debug/pprof.
+// pprof.Index serves the named profiles (goroutine, heap, allocs, block,
+// mutex, threadcreate) from the path.
+func registerPprofRoutes(router *mux.Router) {
+	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline).Methods("GET")
+	router.HandleFunc("/debug/pprof/profile", pprof.Profile).Methods("GET")
+	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol).Methods("GET", "POST")
+	router.HandleFunc("/debug/pprof/trace", pprof.Trace).Methods("GET")
+	router.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index).Methods("GET")
+}
+
+// debugOnAPIListener reports whether the profiles are served by the API
+// listener rather than a separate debug listener
+func (s *Server) debugOnAPIListener() bool {
+	return s.config != nil && s.config.Server.Debug.Enabled && s.config.Server.Debug.Port == 0
+}
+
+// startDebugServer serves the profiles and expvar on the separate debug
+// listener, if one is configured
+func (s *Server) startDebugServer() {
+	debug := s.config.Server.Debug
+	if !debug.Enabled || debug.Port == 0 {
+		return
+	}
+
+	router := mux.NewRouter()
+	router.Use(s.recoveryMiddleware)
+	registerPprofRoutes(router)
+	router.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+
+	s.debugServer = &http.Server{
+		Addr:              debug.GetAddress(),
+		Handler:           router,
+		ReadHeaderTimeout: debugReadHeaderTimeout,
+	}
+
+	s.logger.Info("Starting debug server", "address", debug.GetAddress())
+	go func(server *http.Server) {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("Debug server failed", "error", err)
+		}
+	}(s.debugServer)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gsqlhealth/internal/health"
+)
+
+const (
+	// eventBufferSize is how many events a slow /events client may lag behind
+	eventBufferSize = 64
+	// eventKeepAliveInterval is how often idle /events streams receive a comment
+	eventKeepAliveInterval = 15 * time.Second
+)
+
+// handleEvents streams health events to the client as Server-Sent Events.
+// With since, it returns the status transitions recorded since then instead.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid label selector", err)
+		return
+	}
+
+	databases := parseListParam(r, "database")
+
+	if r.URL.Query().Has("since") {
+		s.writeEventLog(w, r, databases, selector)
+		return
+	}
+
+	// Streams outlive the server write timeout
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to start event stream", err)
+		return
+	}
+
+	events, unsubscribe := s.healthService.Subscribe(eventBufferSize)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := controller.Flush(); err != nil {
+		s.logger.ErrorContext(r.Context(), "Event stream not supported by response writer", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			// Completions are only streamed over /ws; SSE carries state changes
+			if event.Type == health.EventCheckCompleted {
+				continue
+			}
+			if len(databases) > 0 && !databases[event.Database] {
+				continue
+			}
+			if !matchLabels(event.Labels, selector) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEventLog writes the status transitions recorded since the since
+// parameter, oldest first, of the selected databases and labels
+func (s *Server) writeEventLog(w http.ResponseWriter, r *http.Request, databases map[string]bool, selector map[string]string) {
+	now := time.Now()
+	since, err := parseSince(r.URL.Query().Get("since"), now)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid since parameter", err)
+		return
+	}
+
+	events := []health.Event{}
+	for _, event := range s.healthService.GetEvents(since) {
+		if len(databases) > 0 && !databases[event.Database] {
+			continue
+		}
+		if !matchLabels(event.Labels, selector) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	s.writeJSONResponse(w, http.StatusOK, EventLogResponse{
+		Events:    events,
+		Count:     len(events),
+		Since:     since,
+		Timestamp: now,
+	})
+}
+
+// writeEvent writes a health event in Server-Sent Events format
+func writeEvent(w http.ResponseWriter, event health.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// resultFieldNames are the JSON fields of a health result that ?fields may select
+var resultFieldNames = map[string]bool{
+	"database_name":    true,
+	"table_name":       true,
+	"status":           true,
+	"status_code":      true,
+	"labels":           true,
+	"severity":         true,
+	"data":             true,
+	"error":            true,
+	"query_time":       true,
+	"timestamp":        true,
+	"unhealthy_since":  true,
+	"downtime":         true,
+	"disconnected_at":  true,
+	"warn_latency":     true,
+	"critical_latency": true,
+	"plan":             true,
+
+	"last_run_at":          true,
+	"last_duration":        true,
+	"next_run_at":          true,
+	"consecutive_failures": true,
+	"run_count":            true,
+	"failure_count":        true,
+	"stale":                true,
+	"age":                  true,
+	"recent":               true,
+}
+
+// resultFields selects the fields of the health results in a response
+type resultFields struct {
+	fields      map[string]bool // nil keeps every field
+	includeData bool
+}
+
+// parseResultFields parses ?fields and ?include_data, returning nil when
+// neither is given. Results always keep database_name and table_name so they
+// remain identifiable.
+func parseResultFields(r *http.Request) (*resultFields, error) {
+	query := r.URL.Query()
+	if query.Get("fields") == "" && query.Get("include_data") == "" {
+		return nil, nil
+	}
+
+	f := &resultFields{includeData: true}
+	switch query.Get("include_data") {
+	case "", "true":
+	case "false":
+		f.includeData = false
+	default:
+		return nil, fmt.Errorf("include_data must be true or false")
+	}
+
+	if fields := parseListParam(r, "fields"); len(fields) > 0 {
+		var unknown []string
+		for field := range fields {
+			if !resultFieldNames[field] {
+				unknown = append(unknown, field)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("unknown fields: %s", strings.Join(unknown, ", "))
+		}
+		fields["database_name"] = true
+		fields["table_name"] = true
+		f.fields = fields
+	}
+
+	return f, nil
+}
+
+// apply returns the data with the health results reduced to the selected
+// fields. Results are found by shape, as JSON objects with database_name and
+// table_name, wherever they appear in the response.
+func (f *resultFields) apply(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are kept as written so large integers in data survive
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	f.project(generic)
+	return generic, nil
+}
+
+// project removes the unselected fields from the results within a decoded value
+func (f *resultFields) project(value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		_, hasDatabase := value["database_name"]
+		_, hasTable := value["table_name"]
+		if hasDatabase && hasTable {
+			for key := range value {
+				if (f.fields != nil && !f.fields[key]) || (key == "data" && !f.includeData) {
+					delete(value, key)
+				}
+			}
+			return
+		}
+		for _, child := range value {
+			f.project(child)
+		}
+	case []interface{}:
+		for _, child := range value {
+			f.project(child)
+		}
+	}
+}
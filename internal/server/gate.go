@@ -0,0 +1,152 @@
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+
+	"github.com/gorilla/mux"
+)
+
+// gateCondition is the condition a group must satisfy to pass a gate
+type gateCondition struct {
+	minHealthy   string        // count ("3") or percentage ("80%") of checks
+	stableFor    time.Duration // how long a check must have been healthy
+	criticalOnly bool          // only checks of severity critical
+}
+
+// handleGate handles requests to /gate/{group}
+func (s *Server) handleGate(w http.ResponseWriter, r *http.Request) {
+	groupName := mux.Vars(r)["group"]
+
+	group := s.config.GetGroup(groupName)
+	if group == nil {
+		s.writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Group '%s' not found", groupName), nil)
+		return
+	}
+
+	condition, err := parseGateCondition(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid gate condition", err)
+		return
+	}
+
+	now := time.Now()
+	checks := []GateCheck{}
+	healthy := 0
+
+	for _, dbResults := range s.healthService.GetAllCachedHealth() {
+		for _, result := range dbResults {
+			if !groupContains(group, result) || result.Status == database.StatusDisabled || result.Status == database.StatusPaused {
+				continue
+			}
+			if condition.criticalOnly && !result.IsCritical() {
+				continue
+			}
+
+			check := GateCheck{
+				Database: result.DatabaseName,
+				Table:    result.TableName,
+				Status:   result.Status,
+			}
+			if since, ok := s.healthService.HealthySince(result.DatabaseName, result.TableName); ok && result.Status == database.StatusHealthy {
+				check.HealthySince = &since
+				check.Passed = now.Sub(since) >= condition.stableFor
+			}
+			if check.Passed {
+				healthy++
+			}
+			checks = append(checks, check)
+		}
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		if checks[i].Database != checks[j].Database {
+			return checks[i].Database < checks[j].Database
+		}
+		return checks[i].Table < checks[j].Table
+	})
+
+	required, err := requiredHealthy(condition.minHealthy, len(checks))
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid gate condition", err)
+		return
+	}
+
+	response := GateResponse{
+		Group:         groupName,
+		Passed:        len(checks) > 0 && healthy >= required,
+		HealthyChecks: healthy,
+		Required:      required,
+		TotalChecks:   len(checks),
+		StableFor:     condition.stableFor.String(),
+		Checks:        checks,
+		Timestamp:     now,
+	}
+
+	statusCode := http.StatusOK
+	if !response.Passed {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	s.writeJSONResponse(w, statusCode, response)
+}
+
+// parseGateCondition reads the gate condition from the query parameters
+func parseGateCondition(r *http.Request) (gateCondition, error) {
+	query := r.URL.Query()
+	condition := gateCondition{
+		minHealthy:   query.Get("min_healthy"),
+		criticalOnly: query.Get("critical") == "true",
+	}
+
+	if condition.minHealthy == "" {
+		condition.minHealthy = "100%"
+	}
+
+	if value := query.Get("for"); value != "" {
+		stableFor, err := time.ParseDuration(value)
+		if err != nil || stableFor < 0 {
+			return condition, fmt.Errorf("for must be a non-negative duration such as 2m: %q", value)
+		}
+		condition.stableFor = stableFor
+	}
+
+	return condition, nil
+}
+
+// requiredHealthy converts min_healthy into the number of checks that must pass
+func requiredHealthy(minHealthy string, total int) (int, error) {
+	if percent, isPercent := strings.CutSuffix(minHealthy, "%"); isPercent {
+		// The percentage is exact, so 28% of 25 checks is 7 rather than
+		// 7.000000000000001 rounded up to 8
+		value, ok := new(big.Rat).SetString(percent)
+		if !ok || value.Sign() < 0 || value.Cmp(big.NewRat(100, 1)) > 0 {
+			return 0, fmt.Errorf("min_healthy percentage must be between 0%% and 100%%: %q", minHealthy)
+		}
+		value.Mul(value, big.NewRat(int64(total), 100))
+		required, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+		if remainder.Sign() > 0 {
+			required.Add(required, big.NewInt(1))
+		}
+		return int(required.Int64()), nil
+	}
+
+	count, err := strconv.Atoi(minHealthy)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("min_healthy must be a count or a percentage such as 80%%: %q", minHealthy)
+	}
+	return count, nil
+}
+
+// groupContains reports whether a result belongs to a group
+func groupContains(group *config.Group, result *database.HealthResult) bool {
+	return group.Contains(result.DatabaseName, result.Labels)
+}
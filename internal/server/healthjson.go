@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gsqlhealth/internal/database"
+)
+
+// healthJSONContentType is the media type of draft-inadarei-api-health-check
+const healthJSONContentType = "application/health+json"
+
+// Health check draft statuses
+const (
+	healthJSONPass = "pass"
+	healthJSONWarn = "warn"
+	healthJSONFail = "fail"
+)
+
+// HealthJSONResponse is /health rendered as draft-inadarei-api-health-check
+type HealthJSONResponse struct {
+	Status      string                       `json:"status"`
+	Version     string                       `json:"version"`
+	ReleaseID   string                       `json:"releaseId"`
+	Description string                       `json:"description"`
+	Output      string                       `json:"output,omitempty"`
+	Checks      map[string][]HealthJSONCheck `json:"checks"`
+}
+
+// HealthJSONCheck is a single entry of HealthJSONResponse.Checks
+type HealthJSONCheck struct {
+	ComponentID   string    `json:"componentId"`
+	ComponentType string    `json:"componentType"`
+	ObservedValue float64   `json:"observedValue"`
+	ObservedUnit  string    `json:"observedUnit"`
+	Status        string    `json:"status"`
+	Time          time.Time `json:"time"`
+	Output        string    `json:"output,omitempty"`
+}
+
+// wantsHealthJSON reports whether /health should be rendered as
+// application/health+json: for ?format=health+json, or when health+json is
+// accepted before plain JSON
+func wantsHealthJSON(r *http.Request) bool {
+	// An unescaped + in the query string decodes to a space
+	switch r.URL.Query().Get("format") {
+	case "health+json", "health json":
+		return true
+	}
+
+	return preferredMediaType(r, "application/json", healthJSONContentType) == healthJSONContentType
+}
+
+// healthJSONStatus maps a check status onto the draft's pass/warn/fail.
+// Maintenance and degraded checks are warnings, every failure is a fail.
+func healthJSONStatus(status database.Status) string {
+	switch status {
+	case database.StatusHealthy, database.StatusDisabled, database.StatusPaused:
+		return healthJSONPass
+	case database.StatusMaintenance, database.StatusDegraded:
+		return healthJSONWarn
+	default:
+		return healthJSONFail
+	}
+}
+
+// healthJSONCheck converts a health result into a draft check entry
+func healthJSONCheck(result *database.HealthResult) HealthJSONCheck {
+	return HealthJSONCheck{
+		ComponentID:   result.DatabaseName,
+		ComponentType: "datastore",
+		ObservedValue: result.QueryTime.Seconds(),
+		ObservedUnit:  "s",
+		Status:        healthJSONStatus(result.Status),
+		Time:          result.Timestamp,
+		Output:        result.Error,
+	}
+}
+
+// writeHealthJSONResponse writes an overall health response in the
+// draft-inadarei-api-health-check format, with checks keyed by
+// database:table. Disabled checks are left out.
+func (s *Server) writeHealthJSONResponse(w http.ResponseWriter, r *http.Request, response OverallHealthResponse) {
+	healthResponse := HealthJSONResponse{
+		Status:      healthJSONPass,
+		Version:     "1",
+		ReleaseID:   "1.0.0",
+		Description: "gsqlhealth database health checks",
+		Checks:      make(map[string][]HealthJSONCheck),
+	}
+
+	for _, dbResults := range response.Databases {
+		for _, result := range dbResults {
+			if result.Status == database.StatusDisabled || result.Status == database.StatusPaused {
+				continue
+			}
+			key := result.DatabaseName + ":" + result.TableName
+			healthResponse.Checks[key] = append(healthResponse.Checks[key], healthJSONCheck(result))
+		}
+	}
+
+	if response.Self != nil {
+		check := healthJSONCheck(response.Self)
+		check.ComponentType = "system"
+		key := response.Self.DatabaseName + ":" + response.Self.TableName
+		healthResponse.Checks[key] = append(healthResponse.Checks[key], check)
+	}
+
+	switch {
+	case response.Status == database.StatusDegraded:
+		healthResponse.Status = healthJSONWarn
+		healthResponse.Output = "one or more checks are degraded"
+	case response.Status != database.StatusHealthy:
+		healthResponse.Status = healthJSONFail
+		healthResponse.Output = "one or more checks are failing"
+	case response.MaintenanceChecks > 0:
+		healthResponse.Status = healthJSONWarn
+		healthResponse.Output = "one or more checks are failing during maintenance"
+	case response.Self != nil && response.Self.Status != database.StatusHealthy:
+		healthResponse.Status = healthJSONWarn
+		healthResponse.Output = "service " + string(response.Self.Status)
+	}
+
+	// The draft requires a 4xx or 5xx status code for fail
+	statusCode := http.StatusOK
+	if healthResponse.Status == healthJSONFail {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", healthJSONContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(healthResponse); err != nil {
+		s.logger.ErrorContext(r.Context(), "Failed to encode health+json response", "error", err)
+	}
+}
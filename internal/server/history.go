@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/health"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultHistoryLimit is the number of entries returned without ?limit
+const defaultHistoryLimit = 100
+
+// historyExportFlushRows is the number of exported entries written between
+// flushes, so large exports reach the client as they are written
+const historyExportFlushRows = 500
+
+// historyExportColumns is the header row of CSV history exports
+var historyExportColumns = []string{"timestamp", "status", "status_code", "query_time", "error", "plan", "instance"}
+
+// handleTableHistory handles requests to /health/{database}/{table}/history
+func (s *Server) handleTableHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseName := vars["database"]
+	tableName := vars["table"]
+
+	now := time.Now()
+	since, err := parseSince(r.URL.Query().Get("since"), now)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid since parameter", err)
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid limit parameter", fmt.Errorf("limit must be a positive integer"))
+			return
+		}
+	}
+
+	entries, err := s.healthService.GetHistory(databaseName, tableName, since, limit)
+	if err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, tableName)
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	response := HistoryResponse{
+		Database:  databaseName,
+		Table:     tableName,
+		Entries:   entries,
+		Count:     len(entries),
+		Timestamp: now,
+	}
+
+	s.writeResponse(w, r, http.StatusOK, response)
+}
+
+// handleHistoryExport handles requests to
+// /health/{database}/{table}/history/export, streaming the whole history of a
+// check since ?since, oldest first, as CSV (the default) or NDJSON
+func (s *Server) handleHistoryExport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseName := vars["database"]
+	tableName := vars["table"]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	case "ndjson":
+		contentType = "application/x-ndjson"
+	default:
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid format parameter", fmt.Errorf("format must be csv or ndjson"))
+		return
+	}
+
+	since, err := parseSince(r.URL.Query().Get("since"), time.Now())
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid since parameter", err)
+		return
+	}
+
+	entries, err := s.healthService.GetHistory(databaseName, tableName, since, -1)
+	if err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, tableName)
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", databaseName+"-"+tableName+"-history."+format))
+	w.WriteHeader(http.StatusOK)
+
+	flush := func() {}
+	if flusher, ok := w.(http.Flusher); ok {
+		flush = flusher.Flush
+	}
+	if format == "csv" {
+		err = writeHistoryCSV(w, entries, flush)
+	} else {
+		err = writeHistoryNDJSON(w, entries, flush)
+	}
+	if err != nil {
+		// The status has been sent; the client sees a truncated export
+		s.logger.ErrorContext(r.Context(), "Failed to export check history",
+			"database", databaseName,
+			"table", tableName,
+			"error", err)
+	}
+}
+
+// writeHistoryCSV writes history entries, given newest first, as CSV rows
+// oldest first under a header row. Query times are in nanoseconds, as in the
+// JSON API.
+func writeHistoryCSV(w io.Writer, entries []health.HistoryEntry, flush func()) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(historyExportColumns); err != nil {
+		return err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		row := []string{
+			entry.Timestamp.Format(time.RFC3339Nano),
+			string(entry.Status),
+			strconv.Itoa(entry.StatusCode),
+			strconv.FormatInt(int64(entry.QueryTime), 10),
+			entry.Error,
+			entry.Plan,
+			entry.Instance,
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		if (len(entries)-i)%historyExportFlushRows == 0 {
+			writer.Flush()
+			flush()
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeHistoryNDJSON writes history entries, given newest first, as one JSON
+// object per line, oldest first
+func writeHistoryNDJSON(w io.Writer, entries []health.HistoryEntry, flush func()) error {
+	encoder := json.NewEncoder(w)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := encoder.Encode(entries[i]); err != nil {
+			return err
+		}
+		if (len(entries)-i)%historyExportFlushRows == 0 {
+			flush()
+		}
+	}
+	return nil
+}
+
+// parseSince parses a lookback duration ("1h"), a number of days ("7d") or an
+// RFC 3339 timestamp. An empty value selects the whole history.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if lookback, err := parseLookback(value); err == nil {
+		if lookback < 0 {
+			return time.Time{}, fmt.Errorf("duration must not be negative")
+		}
+		return now.Add(-lookback), nil
+	}
+
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a duration or an RFC 3339 timestamp: %s", value)
+	}
+	return since, nil
+}
+
+// parseLookback parses a Go duration or a number of days ("30d")
+func parseLookback(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days: %s", value)
+		}
+		return time.Duration(count) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// systemdListenFDsStart is the first file descriptor passed by systemd socket activation
+const systemdListenFDsStart = 3
+
+var (
+	activationOnce  sync.Once
+	activationFiles []*os.File
+)
+
+// systemdActivationFiles returns the sockets passed by systemd socket
+// activation (LISTEN_PID and LISTEN_FDS), nil when the process was not socket
+// activated. The environment is read once and cleared so child processes do
+// not inherit it; the files stay open so the HTTP server can be restarted on
+// configuration reloads.
+func systemdActivationFiles() []*os.File {
+	activationOnce.Do(func() {
+		pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+		if err != nil || pid != os.Getpid() {
+			return
+		}
+		count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil || count <= 0 {
+			return
+		}
+
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+
+		for fd := systemdListenFDsStart; fd < systemdListenFDsStart+count; fd++ {
+			activationFiles = append(activationFiles, os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd)))
+		}
+	})
+	return activationFiles
+}
+
+// listen opens the listeners of the HTTP server: the sockets passed by systemd
+// when socket activated, otherwise the TCP address and the unix socket that
+// are configured
+func (s *Server) listen() ([]net.Listener, error) {
+	if files := systemdActivationFiles(); len(files) > 0 {
+		listeners := make([]net.Listener, 0, len(files))
+		for _, file := range files {
+			// FileListener duplicates the descriptor, so closing the listener
+			// on shutdown keeps the activated socket open
+			listener, err := net.FileListener(file)
+			if err != nil {
+				closeListeners(listeners)
+				return nil, fmt.Errorf("failed to use socket activation descriptor %s: %w", file.Name(), err)
+			}
+			listeners = append(listeners, listener)
+		}
+		return listeners, nil
+	}
+
+	var listeners []net.Listener
+	if s.config.Server.Port != 0 {
+		listener, err := net.Listen("tcp", s.config.Server.GetAddress())
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	if s.config.Server.Socket != "" {
+		listener, err := listenUnix(s.config.Server.Socket, s.config.Server.GetSocketMode())
+		if err != nil {
+			closeListeners(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listener configured")
+	}
+	return listeners, nil
+}
+
+// listenUnix listens on a unix socket with the given permissions. A socket
+// left behind by a previous run is removed first; sockets in use and other
+// files are not.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("socket path %s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("socket %s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set permissions of socket %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// closeListeners closes listeners opened before a later one failed
+func closeListeners(listeners []net.Listener) {
+	for _, listener := range listeners {
+		listener.Close()
+	}
+}
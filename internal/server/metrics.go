@@ -0,0 +1,267 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+// metricSample is a value of a Prometheus gauge
+type metricSample struct {
+	labels string // rendered label set, e.g. {database="app",table="orders"}
+	value  float64
+}
+
+// handleMetrics handles requests to /metrics, publishing the status of
+// every check and the result columns of checks with metrics as Prometheus
+// gauges, and the SLA reports of all checks with history.sla_metrics_window
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	body := checkMetrics(s.healthService.GetDatabases(), func(databaseName, tableName string) *database.HealthResult {
+		result, _, _ := s.healthService.GetCachedHealth(databaseName, tableName)
+		return result
+	})
+	if window := time.Duration(s.config.History.SLAMetricsWindow); window > 0 {
+		checks, err := s.healthService.GetCheckSLAs(window)
+		if err != nil {
+			s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to compute SLA metrics", err)
+			return
+		}
+		body += formatSLAMetrics(checks)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(body)); err != nil {
+		s.logger.ErrorContext(r.Context(), "Failed to write metrics response", "error", err)
+	}
+}
+
+// checkMetrics renders the status of the checks of databases, and the result
+// columns of checks with metrics, from their latest results
+func checkMetrics(databases []config.Database, latest func(databaseName, tableName string) *database.HealthResult) string {
+	gauges := make(map[string][]metricSample)
+	var results []*database.HealthResult
+	for _, dbConfig := range databases {
+		for _, table := range dbConfig.Tables {
+			result := latest(dbConfig.Name, table.Name)
+			if result == nil {
+				continue
+			}
+			results = append(results, result)
+			if len(table.Metrics) > 0 {
+				addMetricSamples(gauges, result, table.Metrics)
+			}
+		}
+	}
+	return formatStatusMetrics(results) + formatMetrics(gauges)
+}
+
+// addMetricSamples adds the mapped columns of a result to the gauges. Each row
+// of the result is a sample, labeled with its database and table, the check's
+// labels, and the row's other string columns.
+func addMetricSamples(gauges map[string][]metricSample, result *database.HealthResult, metrics map[string]string) {
+	if result.Data == nil {
+		return
+	}
+	for _, row := range resultRows(result.Data) {
+		labels := metricLabels(result, row, metrics)
+		for column, metric := range metrics {
+			if value, ok := metricValue(row[column]); ok {
+				gauges[metric] = append(gauges[metric], metricSample{labels: labels, value: value})
+			}
+		}
+	}
+}
+
+// formatMetrics renders gauges in the Prometheus text exposition format, in
+// name and label order
+func formatMetrics(gauges map[string][]metricSample) string {
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		samples := gauges[name]
+		sort.Slice(samples, func(i, j int) bool { return samples[i].labels < samples[j].labels })
+		fmt.Fprintf(&b, "# HELP %s Result column of a gsqlhealth check.\n# TYPE %s gauge\n", name, name)
+		for _, sample := range samples {
+			fmt.Fprintf(&b, "%s%s %s\n", name, sample.labels, strconv.FormatFloat(sample.value, 'g', -1, 64))
+		}
+	}
+	return b.String()
+}
+
+// formatStatusMetrics renders the status codes of the latest results of
+// checks as a Prometheus gauge, in label order
+func formatStatusMetrics(results []*database.HealthResult) string {
+	if len(results) == 0 {
+		return ""
+	}
+
+	samples := make([]metricSample, len(results))
+	for i, result := range results {
+		samples[i] = metricSample{labels: metricLabels(result, nil, nil), value: float64(result.Status.Code())}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].labels < samples[j].labels })
+
+	var b strings.Builder
+	name := "gsqlhealth_check_status"
+	fmt.Fprintf(&b, "# HELP %s Status code of the latest result of a gsqlhealth check, from 0 (healthy) to 6 (internal error).\n# TYPE %s gauge\n", name, name)
+	for _, sample := range samples {
+		fmt.Fprintf(&b, "%s%s %s\n", name, sample.labels, strconv.FormatFloat(sample.value, 'g', -1, 64))
+	}
+	return b.String()
+}
+
+// slaQuantiles are the latency quantiles of the SLA summaries
+var slaQuantiles = []struct {
+	label  string
+	report func(health.SLAReport) time.Duration
+}{
+	{"0.5", func(r health.SLAReport) time.Duration { return r.LatencyP50 }},
+	{"0.95", func(r health.SLAReport) time.Duration { return r.LatencyP95 }},
+	{"0.99", func(r health.SLAReport) time.Duration { return r.LatencyP99 }},
+}
+
+// formatSLAMetrics renders the SLA reports of checks in the Prometheus text
+// exposition format: availability, status changes, incidents and MTTR as
+// gauges, and query time as a summary
+func formatSLAMetrics(checks []health.CheckSLA) string {
+	labels := make([]string, len(checks))
+	for i, check := range checks {
+		labels[i] = metricLabels(&database.HealthResult{DatabaseName: check.Database, TableName: check.Table, Labels: check.Labels}, nil, nil)
+	}
+
+	var b strings.Builder
+	gauge := func(name, help string, value func(health.SLAReport) (float64, bool)) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for i, check := range checks {
+			if v, ok := value(check.Report); ok {
+				fmt.Fprintf(&b, "%s%s %s\n", name, labels[i], strconv.FormatFloat(v, 'g', -1, 64))
+			}
+		}
+	}
+
+	gauge("gsqlhealth_check_availability_percent", "Percent of the observed SLA metrics window a gsqlhealth check was healthy.", func(r health.SLAReport) (float64, bool) {
+		if r.Availability == nil {
+			return 0, false
+		}
+		return *r.Availability, true
+	})
+	gauge("gsqlhealth_check_status_changes", "Status changes of a gsqlhealth check in the SLA metrics window.", func(r health.SLAReport) (float64, bool) {
+		return float64(r.StatusChanges), true
+	})
+	gauge("gsqlhealth_check_incidents", "Transitions of a gsqlhealth check into a failing status in the SLA metrics window.", func(r health.SLAReport) (float64, bool) {
+		return float64(r.Incidents), true
+	})
+	gauge("gsqlhealth_check_mttr_seconds", "Mean time to recovery of a gsqlhealth check in the SLA metrics window.", func(r health.SLAReport) (float64, bool) {
+		return r.MTTR.Seconds(), true
+	})
+
+	name := "gsqlhealth_check_query_time_seconds"
+	fmt.Fprintf(&b, "# HELP %s Query time of a gsqlhealth check in the SLA metrics window.\n# TYPE %s summary\n", name, name)
+	for i, check := range checks {
+		if check.Report.LatencyCount == 0 {
+			continue
+		}
+		for _, quantile := range slaQuantiles {
+			quantileLabels := strings.TrimSuffix(labels[i], "}") + `,quantile="` + quantile.label + `"}`
+			fmt.Fprintf(&b, "%s%s %s\n", name, quantileLabels, strconv.FormatFloat(quantile.report(check.Report).Seconds(), 'g', -1, 64))
+		}
+		fmt.Fprintf(&b, "%s_sum%s %s\n", name, labels[i], strconv.FormatFloat(check.Report.LatencySum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_count%s %d\n", name, labels[i], check.Report.LatencyCount)
+	}
+	return b.String()
+}
+
+// resultRows returns the rows of a result's data: the rows of a multi-row
+// result, or the data itself
+func resultRows(data map[string]interface{}) []map[string]interface{} {
+	if rows, ok := data["results"].([]map[string]interface{}); ok {
+		return rows
+	}
+	return []map[string]interface{}{data}
+}
+
+// metricLabels renders the label set of the samples of a result row. Label
+// names are sanitized to the Prometheus charset; database and table win over
+// labels and columns of the same name.
+func metricLabels(result *database.HealthResult, row map[string]interface{}, metrics map[string]string) string {
+	labels := make(map[string]string)
+	for column, value := range row {
+		if text, ok := value.(string); ok && metrics[column] == "" {
+			labels[labelName(column)] = text
+		}
+	}
+	for key, value := range result.Labels {
+		labels[labelName(key)] = value
+	}
+	labels["database"] = result.DatabaseName
+	labels["table"] = result.TableName
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelValueReplacer.Replace(labels[name]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// labelValueReplacer escapes label values for the text exposition format
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelName replaces the characters Prometheus does not allow in label names
+// with underscores
+func labelName(name string) string {
+	sanitized := []byte(name)
+	for i, c := range sanitized {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || (c >= '0' && c <= '9' && i > 0)) {
+			sanitized[i] = '_'
+		}
+	}
+	return string(sanitized)
+}
+
+// metricValue converts a result column to a gauge value. Numeric strings,
+// as drivers return decimals, are parsed; booleans are 0 or 1.
+func metricValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gsqlhealth/internal/database"
+)
+
+// Nagios plugin states
+const (
+	nagiosOK       = "OK"
+	nagiosWarning  = "WARNING"
+	nagiosCritical = "CRITICAL"
+	nagiosUnknown  = "UNKNOWN"
+)
+
+// wantsPlainText reports whether /health should be rendered as Nagios plugin
+// output: for /health.txt, or when text/plain is accepted before JSON
+func wantsPlainText(r *http.Request) bool {
+	if strings.HasSuffix(r.URL.Path, ".txt") {
+		return true
+	}
+
+	return preferredMediaType(r, "application/json", "text/plain") == "text/plain"
+}
+
+// nagiosState maps an overall health response onto a Nagios plugin state.
+// Failing checks are CRITICAL, degraded checks or a degraded service itself
+// are a WARNING, and results too old to trust or no completed checks at all
+// are UNKNOWN.
+func nagiosState(response OverallHealthResponse) string {
+	switch {
+	case response.Status == database.StatusDegraded:
+		return nagiosWarning
+	case response.Status == database.StatusUnknown:
+		return nagiosUnknown
+	case response.Status != database.StatusHealthy:
+		return nagiosCritical
+	case response.TotalChecks == 0:
+		return nagiosUnknown
+	case response.Self != nil && response.Self.Status != database.StatusHealthy:
+		return nagiosWarning
+	default:
+		return nagiosOK
+	}
+}
+
+// writeNagiosResponse writes an overall health response as Nagios plugin
+// output: a status line with summary perfdata, one line per check and
+// per-check query time perfdata
+func (s *Server) writeNagiosResponse(w http.ResponseWriter, r *http.Request, response OverallHealthResponse) {
+	state := nagiosState(response)
+
+	var results []*database.HealthResult
+	for _, dbResults := range response.Databases {
+		results = append(results, dbResults...)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].DatabaseName != results[j].DatabaseName {
+			return results[i].DatabaseName < results[j].DatabaseName
+		}
+		return results[i].TableName < results[j].TableName
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "GSQLHEALTH %s - %d/%d checks healthy", state, response.HealthyChecks, response.TotalChecks)
+	if response.DegradedChecks > 0 {
+		fmt.Fprintf(&b, ", %d degraded", response.DegradedChecks)
+	}
+	if response.MaintenanceChecks > 0 {
+		fmt.Fprintf(&b, ", %d in maintenance", response.MaintenanceChecks)
+	}
+	if response.Self != nil && response.Self.Status != database.StatusHealthy {
+		fmt.Fprintf(&b, ", service %s", response.Self.Status)
+	}
+	fmt.Fprintf(&b, " | checks=%d;;;0 healthy=%d;;;0;%d maintenance=%d;;;0\n",
+		response.TotalChecks, response.HealthyChecks, response.TotalChecks, response.MaintenanceChecks)
+
+	for _, result := range results {
+		fmt.Fprintf(&b, "%s/%s: %s", result.DatabaseName, result.TableName, result.Status)
+		if result.Error != "" {
+			fmt.Fprintf(&b, " - %s", strings.ReplaceAll(result.Error, "|", "/"))
+		}
+		b.WriteString("\n")
+	}
+
+	// Perfdata after the long output continues on the following lines
+	separator := "| "
+	for _, result := range results {
+		if result.Status == database.StatusDisabled || result.Status == database.StatusPaused {
+			continue
+		}
+		fmt.Fprintf(&b, "%s'%s/%s'=%.6fs;;;0\n", separator, result.DatabaseName, result.TableName, result.QueryTime.Seconds())
+		separator = ""
+	}
+
+	statusCode := http.StatusOK
+	if state == nagiosCritical || state == nagiosUnknown {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write([]byte(b.String())); err != nil {
+		s.logger.ErrorContext(r.Context(), "Failed to write plain text response", "error", err)
+	}
+}
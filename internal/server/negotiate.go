@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"gsqlhealth/internal/database"
+)
+
+// Response media types besides JSON
+const (
+	yamlContentType      = "application/yaml"
+	plainTextContentType = "text/plain; charset=utf-8"
+)
+
+// yamlMediaTypes are the Accept values that select YAML responses
+var yamlMediaTypes = []string{"application/yaml", "application/x-yaml", "text/yaml"}
+
+// preferredMediaType returns the first of offers listed in the Accept header.
+// Entries are taken in header order, */* selects the first offer and so does
+// an Accept header that lists none of the offers.
+func preferredMediaType(r *http.Request, offers ...string) string {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if mediaType == "*/*" {
+			return offers[0]
+		}
+		for _, offer := range offers {
+			if mediaType == offer {
+				return offer
+			}
+		}
+	}
+	return offers[0]
+}
+
+// writeResponse writes a response as JSON, YAML or plain text depending on the
+// Accept header. Types without a plain text rendering are written as JSON.
+// ?fields and ?include_data reduce the health results of JSON and YAML
+// responses.
+func (s *Server) writeResponse(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	fields, err := parseResultFields(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid field selection", err)
+		return
+	}
+
+	offers := append([]string{"application/json", "text/plain"}, yamlMediaTypes...)
+	mediaType := preferredMediaType(r, offers...)
+	if fields != nil && mediaType != "text/plain" {
+		if data, err = fields.apply(data); err != nil {
+			s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode response", err)
+			return
+		}
+	}
+
+	switch mediaType {
+	case "application/json":
+		s.writeJSONResponse(w, statusCode, data)
+	case "text/plain":
+		s.writeTextResponse(w, r, statusCode, data)
+	default:
+		s.writeYAMLResponse(w, r, statusCode, data)
+	}
+}
+
+// writeYAMLResponse writes a response as YAML. The data is encoded as JSON
+// first so YAML responses have the same keys, key order and values.
+func (s *Server) writeYAMLResponse(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode response", err)
+		return
+	}
+
+	// JSON is valid YAML; decoding into a node keeps the key order
+	var document yaml.Node
+	if err := yaml.Unmarshal(encoded, &document); err != nil {
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode response", err)
+		return
+	}
+	blockStyle(&document)
+
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode response", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", yamlContentType)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(b.Bytes()); err != nil {
+		s.logger.ErrorContext(r.Context(), "Failed to write YAML response", "error", err)
+	}
+}
+
+// blockStyle clears the flow and quoting styles decoded from JSON so the
+// node is encoded as plain block YAML
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// writeTextResponse writes a response as aligned plain text tables
+func (s *Server) writeTextResponse(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	var b bytes.Buffer
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	switch response := data.(type) {
+	case DatabaseHealthResponse:
+		fmt.Fprintf(tw, "Database:\t%s\nStatus:\t%s\n\n", response.Database, response.Status)
+		writeResultsTable(tw, response.Tables)
+	case CachedTableHealthResponse:
+		writeResultsTable(tw, []*database.HealthResult{response.Result})
+		fmt.Fprintf(tw, "\nLast updated:\t%s\nFresh:\t%t\n", response.LastUpdated.Format(time.RFC3339), response.IsFresh)
+	case *database.HealthResult:
+		writeResultsTable(tw, []*database.HealthResult{response})
+	case HistoryResponse:
+		fmt.Fprintf(tw, "Database:\t%s\nTable:\t%s\n\n", response.Database, response.Table)
+		fmt.Fprintln(tw, "TIMESTAMP\tSTATUS\tQUERY TIME\tERROR")
+		for _, entry := range response.Entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Timestamp.Format(time.RFC3339), entry.Status, entry.QueryTime, entry.Error)
+		}
+	case DatabaseListResponse:
+		fmt.Fprintln(tw, "DATABASE\tSTATUS\tLABELS")
+		for _, databaseName := range response.Databases {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", databaseName, response.Statuses[databaseName], formatLabels(response.Labels[databaseName]))
+		}
+	case TableListResponse:
+		fmt.Fprintf(tw, "Database:\t%s\n\n", response.Database)
+		fmt.Fprintln(tw, "TABLE\tSTATUS")
+		for _, tableName := range response.Tables {
+			fmt.Fprintf(tw, "%s\t%s\n", tableName, response.Statuses[tableName])
+		}
+	default:
+		s.writeJSONResponse(w, statusCode, data)
+		return
+	}
+
+	if err := tw.Flush(); err != nil {
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to encode response", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", plainTextContentType)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(b.Bytes()); err != nil {
+		s.logger.ErrorContext(r.Context(), "Failed to write plain text response", "error", err)
+	}
+}
+
+// writeResultsTable writes one row per health result
+func writeResultsTable(w io.Writer, results []*database.HealthResult) {
+	fmt.Fprintln(w, "DATABASE\tTABLE\tSTATUS\tQUERY TIME\tERROR")
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.DatabaseName, result.TableName, result.Status, result.QueryTime, result.Error)
+	}
+}
+
+// formatLabels formats labels as sorted key=value pairs
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Pagination limits
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+// pagination is a requested page of a collection; collections are only
+// paginated when ?page or ?per_page is given
+type pagination struct {
+	page    int // 1-based
+	perPage int
+}
+
+// parsePagination parses ?page and ?per_page, returning nil when neither is given
+func parsePagination(r *http.Request) (*pagination, error) {
+	query := r.URL.Query()
+	if query.Get("page") == "" && query.Get("per_page") == "" {
+		return nil, nil
+	}
+
+	p := &pagination{page: 1, perPage: defaultPerPage}
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return nil, fmt.Errorf("page must be a positive integer")
+		}
+		p.page = page
+	}
+	if value := query.Get("per_page"); value != "" {
+		perPage, err := strconv.Atoi(value)
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			return nil, fmt.Errorf("per_page must be between 1 and %d", maxPerPage)
+		}
+		p.perPage = perPage
+	}
+	return p, nil
+}
+
+// bounds returns the slice bounds of the page in a collection of total items.
+// Pages past the end are empty.
+func (p *pagination) bounds(total int) (int, int) {
+	start := (p.page - 1) * p.perPage
+	if start > total {
+		start = total
+	}
+	end := start + p.perPage
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// lastPage returns the number of the last page, 1 for an empty collection
+func (p *pagination) lastPage(total int) int {
+	if total == 0 {
+		return 1
+	}
+	return (total + p.perPage - 1) / p.perPage
+}
+
+// writeHeaders sets the X-Total-Count header and a Link header with the
+// first, previous, next and last pages
+func (p *pagination) writeHeaders(w http.ResponseWriter, r *http.Request, total int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	last := p.lastPage(total)
+	links := []string{p.link(r, 1, "first")}
+	if p.page > 1 {
+		previous := p.page - 1
+		if previous > last {
+			previous = last
+		}
+		links = append(links, p.link(r, previous, "prev"))
+	}
+	if p.page < last {
+		links = append(links, p.link(r, p.page+1, "next"))
+	}
+	links = append(links, p.link(r, last, "last"))
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// link formats a Link header entry for a page of the requested URL
+func (p *pagination) link(r *http.Request, page int, rel string) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(p.perPage))
+
+	target := *r.URL
+	target.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=\"%s\"", target.RequestURI(), rel)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// PushMetrics pushes the metrics of check results to a Prometheus
+// Pushgateway: the status of every check and the result columns of checks
+// with metrics, as served by /metrics. The push replaces the metrics of the
+// grouping key of the configured job, instance and labels.
+func PushMetrics(ctx context.Context, cfg config.Pushgateway, databases []config.Database, results map[string][]*database.HealthResult) error {
+	latest := make(map[string]*database.HealthResult)
+	for databaseName, dbResults := range results {
+		for _, result := range dbResults {
+			latest[databaseName+"/"+result.TableName] = result
+		}
+	}
+	body := checkMetrics(databases, func(databaseName, tableName string) *database.HealthResult {
+		return latest[databaseName+"/"+tableName]
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.GetTimeout())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL(cfg), strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("pushgateway returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// pushURL returns the URL of the grouping key of a Pushgateway configuration:
+// the job, the instance and the further labels in name order
+func pushURL(cfg config.Pushgateway) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSuffix(cfg.URL, "/"))
+	b.WriteString("/metrics")
+	b.WriteString(groupingSegment("job", cfg.GetJob()))
+	b.WriteString(groupingSegment("instance", cfg.GetInstance()))
+
+	names := make([]string, 0, len(cfg.Labels))
+	for name := range cfg.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(groupingSegment(name, cfg.Labels[name]))
+	}
+	return b.String()
+}
+
+// groupingSegment returns the path segments of a grouping label. Values with
+// slashes, and empty values, are base64 encoded as the Pushgateway requires.
+func groupingSegment(name, value string) string {
+	if value == "" {
+		return "/" + name + "@base64/="
+	}
+	if strings.Contains(value, "/") {
+		return "/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return "/" + name + "/" + url.PathEscape(value)
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// rateLimitSweepInterval is how often idle client buckets are dropped
+const rateLimitSweepInterval = time.Minute
+
+// tokenBucket is the bucket of one client
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket rate limiter keyed by client
+type rateLimiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// newRateLimiter creates a rate limiter; a zero rate allows everything
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow takes a token from the client's bucket. When the bucket is empty it
+// returns false and how long until the next token.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now)
+		l.lastSweep = now
+	}
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// sweep drops the buckets that have refilled, which are equivalent to new
+// ones; callers must hold mu
+func (l *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// connAddressKey is the context key of the rate limit address of a unix
+// socket connection
+type connAddressKey struct{}
+
+// connContext gives each connection accepted on a unix socket its own rate
+// limit address, as their clients have no IP address to tell them apart
+func (s *Server) connContext(ctx context.Context, conn net.Conn) context.Context {
+	if conn.LocalAddr().Network() != "unix" {
+		return ctx
+	}
+	return context.WithValue(ctx, connAddressKey{}, "unix:"+strconv.FormatUint(s.unixConnections.Add(1), 10))
+}
+
+// addressKey identifies the address of a request's client for rate limiting:
+// its IP address, or its connection for clients of a unix socket
+func addressKey(r *http.Request) string {
+	if key, ok := r.Context().Value(connAddressKey{}).(string); ok {
+		return key
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// clientKey identifies the client of a request for rate limiting: the
+// authenticated principal, otherwise its address
+func clientKey(r *http.Request) string {
+	if principal := principalFromContext(r.Context()); principal != "" {
+		return principal
+	}
+	return addressKey(r)
+}
+
+// isRealtimeRequest reports whether a request queries the databases directly:
+// ?realtime=true, batch checks and refreshes
+func isRealtimeRequest(r *http.Request) bool {
+	if r.URL.Query().Get("realtime") == "true" {
+		return true
+	}
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	return r.Method == http.MethodPost && strings.HasPrefix(path, "/health/")
+}
+
+// addressRateLimitMiddleware returns a middleware that rejects addresses
+// exceeding the configured request rate with 429 Too Many Requests. It runs
+// before authentication, so failed logins are limited too.
+func (s *Server) addressRateLimitMiddleware() mux.MiddlewareFunc {
+	if s.config == nil || !s.config.Server.RateLimit.IsEnabled() {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	limits := s.config.Server.RateLimit
+	requests := newRateLimiter(limits.RequestsPerSecond, limits.GetBurst())
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowed, wait := requests.allow(addressKey(r), time.Now()); !allowed {
+				s.rejectRateLimited(w, wait, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitMiddleware returns a middleware that rejects authenticated clients
+// exceeding the configured request rate, and clients exceeding the realtime
+// rate, with 429 Too Many Requests. Realtime requests count against both the
+// overall and the realtime limit.
+func (s *Server) rateLimitMiddleware() mux.MiddlewareFunc {
+	if s.config == nil || !s.config.Server.RateLimit.IsEnabled() {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	limits := s.config.Server.RateLimit
+	requests := newRateLimiter(limits.RequestsPerSecond, limits.GetBurst())
+	realtime := newRateLimiter(limits.RealtimePerSecond, limits.GetRealtimeBurst())
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Anonymous requests were limited by address before authentication
+			key := clientKey(r)
+			now := time.Now()
+			allowed, wait := true, time.Duration(0)
+			if principalFromContext(r.Context()) != "" {
+				allowed, wait = requests.allow(key, now)
+			}
+			message := "Rate limit exceeded"
+			if allowed && isRealtimeRequest(r) {
+				allowed, wait = realtime.allow(key, now)
+				message = "Realtime rate limit exceeded, use cached results"
+			}
+
+			if !allowed {
+				s.rejectRateLimited(w, wait, message)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rejectRateLimited writes the 429 Too Many Requests response of a rate
+// limited request
+func (s *Server) rejectRateLimited(w http.ResponseWriter, wait time.Duration, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	s.writeErrorResponse(w, http.StatusTooManyRequests, message, nil)
+}
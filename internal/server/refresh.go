@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// handleRefreshTable handles requests to /health/{database}/{table}/refresh.
+// The scheduled check runs immediately and the refreshed cached result is
+// returned as by /health/{database}/{table}.
+func (s *Server) handleRefreshTable(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseName := vars["database"]
+	tableName := vars["table"]
+
+	if s.shedRealtime(w) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Server.GetRequestTimeout())
+	defer cancel()
+
+	if err := s.healthService.RefreshHealth(ctx, databaseName, tableName); err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, tableName)
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	s.handleTableHealth(w, r)
+}
+
+// handleRefreshDatabase handles requests to /health/{database}/refresh.
+// The scheduled checks of all tables run immediately and the refreshed
+// cached results are returned as by /health/{database}.
+func (s *Server) handleRefreshDatabase(w http.ResponseWriter, r *http.Request) {
+	databaseName := mux.Vars(r)["database"]
+
+	if s.shedRealtime(w) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Server.GetRequestTimeout())
+	defer cancel()
+
+	if err := s.healthService.RefreshDatabaseHealth(ctx, databaseName); err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, "")
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	s.handleDatabaseHealth(w, r)
+}
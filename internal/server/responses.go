@@ -0,0 +1,192 @@
+package server
+
+import (
+	"time"
+
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+// The response types below define the JSON schema of API version 1. Fields
+// are never removed, renamed or retyped within a version; new fields may be
+// added, so clients must ignore fields they do not know.
+
+// ErrorResponse is returned by every endpoint on failure
+type ErrorResponse struct {
+	Error     string    `json:"error"`
+	Details   string    `json:"details,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OverallHealthResponse is returned by /health
+type OverallHealthResponse struct {
+	Status              database.Status                     `json:"status"`
+	StatusCode          int                                 `json:"status_code"`
+	TotalChecks         int                                 `json:"total_checks"`
+	HealthyChecks       int                                 `json:"healthy_checks"`
+	DegradedChecks      int                                 `json:"degraded_checks"`
+	MaintenanceChecks   int                                 `json:"maintenance_checks"`
+	NonCriticalFailures int                                 `json:"non_critical_failures"` // failing warning and info checks
+	Score               *float64                            `json:"score,omitempty"`       // weighted health score, when scoring is enabled
+	Timestamp           time.Time                           `json:"timestamp"`
+	Databases           map[string][]*database.HealthResult `json:"databases"`
+	Self                *database.HealthResult              `json:"self,omitempty"`
+}
+
+// HealthSummaryResponse is returned by /health?summary=true
+type HealthSummaryResponse struct {
+	Status              database.Status `json:"status"`
+	StatusCode          int             `json:"status_code"`
+	TotalChecks         int             `json:"total_checks"`
+	HealthyChecks       int             `json:"healthy_checks"`
+	DegradedChecks      int             `json:"degraded_checks"`
+	UnhealthyChecks     int             `json:"unhealthy_checks"`
+	MaintenanceChecks   int             `json:"maintenance_checks"`
+	NonCriticalFailures int             `json:"non_critical_failures"`
+	Score               *float64        `json:"score,omitempty"`
+	Timestamp           time.Time       `json:"timestamp"`
+}
+
+// DatabaseHealthResponse is returned by /health/{database}
+type DatabaseHealthResponse struct {
+	Database   string                   `json:"database"`
+	Status     database.Status          `json:"status"`
+	StatusCode int                      `json:"status_code"`
+	Tables     []*database.HealthResult `json:"tables"`
+	Timestamp  time.Time                `json:"timestamp"`
+}
+
+// CachedTableHealthResponse is returned by /health/{database}/{table} when
+// served from the cache; realtime checks return the database.HealthResult itself
+type CachedTableHealthResponse struct {
+	Result      *database.HealthResult `json:"result"`
+	Cached      bool                   `json:"cached"`
+	LastUpdated time.Time              `json:"last_updated"`
+	IsFresh     bool                   `json:"is_fresh"`
+}
+
+// BatchHealthResponse is returned by POST /health/check
+type BatchHealthResponse struct {
+	Status              database.Status          `json:"status"`
+	StatusCode          int                      `json:"status_code"`
+	TotalChecks         int                      `json:"total_checks"`
+	HealthyChecks       int                      `json:"healthy_checks"`
+	DegradedChecks      int                      `json:"degraded_checks"`
+	NonCriticalFailures int                      `json:"non_critical_failures"`
+	Results             []*database.HealthResult `json:"results"`
+	Timestamp           time.Time                `json:"timestamp"`
+}
+
+// HistoryResponse is returned by /health/{database}/{table}/history
+type HistoryResponse struct {
+	Database  string                `json:"database"`
+	Table     string                `json:"table"`
+	Entries   []health.HistoryEntry `json:"entries"` // newest first
+	Count     int                   `json:"count"`
+	Timestamp time.Time             `json:"timestamp"`
+}
+
+// SLAResponse is returned by /sla/{database} and /sla/{database}/{table}
+type SLAResponse struct {
+	Database  string                      `json:"database"`
+	Table     string                      `json:"table,omitempty"`
+	Window    string                      `json:"window,omitempty"` // omitted for reports with from
+	From      time.Time                   `json:"from"`
+	To        time.Time                   `json:"to"`
+	SLA       health.SLAReport            `json:"sla"`
+	Tables    map[string]health.SLAReport `json:"tables,omitempty"` // per table, for /sla/{database}
+	Timestamp time.Time                   `json:"timestamp"`
+}
+
+// DatabaseListResponse is returned by /databases
+type DatabaseListResponse struct {
+	Databases []string                     `json:"databases"`
+	Statuses  map[string]string            `json:"statuses"`
+	Labels    map[string]map[string]string `json:"labels"`
+	Count     int                          `json:"count"`
+}
+
+// TableListResponse is returned by /databases/{database}/tables
+type TableListResponse struct {
+	Database string            `json:"database"`
+	Tables   []string          `json:"tables"`
+	Statuses map[string]string `json:"statuses"`
+	Count    int               `json:"count"`
+}
+
+// PingResponse is returned by /ping/{database}
+type PingResponse struct {
+	Database  string        `json:"database"`
+	Status    string        `json:"status"` // "reachable" or "unreachable"
+	Error     string        `json:"error,omitempty"`
+	PingTime  time.Duration `json:"ping_time"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// CacheStatsResponse is returned by /cache/stats
+type CacheStatsResponse struct {
+	CacheStats health.CacheStats `json:"cache_stats"`
+	Timestamp  time.Time         `json:"timestamp"`
+}
+
+// SchedulerResponse is returned by the /admin/scheduler endpoints
+type SchedulerResponse struct {
+	Paused          bool      `json:"paused"`
+	PausedDatabases []string  `json:"paused_databases"`
+	PausedChecks    []string  `json:"paused_checks"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// RestoreResponse is returned by /admin/restore
+type RestoreResponse struct {
+	Restored  int       `json:"restored"`
+	Skipped   int       `json:"skipped"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventLogResponse is returned by /events with since
+type EventLogResponse struct {
+	Events    []health.Event `json:"events"`
+	Count     int            `json:"count"`
+	Since     time.Time      `json:"since"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// SilencesResponse is returned by GET /admin/silences
+type SilencesResponse struct {
+	Silences  []health.Silence `json:"silences"`
+	Count     int              `json:"count"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// RootResponse is returned by /
+type RootResponse struct {
+	Service         string            `json:"service"`
+	Version         string            `json:"version"`
+	APIVersion      string            `json:"api_version"`
+	Endpoints       []string          `json:"endpoints"`
+	QueryParameters map[string]string `json:"query_parameters"`
+	Timestamp       time.Time         `json:"timestamp"`
+}
+
+// GateResponse is returned by /gate/{group}
+type GateResponse struct {
+	Group         string      `json:"group"`
+	Passed        bool        `json:"passed"`
+	HealthyChecks int         `json:"healthy_checks"` // checks healthy for at least StableFor
+	Required      int         `json:"required"`
+	TotalChecks   int         `json:"total_checks"`
+	StableFor     string      `json:"stable_for"`
+	Checks        []GateCheck `json:"checks"`
+	Timestamp     time.Time   `json:"timestamp"`
+}
+
+// GateCheck is the gate evaluation of a single check
+type GateCheck struct {
+	Database     string          `json:"database"`
+	Table        string          `json:"table"`
+	Status       database.Status `json:"status"`
+	HealthySince *time.Time      `json:"healthy_since,omitempty"`
+	Passed       bool            `json:"passed"`
+}
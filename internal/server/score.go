@@ -0,0 +1,71 @@
+package server
+
+import (
+	"math"
+	"net/http"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+// healthScore returns the weighted health score of the results, from 0 to
+// 100. Healthy checks count fully, degraded checks half and failing checks
+// not at all; checks in maintenance, disabled and paused checks and checks weighted 0 are
+// left out. It reports false when no check is scored.
+func (s *Server) healthScore(results map[string][]*database.HealthResult) (float64, bool) {
+	// The databases come from the health service, which guards them against
+	// databases added and removed at runtime
+	databases := s.healthService.GetDatabases()
+
+	var total, earned float64
+	for databaseName, dbResults := range results {
+		for _, result := range dbResults {
+			var credit float64
+			switch result.Status {
+			case database.StatusDisabled, database.StatusPaused, database.StatusMaintenance:
+				continue
+			case database.StatusHealthy:
+				credit = 1
+			case database.StatusDegraded:
+				credit = 0.5
+			}
+
+			weight := checkWeight(databases, databaseName, result.TableName)
+			total += weight
+			earned += weight * credit
+		}
+	}
+
+	if total == 0 {
+		return 0, false
+	}
+	return math.Round(1000*earned/total) / 10, true
+}
+
+// checkWeight returns the configured weight of a check, defaulting to 1 for
+// checks no longer configured
+func checkWeight(databases []config.Database, databaseName, tableName string) float64 {
+	for _, dbConfig := range databases {
+		if dbConfig.Name != databaseName {
+			continue
+		}
+		for _, table := range dbConfig.Tables {
+			if table.Name == tableName {
+				return table.GetWeight()
+			}
+		}
+	}
+	return 1
+}
+
+// scoreStatus returns the overall status and HTTP status code of a score
+func (s *Server) scoreStatus(score float64) (database.Status, int) {
+	switch {
+	case score < s.config.Scoring.GetUnhealthyBelow():
+		return database.StatusUnhealthy, http.StatusServiceUnavailable
+	case score < s.config.Scoring.GetDegradedBelow():
+		return database.StatusDegraded, s.degradedStatusCode()
+	default:
+		return database.StatusHealthy, http.StatusOK
+	}
+}
@@ -1,28 +1,54 @@
 package server
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"gsqlhealth/internal/audit"
 	"gsqlhealth/internal/config"
 	"gsqlhealth/internal/database"
 	"gsqlhealth/internal/health"
+	"gsqlhealth/internal/requestid"
 
 	"github.com/gorilla/mux"
 )
 
+const (
+	// apiPrefix is the path prefix of the current API version
+	apiPrefix = "/api/v1"
+	// maxBatchChecks is the largest number of checks accepted by POST /health/check
+	maxBatchChecks = 100
+	// maxBatchBodySize bounds the request body of POST /health/check
+	maxBatchBodySize = 1 << 20
+	// maxDatabaseBodySize bounds the request body of POST /admin/databases
+	maxDatabaseBodySize = 1 << 20
+	// maxSnapshotBodySize bounds the request body of POST /admin/restore
+	maxSnapshotBodySize = 64 << 20
+	// maxSilenceBodySize bounds the request body of POST /admin/silences
+	maxSilenceBodySize = 64 << 10
+)
+
 // Server represents the HTTP server
 type Server struct {
 	config        *config.Config
 	healthService *health.Service
 	logger        *slog.Logger
 	httpServer    *http.Server
+	debugServer   *http.Server  // separate pprof listener, nil when not configured
+	auditLog      *audit.Logger // nil unless auditing is enabled
+
+	unixConnections atomic.Uint64 // numbers unix socket connections for rate limiting
 }
 
 // NewServer creates a new HTTP server instance
@@ -44,19 +70,64 @@ func (s *Server) Start() error {
 		ReadTimeout:  s.config.Server.GetReadTimeout(),
 		WriteTimeout: s.config.Server.GetWriteTimeout(),
 		IdleTimeout:  s.config.Server.GetIdleTimeout(),
+		ConnContext:  s.connContext,
 	}
 
+	tlsEnabled := s.config.Server.TLS.IsEnabled()
+	if tlsEnabled {
+		tlsConfig, err := newTLSConfig(s.config.Server.TLS, s.logger)
+		if err != nil {
+			return fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		s.httpServer.TLSConfig = tlsConfig
+	}
+
+	listeners, err := s.listen()
+	if err != nil {
+		return err
+	}
+	s.startDebugServer()
+
+	addresses := make([]string, len(listeners))
+	for i, listener := range listeners {
+		addresses[i] = listener.Addr().Network() + ":" + listener.Addr().String()
+	}
 	s.logger.Info("Starting HTTP server",
-		"address", s.config.Server.GetAddress(),
+		"listeners", addresses,
+		"tls", tlsEnabled,
+		"client_certificates", s.config.Server.TLS.ClientCAFile != "",
 		"read_timeout", s.config.Server.GetReadTimeout(),
 		"write_timeout", s.config.Server.GetWriteTimeout())
 
-	return s.httpServer.ListenAndServe()
+	// Serve every listener until the first one stops; a failing listener
+	// closes the others
+	errCh := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		go func(listener net.Listener) {
+			if tlsEnabled {
+				// The certificate comes from TLSConfig.GetCertificate
+				errCh <- s.httpServer.ServeTLS(listener, "", "")
+				return
+			}
+			errCh <- s.httpServer.Serve(listener)
+		}(listener)
+	}
+
+	err = <-errCh
+	if err != http.ErrServerClosed {
+		s.httpServer.Close()
+	}
+	return err
 }
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down HTTP server")
+	if s.debugServer != nil {
+		if err := s.debugServer.Shutdown(ctx); err != nil {
+			s.logger.Warn("Error shutting down debug server", "error", err)
+		}
+	}
 	return s.httpServer.Shutdown(ctx)
 }
 
@@ -65,14 +136,48 @@ func (s *Server) setupRoutes() *mux.Router {
 	router := mux.NewRouter()
 
 	// Middleware
+	router.Use(s.requestIDMiddleware)
 	router.Use(s.loggingMiddleware)
+	router.Use(s.auditMiddleware)
 	router.Use(s.corsMiddleware)
+	router.Use(s.addressRateLimitMiddleware())
+	router.Use(s.authMiddleware())
+	router.Use(s.rateLimitMiddleware())
 	router.Use(s.recoveryMiddleware)
 
+	// Versioned API; the unprefixed routes are aliases of the current version
+	s.registerAPIRoutes(router.PathPrefix(apiPrefix).Subrouter())
+	s.registerAPIRoutes(router)
+
+	// Internal counters for expvar scrapers
+	router.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+
+	// Result columns for Prometheus scrapers
+	router.HandleFunc("/metrics", s.handleMetrics).Methods("GET")
+
+	// Profiling, unless it has its own listener
+	if s.debugOnAPIListener() {
+		registerPprofRoutes(router)
+	}
+
+	// Root endpoint
+	router.HandleFunc("/", s.handleRoot).Methods("GET")
+
+	return router
+}
+
+// registerAPIRoutes registers the API endpoints on a router
+func (s *Server) registerAPIRoutes(router *mux.Router) {
 	// Health check endpoints
-	router.HandleFunc("/health", s.handleOverallHealth).Methods("GET")
-	router.HandleFunc("/health/{database}", s.handleDatabaseHealth).Methods("GET")
-	router.HandleFunc("/health/{database}/{table}", s.handleTableHealth).Methods("GET")
+	router.HandleFunc("/health", s.handleOverallHealth).Methods("GET", "HEAD")
+	router.HandleFunc("/health.txt", s.handleOverallHealth).Methods("GET", "HEAD")
+	router.HandleFunc("/health/check", s.handleBatchHealth).Methods("POST")
+	router.HandleFunc("/health/{database}", s.handleDatabaseHealth).Methods("GET", "HEAD")
+	router.HandleFunc("/health/{database}/{table}", s.handleTableHealth).Methods("GET", "HEAD")
+	router.HandleFunc("/health/{database}/refresh", s.handleRefreshDatabase).Methods("POST")
+	router.HandleFunc("/health/{database}/{table}/refresh", s.handleRefreshTable).Methods("POST")
+	router.HandleFunc("/health/{database}/{table}/history", s.handleTableHistory).Methods("GET")
+	router.HandleFunc("/health/{database}/{table}/history/export", s.handleHistoryExport).Methods("GET")
 
 	// Info endpoints
 	router.HandleFunc("/databases", s.handleListDatabases).Methods("GET")
@@ -84,10 +189,40 @@ func (s *Server) setupRoutes() *mux.Router {
 	// Cache statistics endpoint
 	router.HandleFunc("/cache/stats", s.handleCacheStats).Methods("GET")
 
-	// Root endpoint
-	router.HandleFunc("/", s.handleRoot).Methods("GET")
+	// Availability reporting
+	router.HandleFunc("/sla/{database}", s.handleSLA).Methods("GET")
+	router.HandleFunc("/sla/{database}/{table}", s.handleSLA).Methods("GET")
 
-	return router
+	// Deployment gate endpoint
+	router.HandleFunc("/gate/{group}", s.handleGate).Methods("GET")
+
+	// Health event stream
+	router.HandleFunc("/events", s.handleEvents).Methods("GET")
+
+	// Check completion subscriptions
+	router.HandleFunc("/ws", s.handleWebSocket).Methods("GET")
+
+	// Scheduler administration
+	router.HandleFunc("/admin/scheduler", s.handleSchedulerState).Methods("GET")
+	router.HandleFunc("/admin/scheduler/pause", s.handlePauseScheduler).Methods("POST")
+	router.HandleFunc("/admin/scheduler/pause/{database}", s.handlePauseScheduler).Methods("POST")
+	router.HandleFunc("/admin/scheduler/resume", s.handleResumeScheduler).Methods("POST")
+	router.HandleFunc("/admin/scheduler/resume/{database}", s.handleResumeScheduler).Methods("POST")
+	router.HandleFunc("/admin/checks/{database}/{table}/pause", s.handlePauseCheck).Methods("POST")
+	router.HandleFunc("/admin/checks/{database}/{table}/resume", s.handleResumeCheck).Methods("POST")
+
+	// Dynamic databases
+	router.HandleFunc("/admin/databases", s.handleAddDatabase).Methods("POST")
+	router.HandleFunc("/admin/databases/{database}", s.handleRemoveDatabase).Methods("DELETE")
+
+	// State snapshots
+	router.HandleFunc("/admin/snapshot", s.handleSnapshot).Methods("GET")
+	router.HandleFunc("/admin/restore", s.handleRestore).Methods("POST")
+
+	// Notification silences
+	router.HandleFunc("/admin/silences", s.handleListSilences).Methods("GET")
+	router.HandleFunc("/admin/silences", s.handleAddSilence).Methods("POST")
+	router.HandleFunc("/admin/silences/{id}", s.handleExpireSilence).Methods("DELETE")
 }
 
 // handleOverallHealth handles requests to /health
@@ -95,12 +230,35 @@ func (s *Server) handleOverallHealth(w http.ResponseWriter, r *http.Request) {
 	// Check if we should force real-time checks
 	forceRealTime := r.URL.Query().Get("realtime") == "true"
 
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid label selector", err)
+		return
+	}
+
+	statuses, err := parseStatusFilter(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid status filter", err)
+		return
+	}
+
+	databases := parseListParam(r, "database")
+
+	page, err := parsePagination(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid pagination", err)
+		return
+	}
+
 	var results map[string][]*database.HealthResult
-	var err error
+
+	if forceRealTime && s.shedRealtime(w) {
+		return
+	}
 
 	if forceRealTime {
 		// Perform real-time health checks
-		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(r.Context(), s.config.Server.GetRequestTimeout())
 		defer cancel()
 		results, err = s.healthService.CheckAllHealth(ctx)
 		if err != nil {
@@ -112,52 +270,130 @@ func (s *Server) handleOverallHealth(w http.ResponseWriter, r *http.Request) {
 		results = s.healthService.GetAllCachedHealth()
 	}
 
-	// Calculate overall status and check for connection errors
-	overallStatus := "healthy"
-	totalChecks := 0
-	healthyChecks := 0
-	hasConnectionError := false
-	hasTimeout := false
+	// Restrict the view to checks matching the label selector
+	if len(selector) > 0 {
+		filtered := make(map[string][]*database.HealthResult)
+		for databaseName, dbResults := range results {
+			if matched := filterResults(dbResults, selector); len(matched) > 0 {
+				filtered[databaseName] = matched
+			}
+		}
+		results = filtered
+	}
 
+	// Restrict the view to the requested databases
+	if len(databases) > 0 {
+		for databaseName := range results {
+			if !databases[databaseName] {
+				delete(results, databaseName)
+			}
+		}
+	}
+
+	summary := database.NewSummary()
 	for _, dbResults := range results {
-		for _, result := range dbResults {
-			totalChecks++
-			if result.Status == "healthy" {
-				healthyChecks++
-			} else {
-				overallStatus = "unhealthy"
-
-				// Check if this is a connection error based on error message
-				if result.Error != "" {
-					if s.isConnectionErrorMessage(result.Error) {
-						hasConnectionError = true
-					} else if s.isTimeoutErrorMessage(result.Error) {
-						hasTimeout = true
-					}
-				}
+		summary.Add(dbResults...)
+	}
+	overallStatus := summary.Status
+	statusCode := s.healthStatusCode(summary)
+
+	// In scoring mode the weighted score decides the overall status instead
+	var score *float64
+	if s.config.Scoring.Enabled {
+		if value, scored := s.healthScore(results); scored {
+			score = &value
+			overallStatus, statusCode = s.scoreStatus(value)
+		}
+	}
+
+	// Summaries leave out the results, for probes that only need the status.
+	// The Nagios and health+json formats are summaries of their own.
+	if r.URL.Query().Get("summary") == "true" && !wantsPlainText(r) && !wantsHealthJSON(r) {
+		response := HealthSummaryResponse{
+			Status:              overallStatus,
+			StatusCode:          overallStatus.Code(),
+			TotalChecks:         summary.TotalChecks,
+			HealthyChecks:       summary.HealthyChecks,
+			DegradedChecks:      summary.DegradedChecks,
+			UnhealthyChecks:     summary.TotalChecks - summary.HealthyChecks - summary.DegradedChecks - summary.MaintenanceChecks,
+			MaintenanceChecks:   summary.MaintenanceChecks,
+			NonCriticalFailures: summary.NonCriticalFailures,
+			Score:               score,
+			Timestamp:           time.Now(),
+		}
+
+		if !forceRealTime {
+			tagged := response
+			tagged.Timestamp = time.Time{}
+			var allResults []*database.HealthResult
+			for _, dbResults := range results {
+				allResults = append(allResults, dbResults...)
+			}
+			if notModified(w, r, statusCode, responseETag(r, tagged), latestResult(allResults...)) {
+				return
 			}
 		}
+
+		s.writeResponse(w, r, statusCode, response)
+		return
 	}
 
-	// Return appropriate HTTP status code based on error types
-	var statusCode int
-	if hasConnectionError {
-		statusCode = http.StatusServiceUnavailable
-	} else if hasTimeout {
-		statusCode = http.StatusGatewayTimeout
-	} else {
-		statusCode = http.StatusOK
+	listed := filterStatuses(results, statuses)
+
+	// Pages hold whole databases, in name order
+	if page != nil {
+		databaseNames := make([]string, 0, len(listed))
+		for databaseName := range listed {
+			databaseNames = append(databaseNames, databaseName)
+		}
+		sort.Strings(databaseNames)
+
+		start, end := page.bounds(len(databaseNames))
+		paged := make(map[string][]*database.HealthResult, end-start)
+		for _, databaseName := range databaseNames[start:end] {
+			paged[databaseName] = listed[databaseName]
+		}
+		page.writeHeaders(w, r, len(databaseNames))
+		listed = paged
 	}
 
-	response := map[string]interface{}{
-		"status":         overallStatus,
-		"total_checks":   totalChecks,
-		"healthy_checks": healthyChecks,
-		"timestamp":      time.Now(),
-		"databases":      results,
+	response := OverallHealthResponse{
+		Status:              overallStatus,
+		StatusCode:          overallStatus.Code(),
+		TotalChecks:         summary.TotalChecks,
+		HealthyChecks:       summary.HealthyChecks,
+		DegradedChecks:      summary.DegradedChecks,
+		MaintenanceChecks:   summary.MaintenanceChecks,
+		NonCriticalFailures: summary.NonCriticalFailures,
+		Score:               score,
+		Timestamp:           time.Now(),
+		Databases:           listed,
+		// The watchdog reports on the service itself and never changes the overall status
+		Self: s.healthService.GetSelfHealth(),
 	}
 
-	s.writeJSONResponse(w, statusCode, response)
+	if wantsPlainText(r) {
+		s.writeNagiosResponse(w, r, response)
+		return
+	}
+	if wantsHealthJSON(r) {
+		s.writeHealthJSONResponse(w, r, response)
+		return
+	}
+
+	if !forceRealTime {
+		tagged := response
+		tagged.Timestamp = time.Time{}
+		var listedResults []*database.HealthResult
+		for _, dbResults := range listed {
+			listedResults = append(listedResults, dbResults...)
+		}
+		if notModified(w, r, statusCode, responseETag(r, tagged), latestResult(listedResults...)) {
+			return
+		}
+	}
+
+	s.writeResponse(w, r, statusCode, response)
 }
 
 // handleDatabaseHealth handles requests to /health/{database}
@@ -168,12 +404,27 @@ func (s *Server) handleDatabaseHealth(w http.ResponseWriter, r *http.Request) {
 	// Check if we should force real-time checks
 	forceRealTime := r.URL.Query().Get("realtime") == "true"
 
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid label selector", err)
+		return
+	}
+
+	page, err := parsePagination(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid pagination", err)
+		return
+	}
+
 	var results []*database.HealthResult
-	var err error
+
+	if forceRealTime && s.shedRealtime(w) {
+		return
+	}
 
 	if forceRealTime {
 		// Perform real-time health checks
-		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(r.Context(), s.config.Server.GetRequestTimeout())
 		defer cancel()
 		results, err = s.healthService.CheckDatabaseHealth(ctx, databaseName)
 		if err != nil {
@@ -191,44 +442,44 @@ func (s *Server) handleDatabaseHealth(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Calculate database status and check for connection errors
-	databaseStatus := "healthy"
-	hasConnectionError := false
-	hasTimeout := false
+	// Restrict the view to tables matching the label selector
+	if len(selector) > 0 {
+		results = filterResults(results, selector)
+	}
 
-	for _, result := range results {
-		if result.Status != "healthy" {
-			databaseStatus = "unhealthy"
-
-			// Check if this is a connection error based on error message
-			if result.Error != "" {
-				if s.isConnectionErrorMessage(result.Error) {
-					hasConnectionError = true
-				} else if s.isTimeoutErrorMessage(result.Error) {
-					hasTimeout = true
-				}
-			}
-		}
+	summary := database.NewSummary()
+	summary.Add(results...)
+	databaseStatus := summary.Status
+	if databaseStatus == database.StatusHealthy && !s.healthService.IsDatabaseEnabled(databaseName) {
+		databaseStatus = database.StatusDisabled
+	}
+	statusCode := s.healthStatusCode(summary)
+
+	// Pages follow table name order; the status covers all tables
+	if page != nil {
+		sort.Slice(results, func(i, j int) bool { return results[i].TableName < results[j].TableName })
+		start, end := page.bounds(len(results))
+		page.writeHeaders(w, r, len(results))
+		results = results[start:end]
 	}
 
-	// Return appropriate HTTP status code based on error types
-	var statusCode int
-	if hasConnectionError {
-		statusCode = http.StatusServiceUnavailable
-	} else if hasTimeout {
-		statusCode = http.StatusGatewayTimeout
-	} else {
-		statusCode = http.StatusOK
+	response := DatabaseHealthResponse{
+		Database:   databaseName,
+		Status:     databaseStatus,
+		StatusCode: databaseStatus.Code(),
+		Tables:     results,
+		Timestamp:  time.Now(),
 	}
 
-	response := map[string]interface{}{
-		"database":  databaseName,
-		"status":    databaseStatus,
-		"tables":    results,
-		"timestamp": time.Now(),
+	if !forceRealTime {
+		tagged := response
+		tagged.Timestamp = time.Time{}
+		if notModified(w, r, statusCode, responseETag(r, tagged), latestResult(results...)) {
+			return
+		}
 	}
 
-	s.writeJSONResponse(w, statusCode, response)
+	s.writeResponse(w, r, statusCode, response)
 }
 
 // handleTableHealth handles requests to /health/{database}/{table}
@@ -237,12 +488,16 @@ func (s *Server) handleTableHealth(w http.ResponseWriter, r *http.Request) {
 	databaseName := vars["database"]
 	tableName := vars["table"]
 
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Server.GetRequestTimeout())
 	defer cancel()
 
 	// Check if we should force real-time checks
 	forceRealTime := r.URL.Query().Get("realtime") == "true"
 
+	if forceRealTime && s.shedRealtime(w) {
+		return
+	}
+
 	if forceRealTime {
 		// Perform real-time health check
 		result, err := s.healthService.CheckHealth(ctx, databaseName, tableName)
@@ -254,11 +509,13 @@ func (s *Server) handleTableHealth(w http.ResponseWriter, r *http.Request) {
 
 		// Check if result indicates connection or timeout error
 		var statusCode int
-		if result.Status != "healthy" && result.Error != "" {
+		if result.Status != database.StatusHealthy && result.Error != "" {
 			if s.isConnectionErrorMessage(result.Error) {
 				statusCode = http.StatusServiceUnavailable
 			} else if s.isTimeoutErrorMessage(result.Error) {
 				statusCode = http.StatusGatewayTimeout
+			} else if result.Status == database.StatusDegraded {
+				statusCode = s.degradedStatusCode()
 			} else {
 				statusCode = http.StatusOK
 			}
@@ -266,7 +523,7 @@ func (s *Server) handleTableHealth(w http.ResponseWriter, r *http.Request) {
 			statusCode = http.StatusOK
 		}
 
-		s.writeJSONResponse(w, statusCode, result)
+		s.writeResponse(w, r, statusCode, result)
 	} else {
 		// Use cached result
 		result, err, updatedAt := s.healthService.GetCachedHealth(databaseName, tableName)
@@ -278,11 +535,15 @@ func (s *Server) handleTableHealth(w http.ResponseWriter, r *http.Request) {
 
 		// Check if cached result indicates connection or timeout error
 		var statusCode int
-		if result != nil && result.Status != "healthy" && result.Error != "" {
+		if result != nil && result.Status == database.StatusUnknown {
+			statusCode = s.config.Server.GetUnknownStatusCode()
+		} else if result != nil && result.Status != database.StatusHealthy && result.Error != "" {
 			if s.isConnectionErrorMessage(result.Error) {
 				statusCode = http.StatusServiceUnavailable
 			} else if s.isTimeoutErrorMessage(result.Error) {
 				statusCode = http.StatusGatewayTimeout
+			} else if result.Status == database.StatusDegraded {
+				statusCode = s.degradedStatusCode()
 			} else {
 				statusCode = http.StatusOK
 			}
@@ -291,27 +552,123 @@ func (s *Server) handleTableHealth(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Add cache metadata to response
-		response := map[string]interface{}{
-			"result":        result,
-			"cached":        true,
-			"last_updated":  updatedAt,
-			"is_fresh":      s.healthService.IsHealthResultFresh(databaseName, tableName),
+		response := CachedTableHealthResponse{
+			Result:      result,
+			Cached:      true,
+			LastUpdated: updatedAt,
+			IsFresh:     s.healthService.IsHealthResultFresh(databaseName, tableName),
+		}
+
+		if notModified(w, r, statusCode, responseETag(r, response), updatedAt) {
+			return
 		}
 
-		s.writeJSONResponse(w, statusCode, response)
+		s.writeResponse(w, r, statusCode, response)
 	}
 }
 
+// handleBatchHealth handles realtime checks of a list of tables posted to /health/check
+func (s *Server) handleBatchHealth(w http.ResponseWriter, r *http.Request) {
+	var targets []health.CheckTarget
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize)).Decode(&targets); err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body, expected a list of {database, table}", err)
+		return
+	}
+
+	if len(targets) == 0 {
+		s.writeErrorResponse(w, http.StatusBadRequest, "No checks requested", nil)
+		return
+	}
+	if len(targets) > maxBatchChecks {
+		s.writeErrorResponse(w, http.StatusBadRequest,
+			fmt.Sprintf("Too many checks requested, at most %d are allowed", maxBatchChecks), nil)
+		return
+	}
+
+	if s.shedRealtime(w) {
+		return
+	}
+
+	// One deadline covers the whole batch
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Server.GetRequestTimeout())
+	defer cancel()
+
+	results, err := s.healthService.CheckBatch(ctx, targets, s.config.Server.GetBatchConcurrency())
+	if err != nil {
+		var healthError *health.HealthError
+		if errors.As(err, &healthError) {
+			statusCode, message := s.getErrorResponse(err, healthError.Database, healthError.Table)
+			s.writeErrorResponse(w, statusCode, message, err)
+			return
+		}
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to perform health checks", err)
+		return
+	}
+
+	summary := database.NewSummary()
+	summary.Add(results...)
+	statusCode := s.healthStatusCode(summary)
+
+	response := BatchHealthResponse{
+		Status:              summary.Status,
+		StatusCode:          summary.Status.Code(),
+		TotalChecks:         len(results),
+		HealthyChecks:       summary.HealthyChecks,
+		DegradedChecks:      summary.DegradedChecks,
+		NonCriticalFailures: summary.NonCriticalFailures,
+		Results:             results,
+		Timestamp:           time.Now(),
+	}
+
+	s.writeJSONResponse(w, statusCode, response)
+}
+
 // handleListDatabases handles requests to /databases
 func (s *Server) handleListDatabases(w http.ResponseWriter, r *http.Request) {
-	databases := s.healthService.GetDatabaseNames()
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid label selector", err)
+		return
+	}
 
-	response := map[string]interface{}{
-		"databases": databases,
-		"count":     len(databases),
+	page, err := parsePagination(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid pagination", err)
+		return
 	}
 
-	s.writeJSONResponse(w, http.StatusOK, response)
+	databases := []string{}
+	for _, databaseName := range s.healthService.GetDatabaseNames() {
+		if matchLabels(s.healthService.GetDatabaseLabels(databaseName), selector) {
+			databases = append(databases, databaseName)
+		}
+	}
+
+	// Pages follow configuration order
+	if page != nil {
+		start, end := page.bounds(len(databases))
+		page.writeHeaders(w, r, len(databases))
+		databases = databases[start:end]
+	}
+
+	statuses := make(map[string]string)
+	labels := make(map[string]map[string]string)
+	for _, databaseName := range databases {
+		databaseLabels := s.healthService.GetDatabaseLabels(databaseName)
+		statuses[databaseName] = enabledStatus(s.healthService.IsDatabaseEnabled(databaseName))
+		if len(databaseLabels) > 0 {
+			labels[databaseName] = databaseLabels
+		}
+	}
+
+	response := DatabaseListResponse{
+		Databases: databases,
+		Statuses:  statuses,
+		Labels:    labels,
+		Count:     len(databases),
+	}
+
+	s.writeResponse(w, r, http.StatusOK, response)
 }
 
 // handleListTables handles requests to /databases/{database}/tables
@@ -319,19 +676,38 @@ func (s *Server) handleListTables(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	databaseName := vars["database"]
 
+	page, err := parsePagination(r)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid pagination", err)
+		return
+	}
+
 	tables, err := s.healthService.GetTableNames(databaseName)
 	if err != nil {
 		s.writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Database '%s' not found", databaseName), err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"database": databaseName,
-		"tables":   tables,
-		"count":    len(tables),
+	// Pages follow configuration order
+	if page != nil {
+		start, end := page.bounds(len(tables))
+		page.writeHeaders(w, r, len(tables))
+		tables = tables[start:end]
 	}
 
-	s.writeJSONResponse(w, http.StatusOK, response)
+	statuses := make(map[string]string, len(tables))
+	for _, tableName := range tables {
+		statuses[tableName] = enabledStatus(s.healthService.IsTableEnabled(databaseName, tableName))
+	}
+
+	response := TableListResponse{
+		Database: databaseName,
+		Tables:   tables,
+		Statuses: statuses,
+		Count:    len(tables),
+	}
+
+	s.writeResponse(w, r, http.StatusOK, response)
 }
 
 // handlePing handles requests to /ping/{database}
@@ -339,7 +715,7 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	databaseName := vars["database"]
 
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Server.GetPingTimeout())
 	defer cancel()
 
 	startTime := time.Now()
@@ -347,22 +723,22 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 	pingTime := time.Since(startTime)
 
 	if err != nil {
-		response := map[string]interface{}{
-			"database":  databaseName,
-			"status":    "unreachable",
-			"error":     err.Error(),
-			"ping_time": pingTime,
-			"timestamp": time.Now(),
+		response := PingResponse{
+			Database:  databaseName,
+			Status:    "unreachable",
+			Error:     err.Error(),
+			PingTime:  pingTime,
+			Timestamp: time.Now(),
 		}
 		s.writeJSONResponse(w, http.StatusServiceUnavailable, response)
 		return
 	}
 
-	response := map[string]interface{}{
-		"database":  databaseName,
-		"status":    "reachable",
-		"ping_time": pingTime,
-		"timestamp": time.Now(),
+	response := PingResponse{
+		Database:  databaseName,
+		Status:    "reachable",
+		PingTime:  pingTime,
+		Timestamp: time.Now(),
 	}
 
 	s.writeJSONResponse(w, http.StatusOK, response)
@@ -372,9 +748,9 @@ func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
 	stats := s.healthService.GetCacheStats()
 
-	response := map[string]interface{}{
-		"cache_stats": stats,
-		"timestamp":   time.Now(),
+	response := CacheStatsResponse{
+		CacheStats: stats,
+		Timestamp:  time.Now(),
 	}
 
 	s.writeJSONResponse(w, http.StatusOK, response)
@@ -382,27 +758,200 @@ func (s *Server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
 
 // handleRoot handles requests to /
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"service":   "gsqlhealth",
-		"version":   "1.0.0",
-		"endpoints": []string{
+	response := RootResponse{
+		Service:    "gsqlhealth",
+		Version:    "1.0.0",
+		APIVersion: "v1",
+		Endpoints: []string{
+			apiPrefix + "/health",
+			apiPrefix + "/health.txt",
+			apiPrefix + "/health/check",
+			apiPrefix + "/health/{database}",
+			apiPrefix + "/health/{database}/{table}",
+			apiPrefix + "/health/{database}/refresh",
+			apiPrefix + "/health/{database}/{table}/refresh",
+			apiPrefix + "/health/{database}/{table}/history",
+			apiPrefix + "/health/{database}/{table}/history/export",
+			apiPrefix + "/databases",
+			apiPrefix + "/databases/{database}/tables",
+			apiPrefix + "/ping/{database}",
+			apiPrefix + "/cache/stats",
+			apiPrefix + "/sla/{database}",
+			apiPrefix + "/sla/{database}/{table}",
+			apiPrefix + "/gate/{group}",
+			apiPrefix + "/events",
+			apiPrefix + "/ws",
+			apiPrefix + "/admin/scheduler",
+			apiPrefix + "/admin/scheduler/pause",
+			apiPrefix + "/admin/scheduler/pause/{database}",
+			apiPrefix + "/admin/scheduler/resume",
+			apiPrefix + "/admin/scheduler/resume/{database}",
+			apiPrefix + "/admin/checks/{database}/{table}/pause",
+			apiPrefix + "/admin/checks/{database}/{table}/resume",
+			apiPrefix + "/admin/databases",
+			apiPrefix + "/admin/databases/{database}",
+			apiPrefix + "/admin/snapshot",
+			apiPrefix + "/admin/restore",
+			apiPrefix + "/admin/silences",
+			apiPrefix + "/admin/silences/{id}",
 			"/health",
+			"/health.txt",
+			"/health/check",
 			"/health/{database}",
 			"/health/{database}/{table}",
+			"/health/{database}/refresh",
+			"/health/{database}/{table}/refresh",
+			"/health/{database}/{table}/history",
+			"/health/{database}/{table}/history/export",
 			"/databases",
 			"/databases/{database}/tables",
 			"/ping/{database}",
 			"/cache/stats",
+			"/sla/{database}",
+			"/sla/{database}/{table}",
+			"/gate/{group}",
+			"/events",
+			"/ws",
+			"/admin/scheduler",
+			"/admin/scheduler/pause",
+			"/admin/scheduler/pause/{database}",
+			"/admin/scheduler/resume",
+			"/admin/scheduler/resume/{database}",
+			"/admin/checks/{database}/{table}/pause",
+			"/admin/checks/{database}/{table}/resume",
+			"/admin/databases",
+			"/admin/databases/{database}",
+			"/admin/snapshot",
+			"/admin/restore",
+			"/admin/silences",
+			"/admin/silences/{id}",
+			"/debug/vars",
+			"/metrics",
 		},
-		"query_parameters": map[string]string{
-			"realtime": "Set to 'true' to force real-time health checks instead of using cached results",
+		QueryParameters: map[string]string{
+			"realtime":     "Set to 'true' to force real-time health checks instead of using cached results",
+			"label":        "Filter by label as key:value; repeat or comma-separate to require several labels",
+			"database":     "Restrict /health and /events to databases; repeat or comma-separate for several databases",
+			"status":       "List only /health results with these statuses; repeat or comma-separate for several statuses",
+			"page":         "Page of a collection endpoint, starting at 1",
+			"per_page":     "Items per page of a collection endpoint (default 100, max 1000)",
+			"summary":      "Set to 'true' to return only the overall status and counts from /health",
+			"fields":       "Keep only these health result fields; repeat or comma-separate for several fields",
+			"include_data": "Set to 'false' to leave the query data out of health results",
 		},
-		"timestamp": time.Now(),
+		Timestamp: time.Now(),
+	}
+	if s.debugOnAPIListener() {
+		response.Endpoints = append(response.Endpoints, "/debug/pprof/")
 	}
 
 	s.writeJSONResponse(w, http.StatusOK, response)
 }
 
+// parseLabelSelector parses the label query parameters (key:value, repeated or
+// comma-separated) into the labels a check must carry to be included
+func parseLabelSelector(r *http.Request) (map[string]string, error) {
+	selector := make(map[string]string)
+
+	for _, param := range r.URL.Query()["label"] {
+		for _, term := range strings.Split(param, ",") {
+			key, value, found := strings.Cut(strings.TrimSpace(term), ":")
+			if !found || key == "" {
+				return nil, fmt.Errorf("label selector %q must be of the form key:value", term)
+			}
+			selector[key] = value
+		}
+	}
+
+	return selector, nil
+}
+
+// parseListParam parses a repeatable, comma-separated query parameter into a set
+func parseListParam(r *http.Request, name string) map[string]bool {
+	values := make(map[string]bool)
+	for _, param := range r.URL.Query()[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values[value] = true
+			}
+		}
+	}
+	return values
+}
+
+// parseStatusFilter parses the ?status query parameter into a set of statuses
+func parseStatusFilter(r *http.Request) (map[database.Status]bool, error) {
+	statuses := make(map[database.Status]bool)
+	for value := range parseListParam(r, "status") {
+		status := database.Status(value)
+		if status.Code() < 0 {
+			return nil, fmt.Errorf("unknown status %q", value)
+		}
+		statuses[status] = true
+	}
+	return statuses, nil
+}
+
+// filterStatuses returns the results with one of the given statuses, leaving
+// out databases without any; an empty set keeps all results
+func filterStatuses(results map[string][]*database.HealthResult, statuses map[database.Status]bool) map[string][]*database.HealthResult {
+	if len(statuses) == 0 {
+		return results
+	}
+
+	filtered := make(map[string][]*database.HealthResult)
+	for databaseName, dbResults := range results {
+		for _, result := range dbResults {
+			if statuses[result.Status] {
+				filtered[databaseName] = append(filtered[databaseName], result)
+			}
+		}
+	}
+	return filtered
+}
+
+// matchLabels reports whether labels contain every key/value pair of the selector
+func matchLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// filterResults returns the results whose labels match the selector
+func filterResults(results []*database.HealthResult, selector map[string]string) []*database.HealthResult {
+	var filtered []*database.HealthResult
+	for _, result := range results {
+		if matchLabels(result.Labels, selector) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// shedRealtime rejects a realtime request with 429 while the service is under
+// memory pressure and reports whether it did so
+func (s *Server) shedRealtime(w http.ResponseWriter) bool {
+	if !s.healthService.UnderMemoryPressure() {
+		return false
+	}
+
+	w.Header().Set("Retry-After", "30")
+	s.writeErrorResponse(w, http.StatusTooManyRequests,
+		"Realtime checks are unavailable under memory pressure, use cached results", nil)
+	return true
+}
+
+// enabledStatus returns the status reported in listings for an enabled flag
+func enabledStatus(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
 // writeJSONResponse writes a JSON response
 func (s *Server) writeJSONResponse(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -413,25 +962,44 @@ func (s *Server) writeJSONResponse(w http.ResponseWriter, statusCode int, data i
 	}
 }
 
-// writeErrorResponse writes an error response
+// writeErrorResponse writes an error response. The request ID set by
+// requestIDMiddleware is logged and returned with the error.
 func (s *Server) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	requestID := w.Header().Get(requestid.Header)
 	s.logger.Error("HTTP error response",
 		"status_code", statusCode,
 		"message", message,
-		"error", err)
+		"error", err,
+		"request_id", requestID)
 
-	response := map[string]interface{}{
-		"error":     message,
-		"timestamp": time.Now(),
+	response := ErrorResponse{
+		Error:     message,
+		RequestID: requestID,
+		Timestamp: time.Now(),
 	}
 
 	if err != nil {
-		response["details"] = err.Error()
+		response.Details = err.Error()
 	}
 
 	s.writeJSONResponse(w, statusCode, response)
 }
 
+// requestIDMiddleware propagates the client's X-Request-ID, or assigns a new
+// one, to the request context and the response. Log records written with the
+// request context include it.
+func (s *Server) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
 // loggingMiddleware logs HTTP requests
 func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -442,7 +1010,7 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 
 		next.ServeHTTP(ww, r)
 
-		s.logger.Info("HTTP request",
+		s.logger.InfoContext(r.Context(), "HTTP request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", ww.statusCode,
@@ -452,12 +1020,40 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// corsMiddleware adds CORS headers
+// corsMiddleware adds CORS headers according to the configured policy and
+// answers preflight requests
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
+	cors := s.corsPolicy()
+	if !cors.IsEnabled() {
+		return next
+	}
+
+	methods := strings.Join(cors.GetAllowedMethods(), ", ")
+	headers := strings.Join(cors.GetAllowedHeaders(), ", ")
+	allowAnyOrigin := containsString(cors.GetAllowedOrigins(), "*")
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		origin := r.Header.Get("Origin")
+
+		allowed := true
+		switch {
+		case allowAnyOrigin:
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && s.corsOriginAllowed(origin):
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		default:
+			allowed = false
+		}
+
+		if allowed {
+			if cors.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Expose-Headers", "ETag, Link, X-Total-Count, X-Request-ID")
+		}
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusNoContent)
@@ -468,12 +1064,50 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// corsOriginAllowed reports whether the CORS policy allows an origin. Requests
+// without an Origin header are not cross-origin and are always allowed.
+func (s *Server) corsOriginAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+
+	cors := s.corsPolicy()
+	if !cors.IsEnabled() {
+		return false
+	}
+
+	for _, allowed := range cors.GetAllowedOrigins() {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// corsPolicy returns the configured CORS policy, the defaults without a configuration
+func (s *Server) corsPolicy() config.CORS {
+	if s.config == nil {
+		return config.CORS{}
+	}
+	return s.config.Server.CORS
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // recoveryMiddleware recovers from panics
 func (s *Server) recoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				s.logger.Error("Panic recovered",
+				s.logger.ErrorContext(r.Context(), "Panic recovered",
 					"error", err,
 					"path", r.URL.Path,
 					"method", r.Method)
@@ -498,6 +1132,56 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 	rw.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Hijack lets the WebSocket handler take over the connection
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the wrapped writer so http.ResponseController can reach it
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// healthStatusCode returns the HTTP status code of an aggregated status: 503
+// when a failing check lost its connection, 504 when one timed out, the
+// configured codes of unknown and degraded statuses, and 200 otherwise
+func (s *Server) healthStatusCode(summary *database.Summary) int {
+	hasConnectionError := false
+	hasTimeout := false
+	for _, result := range summary.Failing {
+		if result.Error == "" {
+			continue
+		}
+		if s.isConnectionErrorMessage(result.Error) {
+			hasConnectionError = true
+		} else if s.isTimeoutErrorMessage(result.Error) {
+			hasTimeout = true
+		}
+	}
+
+	switch {
+	case hasConnectionError:
+		return http.StatusServiceUnavailable
+	case hasTimeout:
+		return http.StatusGatewayTimeout
+	case summary.Status == database.StatusUnknown:
+		return s.config.Server.GetUnknownStatusCode()
+	case summary.Status == database.StatusDegraded:
+		return s.degradedStatusCode()
+	default:
+		return http.StatusOK
+	}
+}
+
+// degradedStatusCode returns the HTTP status code of responses whose worst
+// status is degraded
+func (s *Server) degradedStatusCode() int {
+	if s.config == nil {
+		return http.StatusOK
+	}
+	return s.config.Server.GetDegradedStatusCode()
+}
+
 // getErrorResponse determines the appropriate HTTP status code and message for health errors
 func (s *Server) getErrorResponse(err error, database, table string) (int, string) {
 	var healthError *health.HealthError
@@ -552,6 +1236,7 @@ func (s *Server) isConnectionErrorMessage(errorMsg string) bool {
 		"connection reset",
 		"connection timeout",
 		"connection lost",
+		"connection down",
 		"no connection",
 		"dial tcp",
 		"network unreachable",
@@ -1,7 +1,38 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	"gsqlhealth/internal/audit"
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/net/websocket"
 )
 
 func TestIsConnectionErrorMessage(t *testing.T) {
@@ -27,6 +58,11 @@ func TestIsConnectionErrorMessage(t *testing.T) {
 			errorMsg: "dial tcp 127.0.0.1:3306: connection refused",
 			expected: true,
 		},
+		{
+			name:     "skipped while connection down",
+			errorMsg: "skipped (connection down)",
+			expected: true,
+		},
 		{
 			name:     "network unreachable",
 			errorMsg: "dial tcp 192.168.1.1:5432: network unreachable",
@@ -117,4 +153,2599 @@ func TestIsTimeoutErrorMessage(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestDebugVars(t *testing.T) {
+	server := &Server{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	recorder := httptest.NewRecorder()
+	server.setupRoutes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("Failed to decode /debug/vars: %v", err)
+	}
+
+	if _, ok := vars["gsqlhealth"]; !ok {
+		t.Error("Expected gsqlhealth counters in /debug/vars")
+	}
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name:     "no selector",
+			query:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:     "single label",
+			query:    "label=team:payments",
+			expected: map[string]string{"team": "payments"},
+		},
+		{
+			name:     "repeated and comma-separated",
+			query:    "label=team:payments,env:prod&label=tier:1",
+			expected: map[string]string{"team": "payments", "env": "prod", "tier": "1"},
+		},
+		{
+			name:        "missing value separator",
+			query:       "label=team",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := parseLabelSelector(httptest.NewRequest(http.MethodGet, "/health?"+tt.query, nil))
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if len(selector) != len(tt.expected) {
+				t.Fatalf("parseLabelSelector() = %v; expected %v", selector, tt.expected)
+			}
+			for key, value := range tt.expected {
+				if selector[key] != value {
+					t.Errorf("parseLabelSelector()[%q] = %q; expected %q", key, selector[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"team": "payments", "env": "prod"}
+
+	tests := []struct {
+		name     string
+		selector map[string]string
+		expected bool
+	}{
+		{"empty selector", map[string]string{}, true},
+		{"matching label", map[string]string{"team": "payments"}, true},
+		{"all labels match", map[string]string{"team": "payments", "env": "prod"}, true},
+		{"different value", map[string]string{"team": "search"}, false},
+		{"missing key", map[string]string{"tier": "1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if matched := matchLabels(labels, tt.selector); matched != tt.expected {
+				t.Errorf("matchLabels() = %v; expected %v", matched, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBatchHealth(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}, {Name: "orders"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedLen  int
+	}{
+		{"invalid body", `{"database": "db"}`, http.StatusBadRequest, 0},
+		{"empty list", `[]`, http.StatusBadRequest, 0},
+		{"unknown table", `[{"database": "db", "table": "missing"}]`, http.StatusNotFound, 0},
+		{"not connected", `[{"database": "db", "table": "orders"}, {"database": "db", "table": "users"}]`, http.StatusServiceUnavailable, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/health/check", strings.NewReader(tt.body))
+			server.setupRoutes().ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedCode, recorder.Code, recorder.Body.String())
+			}
+			if tt.expectedLen == 0 {
+				return
+			}
+
+			var response struct {
+				Results []database.HealthResult `json:"results"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Results) != tt.expectedLen {
+				t.Fatalf("Expected %d results, got %d", tt.expectedLen, len(response.Results))
+			}
+			// Results keep the request order
+			if response.Results[0].TableName != "orders" || response.Results[1].TableName != "users" {
+				t.Errorf("Results out of order: %s, %s", response.Results[0].TableName, response.Results[1].TableName)
+			}
+		})
+	}
+}
+
+func TestBatchHealthSeverity(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{
+			{Name: "reports", Severity: "warning"},
+			{Name: "audit", Severity: "info"},
+		}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus database.Status
+	}{
+		{"warning", `[{"database": "db", "table": "reports"}, {"database": "db", "table": "audit"}]`, database.StatusDegraded},
+		{"info", `[{"database": "db", "table": "audit"}]`, database.StatusHealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/health/check", strings.NewReader(tt.body))
+			server.setupRoutes().ServeHTTP(recorder, request)
+
+			// Failures of warning and info checks never take the endpoint down
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+			}
+
+			var response BatchHealthResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Status != tt.expectedStatus || response.NonCriticalFailures != len(response.Results) {
+				t.Errorf("status = %s with %d non-critical failures; expected %s with %d", response.Status, response.NonCriticalFailures, tt.expectedStatus, len(response.Results))
+			}
+			if response.Results[0].Severity == "" {
+				t.Errorf("Expected the results to carry their severity")
+			}
+		})
+	}
+}
+
+func TestHealthStatusCode(t *testing.T) {
+	cfg := &config.Config{Server: config.Server{UnknownStatusCode: http.StatusServiceUnavailable}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	tests := []struct {
+		name           string
+		results        []*database.HealthResult
+		expectedStatus database.Status
+		expectedCode   int
+	}{
+		{"healthy", []*database.HealthResult{{Status: database.StatusHealthy}, {Status: database.StatusDisabled}}, database.StatusHealthy, http.StatusOK},
+		{"unknown", []*database.HealthResult{{Status: database.StatusHealthy}, {Status: database.StatusUnknown}}, database.StatusUnknown, http.StatusServiceUnavailable},
+		{"unknown warning check", []*database.HealthResult{{Status: database.StatusUnknown, Severity: "warning"}}, database.StatusDegraded, http.StatusOK},
+		{"unknown and failing", []*database.HealthResult{{Status: database.StatusUnhealthy, Error: "unexpected row count"}, {Status: database.StatusUnknown}}, database.StatusUnhealthy, http.StatusOK},
+		{"connection error", []*database.HealthResult{{Status: database.StatusError, Error: "dial tcp: connection refused"}}, database.StatusUnhealthy, http.StatusServiceUnavailable},
+		{"maintenance", []*database.HealthResult{{Status: database.StatusMaintenance, Error: "connection refused"}}, database.StatusHealthy, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := database.NewSummary()
+			summary.Add(tt.results...)
+			if summary.Status != tt.expectedStatus {
+				t.Errorf("Status = %s; expected %s", summary.Status, tt.expectedStatus)
+			}
+			if code := server.healthStatusCode(summary); code != tt.expectedCode {
+				t.Errorf("healthStatusCode() = %d; expected %d", code, tt.expectedCode)
+			}
+		})
+	}
+}
+
+func TestVersionedRoutes(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	for _, path := range []string{"/databases", "/databases/db/tables", "/cache/stats"} {
+		t.Run(path, func(t *testing.T) {
+			legacy := httptest.NewRecorder()
+			router.ServeHTTP(legacy, httptest.NewRequest(http.MethodGet, path, nil))
+
+			versioned := httptest.NewRecorder()
+			router.ServeHTTP(versioned, httptest.NewRequest(http.MethodGet, apiPrefix+path, nil))
+
+			if versioned.Code != http.StatusOK || legacy.Code != versioned.Code {
+				t.Fatalf("Expected status 200 on both routes, got %d (legacy) and %d (%s)", legacy.Code, versioned.Code, apiPrefix)
+			}
+
+			// Timestamps aside, the legacy route is an alias of the versioned one
+			var legacyBody, versionedBody map[string]interface{}
+			if err := json.Unmarshal(legacy.Body.Bytes(), &legacyBody); err != nil {
+				t.Fatalf("Failed to decode legacy response: %v", err)
+			}
+			if err := json.Unmarshal(versioned.Body.Bytes(), &versionedBody); err != nil {
+				t.Fatalf("Failed to decode versioned response: %v", err)
+			}
+			delete(legacyBody, "timestamp")
+			delete(versionedBody, "timestamp")
+			if !reflect.DeepEqual(legacyBody, versionedBody) {
+				t.Errorf("Responses differ: %v vs %v", legacyBody, versionedBody)
+			}
+		})
+	}
+}
+
+func TestRequiredHealthy(t *testing.T) {
+	tests := []struct {
+		minHealthy  string
+		total       int
+		expected    int
+		expectError bool
+	}{
+		{"100%", 4, 4, false},
+		{"50%", 3, 2, false},
+		{"0%", 3, 0, false},
+		{"28%", 25, 7, false},
+		{"7%", 100, 7, false},
+		{"12.5%", 8, 1, false},
+		{"33%", 3, 1, false},
+		{"NaN%", 3, 0, true},
+		{"2", 5, 2, false},
+		{"150%", 3, 0, true},
+		{"-1", 3, 0, true},
+		{"most", 3, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.minHealthy, func(t *testing.T) {
+			required, err := requiredHealthy(tt.minHealthy, tt.total)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if required != tt.expected {
+				t.Errorf("requiredHealthy(%q, %d) = %d; expected %d", tt.minHealthy, tt.total, required, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGate(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		Groups:    []config.Group{{Name: "payments", Databases: []string{"db"}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{"unknown group", "/gate/billing", http.StatusNotFound},
+		{"invalid duration", "/gate/payments?for=soon", http.StatusBadRequest},
+		{"invalid min_healthy", "/gate/payments?min_healthy=most", http.StatusBadRequest},
+		{"no healthy checks", "/gate/payments?for=2m", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.setupRoutes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedCode, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestEventsStream(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/events?database=db", nil).WithContext(ctx)
+	server.setupRoutes().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Content-Type = %q; expected %q", contentType, "text/event-stream")
+	}
+}
+
+func TestEventLog(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := NewServer(cfg, health.NewService(cfg, logger), logger).setupRoutes()
+
+	tests := []struct {
+		query        string
+		expectedCode int
+	}{
+		{"since=1h", http.StatusOK},
+		{"since=7d&database=db", http.StatusOK},
+		{"since=2024-01-01T00:00:00Z", http.StatusOK},
+		{"since=yesterday", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/events?"+tt.query, nil))
+		if recorder.Code != tt.expectedCode {
+			t.Errorf("GET /events?%s: expected status %d, got %d", tt.query, tt.expectedCode, recorder.Code)
+			continue
+		}
+		if recorder.Code != http.StatusOK {
+			continue
+		}
+
+		var response EventLogResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode event log: %v", err)
+		}
+		if response.Events == nil || response.Count != 0 || response.Since.IsZero() {
+			t.Errorf("GET /events?%s = %+v; expected an empty timeline", tt.query, response)
+		}
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	event := health.Event{Type: health.EventDisconnected, Database: "db"}
+
+	if err := writeEvent(recorder, event); err != nil {
+		t.Fatalf("writeEvent failed: %v", err)
+	}
+
+	body := recorder.Body.String()
+	if !strings.HasPrefix(body, "event: disconnected\ndata: {") || !strings.HasSuffix(body, "}\n\n") {
+		t.Errorf("Unexpected event encoding: %q", body)
+	}
+}
+
+func TestWebSocketSubscriptions(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	httpServer := httptest.NewServer(server.setupRoutes())
+	defer httpServer.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", "", httpServer.URL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name         string
+		request      wsRequest
+		expectedType string
+	}{
+		{"subscribe to table", wsRequest{Action: "subscribe", Database: "db", Table: "users"}, "subscribed"},
+		{"subscribe to everything", wsRequest{Action: "subscribe"}, "subscribed"},
+		{"unsubscribe", wsRequest{Action: "unsubscribe", Database: "db", Table: "users"}, "unsubscribed"},
+		{"unknown table", wsRequest{Action: "subscribe", Database: "db", Table: "missing"}, "error"},
+		{"unknown action", wsRequest{Action: "watch"}, "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := websocket.JSON.Send(conn, tt.request); err != nil {
+				t.Fatalf("Failed to send request: %v", err)
+			}
+
+			var reply wsMessage
+			if err := websocket.JSON.Receive(conn, &reply); err != nil {
+				t.Fatalf("Failed to receive reply: %v", err)
+			}
+			if reply.Type != tt.expectedType {
+				t.Errorf("Type = %q; expected %q (error: %s)", reply.Type, tt.expectedType, reply.Error)
+			}
+		})
+	}
+}
+
+func TestWebSocketSessionMatches(t *testing.T) {
+	tests := []struct {
+		name          string
+		subscriptions map[string]bool
+		database      string
+		table         string
+		expected      bool
+	}{
+		{"no subscriptions", map[string]bool{}, "db", "users", false},
+		{"everything", map[string]bool{"": true}, "db", "users", true},
+		{"whole database", map[string]bool{"db/": true}, "db", "users", true},
+		{"same table", map[string]bool{"db/users": true}, "db", "users", true},
+		{"other table", map[string]bool{"db/orders": true}, "db", "users", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &wsSession{subscriptions: tt.subscriptions}
+			if matched := session.matches(tt.database, tt.table); matched != tt.expected {
+				t.Errorf("matches(%q, %q) = %v; expected %v", tt.database, tt.table, matched, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWantsPlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		expected bool
+	}{
+		{"json by default", "/health", "", false},
+		{"txt path", "/health.txt", "", true},
+		{"text accepted", "/health", "text/plain", true},
+		{"json preferred", "/health", "application/json, text/plain", false},
+		{"text preferred", "/health", "text/plain;q=0.9, application/json;q=0.8", true},
+		{"anything", "/health", "*/*", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			if result := wantsPlainText(request); result != tt.expected {
+				t.Errorf("wantsPlainText() = %v; expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteNagiosResponse(t *testing.T) {
+	server := &Server{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	tests := []struct {
+		name          string
+		response      OverallHealthResponse
+		expectedCode  int
+		expectedFirst string
+	}{
+		{
+			name: "ok",
+			response: OverallHealthResponse{
+				Status: "healthy", TotalChecks: 1, HealthyChecks: 1,
+				Databases: map[string][]*database.HealthResult{"db": {{DatabaseName: "db", TableName: "users", Status: "healthy", QueryTime: 25 * time.Millisecond}}},
+			},
+			expectedCode:  http.StatusOK,
+			expectedFirst: "GSQLHEALTH OK - 1/1 checks healthy | checks=1;;;0 healthy=1;;;0;1 maintenance=0;;;0",
+		},
+		{
+			name: "critical",
+			response: OverallHealthResponse{
+				Status: "unhealthy", TotalChecks: 1,
+				Databases: map[string][]*database.HealthResult{"db": {{DatabaseName: "db", TableName: "users", Status: "unhealthy", Error: "query failed"}}},
+			},
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedFirst: "GSQLHEALTH CRITICAL - 0/1 checks healthy | checks=1;;;0 healthy=0;;;0;1 maintenance=0;;;0",
+		},
+		{
+			name: "degraded checks",
+			response: OverallHealthResponse{
+				Status: "degraded", TotalChecks: 2, HealthyChecks: 1, DegradedChecks: 1,
+				Databases: map[string][]*database.HealthResult{"db": {
+					{DatabaseName: "db", TableName: "users", Status: "healthy"},
+					{DatabaseName: "db", TableName: "orders", Status: "degraded", Error: "query time 3s exceeded warn_latency 2s"},
+				}},
+			},
+			expectedCode:  http.StatusOK,
+			expectedFirst: "GSQLHEALTH WARNING - 1/2 checks healthy, 1 degraded | checks=2;;;0 healthy=1;;;0;2 maintenance=0;;;0",
+		},
+		{
+			name: "degraded service",
+			response: OverallHealthResponse{
+				Status: "healthy", TotalChecks: 1, HealthyChecks: 1,
+				Self: &database.HealthResult{Status: "degraded"},
+			},
+			expectedCode:  http.StatusOK,
+			expectedFirst: "GSQLHEALTH WARNING - 1/1 checks healthy, service degraded | checks=1;;;0 healthy=1;;;0;1 maintenance=0;;;0",
+		},
+		{
+			name:          "no checks",
+			response:      OverallHealthResponse{Status: "healthy"},
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedFirst: "GSQLHEALTH UNKNOWN - 0/0 checks healthy | checks=0;;;0 healthy=0;;;0;0 maintenance=0;;;0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.writeNagiosResponse(recorder, httptest.NewRequest(http.MethodGet, "/health", nil), tt.response)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			lines := strings.Split(recorder.Body.String(), "\n")
+			if lines[0] != tt.expectedFirst {
+				t.Errorf("First line = %q; expected %q", lines[0], tt.expectedFirst)
+			}
+		})
+	}
+}
+
+func TestWantsHealthJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		accept   string
+		expected bool
+	}{
+		{"json by default", "/health", "", false},
+		{"format parameter", "/health?format=health%2Bjson", "", true},
+		{"unescaped format parameter", "/health?format=health+json", "", true},
+		{"health+json accepted", "/health", "application/health+json", true},
+		{"json preferred", "/health", "application/json, application/health+json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			if result := wantsHealthJSON(request); result != tt.expected {
+				t.Errorf("wantsHealthJSON() = %v; expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteHealthJSONResponse(t *testing.T) {
+	server := &Server{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	tests := []struct {
+		name           string
+		response       OverallHealthResponse
+		expectedCode   int
+		expectedStatus string
+		expectedChecks []string
+	}{
+		{
+			name: "pass",
+			response: OverallHealthResponse{
+				Status: "healthy",
+				Databases: map[string][]*database.HealthResult{"db": {
+					{DatabaseName: "db", TableName: "users", Status: "healthy"},
+					{DatabaseName: "db", TableName: "archive", Status: "disabled"},
+				}},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "pass",
+			expectedChecks: []string{"db:users"},
+		},
+		{
+			name: "warn during maintenance",
+			response: OverallHealthResponse{
+				Status: "healthy", MaintenanceChecks: 1,
+				Databases: map[string][]*database.HealthResult{"db": {{DatabaseName: "db", TableName: "users", Status: "maintenance"}}},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "warn",
+			expectedChecks: []string{"db:users"},
+		},
+		{
+			name: "warn when degraded",
+			response: OverallHealthResponse{
+				Status: "degraded", DegradedChecks: 1,
+				Databases: map[string][]*database.HealthResult{"db": {{DatabaseName: "db", TableName: "users", Status: "degraded"}}},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "warn",
+			expectedChecks: []string{"db:users"},
+		},
+		{
+			name: "fail",
+			response: OverallHealthResponse{
+				Status:    "unhealthy",
+				Databases: map[string][]*database.HealthResult{"db": {{DatabaseName: "db", TableName: "users", Status: "unhealthy", Error: "query failed"}}},
+				Self:      &database.HealthResult{DatabaseName: "_self", TableName: "watchdog", Status: "healthy"},
+			},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "fail",
+			expectedChecks: []string{"_self:watchdog", "db:users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			server.writeHealthJSONResponse(recorder, httptest.NewRequest(http.MethodGet, "/health", nil), tt.response)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != healthJSONContentType {
+				t.Errorf("Content-Type = %q; expected %q", contentType, healthJSONContentType)
+			}
+
+			var response HealthJSONResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Status != tt.expectedStatus {
+				t.Errorf("Status = %q; expected %q", response.Status, tt.expectedStatus)
+			}
+			var checks []string
+			for key := range response.Checks {
+				checks = append(checks, key)
+			}
+			sort.Strings(checks)
+			if !reflect.DeepEqual(checks, tt.expectedChecks) {
+				t.Errorf("Checks = %v; expected %v", checks, tt.expectedChecks)
+			}
+		})
+	}
+}
+
+func TestPreferredMediaType(t *testing.T) {
+	offers := []string{"application/json", "text/plain", "application/yaml"}
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/yaml", "application/yaml"},
+		{"text/html, text/plain", "text/plain"},
+		{"application/json, application/yaml", "application/json"},
+		{"image/png", "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/databases", nil)
+			request.Header.Set("Accept", tt.accept)
+			if result := preferredMediaType(request, offers...); result != tt.expected {
+				t.Errorf("preferredMediaType() = %q; expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestContentNegotiation(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Labels: map[string]string{"env": "prod"}, Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		accept              string
+		expectedContentType string
+		expectedBody        string
+	}{
+		{"application/json", "application/json", `"databases":["db"]`},
+		{"application/yaml", "application/yaml", "databases:\n  - db\n"},
+		{"application/x-yaml", "application/yaml", "count: 1\n"},
+		{"text/plain", "text/plain; charset=utf-8", "db        enabled  env=prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/databases", nil)
+			request.Header.Set("Accept", tt.accept)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", recorder.Code)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != tt.expectedContentType {
+				t.Errorf("Content-Type = %q; expected %q", contentType, tt.expectedContentType)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.expectedBody) {
+				t.Errorf("Body %q does not contain %q", recorder.Body.String(), tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestRefreshUnknownTargets(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	for _, path := range []string{"/health/missing/refresh", "/health/db/missing/refresh", apiPrefix + "/health/missing/refresh"} {
+		t.Run(path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+
+			if recorder.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", recorder.Code)
+			}
+		})
+	}
+}
+
+func TestSchedulerAdmin(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		method         string
+		path           string
+		expectedCode   int
+		expectedPaused bool
+		expectedDBs    []string
+		expectedChecks []string
+	}{
+		{http.MethodPost, "/admin/scheduler/pause/db", http.StatusOK, false, []string{"db"}, []string{}},
+		{http.MethodPost, "/admin/scheduler/pause/missing", http.StatusNotFound, false, nil, nil},
+		{http.MethodPost, "/admin/scheduler/pause", http.StatusOK, true, []string{"db"}, []string{}},
+		{http.MethodPost, "/admin/checks/db/users/pause", http.StatusOK, true, []string{"db"}, []string{"db/users"}},
+		{http.MethodPost, "/admin/checks/db/missing/pause", http.StatusNotFound, true, nil, nil},
+		{http.MethodGet, "/admin/scheduler", http.StatusOK, true, []string{"db"}, []string{"db/users"}},
+		{http.MethodPost, apiPrefix + "/admin/scheduler/resume", http.StatusOK, false, []string{}, []string{"db/users"}},
+		{http.MethodPost, apiPrefix + "/admin/checks/db/users/resume", http.StatusOK, false, []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response SchedulerResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Paused != tt.expectedPaused || !reflect.DeepEqual(response.PausedDatabases, tt.expectedDBs) || !reflect.DeepEqual(response.PausedChecks, tt.expectedChecks) {
+				t.Errorf("Response = %+v; expected paused %v, databases %v, checks %v", response, tt.expectedPaused, tt.expectedDBs, tt.expectedChecks)
+			}
+		})
+	}
+}
+
+func TestSnapshotAdmin(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := NewServer(cfg, health.NewService(cfg, logger), logger).setupRoutes()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/snapshot", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /admin/snapshot: expected status 200, got %d", recorder.Code)
+	}
+	var snapshot health.Snapshot
+	if err := json.Unmarshal(recorder.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("Failed to decode snapshot: %v", err)
+	}
+	if snapshot.SavedAt.IsZero() || snapshot.Scheduler == nil || len(snapshot.Connections) != 1 || snapshot.Connections[0].Connected {
+		t.Errorf("Snapshot = %+v; expected the scheduler state and the unconnected database", snapshot)
+	}
+
+	snapshot.Scheduler.PausedChecks = []string{"db/users", "db/missing"}
+	body, _ := json.Marshal(snapshot)
+
+	tests := []struct {
+		body         string
+		expectedCode int
+	}{
+		{string(body), http.StatusOK},
+		{"{}", http.StatusBadRequest},
+		{"not a snapshot", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(tt.body)))
+		if recorder.Code != tt.expectedCode {
+			t.Errorf("POST /admin/restore %q: expected status %d, got %d", tt.body, tt.expectedCode, recorder.Code)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/scheduler", nil))
+	var response SchedulerResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !reflect.DeepEqual(response.PausedChecks, []string{"db/users"}) {
+		t.Errorf("PausedChecks = %v; expected the restored pause of the configured check", response.PausedChecks)
+	}
+}
+
+func TestDatabaseAdmin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dynamic.yaml")
+	cfg := &config.Config{
+		Databases:        []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		DynamicDatabases: path,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := health.NewService(cfg, logger)
+	defer service.Close()
+	router := NewServer(cfg, service, logger).setupRoutes()
+
+	reports := `{"name": "reports", "type": "postgres", "host": "127.0.0.1", "port": 1, "username": "monitor", "database": "reports",
+		"tables": [{"name": "orders", "query": "SELECT 1", "timeout": "1s", "check_interval": "1h"}]}`
+
+	tests := []struct {
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{http.MethodPost, "/admin/databases", reports, http.StatusCreated},
+		{http.MethodPost, "/admin/databases", reports, http.StatusConflict},
+		{http.MethodPost, "/admin/databases", `{"name": "broken", "type": "oracle"}`, http.StatusBadRequest},
+		{http.MethodPost, "/admin/databases", `[`, http.StatusBadRequest},
+		{http.MethodGet, "/databases/reports/tables", "", http.StatusOK},
+		{http.MethodDelete, "/admin/databases/db", "", http.StatusConflict},
+		{http.MethodDelete, apiPrefix + "/admin/databases/reports", "", http.StatusNoContent},
+		{http.MethodDelete, "/admin/databases/reports", "", http.StatusNotFound},
+		{http.MethodGet, "/databases/reports/tables", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+		if recorder.Code != tt.expectedCode {
+			t.Fatalf("%s %s: expected status %d, got %d: %s", tt.method, tt.path, tt.expectedCode, recorder.Code, recorder.Body)
+		}
+
+		// The added database is kept until it is removed
+		stored, err := config.ReadDynamicDatabases(path)
+		if err != nil {
+			t.Fatalf("ReadDynamicDatabases() error = %v", err)
+		}
+		if tt.expectedCode == http.StatusCreated && (len(stored) != 1 || stored[0].Name != "reports") {
+			t.Errorf("Stored databases = %+v; expected reports", stored)
+		}
+		if tt.expectedCode == http.StatusNoContent && len(stored) != 0 {
+			t.Errorf("Stored databases = %+v; expected none", stored)
+		}
+	}
+
+	// Without a file to keep them in, databases cannot be added
+	cfg = &config.Config{Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}}}
+	router = NewServer(cfg, health.NewService(cfg, logger), logger).setupRoutes()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/databases", strings.NewReader(reports)))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without dynamic_databases, got %d", recorder.Code)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value     string
+		expected  time.Time
+		expectErr bool
+	}{
+		{"", time.Time{}, false},
+		{"1h", now.Add(-time.Hour), false},
+		{"7d", now.Add(-7 * 24 * time.Hour), false},
+		{"2024-01-15T09:00:00Z", time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC), false},
+		{"-1h", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			since, err := parseSince(tt.value, now)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("parseSince(%q) error = %v; expectErr %v", tt.value, err, tt.expectErr)
+			}
+			if !since.Equal(tt.expected) {
+				t.Errorf("parseSince(%q) = %v; expected %v", tt.value, since, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTableHistory(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		path         string
+		expectedCode int
+	}{
+		{"/health/db/users/history", http.StatusOK},
+		{"/health/db/users/history?since=1h&limit=10", http.StatusOK},
+		{"/health/db/users/history?limit=0", http.StatusBadRequest},
+		{"/health/db/users/history?since=soon", http.StatusBadRequest},
+		{"/health/db/missing/history", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response HistoryResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Entries == nil || response.Count != 0 {
+				t.Errorf("Expected an empty history, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestHistoryExport(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		path         string
+		expectedCode int
+		expectedType string
+		expectedBody string
+	}{
+		{"/health/db/users/history/export", http.StatusOK, "text/csv; charset=utf-8", "timestamp,status,status_code,query_time,error,plan,instance\n"},
+		{"/health/db/users/history/export?format=ndjson&since=7d", http.StatusOK, "application/x-ndjson", ""},
+		{"/health/db/users/history/export?format=xml", http.StatusBadRequest, "", ""},
+		{"/health/db/users/history/export?since=soon", http.StatusBadRequest, "", ""},
+		{"/health/db/missing/history/export", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != tt.expectedType {
+				t.Errorf("Content-Type = %q; expected %q", contentType, tt.expectedType)
+			}
+			if disposition := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, `attachment; filename="db-users-history.`) {
+				t.Errorf("Content-Disposition = %q; expected an attachment", disposition)
+			}
+			if body := recorder.Body.String(); body != tt.expectedBody {
+				t.Errorf("Body = %q; expected %q", body, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestWriteHistoryExport(t *testing.T) {
+	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	entries := []health.HistoryEntry{
+		{Status: database.StatusUnhealthy, StatusCode: 4, Error: `Error 1146: Table "users", gone`, QueryTime: 1800000, Timestamp: timestamp.Add(time.Minute)},
+		{Status: database.StatusHealthy, StatusCode: 0, QueryTime: 3200000, Timestamp: timestamp, Instance: "web-1"},
+	}
+
+	var csvExport strings.Builder
+	if err := writeHistoryCSV(&csvExport, entries, func() {}); err != nil {
+		t.Fatalf("writeHistoryCSV() error = %v", err)
+	}
+	expectedCSV := `timestamp,status,status_code,query_time,error,plan,instance
+2024-01-15T10:30:00Z,healthy,0,3200000,,,web-1
+2024-01-15T10:31:00Z,unhealthy,4,1800000,"Error 1146: Table ""users"", gone",,
+`
+	if csvExport.String() != expectedCSV {
+		t.Errorf("writeHistoryCSV() =\n%s\nexpected\n%s", csvExport.String(), expectedCSV)
+	}
+
+	var ndjsonExport strings.Builder
+	if err := writeHistoryNDJSON(&ndjsonExport, entries, func() {}); err != nil {
+		t.Fatalf("writeHistoryNDJSON() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(ndjsonExport.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", ndjsonExport.String())
+	}
+	for i, line := range lines {
+		var entry health.HistoryEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode line %d: %v", i, err)
+		}
+		if expected := entries[len(entries)-1-i]; entry.Status != expected.Status || !entry.Timestamp.Equal(expected.Timestamp) {
+			t.Errorf("Line %d = %+v; expected %+v", i, entry, expected)
+		}
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		value     string
+		expected  time.Duration
+		expectErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"xd", 0, true},
+		{"month", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			window, err := parseWindow(tt.value)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("parseWindow(%q) error = %v; expectErr %v", tt.value, err, tt.expectErr)
+			}
+			if window != tt.expected {
+				t.Errorf("parseWindow(%q) = %v; expected %v", tt.value, window, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSLA(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		path           string
+		expectedCode   int
+		expectedTables int
+	}{
+		{"/sla/db", http.StatusOK, 1},
+		{"/sla/db/users?window=7d", http.StatusOK, 0},
+		{"/sla/db?window=soon", http.StatusBadRequest, 0},
+		{"/sla/db/users?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z", http.StatusOK, 0},
+		{"/sla/db/users?from=6h&to=3h", http.StatusOK, 0},
+		{"/sla/db/users?from=3h&to=6h", http.StatusBadRequest, 0},
+		{"/sla/db/users?from=6h&window=1d", http.StatusBadRequest, 0},
+		{"/sla/missing", http.StatusNotFound, 0},
+		{"/sla/db/missing", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			if tt.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response SLAResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Tables) != tt.expectedTables {
+				t.Errorf("len(Tables) = %d; expected %d", len(response.Tables), tt.expectedTables)
+			}
+		})
+	}
+}
+
+func TestParseStatusFilter(t *testing.T) {
+	tests := []struct {
+		query     string
+		expected  map[database.Status]bool
+		expectErr bool
+	}{
+		{"", map[database.Status]bool{}, false},
+		{"status=unhealthy", map[database.Status]bool{"unhealthy": true}, false},
+		{"status=unhealthy,error&status=maintenance", map[database.Status]bool{"unhealthy": true, "error": true, "maintenance": true}, false},
+		{"status=broken", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			statuses, err := parseStatusFilter(httptest.NewRequest(http.MethodGet, "/health?"+tt.query, nil))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("parseStatusFilter() error = %v; expectErr %v", err, tt.expectErr)
+			}
+			if !tt.expectErr && !reflect.DeepEqual(statuses, tt.expected) {
+				t.Errorf("parseStatusFilter() = %v; expected %v", statuses, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterStatuses(t *testing.T) {
+	results := map[string][]*database.HealthResult{
+		"db1": {{TableName: "users", Status: "healthy"}, {TableName: "orders", Status: "unhealthy"}},
+		"db2": {{TableName: "events", Status: "healthy"}},
+	}
+
+	filtered := filterStatuses(results, map[database.Status]bool{"unhealthy": true})
+	if len(filtered) != 1 || len(filtered["db1"]) != 1 || filtered["db1"][0].TableName != "orders" {
+		t.Errorf("filterStatuses() = %v; expected only db1/orders", filtered)
+	}
+
+	if unfiltered := filterStatuses(results, map[database.Status]bool{}); !reflect.DeepEqual(unfiltered, results) {
+		t.Errorf("Expected an empty status set to keep all results")
+	}
+}
+
+func TestOverallHealthFilters(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{
+			{Name: "db1", Tables: []config.Table{{Name: "users"}}},
+			{Name: "db2", Tables: []config.Table{{Name: "events"}}},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		query        string
+		expectedCode int
+	}{
+		{"?database=db1,db2", http.StatusOK},
+		{"?status=unhealthy", http.StatusOK},
+		{"?status=sideways", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health"+tt.query, nil))
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query     string
+		expected  *pagination
+		expectErr bool
+	}{
+		{"", nil, false},
+		{"page=2", &pagination{page: 2, perPage: defaultPerPage}, false},
+		{"per_page=10", &pagination{page: 1, perPage: 10}, false},
+		{"page=0", nil, true},
+		{"per_page=5000", nil, true},
+		{"page=x", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			p, err := parsePagination(httptest.NewRequest(http.MethodGet, "/databases?"+tt.query, nil))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("parsePagination() error = %v; expectErr %v", err, tt.expectErr)
+			}
+			if !reflect.DeepEqual(p, tt.expected) {
+				t.Errorf("parsePagination() = %+v; expected %+v", p, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPaginatedDatabases(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{
+			{Name: "db1", Tables: []config.Table{{Name: "users"}}},
+			{Name: "db2", Tables: []config.Table{{Name: "users"}}},
+			{Name: "db3", Tables: []config.Table{{Name: "users"}}},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		query             string
+		expectedDatabases []string
+		expectedLink      string
+	}{
+		{
+			query:             "?per_page=2",
+			expectedDatabases: []string{"db1", "db2"},
+			expectedLink:      `</databases?page=1&per_page=2>; rel="first", </databases?page=2&per_page=2>; rel="next", </databases?page=2&per_page=2>; rel="last"`,
+		},
+		{
+			query:             "?page=2&per_page=2",
+			expectedDatabases: []string{"db3"},
+			expectedLink:      `</databases?page=1&per_page=2>; rel="first", </databases?page=1&per_page=2>; rel="prev", </databases?page=2&per_page=2>; rel="last"`,
+		},
+		{
+			query:             "?page=5&per_page=2",
+			expectedDatabases: []string{},
+			expectedLink:      `</databases?page=1&per_page=2>; rel="first", </databases?page=2&per_page=2>; rel="prev", </databases?page=2&per_page=2>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/databases"+tt.query, nil))
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", recorder.Code)
+			}
+			if total := recorder.Header().Get("X-Total-Count"); total != "3" {
+				t.Errorf("X-Total-Count = %q; expected %q", total, "3")
+			}
+			if link := recorder.Header().Get("Link"); link != tt.expectedLink {
+				t.Errorf("Link = %s; expected %s", link, tt.expectedLink)
+			}
+
+			var response DatabaseListResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !reflect.DeepEqual(response.Databases, tt.expectedDatabases) {
+				t.Errorf("Databases = %v; expected %v", response.Databases, tt.expectedDatabases)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 1, 15, 10, 30, 0, 500, time.UTC)
+	etag := `W/"abc"`
+
+	tests := []struct {
+		name        string
+		method      string
+		statusCode  int
+		headers     map[string]string
+		expected304 bool
+	}{
+		{"unconditional", http.MethodGet, http.StatusOK, nil, false},
+		{"etag matches", http.MethodGet, http.StatusOK, map[string]string{"If-None-Match": `"other", W/"abc"`}, true},
+		{"strong form matches weakly", http.MethodGet, http.StatusOK, map[string]string{"If-None-Match": `"abc"`}, true},
+		{"etag differs", http.MethodGet, http.StatusOK, map[string]string{"If-None-Match": `W/"def"`}, false},
+		{"not modified since", http.MethodGet, http.StatusOK, map[string]string{"If-Modified-Since": "Mon, 15 Jan 2024 10:30:00 GMT"}, true},
+		{"modified since", http.MethodGet, http.StatusOK, map[string]string{"If-Modified-Since": "Mon, 15 Jan 2024 10:29:59 GMT"}, false},
+		{"etag takes precedence", http.MethodGet, http.StatusOK, map[string]string{"If-None-Match": `W/"def"`, "If-Modified-Since": "Mon, 15 Jan 2024 10:30:00 GMT"}, false},
+		{"failing response", http.MethodGet, http.StatusServiceUnavailable, map[string]string{"If-None-Match": etag}, false},
+		{"refresh", http.MethodPost, http.StatusOK, map[string]string{"If-None-Match": etag}, false},
+		{"head", http.MethodHead, http.StatusOK, map[string]string{"If-None-Match": etag}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "/health", nil)
+			for key, value := range tt.headers {
+				request.Header.Set(key, value)
+			}
+			recorder := httptest.NewRecorder()
+
+			if result := notModified(recorder, request, tt.statusCode, etag, lastModified); result != tt.expected304 {
+				t.Errorf("notModified() = %v; expected %v", result, tt.expected304)
+			}
+			if tt.expected304 && recorder.Code != http.StatusNotModified {
+				t.Errorf("Expected status 304, got %d", recorder.Code)
+			}
+		})
+	}
+}
+
+func TestLatestResult(t *testing.T) {
+	checked := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	unknownSince := checked.Add(3 * time.Hour)
+
+	tests := []struct {
+		name     string
+		results  []*database.HealthResult
+		expected time.Time
+	}{
+		{"no results", nil, time.Time{}},
+		{"latest check", []*database.HealthResult{{Timestamp: checked.Add(-time.Minute)}, nil, {Timestamp: checked}}, checked},
+		{"unknown result", []*database.HealthResult{{Timestamp: checked, Status: database.StatusUnknown, UnknownSince: &unknownSince}}, unknownSince},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if latest := latestResult(tt.results...); !latest.Equal(tt.expected) {
+				t.Errorf("latestResult() = %v; expected %v", latest, tt.expected)
+			}
+		})
+	}
+
+	// A poller that saw the healthy result gets the unknown one in full
+	request := httptest.NewRequest(http.MethodGet, "/health", nil)
+	request.Header.Set("If-Modified-Since", checked.Format(http.TimeFormat))
+	unknown := &database.HealthResult{Timestamp: checked, Status: database.StatusUnknown, UnknownSince: &unknownSince}
+	if notModified(httptest.NewRecorder(), request, http.StatusOK, "", latestResult(unknown)) {
+		t.Errorf("notModified() = true; expected a result that became unknown to be modified")
+	}
+}
+
+func TestOverallHealthETag(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/health", nil))
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("Expected an ETag header")
+	}
+
+	tests := []struct {
+		name         string
+		accept       string
+		expectedCode int
+	}{
+		{"same representation", "", http.StatusNotModified},
+		{"other representation", "application/yaml", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/health", nil)
+			request.Header.Set("If-None-Match", etag)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestCORSPolicy(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name                string
+		cors                config.CORS
+		origin              string
+		expectedOrigin      string
+		expectedCredentials string
+	}{
+		{"default allows any origin", config.CORS{}, "https://app.example.com", "*", ""},
+		{"allowed origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, "https://app.example.com", "https://app.example.com", "true"},
+		{"other origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}}, "https://evil.example.com", "", ""},
+		{"disabled", config.CORS{Enabled: &disabled}, "https://app.example.com", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &Server{
+				config: &config.Config{Server: config.Server{CORS: tt.cors}},
+				logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			handler := server.corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			request := httptest.NewRequest(http.MethodGet, "/health", nil)
+			request.Header.Set("Origin", tt.origin)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if origin := recorder.Header().Get("Access-Control-Allow-Origin"); origin != tt.expectedOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q; expected %q", origin, tt.expectedOrigin)
+			}
+			if credentials := recorder.Header().Get("Access-Control-Allow-Credentials"); credentials != tt.expectedCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q; expected %q", credentials, tt.expectedCredentials)
+			}
+		})
+	}
+}
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name      string
+		cors      config.CORS
+		origin    string
+		expectErr bool
+	}{
+		{"no origin", config.CORS{Enabled: &disabled}, "", false},
+		{"same origin", config.CORS{Enabled: &disabled}, "http://example.com", false},
+		{"cross origin with CORS disabled", config.CORS{Enabled: &disabled}, "https://app.example.com", true},
+		{"allowed origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}}, "https://app.example.com", false},
+		{"other origin", config.CORS{AllowedOrigins: []string{"https://app.example.com"}}, "https://evil.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &Server{config: &config.Config{Server: config.Server{CORS: tt.cors}}}
+
+			request := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.origin != "" {
+				request.Header.Set("Origin", tt.origin)
+			}
+			if err := server.checkWebSocketOrigin(nil, request); (err != nil) != tt.expectErr {
+				t.Errorf("checkWebSocketOrigin() error = %v; expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		Server: config.Server{Auth: config.Auth{
+			Users:       []config.AuthUser{{Username: "ops", PasswordHash: string(hash)}},
+			APIKeys:     []config.APIKey{{Name: "monitoring", Key: "0123456789abcdef0123"}},
+			ExemptPaths: []string{"/cache/stats"},
+		}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		apiKey       string
+		username     string
+		password     string
+		expectedCode int
+	}{
+		{"no credentials", http.MethodGet, "/databases", "", "", "", http.StatusUnauthorized},
+		{"api key", http.MethodGet, "/databases", "0123456789abcdef0123", "", "", http.StatusOK},
+		{"wrong api key", http.MethodGet, "/databases", "fedcba9876543210fedc", "", "", http.StatusUnauthorized},
+		{"basic auth", http.MethodGet, "/databases", "", "ops", "s3cret", http.StatusOK},
+		{"basic auth again", http.MethodGet, apiPrefix + "/databases", "", "ops", "s3cret", http.StatusOK},
+		{"wrong password", http.MethodGet, "/databases", "", "ops", "guess", http.StatusUnauthorized},
+		{"unknown user", http.MethodGet, "/databases", "", "root", "s3cret", http.StatusUnauthorized},
+		{"exempt path", http.MethodGet, "/cache/stats", "", "", "", http.StatusOK},
+		{"exempt versioned path", http.MethodGet, apiPrefix + "/cache/stats", "", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.apiKey != "" {
+				request.Header.Set("X-API-Key", tt.apiKey)
+			}
+			if tt.username != "" {
+				request.SetBasicAuth(tt.username, tt.password)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			if tt.expectedCode == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("Expected a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestAuthHelpers(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		expectedToken string
+		expectedFound bool
+	}{
+		{"bearer", "Bearer abc.def.ghi", "abc.def.ghi", true},
+		{"lowercase scheme", "bearer abc.def.ghi", "abc.def.ghi", true},
+		{"basic", "Basic b3BzOnMzY3JldA==", "", false},
+		{"empty token", "Bearer ", "", false},
+		{"missing", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/health", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			token, found := bearerToken(request)
+			if token != tt.expectedToken || found != tt.expectedFound {
+				t.Errorf("bearerToken() = %q, %v; expected %q, %v", token, found, tt.expectedToken, tt.expectedFound)
+			}
+		})
+	}
+
+	for path, expected := range map[string]bool{
+		"/admin/scheduler":               true,
+		apiPrefix + "/admin/scheduler":   true,
+		"/administrator":                 false,
+		"/health":                        false,
+		apiPrefix + "/health/db/admin/x": false,
+		"/debug/pprof/heap":              true,
+		"/debug/vars":                    false,
+	} {
+		if got := isAdminPath(path); got != expected {
+			t.Errorf("isAdminPath(%q) = %v; expected %v", path, got, expected)
+		}
+	}
+
+	if !hasAnyRole(nil, nil) {
+		t.Errorf("Expected any roles to satisfy an empty requirement")
+	}
+	if !hasAnyRole([]string{"viewer", "admin"}, []string{"admin"}) {
+		t.Errorf("Expected admin role to satisfy the requirement")
+	}
+	if hasAnyRole([]string{"viewer"}, []string{"admin"}) {
+		t.Errorf("Expected viewer role not to satisfy the requirement")
+	}
+}
+
+// writeTestCertificate writes a self-signed certificate and its key as PEM
+// files and returns their paths
+func writeTestCertificate(t *testing.T, dir, commonName string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, commonName+".crt")
+	keyFile := filepath.Join(dir, commonName+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	certFile, keyFile := writeTestCertificate(t, dir, "server")
+
+	reloader, err := newCertReloader(certFile, keyFile, logger)
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	first, _ := reloader.GetCertificate(nil)
+
+	// Replace the certificate with a newer one
+	next, nextKey := writeTestCertificate(t, t.TempDir(), "server")
+	for _, pair := range [][2]string{{next, certFile}, {nextKey, keyFile}} {
+		data, err := os.ReadFile(pair[0])
+		if err != nil {
+			t.Fatalf("Failed to read certificate: %v", err)
+		}
+		if err := os.WriteFile(pair[1], data, 0o600); err != nil {
+			t.Fatalf("Failed to replace certificate: %v", err)
+		}
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(pair[1], later, later)
+	}
+
+	if cert, _ := reloader.GetCertificate(nil); cert != first {
+		t.Errorf("Expected the certificate to be reused within the reload interval")
+	}
+
+	reloader.checkedAt = time.Now().Add(-certReloadInterval)
+	if cert, _ := reloader.GetCertificate(nil); cert == first {
+		t.Errorf("Expected the changed certificate to be reloaded")
+	}
+
+	// A broken key keeps the previous certificate
+	reloaded, _ := reloader.GetCertificate(nil)
+	if err := os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("Failed to corrupt key: %v", err)
+	}
+	later := time.Now().Add(2 * time.Minute)
+	os.Chtimes(keyFile, later, later)
+	reloader.checkedAt = time.Now().Add(-certReloadInterval)
+	if cert, _ := reloader.GetCertificate(nil); cert != reloaded {
+		t.Errorf("Expected the previous certificate to be kept after a failed reload")
+	}
+}
+
+func TestTLSClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	certFile, keyFile := writeTestCertificate(t, dir, "server")
+	clientCert, clientKey := writeTestCertificate(t, dir, "client")
+	otherCert, otherKey := writeTestCertificate(t, dir, "other")
+
+	tlsConfig, err := newTLSConfig(config.TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: clientCert}, logger)
+	if err != nil {
+		t.Fatalf("Failed to configure TLS: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = tlsConfig
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	serverPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatalf("Failed to read certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(serverPEM)
+
+	tests := []struct {
+		name      string
+		certFile  string
+		keyFile   string
+		expectErr bool
+	}{
+		{"trusted client", clientCert, clientKey, false},
+		{"untrusted client", otherCert, otherKey, true},
+		{"no client certificate", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientTLS := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+			if tt.certFile != "" {
+				cert, err := tls.LoadX509KeyPair(tt.certFile, tt.keyFile)
+				if err != nil {
+					t.Fatalf("Failed to load client certificate: %v", err)
+				}
+				clientTLS.Certificates = []tls.Certificate{cert}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.expectErr {
+				t.Errorf("Request error = %v; expected error %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "http.sock")
+
+	listener, err := listenUnix(path, 0o600)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat socket: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("Socket mode = %o; expected %o", mode, 0o600)
+	}
+
+	if _, err := listenUnix(path, 0o600); err == nil {
+		t.Errorf("Expected an error for a socket in use")
+	}
+
+	// A socket left behind without a listener is replaced
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+	listener, err = listenUnix(path, 0o600)
+	if err != nil {
+		t.Fatalf("Expected a stale socket to be replaced: %v", err)
+	}
+	listener.Close()
+
+	regular := filepath.Join(dir, "regular")
+	if err := os.WriteFile(regular, nil, 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := listenUnix(regular, 0o600); err == nil {
+		t.Errorf("Expected an error for a path that is not a socket")
+	}
+}
+
+func TestServeUnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "http.sock")
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		Server: config.Server{
+			Socket:       path,
+			ReadTimeout:  config.Duration(time.Second),
+			WriteTimeout: config.Duration(time.Second),
+			IdleTimeout:  config.Duration(time.Second),
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+
+	done := make(chan error, 1)
+	go func() { done <- server.Start() }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+
+	var resp *http.Response
+	var err error
+	for attempt := 0; attempt < 50; attempt++ {
+		if resp, err = client.Get("http://gsqlhealth/databases"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Request over unix socket failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if err := <-done; err != http.ErrServerClosed {
+		t.Errorf("Start() = %v; expected %v", err, http.ErrServerClosed)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the socket to be removed on shutdown")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(2, 3)
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.allow("a", start); !allowed {
+			t.Fatalf("Expected request %d within the burst to be allowed", i+1)
+		}
+	}
+	allowed, wait := limiter.allow("a", start)
+	if allowed {
+		t.Fatalf("Expected request beyond the burst to be rejected")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait = %v; expected %v", wait, 500*time.Millisecond)
+	}
+
+	if allowed, _ := limiter.allow("b", start); !allowed {
+		t.Errorf("Expected another client to have its own bucket")
+	}
+	if allowed, _ := limiter.allow("a", start.Add(500*time.Millisecond)); !allowed {
+		t.Errorf("Expected a token to be refilled after 500ms")
+	}
+
+	// Refilled buckets are dropped
+	limiter.allow("c", start.Add(time.Hour))
+	if _, exists := limiter.buckets["a"]; exists {
+		t.Errorf("Expected the idle bucket to be swept")
+	}
+
+	if allowed, _ := newRateLimiter(0, 0).allow("a", start); !allowed {
+		t.Errorf("Expected a zero rate to allow everything")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		Server: config.Server{RateLimit: config.RateLimit{
+			RequestsPerSecond: 0.001,
+			Burst:             3,
+			RealtimePerSecond: 0.001,
+			RealtimeBurst:     1,
+		}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		name         string
+		path         string
+		remoteAddr   string
+		expectedCode int
+	}{
+		{"realtime", "/databases?realtime=true", "10.0.0.1:1234", http.StatusOK},
+		{"second realtime", "/databases?realtime=true", "10.0.0.1:1234", http.StatusTooManyRequests},
+		{"cached", "/databases", "10.0.0.1:1234", http.StatusOK},
+		{"burst exhausted", "/databases", "10.0.0.1:5678", http.StatusTooManyRequests},
+		{"other client", "/databases?realtime=true", "10.0.0.2:1234", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			request.RemoteAddr = tt.remoteAddr
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+			if tt.expectedCode == http.StatusTooManyRequests && recorder.Header().Get("Retry-After") == "" {
+				t.Errorf("Expected a Retry-After header")
+			}
+		})
+	}
+}
+
+func TestRateLimitBeforeAuth(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		Server: config.Server{
+			Auth:      config.Auth{APIKeys: []config.APIKey{{Name: "monitoring", Key: "0123456789abcdef0123"}}},
+			RateLimit: config.RateLimit{RequestsPerSecond: 0.001, Burst: 2},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		name         string
+		apiKey       string
+		remoteAddr   string
+		expectedCode int
+	}{
+		{"failed login", "fedcba9876543210fedc", "10.0.0.1:1234", http.StatusUnauthorized},
+		{"second failed login", "fedcba9876543210fedc", "10.0.0.1:1234", http.StatusUnauthorized},
+		{"address exhausted", "0123456789abcdef0123", "10.0.0.1:1234", http.StatusTooManyRequests},
+		{"other address", "0123456789abcdef0123", "10.0.0.2:1234", http.StatusOK},
+		{"key exhausted", "0123456789abcdef0123", "10.0.0.3:1234", http.StatusOK},
+		{"key limited", "0123456789abcdef0123", "10.0.0.4:1234", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/databases", nil)
+			request.RemoteAddr = tt.remoteAddr
+			request.Header.Set("X-API-Key", tt.apiKey)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestAddressKeyUnixSocket(t *testing.T) {
+	server := &Server{}
+	first, second := net.Pipe()
+	defer first.Close()
+	defer second.Close()
+
+	// Pipes are not unix sockets, so their clients are told apart by IP
+	request := httptest.NewRequest(http.MethodGet, "/databases", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	if key := addressKey(request.WithContext(server.connContext(request.Context(), first))); key != "ip:10.0.0.1" {
+		t.Errorf("addressKey() = %q; expected %q", key, "ip:10.0.0.1")
+	}
+
+	path := filepath.Join(t.TempDir(), "gsqlhealth.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to listen on a unix socket: %v", err)
+	}
+	defer listener.Close()
+
+	var keys []string
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("Failed to connect to the unix socket: %v", err)
+		}
+		defer conn.Close()
+
+		request := httptest.NewRequest(http.MethodGet, "/databases", nil)
+		request.RemoteAddr = "@"
+		keys = append(keys, addressKey(request.WithContext(server.connContext(request.Context(), conn))))
+	}
+	if keys[0] == keys[1] || !strings.HasPrefix(keys[0], "unix:") {
+		t.Errorf("addressKey() = %q, %q; expected a distinct key per unix socket connection", keys[0], keys[1])
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	router := server.setupRoutes()
+
+	tests := []struct {
+		name       string
+		path       string
+		requestID  string
+		expectSame bool
+	}{
+		{"propagated", "/databases", "dashboard-42", true},
+		{"generated", "/databases", "", false},
+		{"invalid replaced", "/databases", "bad id\n", false},
+		{"error response", "/health/missing", "dashboard-43", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.requestID != "" {
+				request.Header.Set("X-Request-ID", tt.requestID)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			id := recorder.Header().Get("X-Request-ID")
+			if id == "" {
+				t.Fatalf("Expected an X-Request-ID response header")
+			}
+			if (id == tt.requestID) != tt.expectSame {
+				t.Errorf("X-Request-ID = %q; expected propagated %v", id, tt.expectSame)
+			}
+
+			if recorder.Code >= 400 {
+				var response ErrorResponse
+				if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+					t.Fatalf("Failed to decode error response: %v", err)
+				}
+				if response.RequestID != id {
+					t.Errorf("RequestID = %q; expected %q", response.RequestID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestPprofRoutes(t *testing.T) {
+	tests := []struct {
+		name         string
+		debug        config.Debug
+		path         string
+		expectedCode int
+	}{
+		{"disabled", config.Debug{}, "/debug/pprof/", http.StatusNotFound},
+		{"index", config.Debug{Enabled: true}, "/debug/pprof/", http.StatusOK},
+		{"named profile", config.Debug{Enabled: true}, "/debug/pprof/goroutine?debug=1", http.StatusOK},
+		{"separate listener", config.Debug{Enabled: true, Port: 6060}, "/debug/pprof/", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Server: config.Server{Debug: tt.debug}}
+			server := &Server{config: cfg, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+			recorder := httptest.NewRecorder()
+			server.setupRoutes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("Expected status %d, got %d", tt.expectedCode, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestHealthSummaryAndHead(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	httpServer := httptest.NewServer(server.setupRoutes())
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/health?summary=true")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var summary map[string]json.RawMessage
+	err = json.NewDecoder(resp.Body).Decode(&summary)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode summary: %v", err)
+	}
+	if _, ok := summary["databases"]; ok {
+		t.Errorf("Expected the summary to leave out the results")
+	}
+	for _, field := range []string{"status", "total_checks", "healthy_checks", "unhealthy_checks"} {
+		if _, ok := summary[field]; !ok {
+			t.Errorf("Expected %s in the summary", field)
+		}
+	}
+
+	for _, path := range []string{"/health", "/health?summary=true", "/health/db", "/health/db/users", apiPrefix + "/health", "/health/missing"} {
+		t.Run("HEAD "+path, func(t *testing.T) {
+			get, err := http.Get(httpServer.URL + path)
+			if err != nil {
+				t.Fatalf("GET failed: %v", err)
+			}
+			get.Body.Close()
+
+			head, err := http.Head(httpServer.URL + path)
+			if err != nil {
+				t.Fatalf("HEAD failed: %v", err)
+			}
+			body, _ := io.ReadAll(head.Body)
+			head.Body.Close()
+
+			if head.StatusCode != get.StatusCode {
+				t.Errorf("HEAD status = %d; expected the GET status %d", head.StatusCode, get.StatusCode)
+			}
+			if len(body) != 0 {
+				t.Errorf("Expected no body, got %d bytes", len(body))
+			}
+		})
+	}
+}
+
+func TestParseResultFields(t *testing.T) {
+	tests := []struct {
+		query         string
+		expectNil     bool
+		expectError   bool
+		expectedData  bool
+		expectedCount int // selected fields, 0 for all
+	}{
+		{"", true, false, true, 0},
+		{"?include_data=false", false, false, false, 0},
+		{"?include_data=true", false, false, true, 0},
+		{"?include_data=no", false, true, false, 0},
+		{"?fields=status,query_time", false, false, true, 4},
+		{"?fields=status&fields=data&include_data=false", false, false, false, 4},
+		{"?fields=status,rows", false, true, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			fields, err := parseResultFields(httptest.NewRequest(http.MethodGet, "/health"+tt.query, nil))
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if (fields == nil) != tt.expectNil {
+				t.Fatalf("parseResultFields() = %v; expected nil %v", fields, tt.expectNil)
+			}
+			if fields == nil {
+				return
+			}
+			if fields.includeData != tt.expectedData {
+				t.Errorf("includeData = %v; expected %v", fields.includeData, tt.expectedData)
+			}
+			if len(fields.fields) != tt.expectedCount {
+				t.Errorf("Selected %d fields; expected %d", len(fields.fields), tt.expectedCount)
+			}
+		})
+	}
+}
+
+func TestResultFieldsApply(t *testing.T) {
+	response := OverallHealthResponse{
+		Status: "healthy",
+		Databases: map[string][]*database.HealthResult{"db": {{
+			DatabaseName: "db",
+			TableName:    "users",
+			Status:       "healthy",
+			Data:         map[string]interface{}{"count": 12345678901234},
+			QueryTime:    time.Millisecond,
+		}}},
+	}
+
+	tests := []struct {
+		name         string
+		fields       resultFields
+		expectedKeys []string
+	}{
+		{"without data", resultFields{includeData: false}, []string{"database_name", "query_time", "status", "status_code", "table_name", "timestamp"}},
+		{"selected", resultFields{fields: map[string]bool{"database_name": true, "table_name": true, "status": true, "data": true}, includeData: true}, []string{"data", "database_name", "status", "table_name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projected, err := tt.fields.apply(response)
+			if err != nil {
+				t.Fatalf("apply() failed: %v", err)
+			}
+			encoded, _ := json.Marshal(projected)
+
+			var decoded struct {
+				Status    string                              `json:"status"`
+				Databases map[string][]map[string]interface{} `json:"databases"`
+			}
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("Failed to decode projection: %v", err)
+			}
+			if decoded.Status != "healthy" {
+				t.Errorf("Expected the response fields to be kept")
+			}
+
+			var keys []string
+			for key := range decoded.Databases["db"][0] {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.expectedKeys) {
+				t.Errorf("Result keys = %v; expected %v", keys, tt.expectedKeys)
+			}
+			if tt.fields.includeData && !strings.Contains(string(encoded), "12345678901234") {
+				t.Errorf("Expected data to be kept exactly in %s", encoded)
+			}
+		})
+	}
+}
+
+func TestDegradedStatusCode(t *testing.T) {
+	if code := (&Server{}).degradedStatusCode(); code != http.StatusOK {
+		t.Errorf("degradedStatusCode() without config = %d; expected %d", code, http.StatusOK)
+	}
+
+	cfg := &config.Config{}
+	if code := (&Server{config: cfg}).degradedStatusCode(); code != http.StatusOK {
+		t.Errorf("Default degradedStatusCode() = %d; expected %d", code, http.StatusOK)
+	}
+
+	cfg.Server.DegradedStatusCode = http.StatusMultiStatus
+	if code := (&Server{config: cfg}).degradedStatusCode(); code != http.StatusMultiStatus {
+		t.Errorf("degradedStatusCode() = %d; expected %d", code, http.StatusMultiStatus)
+	}
+}
+
+func TestHealthScore(t *testing.T) {
+	heavy, excluded := 3.0, 0.0
+	cfg := &config.Config{
+		Scoring: config.Scoring{Enabled: true, DegradedBelow: 90},
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{
+			{Name: "orders", Weight: &heavy},
+			{Name: "reports"},
+			{Name: "audit", Weight: &excluded},
+		}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := &Server{config: cfg, healthService: health.NewService(cfg, logger)}
+
+	tests := []struct {
+		name           string
+		statuses       map[string]database.Status
+		expectedScore  float64
+		expectedStatus database.Status
+		expectedCode   int
+	}{
+		{"all healthy", map[string]database.Status{"orders": database.StatusHealthy, "reports": database.StatusHealthy}, 100, database.StatusHealthy, http.StatusOK},
+		{"light failure", map[string]database.Status{"orders": database.StatusHealthy, "reports": database.StatusUnhealthy}, 75, database.StatusDegraded, http.StatusOK},
+		{"heavy failure", map[string]database.Status{"orders": database.StatusUnhealthy, "reports": database.StatusHealthy}, 25, database.StatusUnhealthy, http.StatusServiceUnavailable},
+		{"degraded", map[string]database.Status{"orders": database.StatusDegraded, "reports": database.StatusHealthy}, 62.5, database.StatusDegraded, http.StatusOK},
+		{"unweighted failure", map[string]database.Status{"orders": database.StatusHealthy, "audit": database.StatusError}, 100, database.StatusHealthy, http.StatusOK},
+		{"maintenance", map[string]database.Status{"orders": database.StatusMaintenance, "reports": database.StatusHealthy}, 100, database.StatusHealthy, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var results []*database.HealthResult
+			for table, status := range tt.statuses {
+				results = append(results, &database.HealthResult{DatabaseName: "db", TableName: table, Status: status})
+			}
+
+			score, scored := server.healthScore(map[string][]*database.HealthResult{"db": results})
+			if !scored || score != tt.expectedScore {
+				t.Fatalf("healthScore() = %g, %v; expected %g", score, scored, tt.expectedScore)
+			}
+			if status, code := server.scoreStatus(score); status != tt.expectedStatus || code != tt.expectedCode {
+				t.Errorf("scoreStatus(%g) = %s, %d; expected %s, %d", score, status, code, tt.expectedStatus, tt.expectedCode)
+			}
+		})
+	}
+
+	if _, scored := server.healthScore(map[string][]*database.HealthResult{}); scored {
+		t.Errorf("Expected no score without results")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	gauges := make(map[string][]metricSample)
+	addMetricSamples(gauges, &database.HealthResult{
+		DatabaseName: "app",
+		TableName:    "orders",
+		Labels:       map[string]string{"team": "payments", "cost-center": "42"},
+		Data: map[string]interface{}{"results": []map[string]interface{}{
+			{"region": "eu", "count": int64(12), "total": "1250.50"},
+			{"region": "us \"east\"", "count": int64(7), "total": nil},
+		}, "row_count": 2},
+	}, map[string]string{"count": "gsqlhealth_orders_count", "total": "gsqlhealth_orders_total"})
+	addMetricSamples(gauges, &database.HealthResult{DatabaseName: "app", TableName: "stripped"}, map[string]string{"count": "gsqlhealth_orders_count"})
+
+	expected := `# HELP gsqlhealth_orders_count Result column of a gsqlhealth check.
+# TYPE gsqlhealth_orders_count gauge
+gsqlhealth_orders_count{cost_center="42",database="app",region="eu",table="orders",team="payments"} 12
+gsqlhealth_orders_count{cost_center="42",database="app",region="us \"east\"",table="orders",team="payments"} 7
+# HELP gsqlhealth_orders_total Result column of a gsqlhealth check.
+# TYPE gsqlhealth_orders_total gauge
+gsqlhealth_orders_total{cost_center="42",database="app",region="eu",table="orders",team="payments"} 1250.5
+`
+	if metrics := formatMetrics(gauges); metrics != expected {
+		t.Errorf("formatMetrics() =\n%s\nexpected\n%s", metrics, expected)
+	}
+
+	expected = `# HELP gsqlhealth_check_status Status code of the latest result of a gsqlhealth check, from 0 (healthy) to 6 (internal error).
+# TYPE gsqlhealth_check_status gauge
+gsqlhealth_check_status{database="app",table="orders",team="payments"} 0
+gsqlhealth_check_status{database="app",table="reports"} 4
+`
+	statuses := formatStatusMetrics([]*database.HealthResult{
+		{DatabaseName: "app", TableName: "reports", Status: database.StatusUnhealthy},
+		{DatabaseName: "app", TableName: "orders", Status: database.StatusHealthy, Labels: map[string]string{"team": "payments"}},
+	})
+	if statuses != expected {
+		t.Errorf("formatStatusMetrics() =\n%s\nexpected\n%s", statuses, expected)
+	}
+
+	cfg := &config.Config{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	recorder := httptest.NewRecorder()
+	server.setupRoutes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("GET /metrics = %d (%s); expected the text exposition format", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+}
+
+func TestSLAMetrics(t *testing.T) {
+	availability := 99.5
+	checks := []health.CheckSLA{
+		{Database: "app", Table: "orders", Labels: map[string]string{"team": "payments"}, Report: health.SLAReport{
+			Availability:  &availability,
+			StatusChanges: 4,
+			Incidents:     2,
+			MTTR:          90 * time.Second,
+			LatencyP50:    2 * time.Millisecond,
+			LatencyP95:    8 * time.Millisecond,
+			LatencyP99:    20 * time.Millisecond,
+			LatencySum:    500 * time.Millisecond,
+			LatencyCount:  100,
+		}},
+		{Database: "app", Table: "new"},
+	}
+
+	expected := `# HELP gsqlhealth_check_availability_percent Percent of the observed SLA metrics window a gsqlhealth check was healthy.
+# TYPE gsqlhealth_check_availability_percent gauge
+gsqlhealth_check_availability_percent{database="app",table="orders",team="payments"} 99.5
+# HELP gsqlhealth_check_status_changes Status changes of a gsqlhealth check in the SLA metrics window.
+# TYPE gsqlhealth_check_status_changes gauge
+gsqlhealth_check_status_changes{database="app",table="orders",team="payments"} 4
+gsqlhealth_check_status_changes{database="app",table="new"} 0
+# HELP gsqlhealth_check_incidents Transitions of a gsqlhealth check into a failing status in the SLA metrics window.
+# TYPE gsqlhealth_check_incidents gauge
+gsqlhealth_check_incidents{database="app",table="orders",team="payments"} 2
+gsqlhealth_check_incidents{database="app",table="new"} 0
+# HELP gsqlhealth_check_mttr_seconds Mean time to recovery of a gsqlhealth check in the SLA metrics window.
+# TYPE gsqlhealth_check_mttr_seconds gauge
+gsqlhealth_check_mttr_seconds{database="app",table="orders",team="payments"} 90
+gsqlhealth_check_mttr_seconds{database="app",table="new"} 0
+# HELP gsqlhealth_check_query_time_seconds Query time of a gsqlhealth check in the SLA metrics window.
+# TYPE gsqlhealth_check_query_time_seconds summary
+gsqlhealth_check_query_time_seconds{database="app",table="orders",team="payments",quantile="0.5"} 0.002
+gsqlhealth_check_query_time_seconds{database="app",table="orders",team="payments",quantile="0.95"} 0.008
+gsqlhealth_check_query_time_seconds{database="app",table="orders",team="payments",quantile="0.99"} 0.02
+gsqlhealth_check_query_time_seconds_sum{database="app",table="orders",team="payments"} 0.5
+gsqlhealth_check_query_time_seconds_count{database="app",table="orders",team="payments"} 100
+`
+	if metrics := formatSLAMetrics(checks); metrics != expected {
+		t.Errorf("formatSLAMetrics() =\n%s\nexpected\n%s", metrics, expected)
+	}
+
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		History:   config.History{SLAMetricsWindow: config.Duration(time.Hour)},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	recorder := httptest.NewRecorder()
+	server.setupRoutes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(recorder.Body.String(), `gsqlhealth_check_incidents{database="db",table="users"} 0`) {
+		t.Errorf("GET /metrics = %s; expected the SLA metrics of db/users", recorder.Body.String())
+	}
+}
+
+func TestPushMetrics(t *testing.T) {
+	var method, path, auth, body string
+	status := http.StatusOK
+	pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, auth = r.Method, r.URL.EscapedPath(), r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(status)
+	}))
+	defer pushgateway.Close()
+
+	cfg := config.Pushgateway{
+		URL:      pushgateway.URL + "/",
+		Instance: "batch-01",
+		Labels:   map[string]string{"env": "prod", "path": "/var/lib"},
+		Username: "pusher",
+		Password: "secret",
+	}
+	databases := []config.Database{{Name: "app", Tables: []config.Table{
+		{Name: "orders", Metrics: map[string]string{"count": "gsqlhealth_orders_count"}},
+		{Name: "reports"},
+	}}}
+	results := map[string][]*database.HealthResult{"app": {
+		{DatabaseName: "app", TableName: "orders", Status: database.StatusHealthy, Data: map[string]interface{}{"count": int64(12)}},
+		{DatabaseName: "app", TableName: "reports", Status: database.StatusError},
+	}}
+
+	if err := PushMetrics(context.Background(), cfg, databases, results); err != nil {
+		t.Fatalf("PushMetrics() error = %v", err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/gsqlhealth/instance/batch-01/env/prod/path@base64/L3Zhci9saWI" {
+		t.Errorf("request = %s %s; expected a PUT to the grouping key", method, path)
+	}
+	if !strings.HasPrefix(auth, "Basic ") {
+		t.Errorf("Authorization = %q; expected basic auth", auth)
+	}
+	for _, sample := range []string{
+		`gsqlhealth_check_status{database="app",table="orders"} 0`,
+		`gsqlhealth_check_status{database="app",table="reports"} 5`,
+		`gsqlhealth_orders_count{database="app",table="orders"} 12`,
+	} {
+		if !strings.Contains(body, sample) {
+			t.Errorf("body = %s; expected %s", body, sample)
+		}
+	}
+
+	status = http.StatusBadRequest
+	if err := PushMetrics(context.Background(), cfg, databases, results); err == nil {
+		t.Error("expected an error for a rejected push")
+	}
+}
+
+func TestSilencesAdmin(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := health.NewService(cfg, logger)
+	router := NewServer(cfg, service, logger).setupRoutes()
+
+	tests := []struct {
+		body         string
+		expectedCode int
+	}{
+		{`{"matcher": {"database": "db"}, "duration": "2h", "comment": "vacuum", "created_by": "dba"}`, http.StatusCreated},
+		{`{"matcher": {"database": "db"}, "duration": "1d", "comment": "vacuum"}`, http.StatusBadRequest},
+		{`{"matcher": {}, "duration": "2h", "comment": "vacuum", "created_by": "dba"}`, http.StatusBadRequest},
+		{`{"matcher": {"database": "db"}, "duration": "soon", "comment": "vacuum", "created_by": "dba"}`, http.StatusBadRequest},
+		{"not a silence", http.StatusBadRequest},
+	}
+
+	var created health.Silence
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/admin/silences", strings.NewReader(tt.body)))
+		if recorder.Code != tt.expectedCode {
+			t.Errorf("POST /admin/silences %q: expected status %d, got %d", tt.body, tt.expectedCode, recorder.Code)
+		}
+		if recorder.Code == http.StatusCreated {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &created); err != nil {
+				t.Fatalf("Failed to decode silence: %v", err)
+			}
+		}
+	}
+	if created.ID == "" || created.CreatedBy != "dba" || created.EndsAt.Sub(created.StartsAt) != 2*time.Hour {
+		t.Fatalf("Silence = %+v; expected a 2h silence by dba", created)
+	}
+	if !service.IsSilenced("db", "users", nil, time.Now()) {
+		t.Error("expected the database's checks to be silenced")
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/silences", nil))
+	var response SilencesResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode silences: %v", err)
+	}
+	if response.Count != 1 || response.Silences[0].ID != created.ID {
+		t.Errorf("Silences = %+v; expected the created silence", response.Silences)
+	}
+
+	for _, expectedCode := range []int{http.StatusNoContent, http.StatusNotFound} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/admin/silences/"+created.ID, nil))
+		if recorder.Code != expectedCode {
+			t.Errorf("DELETE /admin/silences/%s: expected status %d, got %d", created.ID, expectedCode, recorder.Code)
+		}
+	}
+	if service.IsSilenced("db", "users", nil, time.Now()) {
+		t.Error("expected the expired silence to end")
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "db", Tables: []config.Table{{Name: "users"}}}},
+		Server: config.Server{Auth: config.Auth{
+			APIKeys: []config.APIKey{{Name: "ops", Key: "0123456789abcdef0123"}},
+		}},
+		Audit: config.Audit{Enabled: true, Output: config.AuditOutputStdout, ExemptPaths: []string{"/cache/stats"}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var output bytes.Buffer
+	server := NewServer(cfg, health.NewService(cfg, logger), logger)
+	server.SetAuditLog(audit.New(&output))
+	router := server.setupRoutes()
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+		apiKey string
+	}{
+		{http.MethodGet, "/databases", "", ""},
+		{http.MethodPost, apiPrefix + "/admin/scheduler/pause/db", "", "0123456789abcdef0123"},
+		{http.MethodPost, "/admin/databases", "name: added\ntype: postgres\npassword: hunter2\n", "0123456789abcdef0123"},
+		{http.MethodGet, "/cache/stats?token=abc", "", "0123456789abcdef0123"},
+		{http.MethodGet, "/databases?page_token=abc", "", "0123456789abcdef0123"},
+	}
+	for _, req := range requests {
+		request := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+		if req.apiKey != "" {
+			request.Header.Set("X-API-Key", req.apiKey)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	var records []audit.Record
+	decoder := json.NewDecoder(&output)
+	for decoder.More() {
+		var record audit.Record
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("Failed to decode audit record: %v", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 4 {
+		t.Fatalf("recorded %d requests; expected 4 without the exempt path", len(records))
+	}
+
+	denied := records[0]
+	if denied.Action != "http.request" || denied.Outcome != audit.OutcomeDenied || denied.Principal != "" || denied.Status != http.StatusUnauthorized {
+		t.Errorf("record = %+v; expected the denied request", denied)
+	}
+
+	pause := records[1]
+	if pause.Action != "scheduler.pause" || pause.Principal != "api_key:ops" || pause.Outcome != audit.OutcomeSuccess || pause.Params["database"] != "db" || pause.RequestID == "" {
+		t.Errorf("record = %+v; expected the pause by the API key", pause)
+	}
+
+	add := records[2]
+	body, _ := add.Body.(map[string]interface{})
+	if add.Action != "database.add" || body["name"] != "added" || body["password"] != "[REDACTED]" {
+		t.Errorf("record = %+v; expected the redacted database definition", add)
+	}
+
+	if query := records[3].Query["page_token"]; len(query) != 1 || query[0] != "[REDACTED]" {
+		t.Errorf("query = %v; expected the token redacted", records[3].Query)
+	}
+}
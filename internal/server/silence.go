@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gsqlhealth/internal/health"
+
+	"github.com/gorilla/mux"
+)
+
+// silenceRequest is the body of POST /admin/silences
+type silenceRequest struct {
+	Matcher   health.SilenceMatcher `json:"matcher"`
+	Duration  string                `json:"duration"`   // Go duration or days, e.g. "2h" or "1d"
+	Comment   string                `json:"comment"`    // why, such as the maintenance it covers
+	CreatedBy string                `json:"created_by"` // defaults to the authenticated client
+}
+
+// handleAddSilence handles requests to POST /admin/silences, silencing the
+// notifications of the matching checks for a duration
+func (s *Server) handleAddSilence(w http.ResponseWriter, r *http.Request) {
+	var request silenceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSilenceBodySize)).Decode(&request); err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body, expected a silence", err)
+		return
+	}
+
+	duration, err := parseLookback(request.Duration)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid duration: %q", request.Duration), err)
+		return
+	}
+
+	createdBy := request.CreatedBy
+	if createdBy == "" {
+		createdBy = principalFromContext(r.Context())
+	}
+
+	silence, err := s.healthService.AddSilence(request.Matcher, duration, request.Comment, createdBy)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid silence", err)
+		return
+	}
+
+	s.writeJSONResponse(w, http.StatusCreated, silence)
+}
+
+// handleListSilences handles requests to GET /admin/silences
+func (s *Server) handleListSilences(w http.ResponseWriter, r *http.Request) {
+	silences := s.healthService.GetSilences()
+	s.writeJSONResponse(w, http.StatusOK, SilencesResponse{
+		Silences:  silences,
+		Count:     len(silences),
+		Timestamp: time.Now(),
+	})
+}
+
+// handleExpireSilence handles requests to DELETE /admin/silences/{id}, ending
+// a silence before its end time
+func (s *Server) handleExpireSilence(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := s.healthService.ExpireSilence(id); err != nil {
+		if errors.Is(err, health.ErrSilenceNotFound) {
+			s.writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Silence '%s' not found", id), err)
+			return
+		}
+		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to expire silence", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultSLAWindow is the reporting window used without ?window
+const defaultSLAWindow = "30d"
+
+// handleSLA handles requests to /sla/{database} and /sla/{database}/{table}
+func (s *Server) handleSLA(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	databaseName := vars["database"]
+	tableName := vars["table"]
+
+	// to defaults to now and from to the window before it; from replaces the
+	// window
+	now := time.Now()
+	to := now
+	if value := r.URL.Query().Get("to"); value != "" {
+		var err error
+		if to, err = parseSince(value, now); err != nil {
+			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid to parameter", err)
+			return
+		}
+	}
+
+	windowValue := r.URL.Query().Get("window")
+	from, err := parseSince(r.URL.Query().Get("from"), now)
+	if err != nil {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid from parameter", err)
+		return
+	}
+	if from.IsZero() {
+		if windowValue == "" {
+			windowValue = defaultSLAWindow
+		}
+		window, err := parseWindow(windowValue)
+		if err != nil {
+			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid window parameter", err)
+			return
+		}
+		from = to.Add(-window)
+	} else if windowValue != "" {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid window parameter", fmt.Errorf("window cannot be combined with from"))
+		return
+	}
+	if !from.Before(to) {
+		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid from parameter", fmt.Errorf("from must be before to"))
+		return
+	}
+
+	response := SLAResponse{
+		Database:  databaseName,
+		Table:     tableName,
+		Window:    windowValue,
+		From:      from,
+		To:        to,
+		Timestamp: now,
+	}
+
+	if tableName != "" {
+		response.SLA, err = s.healthService.GetTableSLA(databaseName, tableName, from, to)
+	} else {
+		response.SLA, response.Tables, err = s.healthService.GetDatabaseSLA(databaseName, from, to)
+	}
+	if err != nil {
+		statusCode, message := s.getErrorResponse(err, databaseName, tableName)
+		s.writeErrorResponse(w, statusCode, message, err)
+		return
+	}
+
+	s.writeResponse(w, r, http.StatusOK, response)
+}
+
+// parseWindow parses a reporting window, a Go duration or a number of days ("30d")
+func parseWindow(value string) (time.Duration, error) {
+	window, err := parseLookback(value)
+	if err != nil {
+		if strings.HasSuffix(value, "d") {
+			return 0, err
+		}
+		return 0, fmt.Errorf("expected a duration or a number of days: %s", value)
+	}
+
+	if window <= 0 {
+		return 0, fmt.Errorf("window must be positive")
+	}
+	return window, nil
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"gsqlhealth/internal/config"
+)
+
+// certReloadInterval limits how often the certificate files are checked for changes
+const certReloadInterval = 10 * time.Second
+
+// certReloader serves the configured certificate, reloading it when the
+// certificate or key file changes. A failed reload keeps the previous
+// certificate, since the files are usually replaced one after the other.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTimes  [2]time.Time // of the certificate and key files when loaded
+	checkedAt time.Time
+}
+
+// newCertReloader loads the certificate and key
+func newCertReloader(certFile, keyFile string, logger *slog.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, logger: logger}
+
+	modTimes, err := r.statFiles()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.modTimes = modTimes
+	r.checkedAt = time.Now()
+	return r, nil
+}
+
+// statFiles returns the modification times of the certificate and key files
+func (r *certReloader) statFiles() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(r.checkedAt) < certReloadInterval {
+		return r.cert, nil
+	}
+	r.checkedAt = now
+
+	modTimes, err := r.statFiles()
+	if err != nil {
+		r.logger.Warn("Failed to check certificate for changes", "error", err)
+		return r.cert, nil
+	}
+	if modTimes[0].Equal(r.modTimes[0]) && modTimes[1].Equal(r.modTimes[1]) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		r.logger.Warn("Failed to reload certificate, keeping the previous one",
+			"cert_file", r.certFile,
+			"error", err)
+		return r.cert, nil
+	}
+
+	r.cert = &cert
+	r.modTimes = modTimes
+	r.logger.Info("Reloaded TLS certificate", "cert_file", r.certFile)
+	return r.cert, nil
+}
+
+// newTLSConfig builds the TLS configuration of the HTTP server. With a client
+// CA, clients must present a certificate signed by it.
+func newTLSConfig(cfg config.TLS, logger *slog.Logger) (*tls.Config, error) {
+	reloader, err := newCertReloader(cfg.CertFile, cfg.KeyFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
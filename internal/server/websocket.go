@@ -0,0 +1,181 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"gsqlhealth/internal/health"
+
+	"golang.org/x/net/websocket"
+)
+
+// wsBufferSize is how many check completions a slow /ws client may lag behind
+const wsBufferSize = 256
+
+// wsRequest is a message sent by /ws clients
+type wsRequest struct {
+	Action   string `json:"action"`             // "subscribe" or "unsubscribe"
+	Database string `json:"database,omitempty"` // empty for all databases
+	Table    string `json:"table,omitempty"`    // empty for all tables of the database
+}
+
+// wsMessage is a message sent to /ws clients; check completions are sent as health.Event
+type wsMessage struct {
+	Type     string `json:"type"` // "subscribed", "unsubscribed" or "error"
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// wsSession holds the subscriptions of one /ws connection
+type wsSession struct {
+	conn *websocket.Conn
+
+	sendMu sync.Mutex
+
+	mu            sync.Mutex
+	subscriptions map[string]bool // "" for everything, "database/" or "database/table"
+}
+
+// handleWebSocket upgrades /ws requests. Browsers do not apply CORS to
+// WebSockets, so cross-origin handshakes are checked against the CORS policy.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	server := websocket.Server{
+		Handshake: s.checkWebSocketOrigin,
+		Handler:   s.serveWebSocket,
+	}
+	server.ServeHTTP(w, r)
+}
+
+// checkWebSocketOrigin accepts same-origin handshakes and those from origins
+// allowed by the CORS policy
+func (s *Server) checkWebSocketOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" || s.corsOriginAllowed(origin) {
+		return nil
+	}
+
+	if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+		return nil
+	}
+	return fmt.Errorf("origin %q not allowed", origin)
+}
+
+// serveWebSocket sends check completions matching the client's subscriptions
+// until the client disconnects
+func (s *Server) serveWebSocket(conn *websocket.Conn) {
+	session := &wsSession{
+		conn:          conn,
+		subscriptions: make(map[string]bool),
+	}
+
+	events, unsubscribe := s.healthService.Subscribe(wsBufferSize)
+	defer unsubscribe()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		s.readWebSocket(session)
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			if event.Type != health.EventCheckCompleted || !session.matches(event.Database, event.Table) {
+				continue
+			}
+			if err := session.send(event); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// readWebSocket applies subscription requests until the connection fails
+func (s *Server) readWebSocket(session *wsSession) {
+	for {
+		var request wsRequest
+		if err := websocket.JSON.Receive(session.conn, &request); err != nil {
+			return
+		}
+
+		reply := wsMessage{Database: request.Database, Table: request.Table}
+		if err := s.validateSubscription(request); err != nil {
+			reply.Type = "error"
+			reply.Error = err.Error()
+		} else {
+			session.mu.Lock()
+			if request.Action == "subscribe" {
+				session.subscriptions[subscriptionKey(request.Database, request.Table)] = true
+				reply.Type = "subscribed"
+			} else {
+				delete(session.subscriptions, subscriptionKey(request.Database, request.Table))
+				reply.Type = "unsubscribed"
+			}
+			session.mu.Unlock()
+		}
+
+		if err := session.send(reply); err != nil {
+			return
+		}
+	}
+}
+
+// validateSubscription checks the action and that the target is configured
+func (s *Server) validateSubscription(request wsRequest) error {
+	if request.Action != "subscribe" && request.Action != "unsubscribe" {
+		return fmt.Errorf("unknown action %q, expected subscribe or unsubscribe", request.Action)
+	}
+
+	if request.Database == "" {
+		if request.Table != "" {
+			return fmt.Errorf("table requires database")
+		}
+		return nil
+	}
+
+	tables, err := s.healthService.GetTableNames(request.Database)
+	if err != nil {
+		return fmt.Errorf("database '%s' not found", request.Database)
+	}
+	if request.Table == "" {
+		return nil
+	}
+	for _, tableName := range tables {
+		if tableName == request.Table {
+			return nil
+		}
+	}
+	return fmt.Errorf("table '%s' not found in database '%s'", request.Table, request.Database)
+}
+
+// matches reports whether the session subscribed to a check
+func (session *wsSession) matches(databaseName, tableName string) bool {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	return session.subscriptions[""] ||
+		session.subscriptions[subscriptionKey(databaseName, "")] ||
+		session.subscriptions[subscriptionKey(databaseName, tableName)]
+}
+
+// send writes a JSON message; the reader and writer goroutines both send
+func (session *wsSession) send(message interface{}) error {
+	session.sendMu.Lock()
+	defer session.sendMu.Unlock()
+
+	return websocket.JSON.Send(session.conn, message)
+}
+
+// subscriptionKey returns the subscription key of a database and table
+func subscriptionKey(databaseName, tableName string) string {
+	if databaseName == "" {
+		return ""
+	}
+	return databaseName + "/" + tableName
+}
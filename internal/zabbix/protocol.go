@@ -0,0 +1,116 @@
+// Package zabbix pushes the results of checks to Zabbix trapper items with
+// the sender protocol, and generates the Zabbix template of those items.
+package zabbix
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+)
+
+// Sender protocol: a request and its response are each a header of "ZBXD",
+// a flags byte and the little endian length of the JSON data that follows
+const (
+	headerMagic = "ZBXD"
+	headerFlags = 0x01
+	headerSize  = len(headerMagic) + 1 + 8
+
+	// maxResponseSize bounds the responses read from the server
+	maxResponseSize = 1 << 20
+)
+
+// Value is a value of a trapper item
+type Value struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock,omitempty"`
+	NS    int    `json:"ns,omitempty"`
+}
+
+// request is a sender data request
+type request struct {
+	Request string  `json:"request"`
+	Data    []Value `json:"data"`
+}
+
+// response is the response of the server to a sender data request
+type response struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+// Send sends values to a Zabbix server or proxy. It fails unless the server
+// processed every value; values of items that do not exist, or are not
+// trapper items of the host, are the usual failures.
+func Send(ctx context.Context, address string, values []Value) error {
+	data, err := json.Marshal(request{Request: "sender data", Data: values})
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(append(header(len(data)), data...)); err != nil {
+		return fmt.Errorf("failed to send values: %w", err)
+	}
+
+	body, err := readPacket(conn)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if resp.Response != "success" {
+		return fmt.Errorf("zabbix returned %q: %s", resp.Response, resp.Info)
+	}
+
+	var processed, failed, total int
+	if _, err := fmt.Sscanf(resp.Info, "processed: %d; failed: %d; total: %d", &processed, &failed, &total); err == nil && failed > 0 {
+		return fmt.Errorf("zabbix failed to process %d of %d values; are the items of the template linked to the host?", failed, total)
+	}
+	return nil
+}
+
+// header returns the header of a packet of length bytes of data
+func header(length int) []byte {
+	h := make([]byte, headerSize)
+	copy(h, headerMagic)
+	h[len(headerMagic)] = headerFlags
+	binary.LittleEndian.PutUint64(h[len(headerMagic)+1:], uint64(length))
+	return h
+}
+
+// readPacket reads a packet and returns its data
+func readPacket(r io.Reader) ([]byte, error) {
+	h := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, h); err != nil {
+		return nil, err
+	}
+	if string(h[:len(headerMagic)]) != headerMagic {
+		return nil, fmt.Errorf("invalid header %q", h[:len(headerMagic)])
+	}
+	length := binary.LittleEndian.Uint64(h[len(headerMagic)+1:])
+	if length > maxResponseSize {
+		return nil, fmt.Errorf("packet of %d bytes exceeds %d", length, maxResponseSize)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
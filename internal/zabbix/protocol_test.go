@@ -0,0 +1,84 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// testServer answers one sender request, returning the values it received.
+// Values of keys in failKeys fail to process.
+func testServer(t *testing.T, failKeys ...string) (string, <-chan []Value) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []Value, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		data, err := readPacket(conn)
+		if err != nil {
+			return
+		}
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil || req.Request != "sender data" {
+			return
+		}
+		received <- req.Data
+
+		failed := 0
+		for _, value := range req.Data {
+			for _, key := range failKeys {
+				if value.Key == key {
+					failed++
+				}
+			}
+		}
+		body, _ := json.Marshal(response{
+			Response: "success",
+			Info:     fmt.Sprintf("processed: %d; failed: %d; total: %d; seconds spent: 0.000050", len(req.Data)-failed, failed, len(req.Data)),
+		})
+		conn.Write(append(header(len(body)), body...))
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSend(t *testing.T) {
+	address, received := testServer(t)
+	values := []Value{
+		{Host: "db-monitor", Key: "gsqlhealth.status[app,orders]", Value: "0", Clock: 1705314600},
+		{Host: "db-monitor", Key: "gsqlhealth.latency[app,orders]", Value: "0.012000", Clock: 1705314600},
+	}
+
+	if err := Send(context.Background(), address, values); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	got := <-received
+	if len(got) != 2 || got[0] != values[0] || got[1] != values[1] {
+		t.Errorf("received %+v; expected %+v", got, values)
+	}
+}
+
+func TestSendFailedValues(t *testing.T) {
+	address, _ := testServer(t, "gsqlhealth.status[app,missing]")
+	values := []Value{
+		{Host: "db-monitor", Key: "gsqlhealth.status[app,orders]", Value: "0"},
+		{Host: "db-monitor", Key: "gsqlhealth.status[app,missing]", Value: "0"},
+	}
+
+	err := Send(context.Background(), address, values)
+	if err == nil || !strings.Contains(err.Error(), "failed to process 1 of 2 values") {
+		t.Errorf("Send() error = %v; expected the failed value", err)
+	}
+}
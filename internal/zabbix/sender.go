@@ -0,0 +1,153 @@
+package zabbix
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/health"
+)
+
+const (
+	// eventBuffer is the number of health events buffered while values are
+	// being sent
+	eventBuffer = 256
+	// maxBatch bounds the values sent at once
+	maxBatch = 250
+)
+
+// Item keys of the checks, with the database and table as parameters
+const (
+	statusKey  = "gsqlhealth.status"
+	latencyKey = "gsqlhealth.latency"
+)
+
+// Sender pushes the status code and query time of every scheduled check run
+// to the trapper items of the configured host. Results queued while values
+// are sent go out together in the next send. Values that fail to send are
+// logged and dropped.
+type Sender struct {
+	cfg           config.Zabbix
+	healthService *health.Service
+	logger        *slog.Logger
+
+	unsubscribe func()
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+// NewSender creates a sender to the configured Zabbix server or proxy
+func NewSender(cfg *config.Config, healthService *health.Service, logger *slog.Logger) *Sender {
+	return &Sender{
+		cfg:           cfg.Zabbix,
+		healthService: healthService,
+		logger:        logger,
+	}
+}
+
+// Start sends the results of scheduled checks until Stop is called
+func (s *Sender) Start() {
+	events, unsubscribe := s.healthService.Subscribe(eventBuffer)
+	s.unsubscribe = unsubscribe
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		for {
+			select {
+			case event := <-events:
+				s.send(s.batch(event, events))
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	s.logger.Info("Zabbix sender enabled",
+		"server", s.cfg.GetServer(),
+		"host", s.cfg.GetHost())
+}
+
+// Stop ends the sender, waiting for the values being sent
+func (s *Sender) Stop() {
+	if s.done == nil {
+		return
+	}
+	s.unsubscribe()
+	close(s.stop)
+	<-s.done
+}
+
+// batch returns the values of an event and of the events queued after it
+func (s *Sender) batch(first health.Event, events <-chan health.Event) []Value {
+	values := s.values(first)
+	for len(values) < maxBatch {
+		select {
+		case event := <-events:
+			values = append(values, s.values(event)...)
+		default:
+			return values
+		}
+	}
+	return values
+}
+
+// send sends values, logging failures
+func (s *Sender) send(values []Value) {
+	if len(values) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.GetTimeout())
+	defer cancel()
+	if err := Send(ctx, s.cfg.GetServer(), values); err != nil {
+		s.logger.Warn("Failed to send values to Zabbix",
+			"server", s.cfg.GetServer(),
+			"values", len(values),
+			"error", err)
+	}
+}
+
+// values returns the item values of a completed check: its status code, and
+// its query time in seconds when the query ran
+func (s *Sender) values(event health.Event) []Value {
+	if event.Type != health.EventCheckCompleted {
+		return nil
+	}
+
+	host := s.cfg.GetHost()
+	clock, ns := event.Timestamp.Unix(), event.Timestamp.Nanosecond()
+	values := []Value{{
+		Host:  host,
+		Key:   itemKey(statusKey, event.Database, event.Table),
+		Value: strconv.Itoa(event.Status.Code()),
+		Clock: clock,
+		NS:    ns,
+	}}
+	if event.QueryTime > 0 {
+		values = append(values, Value{
+			Host:  host,
+			Key:   itemKey(latencyKey, event.Database, event.Table),
+			Value: strconv.FormatFloat(event.QueryTime.Seconds(), 'f', 6, 64),
+			Clock: clock,
+			NS:    ns,
+		})
+	}
+	return values
+}
+
+// itemKey returns the item key of a check, quoting parameters that contain
+// characters unquoted parameters cannot
+func itemKey(key string, params ...string) string {
+	quoted := make([]string, len(params))
+	for i, param := range params {
+		if strings.ContainsAny(param, `,[]"`) || strings.TrimSpace(param) != param {
+			param = `"` + strings.ReplaceAll(param, `"`, `\"`) + `"`
+		}
+		quoted[i] = param
+	}
+	return key + "[" + strings.Join(quoted, ",") + "]"
+}
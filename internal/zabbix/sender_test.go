@@ -0,0 +1,60 @@
+package zabbix
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+	"gsqlhealth/internal/health"
+)
+
+func TestSenderValues(t *testing.T) {
+	cfg := &config.Config{
+		Databases: []config.Database{{Name: "app", Tables: []config.Table{{Name: "orders"}}}},
+		Zabbix:    config.Zabbix{Enabled: true, Server: "zabbix", Host: "db-monitor"},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sender := NewSender(cfg, health.NewService(cfg, logger), logger)
+	timestamp := time.Date(2024, 1, 15, 10, 30, 0, 500, time.UTC)
+
+	events := make(chan health.Event, 2)
+	events <- health.Event{Type: health.EventStatusChanged, Database: "app", Table: "orders", Status: database.StatusUnhealthy}
+	events <- health.Event{Type: health.EventCheckCompleted, Database: "app", Table: "orders", Status: database.StatusUnhealthy, Timestamp: timestamp}
+	values := sender.batch(health.Event{Type: health.EventCheckCompleted, Database: "app", Table: "orders", Status: database.StatusHealthy, QueryTime: 12 * time.Millisecond, Timestamp: timestamp}, events)
+
+	expected := []Value{
+		{Host: "db-monitor", Key: "gsqlhealth.status[app,orders]", Value: "0", Clock: timestamp.Unix(), NS: 500},
+		{Host: "db-monitor", Key: "gsqlhealth.latency[app,orders]", Value: "0.012000", Clock: timestamp.Unix(), NS: 500},
+		{Host: "db-monitor", Key: "gsqlhealth.status[app,orders]", Value: "4", Clock: timestamp.Unix(), NS: 500},
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("batch() = %+v; expected %+v", values, expected)
+	}
+	for i := range values {
+		if values[i] != expected[i] {
+			t.Errorf("value %d = %+v; expected %+v", i, values[i], expected[i])
+		}
+	}
+}
+
+func TestItemKey(t *testing.T) {
+	tests := []struct {
+		params   []string
+		expected string
+	}{
+		{[]string{"app", "orders"}, "gsqlhealth.status[app,orders]"},
+		{[]string{"Reports DB", "connection"}, "gsqlhealth.status[Reports DB,connection]"},
+		{[]string{"app", "orders,items"}, `gsqlhealth.status[app,"orders,items"]`},
+		{[]string{"app", `say "hi"`}, `gsqlhealth.status[app,"say \"hi\""]`},
+		{[]string{" app", "rows[0]"}, `gsqlhealth.status[" app","rows[0]"]`},
+	}
+
+	for _, tt := range tests {
+		if key := itemKey(statusKey, tt.params...); key != tt.expected {
+			t.Errorf("itemKey(%q) = %s; expected %s", tt.params, key, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package zabbix
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"gsqlhealth/internal/config"
+	"gsqlhealth/internal/database"
+)
+
+const (
+	// exportVersion is the Zabbix export format of templates, imported by
+	// Zabbix 5.0 and later
+	exportVersion = "5.0"
+	// statusValueMap is the value map naming status codes
+	statusValueMap = "gsqlhealth status"
+	// application groups the items of the template
+	application = "gsqlhealth"
+)
+
+// valueMapStatuses name the status codes of status items; statuses sharing a
+// code are named by the first of them
+var valueMapStatuses = []database.Status{
+	database.StatusHealthy,
+	database.StatusDisabled,
+	database.StatusMaintenance,
+	database.StatusDegraded,
+	database.StatusUnhealthy,
+	database.StatusError,
+	database.StatusInternalError,
+}
+
+// TemplateOptions are the options of a generated template
+type TemplateOptions struct {
+	Name  string // technical name of the template
+	Group string // template group
+}
+
+// Export is a Zabbix configuration export holding a template
+type Export struct {
+	XMLName   xml.Name   `xml:"zabbix_export"`
+	Version   string     `xml:"version"`
+	Groups    []Group    `xml:"groups>group"`
+	Templates []Template `xml:"templates>template"`
+	ValueMaps []ValueMap `xml:"value_maps>value_map"`
+}
+
+// Group is a host or template group
+type Group struct {
+	Name string `xml:"name"`
+}
+
+// Application is an application of items
+type Application struct {
+	Name string `xml:"name"`
+}
+
+// Template is a template of items
+type Template struct {
+	Template     string        `xml:"template"`
+	Name         string        `xml:"name"`
+	Description  string        `xml:"description"`
+	Groups       []Group       `xml:"groups>group"`
+	Applications []Application `xml:"applications>application"`
+	Items        []Item        `xml:"items>item"`
+}
+
+// Item is a trapper item of a template
+type Item struct {
+	Name         string        `xml:"name"`
+	Type         string        `xml:"type"`
+	Key          string        `xml:"key"`
+	Delay        string        `xml:"delay"`
+	ValueType    string        `xml:"value_type"`
+	Units        string        `xml:"units,omitempty"`
+	Description  string        `xml:"description,omitempty"`
+	Applications []Application `xml:"applications>application"`
+	ValueMap     *ValueMapRef  `xml:"valuemap,omitempty"`
+	Triggers     []Trigger     `xml:"triggers>trigger,omitempty"`
+}
+
+// ValueMapRef refers to a value map by name
+type ValueMapRef struct {
+	Name string `xml:"name"`
+}
+
+// Trigger is a trigger of an item
+type Trigger struct {
+	Expression string `xml:"expression"`
+	Name       string `xml:"name"`
+	Priority   string `xml:"priority"`
+}
+
+// ValueMap maps item values to names
+type ValueMap struct {
+	Name     string    `xml:"name"`
+	Mappings []Mapping `xml:"mappings>mapping"`
+}
+
+// Mapping is a value of a value map and its name
+type Mapping struct {
+	Value    string `xml:"value"`
+	NewValue string `xml:"newvalue"`
+}
+
+// GenerateTemplate returns the template of the items the sender pushes to:
+// the status code of every enabled check, with triggers on degraded and
+// failing statuses, and its query time. Triggers on failures follow the
+// severity of the check.
+func GenerateTemplate(cfg *config.Config, opts TemplateOptions) *Export {
+	template := Template{
+		Template:     opts.Name,
+		Name:         opts.Name,
+		Description:  "Checks of gsqlhealth, pushed by its Zabbix sender",
+		Groups:       []Group{{Name: opts.Group}},
+		Applications: []Application{{Name: application}},
+	}
+
+	for i := range cfg.Databases {
+		dbConfig := &cfg.Databases[i]
+		if !dbConfig.IsEnabled() {
+			continue
+		}
+		for j := range dbConfig.Tables {
+			table := &dbConfig.Tables[j]
+			if !table.IsEnabled() {
+				continue
+			}
+			template.Items = append(template.Items,
+				statusItem(opts.Name, dbConfig.Name, table),
+				latencyItem(dbConfig.Name, table.Name))
+		}
+	}
+
+	valueMap := ValueMap{Name: statusValueMap}
+	seen := make(map[int]bool)
+	for _, status := range valueMapStatuses {
+		if seen[status.Code()] {
+			continue
+		}
+		seen[status.Code()] = true
+		valueMap.Mappings = append(valueMap.Mappings, Mapping{Value: fmt.Sprint(status.Code()), NewValue: string(status)})
+	}
+
+	return &Export{
+		Version:   exportVersion,
+		Groups:    []Group{{Name: opts.Group}},
+		Templates: []Template{template},
+		ValueMaps: []ValueMap{valueMap},
+	}
+}
+
+// statusItem returns the status item of a check and its triggers
+func statusItem(templateName, databaseName string, table *config.Table) Item {
+	key := itemKey(statusKey, databaseName, table.Name)
+	failing, degraded := triggerPriorities(table.GetSeverity())
+	last := fmt.Sprintf("{%s:%s.last()}", templateName, key)
+
+	return Item{
+		Name:         fmt.Sprintf("%s/%s: status", databaseName, table.Name),
+		Type:         "TRAP",
+		Key:          key,
+		Delay:        "0",
+		ValueType:    "UNSIGNED",
+		Description:  "Status code of the check, as gsqlhealth_check_status",
+		Applications: []Application{{Name: application}},
+		ValueMap:     &ValueMapRef{Name: statusValueMap},
+		Triggers: []Trigger{
+			{
+				Expression: fmt.Sprintf("%s>=%d", last, database.StatusUnhealthy.Code()),
+				Name:       fmt.Sprintf("%s on %s is failing", table.Name, databaseName),
+				Priority:   failing,
+			},
+			{
+				Expression: fmt.Sprintf("%s=%d", last, database.StatusDegraded.Code()),
+				Name:       fmt.Sprintf("%s on %s is degraded", table.Name, databaseName),
+				Priority:   degraded,
+			},
+		},
+	}
+}
+
+// latencyItem returns the query time item of a check
+func latencyItem(databaseName, tableName string) Item {
+	return Item{
+		Name:         fmt.Sprintf("%s/%s: query time", databaseName, tableName),
+		Type:         "TRAP",
+		Key:          itemKey(latencyKey, databaseName, tableName),
+		Delay:        "0",
+		ValueType:    "FLOAT",
+		Units:        "s",
+		Applications: []Application{{Name: application}},
+	}
+}
+
+// triggerPriorities returns the priorities of the failing and degraded
+// triggers of a check of a severity
+func triggerPriorities(severity string) (string, string) {
+	switch severity {
+	case config.SeverityWarning:
+		return "WARNING", "INFO"
+	case config.SeverityInfo:
+		return "INFO", "INFO"
+	default:
+		return "HIGH", "WARNING"
+	}
+}
@@ -0,0 +1,57 @@
+package zabbix
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"gsqlhealth/internal/config"
+)
+
+func TestGenerateTemplate(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Databases: []config.Database{
+			{Name: "app", Tables: []config.Table{
+				{Name: "orders"},
+				{Name: "reports", Severity: config.SeverityWarning},
+				{Name: "archive", Enabled: &disabled},
+			}},
+			{Name: "legacy", Enabled: &disabled, Tables: []config.Table{{Name: "users"}}},
+		},
+	}
+
+	export := GenerateTemplate(cfg, TemplateOptions{Name: "Template gsqlhealth", Group: "Templates/Databases"})
+	items := export.Templates[0].Items
+	if len(items) != 4 {
+		t.Fatalf("generated %d items; expected status and query time of 2 checks", len(items))
+	}
+
+	orders := items[0]
+	if orders.Key != "gsqlhealth.status[app,orders]" || orders.Type != "TRAP" || orders.ValueType != "UNSIGNED" || orders.ValueMap == nil {
+		t.Errorf("item = %+v; expected the status trapper item of orders", orders)
+	}
+	if len(orders.Triggers) != 2 || orders.Triggers[0].Expression != "{Template gsqlhealth:gsqlhealth.status[app,orders].last()}>=4" || orders.Triggers[0].Priority != "HIGH" {
+		t.Errorf("triggers = %+v; expected a HIGH trigger on failures", orders.Triggers)
+	}
+	if items[1].Key != "gsqlhealth.latency[app,orders]" || items[1].ValueType != "FLOAT" || items[1].Units != "s" {
+		t.Errorf("item = %+v; expected the query time item of orders", items[1])
+	}
+	if priority := items[2].Triggers[0].Priority; priority != "WARNING" {
+		t.Errorf("failing priority of a warning check = %s; expected WARNING", priority)
+	}
+
+	if mappings := export.ValueMaps[0].Mappings; len(mappings) != 7 || mappings[3].Value != "3" || mappings[3].NewValue != "degraded" {
+		t.Errorf("mappings = %+v; expected one per status code", mappings)
+	}
+
+	output, err := xml.MarshalIndent(export, "", "    ")
+	if err != nil {
+		t.Fatalf("Failed to encode template: %v", err)
+	}
+	for _, expected := range []string{"<zabbix_export>", "<version>5.0</version>", "<key>gsqlhealth.status[app,orders]</key>", "<expression>{Template gsqlhealth:gsqlhealth.status[app,orders].last()}&gt;=4</expression>"} {
+		if !strings.Contains(string(output), expected) {
+			t.Errorf("template does not contain %s", expected)
+		}
+	}
+}